@@ -0,0 +1,214 @@
+// Command loadgen 对运行中的 Prompt Manager 实例施加接近真实的混合流量
+// （列表、按名称查询、渲染、执行上报），输出各场景的延迟分位数，
+// 并在 p95 超出 --budget 给定的性能预算时以非零状态退出，便于在发布前发现仓储层退化。
+//
+// 服务端分配情况由仓储基准测试给出（make bench 生成内存 profile），loadgen 只度量端到端延迟。
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// options 控制命令行参数。
+type options struct {
+	BaseURL     string
+	APIKey      string
+	Email       string
+	Password    string
+	Workspace   string
+	Duration    time.Duration
+	Warmup      time.Duration
+	Concurrency int
+	Prompts     int
+	ReportBatch int
+	Mix         string
+	Budget      string
+	MaxErrors   float64
+	JSON        bool
+	Keep        bool
+}
+
+func main() {
+	os.Exit(execute(parseFlags()))
+}
+
+// execute 准备数据、压测并输出报告，返回进程退出码；延迟的清理在返回前执行。
+func execute(opts options) int {
+	weights, err := parseWeights(opts.Mix)
+	if err != nil {
+		return failf(2, "--mix: %v", err)
+	}
+	budget, err := parseBudget(opts.Budget)
+	if err != nil {
+		return failf(2, "--budget: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := &client{
+		baseURL: strings.TrimRight(opts.BaseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{MaxIdleConnsPerHost: opts.Concurrency}},
+		header:  http.Header{},
+	}
+	switch {
+	case opts.APIKey != "":
+		c.header.Set("X-API-Key", opts.APIKey)
+	case opts.Email != "":
+		token, err := c.login(ctx, opts.Email, opts.Password)
+		if err != nil {
+			return failf(1, "%v", err)
+		}
+		c.header.Set("Authorization", "Bearer "+token)
+	default:
+		return failf(2, "either --api-key or --email/--password is required")
+	}
+	if opts.Workspace != "" {
+		c.header.Set("X-Workspace-ID", opts.Workspace)
+	}
+
+	prefix := fmt.Sprintf("loadgen-%d", time.Now().Unix())
+	prompts, err := c.seed(ctx, prefix, opts.Prompts)
+	if !opts.Keep {
+		defer func() {
+			// 中断后仍需清理，使用独立的上下文。
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if err := c.cleanup(cleanupCtx, prompts); err != nil {
+				fmt.Fprintf(os.Stderr, "清理压测数据失败: %v\n", err)
+			}
+		}()
+	}
+	if err != nil {
+		return failf(1, "准备压测数据失败: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "已创建 %d 个 Prompt（前缀 %s），预热 %s 后压测 %s，并发 %d\n", len(prompts), prefix, opts.Warmup, opts.Duration, opts.Concurrency)
+
+	report := run(ctx, c, prompts, newPicker(weights), opts, budget)
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	} else {
+		printReport(report)
+	}
+	code := 0
+	if failing := report.OverErrorRate(opts.MaxErrors); len(failing) > 0 {
+		code = failf(1, "错误率超过 %.1f%%: %s", opts.MaxErrors*100, strings.Join(failing, ", "))
+	}
+	if over := report.OverBudget(); len(over) > 0 {
+		code = failf(1, "p95 超出性能预算: %s", strings.Join(over, ", "))
+	}
+	return code
+}
+
+// run 启动 opts.Concurrency 个闭环 worker，预热阶段的请求不计入统计。
+func run(ctx context.Context, c *client, prompts []seededPrompt, p *picker, opts options, budget map[string]time.Duration) *Report {
+	warmupEnd := time.Now().Add(opts.Warmup)
+	ctx, cancel := context.WithDeadline(ctx, warmupEnd.Add(opts.Duration))
+	defer cancel()
+
+	var wg sync.WaitGroup
+	recorders := make([]*recorder, opts.Concurrency)
+	for i := range recorders {
+		rec := newRecorder()
+		recorders[i] = rec
+		wg.Add(1)
+		go func(seed uint64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(seed, uint64(time.Now().UnixNano())))
+			for ctx.Err() == nil {
+				scenario := p.pick(rng)
+				prompt := prompts[rng.IntN(len(prompts))]
+				start := time.Now()
+				err := c.request(ctx, scenario, prompt, rng, opts.ReportBatch)
+				if ctx.Err() != nil {
+					// 截止时被取消的请求不计入结果。
+					return
+				}
+				if start.After(warmupEnd) {
+					rec.record(scenario, time.Since(start), err)
+				}
+			}
+		}(uint64(i))
+	}
+	wg.Wait()
+
+	merged := newRecorder()
+	for _, rec := range recorders {
+		merged.merge(rec)
+	}
+	elapsed := time.Since(warmupEnd)
+	return buildReport(merged, min(elapsed, opts.Duration), opts.Concurrency, budget)
+}
+
+func printReport(report *Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "scenario\trequests\terrors\tp50\tp95\tp99\tmax\tbudget\t")
+	for _, s := range report.Scenarios {
+		budget, verdict := "-", ""
+		if s.Budget > 0 {
+			budget = s.Budget.String()
+			verdict = " ok"
+			if s.OverBudget {
+				verdict = " OVER"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s%s\t\n", s.Name, s.Requests, s.Errors,
+			round(s.P50), round(s.P95), round(s.P99), round(s.Max), budget, verdict)
+	}
+	_ = w.Flush()
+	fmt.Printf("\n%.1f req/s over %s with %d workers\n", report.Throughput, report.Duration.Round(time.Millisecond), report.Concurrency)
+	for _, s := range report.Scenarios {
+		if s.ErrorSample != "" {
+			fmt.Printf("%s errors, e.g.: %s\n", s.Name, s.ErrorSample)
+		}
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
+
+func parseFlags() options {
+	var opts options
+	pflag.StringVar(&opts.BaseURL, "base-url", "http://localhost:8080", "目标实例地址")
+	pflag.StringVar(&opts.APIKey, "api-key", "", "API Key，需 read、render、execute 范围及创建/删除 Prompt 的权限")
+	pflag.StringVar(&opts.Email, "email", "", "登录邮箱（未提供 --api-key 时使用）")
+	pflag.StringVar(&opts.Password, "password", "", "登录密码")
+	pflag.StringVar(&opts.Workspace, "workspace", "", "目标工作区 ID，缺省为默认工作区")
+	pflag.DurationVar(&opts.Duration, "duration", 30*time.Second, "计入统计的压测时长")
+	pflag.DurationVar(&opts.Warmup, "warmup", 5*time.Second, "预热时长，期间的请求不计入统计")
+	pflag.IntVar(&opts.Concurrency, "concurrency", 8, "并发 worker 数")
+	pflag.IntVar(&opts.Prompts, "prompts", 20, "压测前创建的 Prompt 数量")
+	pflag.IntVar(&opts.ReportBatch, "report-batch", 10, "每次执行上报的记录条数")
+	pflag.StringVar(&opts.Mix, "mix", "list=4,get=4,render=3,report=1", "场景权重")
+	pflag.StringVar(&opts.Budget, "budget", "", "各场景 p95 预算，例如 list=50ms,render=80ms；超出时退出码为 1")
+	pflag.Float64Var(&opts.MaxErrors, "max-error-rate", 0.01, "允许的单场景错误率，超出时退出码为 1")
+	pflag.BoolVar(&opts.JSON, "json", false, "以 JSON 输出报告")
+	pflag.BoolVar(&opts.Keep, "keep", false, "保留压测创建的 Prompt")
+	pflag.Parse()
+	if opts.Concurrency < 1 || opts.Prompts < 1 || opts.ReportBatch < 1 || opts.Duration <= 0 || opts.Warmup < 0 {
+		os.Exit(failf(2, "--concurrency、--prompts、--report-batch 与 --duration 必须为正数"))
+	}
+	return opts
+}
+
+// failf 输出错误信息并返回给定的退出码。
+func failf(code int, format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	return code
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 场景名称，与 --mix、--budget 中的键一致。
+const (
+	scenarioList   = "list"
+	scenarioGet    = "get"
+	scenarioRender = "render"
+	scenarioReport = "report"
+)
+
+var scenarioNames = []string{scenarioList, scenarioGet, scenarioRender, scenarioReport}
+
+// seedBody 为压测 Prompt 的正文，渲染场景会填充其中的变量。
+const seedBody = "Hello {{ name | default \"guest\" }}, you have {{ count }} new messages.\n{{#each items}}- {{ title | upper }}\n{{/each}}"
+
+// client 封装对 Prompt Manager HTTP API 的调用。
+type client struct {
+	baseURL string
+	http    *http.Client
+	header  http.Header
+}
+
+// apiError 表示非 2xx 响应。
+type apiError struct {
+	Status int
+	Code   string
+}
+
+func (e *apiError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("unexpected status %d", e.Status)
+	}
+	return fmt.Sprintf("unexpected status %d (%s)", e.Status, e.Code)
+}
+
+func (c *client) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var failure struct {
+			Code string `json:"code"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		return &apiError{Status: resp.StatusCode, Code: failure.Code}
+	}
+	if out == nil {
+		// 读完响应体以复用连接，响应解码耗时也计入延迟。
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(&struct {
+		Data interface{} `json:"data"`
+	}{Data: out})
+}
+
+// login 使用邮箱密码登录并返回访问令牌。
+func (c *client) login(ctx context.Context, email, password string) (string, error) {
+	var result struct {
+		Tokens struct {
+			AccessToken string `json:"access_token"`
+		} `json:"tokens"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/login", map[string]string{"email": email, "password": password}, &result); err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+	return result.Tokens.AccessToken, nil
+}
+
+// seededPrompt 为压测前创建的 Prompt。
+type seededPrompt struct {
+	ID        string
+	Name      string
+	VersionID string
+}
+
+// seed 创建 count 个带激活版本的 Prompt，名称带运行前缀避免与已有数据冲突。
+func (c *client) seed(ctx context.Context, prefix string, count int) ([]seededPrompt, error) {
+	prompts := make([]seededPrompt, 0, count)
+	for i := 0; i < count; i++ {
+		var result struct {
+			Prompt struct {
+				ID              string  `json:"id"`
+				Name            string  `json:"name"`
+				ActiveVersionID *string `json:"active_version_id"`
+			} `json:"prompt"`
+		}
+		payload := map[string]interface{}{
+			"name": fmt.Sprintf("%s-%03d", prefix, i),
+			"tags": []string{"loadgen"},
+			"body": seedBody,
+		}
+		if err := c.do(ctx, http.MethodPost, "/api/v1/prompts", payload, &result); err != nil {
+			return prompts, fmt.Errorf("create prompt: %w", err)
+		}
+		if result.Prompt.ActiveVersionID == nil {
+			return prompts, fmt.Errorf("prompt %s has no active version", result.Prompt.Name)
+		}
+		prompts = append(prompts, seededPrompt{ID: result.Prompt.ID, Name: result.Prompt.Name, VersionID: *result.Prompt.ActiveVersionID})
+	}
+	return prompts, nil
+}
+
+// cleanup 软删除压测创建的 Prompt，被限流时等待后重试。
+func (c *client) cleanup(ctx context.Context, prompts []seededPrompt) error {
+	var errs []error
+	for _, prompt := range prompts {
+		var err error
+		for attempt := 1; attempt <= 3; attempt++ {
+			err = c.do(ctx, http.MethodDelete, "/api/v1/prompts/"+prompt.ID, nil, nil)
+			var apiErr *apiError
+			if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return errors.Join(append(errs, ctx.Err())...)
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", prompt.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// request 执行一次场景请求。
+func (c *client) request(ctx context.Context, scenario string, prompt seededPrompt, rng *rand.Rand, reportBatch int) error {
+	switch scenario {
+	case scenarioList:
+		return c.do(ctx, http.MethodGet, "/api/v1/prompts?limit=20", nil, nil)
+	case scenarioGet:
+		// HTTP 接口没有按名称精确查询的端点，按名称搜索并只取一条，覆盖 GetByName 同样的索引路径。
+		query := url.Values{"search": {prompt.Name}, "limit": {"1"}, "count": {"none"}}
+		return c.do(ctx, http.MethodGet, "/api/v1/prompts?"+query.Encode(), nil, nil)
+	case scenarioRender:
+		payload := map[string]interface{}{
+			"variables": map[string]interface{}{
+				"name":  "loadgen",
+				"count": rng.IntN(100),
+				"items": []map[string]string{{"title": "alpha"}, {"title": "beta"}, {"title": "gamma"}},
+			},
+		}
+		return c.do(ctx, http.MethodPost, "/api/v1/prompts/"+prompt.ID+"/render", payload, nil)
+	case scenarioReport:
+		records := make([]map[string]interface{}, 0, reportBatch)
+		for i := 0; i < reportBatch; i++ {
+			status := "success"
+			if rng.IntN(20) == 0 {
+				status = "failed"
+			}
+			records = append(records, map[string]interface{}{
+				"prompt_id":   prompt.ID,
+				"version_id":  prompt.VersionID,
+				"status":      status,
+				"duration_ms": 50 + rng.IntN(950),
+			})
+		}
+		return c.do(ctx, http.MethodPost, "/api/v1/executions/batch", map[string]interface{}{"records": records}, nil)
+	default:
+		return fmt.Errorf("unknown scenario %q", scenario)
+	}
+}
+
+// parseWeights 解析 "list=4,get=4" 形式的场景权重，未列出的场景权重为 0。
+func parseWeights(spec string) (map[string]int, error) {
+	weights := make(map[string]int)
+	total := 0
+	err := parsePairs(spec, func(name, value string) error {
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 {
+			return fmt.Errorf("invalid weight %q for %s", value, name)
+		}
+		weights[name] = weight
+		total += weight
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, errors.New("at least one scenario needs a positive weight")
+	}
+	return weights, nil
+}
+
+// parseBudget 解析 "list=50ms,render=80ms" 形式的 p95 预算。
+func parseBudget(spec string) (map[string]time.Duration, error) {
+	budget := make(map[string]time.Duration)
+	err := parsePairs(spec, func(name, value string) error {
+		limit, err := time.ParseDuration(value)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid budget %q for %s", value, name)
+		}
+		budget[name] = limit
+		return nil
+	})
+	return budget, err
+}
+
+func parsePairs(spec string, fn func(name, value string) error) error {
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !knownScenario(name) {
+			return fmt.Errorf("invalid entry %q, expected <scenario>=<value> with scenario one of %s", pair, strings.Join(scenarioNames, ", "))
+		}
+		if err := fn(name, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func knownScenario(name string) bool {
+	for _, known := range scenarioNames {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// picker 按权重随机选择场景。
+type picker struct {
+	names   []string
+	weights []int
+	total   int
+}
+
+func newPicker(weights map[string]int) *picker {
+	p := &picker{}
+	for _, name := range scenarioNames {
+		if weight := weights[name]; weight > 0 {
+			p.names = append(p.names, name)
+			p.weights = append(p.weights, weight)
+			p.total += weight
+		}
+	}
+	return p
+}
+
+func (p *picker) pick(rng *rand.Rand) string {
+	n := rng.IntN(p.total)
+	for i, weight := range p.weights {
+		if n < weight {
+			return p.names[i]
+		}
+		n -= weight
+	}
+	return p.names[len(p.names)-1]
+}
+
+// recorder 收集单个 worker 的请求耗时，结束后合并，避免热路径加锁。
+type recorder struct {
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	samples   map[string]string
+}
+
+func newRecorder() *recorder {
+	return &recorder{latencies: make(map[string][]time.Duration), errors: make(map[string]int), samples: make(map[string]string)}
+}
+
+func (r *recorder) record(scenario string, elapsed time.Duration, err error) {
+	if err != nil {
+		r.errors[scenario]++
+		if _, ok := r.samples[scenario]; !ok {
+			r.samples[scenario] = err.Error()
+		}
+		return
+	}
+	r.latencies[scenario] = append(r.latencies[scenario], elapsed)
+}
+
+func (r *recorder) merge(other *recorder) {
+	for scenario, latencies := range other.latencies {
+		r.latencies[scenario] = append(r.latencies[scenario], latencies...)
+	}
+	for scenario, count := range other.errors {
+		r.errors[scenario] += count
+	}
+	for scenario, sample := range other.samples {
+		if _, ok := r.samples[scenario]; !ok {
+			r.samples[scenario] = sample
+		}
+	}
+}
+
+// ScenarioReport 为单个场景的统计结果，延迟只统计成功请求。
+type ScenarioReport struct {
+	Name        string        `json:"name"`
+	Requests    int           `json:"requests"`
+	Errors      int           `json:"errors"`
+	ErrorSample string        `json:"error_sample,omitempty"`
+	P50         time.Duration `json:"p50_ns"`
+	P95         time.Duration `json:"p95_ns"`
+	P99         time.Duration `json:"p99_ns"`
+	Max         time.Duration `json:"max_ns"`
+	Budget      time.Duration `json:"budget_ns,omitempty"`
+	OverBudget  bool          `json:"over_budget"`
+}
+
+// Report 为整次压测的结果。
+type Report struct {
+	Duration    time.Duration     `json:"duration_ns"`
+	Concurrency int               `json:"concurrency"`
+	Throughput  float64           `json:"throughput_rps"`
+	Scenarios   []*ScenarioReport `json:"scenarios"`
+}
+
+// OverBudget 返回 p95 超出预算的场景名称。
+func (r *Report) OverBudget() []string {
+	var names []string
+	for _, scenario := range r.Scenarios {
+		if scenario.OverBudget {
+			names = append(names, scenario.Name)
+		}
+	}
+	return names
+}
+
+// OverErrorRate 返回错误率超过 limit 的场景名称；延迟只统计成功请求，错误过多时分位数没有意义。
+func (r *Report) OverErrorRate(limit float64) []string {
+	var names []string
+	for _, scenario := range r.Scenarios {
+		if scenario.Requests > 0 && float64(scenario.Errors)/float64(scenario.Requests) > limit {
+			names = append(names, scenario.Name)
+		}
+	}
+	return names
+}
+
+func buildReport(rec *recorder, elapsed time.Duration, concurrency int, budget map[string]time.Duration) *Report {
+	report := &Report{Duration: elapsed, Concurrency: concurrency}
+	total := 0
+	for _, name := range scenarioNames {
+		latencies := rec.latencies[name]
+		errs := rec.errors[name]
+		if len(latencies) == 0 && errs == 0 {
+			continue
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		scenario := &ScenarioReport{
+			Name:        name,
+			Requests:    len(latencies) + errs,
+			Errors:      errs,
+			ErrorSample: rec.samples[name],
+			P50:         percentile(latencies, 0.50),
+			P95:         percentile(latencies, 0.95),
+			P99:         percentile(latencies, 0.99),
+			Budget:      budget[name],
+		}
+		if len(latencies) > 0 {
+			scenario.Max = latencies[len(latencies)-1]
+		}
+		// 没有成功样本时无法证明满足预算，同样视为超出。
+		scenario.OverBudget = scenario.Budget > 0 && (len(latencies) == 0 || scenario.P95 > scenario.Budget)
+		report.Scenarios = append(report.Scenarios, scenario)
+		total += scenario.Requests
+	}
+	if elapsed > 0 {
+		report.Throughput = float64(total) / elapsed.Seconds()
+	}
+	return report
+}
+
+// percentile 按最近秩法取已排序样本的分位数，无样本时返回 0。
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseWeightsAndBudget(t *testing.T) {
+	weights, err := parseWeights("list=3, render=1,get=0")
+	if err != nil {
+		t.Fatalf("parse weights: %v", err)
+	}
+	if weights[scenarioList] != 3 || weights[scenarioRender] != 1 || weights[scenarioReport] != 0 {
+		t.Fatalf("unexpected weights %v", weights)
+	}
+	for _, spec := range []string{"", "get=0", "list=-1", "list", "unknown=1"} {
+		if _, err := parseWeights(spec); err == nil {
+			t.Fatalf("expected error for mix %q", spec)
+		}
+	}
+
+	budget, err := parseBudget("list=50ms,report=1s")
+	if err != nil {
+		t.Fatalf("parse budget: %v", err)
+	}
+	if budget[scenarioList] != 50*time.Millisecond || budget[scenarioReport] != time.Second {
+		t.Fatalf("unexpected budget %v", budget)
+	}
+	if _, err := parseBudget("list=fast"); err == nil {
+		t.Fatalf("expected error for invalid duration")
+	}
+}
+
+func TestPercentileNearestRank(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	if got := percentile(samples, 0.95); got != 95*time.Millisecond {
+		t.Fatalf("expected p95 95ms, got %s", got)
+	}
+	if got := percentile(samples[:1], 0.99); got != time.Millisecond {
+		t.Fatalf("expected single sample, got %s", got)
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Fatalf("expected 0 without samples, got %s", got)
+	}
+}
+
+func TestRunReportsScenariosAndBudget(t *testing.T) {
+	var created, deleted, rendered, reported atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/prompts":
+			n := created.Add(1)
+			id := "p" + strings.Repeat("x", int(n))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+				"prompt": map[string]interface{}{"id": id, "name": id, "active_version_id": "v-" + id},
+			}})
+		case r.Method == http.MethodDelete:
+			deleted.Add(1)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/prompts":
+			_, _ = w.Write([]byte(`{"data":{"items":[]}}`))
+		case strings.HasSuffix(r.URL.Path, "/render"):
+			rendered.Add(1)
+			// 渲染场景刻意放慢，用于触发预算检查。
+			time.Sleep(5 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"data":{"output":"ok"}}`))
+		case r.URL.Path == "/api/v1/executions/batch":
+			var payload struct {
+				Records []map[string]interface{} `json:"records"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || len(payload.Records) != 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			reported.Add(1)
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := &client{baseURL: server.URL, http: server.Client(), header: http.Header{"X-Api-Key": {"key"}}}
+	ctx := context.Background()
+	prompts, err := c.seed(ctx, "loadgen-test", 3)
+	if err != nil || len(prompts) != 3 || prompts[0].VersionID == "" {
+		t.Fatalf("seed prompts: %v (%d)", err, len(prompts))
+	}
+
+	opts := options{Duration: 200 * time.Millisecond, Concurrency: 2, ReportBatch: 2}
+	weights := map[string]int{scenarioList: 1, scenarioGet: 1, scenarioRender: 1, scenarioReport: 1}
+	budget := map[string]time.Duration{scenarioRender: time.Millisecond, scenarioList: time.Minute}
+	report := run(ctx, c, prompts, newPicker(weights), opts, budget)
+
+	if len(report.Scenarios) != 4 || report.Throughput <= 0 {
+		t.Fatalf("expected all scenarios in report, got %+v", report)
+	}
+	for _, scenario := range report.Scenarios {
+		if scenario.Requests == 0 || scenario.Errors != 0 || scenario.P95 < scenario.P50 || scenario.Max < scenario.P99 {
+			t.Fatalf("unexpected stats for %s: %+v", scenario.Name, scenario)
+		}
+	}
+	if over := report.OverBudget(); len(over) != 1 || over[0] != scenarioRender {
+		t.Fatalf("expected only render over budget, got %v", over)
+	}
+	if failing := report.OverErrorRate(0); len(failing) != 0 {
+		t.Fatalf("expected no failing scenarios, got %v", failing)
+	}
+	if rendered.Load() == 0 || reported.Load() == 0 {
+		t.Fatalf("expected render and report requests, got %d and %d", rendered.Load(), reported.Load())
+	}
+
+	if err := c.cleanup(ctx, prompts); err != nil || deleted.Load() != 3 {
+		t.Fatalf("cleanup: %v (%d deleted)", err, deleted.Load())
+	}
+
+	// 全部请求失败时没有延迟样本，预算与错误率检查都应失败。
+	c.header = http.Header{}
+	if _, err := c.seed(ctx, "unauthorized", 1); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	opts.Duration = 50 * time.Millisecond
+	report = run(ctx, c, prompts, newPicker(map[string]int{scenarioList: 1}), opts, budget)
+	if len(report.Scenarios) != 1 || report.Scenarios[0].Errors != report.Scenarios[0].Requests || report.Scenarios[0].ErrorSample == "" {
+		t.Fatalf("expected only failed list requests, got %+v", report.Scenarios)
+	}
+	if over := report.OverBudget(); len(over) != 1 || over[0] != scenarioList {
+		t.Fatalf("expected list without samples to be over budget, got %v", over)
+	}
+	if failing := report.OverErrorRate(0.5); len(failing) != 1 {
+		t.Fatalf("expected list to exceed error rate, got %v", failing)
+	}
+}
@@ -1,24 +1,13 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	_ "time/tzdata" // 运行镜像不含时区库，统计接口的 tz 参数依赖内嵌数据。
 
-	"github.com/gin-gonic/gin"
 	"github.com/spf13/pflag"
-	"github.com/ulule/limiter/v3"
-	memorystore "github.com/ulule/limiter/v3/drivers/store/memory"
-	"github.com/zacharykka/prompt-manager/internal/app"
+	"github.com/zacharykka/prompt-manager/internal/bootstrap"
 	"github.com/zacharykka/prompt-manager/internal/config"
-	"github.com/zacharykka/prompt-manager/internal/infra"
-	"github.com/zacharykka/prompt-manager/internal/middleware"
-	httpserver "github.com/zacharykka/prompt-manager/internal/server/http"
-	"github.com/zacharykka/prompt-manager/internal/service/auth"
-	"github.com/zacharykka/prompt-manager/internal/service/prompt"
 	"github.com/zacharykka/prompt-manager/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -41,67 +30,34 @@ func main() {
 		_ = log.Sync()
 	}()
 
-	initCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	infraContainer, cleanup, err := infra.Initialize(initCtx, cfg, log)
-	cancel()
-	if err != nil {
+	// 依赖按模块装配（见 internal/bootstrap），Run 阻塞至收到 SIGINT/SIGTERM 后按注册的逆序停止各组件。
+	application := bootstrap.New(cfg, log, opts.Mode)
+	if err := application.Err(); err != nil {
 		log.Fatal("依赖初始化失败", zap.Error(err))
 	}
-
-	defer func() {
-		if cleanup == nil {
-			return
-		}
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := cleanup(shutdownCtx); err != nil {
-			log.Warn("资源清理失败", zap.Error(err))
-		}
-	}()
-
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	authService := auth.NewService(infraContainer.Repos, cfg.Auth)
-	authHandler := httpserver.NewAuthHandler(authService)
-	promptService := prompt.NewService(infraContainer.Repos)
-	promptHandler := httpserver.NewPromptHandler(promptService)
-
-	store := memorystore.NewStore()
-	generalLimiter := middleware.RateLimit(limiter.New(store, limiter.Rate{Period: time.Minute, Limit: 120}), middleware.KeyByClientIP())
-	loginLimiter := middleware.RateLimit(limiter.New(store, limiter.Rate{Period: time.Minute, Limit: 10}), middleware.KeyByClientIP())
-
-	engine := httpserver.NewEngine(cfg, log, httpserver.RouterOptions{
-		Middlewares: []gin.HandlerFunc{
-			middleware.RequestLogger(log),
-		},
-		HealthDeps: &httpserver.HealthDependencies{
-			DB:    infraContainer.DB,
-			Redis: infraContainer.Redis,
-		},
-		AuthHandler:    authHandler,
-		PromptHandler:  promptHandler,
-		RateLimiter:    generalLimiter,
-		LoginRateLimit: loginLimiter,
-	})
-
-	application := app.New(cfg, log, engine)
-
-	if err := application.Run(ctx); err != nil {
-		log.Fatal("服务运行异常", zap.Error(err))
-	}
+	log.Info("prompt manager starting", zap.String("mode", string(opts.Mode)), zap.String("executionLogs", cfg.ExecutionLogs.Mode))
+	application.Run()
 }
 
 // options 控制命令行参数。
 type options struct {
 	ConfigDir string
 	Env       string
+	Mode      bootstrap.Mode
 }
 
 func parseFlags() options {
 	var opts options
 	pflag.StringVar(&opts.ConfigDir, "config-dir", "./config", "配置文件目录")
 	pflag.StringVar(&opts.Env, "env", "", "强制指定运行环境，覆盖 PROMPT_MANAGER_ENV")
+	mode := pflag.String("mode", string(bootstrap.ModeAll), "运行模式：all、api 或 worker")
 	pflag.Parse()
+	opts.Mode = bootstrap.Mode(*mode)
+	switch opts.Mode {
+	case bootstrap.ModeAll, bootstrap.ModeAPI, bootstrap.ModeWorker:
+	default:
+		fmt.Fprintf(os.Stderr, "未知的运行模式 %q，可选 all、api、worker\n", opts.Mode)
+		os.Exit(2)
+	}
 	return opts
 }
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS prompt_version_locales (
+    id TEXT PRIMARY KEY,
+    version_id TEXT NOT NULL,
+    locale TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(version_id, locale),
+    FOREIGN KEY (version_id) REFERENCES prompt_versions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS prompt_version_locales_locale_idx ON prompt_version_locales(locale);
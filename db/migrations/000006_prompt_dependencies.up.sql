@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS prompt_dependencies (
+    prompt_id TEXT NOT NULL,
+    depends_on_id TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'explicit',
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (prompt_id, depends_on_id, source),
+    FOREIGN KEY (prompt_id) REFERENCES prompts(id) ON DELETE CASCADE,
+    FOREIGN KEY (depends_on_id) REFERENCES prompts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS prompt_dependencies_depends_on_idx ON prompt_dependencies(depends_on_id);
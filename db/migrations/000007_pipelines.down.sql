@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pipeline_versions;
+DROP TABLE IF EXISTS pipelines;
@@ -0,0 +1 @@
+ALTER TABLE prompts DROP COLUMN render_mode;
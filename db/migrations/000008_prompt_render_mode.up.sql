@@ -0,0 +1 @@
+ALTER TABLE prompts ADD COLUMN render_mode TEXT;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id TEXT PRIMARY KEY,
+    algorithm TEXT NOT NULL,
+    encrypted_key TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    retires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS signing_keys_status_idx ON signing_keys(status, created_at DESC);
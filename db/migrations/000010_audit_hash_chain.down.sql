@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS prompt_audit_logs_seq_idx;
+ALTER TABLE prompt_audit_logs DROP COLUMN hash;
+ALTER TABLE prompt_audit_logs DROP COLUMN prev_hash;
+ALTER TABLE prompt_audit_logs DROP COLUMN seq;
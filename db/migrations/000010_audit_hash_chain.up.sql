@@ -0,0 +1,5 @@
+ALTER TABLE prompt_audit_logs ADD COLUMN seq BIGINT;
+ALTER TABLE prompt_audit_logs ADD COLUMN prev_hash TEXT;
+ALTER TABLE prompt_audit_logs ADD COLUMN hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS prompt_audit_logs_seq_idx ON prompt_audit_logs(seq);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS audit_logs_target_idx;
+DROP INDEX IF EXISTS audit_logs_actor_idx;
+DROP INDEX IF EXISTS audit_logs_action_idx;
+DROP INDEX IF EXISTS audit_logs_seq_idx;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id TEXT PRIMARY KEY,
+    action TEXT NOT NULL,
+    actor TEXT,
+    target_type TEXT NOT NULL DEFAULT '',
+    target_id TEXT,
+    ip TEXT,
+    user_agent TEXT,
+    payload TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT,
+    prev_hash TEXT,
+    hash TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS audit_logs_seq_idx ON audit_logs(seq);
+CREATE INDEX IF NOT EXISTS audit_logs_action_idx ON audit_logs(action, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_idx ON audit_logs(actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_logs_target_idx ON audit_logs(target_type, target_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS prompts_workspace_idx;
+ALTER TABLE prompts DROP COLUMN workspace_id;
+
+DROP INDEX IF EXISTS workspace_members_user_idx;
+DROP TABLE IF EXISTS workspace_members;
+DROP INDEX IF EXISTS workspaces_org_slug_idx;
+DROP TABLE IF EXISTS workspaces;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS invitations_pending_idx;
+DROP INDEX IF EXISTS invitations_email_idx;
+DROP TABLE IF EXISTS invitations;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS invitations (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    workspace_id TEXT NOT NULL DEFAULT 'default',
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_user_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS invitations_email_idx ON invitations(email);
+CREATE INDEX IF NOT EXISTS invitations_pending_idx ON invitations(accepted_at, expires_at);
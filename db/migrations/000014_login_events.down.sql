@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS login_events_user_device_idx;
+DROP INDEX IF EXISTS login_events_user_created_idx;
+DROP TABLE IF EXISTS login_events;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS login_events (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    device_hash TEXT NOT NULL,
+    new_device BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS login_events_user_created_idx ON login_events(user_id, created_at);
+CREATE INDEX IF NOT EXISTS login_events_user_device_idx ON login_events(user_id, device_hash);
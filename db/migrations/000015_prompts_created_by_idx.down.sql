@@ -0,0 +1 @@
+DROP INDEX IF EXISTS prompts_created_by_idx;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS prompts_created_by_idx ON prompts(created_by, updated_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE prompt_execution_logs DROP COLUMN error_code;
+ALTER TABLE prompt_execution_logs DROP COLUMN error_class;
@@ -0,0 +1,2 @@
+ALTER TABLE prompt_execution_logs ADD COLUMN error_class TEXT;
+ALTER TABLE prompt_execution_logs ADD COLUMN error_code TEXT;
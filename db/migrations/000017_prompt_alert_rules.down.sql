@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS prompt_alert_rules_enabled_idx;
+DROP INDEX IF EXISTS prompt_alert_rules_prompt_idx;
+DROP TABLE IF EXISTS prompt_alert_rules;
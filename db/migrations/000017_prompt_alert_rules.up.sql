@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS prompt_alert_rules (
+    id TEXT PRIMARY KEY,
+    prompt_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    window_minutes INTEGER NOT NULL,
+    webhook_url TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    state TEXT NOT NULL DEFAULT 'ok',
+    last_value DOUBLE PRECISION,
+    last_evaluated_at TIMESTAMP,
+    state_changed_at TIMESTAMP,
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (prompt_id) REFERENCES prompts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS prompt_alert_rules_prompt_idx ON prompt_alert_rules(prompt_id);
+CREATE INDEX IF NOT EXISTS prompt_alert_rules_enabled_idx ON prompt_alert_rules(enabled);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS api_keys_user_idx;
+DROP TABLE IF EXISTS api_keys;
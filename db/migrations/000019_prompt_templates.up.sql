@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS prompt_templates (
+    id TEXT PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    description TEXT,
+    body TEXT NOT NULL,
+    variables_schema TEXT,
+    metadata TEXT,
+    tags TEXT,
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO prompt_templates (id, slug, name, description, body, variables_schema, tags) VALUES (
+    'builtin-rag-qa',
+    'rag-qa',
+    'RAG 问答',
+    '基于检索到的上下文回答问题，上下文不足时明确说明。',
+    '你是一名严谨的问答助手。请仅根据以下上下文回答问题，上下文中没有答案时回答“根据现有资料无法回答”。
+
+## 上下文
+{{context}}
+
+## 问题
+{{question}}
+
+## 回答要求
+- 使用与问题相同的语言
+- 引用上下文中的关键信息',
+    '{"type":"object","properties":{"context":{"type":"string"},"question":{"type":"string"}},"required":["context","question"]}',
+    '["rag","qa"]'
+);
+
+INSERT INTO prompt_templates (id, slug, name, description, body, variables_schema, tags) VALUES (
+    'builtin-summarizer',
+    'summarizer',
+    '摘要生成',
+    '把长文本压缩为指定长度的要点摘要。',
+    '请把下面的内容总结为不超过 {{max_words}} 字的摘要，保留关键事实与结论，使用要点列表输出。
+
+## 内容
+{{text}}',
+    '{"type":"object","properties":{"text":{"type":"string"},"max_words":{"type":"integer"}},"required":["text"]}',
+    '["summarization"]'
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS prompt_drafts_user_idx;
+DROP TABLE IF EXISTS prompt_drafts;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS prompt_drafts (
+    prompt_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    body TEXT NOT NULL,
+    variables_schema TEXT,
+    metadata TEXT,
+    base_version_id TEXT,
+    revision INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (prompt_id, user_id),
+    FOREIGN KEY (prompt_id) REFERENCES prompts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS prompt_drafts_user_idx ON prompt_drafts(user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS prompts_owner_idx;
+ALTER TABLE prompts DROP COLUMN owner_id;
+ALTER TABLE prompts DROP COLUMN owner_type;
@@ -0,0 +1,4 @@
+ALTER TABLE prompts ADD COLUMN owner_type TEXT;
+ALTER TABLE prompts ADD COLUMN owner_id TEXT;
+
+CREATE INDEX IF NOT EXISTS prompts_owner_idx ON prompts(owner_type, owner_id);
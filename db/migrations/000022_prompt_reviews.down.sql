@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS prompt_version_approvals_prompt_idx;
+DROP TABLE IF EXISTS prompt_version_approvals;
+DROP TABLE IF EXISTS prompt_review_policies;
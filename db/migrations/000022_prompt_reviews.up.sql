@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS prompt_review_policies (
+    prompt_id TEXT PRIMARY KEY,
+    required_approvals INTEGER NOT NULL DEFAULT 1,
+    reviewers TEXT,
+    updated_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (prompt_id) REFERENCES prompts(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS prompt_version_approvals (
+    version_id TEXT NOT NULL,
+    prompt_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL,
+    reviewer TEXT,
+    comment TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version_id, reviewer_id),
+    FOREIGN KEY (prompt_id) REFERENCES prompts(id) ON DELETE CASCADE,
+    FOREIGN KEY (version_id) REFERENCES prompt_versions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS prompt_version_approvals_prompt_idx ON prompt_version_approvals(prompt_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS audit_logs_created_idx;
+DROP INDEX IF EXISTS prompt_versions_status_idx;
+DROP INDEX IF EXISTS prompts_deleted_updated_idx;
@@ -0,0 +1,15 @@
+-- 针对实际查询形态补充的复合索引。以下查询已有合适索引，无需重复创建：
+--   版本列表/最新版本  WHERE prompt_id = ? ORDER BY version_number DESC  -> prompt_versions_unique_version
+--   执行日志与统计      WHERE prompt_id = ? AND created_at >= ?            -> prompt_execution_logs_lookup_idx
+--   Prompt 审计日志     WHERE prompt_id = ? ORDER BY created_at DESC       -> prompt_audit_logs_prompt_idx
+--   工作区内列表        WHERE workspace_id = ? ORDER BY updated_at DESC    -> prompts_workspace_idx
+
+-- 未限定工作区的 Prompt 列表：WHERE deleted_at IS NULL ORDER BY updated_at DESC LIMIT ?，
+-- 按索引顺序读取即可，避免全表扫描后排序。
+CREATE INDEX IF NOT EXISTS prompts_deleted_updated_idx ON prompts(deleted_at, updated_at DESC);
+
+-- 按状态过滤的版本列表：WHERE prompt_id = ? AND status = ? ORDER BY version_number DESC。
+CREATE INDEX IF NOT EXISTS prompt_versions_status_idx ON prompt_versions(prompt_id, status, version_number DESC);
+
+-- 全局审计日志列表：ORDER BY created_at DESC, seq DESC LIMIT ?。
+CREATE INDEX IF NOT EXISTS audit_logs_created_idx ON audit_logs(created_at DESC, seq DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS usage_counters_period_idx;
+DROP TABLE IF EXISTS usage_exports;
+DROP TABLE IF EXISTS usage_counters;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS usage_counters (
+    workspace_id TEXT NOT NULL,
+    period TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, period, metric)
+);
+
+CREATE TABLE IF NOT EXISTS usage_exports (
+    organization_id TEXT NOT NULL,
+    period TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    exporter TEXT NOT NULL,
+    value BIGINT NOT NULL,
+    exported_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, period, metric, exporter)
+);
+
+CREATE INDEX IF NOT EXISTS usage_counters_period_idx ON usage_counters(period);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS announcements_window_idx;
+DROP TABLE IF EXISTS announcements;
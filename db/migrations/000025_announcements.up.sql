@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS announcements (
+    id TEXT PRIMARY KEY,
+    message TEXT NOT NULL,
+    severity TEXT NOT NULL DEFAULT 'info',
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS announcements_window_idx ON announcements(starts_at, ends_at);
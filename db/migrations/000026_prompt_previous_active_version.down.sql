@@ -0,0 +1 @@
+ALTER TABLE prompts DROP COLUMN previous_active_version_id;
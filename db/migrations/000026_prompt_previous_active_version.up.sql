@@ -0,0 +1 @@
+ALTER TABLE prompts ADD COLUMN previous_active_version_id TEXT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS prompt_canaries_prompt_idx;
+DROP INDEX IF EXISTS prompt_canaries_running_idx;
+DROP TABLE IF EXISTS prompt_canaries;
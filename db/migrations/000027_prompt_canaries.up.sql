@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS prompt_canaries (
+    id TEXT PRIMARY KEY,
+    prompt_id TEXT NOT NULL,
+    version_id TEXT NOT NULL,
+    baseline_version_id TEXT NOT NULL,
+    percent INTEGER NOT NULL,
+    max_error_rate_delta DOUBLE PRECISION NOT NULL,
+    min_calls INTEGER NOT NULL,
+    window_minutes INTEGER NOT NULL,
+    webhook_url TEXT,
+    status TEXT NOT NULL DEFAULT 'running',
+    canary_error_rate DOUBLE PRECISION,
+    baseline_error_rate DOUBLE PRECISION,
+    reason TEXT,
+    started_by TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_evaluated_at TIMESTAMP,
+    ended_at TIMESTAMP,
+    FOREIGN KEY (prompt_id) REFERENCES prompts(id) ON DELETE CASCADE,
+    FOREIGN KEY (version_id) REFERENCES prompt_versions(id) ON DELETE CASCADE
+);
+
+-- 每个 Prompt 同一时刻最多一个进行中的灰度。
+CREATE UNIQUE INDEX IF NOT EXISTS prompt_canaries_running_idx ON prompt_canaries(prompt_id) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS prompt_canaries_prompt_idx ON prompt_canaries(prompt_id, started_at DESC);
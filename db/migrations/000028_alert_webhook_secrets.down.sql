@@ -0,0 +1,3 @@
+ALTER TABLE prompt_alert_rules DROP COLUMN previous_secret_expires_at;
+ALTER TABLE prompt_alert_rules DROP COLUMN previous_webhook_secret;
+ALTER TABLE prompt_alert_rules DROP COLUMN webhook_secret;
@@ -0,0 +1,3 @@
+ALTER TABLE prompt_alert_rules ADD COLUMN webhook_secret TEXT;
+ALTER TABLE prompt_alert_rules ADD COLUMN previous_webhook_secret TEXT;
+ALTER TABLE prompt_alert_rules ADD COLUMN previous_secret_expires_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- 版本声明的目标模型或模型系列（JSON 数组），由 metadata.target_models 规范化而来，用于按模型筛选 Prompt。
+ALTER TABLE prompt_versions ADD COLUMN target_models TEXT;
@@ -0,0 +1,2 @@
+-- 单个 API Key 每 24 小时的请求配额，0 表示不限。
+ALTER TABLE api_keys ADD COLUMN daily_quota INTEGER NOT NULL DEFAULT 0;
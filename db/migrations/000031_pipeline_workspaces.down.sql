@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS pipelines_workspace_idx;
+ALTER TABLE pipelines DROP COLUMN workspace_id;
@@ -0,0 +1,3 @@
+-- Pipeline 归属工作区，历史数据归入默认工作区。
+ALTER TABLE pipelines ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS pipelines_workspace_idx ON pipelines(workspace_id, updated_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS signing_keys_org_status_idx;
+ALTER TABLE signing_keys DROP COLUMN organization_id;
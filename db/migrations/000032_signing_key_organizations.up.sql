@@ -0,0 +1,3 @@
+-- 签名密钥归属组织，每个组织各有当前密钥；历史密钥归入默认组织。
+ALTER TABLE signing_keys ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS signing_keys_org_status_idx ON signing_keys(organization_id, status);
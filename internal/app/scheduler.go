@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/pkg/distlock"
+	"go.uber.org/zap"
+)
+
+// Job 描述按固定间隔运行的后台任务。配置了 Locker 时，Run 的上下文携带本次租约的
+// fencing token（见 distlock.TokenFromContext），任务可将其随写入传给下游以拒绝过期持有者。
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// SchedulerOption 调整调度器行为。
+type SchedulerOption func(*Scheduler)
+
+// WithLocker 使每次执行前先获取以任务名命名的分布式租约，多副本部署时同一周期内只有一个实例执行。
+func WithLocker(locker *distlock.Locker) SchedulerOption {
+	return func(s *Scheduler) {
+		s.locker = locker
+	}
+}
+
+// Scheduler 在进程内周期执行后台任务，随上下文取消退出。
+type Scheduler struct {
+	logger *zap.Logger
+	locker *distlock.Locker
+	jobs   []Job
+}
+
+// NewScheduler 创建调度器。
+func NewScheduler(logger *zap.Logger, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{logger: logger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Every 注册每隔 interval 执行一次的任务。
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start 为每个任务启动独立的 goroutine；同一任务不会并发执行，失败只记录日志。
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.runJob(ctx, job); err != nil && ctx.Err() == nil {
+				s.logger.Warn("scheduled job failed", zap.String("job", job.Name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// runJob 执行一次任务。配置了 Locker 时先获取租约：租约时长略短于间隔，执行结束后不主动释放，
+// 使其他副本在本周期内的触发被跳过，下个周期由最先触发的副本接手；执行期间定期续约，
+// 续约失败（租约被他人取得）时取消任务上下文，避免两个实例同时执行。
+func (s *Scheduler) runJob(ctx context.Context, job Job) error {
+	if s.locker == nil {
+		return job.Run(ctx)
+	}
+	lease, err := s.locker.Acquire(ctx, "job:"+job.Name, job.Interval*9/10)
+	if errors.Is(err, distlock.ErrNotAcquired) {
+		s.logger.Debug("scheduled job skipped; held by another replica", zap.String("job", job.Name))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(distlock.WithToken(ctx, lease.Token))
+	defer cancel()
+	go s.keepAlive(runCtx, cancel, job, lease)
+	s.logger.Debug("scheduled job started", zap.String("job", job.Name), zap.Int64("fencing_token", lease.Token))
+	return job.Run(runCtx)
+}
+
+func (s *Scheduler) keepAlive(ctx context.Context, cancel context.CancelFunc, job Job, lease *distlock.Lease) {
+	ticker := time.NewTicker(lease.TTL() / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := lease.Refresh(ctx)
+			if errors.Is(err, distlock.ErrLeaseLost) {
+				s.logger.Warn("scheduled job lease lost; cancelling", zap.String("job", job.Name), zap.Int64("fencing_token", lease.Token))
+				cancel()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				s.logger.Warn("refresh scheduled job lease failed", zap.String("job", job.Name), zap.Error(err))
+			}
+		}
+	}
+}
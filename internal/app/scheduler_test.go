@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/pkg/distlock"
+	"github.com/zacharykka/prompt-manager/pkg/distlock/distlocktest"
+	"go.uber.org/zap"
+)
+
+func TestRunJobWithoutLocker(t *testing.T) {
+	scheduler := NewScheduler(zap.NewNop())
+	ran := false
+	job := Job{Name: "plain", Interval: time.Second, Run: func(ctx context.Context) error {
+		if _, ok := distlock.TokenFromContext(ctx); ok {
+			t.Errorf("expected no fencing token without a locker")
+		}
+		ran = true
+		return nil
+	}}
+	if err := scheduler.runJob(context.Background(), job); err != nil || !ran {
+		t.Fatalf("expected job to run, ran=%v err=%v", ran, err)
+	}
+}
+
+func TestRunJobPassesIncreasingFencingTokens(t *testing.T) {
+	store := distlocktest.NewStore()
+	scheduler := NewScheduler(zap.NewNop(), WithLocker(distlock.New(store)))
+
+	var tokens []int64
+	job := Job{Name: "archive", Interval: time.Minute, Run: func(ctx context.Context) error {
+		token, ok := distlock.TokenFromContext(ctx)
+		if !ok {
+			t.Errorf("expected fencing token on job context")
+		}
+		tokens = append(tokens, token)
+		return nil
+	}}
+
+	for i := 0; i < 2; i++ {
+		if err := scheduler.runJob(context.Background(), job); err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		// 租约在执行结束后保留到过期，推进时钟模拟进入下一个周期。
+		store.Advance(job.Interval)
+	}
+	if len(tokens) != 2 || tokens[1] <= tokens[0] {
+		t.Fatalf("expected increasing fencing tokens got %v", tokens)
+	}
+}
+
+func TestRunJobSkipsWhenAnotherReplicaHoldsLease(t *testing.T) {
+	store := distlocktest.NewStore()
+	other := distlock.New(store)
+	if _, err := other.Acquire(context.Background(), "job:archive", time.Minute); err != nil {
+		t.Fatalf("acquire on other replica: %v", err)
+	}
+
+	scheduler := NewScheduler(zap.NewNop(), WithLocker(distlock.New(store)))
+	ran := false
+	job := Job{Name: "archive", Interval: time.Minute, Run: func(context.Context) error {
+		ran = true
+		return nil
+	}}
+	if err := scheduler.runJob(context.Background(), job); err != nil {
+		t.Fatalf("skipped run should not fail: %v", err)
+	}
+	if ran {
+		t.Fatalf("job must not run while another replica holds the lease")
+	}
+
+	// 本次执行不会释放或续期他人的租约。
+	if _, err := other.Acquire(context.Background(), "job:archive", time.Minute); !errors.Is(err, distlock.ErrNotAcquired) {
+		t.Fatalf("other replica's lease should remain held, got %v", err)
+	}
+}
+
+func TestRunJobCancelledWhenLeaseLost(t *testing.T) {
+	store := distlocktest.NewStore()
+	scheduler := NewScheduler(zap.NewNop(), WithLocker(distlock.New(store)))
+
+	started := make(chan struct{})
+	job := Job{Name: "archive", Interval: 300 * time.Millisecond, Run: func(ctx context.Context) error {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return errors.New("job was not cancelled after losing its lease")
+		}
+	}}
+
+	done := make(chan error, 1)
+	go func() { done <- scheduler.runJob(context.Background(), job) }()
+	<-started
+
+	// 租约被清除后由另一副本取得，下一次续约失败应取消正在执行的任务。
+	store.Evict("prompt-manager:lock:job:archive")
+	if _, err := distlock.New(store).Acquire(context.Background(), "job:archive", time.Minute); err != nil {
+		t.Fatalf("acquire on other replica: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected job context to be cancelled got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("runJob did not return after the lease was lost")
+	}
+}
+
+func TestRunJobKeepsLeaseAliveWhileRunning(t *testing.T) {
+	store := distlocktest.NewStore()
+	scheduler := NewScheduler(zap.NewNop(), WithLocker(distlock.New(store)))
+
+	job := Job{Name: "archive", Interval: 300 * time.Millisecond, Run: func(ctx context.Context) error {
+		// 执行时长超过租约 TTL（270ms），期间依赖续约保持持有。
+		for i := 0; i < 4; i++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(100 * time.Millisecond):
+			}
+			store.Advance(100 * time.Millisecond)
+		}
+		if _, err := distlock.New(store).Acquire(ctx, "job:archive", time.Minute); !errors.Is(err, distlock.ErrNotAcquired) {
+			t.Errorf("lease should still be held after its TTL elapsed, got %v", err)
+		}
+		return nil
+	}}
+	if err := scheduler.runJob(context.Background(), job); err != nil {
+		t.Fatalf("job holding a refreshed lease should complete: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+// Package bootstrap 以 fx 组装应用的依赖图（composition root）：各子系统以 fx.Module 注册构造函数与
+// 生命周期钩子，cmd/server 只负责加载配置并按运行模式选择模块；测试可只装配部分模块并注入替身依赖。
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/config"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Mode 为进程运行模式：all 同时提供 HTTP 接口与后台任务，api 只提供 HTTP 接口，
+// worker 只运行后台任务（执行日志消费、告警评估与 webhook 推送、版本清理、计费推送、匿名统计上报）。
+type Mode string
+
+const (
+	ModeAll    Mode = "all"
+	ModeAPI    Mode = "api"
+	ModeWorker Mode = "worker"
+)
+
+// RunAPI 表示该模式下提供 HTTP 接口。
+func (m Mode) RunAPI() bool {
+	return m != ModeWorker
+}
+
+// RunWorker 表示该模式下运行后台任务。
+func (m Mode) RunWorker() bool {
+	return m != ModeAPI
+}
+
+// JobsGroup 为后台任务的 fx 值分组，见 AsJobs。
+const JobsGroup = `group:"jobs"`
+
+// AsJobs 将返回 []app.Job 的构造函数注册到后台任务分组，worker 模块统一调度；
+// 新子系统只需提供自己的任务，无需改动调度器的装配。
+func AsJobs(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"jobs,flatten"`))
+}
+
+// Modules 返回按运行模式组装的全部模块。
+func Modules(mode Mode) fx.Option {
+	options := []fx.Option{Infra, Services}
+	if mode.RunAPI() {
+		options = append(options, HTTP)
+	}
+	if mode.RunWorker() {
+		options = append(options, Worker)
+	}
+	return fx.Options(options...)
+}
+
+// New 以已加载的配置与日志创建应用；依赖初始化失败时通过 Err 返回。
+func New(cfg *config.Config, logger *zap.Logger, mode Mode, extra ...fx.Option) *fx.App {
+	options := []fx.Option{
+		fx.Supply(cfg, logger, mode),
+		fx.WithLogger(func(logger *zap.Logger) fxevent.Logger {
+			fxLogger := &fxevent.ZapLogger{Logger: logger.Named("fx")}
+			fxLogger.UseLogLevel(zapcore.DebugLevel)
+			return fxLogger
+		}),
+		// 停止阶段依次停止 HTTP 服务、排空各队列并关闭连接，每步最多 shutdownTimeout。
+		fx.StopTimeout(3*cfg.Server.ShutdownTimeout + 5*time.Second),
+		Modules(mode),
+	}
+	return fx.New(append(options, extra...)...)
+}
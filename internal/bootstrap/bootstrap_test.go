@@ -0,0 +1,73 @@
+package bootstrap
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/app"
+	"github.com/zacharykka/prompt-manager/internal/config"
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	"github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+func TestModulesGraphIsComplete(t *testing.T) {
+	cfg := &config.Config{}
+	for _, mode := range []Mode{ModeAll, ModeAPI, ModeWorker} {
+		err := fx.ValidateApp(
+			fx.Supply(cfg, zap.NewNop(), mode),
+			fx.WithLogger(func() fxevent.Logger { return fxevent.NopLogger }),
+			Modules(mode),
+		)
+		if err != nil {
+			t.Fatalf("mode %s: %v", mode, err)
+		}
+	}
+}
+
+func TestServicesModuleWithSuppliedContainer(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	container := &infra.Container{
+		DB:       db,
+		Repos:    repository.NewSQLRepositories(db, database.NewDialect("sqlite")),
+		Degraded: true,
+	}
+	cfg := &config.Config{Prompts: config.PromptsConfig{MaxVersions: 10, RetentionInterval: time.Hour, ListCache: true}}
+
+	var (
+		promptService *prompt.Service
+		jobs          []app.Job
+	)
+	app := fx.New(
+		fx.Supply(cfg, zap.NewNop(), container),
+		fx.Provide(func(c *infra.Container) *domain.Repositories { return c.Repos }),
+		fx.WithLogger(func() fxevent.Logger { return fxevent.NopLogger }),
+		Services,
+		fx.Populate(&promptService, fx.Annotate(&jobs, fx.ParamTags(JobsGroup))),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatalf("assemble services: %v", err)
+	}
+	if promptService == nil {
+		t.Fatalf("expected prompt service")
+	}
+	names := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		names[job.Name] = true
+	}
+	if len(jobs) != 3 || !names["prompt-alerts"] || !names["prompt-canaries"] || !names["prompt-version-retention"] {
+		t.Fatalf("unexpected jobs %v", names)
+	}
+}
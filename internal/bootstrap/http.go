@@ -0,0 +1,224 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ulule/limiter/v3"
+	memorystore "github.com/ulule/limiter/v3/drivers/store/memory"
+	"github.com/zacharykka/prompt-manager/internal/app"
+	"github.com/zacharykka/prompt-manager/internal/config"
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra"
+	"github.com/zacharykka/prompt-manager/internal/infra/cache"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	httpserver "github.com/zacharykka/prompt-manager/internal/server/http"
+	"github.com/zacharykka/prompt-manager/internal/service/auth"
+	"github.com/zacharykka/prompt-manager/internal/service/gitsync"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+	"github.com/zacharykka/prompt-manager/internal/service/metering"
+	"github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/internal/service/slack"
+	"github.com/zacharykka/prompt-manager/internal/service/workspace"
+	"github.com/zacharykka/prompt-manager/pkg/metrics"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// HTTP 提供 HTTP 接口：各 Handler、Gin 引擎与 HTTP 服务的生命周期。
+var HTTP = fx.Module("http",
+	fx.Provide(
+		// Handler 依赖服务接口，这里绑定到具体实现。
+		func(s *auth.Service) httpserver.AuthService { return s },
+		func(s *prompt.Service) httpserver.PromptService { return s },
+		httpserver.NewAuthHandler,
+		httpserver.NewPipelineHandler,
+		httpserver.NewAuditHandler,
+		httpserver.NewWorkspaceHandler,
+		httpserver.NewTelemetryHandler,
+		httpserver.NewAnnouncementHandler,
+		newPromptHandler,
+		newEngine,
+	),
+	fx.Invoke(
+		startSecretRotation,
+		runServer,
+	),
+)
+
+func newPromptHandler(cfg *config.Config, promptService httpserver.PromptService) *httpserver.PromptHandler {
+	return httpserver.NewPromptHandler(promptService, httpserver.WithUploadLimit(cfg.Server.BodyLimits.Prompts))
+}
+
+// engineParams 汇总路由所需的依赖。
+type engineParams struct {
+	fx.In
+
+	Lifecycle        fx.Lifecycle
+	Config           *config.Config
+	Logger           *zap.Logger
+	Container        *infra.Container
+	Repos            *domain.Repositories
+	AuthService      *auth.Service
+	WorkspaceService *workspace.Service
+	MeteringService  *metering.Service
+	GitSyncService   *gitsync.Service
+	SlackService     *slack.Service
+	ListCache        *cache.ResponseCache
+	Models           *llm.Registry
+
+	AuthHandler         *httpserver.AuthHandler
+	PromptHandler       *httpserver.PromptHandler
+	PipelineHandler     *httpserver.PipelineHandler
+	AuditHandler        *httpserver.AuditHandler
+	WorkspaceHandler    *httpserver.WorkspaceHandler
+	TelemetryHandler    *httpserver.TelemetryHandler
+	AnnouncementHandler *httpserver.AnnouncementHandler
+}
+
+func newEngine(p engineParams) *gin.Engine {
+	cfg, logger := p.Config, p.Logger
+
+	var (
+		meteringHandler *httpserver.MeteringHandler
+		usageRecorder   middleware.UsageRecorder
+	)
+	if p.MeteringService != nil {
+		meteringHandler = httpserver.NewMeteringHandler(p.MeteringService)
+	}
+	// 只读模式下不写库，也就不统计 API 调用次数。
+	if p.MeteringService != nil && !p.Container.ReadOnly {
+		// API 调用计数先在进程内累加，定期写库；退出时再写一次，避免丢失最后一个周期的计数。
+		recorder := metering.NewRecorder(p.Repos.Usage)
+		usageRecorder = recorder.Record
+		flusher := app.NewScheduler(logger)
+		flusher.Every("metering-flush", cfg.Metering.FlushInterval, recorder.Flush)
+		startBackground(p.Lifecycle, flusher.Start)
+		p.Lifecycle.Append(fx.StopHook(func(ctx context.Context) {
+			flushCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			if err := recorder.Flush(flushCtx); err != nil {
+				logger.Warn("API 调用计数写库失败", zap.Error(err))
+			}
+		}))
+	}
+
+	var gitSyncHandler *httpserver.GitSyncHandler
+	if p.GitSyncService != nil {
+		gitSyncHandler = httpserver.NewGitSyncHandler(p.GitSyncService)
+	}
+
+	var slackHandler *httpserver.SlackHandler
+	if p.SlackService != nil {
+		slackHandler = httpserver.NewSlackHandler(p.SlackService)
+	}
+
+	var responseCache middleware.ResponseCacheStore
+	if p.ListCache != nil {
+		responseCache = p.ListCache
+	}
+
+	features := map[string]bool{
+		"github_login":      cfg.Auth.GitHub.Enabled,
+		"self_registration": cfg.Auth.Registration.Mode != config.RegistrationClosed,
+		"edit_locks":        cfg.Prompts.EditLocks && !p.Container.Degraded,
+		"list_cache":        responseCache != nil,
+		"freeze_windows":    len(cfg.Prompts.FreezeWindows) > 0,
+		"metering":          p.MeteringService != nil,
+		"telemetry":         cfg.Telemetry.Enabled,
+		"git_sync":          p.GitSyncService != nil,
+		"slack":             p.SlackService != nil,
+		"read_only":         p.Container.ReadOnly,
+	}
+
+	store := memorystore.NewStore()
+	generalLimiter := middleware.RateLimit(limiter.New(store, limiter.Rate{Period: time.Minute, Limit: 120}), middleware.KeyByClientIP(), middleware.WithKeyClass("general"))
+	loginLimiter := middleware.RateLimit(limiter.New(store, limiter.Rate{Period: time.Minute, Limit: 10}), middleware.KeyByClientIP(), middleware.WithKeyClass("login"))
+
+	return httpserver.NewEngine(cfg, logger, httpserver.RouterOptions{
+		Middlewares: []gin.HandlerFunc{
+			middleware.RequestLogger(logger),
+		},
+		HealthDeps: &httpserver.HealthDependencies{
+			DB:            p.Container.DB,
+			Redis:         p.Container.Redis,
+			RedisDegraded: p.Container.Degraded,
+			MemoryStore:   cfg.Database.Driver == config.DatabaseDriverMemory,
+		},
+		AuthHandler:       p.AuthHandler,
+		PromptHandler:     p.PromptHandler,
+		PipelineHandler:   p.PipelineHandler,
+		AuditHandler:      p.AuditHandler,
+		WorkspaceHandler:  p.WorkspaceHandler,
+		RateLimiter:       generalLimiter,
+		LoginRateLimit:    loginLimiter,
+		TokenParser:       p.AuthService.ParseAccessToken,
+		WorkspaceResolver: p.WorkspaceService.ResolveRole,
+		MetricsHandler:    metrics.Default.Handler(),
+		// 单个 API Key 默认每分钟 60 次，可在签发时通过 rate_limit 单独调整。
+		APIKeyAuthenticator: httpserver.APIKeyAuthenticator(p.AuthService),
+		APIKeyRateLimit:     middleware.RateLimitByAPIKey(store, limiter.Rate{Period: time.Minute, Limit: 60}),
+		MeteringHandler:     meteringHandler,
+		UsageRecorder:       usageRecorder,
+		TelemetryHandler:    p.TelemetryHandler,
+		AnnouncementHandler: p.AnnouncementHandler,
+		GitSyncHandler:      gitSyncHandler,
+		SlackHandler:        slackHandler,
+		ModelHandler:        httpserver.NewModelHandler(p.Models),
+		StatusHandler:       httpserver.NewStatusHandler(features),
+		FreezeOverrideRoles: cfg.Prompts.FreezeOverrideRoles,
+		ResponseCache:       responseCache,
+		ResponseCacheTTL:    cfg.Prompts.ListCacheTTL,
+		ReadOnly:            p.Container.ReadOnly,
+	})
+}
+
+// startSecretRotation 定期从密钥服务刷新引用的密钥；每个进程各自刷新，不经分布式租约。
+func startSecretRotation(lc fx.Lifecycle, cfg *config.Config, logger *zap.Logger, authService *auth.Service) {
+	if !cfg.HasSecretRefs() || cfg.Secrets.RefreshInterval <= 0 {
+		return
+	}
+	rotation := app.NewScheduler(logger)
+	rotation.Every("secret-refresh", cfg.Secrets.RefreshInterval, func(ctx context.Context) error {
+		changed, err := cfg.RefreshSecrets(ctx)
+		for path, value := range changed {
+			switch path {
+			case "auth.github.clientSecret":
+				authService.SetGitHubClientSecret(value)
+				logger.Info("密钥已轮换并生效", zap.String("path", path))
+			default:
+				logger.Warn("密钥已轮换，需重启后生效", zap.String("path", path))
+			}
+		}
+		return err
+	})
+	startBackground(lc, rotation.Start)
+}
+
+// runServer 在应用启动后开始监听；监听失败时关闭整个应用并以非零状态退出。
+func runServer(lc fx.Lifecycle, shutdowner fx.Shutdowner, cfg *config.Config, logger *zap.Logger, engine *gin.Engine) {
+	application := app.New(cfg, logger, engine)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				if err := application.Run(ctx); err != nil {
+					logger.Error("服务运行异常", zap.Error(err))
+					_ = shutdowner.Shutdown(fx.ExitCode(1))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,106 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/config"
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra"
+	"github.com/zacharykka/prompt-manager/internal/service/audit"
+	"github.com/zacharykka/prompt-manager/internal/service/executionlog"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Infra 提供数据库、Redis 连接与装饰后的仓储。
+var Infra = fx.Module("infra",
+	fx.Provide(
+		newContainer,
+		newRepositories,
+	),
+)
+
+func newContainer(lc fx.Lifecycle, cfg *config.Config, logger *zap.Logger) (*infra.Container, error) {
+	// 数据库与 Redis 各自最多重试 startup.maxWait，另留出种子数据等初始化的时间。
+	initCtx, cancel := context.WithTimeout(context.Background(), 2*cfg.Startup.MaxWait+15*time.Second)
+	defer cancel()
+	container, cleanup, err := infra.Initialize(initCtx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	// 最先注册，因此最后执行：其他组件排空队列后再关闭连接。
+	lc.Append(fx.StopHook(cleanup))
+	return container, nil
+}
+
+// newRepositories 在 SQL 仓储之上叠加审计推送与执行日志写入方式；Container.Repos 保留未装饰的 SQL 实现，
+// 供执行日志消费者直接写库。
+func newRepositories(lc fx.Lifecycle, cfg *config.Config, logger *zap.Logger, mode Mode, container *infra.Container) *domain.Repositories {
+	repos := *container.Repos
+
+	if sinks := auditSinks(cfg.Audit); len(sinks) > 0 {
+		streamer := audit.NewStreamer(logger, sinks,
+			audit.WithStreamBufferSize(cfg.Audit.BufferSize),
+			audit.WithStreamBatchSize(cfg.Audit.BatchSize),
+			audit.WithStreamFlushInterval(cfg.Audit.FlushInterval),
+			audit.WithStreamOverflowPolicy(cfg.Audit.Overflow),
+			audit.WithStreamRetry(cfg.Audit.MaxRetries, 0),
+		)
+		streamer.Start(context.Background())
+		repos.AuditLogs = audit.NewStreamingAuditLogs(repos.AuditLogs, streamer)
+		repos.PromptAuditLog = audit.NewStreamingPromptAuditLogs(repos.PromptAuditLog, streamer)
+		lc.Append(fx.StopHook(func(ctx context.Context) {
+			drainCtx, cancel := context.WithTimeout(ctx, cfg.Server.ShutdownTimeout)
+			defer cancel()
+			if err := streamer.Close(drainCtx); err != nil {
+				logger.Warn("审计推送队列未能排空", zap.Error(err))
+			}
+		}))
+	}
+
+	switch {
+	// buffered 模式只缓冲本进程 HTTP 请求产生的日志，worker 模式下无需启动。
+	case cfg.ExecutionLogs.Mode == config.ExecutionLogModeBuffered && mode.RunAPI():
+		logWriter := executionlog.NewBufferedWriter(repos.PromptExecutionLog, logger,
+			executionlog.WithBufferSize(cfg.ExecutionLogs.BufferSize),
+			executionlog.WithBatchSize(cfg.ExecutionLogs.BatchSize),
+			executionlog.WithFlushInterval(cfg.ExecutionLogs.FlushInterval),
+			executionlog.WithOverflowPolicy(cfg.ExecutionLogs.Overflow),
+		)
+		logWriter.Start(context.Background())
+		repos.PromptExecutionLog = logWriter
+		// 晚于数据库清理注册，先于其执行：HTTP 服务退出后排空队列再关闭连接。
+		lc.Append(fx.StopHook(func(ctx context.Context) {
+			drainCtx, cancel := context.WithTimeout(ctx, cfg.Server.ShutdownTimeout)
+			defer cancel()
+			if err := logWriter.Close(drainCtx); err != nil {
+				logger.Warn("执行日志队列未能排空", zap.Error(err))
+			}
+		}))
+	case cfg.ExecutionLogs.Mode == config.ExecutionLogModeRedis && container.Degraded:
+		logger.Warn("Redis 不可用，执行日志改为同步写库")
+	case cfg.ExecutionLogs.Mode == config.ExecutionLogModeRedis:
+		// api 模式只写入 Stream，由 worker 实例消费（见 Worker 模块）。
+		repos.PromptExecutionLog = executionlog.NewStreamWriter(repos.PromptExecutionLog,
+			container.Redis, cfg.ExecutionLogs.Stream, cfg.ExecutionLogs.StreamMaxLen)
+	}
+
+	return &repos
+}
+
+// auditSinks 根据配置创建审计推送目标。
+func auditSinks(cfg config.AuditConfig) []audit.Sink {
+	sinks := make([]audit.Sink, 0, len(cfg.Sinks))
+	for _, sink := range cfg.Sinks {
+		switch sink.Type {
+		case config.AuditSinkSyslog:
+			sinks = append(sinks, audit.NewSyslogSink(sink.Name, sink.Network, sink.Address, sink.AppName))
+		case config.AuditSinkHTTP:
+			sinks = append(sinks, audit.NewHTTPSink(sink.Name, sink.URL, sink.Secret))
+		case config.AuditSinkKafka:
+			sinks = append(sinks, audit.NewKafkaSink(sink.Name, sink.URL, sink.Topic))
+		}
+	}
+	return sinks
+}
@@ -0,0 +1,233 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/app"
+	"github.com/zacharykka/prompt-manager/internal/config"
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra"
+	"github.com/zacharykka/prompt-manager/internal/infra/cache"
+	"github.com/zacharykka/prompt-manager/internal/service/announcement"
+	"github.com/zacharykka/prompt-manager/internal/service/audit"
+	"github.com/zacharykka/prompt-manager/internal/service/auth"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+	"github.com/zacharykka/prompt-manager/internal/service/freeze"
+	"github.com/zacharykka/prompt-manager/internal/service/gitsync"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+	"github.com/zacharykka/prompt-manager/internal/service/metering"
+	"github.com/zacharykka/prompt-manager/internal/service/pipeline"
+	"github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/internal/service/slack"
+	"github.com/zacharykka/prompt-manager/internal/service/telemetry"
+	"github.com/zacharykka/prompt-manager/internal/service/workspace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Services 提供业务服务及其后台任务；依赖 *domain.Repositories 与 *infra.Container。
+var Services = fx.Module("services",
+	fx.Provide(
+		newListCache,
+		newModelRegistry,
+		newPromptService,
+		newAuthService,
+		newMeteringService,
+		newGitSyncService,
+		newSlackService,
+		newTelemetryReporter,
+		workspace.NewService,
+		newPipelineService,
+		audit.NewService,
+		announcement.NewService,
+		AsJobs(promptJobs),
+		AsJobs(meteringJobs),
+		AsJobs(telemetryJobs),
+	),
+	fx.Invoke(seedPrompts),
+)
+
+// newListCache 在启用列表缓存且 Redis 可用时返回响应缓存，否则返回 nil。
+func newListCache(cfg *config.Config, logger *zap.Logger, container *infra.Container) *cache.ResponseCache {
+	// 降级模式下依赖 Redis 的功能直接关闭：列表缓存回源数据库，编辑锁不再互斥。
+	if container.Degraded && (cfg.Prompts.EditLocks || cfg.Prompts.ListCache) {
+		logger.Warn("Redis 不可用，已关闭编辑锁与列表缓存", zap.Bool("editLocks", cfg.Prompts.EditLocks), zap.Bool("listCache", cfg.Prompts.ListCache))
+	}
+	if !cfg.Prompts.ListCache || container.Degraded {
+		return nil
+	}
+	return cache.NewResponseCache(container.Redis)
+}
+
+// newModelRegistry 根据 models.registry 构建已知模型注册表，未配置时注册表为空、不校验模型名称。
+func newModelRegistry(cfg *config.Config) *llm.Registry {
+	models := make([]llm.Model, 0, len(cfg.Models.Registry))
+	for _, model := range cfg.Models.Registry {
+		models = append(models, model.Model())
+	}
+	return llm.NewRegistry(models)
+}
+
+func newPipelineService(repos *domain.Repositories, models *llm.Registry) *pipeline.Service {
+	return pipeline.NewService(repos, pipeline.WithModelRegistry(models))
+}
+
+func newPromptService(cfg *config.Config, logger *zap.Logger, container *infra.Container, repos *domain.Repositories, listCache *cache.ResponseCache, models *llm.Registry) (*prompt.Service, error) {
+	options := []prompt.Option{
+		prompt.WithRequireReleaseNote(cfg.Prompts.RequireReleaseNote),
+		prompt.WithRequirePublished(cfg.Prompts.RequirePublished),
+		prompt.WithActivationWebhook(cfg.Prompts.ActivationWebhookURL),
+		prompt.WithMaxVersions(cfg.Prompts.MaxVersions),
+		prompt.WithMaxBodyBytes(cfg.Prompts.MaxBodyBytes),
+		prompt.WithStripInvisibleChars(cfg.Prompts.StripInvisibleChars),
+		prompt.WithModelRegistry(models),
+	}
+	if cfg.Prompts.EditLocks && !container.Degraded {
+		options = append(options, prompt.WithEditLocks(cache.NewEditLockStore(container.Redis), cfg.Prompts.EditLockTTL))
+	}
+	if listCache != nil {
+		bus := events.NewBus()
+		// 未启用工作区时列表不区分工作区，因此同时失效全局作用域。
+		bus.Subscribe(func(ctx context.Context, event events.Event) {
+			if err := listCache.Invalidate(ctx, cache.WorkspaceScope(event.WorkspaceID), cache.WorkspaceScope(""), cache.PromptScope(event.PromptID)); err != nil {
+				logger.Warn("列表缓存失效失败", zap.String("prompt_id", event.PromptID), zap.Error(err))
+			}
+		})
+		options = append(options, prompt.WithEventBus(bus))
+	}
+	if len(cfg.Prompts.FreezeWindows) > 0 {
+		windows := make([]*freeze.Window, 0, len(cfg.Prompts.FreezeWindows))
+		for _, windowCfg := range cfg.Prompts.FreezeWindows {
+			window, err := freeze.ParseWindow(windowCfg.Spec())
+			if err != nil {
+				return nil, fmt.Errorf("冻结窗口配置无效: %w", err)
+			}
+			windows = append(windows, window)
+		}
+		options = append(options, prompt.WithChangeFreeze(freeze.NewCalendar(windows...)))
+	}
+	return prompt.NewService(repos, options...), nil
+}
+
+// seedPrompts 在启动时导入 seed.prompts.dir 下的种子 Prompt，只读模式下跳过。
+func seedPrompts(cfg *config.Config, logger *zap.Logger, container *infra.Container, promptService *prompt.Service) error {
+	dir := cfg.Seed.Prompts.Dir
+	if dir == "" || container.ReadOnly {
+		return nil
+	}
+	seedCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	report, err := promptService.SeedPrompts(seedCtx, os.DirFS(dir), prompt.SeedOptions{})
+	if err != nil {
+		return fmt.Errorf("种子 Prompt 加载失败（%s）: %w", dir, err)
+	}
+	for _, item := range report.Items {
+		if item.Status == prompt.ImportItemFailed {
+			logger.Warn("种子 Prompt 导入失败", zap.String("file", item.File), zap.String("error", item.Error))
+		}
+	}
+	logger.Info("种子 Prompt 加载完成", zap.String("dir", dir), zap.Int("created", report.Created), zap.Int("skipped", report.Skipped), zap.Int("failed", report.Failed))
+	return nil
+}
+
+// promptJobs 返回告警评估（含告警 webhook 推送）、灰度评估与版本清理任务。
+func promptJobs(cfg *config.Config, promptService *prompt.Service) []app.Job {
+	jobs := []app.Job{
+		{Name: "prompt-alerts", Interval: time.Minute, Run: promptService.EvaluateAlerts},
+		{Name: "prompt-canaries", Interval: time.Minute, Run: promptService.EvaluateCanaries},
+	}
+	if cfg.Prompts.MaxVersions > 0 {
+		jobs = append(jobs, app.Job{Name: "prompt-version-retention", Interval: cfg.Prompts.RetentionInterval, Run: promptService.PruneVersions})
+	}
+	return jobs
+}
+
+func newAuthService(cfg *config.Config, repos *domain.Repositories) (*auth.Service, error) {
+	authService := auth.NewService(repos, cfg.Auth)
+	initCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := authService.InitSigningKeys(initCtx); err != nil {
+		return nil, fmt.Errorf("签名密钥初始化失败: %w", err)
+	}
+	return authService, nil
+}
+
+// newMeteringService 在未启用计费时返回 nil。
+func newMeteringService(cfg *config.Config, repos *domain.Repositories) *metering.Service {
+	if !cfg.Metering.Enabled {
+		return nil
+	}
+	return metering.NewService(repos.Usage, metering.WithExporters(meteringExporters(cfg.Metering)...))
+}
+
+// newGitSyncService 在未配置同步仓库时返回 nil。
+func newGitSyncService(cfg *config.Config, promptService *prompt.Service) *gitsync.Service {
+	syncCfg := cfg.GitSync
+	if syncCfg.Repository == "" {
+		return nil
+	}
+	return gitsync.NewService(promptService, syncCfg.Repository, syncCfg.WebhookSecret,
+		gitsync.WithBranch(syncCfg.Branch),
+		gitsync.WithPath(syncCfg.Path),
+		gitsync.WithToken(syncCfg.Token),
+		gitsync.WithAPIBase(syncCfg.APIBase),
+		gitsync.WithWorkspace(syncCfg.Workspace),
+	)
+}
+
+// newSlackService 在未配置 Signing Secret 时返回 nil。
+func newSlackService(cfg *config.Config, promptService *prompt.Service) *slack.Service {
+	if cfg.Slack.SigningSecret == "" {
+		return nil
+	}
+	return slack.NewService(promptService, cfg.Slack.SigningSecret, slack.WithWorkspace(cfg.Slack.Workspace))
+}
+
+func meteringJobs(cfg *config.Config, meteringService *metering.Service) []app.Job {
+	if meteringService == nil || !meteringService.HasExporters() {
+		return nil
+	}
+	return []app.Job{{Name: "metering-export", Interval: cfg.Metering.ExportInterval, Run: meteringService.Export}}
+}
+
+// meteringExporters 根据配置创建计费推送目标。
+func meteringExporters(cfg config.MeteringConfig) []metering.Exporter {
+	var exporters []metering.Exporter
+	if cfg.Webhook.URL != "" {
+		exporters = append(exporters, metering.NewWebhookExporter(cfg.Webhook.URL, cfg.Webhook.Secret))
+	}
+	if cfg.Stripe.APIKey != "" {
+		customers := make(map[string]string, len(cfg.Stripe.Customers))
+		for _, customer := range cfg.Stripe.Customers {
+			customers[customer.OrganizationID] = customer.CustomerID
+		}
+		events := map[string]string{
+			domain.UsageMetricAPICalls:     cfg.Stripe.Events.APICalls,
+			domain.UsageMetricExecutions:   cfg.Stripe.Events.Executions,
+			domain.UsageMetricStorageBytes: cfg.Stripe.Events.StorageBytes,
+		}
+		exporters = append(exporters, metering.NewStripeExporter(cfg.Stripe.APIKey, cfg.Stripe.APIBase, customers, events))
+	}
+	return exporters
+}
+
+func newTelemetryReporter(cfg *config.Config, repos *domain.Repositories) *telemetry.Reporter {
+	options := []telemetry.Option{
+		telemetry.WithInstanceID(telemetry.InstanceID(cfg.Auth.AccessTokenSecret)),
+		telemetry.WithDatabaseDriver(cfg.Database.Driver),
+	}
+	if cfg.Telemetry.Enabled {
+		options = append(options, telemetry.WithEndpoint(cfg.Telemetry.Endpoint))
+	}
+	return telemetry.NewReporter(repos, options...)
+}
+
+func telemetryJobs(cfg *config.Config, reporter *telemetry.Reporter) []app.Job {
+	if !reporter.Enabled() {
+		return nil
+	}
+	return []app.Job{{Name: "telemetry", Interval: cfg.Telemetry.Interval, Run: reporter.Send}}
+}
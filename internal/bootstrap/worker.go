@@ -0,0 +1,105 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/zacharykka/prompt-manager/internal/app"
+	"github.com/zacharykka/prompt-manager/internal/config"
+	"github.com/zacharykka/prompt-manager/internal/infra"
+	"github.com/zacharykka/prompt-manager/internal/service/executionlog"
+	"github.com/zacharykka/prompt-manager/pkg/distlock"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Worker 运行后台任务：调度 JobsGroup 中的全部任务，并在 redis 模式下消费执行日志 Stream。
+var Worker = fx.Module("worker",
+	fx.Invoke(
+		fx.Annotate(startScheduler, fx.ParamTags(``, ``, ``, JobsGroup)),
+		startExecutionLogConsumer,
+	),
+)
+
+// startScheduler 启动后台任务调度；多个 worker 副本通过分布式租约保证同一周期只执行一次。
+// 后台任务都会写库，只读模式下不启动。
+func startScheduler(lc fx.Lifecycle, logger *zap.Logger, container *infra.Container, jobs []app.Job) {
+	if container.ReadOnly {
+		logger.Warn("只读模式下不运行后台任务", zap.Int("jobs", len(jobs)))
+		return
+	}
+	var options []app.SchedulerOption
+	if container.Redis != nil {
+		options = append(options, app.WithLocker(distlock.New(container.Redis)))
+	} else {
+		logger.Warn("Redis 不可用，后台任务不再经分布式租约互斥，多个 worker 副本可能重复执行")
+	}
+	scheduler := app.NewScheduler(logger, options...)
+	for _, job := range jobs {
+		scheduler.Every(job.Name, job.Interval, job.Run)
+	}
+	startBackground(lc, scheduler.Start)
+}
+
+// startExecutionLogConsumer 在 redis 模式下消费执行日志 Stream 并写库；api 模式只写入 Stream。
+func startExecutionLogConsumer(lc fx.Lifecycle, cfg *config.Config, logger *zap.Logger, container *infra.Container) {
+	if cfg.ExecutionLogs.Mode != config.ExecutionLogModeRedis || container.Degraded || container.ReadOnly {
+		return
+	}
+	consumer := executionlog.NewStreamConsumer(container.Repos.PromptExecutionLog, container.Redis, logger,
+		cfg.ExecutionLogs.Stream, cfg.ExecutionLogs.ConsumerGroup,
+		executionlog.WithConsumerName(consumerName()),
+		executionlog.WithReadBatchSize(cfg.ExecutionLogs.BatchSize),
+		executionlog.WithReadBlock(cfg.ExecutionLogs.FlushInterval),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				if err := consumer.Run(ctx); err != nil {
+					logger.Error("执行日志消费者退出", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		// 等待正在写库的批次结束后再关闭数据库连接。
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+				logger.Warn("执行日志消费者未能按时退出")
+			}
+			return nil
+		},
+	})
+}
+
+// startBackground 在应用启动后以独立上下文运行 start，停止时取消该上下文。
+// fx 传给 OnStart 的上下文只覆盖启动阶段，不能交给常驻 goroutine。
+func startBackground(lc fx.Lifecycle, start func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			start(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// consumerName 以主机名与进程号区分共享消费组的多个实例。
+func consumerName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "prompt-manager"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -10,6 +12,8 @@ import (
 
 	mapstructure "github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
+	"github.com/zacharykka/prompt-manager/internal/service/freeze"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
 )
 
 const (
@@ -27,8 +31,24 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Redis    RedisConfig    `mapstructure:"redis"`
 	Auth     AuthConfig     `mapstructure:"auth"`
+	Prompts  PromptsConfig  `mapstructure:"prompts"`
+	Models   ModelsConfig   `mapstructure:"models"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
 	Seed     SeedConfig     `mapstructure:"seed"`
+
+	ExecutionLogs ExecutionLogsConfig `mapstructure:"executionLogs"`
+	Secrets       SecretsConfig       `mapstructure:"secrets"`
+	Metering      MeteringConfig      `mapstructure:"metering"`
+	GitSync       GitSyncConfig       `mapstructure:"gitSync"`
+	Slack         SlackConfig         `mapstructure:"slack"`
+	Telemetry     TelemetryConfig     `mapstructure:"telemetry"`
+	Audit         AuditConfig         `mapstructure:"audit"`
+	Startup       StartupConfig       `mapstructure:"startup"`
+	// Includes 列出 default.yaml 之后按顺序合并的配置文件（相对配置目录，支持通配符），见 mergeIncludes。
+	Includes []string `mapstructure:"includes"`
+
+	// secretRefs 记录从密钥服务解析的字段，供 RefreshSecrets 感知轮换。
+	secretRefs *secretRefs
 }
 
 // AppConfig 描述应用级别的元信息。
@@ -45,14 +65,51 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration         `mapstructure:"writeTimeout"`
 	ShutdownTimeout time.Duration         `mapstructure:"shutdownTimeout"`
 	MaxRequestBody  int64                 `mapstructure:"maxRequestBody"`
+	BodyLimits      BodyLimitsConfig      `mapstructure:"bodyLimits"`
 	CORS            CORSConfig            `mapstructure:"cors"`
 	SecurityHeaders SecurityHeadersConfig `mapstructure:"securityHeaders"`
+	Probes          ProbesConfig          `mapstructure:"probes"`
+}
+
+// ProbesConfig 描述负载均衡健康检查、Prometheus 抓取等探测请求的豁免名单。
+type ProbesConfig struct {
+	// Paths 为精确匹配的探测路径，不限流也不记录请求日志，默认 /healthz 与 /metrics。
+	Paths []string `mapstructure:"paths"`
+	// TrustedCIDRs 为可信内网网段，来自这些网段的请求（如合成监控）不限流。
+	TrustedCIDRs []string `mapstructure:"trustedCIDRs"`
+}
+
+// BodyLimitsConfig 按路由分组覆盖请求体上限（字节），未配置的分组沿用 maxRequestBody。
+type BodyLimitsConfig struct {
+	// Auth 作用于 /auth 与 /me 下的登录、注册、改密等接口，默认 64KB。
+	Auth int64 `mapstructure:"auth"`
+	// Prompts 作用于 Prompt 的增删改与版本上传。
+	Prompts int64 `mapstructure:"prompts"`
+	// ImportExport 作用于批量导入与导出接口，默认 32MB。
+	ImportExport int64 `mapstructure:"importExport"`
+	// Invoke 作用于流水线调用接口。
+	Invoke int64 `mapstructure:"invoke"`
 }
 
 // CORSConfig 控制跨域访问白名单及相关选项。
 type CORSConfig struct {
 	AllowOrigins     []string `mapstructure:"allowOrigins"`
 	AllowCredentials bool     `mapstructure:"allowCredentials"`
+	// AllowHeaders 在内置请求头（Authorization、Content-Type、X-Workspace-ID）之外追加允许的请求头。
+	AllowHeaders []string `mapstructure:"allowHeaders"`
+	// MaxAge 控制预检结果缓存时长（Access-Control-Max-Age），默认 12h。
+	MaxAge time.Duration `mapstructure:"maxAge"`
+	// Overrides 按路径前缀覆盖跨域策略，最长前缀优先，未匹配时使用全局配置。
+	Overrides []CORSOverrideConfig `mapstructure:"overrides"`
+}
+
+// CORSOverrideConfig 描述某一路由前缀的跨域策略；AllowHeaders 在全局配置基础上追加，MaxAge 为空时沿用全局值。
+type CORSOverrideConfig struct {
+	PathPrefix       string        `mapstructure:"pathPrefix"`
+	AllowOrigins     []string      `mapstructure:"allowOrigins"`
+	AllowCredentials bool          `mapstructure:"allowCredentials"`
+	AllowHeaders     []string      `mapstructure:"allowHeaders"`
+	MaxAge           time.Duration `mapstructure:"maxAge"`
 }
 
 // SecurityHeadersConfig 控制通用安全响应头的行为。
@@ -67,43 +124,427 @@ type SecurityHeadersConfig struct {
 	CrossOriginResourcePolicy string `mapstructure:"crossOriginResourcePolicy"`
 }
 
-// DatabaseConfig 定义数据库连接选项，兼容 SQLite 与 PostgreSQL。
+// 数据库驱动类型。
+const (
+	DatabaseDriverSQLite = "sqlite"
+	// DatabaseDriverMemory 使用进程内存保存全部数据，无需数据库文件或迁移，重启后数据丢失，仅用于测试与演示。
+	DatabaseDriverMemory = "memory"
+)
+
+// DatabaseConfig 定义数据库连接选项，兼容 SQLite、PostgreSQL 与内存模式。
 type DatabaseConfig struct {
 	Driver          string        `mapstructure:"driver"`
-	DSN             string        `mapstructure:"dsn"`
+	DSN             string        `mapstructure:"dsn" secret:"dsn"`
 	MaxOpen         int           `mapstructure:"maxOpen"`
 	MaxIdle         int           `mapstructure:"maxIdle"`
 	ConnMaxLifetime time.Duration `mapstructure:"connMaxLifetime"`
+	// SchemaCheck 控制启动时数据库结构版本与当前程序的兼容性校验。
+	SchemaCheck SchemaCheckConfig `mapstructure:"schemaCheck"`
+}
+
+// 结构版本校验模式。
+const (
+	// SchemaCheckStrict 在数据库落后、超前或存在失败迁移时拒绝启动。
+	SchemaCheckStrict = "strict"
+	// SchemaCheckReadOnly 在数据库超前时以只读模式启动，其余不兼容情况仍拒绝启动。
+	SchemaCheckReadOnly = "readonly"
+	// SchemaCheckOff 不做校验。
+	SchemaCheckOff = "off"
+)
+
+// SchemaCheckConfig 定义启动时的数据库结构版本校验，内存模式下不生效。
+type SchemaCheckConfig struct {
+	// Mode 为 strict（默认）、readonly 或 off。
+	Mode string `mapstructure:"mode"`
+	// AllowAhead 为数据库可超前的迁移数量，这些迁移需保证旧版本可以安全读写（仅新增表或可空列），默认 0。
+	AllowAhead int `mapstructure:"allowAhead"`
 }
 
 // RedisConfig 描述 Redis 客户端所需的连接参数。
 type RedisConfig struct {
 	Addr     string `mapstructure:"addr"`
 	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
+	Password string `mapstructure:"password" secret:"true"`
 	DB       int    `mapstructure:"db"`
 	PoolSize int    `mapstructure:"poolSize"`
+	// Required 为 true 时启动阶段 Redis 不可达直接失败；默认以降级模式启动，缓存、编辑锁等功能退化或关闭。
+	Required bool `mapstructure:"required"`
 }
 
 // AuthConfig 管理 JWT 与 API Key 等认证参数。
 type AuthConfig struct {
-	AccessTokenSecret  string            `mapstructure:"accessTokenSecret"`
-	RefreshTokenSecret string            `mapstructure:"refreshTokenSecret"`
-	AccessTokenTTL     time.Duration     `mapstructure:"accessTokenTTL"`
-	RefreshTokenTTL    time.Duration     `mapstructure:"refreshTokenTTL"`
-	APIKeyHashSecret   string            `mapstructure:"apiKeyHashSecret"`
-	GitHub             GitHubOAuthConfig `mapstructure:"github"`
+	AccessTokenSecret  string                `mapstructure:"accessTokenSecret" secret:"true"`
+	RefreshTokenSecret string                `mapstructure:"refreshTokenSecret" secret:"true"`
+	AccessTokenTTL     time.Duration         `mapstructure:"accessTokenTTL"`
+	RefreshTokenTTL    time.Duration         `mapstructure:"refreshTokenTTL"`
+	APIKeyHashSecret   string                `mapstructure:"apiKeyHashSecret" secret:"true"`
+	InvitationTTL      time.Duration         `mapstructure:"invitationTTL"`
+	Registration       RegistrationConfig    `mapstructure:"registration"`
+	RoleMapping        RoleMappingConfig     `mapstructure:"roleMapping"`
+	PasswordPolicy     PasswordPolicyConfig  `mapstructure:"passwordPolicy"`
+	PasswordHashing    PasswordHashingConfig `mapstructure:"passwordHashing"`
+	GitHub             GitHubOAuthConfig     `mapstructure:"github"`
+	Signing            SigningConfig         `mapstructure:"signing"`
+}
+
+// RegistrationConfig 控制自助注册（含 GitHub OAuth 首次登录自动建号）的策略。
+type RegistrationConfig struct {
+	// Mode 为 open（默认，注册即激活）、closed（关闭自助注册）或 approval（注册后为 pending，需管理员审批）。
+	Mode string `mapstructure:"mode"`
+	// AllowedDomains 非空时仅允许这些邮箱域名注册，不区分大小写。
+	AllowedDomains []string `mapstructure:"allowedDomains"`
+}
+
+// RoleMappingConfig 定义身份提供方声明到本地角色/工作区的映射规则，在外部身份登录时应用。
+type RoleMappingConfig struct {
+	Rules []RoleMappingRule `mapstructure:"rules"`
+}
+
+// RoleMappingRule 描述一条映射规则：Claim 的任一值匹配 Values（支持 * 通配）时授予 Role 及/或工作区角色。
+// Provider 为空时对全部外部身份提供方生效；多条规则命中同一目标时取最高角色。
+type RoleMappingRule struct {
+	Provider      string   `mapstructure:"provider"`
+	Claim         string   `mapstructure:"claim"`
+	Values        []string `mapstructure:"values"`
+	Role          string   `mapstructure:"role"`
+	WorkspaceID   string   `mapstructure:"workspaceId"`
+	WorkspaceRole string   `mapstructure:"workspaceRole"`
+}
+
+// PasswordPolicyConfig 控制注册、接受邀请与修改密码时的密码强度校验。
+type PasswordPolicyConfig struct {
+	MinLength           int  `mapstructure:"minLength"`
+	MaxLength           int  `mapstructure:"maxLength"`
+	RequireUppercase    bool `mapstructure:"requireUppercase"`
+	RequireLowercase    bool `mapstructure:"requireLowercase"`
+	RequireDigit        bool `mapstructure:"requireDigit"`
+	RequireSymbol       bool `mapstructure:"requireSymbol"`
+	MinCharacterClasses int  `mapstructure:"minCharacterClasses"`
+	// DenyCommon 拒绝内置常见弱密码列表中的密码。
+	DenyCommon bool `mapstructure:"denyCommon"`
+	// CheckBreached 通过 HIBP k-anonymity 接口拒绝已泄露密码；接口不可用时放行。
+	CheckBreached bool `mapstructure:"checkBreached"`
+	// BreachAPIURL 自定义 HIBP 兼容接口地址，留空使用官方地址。
+	BreachAPIURL string `mapstructure:"breachApiUrl"`
+}
+
+// PasswordHashingConfig 控制新密码的哈希算法与代价参数，旧哈希在登录成功后透明升级。
+type PasswordHashingConfig struct {
+	// Algorithm 为 argon2id（默认）或 bcrypt。
+	Algorithm  string       `mapstructure:"algorithm"`
+	BcryptCost int          `mapstructure:"bcryptCost"`
+	Argon2     Argon2Config `mapstructure:"argon2"`
+}
+
+// Argon2Config 描述 argon2id 参数，Memory 单位为 KiB。
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+}
+
+// 自助注册模式。
+const (
+	RegistrationOpen     = "open"
+	RegistrationClosed   = "closed"
+	RegistrationApproval = "approval"
+)
+
+// SigningConfig 控制访问令牌签名密钥的算法、加密存储与轮换。
+type SigningConfig struct {
+	// Algorithm 为新生成密钥使用的算法：HS256（默认）、RS256 或 EdDSA；启动时与当前密钥不一致会自动轮换。
+	Algorithm string `mapstructure:"algorithm"`
+	// EncryptionKey 用于加密落库的私钥材料，留空时复用 accessTokenSecret。
+	EncryptionKey string `mapstructure:"encryptionKey" secret:"true"`
+	// RotationGrace 为轮换后旧密钥仍可验签的时长，默认等于 accessTokenTTL。
+	RotationGrace time.Duration `mapstructure:"rotationGrace"`
 }
 
 // GitHubOAuthConfig 描述 GitHub OAuth 所需参数。
 type GitHubOAuthConfig struct {
-	Enabled      bool          `mapstructure:"enabled"`
-	ClientID     string        `mapstructure:"clientId"`
-	ClientSecret string        `mapstructure:"clientSecret"`
-	RedirectURL  string        `mapstructure:"redirectUrl"`
-	Scopes       []string      `mapstructure:"scopes"`
-	AllowedOrgs  []string      `mapstructure:"allowedOrgs"`
-	StateTTL     time.Duration `mapstructure:"stateTTL"`
+	Enabled      bool     `mapstructure:"enabled"`
+	ClientID     string   `mapstructure:"clientId"`
+	ClientSecret string   `mapstructure:"clientSecret" secret:"true"`
+	RedirectURL  string   `mapstructure:"redirectUrl"`
+	Scopes       []string `mapstructure:"scopes"`
+	AllowedOrgs  []string `mapstructure:"allowedOrgs"`
+	// AllowedTeams 非空时仅允许这些团队（org/team-slug）的成员登录，需要 read:org scope。
+	AllowedTeams []string `mapstructure:"allowedTeams"`
+	// RoleMappings 按组织或团队成员身份映射本地角色，是 auth.roleMapping 中 orgs/teams 规则的简写。
+	RoleMappings []GitHubRoleMapping `mapstructure:"roleMappings"`
+	StateTTL     time.Duration       `mapstructure:"stateTTL"`
+	// AllowedRedirectOrigins 限定登录结果可投递的 redirect_uri 与 client_origin 来源，支持 https://*.example.com 通配；
+	// 为空时不限制，生产环境必须配置。
+	AllowedRedirectOrigins []string `mapstructure:"allowedRedirectOrigins"`
+}
+
+// GitHubRoleMapping 描述一条组织/团队到本地角色的映射，Match 为 org 或 org/team-slug。
+type GitHubRoleMapping struct {
+	Match string `mapstructure:"match"`
+	Role  string `mapstructure:"role"`
+}
+
+// PromptsConfig 控制 Prompt 发布流程相关行为。
+type PromptsConfig struct {
+	// RequireReleaseNote 为 true 时通过激活接口发布版本必须填写发布说明。
+	RequireReleaseNote bool `mapstructure:"requireReleaseNote"`
+	// RequirePublished 为 true 时仅允许激活 published 状态的版本，配置了评审策略的 Prompt 还需版本已获批准。
+	RequirePublished bool `mapstructure:"requirePublished"`
+	// ActivationWebhookURL 非空时每次激活版本后推送激活事件（含发布说明）。
+	ActivationWebhookURL string `mapstructure:"activationWebhookURL"`
+	// EditLocks 为 true 时启用基于 Redis 的编辑锁（咨询性质，不阻止写入）。
+	EditLocks bool `mapstructure:"editLocks"`
+	// EditLockTTL 为编辑锁有效期，编辑器需在到期前续期，默认 2 分钟。
+	EditLockTTL time.Duration `mapstructure:"editLockTTL"`
+	// ListCache 为 true 时在 Redis 中缓存 Prompt 列表与版本列表，变更后按工作区与 Prompt 失效。
+	ListCache bool `mapstructure:"listCache"`
+	// ListCacheTTL 为列表缓存有效期，默认 5 秒。
+	ListCacheTTL time.Duration `mapstructure:"listCacheTTL"`
+	// MaxVersions 为每个 Prompt 保留的版本上限，0 表示不限制；激活过的版本与最新版本不会被清理。
+	MaxVersions int `mapstructure:"maxVersions"`
+	// RetentionInterval 为版本保留任务的执行间隔，默认 1 小时。
+	RetentionInterval time.Duration `mapstructure:"retentionInterval"`
+	// MaxBodyBytes 为单个版本正文（含多语言正文）的字节上限，0 表示不限制。
+	MaxBodyBytes int `mapstructure:"maxBodyBytes"`
+	// CompressionThreshold 为正文压缩存储的字节阈值，不小于该值的正文以 zstd 压缩后写入数据库，0 表示不压缩。
+	CompressionThreshold int `mapstructure:"compressionThreshold"`
+	// StripInvisibleChars 为 true 时保存版本前移除零宽空格、方向控制符、BOM 等不可见字符；正文总是规范化为 NFC。
+	StripInvisibleChars bool `mapstructure:"stripInvisibleChars"`
+	// FreezeWindows 为变更冻结窗口，窗口内禁止激活（含灰度）与删除 Prompt。
+	FreezeWindows []FreezeWindowConfig `mapstructure:"freezeWindows"`
+	// FreezeOverrideRoles 为可在冻结窗口内继续操作的角色，默认 admin。
+	FreezeOverrideRoles []string `mapstructure:"freezeOverrideRoles"`
+}
+
+// FreezeWindowConfig 描述一个冻结窗口：固定时间段（start/end，RFC3339）或周期窗口（cron 为开始时刻，持续 duration），二选一。
+// workspaces 为空时对全部工作区生效；timezone 为 cron 的计算时区，默认 UTC。
+type FreezeWindowConfig struct {
+	Name       string        `mapstructure:"name"`
+	Workspaces []string      `mapstructure:"workspaces"`
+	Start      string        `mapstructure:"start"`
+	End        string        `mapstructure:"end"`
+	Cron       string        `mapstructure:"cron"`
+	Duration   time.Duration `mapstructure:"duration"`
+	Timezone   string        `mapstructure:"timezone"`
+}
+
+// Spec 转换为冻结窗口定义。
+func (c FreezeWindowConfig) Spec() freeze.WindowSpec {
+	return freeze.WindowSpec{
+		Name:       c.Name,
+		Workspaces: c.Workspaces,
+		Start:      c.Start,
+		End:        c.End,
+		Cron:       c.Cron,
+		Duration:   c.Duration,
+		Timezone:   c.Timezone,
+	}
+}
+
+// ModelsConfig 描述已知模型注册表。版本通过 metadata.target_models 声明目标模型或系列，
+// 配置了注册表时只接受其中的模型 ID 与系列名。
+type ModelsConfig struct {
+	// Registry 为已知模型列表，为空时不校验目标模型名称。
+	Registry []ModelConfig `mapstructure:"registry"`
+}
+
+// ModelConfig 描述一个已知模型，family 为所属系列（如 gpt-4o），provider 仅用于展示。
+// status 为生命周期状态（available、deprecated、retired，默认 available），replacement 为建议迁移到的模型。
+type ModelConfig struct {
+	ID          string `mapstructure:"id"`
+	Family      string `mapstructure:"family"`
+	Provider    string `mapstructure:"provider"`
+	Status      string `mapstructure:"status"`
+	Replacement string `mapstructure:"replacement"`
+}
+
+// Model 转换为注册表条目。
+func (c ModelConfig) Model() llm.Model {
+	return llm.Model{ID: c.ID, Family: c.Family, Provider: c.Provider, Status: c.Status, Replacement: c.Replacement}
+}
+
+// 执行日志写入模式。
+const (
+	ExecutionLogModeSync     = "sync"
+	ExecutionLogModeBuffered = "buffered"
+	ExecutionLogModeRedis    = "redis"
+)
+
+// ExecutionLogsConfig 控制执行日志的写入方式。
+type ExecutionLogsConfig struct {
+	// Mode 为 sync（默认，请求内直接写库）、buffered（进程内队列异步批量写库）
+	// 或 redis（写入 Redis Stream，由后台消费者批量写库）。
+	Mode string `mapstructure:"mode"`
+	// BufferSize 为 buffered 模式的内存队列容量，默认 10000。
+	BufferSize int `mapstructure:"bufferSize"`
+	// BatchSize 为单次写库的最大条数，默认 200。
+	BatchSize int `mapstructure:"batchSize"`
+	// FlushInterval 为未攒满一批时的最长等待时间，默认 1 秒；redis 模式下为单次读取的最长阻塞时间。
+	FlushInterval time.Duration `mapstructure:"flushInterval"`
+	// Overflow 为队列已满时的策略：drop（默认，丢弃并计数）或 block（等待空位）。
+	Overflow string `mapstructure:"overflow"`
+	// Stream 为 redis 模式使用的 Stream 键，默认 prompt-manager:execution-logs。
+	Stream string `mapstructure:"stream"`
+	// ConsumerGroup 为 redis 模式的消费组名称，默认 prompt-manager。
+	ConsumerGroup string `mapstructure:"consumerGroup"`
+	// StreamMaxLen 为 Stream 的近似长度上限，超出后最旧的条目被裁剪，默认 1000000。
+	StreamMaxLen int64 `mapstructure:"streamMaxLen"`
+}
+
+// 密钥服务类型。
+const (
+	SecretsProviderVault = "vault"
+	SecretsProviderAWS   = "aws"
+)
+
+// SecretsConfig 配置 secret://<路径>#<字段> 引用的解析来源。
+type SecretsConfig struct {
+	// Provider 为 vault 或 aws；为空时配置中不能出现 secret:// 引用。
+	Provider string `mapstructure:"provider"`
+	// RefreshInterval 大于 0 时按该间隔重新读取引用的密钥以感知轮换，默认只在启动时读取。
+	RefreshInterval time.Duration      `mapstructure:"refreshInterval"`
+	Vault           VaultSecretsConfig `mapstructure:"vault"`
+	AWS             AWSSecretsConfig   `mapstructure:"aws"`
+}
+
+// VaultSecretsConfig 描述 Vault KV v2 的访问方式，路径相对于 Mount。
+type VaultSecretsConfig struct {
+	Addr      string `mapstructure:"addr"`
+	Token     string `mapstructure:"token" secret:"true"`
+	Mount     string `mapstructure:"mount"`
+	Namespace string `mapstructure:"namespace"`
+}
+
+// AWSSecretsConfig 描述 AWS Secrets Manager 的区域与可选端点，凭证读取标准 AWS_* 环境变量。
+type AWSSecretsConfig struct {
+	Region   string `mapstructure:"region"`
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// MeteringConfig 控制按租户（组织）的用量计量与计费推送。
+type MeteringConfig struct {
+	// Enabled 为 true 时统计 API 调用，并开放 /api/v1/admin/metering 用量接口。
+	Enabled bool `mapstructure:"enabled"`
+	// FlushInterval 为内存中 API 调用计数写库的间隔，默认 30 秒。
+	FlushInterval time.Duration `mapstructure:"flushInterval"`
+	// ExportInterval 为向计费系统推送用量的间隔，默认 1 小时，仅在配置了推送目标时生效。
+	ExportInterval time.Duration         `mapstructure:"exportInterval"`
+	Webhook        MeteringWebhookConfig `mapstructure:"webhook"`
+	Stripe         MeteringStripeConfig  `mapstructure:"stripe"`
+}
+
+// MeteringWebhookConfig 描述接收用量的外部计费 webhook。
+type MeteringWebhookConfig struct {
+	// URL 非空时以 JSON POST 推送用量。
+	URL string `mapstructure:"url"`
+	// Secret 非空时以 HMAC-SHA256 签名请求体。
+	Secret string `mapstructure:"secret" secret:"true"`
+}
+
+// MeteringStripeConfig 描述 Stripe Billing Meter 的上报方式，APIKey 为空时不推送。
+type MeteringStripeConfig struct {
+	APIKey string `mapstructure:"apiKey" secret:"true"`
+	// APIBase 默认 https://api.stripe.com，可指向 stripe-mock 等测试服务。
+	APIBase string `mapstructure:"apiBase"`
+	// Customers 将组织映射到 Stripe 客户，未映射的组织不上报。
+	Customers []StripeCustomerConfig `mapstructure:"customers"`
+	Events    StripeEventsConfig     `mapstructure:"events"`
+}
+
+// StripeCustomerConfig 为组织与 Stripe 客户的对应关系。
+type StripeCustomerConfig struct {
+	OrganizationID string `mapstructure:"organizationID"`
+	CustomerID     string `mapstructure:"customerID"`
+}
+
+// StripeEventsConfig 为各指标对应的 Meter 事件名，留空的指标不上报。
+type StripeEventsConfig struct {
+	APICalls     string `mapstructure:"apiCalls"`
+	Executions   string `mapstructure:"executions"`
+	StorageBytes string `mapstructure:"storageBytes"`
+}
+
+// GitSyncConfig 控制由 GitHub push webhook 触发的 Prompt 同步：推送到 Branch 且改动 Path 下 Prompt 文件时，
+// 读取改动后的文件并按导入包格式创建或追加版本。Repository 为空时不开放接口。
+type GitSyncConfig struct {
+	// Repository 为 owner/name，只处理该仓库的 push 事件。
+	Repository string `mapstructure:"repository"`
+	// Branch 为同步的分支，默认 main。
+	Branch string `mapstructure:"branch"`
+	// Path 为 Prompt 文件所在目录（相对仓库根目录），为空表示整个仓库。
+	Path string `mapstructure:"path"`
+	// WebhookSecret 为 GitHub webhook 的签名密钥，用于校验 X-Hub-Signature-256，启用时必填。
+	WebhookSecret string `mapstructure:"webhookSecret" secret:"true"`
+	// Token 为读取文件内容的 GitHub 令牌，公开仓库可为空。
+	Token string `mapstructure:"token" secret:"true"`
+	// APIBase 默认 https://api.github.com，GitHub Enterprise Server 需指向其 API 地址。
+	APIBase string `mapstructure:"apiBase"`
+	// Workspace 为导入的目标工作区，默认 default。
+	Workspace string `mapstructure:"workspace"`
+}
+
+// SlackConfig 控制 Slack 斜杠命令（POST /api/v1/integrations/slack/commands），SigningSecret 为空时不开放接口。
+type SlackConfig struct {
+	// SigningSecret 为 Slack 应用的 Signing Secret，用于校验 X-Slack-Signature。
+	SigningSecret string `mapstructure:"signingSecret" secret:"true"`
+	// Workspace 为命令查询的工作区，默认 default。
+	Workspace string `mapstructure:"workspace"`
+}
+
+// TelemetryConfig 控制匿名使用统计上报，默认关闭；上报内容可通过 /api/v1/admin/telemetry/preview 预览。
+type TelemetryConfig struct {
+	// Enabled 为 true 时定期向 Endpoint 上报，需显式开启。
+	Enabled  bool   `mapstructure:"enabled"`
+	Endpoint string `mapstructure:"endpoint"`
+	// Interval 为上报间隔，默认 24 小时。
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// 审计推送目标类型。
+const (
+	AuditSinkSyslog = "syslog"
+	AuditSinkHTTP   = "http"
+	AuditSinkKafka  = "kafka"
+)
+
+// StartupConfig 控制启动阶段连接数据库与 Redis 的重试，应对编排时依赖尚未就绪的情况。
+type StartupConfig struct {
+	// MaxWait 为单个依赖的最长等待时间，超过后放弃（Redis 按 redis.required 决定失败或降级），默认 30s。
+	MaxWait time.Duration `mapstructure:"maxWait"`
+	// InitialBackoff 为首次重试前的等待时间，之后每次翻倍，默认 500ms。
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	// MaxBackoff 为单次等待的上限，默认 5s。
+	MaxBackoff time.Duration `mapstructure:"maxBackoff"`
+}
+
+// AuditConfig 控制审计记录向外部系统（SIEM 等）的近实时推送，Sinks 为空时不推送。
+type AuditConfig struct {
+	Sinks []AuditSinkConfig `mapstructure:"sinks"`
+	// BufferSize 为每个推送目标的内存队列容量，默认 10000。
+	BufferSize int `mapstructure:"bufferSize"`
+	// BatchSize 为单次发送的最大条数，默认 100。
+	BatchSize int `mapstructure:"batchSize"`
+	// FlushInterval 为未攒满一批时的最长等待时间，默认 1 秒。
+	FlushInterval time.Duration `mapstructure:"flushInterval"`
+	// Overflow 为队列已满时的策略：drop（默认，丢弃并计数）或 block（等待空位）。
+	Overflow string `mapstructure:"overflow"`
+	// MaxRetries 为单批发送失败后的最大重试次数。
+	MaxRetries int `mapstructure:"maxRetries"`
+}
+
+// AuditSinkConfig 描述一个推送目标：syslog 使用 network/address，http 使用 url/secret，
+// kafka 通过 REST Proxy 写入，使用 url/topic。
+type AuditSinkConfig struct {
+	// Name 用于指标与日志区分各目标，默认同 Type。
+	Name    string `mapstructure:"name"`
+	Type    string `mapstructure:"type"`
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"`
+	AppName string `mapstructure:"appName"`
+	URL     string `mapstructure:"url"`
+	// Secret 非空时以 HMAC-SHA256 签名 HTTP 请求体。
+	Secret string `mapstructure:"secret" secret:"true"`
+	Topic  string `mapstructure:"topic"`
 }
 
 // LoggingConfig 控制日志输出级别等行为。
@@ -113,17 +554,25 @@ type LoggingConfig struct {
 
 // SeedConfig 控制启动时的种子数据行为。
 type SeedConfig struct {
-	Admin SeedAdminConfig `mapstructure:"admin"`
+	Admin   SeedAdminConfig   `mapstructure:"admin"`
+	Prompts SeedPromptsConfig `mapstructure:"prompts"`
 }
 
 // SeedAdminConfig 描述初始管理员账号信息。
 type SeedAdminConfig struct {
 	Email    string `mapstructure:"email"`
-	Password string `mapstructure:"password"`
+	Password string `mapstructure:"password" secret:"true"`
 	Role     string `mapstructure:"role"`
 }
 
+// SeedPromptsConfig 描述启动时加载的示例 Prompt。
+type SeedPromptsConfig struct {
+	// Dir 为 Prompt 文档（YAML/JSON，与导入格式一致）所在目录，为空表示不加载。
+	Dir string `mapstructure:"dir"`
+}
+
 // Load 从给定路径加载配置；若 env 为空会自动读取环境变量或回退到默认值。
+// 合并顺序：default.yaml → includes 列出的文件 → config.d/*.yaml → <env>.yaml → 环境变量，后者覆盖前者。
 func Load(configDir string, env string) (*Config, error) {
 	chosenEnv := determineEnv(env)
 
@@ -136,6 +585,10 @@ func Load(configDir string, env string) (*Config, error) {
 		return nil, fmt.Errorf("read base config: %w", err)
 	}
 
+	if err := mergeIncludes(v, configDir); err != nil {
+		return nil, err
+	}
+
 	if chosenEnv != defaultConfigName {
 		envConfig := viper.New()
 		envConfig.SetConfigType(configType)
@@ -154,9 +607,13 @@ func Load(configDir string, env string) (*Config, error) {
 	v.AutomaticEnv()
 
 	var cfg Config
+	var metadata mapstructure.Metadata
 	if err := v.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) {
 		dc.TagName = "mapstructure"
+		// 通过 Metadata 收集未识别的键，效果与 ErrorUnused 相同，但可与其他问题一并报告。
+		dc.Metadata = &metadata
 		dc.DecodeHook = mapstructure.ComposeDecodeHookFunc(
+			expandValueHook,
 			mapstructure.StringToTimeDurationHookFunc(),
 			mapstructure.StringToSliceHookFunc(","),
 		)
@@ -164,10 +621,22 @@ func Load(configDir string, env string) (*Config, error) {
 		return nil, fmt.Errorf("unmarshal config: %w", err)
 	}
 
+	problems := unknownKeyErrors(metadata.Unused)
+	if err := validateSecretsConfig(cfg.Secrets); err != nil {
+		problems = append(problems, err)
+	} else {
+		resolveCtx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+		defer cancel()
+		if err := resolveSecretRefs(resolveCtx, &cfg); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
 	applyDefaults(&cfg, chosenEnv)
 
-	if err := validateConfig(&cfg); err != nil {
-		return nil, err
+	problems = append(problems, validateConfig(&cfg)...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
 	}
 
 	return &cfg, nil
@@ -210,9 +679,27 @@ func applyDefaults(cfg *Config, env string) {
 	if cfg.Server.MaxRequestBody <= 0 {
 		cfg.Server.MaxRequestBody = 3 * 1024 * 1024
 	}
+	if cfg.Server.BodyLimits.Auth <= 0 {
+		cfg.Server.BodyLimits.Auth = 64 * 1024
+	}
+	if cfg.Server.BodyLimits.Prompts <= 0 {
+		cfg.Server.BodyLimits.Prompts = cfg.Server.MaxRequestBody
+	}
+	if cfg.Server.BodyLimits.ImportExport <= 0 {
+		cfg.Server.BodyLimits.ImportExport = 32 * 1024 * 1024
+	}
+	if cfg.Server.BodyLimits.Invoke <= 0 {
+		cfg.Server.BodyLimits.Invoke = cfg.Server.MaxRequestBody
+	}
 	if len(cfg.Server.CORS.AllowOrigins) == 0 {
 		cfg.Server.CORS.AllowOrigins = []string{"*"}
 	}
+	if cfg.Server.CORS.MaxAge <= 0 {
+		cfg.Server.CORS.MaxAge = 12 * time.Hour
+	}
+	if len(cfg.Server.Probes.Paths) == 0 {
+		cfg.Server.Probes.Paths = []string{"/healthz", "/metrics"}
+	}
 	if cfg.Server.SecurityHeaders.FrameOptions == "" {
 		cfg.Server.SecurityHeaders.FrameOptions = "DENY"
 	}
@@ -232,7 +719,7 @@ func applyDefaults(cfg *Config, env string) {
 		cfg.Server.SecurityHeaders.CrossOriginResourcePolicy = "same-site"
 	}
 	if cfg.Database.Driver == "" {
-		cfg.Database.Driver = "sqlite"
+		cfg.Database.Driver = DatabaseDriverSQLite
 	}
 	if cfg.Database.DSN == "" {
 		cfg.Database.DSN = filepath.ToSlash("file:./data/dev.db?cache=shared&_fk=1")
@@ -246,9 +733,93 @@ func applyDefaults(cfg *Config, env string) {
 	if cfg.Database.ConnMaxLifetime == 0 {
 		cfg.Database.ConnMaxLifetime = 5 * time.Minute
 	}
+	if cfg.Database.SchemaCheck.Mode == "" {
+		cfg.Database.SchemaCheck.Mode = SchemaCheckStrict
+	}
 	if cfg.Redis.PoolSize == 0 {
 		cfg.Redis.PoolSize = 10
 	}
+	if cfg.Prompts.ListCacheTTL <= 0 {
+		cfg.Prompts.ListCacheTTL = 5 * time.Second
+	}
+	if cfg.Prompts.EditLockTTL <= 0 {
+		cfg.Prompts.EditLockTTL = 2 * time.Minute
+	}
+	if cfg.Prompts.RetentionInterval <= 0 {
+		cfg.Prompts.RetentionInterval = time.Hour
+	}
+	if len(cfg.Prompts.FreezeOverrideRoles) == 0 {
+		cfg.Prompts.FreezeOverrideRoles = []string{"admin"}
+	}
+	if cfg.ExecutionLogs.Mode == "" {
+		cfg.ExecutionLogs.Mode = ExecutionLogModeSync
+	}
+	if cfg.ExecutionLogs.BufferSize <= 0 {
+		cfg.ExecutionLogs.BufferSize = 10000
+	}
+	if cfg.ExecutionLogs.BatchSize <= 0 {
+		cfg.ExecutionLogs.BatchSize = 200
+	}
+	if cfg.ExecutionLogs.FlushInterval <= 0 {
+		cfg.ExecutionLogs.FlushInterval = time.Second
+	}
+	if cfg.ExecutionLogs.Overflow == "" {
+		cfg.ExecutionLogs.Overflow = "drop"
+	}
+	if cfg.Audit.BufferSize <= 0 {
+		cfg.Audit.BufferSize = 10000
+	}
+	if cfg.Audit.BatchSize <= 0 {
+		cfg.Audit.BatchSize = 100
+	}
+	if cfg.Audit.FlushInterval <= 0 {
+		cfg.Audit.FlushInterval = time.Second
+	}
+	if cfg.Audit.Overflow == "" {
+		cfg.Audit.Overflow = "drop"
+	}
+	for i := range cfg.Audit.Sinks {
+		sink := &cfg.Audit.Sinks[i]
+		if sink.Name == "" {
+			sink.Name = sink.Type
+		}
+		if sink.Type == AuditSinkSyslog && sink.Network == "" {
+			sink.Network = "udp"
+		}
+	}
+	if cfg.Startup.MaxWait <= 0 {
+		cfg.Startup.MaxWait = 30 * time.Second
+	}
+	if cfg.Startup.InitialBackoff <= 0 {
+		cfg.Startup.InitialBackoff = 500 * time.Millisecond
+	}
+	if cfg.Startup.MaxBackoff <= 0 {
+		cfg.Startup.MaxBackoff = 5 * time.Second
+	}
+	if cfg.ExecutionLogs.Stream == "" {
+		cfg.ExecutionLogs.Stream = "prompt-manager:execution-logs"
+	}
+	if cfg.ExecutionLogs.ConsumerGroup == "" {
+		cfg.ExecutionLogs.ConsumerGroup = "prompt-manager"
+	}
+	if cfg.ExecutionLogs.StreamMaxLen <= 0 {
+		cfg.ExecutionLogs.StreamMaxLen = 1000000
+	}
+	if cfg.Metering.FlushInterval <= 0 {
+		cfg.Metering.FlushInterval = 30 * time.Second
+	}
+	if cfg.Metering.ExportInterval <= 0 {
+		cfg.Metering.ExportInterval = time.Hour
+	}
+	if cfg.Telemetry.Interval <= 0 {
+		cfg.Telemetry.Interval = 24 * time.Hour
+	}
+	if cfg.GitSync.Branch == "" {
+		cfg.GitSync.Branch = "main"
+	}
+	if cfg.GitSync.APIBase == "" {
+		cfg.GitSync.APIBase = "https://api.github.com"
+	}
 	if cfg.Auth.GitHub.StateTTL <= 0 {
 		cfg.Auth.GitHub.StateTTL = 5 * time.Minute
 	}
@@ -258,32 +829,268 @@ func applyDefaults(cfg *Config, env string) {
 	if cfg.Auth.GitHub.RedirectURL == "" {
 		cfg.Auth.GitHub.RedirectURL = "http://localhost:8080/api/v1/auth/github/callback"
 	}
+	if cfg.Auth.PasswordPolicy.MinLength <= 0 {
+		cfg.Auth.PasswordPolicy.MinLength = 8
+	}
+	if cfg.Auth.PasswordPolicy.MaxLength <= 0 {
+		cfg.Auth.PasswordPolicy.MaxLength = 128
+	}
+	if cfg.Auth.PasswordHashing.Algorithm == "" {
+		cfg.Auth.PasswordHashing.Algorithm = "argon2id"
+	}
+	if cfg.Auth.PasswordHashing.BcryptCost == 0 {
+		cfg.Auth.PasswordHashing.BcryptCost = 10
+	}
+	if cfg.Auth.PasswordHashing.Argon2.Memory == 0 {
+		cfg.Auth.PasswordHashing.Argon2.Memory = 64 * 1024
+	}
+	if cfg.Auth.PasswordHashing.Argon2.Iterations == 0 {
+		cfg.Auth.PasswordHashing.Argon2.Iterations = 3
+	}
+	if cfg.Auth.PasswordHashing.Argon2.Parallelism == 0 {
+		cfg.Auth.PasswordHashing.Argon2.Parallelism = 2
+	}
+	if cfg.Auth.Registration.Mode == "" {
+		cfg.Auth.Registration.Mode = RegistrationOpen
+	}
+	if cfg.Auth.InvitationTTL <= 0 {
+		cfg.Auth.InvitationTTL = 7 * 24 * time.Hour
+	}
+	if cfg.Auth.Signing.Algorithm == "" {
+		cfg.Auth.Signing.Algorithm = "HS256"
+	}
+	if cfg.Auth.Signing.RotationGrace <= 0 {
+		cfg.Auth.Signing.RotationGrace = cfg.Auth.AccessTokenTTL
+		if cfg.Auth.Signing.RotationGrace <= 0 {
+			cfg.Auth.Signing.RotationGrace = 15 * time.Minute
+		}
+	}
 	if cfg.Logging.Level == "" {
 		cfg.Logging.Level = "info"
 	}
 }
 
-func validateConfig(cfg *Config) error {
-	if err := validateSecret("auth.accessTokenSecret", cfg.Auth.AccessTokenSecret); err != nil {
-		return err
+// validateConfig 逐项校验并返回全部问题，一次启动即可看到所有需要修改的配置。
+func validateConfig(cfg *Config) []error {
+	var problems []error
+	for _, err := range []error{
+		validateSecret("auth.accessTokenSecret", cfg.Auth.AccessTokenSecret),
+		validateSecret("auth.refreshTokenSecret", cfg.Auth.RefreshTokenSecret),
+		validateSecret("auth.apiKeyHashSecret", cfg.Auth.APIKeyHashSecret),
+		validateCORSConfig(cfg.Server.CORS, cfg.App.Env),
+		validateSecurityHeaders(cfg.Server.SecurityHeaders),
+		validateProbesConfig(cfg.Server.Probes),
+		validateSchemaCheckConfig(cfg.Database.SchemaCheck),
+		validateGitHubOAuthConfig(cfg.Auth.GitHub, cfg.App.Env),
+		validateSigningConfig(cfg.Auth.Signing),
+		validateRegistrationConfig(cfg.Auth.Registration),
+		validateRoleMappingConfig(cfg.Auth.RoleMapping),
+		validatePasswordPolicyConfig(cfg.Auth.PasswordPolicy),
+		validatePasswordHashingConfig(cfg.Auth.PasswordHashing),
+		validateSeedConfig(cfg.Seed),
+		validatePromptsConfig(cfg.Prompts),
+		validateFreezeWindows(cfg.Prompts.FreezeWindows),
+		validateModelsConfig(cfg.Models),
+		validateAuditConfig(cfg.Audit),
+		validateStartupConfig(cfg.Startup),
+		validateExecutionLogsConfig(cfg.ExecutionLogs),
+		validateMeteringConfig(cfg.Metering),
+		validateGitSyncConfig(cfg.GitSync),
+		validateTelemetryConfig(cfg.Telemetry),
+	} {
+		if err != nil {
+			problems = append(problems, err)
+		}
 	}
-	if err := validateSecret("auth.refreshTokenSecret", cfg.Auth.RefreshTokenSecret); err != nil {
-		return err
+	return problems
+}
+
+func validateSecretsConfig(secrets SecretsConfig) error {
+	switch secrets.Provider {
+	case "", SecretsProviderVault, SecretsProviderAWS:
+	default:
+		return fmt.Errorf("config secrets.provider must be vault or aws")
 	}
-	if err := validateSecret("auth.apiKeyHashSecret", cfg.Auth.APIKeyHashSecret); err != nil {
-		return err
+	if secrets.RefreshInterval < 0 {
+		return fmt.Errorf("config secrets.refreshInterval must not be negative")
 	}
-	if err := validateCORSConfig(cfg.Server.CORS, cfg.App.Env); err != nil {
-		return err
+	return nil
+}
+
+func validateProbesConfig(probes ProbesConfig) error {
+	for _, path := range probes.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("config server.probes.paths entry %q must start with /", path)
+		}
 	}
-	if err := validateSecurityHeaders(cfg.Server.SecurityHeaders); err != nil {
-		return err
+	for _, cidr := range probes.TrustedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("config server.probes.trustedCIDRs entry %q is not a valid CIDR", cidr)
+		}
 	}
-	if err := validateGitHubOAuthConfig(cfg.Auth.GitHub); err != nil {
-		return err
+	return nil
+}
+
+func validateSchemaCheckConfig(check SchemaCheckConfig) error {
+	switch check.Mode {
+	case SchemaCheckStrict, SchemaCheckReadOnly, SchemaCheckOff:
+	default:
+		return fmt.Errorf("config database.schemaCheck.mode must be strict, readonly or off")
 	}
-	if err := validateSeedConfig(cfg.Seed); err != nil {
-		return err
+	if check.AllowAhead < 0 {
+		return fmt.Errorf("config database.schemaCheck.allowAhead must not be negative")
+	}
+	return nil
+}
+
+func validateStartupConfig(startup StartupConfig) error {
+	if startup.InitialBackoff > startup.MaxBackoff {
+		return fmt.Errorf("config startup.initialBackoff must not exceed startup.maxBackoff")
+	}
+	return nil
+}
+
+func validateAuditConfig(audit AuditConfig) error {
+	if audit.Overflow != "drop" && audit.Overflow != "block" {
+		return fmt.Errorf("config audit.overflow must be drop or block")
+	}
+	if audit.MaxRetries < 0 {
+		return fmt.Errorf("config audit.maxRetries must not be negative")
+	}
+	names := make(map[string]struct{}, len(audit.Sinks))
+	for i, sink := range audit.Sinks {
+		switch sink.Type {
+		case AuditSinkSyslog:
+			if sink.Network != "udp" && sink.Network != "tcp" {
+				return fmt.Errorf("config audit.sinks[%d].network must be udp or tcp", i)
+			}
+			if sink.Address == "" {
+				return fmt.Errorf("config audit.sinks[%d].address is required for syslog", i)
+			}
+		case AuditSinkHTTP, AuditSinkKafka:
+			if sink.URL == "" {
+				return fmt.Errorf("config audit.sinks[%d].url is required for %s", i, sink.Type)
+			}
+			if sink.Type == AuditSinkKafka && sink.Topic == "" {
+				return fmt.Errorf("config audit.sinks[%d].topic is required for kafka", i)
+			}
+		default:
+			return fmt.Errorf("config audit.sinks[%d].type must be syslog, http or kafka", i)
+		}
+		if _, ok := names[sink.Name]; ok {
+			return fmt.Errorf("config audit.sinks[%d].name %q is duplicated", i, sink.Name)
+		}
+		names[sink.Name] = struct{}{}
+	}
+	return nil
+}
+
+func validateExecutionLogsConfig(logs ExecutionLogsConfig) error {
+	switch logs.Mode {
+	case ExecutionLogModeSync, ExecutionLogModeBuffered, ExecutionLogModeRedis:
+	default:
+		return fmt.Errorf("config executionLogs.mode must be sync, buffered or redis")
+	}
+	if logs.Overflow != "drop" && logs.Overflow != "block" {
+		return fmt.Errorf("config executionLogs.overflow must be drop or block")
+	}
+	return nil
+}
+
+func validateMeteringConfig(metering MeteringConfig) error {
+	if target := strings.TrimSpace(metering.Webhook.URL); target != "" {
+		parsed, err := url.Parse(target)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("config metering.webhook.url must be an absolute http(s) url")
+		}
+	}
+	for i, customer := range metering.Stripe.Customers {
+		if strings.TrimSpace(customer.OrganizationID) == "" || strings.TrimSpace(customer.CustomerID) == "" {
+			return fmt.Errorf("config metering.stripe.customers[%d] requires organizationID and customerID", i)
+		}
+	}
+	return nil
+}
+
+func validateGitSyncConfig(sync GitSyncConfig) error {
+	repository := strings.TrimSpace(sync.Repository)
+	if repository == "" {
+		return nil
+	}
+	if owner, name, ok := strings.Cut(repository, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("config gitSync.repository must be formatted as owner/name")
+	}
+	if strings.TrimSpace(sync.WebhookSecret) == "" {
+		return fmt.Errorf("config gitSync.webhookSecret is required when gitSync.repository is set")
+	}
+	parsed, err := url.Parse(strings.TrimSpace(sync.APIBase))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("config gitSync.apiBase must be an absolute http(s) url")
+	}
+	return nil
+}
+
+func validateTelemetryConfig(telemetry TelemetryConfig) error {
+	if !telemetry.Enabled {
+		return nil
+	}
+	parsed, err := url.Parse(strings.TrimSpace(telemetry.Endpoint))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("config telemetry.endpoint must be an absolute http(s) url when telemetry is enabled")
+	}
+	return nil
+}
+
+func validateFreezeWindows(windows []FreezeWindowConfig) error {
+	for i, window := range windows {
+		if _, err := freeze.ParseWindow(window.Spec()); err != nil {
+			return fmt.Errorf("config prompts.freezeWindows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateModelsConfig(models ModelsConfig) error {
+	seen := make(map[string]bool, len(models.Registry))
+	for i, model := range models.Registry {
+		id := llm.NormalizeModelName(model.ID)
+		if !llm.ValidModelName(id) {
+			return fmt.Errorf("config models.registry[%d].id %q is not a valid model name", i, model.ID)
+		}
+		if seen[id] {
+			return fmt.Errorf("config models.registry[%d].id %q is duplicated", i, model.ID)
+		}
+		seen[id] = true
+		if family := llm.NormalizeModelName(model.Family); family != "" && !llm.ValidModelName(family) {
+			return fmt.Errorf("config models.registry[%d].family %q is not a valid model name", i, model.Family)
+		}
+		if model.Status != "" && !llm.ValidModelStatus(model.Status) {
+			return fmt.Errorf("config models.registry[%d].status must be available, deprecated or retired", i)
+		}
+		if replacement := llm.NormalizeModelName(model.Replacement); replacement != "" && !llm.ValidModelName(replacement) {
+			return fmt.Errorf("config models.registry[%d].replacement %q is not a valid model name", i, model.Replacement)
+		}
+	}
+	return nil
+}
+
+func validatePromptsConfig(prompts PromptsConfig) error {
+	if prompts.MaxVersions < 0 {
+		return fmt.Errorf("config prompts.maxVersions must not be negative")
+	}
+	if prompts.MaxBodyBytes < 0 {
+		return fmt.Errorf("config prompts.maxBodyBytes must not be negative")
+	}
+	if prompts.CompressionThreshold < 0 {
+		return fmt.Errorf("config prompts.compressionThreshold must not be negative")
+	}
+	target := strings.TrimSpace(prompts.ActivationWebhookURL)
+	if target == "" {
+		return nil
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("config prompts.activationWebhookURL must be an absolute http(s) url")
 	}
 	return nil
 }
@@ -299,7 +1106,90 @@ func validateSecret(field, secret string) error {
 	return nil
 }
 
-func validateGitHubOAuthConfig(oauth GitHubOAuthConfig) error {
+func validateRegistrationConfig(registration RegistrationConfig) error {
+	switch registration.Mode {
+	case RegistrationOpen, RegistrationClosed, RegistrationApproval:
+	default:
+		return fmt.Errorf("config auth.registration.mode must be one of open, closed, approval")
+	}
+	for _, domain := range registration.AllowedDomains {
+		clean := strings.TrimSpace(domain)
+		if clean == "" || strings.Contains(clean, "@") {
+			return fmt.Errorf("config auth.registration.allowedDomains contains invalid entry %q", domain)
+		}
+	}
+	return nil
+}
+
+func validateRoleMappingConfig(mapping RoleMappingConfig) error {
+	isRole := func(role string) bool {
+		switch strings.ToLower(strings.TrimSpace(role)) {
+		case "admin", "editor", "viewer":
+			return true
+		}
+		return false
+	}
+	for i, rule := range mapping.Rules {
+		field := fmt.Sprintf("auth.roleMapping.rules[%d]", i)
+		if strings.TrimSpace(rule.Claim) == "" {
+			return fmt.Errorf("config %s.claim is required", field)
+		}
+		if len(rule.Values) == 0 {
+			return fmt.Errorf("config %s.values must not be empty", field)
+		}
+		if rule.Role == "" && rule.WorkspaceID == "" {
+			return fmt.Errorf("config %s must set role or workspaceId", field)
+		}
+		if rule.Role != "" && !isRole(rule.Role) {
+			return fmt.Errorf("config %s.role must be one of admin, editor, viewer", field)
+		}
+		if rule.WorkspaceID != "" && !isRole(rule.WorkspaceRole) {
+			return fmt.Errorf("config %s.workspaceRole must be one of admin, editor, viewer", field)
+		}
+	}
+	return nil
+}
+
+func validatePasswordPolicyConfig(policy PasswordPolicyConfig) error {
+	if policy.MaxLength < policy.MinLength {
+		return fmt.Errorf("config auth.passwordPolicy.maxLength must not be less than minLength")
+	}
+	if policy.MinCharacterClasses < 0 || policy.MinCharacterClasses > 4 {
+		return fmt.Errorf("config auth.passwordPolicy.minCharacterClasses must be between 0 and 4")
+	}
+	return nil
+}
+
+func validatePasswordHashingConfig(hashing PasswordHashingConfig) error {
+	switch hashing.Algorithm {
+	case "argon2id", "bcrypt":
+	default:
+		return fmt.Errorf("config auth.passwordHashing.algorithm must be one of argon2id, bcrypt")
+	}
+	if hashing.BcryptCost < 4 || hashing.BcryptCost > 31 {
+		return fmt.Errorf("config auth.passwordHashing.bcryptCost must be between 4 and 31")
+	}
+	if hashing.Argon2.Memory < 8*uint32(hashing.Argon2.Parallelism) {
+		return fmt.Errorf("config auth.passwordHashing.argon2.memory must be at least 8 KiB per lane")
+	}
+	return nil
+}
+
+func validateSigningConfig(signing SigningConfig) error {
+	switch signing.Algorithm {
+	case "HS256", "RS256", "EdDSA":
+	default:
+		return fmt.Errorf("config auth.signing.algorithm must be one of HS256, RS256, EdDSA")
+	}
+	if signing.EncryptionKey != "" {
+		if err := validateSecret("auth.signing.encryptionKey", signing.EncryptionKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateGitHubOAuthConfig(oauth GitHubOAuthConfig, env string) error {
 	if !oauth.Enabled {
 		return nil
 	}
@@ -326,9 +1216,28 @@ func validateGitHubOAuthConfig(oauth GitHubOAuthConfig) error {
 			return fmt.Errorf("config auth.github.allowedOrgs contains empty entry")
 		}
 	}
+	for _, team := range oauth.AllowedTeams {
+		org, slug, ok := strings.Cut(strings.TrimSpace(team), "/")
+		if !ok || org == "" || slug == "" {
+			return fmt.Errorf("config auth.github.allowedTeams entry %q must be org/team-slug", team)
+		}
+	}
+	for i, mapping := range oauth.RoleMappings {
+		if strings.TrimSpace(mapping.Match) == "" {
+			return fmt.Errorf("config auth.github.roleMappings[%d].match is required", i)
+		}
+		switch strings.ToLower(strings.TrimSpace(mapping.Role)) {
+		case "admin", "editor", "viewer":
+		default:
+			return fmt.Errorf("config auth.github.roleMappings[%d].role must be one of admin, editor, viewer", i)
+		}
+	}
 	if oauth.StateTTL <= 0 {
 		return fmt.Errorf("config auth.github.stateTTL must be positive")
 	}
+	if err := validateRedirectOrigins("auth.github.allowedRedirectOrigins", oauth.AllowedRedirectOrigins, env); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -342,6 +1251,53 @@ func validateCORSConfig(corsCfg CORSConfig, env string) error {
 			return fmt.Errorf("config server.cors.allowOrigins must not use wildcard '*' in production")
 		}
 	}
+	for _, header := range corsCfg.AllowHeaders {
+		if strings.TrimSpace(header) == "" {
+			return fmt.Errorf("config server.cors.allowHeaders must not contain empty entries")
+		}
+	}
+	for i, override := range corsCfg.Overrides {
+		field := fmt.Sprintf("server.cors.overrides[%d]", i)
+		if !strings.HasPrefix(override.PathPrefix, "/") {
+			return fmt.Errorf("config %s.pathPrefix must start with '/'", field)
+		}
+		if len(override.AllowOrigins) == 0 {
+			return fmt.Errorf("config %s.allowOrigins must not be empty", field)
+		}
+		for _, origin := range override.AllowOrigins {
+			clean := strings.TrimSpace(origin)
+			if clean == "" {
+				return fmt.Errorf("config %s.allowOrigins must not contain empty entries", field)
+			}
+			// 覆盖规则用于显式开放公共接口，允许 '*'，但不得同时携带凭据。
+			if clean == "*" && override.AllowCredentials {
+				return fmt.Errorf("config %s must not combine wildcard '*' with allowCredentials", field)
+			}
+		}
+		for _, header := range override.AllowHeaders {
+			if strings.TrimSpace(header) == "" {
+				return fmt.Errorf("config %s.allowHeaders must not contain empty entries", field)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRedirectOrigins 校验 OAuth 回跳来源白名单，供各身份提供方复用。
+func validateRedirectOrigins(field string, origins []string, env string) error {
+	if env == "production" && len(origins) == 0 {
+		return fmt.Errorf("config %s must be set in production", field)
+	}
+	for _, origin := range origins {
+		clean := strings.TrimSpace(origin)
+		if clean == "" || clean == "*" {
+			return fmt.Errorf("config %s contains invalid entry %q", field, origin)
+		}
+		u, err := url.Parse(strings.Replace(clean, "*.", "wildcard.", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return fmt.Errorf("config %s entry %q must be an origin like https://app.example.com", field, origin)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// includeDirName 为按文件名顺序自动合并的配置片段目录，便于不同团队各自维护限流、认证等配置。
+const includeDirName = "config.d"
+
+// mergeIncludes 依次合并 default.yaml 中 includes 列出的文件与 config.d 目录下的 .yaml/.yml 文件。
+// includes 中的路径相对配置目录，可使用通配符（无匹配时报错，避免拼写错误被静默忽略）；
+// config.d 不存在时跳过。映射按键深度合并，列表整体替换。
+func mergeIncludes(v *viper.Viper, configDir string) error {
+	var files []string
+	for _, pattern := range v.GetStringSlice("includes") {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(configDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("config includes %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("config includes %q matched no files", pattern)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(configDir, includeDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read %s: %w", includeDirName, err)
+	}
+	// os.ReadDir 已按文件名排序，可用 10-auth.yaml、20-ratelimit.yaml 之类的前缀控制顺序。
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		files = append(files, filepath.Join(configDir, includeDirName, entry.Name()))
+	}
+
+	for _, file := range files {
+		part := viper.New()
+		part.SetConfigType(configType)
+		part.SetConfigFile(file)
+		if err := part.ReadInConfig(); err != nil {
+			return fmt.Errorf("read included config %s: %w", file, err)
+		}
+		if part.IsSet("includes") {
+			return fmt.Errorf("included config %s must not declare includes", file)
+		}
+		if err := v.MergeConfigMap(part.AllSettings()); err != nil {
+			return fmt.Errorf("merge included config %s: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/pkg/secrets"
+)
+
+const (
+	// secretFilePrefix 标记从文件读取的配置值，常用于挂载的 Docker/Kubernetes Secret。
+	secretFilePrefix = "file://"
+	// redactedValue 替换配置导出中的敏感值。
+	redactedValue = "******"
+	// secretResolveTimeout 为启动时读取全部 secret:// 引用的总时限。
+	secretResolveTimeout = 30 * time.Second
+)
+
+var (
+	envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+	dsnPasswordPattern  = regexp.MustCompile(`(?i)(password=)('[^']*'|\S+)`)
+)
+
+// expandValueHook 为解码钩子：先把字符串中的 ${VAR} 与 ${VAR:-默认值} 替换为环境变量，
+// 再把以 file:// 开头的值替换为对应文件的内容（去掉末尾换行）。引用未设置的变量或读取文件失败时返回错误。
+func expandValueHook(from reflect.Type, _ reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	return expandValue(data.(string))
+}
+
+func expandValue(value string) (string, error) {
+	var missing []string
+	expanded := envReferencePattern.ReplaceAllStringFunc(value, func(ref string) string {
+		match := envReferencePattern.FindStringSubmatch(ref)
+		env, ok := os.LookupEnv(match[1])
+		if match[2] != "" {
+			if env != "" {
+				return env
+			}
+			return match[3]
+		}
+		if ok {
+			return env
+		}
+		missing = append(missing, match[1])
+		return ref
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+
+	if !strings.HasPrefix(expanded, secretFilePrefix) {
+		return expanded, nil
+	}
+	path := strings.TrimPrefix(expanded, secretFilePrefix)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read secret file: %w", err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// secretRefs 保存 secret:// 引用及最近一次读取到的值，键为配置路径（如 auth.github.clientSecret）。
+type secretRefs struct {
+	provider secrets.Provider
+	refs     map[string]secrets.Reference
+	values   map[string]string
+}
+
+// resolveSecretRefs 将结构体中形如 secret://<路径>#<字段> 的字符串替换为密钥服务中的值。
+func resolveSecretRefs(ctx context.Context, cfg *Config) error {
+	fields := make(map[string]reflect.Value)
+	collectSecretFields(reflect.ValueOf(cfg).Elem(), "", fields)
+	if len(fields) == 0 {
+		return nil
+	}
+	provider, err := newSecretProvider(cfg.Secrets)
+	if err != nil {
+		return err
+	}
+
+	refs := &secretRefs{provider: provider, refs: make(map[string]secrets.Reference), values: make(map[string]string)}
+	for _, path := range sortedKeys(fields) {
+		field := fields[path]
+		ref, _, err := secrets.ParseReference(field.String())
+		if err != nil {
+			return fmt.Errorf("config %s: %w", path, err)
+		}
+		value, err := secrets.Resolve(ctx, provider, ref)
+		if err != nil {
+			return fmt.Errorf("config %s: %w", path, err)
+		}
+		field.SetString(value)
+		refs.refs[path] = ref
+		refs.values[path] = value
+	}
+	cfg.secretRefs = refs
+	return nil
+}
+
+func collectSecretFields(value reflect.Value, prefix string, fields map[string]reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			collectSecretFields(field, prefix+name+".", fields)
+		case reflect.String:
+			if strings.HasPrefix(field.String(), secrets.ReferencePrefix) {
+				fields[prefix+name] = field
+			}
+		}
+	}
+}
+
+func newSecretProvider(cfg SecretsConfig) (secrets.Provider, error) {
+	switch cfg.Provider {
+	case SecretsProviderVault:
+		if cfg.Vault.Addr == "" || cfg.Vault.Token == "" {
+			return nil, fmt.Errorf("config secrets.vault.addr and secrets.vault.token are required")
+		}
+		return secrets.NewVault(cfg.Vault.Addr, cfg.Vault.Token, cfg.Vault.Mount, cfg.Vault.Namespace), nil
+	case SecretsProviderAWS:
+		if cfg.AWS.Region == "" {
+			return nil, fmt.Errorf("config secrets.aws.region is required")
+		}
+		creds, err := secrets.AWSCredentialsFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("config secrets.aws: %w", err)
+		}
+		return secrets.NewAWSSecretsManager(cfg.AWS.Region, cfg.AWS.Endpoint, creds), nil
+	default:
+		return nil, fmt.Errorf("config uses secret:// references but secrets.provider is not set")
+	}
+}
+
+// HasSecretRefs 表示配置中是否有从密钥服务读取的值。
+func (c *Config) HasSecretRefs() bool {
+	return c.secretRefs != nil
+}
+
+// RefreshSecrets 重新读取 secret:// 引用的密钥，返回自上次读取以来发生变化的配置路径及新值。
+// 不修改配置本身，由调用方决定哪些变化可以在线生效；不可并发调用。
+func (c *Config) RefreshSecrets(ctx context.Context) (map[string]string, error) {
+	if c.secretRefs == nil {
+		return nil, nil
+	}
+	changed := make(map[string]string)
+	for _, path := range sortedKeys(c.secretRefs.refs) {
+		value, err := secrets.Resolve(ctx, c.secretRefs.provider, c.secretRefs.refs[path])
+		if err != nil {
+			return changed, fmt.Errorf("config %s: %w", path, err)
+		}
+		if value != c.secretRefs.values[path] {
+			c.secretRefs.values[path] = value
+			changed[path] = value
+		}
+	}
+	return changed, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Redacted 以配置文件的键名导出生效配置，带 secret 标签的字段被替换为 ******（为空时保持为空），
+// 数据库 DSN 只隐藏其中的密码，便于排查配置而不泄露密钥。
+func Redacted(cfg *Config) map[string]interface{} {
+	return redactStruct(reflect.ValueOf(cfg).Elem())
+}
+
+func redactStruct(value reflect.Value) map[string]interface{} {
+	out := make(map[string]interface{}, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		out[name] = redactValue(value.Field(i), field.Tag.Get("secret"))
+	}
+	return out
+}
+
+func redactValue(value reflect.Value, secret string) interface{} {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(value.Int()).String()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return redactStruct(value)
+	case reflect.Slice:
+		items := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, redactValue(value.Index(i), secret))
+		}
+		return items
+	case reflect.String:
+		switch {
+		case value.String() == "":
+			return ""
+		case secret == "dsn":
+			return redactDSN(value.String())
+		case secret != "":
+			return redactedValue
+		}
+	}
+	return value.Interface()
+}
+
+// redactDSN 隐藏 URL 形式（user:pass@host）与键值形式（password=...）DSN 中的密码。
+func redactDSN(dsn string) string {
+	if parsed, err := url.Parse(dsn); err == nil && parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			// 直接写入 ****** 会被百分号编码，先用不需转义的占位符再替换。
+			parsed.User = url.UserPassword(parsed.User.Username(), "REDACTED")
+			return strings.Replace(parsed.String(), ":REDACTED@", ":"+redactedValue+"@", 1)
+		}
+	}
+	return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+redactedValue)
+}
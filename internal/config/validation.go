@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ValidationError 汇总加载配置时发现的全部问题（未识别的键与取值错误）。
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "config has %d problems:", len(e.Problems))
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem.Error())
+	}
+	return b.String()
+}
+
+// Unwrap 支持 errors.Is/As 匹配其中任一问题。
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+var sliceIndexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// unknownKeyErrors 为每个未识别的键生成错误，并在同级存在拼写相近的键时给出建议。
+// viper 会将键统一转为小写，因此按不区分大小写的方式比较。
+func unknownKeyErrors(unused []string) []error {
+	if len(unused) == 0 {
+		return nil
+	}
+	known := make(map[string][]string)
+	collectKnownKeys(reflect.TypeOf(Config{}), "", known)
+
+	problems := make([]error, 0, len(unused))
+	for _, key := range unused {
+		normalized := sliceIndexPattern.ReplaceAllString(key, "")
+		parent, name := "", normalized
+		if idx := strings.LastIndex(normalized, "."); idx >= 0 {
+			parent, name = normalized[:idx], normalized[idx+1:]
+		}
+		if suggestion := closestKey(name, known[strings.ToLower(parent)]); suggestion != "" {
+			problems = append(problems, fmt.Errorf("config key %q is not recognized; did you mean %q?", key, suggestion))
+			continue
+		}
+		problems = append(problems, fmt.Errorf("config key %q is not recognized", key))
+	}
+	return problems
+}
+
+// collectKnownKeys 按父路径（小写）记录结构体允许的子键名。
+func collectKnownKeys(t reflect.Type, prefix string, known map[string][]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		parent := strings.ToLower(strings.TrimSuffix(prefix, "."))
+		known[parent] = append(known[parent], name)
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Duration(0)) {
+			collectKnownKeys(fieldType, prefix+name+".", known)
+		}
+	}
+}
+
+// closestKey 返回编辑距离不超过 2（或互为前缀）的最接近候选，没有时返回空字符串。
+func closestKey(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	lower := strings.ToLower(name)
+	for _, candidate := range candidates {
+		candidateLower := strings.ToLower(candidate)
+		distance := levenshtein(lower, candidateLower)
+		if distance > 2 && (strings.HasPrefix(candidateLower, lower) || strings.HasPrefix(lower, candidateLower)) {
+			distance = 2
+		}
+		if distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+		}
+		prev = current
+	}
+	return prev[len(b)]
+}
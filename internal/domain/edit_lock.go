@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// EditLock 为 Prompt 的编辑占用标记（咨询锁），仅用于提示其他编辑者，不阻止写入。
+type EditLock struct {
+	PromptID   string    `json:"prompt_id"`
+	HolderID   string    `json:"holder_id"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// EditLockStore 定义编辑锁的存取接口，锁到期后自动释放。
+type EditLockStore interface {
+	// Acquire 在锁空闲或已由同一 HolderID 持有时写入（续期）并返回 acquired=true，
+	// 否则返回当前持有的锁与 acquired=false。
+	Acquire(ctx context.Context, lock EditLock, ttl time.Duration) (current *EditLock, acquired bool, err error)
+	// Get 返回当前锁，空闲时返回 nil。
+	Get(ctx context.Context, promptID string) (*EditLock, error)
+	// Release 仅在锁由 holderID 持有时删除，返回是否删除。
+	Release(ctx context.Context, promptID, holderID string) (bool, error)
+}
@@ -2,7 +2,11 @@ package domain
 
 import (
 	"encoding/json"
+	"sort"
+	"strconv"
 	"time"
+
+	"github.com/zacharykka/prompt-manager/pkg/audit"
 )
 
 // User 表示系统中的登录用户。
@@ -36,12 +40,132 @@ type Prompt struct {
 	Description     *string         `json:"description,omitempty"`
 	Tags            json.RawMessage `json:"tags,omitempty"`
 	ActiveVersionID *string         `json:"active_version_id,omitempty"`
-	Body            *string         `json:"body,omitempty"`
-	CreatedBy       *string         `json:"created_by,omitempty"`
-	Status          string          `json:"status"`
-	DeletedAt       *time.Time      `json:"deleted_at,omitempty"`
-	CreatedAt       time.Time       `json:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at"`
+	// PreviousActiveVersionID 为上一次切换前的激活版本，作为一键回滚的目标。
+	PreviousActiveVersionID *string      `json:"previous_active_version_id,omitempty"`
+	Body                    *string      `json:"body,omitempty"`
+	CreatedBy               *string      `json:"created_by,omitempty"`
+	Status                  string       `json:"status"`
+	DeletedAt               *time.Time   `json:"deleted_at,omitempty"`
+	RenderMode              *string      `json:"render_mode,omitempty"`
+	Owner                   *PromptOwner `json:"owner,omitempty"`
+	// ModelDeprecation 仅由列表接口填充：激活版本 metadata.model 引用的模型在注册表中已弃用或下线。
+	ModelDeprecation *ModelDeprecation `json:"model_deprecation,omitempty"`
+	WorkspaceID      string            `json:"workspace_id"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// Prompt 状态取值；已归档的 Prompt 不出现在默认列表中且禁止执行，但仍可查看与恢复。
+const (
+	PromptStatusActive   = "active"
+	PromptStatusArchived = "archived"
+	PromptStatusDeleted  = "deleted"
+)
+
+// ModelDeprecation 描述 Prompt 引用的弃用模型：Status 为 deprecated 或 retired，Replacement 为建议迁移到的模型。
+type ModelDeprecation struct {
+	Model       string `json:"model"`
+	Status      string `json:"status"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// PromptOwner 为 Prompt 的负责人，独立于创建人；Type 为 user 时 ID 为用户 ID，为 team 时 ID 为团队标识（如 org/team-slug）。
+type PromptOwner struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Prompt 负责人类型。
+const (
+	PromptOwnerUser = "user"
+	PromptOwnerTeam = "team"
+)
+
+// Organization 表示组织，包含多个工作区。
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedBy *string   `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Workspace 表示组织下的工作区，Prompt 与成员角色按工作区隔离。
+type Workspace struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Slug           string    `json:"slug"`
+	CreatedBy      *string   `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Role 为当前用户在该工作区的角色，仅在按用户列出工作区时填充。
+	Role string `json:"role,omitempty"`
+}
+
+// WorkspaceMember 描述用户在工作区中的角色。
+type WorkspaceMember struct {
+	WorkspaceID string    `json:"workspace_id"`
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email,omitempty"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Invitation 表示邀请用户加入工作区的待接受邀请，令牌仅以哈希形式存储。
+type Invitation struct {
+	ID             string     `json:"id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	WorkspaceID    string     `json:"workspace_id"`
+	TokenHash      string     `json:"-"`
+	InvitedBy      *string    `json:"invited_by,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserID *string    `json:"accepted_user_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// LoginEvent 记录一次登录或令牌刷新，DeviceHash 由 User-Agent 派生，用于识别新设备。
+type LoginEvent struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	EventType  string    `json:"event_type"`
+	Provider   string    `json:"provider"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	DeviceHash string    `json:"-"`
+	NewDevice  bool      `json:"new_device"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// API Key 的权限范围；admin 隐含其余全部范围。write 对应 editor 权限，可创建与修改 Prompt、Pipeline。
+const (
+	APIKeyScopeRead    = "read"
+	APIKeyScopeRender  = "render"
+	APIKeyScopeExecute = "execute"
+	APIKeyScopeWrite   = "write"
+	APIKeyScopeAdmin   = "admin"
+)
+
+// APIKey 为机器对机器访问签发的密钥，明文只在创建时返回一次，库中仅保存 HMAC 摘要。
+type APIKey struct {
+	ID      string   `json:"id"`
+	UserID  string   `json:"user_id"`
+	Name    string   `json:"name"`
+	Prefix  string   `json:"prefix"`
+	KeyHash string   `json:"-"`
+	Scopes  []string `json:"scopes"`
+	// RateLimit 为每分钟请求上限，0 表示使用服务默认值。
+	RateLimit int `json:"rate_limit"`
+	// DailyQuota 为每 24 小时的请求配额，0 表示不限。
+	DailyQuota int        `json:"daily_quota"`
+	CreatedBy  *string    `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 }
 
 // PromptVersion 记录 Prompt 的具体模板内容与变量信息。
@@ -53,8 +177,44 @@ type PromptVersion struct {
 	VariablesSchema json.RawMessage `json:"variables_schema,omitempty"`
 	Status          string          `json:"status"`
 	Metadata        json.RawMessage `json:"metadata,omitempty"`
-	CreatedBy       *string         `json:"created_by,omitempty"`
-	CreatedAt       time.Time       `json:"created_at"`
+	// TargetModels 为 metadata.target_models 声明的目标模型或模型系列（已规范化），未声明时为空。
+	TargetModels []string  `json:"target_models,omitempty"`
+	CreatedBy    *string   `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PromptVersion 状态取值，只允许 draft → published → archived 单向流转；已归档版本不可激活。
+const (
+	PromptVersionStatusDraft     = "draft"
+	PromptVersionStatusPublished = "published"
+	PromptVersionStatusArchived  = "archived"
+)
+
+// PromptVersionLocale 记录某个版本在特定语言区域下的正文变体。
+type PromptVersionLocale struct {
+	ID        string    `json:"id"`
+	VersionID string    `json:"version_id"`
+	Locale    string    `json:"locale"`
+	Body      string    `json:"body"`
+	CreatedBy *string   `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PromptLocaleCoverage 描述 Prompt 当前激活版本已具备的语言变体。
+type PromptLocaleCoverage struct {
+	PromptID        string   `json:"prompt_id"`
+	PromptName      string   `json:"prompt_name"`
+	ActiveVersionID string   `json:"active_version_id"`
+	Locales         []string `json:"locales"`
+}
+
+// PromptDependencyLink 描述依赖图中的一条边，Source 为 explicit（显式声明）或 include（正文引用）。
+type PromptDependencyLink struct {
+	PromptID string `json:"prompt_id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Source   string `json:"source"`
 }
 
 // PromptExecutionLog 记录 Prompt 运行时日志。
@@ -67,17 +227,157 @@ type PromptExecutionLog struct {
 	DurationMs       int64           `json:"duration_ms"`
 	RequestPayload   json.RawMessage `json:"request_payload,omitempty"`
 	ResponseMetadata json.RawMessage `json:"response_metadata,omitempty"`
-	CreatedAt        time.Time       `json:"created_at"`
+	// ErrorClass 为失败分类（见 ExecutionError* 常量），ErrorCode 为提供方或内部错误码，成功时为空。
+	ErrorClass *string   `json:"error_class,omitempty"`
+	ErrorCode  *string   `json:"error_code,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
+// 执行日志的错误分类。
+const (
+	ExecutionErrorTimeout        = "timeout"
+	ExecutionErrorProvider       = "provider_error"
+	ExecutionErrorGuardrailBlock = "guardrail_block"
+	ExecutionErrorValidation     = "validation"
+)
+
 // PromptExecutionAggregate 描述某一时间区间的统计信息。
 type PromptExecutionAggregate struct {
+	// Day 为分桶在所选时区下的起点，以 UTC 表示；Bucket 为该时区下的起点文本（按小时分桶时含时分）。
 	Day           time.Time `json:"day"`
+	Bucket        string    `json:"bucket"`
 	TotalCalls    int       `json:"total_calls"`
 	SuccessCalls  int       `json:"success_calls"`
 	AverageMillis float64   `json:"average_ms"`
+	// P50Millis/P90Millis/P99Millis 为耗时分位数（线性插值），无耗时数据时为 0。
+	P50Millis float64 `json:"p50_ms"`
+	P90Millis float64 `json:"p90_ms"`
+	P99Millis float64 `json:"p99_ms"`
+	// ErrorClasses 为该分桶内按错误分类统计的失败次数。
+	ErrorClasses map[string]int `json:"error_classes,omitempty"`
+}
+
+// PromptExecutionWindow 汇总某一时间窗口内的执行情况，供告警规则评估使用。
+type PromptExecutionWindow struct {
+	TotalCalls  int
+	FailedCalls int
+	P95Millis   float64
+}
+
+// 告警规则支持的指标与状态。
+const (
+	AlertMetricErrorRate  = "error_rate"
+	AlertMetricP95Latency = "p95_latency"
+
+	AlertStateOK     = "ok"
+	AlertStateFiring = "firing"
+)
+
+// PromptTemplate 为新建 Prompt 时可选的起始脚手架（如 RAG 问答、摘要），由管理员维护，全局共享。
+type PromptTemplate struct {
+	ID              string          `json:"id"`
+	Slug            string          `json:"slug"`
+	Name            string          `json:"name"`
+	Description     *string         `json:"description,omitempty"`
+	Body            string          `json:"body"`
+	VariablesSchema json.RawMessage `json:"variables_schema,omitempty"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+	Tags            json.RawMessage `json:"tags,omitempty"`
+	CreatedBy       *string         `json:"created_by,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// PromptDraft 为用户在某个 Prompt 上尚未保存为版本的草稿，每人每个 Prompt 一份，不进入版本历史。
+type PromptDraft struct {
+	PromptID        string          `json:"prompt_id"`
+	UserID          string          `json:"user_id"`
+	Body            string          `json:"body"`
+	VariablesSchema json.RawMessage `json:"variables_schema,omitempty"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+	// BaseVersionID 为开始编辑时所基于的版本，用于判断草稿是否已落后于最新版本。
+	BaseVersionID *string `json:"base_version_id,omitempty"`
+	// Revision 每次保存递增，客户端回传以检测并发覆盖。
+	Revision  int       `json:"revision"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PromptReviewPolicy 为 Prompt 的评审要求：激活版本前需获得 RequiredApprovals 个合格评审人的批准。
+// Reviewers 为空时任何可写成员都可评审，否则评审人需为列表中的用户或团队成员。
+type PromptReviewPolicy struct {
+	PromptID          string        `json:"prompt_id"`
+	RequiredApprovals int           `json:"required_approvals"`
+	Reviewers         []PromptOwner `json:"reviewers"`
+	UpdatedBy         *string       `json:"updated_by,omitempty"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+// PromptVersionApproval 为评审人对某个版本的批准，每人每个版本一条。
+type PromptVersionApproval struct {
+	VersionID  string    `json:"version_id"`
+	PromptID   string    `json:"prompt_id"`
+	ReviewerID string    `json:"reviewer_id"`
+	Reviewer   *string   `json:"reviewer,omitempty"`
+	Comment    *string   `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PromptAlertRule 描述基于 Prompt 执行指标的告警规则及最近一次评估的状态。
+type PromptAlertRule struct {
+	ID       string `json:"id"`
+	PromptID string `json:"prompt_id"`
+	Name     string `json:"name"`
+	Metric   string `json:"metric"`
+	// Threshold 对 error_rate 为百分比（0-100），对 p95_latency 为毫秒。
+	Threshold     float64 `json:"threshold"`
+	WindowMinutes int     `json:"window_minutes"`
+	WebhookURL    *string `json:"webhook_url,omitempty"`
+	// WebhookSecret 为 webhook 请求体的 HMAC-SHA256 签名密钥，只在创建与轮换时返回一次；
+	// 轮换后 PreviousWebhookSecret 在 PreviousSecretExpiresAt 之前继续参与签名，便于接收方平滑切换。
+	WebhookSecret           string     `json:"-"`
+	PreviousWebhookSecret   *string    `json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+	Enabled                 bool       `json:"enabled"`
+	State                   string     `json:"state"`
+	LastValue               *float64   `json:"last_value,omitempty"`
+	LastEvaluatedAt         *time.Time `json:"last_evaluated_at,omitempty"`
+	StateChangedAt          *time.Time `json:"state_changed_at,omitempty"`
+	CreatedBy               *string    `json:"created_by,omitempty"`
+	CreatedAt               time.Time  `json:"created_at"`
 }
 
+// PromptCanary 为一次灰度激活：解析激活版本时按 Percent 的比例改用 VersionID，
+// 调度器比较其与 BaselineVersionID 在窗口内的错误率，超出 MaxErrorRateDelta（百分点）时自动回滚。
+type PromptCanary struct {
+	ID                string     `json:"id"`
+	PromptID          string     `json:"prompt_id"`
+	VersionID         string     `json:"version_id"`
+	BaselineVersionID string     `json:"baseline_version_id"`
+	Percent           int        `json:"percent"`
+	MaxErrorRateDelta float64    `json:"max_error_rate_delta"`
+	MinCalls          int        `json:"min_calls"`
+	WindowMinutes     int        `json:"window_minutes"`
+	WebhookURL        *string    `json:"webhook_url,omitempty"`
+	Status            string     `json:"status"`
+	CanaryErrorRate   *float64   `json:"canary_error_rate,omitempty"`
+	BaselineErrorRate *float64   `json:"baseline_error_rate,omitempty"`
+	Reason            *string    `json:"reason,omitempty"`
+	StartedBy         *string    `json:"started_by,omitempty"`
+	StartedAt         time.Time  `json:"started_at"`
+	LastEvaluatedAt   *time.Time `json:"last_evaluated_at,omitempty"`
+	EndedAt           *time.Time `json:"ended_at,omitempty"`
+}
+
+// 灰度状态：running 进行中，promoted 已全量激活，rolled_back 因错误率超限自动回滚，aborted 被手动终止或被其他激活取代。
+const (
+	CanaryStatusRunning    = "running"
+	CanaryStatusPromoted   = "promoted"
+	CanaryStatusRolledBack = "rolled_back"
+	CanaryStatusAborted    = "aborted"
+)
+
 // PromptAuditLog 记录 Prompt 相关的审计事件。
 type PromptAuditLog struct {
 	ID        string          `json:"id"`
@@ -86,4 +386,228 @@ type PromptAuditLog struct {
 	Payload   json.RawMessage `json:"payload,omitempty"`
 	CreatedBy *string         `json:"created_by,omitempty"`
 	CreatedAt time.Time       `json:"created_at"`
+	// Seq 为哈希链中的序号，链式哈希引入前写入的记录为 0。
+	Seq      int64  `json:"seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// ChainHash 基于 PrevHash 与记录内容计算哈希链中的哈希值，写入与校验共用同一实现。
+func (l *PromptAuditLog) ChainHash() string {
+	return audit.ChainHash(l.PrevHash,
+		strconv.FormatInt(l.Seq, 10),
+		l.ID,
+		l.PromptID,
+		l.Action,
+		string(l.Payload),
+		derefString(l.CreatedBy),
+		audit.Timestamp(l.CreatedAt),
+	)
+}
+
+// AuditLog 记录 Prompt 之外的管理与认证事件（登录、角色变更、密钥轮换等），与 Prompt 审计共用哈希链机制。
+type AuditLog struct {
+	ID         string          `json:"id"`
+	Action     string          `json:"action"`
+	Actor      *string         `json:"actor,omitempty"`
+	TargetType string          `json:"target_type,omitempty"`
+	TargetID   *string         `json:"target_id,omitempty"`
+	IP         *string         `json:"ip,omitempty"`
+	UserAgent  *string         `json:"user_agent,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Seq        int64           `json:"seq,omitempty"`
+	PrevHash   string          `json:"prev_hash,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+}
+
+// ChainHash 基于 PrevHash 与记录内容计算哈希链中的哈希值。
+func (l *AuditLog) ChainHash() string {
+	return audit.ChainHash(l.PrevHash,
+		strconv.FormatInt(l.Seq, 10),
+		l.ID,
+		l.Action,
+		derefString(l.Actor),
+		l.TargetType,
+		derefString(l.TargetID),
+		derefString(l.IP),
+		derefString(l.UserAgent),
+		string(l.Payload),
+		audit.Timestamp(l.CreatedAt),
+	)
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// Pipeline 描述由多个 Prompt 步骤组成的执行链。
+type Pipeline struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Description   *string   `json:"description,omitempty"`
+	LatestVersion int       `json:"latest_version"`
+	WorkspaceID   string    `json:"workspace_id"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// PipelineVersion 记录某一版本的步骤定义（JSON 序列化的 DAG）。
+type PipelineVersion struct {
+	ID            string          `json:"id"`
+	PipelineID    string          `json:"pipeline_id"`
+	VersionNumber int             `json:"version_number"`
+	Steps         json.RawMessage `json:"steps"`
+	CreatedBy     *string         `json:"created_by,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// SigningKey 记录 JWT 签名密钥，私钥材料以所属组织派生的密钥加密保存。
+type SigningKey struct {
+	ID             string     `json:"kid"`
+	OrganizationID string     `json:"organization_id"`
+	Algorithm      string     `json:"algorithm"`
+	EncryptedKey   string     `json:"-"`
+	Status         string     `json:"status"`
+	CreatedBy      *string    `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RetiresAt      *time.Time `json:"retires_at,omitempty"`
+}
+
+// 计量指标名称。
+const (
+	UsageMetricAPICalls     = "api_calls"
+	UsageMetricExecutions   = "executions"
+	UsageMetricStorageBytes = "storage_bytes"
+)
+
+// UsageCounter 为工作区在某计量周期（UTC 自然月，格式 YYYY-MM）内某指标的增量。
+type UsageCounter struct {
+	WorkspaceID string
+	Period      string
+	Metric      string
+	Value       int64
+}
+
+// TenantUsage 汇总组织（租户）在计量周期内的用量；StorageBytes 为查询时的快照。
+type TenantUsage struct {
+	OrganizationID   string `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	Period           string `json:"period"`
+	APICalls         int64  `json:"api_calls"`
+	Executions       int64  `json:"executions"`
+	StorageBytes     int64  `json:"storage_bytes"`
+}
+
+// StorageUsage 为一类数据的行数与估算存储字节数，字节数只累计正文与 JSON 等可变长列，不含索引与行开销。
+type StorageUsage struct {
+	Rows  int64 `json:"rows"`
+	Bytes int64 `json:"estimated_bytes"`
+}
+
+// Add 累加另一组占用。
+func (u *StorageUsage) Add(other StorageUsage) {
+	u.Rows += other.Rows
+	u.Bytes += other.Bytes
+}
+
+// TenantStorage 汇总组织（租户）各类数据的存储占用。HistoryVersions 为 Versions 中非激活的历史版本，
+// 即版本保留策略可以回收的部分；AuditLogs 为 Prompt 审计日志。
+type TenantStorage struct {
+	OrganizationID   string       `json:"organization_id"`
+	OrganizationName string       `json:"organization_name"`
+	Prompts          StorageUsage `json:"prompts"`
+	Versions         StorageUsage `json:"versions"`
+	HistoryVersions  StorageUsage `json:"history_versions"`
+	ExecutionLogs    StorageUsage `json:"execution_logs"`
+	AuditLogs        StorageUsage `json:"audit_logs"`
+	TotalBytes       int64        `json:"total_estimated_bytes"`
+}
+
+// PromptStorage 为单个 Prompt（含已软删除）及其关联数据的存储占用，用于找出占用最多的 Prompt。
+type PromptStorage struct {
+	PromptID        string       `json:"prompt_id"`
+	PromptName      string       `json:"prompt_name"`
+	WorkspaceID     string       `json:"workspace_id"`
+	OrganizationID  string       `json:"organization_id"`
+	Prompt          StorageUsage `json:"prompt"`
+	Versions        StorageUsage `json:"versions"`
+	HistoryVersions StorageUsage `json:"history_versions"`
+	ExecutionLogs   StorageUsage `json:"execution_logs"`
+	AuditLogs       StorageUsage `json:"audit_logs"`
+	TotalBytes      int64        `json:"total_estimated_bytes"`
+}
+
+// StorageReport 为存储占用报告：按租户汇总、占用最多的 Prompt，以及不归属任何租户的系统审计日志。
+type StorageReport struct {
+	Tenants         []*TenantStorage `json:"tenants"`
+	TopPrompts      []*PromptStorage `json:"top_prompts"`
+	SystemAuditLogs StorageUsage     `json:"system_audit_logs"`
+}
+
+// NewStorageReport 将各 Prompt 的占用累加到所属组织并计算总字节数，按总字节数倒序保留前 top 个 Prompt。
+// 各仓储实现共用该函数，保证汇总口径一致。
+func NewStorageReport(tenants []*TenantStorage, prompts []*PromptStorage, systemAuditLogs StorageUsage, top int) *StorageReport {
+	byOrg := make(map[string]*TenantStorage, len(tenants))
+	for _, tenant := range tenants {
+		byOrg[tenant.OrganizationID] = tenant
+	}
+	for _, prompt := range prompts {
+		prompt.TotalBytes = prompt.Prompt.Bytes + prompt.Versions.Bytes + prompt.ExecutionLogs.Bytes + prompt.AuditLogs.Bytes
+		tenant, ok := byOrg[prompt.OrganizationID]
+		if !ok {
+			continue
+		}
+		tenant.Prompts.Add(prompt.Prompt)
+		tenant.Versions.Add(prompt.Versions)
+		tenant.HistoryVersions.Add(prompt.HistoryVersions)
+		tenant.ExecutionLogs.Add(prompt.ExecutionLogs)
+		tenant.AuditLogs.Add(prompt.AuditLogs)
+		tenant.TotalBytes += prompt.TotalBytes
+	}
+
+	sorted := make([]*PromptStorage, len(prompts))
+	copy(sorted, prompts)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].TotalBytes != sorted[j].TotalBytes {
+			return sorted[i].TotalBytes > sorted[j].TotalBytes
+		}
+		return sorted[i].PromptID < sorted[j].PromptID
+	})
+	if top < len(sorted) {
+		sorted = sorted[:max(top, 0)]
+	}
+	return &StorageReport{Tenants: tenants, TopPrompts: sorted, SystemAuditLogs: systemAuditLogs}
+}
+
+// UsageExport 记录某导出目标已确认接收的累计用量，用于计算下次推送的增量。
+type UsageExport struct {
+	OrganizationID string
+	Period         string
+	Metric         string
+	Exporter       string
+	Value          int64
+}
+
+// 公告级别。
+const (
+	AnnouncementSeverityInfo     = "info"
+	AnnouncementSeverityWarning  = "warning"
+	AnnouncementSeverityCritical = "critical"
+)
+
+// Announcement 为管理员发布的站内公告（如维护通知），StartsAt/EndsAt 为空表示不限制。
+type Announcement struct {
+	ID        string     `json:"id"`
+	Message   string     `json:"message"`
+	Severity  string     `json:"severity"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	CreatedBy *string    `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
@@ -5,4 +5,10 @@ import "errors"
 var (
 	// ErrNotFound 表示仓储查询结果为空。
 	ErrNotFound = errors.New("domain: not found")
+	// ErrWorkspaceNotFound 表示请求的工作区不存在。
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	// ErrWorkspaceForbidden 表示用户不是该工作区成员。
+	ErrWorkspaceForbidden = errors.New("not a member of this workspace")
+	// ErrRevisionConflict 表示乐观并发写入时记录版本号已变化。
+	ErrRevisionConflict = errors.New("domain: revision conflict")
 )
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+type freezeOverrideContextKey struct{}
+
+// WithFreezeOverride 标记当前操作者可在变更冻结窗口内继续激活与删除 Prompt。
+func WithFreezeOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freezeOverrideContextKey{}, true)
+}
+
+// FreezeOverrideFromContext 判断当前操作者是否可绕过变更冻结。
+func FreezeOverrideFromContext(ctx context.Context) bool {
+	override, _ := ctx.Value(freezeOverrideContextKey{}).(bool)
+	return override
+}
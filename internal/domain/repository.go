@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
@@ -11,12 +12,32 @@ type UserRepository interface {
 	GetByID(ctx context.Context, userID string) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	UpdateLastLogin(ctx context.Context, userID string) error
+	UpdateStatus(ctx context.Context, userID, status string) error
+	UpdatePassword(ctx context.Context, userID, hashedPassword string) error
+	UpdateRole(ctx context.Context, userID, role string) error
+	ListByStatus(ctx context.Context, status string) ([]*User, error)
+	// Merge 在同一事务内把 FromUserID 的数据迁移到 ToUserID 并停用原用户，返回各表迁移的行数。
+	Merge(ctx context.Context, merge UserReassignment) (map[string]int64, error)
+	// TransferOwnership 在同一事务内只迁移创建人归属并更新原用户状态，账号自身的身份与成员关系保持不变。
+	TransferOwnership(ctx context.Context, transfer UserReassignment) (map[string]int64, error)
+}
+
+// UserReassignment 描述把一个用户名下的数据迁移给另一个用户。
+// created_by 等列记录的是操作者标识（邮箱，缺失时为用户 ID），因此需要同时给出新旧标识。
+type UserReassignment struct {
+	FromUserID string
+	ToUserID   string
+	FromActors []string
+	ToActor    string
+	// FromStatus 为迁移完成后原用户的状态，通常为 disabled。
+	FromStatus string
 }
 
 // UserIdentityRepository 负责外部身份与本地用户的映射。
 type UserIdentityRepository interface {
 	Create(ctx context.Context, identity *UserIdentity) error
 	GetByProviderAndExternalID(ctx context.Context, provider, externalID string) (*UserIdentity, error)
+	ListByUser(ctx context.Context, userID string) ([]*UserIdentity, error)
 }
 
 // PromptRepository 定义 Prompt 模板存取接口。
@@ -27,38 +48,268 @@ type PromptRepository interface {
 	GetByName(ctx context.Context, name string, includeDeleted bool) (*Prompt, error)
 	List(ctx context.Context, opts PromptListOptions) ([]*Prompt, error)
 	Count(ctx context.Context, opts PromptListOptions) (int64, error)
+	// EstimateCount 返回 prompts 表行数的统计估计（不区分工作区与状态）；
+	// 数据库不支持或尚无统计信息时 ok 为 false。
+	EstimateCount(ctx context.Context) (estimate int64, ok bool, err error)
 	UpdateActiveVersion(ctx context.Context, promptID string, versionID *string, body *string) error
 	Update(ctx context.Context, promptID string, params PromptUpdateParams) error
 	Delete(ctx context.Context, promptID string) error
 	Restore(ctx context.Context, promptID string, params PromptRestoreParams) error
+	// UpdateStatus 将未删除且状态为 from 的 Prompt 切换为 to，不匹配时返回 ErrNotFound。
+	UpdateStatus(ctx context.Context, promptID, from, to string) error
+	// UpdateOwner 设置或清除（owner 为 nil）未删除 Prompt 的负责人。
+	UpdateOwner(ctx context.Context, promptID string, owner *PromptOwner) error
 }
 
 // PromptVersionRepository 定义 Prompt 版本存取接口。
 type PromptVersionRepository interface {
 	Create(ctx context.Context, version *PromptVersion) error
 	GetByID(ctx context.Context, versionID string) (*PromptVersion, error)
+	// MetadataByIDs 批量读取版本 metadata，键为版本 ID；不存在或未设置 metadata 的版本不出现在结果中。
+	MetadataByIDs(ctx context.Context, versionIDs []string) (map[string]json.RawMessage, error)
 	ListByPrompt(ctx context.Context, promptID string, limit, offset int) ([]*PromptVersion, error)
 	// ListByPromptAndStatus 基于状态过滤版本列表（如 draft/published/archived）。
 	ListByPromptAndStatus(ctx context.Context, promptID string, status string, limit, offset int) ([]*PromptVersion, error)
+	// IterateByPrompt 按版本号倒序逐行回调 Prompt 的全部版本，status 非空时按状态过滤，便于流式输出。
+	IterateByPrompt(ctx context.Context, promptID string, status string, fn func(*PromptVersion) error) error
 	// CountByPrompt 统计指定 Prompt 的版本总数。
 	CountByPrompt(ctx context.Context, promptID string) (int64, error)
 	// CountByPromptAndStatus 统计指定 Prompt 在某状态下的版本总数。
 	CountByPromptAndStatus(ctx context.Context, promptID string, status string) (int64, error)
 	GetLatestVersionNumber(ctx context.Context, promptID string) (int, error)
 	GetPreviousVersion(ctx context.Context, promptID string, versionNumber int) (*PromptVersion, error)
+	// DeleteByIDs 删除指定 Prompt 下的版本及其语言变体、调用日志与批准记录，返回实际删除的版本数。
+	DeleteByIDs(ctx context.Context, promptID string, versionIDs []string) (int64, error)
+	// UpdateStatus 仅在当前状态为 from 时将版本状态改为 to，状态已被并发修改时返回 ErrNotFound。
+	UpdateStatus(ctx context.Context, versionID, from, to string) error
+}
+
+// PromptVersionLocaleRepository 定义版本语言变体存取接口。
+type PromptVersionLocaleRepository interface {
+	// Upsert 按 (version_id, locale) 创建或覆盖语言变体。
+	Upsert(ctx context.Context, locale *PromptVersionLocale) error
+	GetByVersionAndLocale(ctx context.Context, versionID, locale string) (*PromptVersionLocale, error)
+	ListByVersion(ctx context.Context, versionID string) ([]*PromptVersionLocale, error)
+	Delete(ctx context.Context, versionID, locale string) error
+	// ListActiveCoverage 返回所有未删除且已激活版本的 Prompt 及其语言变体列表。
+	ListActiveCoverage(ctx context.Context) ([]*PromptLocaleCoverage, error)
+}
+
+// PromptDependencyRepository 定义 Prompt 依赖关系存取接口。
+type PromptDependencyRepository interface {
+	// ReplaceBySource 用 dependsOn 覆盖指定来源的全部依赖。
+	ReplaceBySource(ctx context.Context, promptID, source string, dependsOn []string, createdBy *string) error
+	// ListDependencies 返回 promptID 依赖的 Prompt。
+	ListDependencies(ctx context.Context, promptID string) ([]*PromptDependencyLink, error)
+	// ListDependents 返回依赖 promptID 且未删除的 Prompt。
+	ListDependents(ctx context.Context, promptID string) ([]*PromptDependencyLink, error)
 }
 
 // PromptExecutionLogRepository 定义 Prompt 执行日志接口。
 type PromptExecutionLogRepository interface {
 	Create(ctx context.Context, log *PromptExecutionLog) error
+	// CreateBatch 在同一事务内以多行 INSERT 写入日志，任一失败则全部回滚；CreatedAt 为零值时使用数据库当前时间。
+	// ID 已存在的日志会被跳过，队列重投递时可安全地重复写入。
+	CreateBatch(ctx context.Context, logs []*PromptExecutionLog) error
+	GetByID(ctx context.Context, id string) (*PromptExecutionLog, error)
 	ListRecent(ctx context.Context, promptID string, limit int) ([]*PromptExecutionLog, error)
-	AggregateUsage(ctx context.Context, promptID string, from time.Time) ([]*PromptExecutionAggregate, error)
+	// IterateSince 按时间倒序逐行回调 from 之后的执行日志，便于流式导出。
+	IterateSince(ctx context.Context, promptID string, from time.Time, fn func(*PromptExecutionLog) error) error
+	AggregateUsage(ctx context.Context, promptID string, opts ExecutionAggregateOptions) ([]*PromptExecutionAggregate, error)
+	// SummarizeWindow 汇总 from 之后（含）的调用量、失败数与 p95 耗时。
+	SummarizeWindow(ctx context.Context, promptID string, from time.Time) (*PromptExecutionWindow, error)
+	// SummarizeWindowByVersion 按版本汇总 from 之后（含）的调用量与失败数，不计算 p95 耗时。
+	SummarizeWindowByVersion(ctx context.Context, promptID string, from time.Time) (map[string]*PromptExecutionWindow, error)
+}
+
+// 执行统计的分桶粒度。
+const (
+	GranularityHour  = "hour"
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// ExecutionAggregateOptions 控制执行统计的时间范围、分桶粒度与时区。
+type ExecutionAggregateOptions struct {
+	From time.Time
+	// To 为开区间上界，零值表示不限。
+	To time.Time
+	// Granularity 为 hour/day/week/month，空值按天。
+	Granularity string
+	// Location 为分桶所用的时区，nil 表示 UTC。
+	Location *time.Location
 }
 
 // PromptAuditLogRepository 定义 Prompt 审计日志存取接口。
 type PromptAuditLogRepository interface {
 	Create(ctx context.Context, log *PromptAuditLog) error
 	ListByPrompt(ctx context.Context, promptID string, limit int) ([]*PromptAuditLog, error)
+	// Iterate 按链序号升序逐行回调审计日志（未入链的历史记录排在最前），便于校验与流式导出。
+	Iterate(ctx context.Context, opts AuditLogIterateOptions, fn func(*PromptAuditLog) error) error
+}
+
+// WorkspaceRepository 定义组织、工作区与成员的存取接口。
+type WorkspaceRepository interface {
+	CreateOrganization(ctx context.Context, org *Organization) error
+	GetOrganization(ctx context.Context, orgID string) (*Organization, error)
+	ListOrganizations(ctx context.Context) ([]*Organization, error)
+	// CreateWorkspace 创建工作区，owner 非空时在同一事务内写入其成员关系。
+	CreateWorkspace(ctx context.Context, workspace *Workspace, owner *WorkspaceMember) error
+	GetWorkspace(ctx context.Context, workspaceID string) (*Workspace, error)
+	ListWorkspaces(ctx context.Context, orgID string) ([]*Workspace, error)
+	// ListWorkspacesForUser 返回用户作为成员加入的工作区，Role 字段为成员角色。
+	ListWorkspacesForUser(ctx context.Context, userID string) ([]*Workspace, error)
+	UpsertMember(ctx context.Context, member *WorkspaceMember) error
+	GetMember(ctx context.Context, workspaceID, userID string) (*WorkspaceMember, error)
+	ListMembers(ctx context.Context, workspaceID string) ([]*WorkspaceMember, error)
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+}
+
+// AuditLogRepository 定义通用审计日志存取接口。
+type AuditLogRepository interface {
+	// Create 追加一条审计记录并写入链式哈希。
+	Create(ctx context.Context, log *AuditLog) error
+	List(ctx context.Context, opts AuditLogListOptions) ([]*AuditLog, error)
+	Count(ctx context.Context, opts AuditLogListOptions) (int64, error)
+	// Iterate 按链序号升序逐行回调符合条件的审计日志（忽略分页参数）。
+	Iterate(ctx context.Context, opts AuditLogListOptions, fn func(*AuditLog) error) error
+}
+
+// PipelineRepository 定义 Pipeline 及其版本的存取接口。
+// 上下文携带工作区时（见 WithWorkspace），按 ID 查询、列表与计数仅限该工作区。
+type PipelineRepository interface {
+	Create(ctx context.Context, pipeline *Pipeline) error
+	GetByID(ctx context.Context, pipelineID string) (*Pipeline, error)
+	List(ctx context.Context, limit, offset int) ([]*Pipeline, error)
+	Count(ctx context.Context) (int64, error)
+	// CreateVersion 写入新版本并同步更新 pipelines.latest_version。
+	CreateVersion(ctx context.Context, version *PipelineVersion) error
+	GetVersion(ctx context.Context, pipelineID string, versionNumber int) (*PipelineVersion, error)
+	ListVersions(ctx context.Context, pipelineID string) ([]*PipelineVersion, error)
+}
+
+// SigningKeyRepository 定义 JWT 签名密钥的存取接口。
+type SigningKeyRepository interface {
+	// Rotate 将同一组织现有的 active 密钥标记为 retired（retiresAt 后失效），并写入新的 active 密钥；
+	// OrganizationID 为空时归入默认组织。
+	Rotate(ctx context.Context, key *SigningKey, retiresAt time.Time) error
+	// ListUsable 返回所有组织的 active 密钥及 retires_at 晚于 now 的历史密钥。
+	ListUsable(ctx context.Context, now time.Time) ([]*SigningKey, error)
+}
+
+// InvitationRepository 定义成员邀请的存取接口。
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *Invitation) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	// ListPending 返回未接受且在 now 之后过期的邀请。
+	ListPending(ctx context.Context, now time.Time) ([]*Invitation, error)
+	// MarkAccepted 仅在邀请尚未被接受时更新，已接受时返回 ErrNotFound。
+	MarkAccepted(ctx context.Context, invitationID, userID string, acceptedAt time.Time) error
+}
+
+// PromptAlertRuleRepository 定义 Prompt 告警规则的存取接口。
+type PromptAlertRuleRepository interface {
+	Create(ctx context.Context, rule *PromptAlertRule) error
+	GetByID(ctx context.Context, id string) (*PromptAlertRule, error)
+	ListByPrompt(ctx context.Context, promptID string) ([]*PromptAlertRule, error)
+	// ListEnabled 返回全部启用的规则，供调度器周期评估。
+	ListEnabled(ctx context.Context) ([]*PromptAlertRule, error)
+	// UpdateState 写入评估结果；changedAt 非空表示状态发生切换。
+	UpdateState(ctx context.Context, id, state string, value *float64, evaluatedAt time.Time, changedAt *time.Time) error
+	// UpdateWebhookSecret 替换签名密钥；previous 为空表示不保留旧密钥。
+	UpdateWebhookSecret(ctx context.Context, id, secret string, previous *string, previousExpiresAt *time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+// PromptTemplateRepository 定义 Prompt 脚手架模板的存取接口，以 slug 作为对外标识。
+type PromptTemplateRepository interface {
+	Create(ctx context.Context, template *PromptTemplate) error
+	GetBySlug(ctx context.Context, slug string) (*PromptTemplate, error)
+	List(ctx context.Context) ([]*PromptTemplate, error)
+	Update(ctx context.Context, template *PromptTemplate) error
+	Delete(ctx context.Context, slug string) error
+}
+
+// PromptDraftRepository 定义 Prompt 草稿的存取接口，以 (prompt_id, user_id) 唯一定位。
+type PromptDraftRepository interface {
+	Get(ctx context.Context, promptID, userID string) (*PromptDraft, error)
+	// Save 写入草稿并递增 Revision：expectedRevision 为 nil 时无条件覆盖，
+	// 为 0 时要求草稿尚不存在，否则要求当前 Revision 一致，不满足返回 ErrRevisionConflict。
+	Save(ctx context.Context, draft *PromptDraft, expectedRevision *int) error
+	Delete(ctx context.Context, promptID, userID string) error
+}
+
+// PromptReviewRepository 定义评审策略与版本批准的存取接口。
+type PromptReviewRepository interface {
+	GetPolicy(ctx context.Context, promptID string) (*PromptReviewPolicy, error)
+	// SavePolicy 按 prompt_id 创建或覆盖评审策略。
+	SavePolicy(ctx context.Context, policy *PromptReviewPolicy) error
+	DeletePolicy(ctx context.Context, promptID string) error
+	// Approve 记录批准，同一评审人重复批准时覆盖评论与时间。
+	Approve(ctx context.Context, approval *PromptVersionApproval) error
+	RevokeApproval(ctx context.Context, versionID, reviewerID string) error
+	ListApprovals(ctx context.Context, versionID string) ([]*PromptVersionApproval, error)
+}
+
+// APIKeyRepository 定义 API Key 的存取接口。
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	GetByID(ctx context.Context, id string) (*APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]*APIKey, error)
+	// Revoke 仅吊销尚未吊销的密钥，否则返回 ErrNotFound。
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// LoginEventRepository 定义登录事件的存取接口。
+type LoginEventRepository interface {
+	Create(ctx context.Context, event *LoginEvent) error
+	// ListByUser 按时间倒序返回用户最近的登录事件。
+	ListByUser(ctx context.Context, userID string, limit int) ([]*LoginEvent, error)
+	// HasDevice 判断用户是否曾以该设备登录。
+	HasDevice(ctx context.Context, userID, deviceHash string) (bool, error)
+}
+
+// UsageRepository 定义计量数据的存取接口。
+type UsageRepository interface {
+	// AddCounters 将增量累加到对应的计数器上。
+	AddCounters(ctx context.Context, counters []UsageCounter) error
+	// TenantUsage 按组织汇总 period 的用量：API 调用取计数器，执行次数统计 [from, to) 内的执行日志，
+	// 存储为当前全部 Prompt 版本正文的字节数。没有用量的组织同样返回。
+	TenantUsage(ctx context.Context, period string, from, to time.Time) ([]*TenantUsage, error)
+	// StorageReport 按组织汇总 Prompt、版本、执行日志与 Prompt 审计日志的行数和估算字节数，
+	// 并按总字节数倒序返回前 top 个 Prompt。没有数据的组织同样返回。
+	StorageReport(ctx context.Context, top int) (*StorageReport, error)
+	// ListExports 返回导出目标在 period 已确认的累计用量。
+	ListExports(ctx context.Context, exporter, period string) ([]*UsageExport, error)
+	// SaveExports 记录导出目标已确认的累计用量（覆盖旧值）。
+	SaveExports(ctx context.Context, exports []*UsageExport) error
+}
+
+// AnnouncementRepository 定义站内公告的存取接口。
+type AnnouncementRepository interface {
+	Create(ctx context.Context, announcement *Announcement) error
+	GetByID(ctx context.Context, id string) (*Announcement, error)
+	// List 按创建时间倒序返回全部公告。
+	List(ctx context.Context) ([]*Announcement, error)
+	// ListActive 返回在 at 时刻处于展示窗口内的公告，按开始时间倒序。
+	ListActive(ctx context.Context, at time.Time) ([]*Announcement, error)
+	Update(ctx context.Context, announcement *Announcement) error
+	Delete(ctx context.Context, id string) error
+}
+
+// PromptCanaryRepository 定义灰度激活的存取接口。
+type PromptCanaryRepository interface {
+	// Create 写入进行中的灰度，同一 Prompt 已有进行中的灰度时返回唯一约束错误。
+	Create(ctx context.Context, canary *PromptCanary) error
+	// GetRunning 返回 Prompt 进行中的灰度，不存在时返回 ErrNotFound。
+	GetRunning(ctx context.Context, promptID string) (*PromptCanary, error)
+	ListRunning(ctx context.Context) ([]*PromptCanary, error)
+	UpdateEvaluation(ctx context.Context, id string, canaryRate, baselineRate *float64, evaluatedAt time.Time) error
+	// Finish 结束进行中的灰度，灰度已结束时返回 ErrNotFound。
+	Finish(ctx context.Context, id, status string, reason *string, endedAt time.Time) error
 }
 
 // Repositories 聚合全部仓储接口，便于依赖注入。
@@ -67,8 +318,24 @@ type Repositories struct {
 	UserIdentities     UserIdentityRepository
 	Prompts            PromptRepository
 	PromptVersions     PromptVersionRepository
+	PromptLocales      PromptVersionLocaleRepository
+	PromptDependencies PromptDependencyRepository
 	PromptExecutionLog PromptExecutionLogRepository
 	PromptAuditLog     PromptAuditLogRepository
+	Pipelines          PipelineRepository
+	SigningKeys        SigningKeyRepository
+	AuditLogs          AuditLogRepository
+	Workspaces         WorkspaceRepository
+	Invitations        InvitationRepository
+	LoginEvents        LoginEventRepository
+	PromptAlertRules   PromptAlertRuleRepository
+	PromptTemplates    PromptTemplateRepository
+	PromptDrafts       PromptDraftRepository
+	PromptReviews      PromptReviewRepository
+	APIKeys            APIKeyRepository
+	Usage              UsageRepository
+	Announcements      AnnouncementRepository
+	PromptCanaries     PromptCanaryRepository
 }
 
 // PromptListOptions 定义 Prompt 列表过滤与分页参数。
@@ -77,6 +344,17 @@ type PromptListOptions struct {
 	Offset         int
 	Search         string
 	IncludeDeleted bool
+	// IncludeArchived 为 true 时同时返回已归档的 Prompt；ArchivedOnly 为 true 时只返回已归档的 Prompt。
+	IncludeArchived bool
+	ArchivedOnly    bool
+	// WorkspaceID 为空时使用上下文中的工作区（见 WithWorkspace），两者皆空则不限定。
+	WorkspaceID string
+	// CreatedBy 非空时只返回创建人为其中任一标识（邮箱或用户 ID）的 Prompt。
+	CreatedBy []string
+	// OmitBody 为 true 时不读取正文列，返回的 Prompt.Body 为 nil，用于只展示索引字段的列表视图。
+	OmitBody bool
+	// TargetModels 非空时只返回激活版本声明了其中任一目标模型或系列的 Prompt。
+	TargetModels []string
 }
 
 // PromptUpdateParams 描述 Prompt 更新操作的可选字段。
@@ -84,9 +362,11 @@ type PromptUpdateParams struct {
 	Name           *string
 	Description    *string
 	Tags           *string
+	RenderMode     *string
 	HasName        bool
 	HasDescription bool
 	HasTags        bool
+	HasRenderMode  bool
 }
 
 // PromptRestoreParams 描述 Prompt 恢复时需要更新的字段。
@@ -100,3 +380,23 @@ type PromptRestoreParams struct {
 	HasCreatedBy   bool
 	HasBody        bool
 }
+
+// AuditLogIterateOptions 定义审计日志遍历的过滤条件，零值表示不过滤。
+type AuditLogIterateOptions struct {
+	PromptID string
+	From     time.Time
+	To       time.Time
+}
+
+// AuditLogListOptions 定义通用审计日志的过滤与分页参数，零值表示不过滤。
+type AuditLogListOptions struct {
+	// Action 支持以 "." 结尾的前缀匹配，例如 "auth." 匹配全部认证事件。
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+// DefaultWorkspaceID 为迁移时创建的默认工作区，历史 Prompt 均归属于此，所有用户按全局角色访问。
+const (
+	DefaultOrganizationID = "default"
+	DefaultWorkspaceID    = "default"
+)
+
+type workspaceContextKey struct{}
+
+// WithWorkspace 将当前工作区写入上下文，仓储据此限定 Prompt 的读写范围。
+func WithWorkspace(ctx context.Context, workspaceID string) context.Context {
+	return context.WithValue(ctx, workspaceContextKey{}, workspaceID)
+}
+
+// WorkspaceFromContext 返回上下文中的工作区，未设置时返回空字符串（不限定范围）。
+func WorkspaceFromContext(ctx context.Context) string {
+	workspaceID, _ := ctx.Value(workspaceContextKey{}).(string)
+	return workspaceID
+}
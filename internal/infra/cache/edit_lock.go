@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+const editLockKeyPrefix = "prompt-manager:edit-lock:"
+
+// acquireEditLockScript 原子地检查并写入锁：空闲或同一持有者时写入并续期（保留首次获取时间），
+// 否则原样返回当前锁。返回 {1|0, 锁 JSON}。
+var acquireEditLockScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+local lock = cjson.decode(ARGV[1])
+if current then
+  local ok, held = pcall(cjson.decode, current)
+  if ok and held.holder_id ~= lock.holder_id then
+    return {0, current}
+  end
+  if ok and held.acquired_at then
+    lock.acquired_at = held.acquired_at
+  end
+end
+local encoded = cjson.encode(lock)
+redis.call('SET', KEYS[1], encoded, 'PX', ARGV[2])
+return {1, encoded}
+`)
+
+// releaseEditLockScript 仅在持有者一致时删除锁。
+var releaseEditLockScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+  return 0
+end
+local ok, held = pcall(cjson.decode, current)
+if ok and held.holder_id == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// EditLockStore 基于 Redis 的编辑锁存储，依赖键过期自动释放。
+type EditLockStore struct {
+	client *redis.Client
+}
+
+// NewEditLockStore 创建基于 Redis 的编辑锁存储。
+func NewEditLockStore(client *redis.Client) *EditLockStore {
+	return &EditLockStore{client: client}
+}
+
+// Acquire 实现 domain.EditLockStore。
+func (s *EditLockStore) Acquire(ctx context.Context, lock domain.EditLock, ttl time.Duration) (*domain.EditLock, bool, error) {
+	payload, err := json.Marshal(lock)
+	if err != nil {
+		return nil, false, err
+	}
+	result, err := acquireEditLockScript.Run(ctx, s.client, []string{editLockKey(lock.PromptID)}, string(payload), ttl.Milliseconds()).Slice()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(result) != 2 {
+		return nil, false, errors.New("unexpected edit lock script result")
+	}
+	acquired, _ := result[0].(int64)
+	encoded, _ := result[1].(string)
+	current, err := decodeEditLock(encoded)
+	if err != nil {
+		return nil, false, err
+	}
+	return current, acquired == 1, nil
+}
+
+// Get 实现 domain.EditLockStore。
+func (s *EditLockStore) Get(ctx context.Context, promptID string) (*domain.EditLock, error) {
+	encoded, err := s.client.Get(ctx, editLockKey(promptID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeEditLock(encoded)
+}
+
+// Release 实现 domain.EditLockStore。
+func (s *EditLockStore) Release(ctx context.Context, promptID, holderID string) (bool, error) {
+	deleted, err := releaseEditLockScript.Run(ctx, s.client, []string{editLockKey(promptID)}, holderID).Int64()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+func editLockKey(promptID string) string {
+	return editLockKeyPrefix + promptID
+}
+
+func decodeEditLock(encoded string) (*domain.EditLock, error) {
+	var lock domain.EditLock
+	if err := json.Unmarshal([]byte(encoded), &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	responseCacheKeyPrefix = "prompt-manager:response-cache:"
+	responseCacheGenPrefix = "prompt-manager:response-cache:gen:"
+	// responseCacheGenTTL 为失效代数的保留时间，远长于响应缓存的 TTL，过期重置后不会命中旧响应。
+	responseCacheGenTTL = 24 * time.Hour
+)
+
+// WorkspaceScope 返回工作区列表的失效作用域，workspaceID 为空表示未启用工作区时的全局列表。
+func WorkspaceScope(workspaceID string) string {
+	return "workspace:" + workspaceID
+}
+
+// PromptScope 返回单个 Prompt 下列表（如版本列表）的失效作用域。
+func PromptScope(promptID string) string {
+	return "prompt:" + promptID
+}
+
+// ResponseCache 基于 Redis 缓存 GET 响应体。失效不删除缓存条目，而是递增作用域的代数：
+// 缓存键包含读取时的代数，递增后旧条目不再被命中并随 TTL 过期。
+type ResponseCache struct {
+	client *redis.Client
+}
+
+// NewResponseCache 创建基于 Redis 的响应缓存。
+func NewResponseCache(client *redis.Client) *ResponseCache {
+	return &ResponseCache{client: client}
+}
+
+// Get 读取缓存的响应体。
+func (c *ResponseCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	body, err := c.client.Get(ctx, responseCacheKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return body, true, nil
+}
+
+// Set 写入响应体。
+func (c *ResponseCache) Set(ctx context.Context, key string, body []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, responseCacheKey(key), body, ttl).Err()
+}
+
+// Generations 返回各作用域的当前代数，未失效过的作用域为 0。
+func (c *ResponseCache) Generations(ctx context.Context, scopes ...string) ([]int64, error) {
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(scopes))
+	for i, scope := range scopes {
+		keys[i] = responseCacheGenPrefix + scope
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	generations := make([]int64, len(values))
+	for i, value := range values {
+		if text, ok := value.(string); ok {
+			generations[i], _ = strconv.ParseInt(text, 10, 64)
+		}
+	}
+	return generations, nil
+}
+
+// Invalidate 递增各作用域的代数，使其下的缓存响应全部失效。
+func (c *ResponseCache) Invalidate(ctx context.Context, scopes ...string) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+	pipe := c.client.TxPipeline()
+	for _, scope := range scopes {
+		key := responseCacheGenPrefix + scope
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, responseCacheGenTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// responseCacheKey 对调用方拼接的键取哈希，避免查询参数过长或含特殊字符。
+func responseCacheKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return responseCacheKeyPrefix + hex.EncodeToString(sum[:])
+}
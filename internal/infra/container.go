@@ -3,6 +3,8 @@ package infra
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"strings"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/zacharykka/prompt-manager/internal/infra/cache"
 	"github.com/zacharykka/prompt-manager/internal/infra/database"
 	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository/memory"
 	"github.com/zacharykka/prompt-manager/internal/middleware"
 	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
 	"go.uber.org/multierr"
@@ -24,31 +27,71 @@ type Container struct {
 	DB    *sql.DB
 	Redis *redis.Client
 	Repos *domain.Repositories
+	// Degraded 表示启动时 Redis 不可达、以降级模式运行：Redis 为 nil，依赖它的功能需退化为内存实现或关闭。
+	Degraded bool
+	// ReadOnly 表示数据库结构版本高于当前程序且 schemaCheck.mode 为 readonly：实例只处理读请求，不运行写库的后台任务。
+	ReadOnly bool
 }
 
 // Initialize 构建各类依赖并返回关闭函数。
 func Initialize(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Container, func(context.Context) error, error) {
 	container := &Container{}
 
-	db, err := database.New(ctx, cfg.Database, logger)
-	if err != nil {
-		return nil, nil, err
+	if cfg.Database.Driver == config.DatabaseDriverMemory {
+		// 内存模式不建立数据库连接，DB 保持为 nil。
+		logger.Warn("using in-memory repositories; data is lost on restart")
+		container.Repos = memory.New()
+	} else {
+		var db *sql.DB
+		err := connectWithRetry(ctx, cfg.Startup, logger, "database", func(ctx context.Context) (err error) {
+			db, err = database.New(ctx, cfg.Database, logger)
+			return err
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		container.DB = db
+
+		readOnly, err := checkSchema(ctx, cfg.Database.SchemaCheck, db, logger)
+		if err != nil {
+			_ = db.Close()
+			return nil, nil, err
+		}
+		container.ReadOnly = readOnly
+
+		dialect := database.NewDialect(cfg.Database.Driver)
+		container.Repos = repository.NewSQLRepositories(db, dialect, repository.WithBodyCompression(cfg.Prompts.CompressionThreshold))
 	}
-	container.DB = db
 
-	dialect := database.NewDialect(cfg.Database.Driver)
-	container.Repos = repository.NewSQLRepositories(db, dialect)
+	closeDB := func() {
+		if container.DB != nil {
+			_ = container.DB.Close()
+		}
+	}
 
-	redisClient, err := cache.New(ctx, cfg.Redis, logger)
+	var redisClient *redis.Client
+	err := connectWithRetry(ctx, cfg.Startup, logger, "redis", func(ctx context.Context) (err error) {
+		redisClient, err = cache.New(ctx, cfg.Redis, logger)
+		return err
+	})
 	if err != nil {
-		_ = db.Close()
-		return nil, nil, err
+		if cfg.Redis.Required {
+			closeDB()
+			return nil, nil, err
+		}
+		logger.Warn("redis unavailable; running in degraded mode", zap.String("addr", cfg.Redis.Addr), zap.Error(err))
+		container.Degraded = true
+	} else {
+		container.Redis = redisClient
 	}
-	container.Redis = redisClient
 
-	if err := ensureDefaultAdmin(ctx, cfg, container.Repos, logger); err != nil {
-		_ = db.Close()
-		_ = redisClient.Close()
+	if container.ReadOnly {
+		logger.Warn("read-only mode; skipping default admin seeding")
+	} else if err := ensureDefaultAdmin(ctx, cfg, container.Repos, logger); err != nil {
+		closeDB()
+		if container.Redis != nil {
+			_ = container.Redis.Close()
+		}
 		return nil, nil, err
 	}
 
@@ -70,6 +113,29 @@ func Initialize(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*C
 	return container, cleanup, nil
 }
 
+// checkSchema 校验数据库已执行的迁移与当前程序是否兼容。mode 为 readonly 且数据库超前时返回 true，
+// 其余不兼容情况返回错误以拒绝启动，避免旧版本实例写坏新结构。
+func checkSchema(ctx context.Context, check config.SchemaCheckConfig, db *sql.DB, logger *zap.Logger) (bool, error) {
+	if check.Mode == "" || check.Mode == config.SchemaCheckOff {
+		return false, nil
+	}
+	applied, dirty, err := database.AppliedSchemaVersion(ctx, db)
+	if err != nil {
+		return false, fmt.Errorf("schema check: %w", err)
+	}
+	err = database.CheckSchemaVersion(applied, dirty, check.AllowAhead)
+	switch {
+	case err == nil:
+		logger.Info("database schema compatible", zap.Int64("applied", applied), zap.Int64("expected", database.SchemaVersion))
+		return false, nil
+	case errors.Is(err, database.ErrSchemaTooNew) && check.Mode == config.SchemaCheckReadOnly:
+		logger.Warn("database schema is newer than this build; starting in read-only mode", zap.Error(err))
+		return true, nil
+	default:
+		return false, fmt.Errorf("schema check: %w", err)
+	}
+}
+
 func ensureDefaultAdmin(ctx context.Context, cfg *config.Config, repos *domain.Repositories, logger *zap.Logger) error {
 	email := strings.ToLower(strings.TrimSpace(cfg.Seed.Admin.Email))
 	password := cfg.Seed.Admin.Password
@@ -3,9 +3,12 @@ package infra
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zacharykka/prompt-manager/internal/config"
 	domain "github.com/zacharykka/prompt-manager/internal/domain"
@@ -118,3 +121,110 @@ func TestEnsureDefaultAdminUsesConfig(t *testing.T) {
 		t.Fatalf("expected role editor got %s", user.Role)
 	}
 }
+
+func TestInitializeDegradesWhenRedisUnavailable(t *testing.T) {
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Driver: "sqlite", DSN: "file:" + filepath.Join(t.TempDir(), "app.db")},
+		Redis:    config.RedisConfig{Addr: "127.0.0.1:1"},
+	}
+	container, cleanup, err := Initialize(context.Background(), cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("initialize should degrade instead of failing: %v", err)
+	}
+	defer func() { _ = cleanup(context.Background()) }()
+	if !container.Degraded || container.Redis != nil {
+		t.Fatalf("expected degraded container without redis client")
+	}
+
+	cfg.Redis.Required = true
+	if _, _, err := Initialize(context.Background(), cfg, zap.NewNop()); err == nil {
+		t.Fatalf("expected startup failure when redis is required")
+	}
+}
+
+func TestInitializeChecksSchemaVersion(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version uint64, dirty bool)`); err != nil {
+		t.Fatalf("create schema_migrations: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (?, 0)`, database.SchemaVersion+1); err != nil {
+		t.Fatalf("insert version: %v", err)
+	}
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Driver: "sqlite", DSN: dsn, SchemaCheck: config.SchemaCheckConfig{Mode: config.SchemaCheckStrict}},
+		Redis:    config.RedisConfig{Addr: "127.0.0.1:1"},
+	}
+	if _, _, err := Initialize(context.Background(), cfg, zap.NewNop()); !errors.Is(err, database.ErrSchemaTooNew) {
+		t.Fatalf("expected strict mode to refuse a newer schema, got %v", err)
+	}
+
+	cfg.Database.SchemaCheck.Mode = config.SchemaCheckReadOnly
+	container, cleanup, err := Initialize(context.Background(), cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("readonly mode should start: %v", err)
+	}
+	_ = cleanup(context.Background())
+	if !container.ReadOnly {
+		t.Fatalf("expected read-only container")
+	}
+
+	cfg.Database.SchemaCheck = config.SchemaCheckConfig{Mode: config.SchemaCheckStrict, AllowAhead: 1}
+	container, cleanup, err = Initialize(context.Background(), cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("allowed compatible migration should start: %v", err)
+	}
+	_ = cleanup(context.Background())
+	if container.ReadOnly {
+		t.Fatalf("expected writable container within allowAhead")
+	}
+}
+
+func TestInitializeWithMemoryDriver(t *testing.T) {
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Driver: config.DatabaseDriverMemory},
+		Redis:    config.RedisConfig{Addr: "127.0.0.1:1"},
+		Seed: config.SeedConfig{
+			Admin: config.SeedAdminConfig{Email: "demo@example.com", Password: "super-secure-password-1234567890"},
+		},
+	}
+	container, cleanup, err := Initialize(context.Background(), cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("initialize with memory driver failed: %v", err)
+	}
+	defer func() { _ = cleanup(context.Background()) }()
+	if container.DB != nil {
+		t.Fatalf("memory driver should not open a database connection")
+	}
+	if _, err := container.Repos.Users.GetByEmail(context.Background(), "demo@example.com"); err != nil {
+		t.Fatalf("expected seed admin in memory store: %v", err)
+	}
+}
+
+func TestConnectWithRetryBacksOffUntilAvailable(t *testing.T) {
+	cfg := config.StartupConfig{MaxWait: time.Second, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+	attempts := 0
+	err := connectWithRetry(context.Background(), cfg, zap.NewNop(), "database", func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("expected success on third attempt, got attempts=%d err=%v", attempts, err)
+	}
+
+	cfg.MaxWait = 20 * time.Millisecond
+	err = connectWithRetry(context.Background(), cfg, zap.NewNop(), "redis", func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	if err == nil || !strings.Contains(err.Error(), "redis unavailable after") || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("expected give-up error after max wait, got %v", err)
+	}
+}
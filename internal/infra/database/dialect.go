@@ -1,6 +1,9 @@
 package database
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Dialect 用于适配不同数据库的占位符风格。
 type Dialect struct {
@@ -12,16 +15,24 @@ func NewDialect(driver string) Dialect {
 	return Dialect{driver: driver}
 }
 
-// Placeholder 返回指定序号的占位符。
-func (d Dialect) Placeholder(index int) string {
+// IsPostgres 判断是否为 PostgreSQL 方言。
+func (d Dialect) IsPostgres() bool {
 	switch d.driver {
 	case "postgres", "pgx", "postgresql":
-		return fmt.Sprintf("$%d", index)
+		return true
 	default:
-		return "?"
+		return false
 	}
 }
 
+// Placeholder 返回指定序号的占位符。
+func (d Dialect) Placeholder(index int) string {
+	if d.IsPostgres() {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
 // PlaceholderBuilder 用于生成顺序占位符，避免手动维护计数。
 type PlaceholderBuilder struct {
 	dialect Dialect
@@ -38,3 +49,15 @@ func (b *PlaceholderBuilder) Next() string {
 	b.index++
 	return b.dialect.Placeholder(b.index)
 }
+
+// sqliteTimestampLayout 与 SQLite CURRENT_TIMESTAMP 的文本格式一致，date/strftime 等函数可直接解析。
+const sqliteTimestampLayout = "2006-01-02 15:04:05"
+
+// TimestampArg 把时间转换为写入时间列的参数：Postgres 直接使用 UTC 时间，SQLite 格式化为
+// 与列默认值相同的文本，保证按时间分桶等 SQL 函数对显式写入与默认写入的行结果一致。
+func (d Dialect) TimestampArg(t time.Time) interface{} {
+	if d.IsPostgres() {
+		return t.UTC()
+	}
+	return t.UTC().Format(sqliteTimestampLayout)
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion 为当前程序期望的数据库结构版本，即 db/migrations 中最新迁移的编号。
+// 新增迁移时需同步更新，TestSchemaVersionMatchesMigrations 会校验两者一致。
+const SchemaVersion int64 = 32
+
+var (
+	// ErrSchemaOutdated 表示数据库尚未执行当前程序依赖的迁移。
+	ErrSchemaOutdated = errors.New("database schema is older than this build")
+	// ErrSchemaTooNew 表示数据库已执行本程序未知的迁移，旧版本实例写入可能破坏新结构。
+	ErrSchemaTooNew = errors.New("database schema is newer than this build")
+	// ErrSchemaDirty 表示最近一次迁移执行失败，需人工修复后再启动。
+	ErrSchemaDirty = errors.New("database schema is dirty")
+)
+
+// AppliedSchemaVersion 读取 golang-migrate 记录在 schema_migrations 中的已执行版本与失败标记。
+func AppliedSchemaVersion(ctx context.Context, db *sql.DB) (int64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema_migrations (have migrations been applied?): %w", err)
+	}
+	return version, dirty, nil
+}
+
+// CheckSchemaVersion 比较已执行版本与 SchemaVersion：落后或存在失败迁移时不兼容；
+// 超前不超过 allowAhead 个迁移视为兼容（仅新增表或可空列的扩展迁移），便于滚动发布期间旧实例继续启动。
+func CheckSchemaVersion(applied int64, dirty bool, allowAhead int) error {
+	switch {
+	case dirty:
+		return fmt.Errorf("%w: migration %d failed part-way, fix it and run migrate force before starting", ErrSchemaDirty, applied)
+	case applied < SchemaVersion:
+		return fmt.Errorf("%w: applied %d, expected %d; run migrations before starting this version", ErrSchemaOutdated, applied, SchemaVersion)
+	case applied > SchemaVersion+int64(allowAhead):
+		return fmt.Errorf("%w: applied %d, expected %d (allowing %d ahead); upgrade this instance", ErrSchemaTooNew, applied, SchemaVersion, allowAhead)
+	default:
+		return nil
+	}
+}
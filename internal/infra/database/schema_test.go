@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSchemaVersionMatchesMigrations(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("list migrations: %v", err)
+	}
+	var latest int64
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(file), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			t.Fatalf("parse migration %s: %v", file, err)
+		}
+		latest = max(latest, version)
+	}
+	if latest != SchemaVersion {
+		t.Fatalf("SchemaVersion is %d but the latest migration is %d; update SchemaVersion", SchemaVersion, latest)
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	cases := []struct {
+		applied    int64
+		dirty      bool
+		allowAhead int
+		want       error
+	}{
+		{SchemaVersion, false, 0, nil},
+		{SchemaVersion + 2, false, 2, nil},
+		{SchemaVersion + 1, false, 0, ErrSchemaTooNew},
+		{SchemaVersion - 1, false, 5, ErrSchemaOutdated},
+		{SchemaVersion, true, 0, ErrSchemaDirty},
+	}
+	for _, tc := range cases {
+		err := CheckSchemaVersion(tc.applied, tc.dirty, tc.allowAhead)
+		if (tc.want == nil && err != nil) || (tc.want != nil && !errors.Is(err, tc.want)) {
+			t.Fatalf("applied %d dirty %v ahead %d: expected %v, got %v", tc.applied, tc.dirty, tc.allowAhead, tc.want, err)
+		}
+	}
+}
+
+func TestAppliedSchemaVersion(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "schema.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	if _, _, err := AppliedSchemaVersion(ctx, db); err == nil {
+		t.Fatalf("expected error without schema_migrations")
+	}
+	// 与 golang-migrate sqlite3 驱动创建的表结构一致。
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version uint64, dirty bool)`); err != nil {
+		t.Fatalf("create schema_migrations: %v", err)
+	}
+	if version, dirty, err := AppliedSchemaVersion(ctx, db); err != nil || version != 0 || dirty {
+		t.Fatalf("expected empty table to report version 0, got %d %v %v", version, dirty, err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (28, 1)`); err != nil {
+		t.Fatalf("insert version: %v", err)
+	}
+	if version, dirty, err := AppliedSchemaVersion(ctx, db); err != nil || version != 28 || !dirty {
+		t.Fatalf("expected version 28 dirty, got %d %v %v", version, dirty, err)
+	}
+}
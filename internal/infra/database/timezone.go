@@ -0,0 +1,64 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// 时间分桶粒度，与 LocalBucket 的 unit 参数对应。
+const (
+	BucketHour  = "hour"
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// BucketLayout 返回 LocalBucket 输出文本对应的 Go 时间格式。
+func BucketLayout(unit string) string {
+	if unit == BucketHour {
+		return "2006-01-02 15:04"
+	}
+	return "2006-01-02"
+}
+
+// LocalBucket 返回把 UTC 时间列换算到指定时区后截断到 unit 起点的表达式，结果为 BucketLayout 格式的文本；
+// 周以周一为起点。tzPlaceholder 对应的参数由 TimezoneArg 生成，未知 unit 按天处理。
+func (d Dialect) LocalBucket(column, unit, tzPlaceholder string) string {
+	if d.IsPostgres() {
+		local := fmt.Sprintf("((%s AT TIME ZONE 'UTC') AT TIME ZONE CAST(%s AS TEXT))", column, tzPlaceholder)
+		switch unit {
+		case BucketHour:
+			return fmt.Sprintf("to_char(date_trunc('hour', %s), 'YYYY-MM-DD HH24:MI')", local)
+		case BucketWeek:
+			return fmt.Sprintf("to_char(date_trunc('week', %s), 'YYYY-MM-DD')", local)
+		case BucketMonth:
+			return fmt.Sprintf("to_char(date_trunc('month', %s), 'YYYY-MM-DD')", local)
+		default:
+			return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", local)
+		}
+	}
+	switch unit {
+	case BucketHour:
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:00', %s, %s)", column, tzPlaceholder)
+	case BucketWeek:
+		// weekday 0 前进到本周日（当天为周日时不变），再回退 6 天即为周一。
+		return fmt.Sprintf("date(%s, %s, 'weekday 0', '-6 days')", column, tzPlaceholder)
+	case BucketMonth:
+		return fmt.Sprintf("strftime('%%Y-%%m-01', %s, %s)", column, tzPlaceholder)
+	default:
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s, %s)", column, tzPlaceholder)
+	}
+}
+
+// TimezoneArg 返回 LocalBucket 时区占位符的参数。PostgreSQL 直接使用 IANA 时区名；
+// SQLite 没有时区库，使用 ref 时刻的 UTC 偏移，窗口内跨越夏令时切换的记录会偏差一小时。
+func (d Dialect) TimezoneArg(loc *time.Location, ref time.Time) interface{} {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if d.IsPostgres() {
+		return loc.String()
+	}
+	_, offset := ref.In(loc).Zone()
+	return fmt.Sprintf("%+d minutes", offset/60)
+}
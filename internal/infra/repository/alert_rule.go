@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- Prompt 告警规则仓储 ----
+
+type promptAlertRuleRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const alertRuleColumns = `id, prompt_id, name, metric, threshold, window_minutes, webhook_url, webhook_secret, previous_webhook_secret, previous_secret_expires_at, enabled, state, last_value, last_evaluated_at, state_changed_at, created_by, created_at`
+
+func (r *promptAlertRuleRepository) Create(ctx context.Context, rule *domain.PromptAlertRule) error {
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now().UTC()
+	}
+	if rule.State == "" {
+		rule.State = domain.AlertStateOK
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO prompt_alert_rules (id, prompt_id, name, metric, threshold, window_minutes, webhook_url, webhook_secret, enabled, state, created_by, created_at)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query,
+		rule.ID, rule.PromptID, rule.Name, rule.Metric, rule.Threshold, rule.WindowMinutes,
+		nullableString(rule.WebhookURL), sql.NullString{String: rule.WebhookSecret, Valid: rule.WebhookSecret != ""}, rule.Enabled, rule.State, nullableString(rule.CreatedBy), rule.CreatedAt.UTC(),
+	)
+	return err
+}
+
+func (r *promptAlertRuleRepository) GetByID(ctx context.Context, id string) (*domain.PromptAlertRule, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM prompt_alert_rules WHERE id = %s`, alertRuleColumns, ph.Next())
+	rule, err := scanAlertRule(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return rule, err
+}
+
+func (r *promptAlertRuleRepository) ListByPrompt(ctx context.Context, promptID string) ([]*domain.PromptAlertRule, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM prompt_alert_rules WHERE prompt_id = %s ORDER BY created_at`, alertRuleColumns, ph.Next())
+	return r.list(ctx, query, promptID)
+}
+
+func (r *promptAlertRuleRepository) ListEnabled(ctx context.Context) ([]*domain.PromptAlertRule, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM prompt_alert_rules WHERE enabled = %s ORDER BY prompt_id, created_at`, alertRuleColumns, ph.Next())
+	return r.list(ctx, query, true)
+}
+
+func (r *promptAlertRuleRepository) UpdateState(ctx context.Context, id, state string, value *float64, evaluatedAt time.Time, changedAt *time.Time) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	lastValue := sql.NullFloat64{}
+	if value != nil {
+		lastValue = sql.NullFloat64{Float64: *value, Valid: true}
+	}
+	args := []interface{}{state, lastValue, evaluatedAt.UTC()}
+	query := fmt.Sprintf(`UPDATE prompt_alert_rules SET state = %s, last_value = %s, last_evaluated_at = %s`, ph.Next(), ph.Next(), ph.Next())
+	if changedAt != nil {
+		query += fmt.Sprintf(`, state_changed_at = %s`, ph.Next())
+		args = append(args, changedAt.UTC())
+	}
+	query += fmt.Sprintf(` WHERE id = %s`, ph.Next())
+	args = append(args, id)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptAlertRuleRepository) UpdateWebhookSecret(ctx context.Context, id, secret string, previous *string, previousExpiresAt *time.Time) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	expiresAt := sql.NullTime{}
+	if previousExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: previousExpiresAt.UTC(), Valid: true}
+	}
+	query := fmt.Sprintf(`UPDATE prompt_alert_rules SET webhook_secret = %s, previous_webhook_secret = %s, previous_secret_expires_at = %s WHERE id = %s`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query, secret, nullableString(previous), expiresAt, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptAlertRuleRepository) Delete(ctx context.Context, id string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM prompt_alert_rules WHERE id = %s`, ph.Next()), id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptAlertRuleRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.PromptAlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.PromptAlertRule
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func scanAlertRule(row rowScanner) (*domain.PromptAlertRule, error) {
+	var (
+		rule                               domain.PromptAlertRule
+		webhookURL, createdBy              sql.NullString
+		secret, previousSecret             sql.NullString
+		lastValue                          sql.NullFloat64
+		evaluatedAt, changed, previousEnds sql.NullTime
+	)
+	if err := row.Scan(&rule.ID, &rule.PromptID, &rule.Name, &rule.Metric, &rule.Threshold, &rule.WindowMinutes,
+		&webhookURL, &secret, &previousSecret, &previousEnds, &rule.Enabled, &rule.State, &lastValue, &evaluatedAt, &changed, &createdBy, &rule.CreatedAt); err != nil {
+		return nil, err
+	}
+	rule.WebhookURL = stringPtr(webhookURL)
+	rule.WebhookSecret = secret.String
+	rule.PreviousWebhookSecret = stringPtr(previousSecret)
+	rule.CreatedBy = stringPtr(createdBy)
+	if previousEnds.Valid {
+		t := previousEnds.Time
+		rule.PreviousSecretExpiresAt = &t
+	}
+	if lastValue.Valid {
+		value := lastValue.Float64
+		rule.LastValue = &value
+	}
+	if evaluatedAt.Valid {
+		t := evaluatedAt.Time
+		rule.LastEvaluatedAt = &t
+	}
+	if changed.Valid {
+		t := changed.Time
+		rule.StateChangedAt = &t
+	}
+	return &rule, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 站内公告仓储 ----
+
+type announcementRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const announcementColumns = `id, message, severity, starts_at, ends_at, created_by, created_at, updated_at`
+
+func (r *announcementRepository) Create(ctx context.Context, announcement *domain.Announcement) error {
+	now := time.Now().UTC()
+	if announcement.CreatedAt.IsZero() {
+		announcement.CreatedAt = now
+	}
+	announcement.UpdatedAt = announcement.CreatedAt
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO announcements (%s) VALUES (%s, %s, %s, %s, %s, %s, %s, %s)`, announcementColumns,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query,
+		announcement.ID, announcement.Message, announcement.Severity,
+		r.windowArg(announcement.StartsAt), r.windowArg(announcement.EndsAt),
+		nullableString(announcement.CreatedBy), announcement.CreatedAt.UTC(), announcement.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+func (r *announcementRepository) GetByID(ctx context.Context, id string) (*domain.Announcement, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM announcements WHERE id = %s`, announcementColumns, ph.Next())
+	announcement, err := scanAnnouncement(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return announcement, err
+}
+
+func (r *announcementRepository) List(ctx context.Context) ([]*domain.Announcement, error) {
+	return r.list(ctx, fmt.Sprintf(`SELECT %s FROM announcements ORDER BY created_at DESC`, announcementColumns))
+}
+
+func (r *announcementRepository) ListActive(ctx context.Context, at time.Time) ([]*domain.Announcement, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM announcements
+WHERE (starts_at IS NULL OR starts_at <= %s) AND (ends_at IS NULL OR ends_at > %s)
+ORDER BY COALESCE(starts_at, created_at) DESC`, announcementColumns, ph.Next(), ph.Next())
+	at = at.UTC()
+	return r.list(ctx, query, r.dialect.TimestampArg(at), r.dialect.TimestampArg(at))
+}
+
+func (r *announcementRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.Announcement, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var announcements []*domain.Announcement
+	for rows.Next() {
+		announcement, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, err
+		}
+		announcements = append(announcements, announcement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
+
+func (r *announcementRepository) Update(ctx context.Context, announcement *domain.Announcement) error {
+	announcement.UpdatedAt = time.Now().UTC()
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE announcements SET message = %s, severity = %s, starts_at = %s, ends_at = %s, updated_at = %s WHERE id = %s`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query,
+		announcement.Message, announcement.Severity,
+		r.windowArg(announcement.StartsAt), r.windowArg(announcement.EndsAt),
+		announcement.UpdatedAt, announcement.ID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *announcementRepository) Delete(ctx context.Context, id string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM announcements WHERE id = %s`, ph.Next()), id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// windowArg 以 TimestampArg 写入展示窗口，保证与 ListActive 的比较参数格式一致。
+func (r *announcementRepository) windowArg(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return r.dialect.TimestampArg(*t)
+}
+
+func scanAnnouncement(row rowScanner) (*domain.Announcement, error) {
+	var (
+		announcement     domain.Announcement
+		startsAt, endsAt sql.NullTime
+		createdBy        sql.NullString
+	)
+	if err := row.Scan(&announcement.ID, &announcement.Message, &announcement.Severity, &startsAt, &endsAt,
+		&createdBy, &announcement.CreatedAt, &announcement.UpdatedAt); err != nil {
+		return nil, err
+	}
+	announcement.CreatedBy = stringPtr(createdBy)
+	if startsAt.Valid {
+		t := startsAt.Time
+		announcement.StartsAt = &t
+	}
+	if endsAt.Valid {
+		t := endsAt.Time
+		announcement.EndsAt = &t
+	}
+	return &announcement, nil
+}
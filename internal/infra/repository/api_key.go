@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- API Key 仓储 ----
+
+type apiKeyRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const apiKeyColumns = `id, user_id, name, key_prefix, key_hash, scopes, rate_limit, daily_quota, created_by, created_at, last_used_at, revoked_at`
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now().UTC()
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO api_keys (id, user_id, name, key_prefix, key_hash, scopes, rate_limit, daily_quota, created_by, created_at)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, strings.Join(key.Scopes, ","), key.RateLimit, key.DailyQuota,
+		nullableString(key.CreatedBy), key.CreatedAt.UTC(),
+	)
+	return err
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM api_keys WHERE id = %s`, apiKeyColumns, ph.Next())
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return key, err
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM api_keys WHERE key_hash = %s`, apiKeyColumns, ph.Next())
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return key, err
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM api_keys WHERE user_id = %s ORDER BY created_at DESC`, apiKeyColumns, ph.Next())
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE api_keys SET revoked_at = %s WHERE id = %s AND revoked_at IS NULL`, ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query, revokedAt.UTC(), id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE api_keys SET last_used_at = %s WHERE id = %s`, ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query, usedAt.UTC(), id)
+	return err
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var (
+		key                 domain.APIKey
+		scopes              string
+		createdBy           sql.NullString
+		lastUsedAt, revoked sql.NullTime
+	)
+	if err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &scopes, &key.RateLimit, &key.DailyQuota,
+		&createdBy, &key.CreatedAt, &lastUsedAt, &revoked); err != nil {
+		return nil, err
+	}
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, ",")
+	}
+	key.CreatedBy = stringPtr(createdBy)
+	if lastUsedAt.Valid {
+		t := lastUsedAt.Time
+		key.LastUsedAt = &t
+	}
+	if revoked.Valid {
+		t := revoked.Time
+		key.RevokedAt = &t
+	}
+	return &key, nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 通用审计日志仓储 ----
+
+type auditLogRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const auditLogColumns = `id, action, actor, target_type, target_id, ip, user_agent, payload, created_at, seq, prev_hash, hash`
+
+// Create 在事务内读取链尾并写入带链式哈希的审计记录，序号冲突时重试。
+func (r *auditLogRepository) Create(ctx context.Context, log *domain.AuditLog) error {
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+	log.CreatedAt = log.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	var err error
+	for attempt := 0; attempt < auditChainRetries; attempt++ {
+		if err = r.appendChained(ctx, log); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (r *auditLogRepository) appendChained(ctx context.Context, log *domain.AuditLog) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		lastSeq  sql.NullInt64
+		lastHash sql.NullString
+	)
+	err = tx.QueryRowContext(ctx, `SELECT seq, hash FROM audit_logs WHERE seq IS NOT NULL ORDER BY seq DESC LIMIT 1`).Scan(&lastSeq, &lastHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	log.Seq = lastSeq.Int64 + 1
+	log.PrevHash = lastHash.String
+	log.Hash = log.ChainHash()
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO audit_logs (%s)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`, auditLogColumns,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(),
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	payload := sql.NullString{}
+	if len(log.Payload) > 0 {
+		payload = sql.NullString{String: string(log.Payload), Valid: true}
+	}
+	if _, err = tx.ExecContext(ctx, query,
+		log.ID, log.Action, nullableString(log.Actor), log.TargetType, nullableString(log.TargetID),
+		nullableString(log.IP), nullableString(log.UserAgent), payload, log.CreatedAt,
+		log.Seq, log.PrevHash, log.Hash,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *auditLogRepository) List(ctx context.Context, opts domain.AuditLogListOptions) ([]*domain.AuditLog, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	where, args := buildAuditLogFilter(ph, opts)
+	query := fmt.Sprintf(`SELECT %s FROM audit_logs %s ORDER BY created_at DESC, seq DESC LIMIT %s OFFSET %s`,
+		auditLogColumns, where, ph.Next(), ph.Next())
+	args = append(args, limit, opts.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []*domain.AuditLog
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (r *auditLogRepository) Count(ctx context.Context, opts domain.AuditLogListOptions) (int64, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	where, args := buildAuditLogFilter(ph, opts)
+	var total int64
+	if err := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM audit_logs %s`, where), args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *auditLogRepository) Iterate(ctx context.Context, opts domain.AuditLogListOptions, fn func(*domain.AuditLog) error) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	where, args := buildAuditLogFilter(ph, opts)
+	query := fmt.Sprintf(`SELECT %s FROM audit_logs %s
+ORDER BY CASE WHEN seq IS NULL THEN 0 ELSE 1 END, seq, created_at`, auditLogColumns, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func buildAuditLogFilter(ph *database.PlaceholderBuilder, opts domain.AuditLogListOptions) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if action := strings.TrimSpace(opts.Action); action != "" {
+		if strings.HasSuffix(action, ".") {
+			conditions = append(conditions, fmt.Sprintf("SUBSTR(action, 1, %d) = %s", len(action), ph.Next()))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("action = %s", ph.Next()))
+		}
+		args = append(args, action)
+	}
+	if opts.Actor != "" {
+		conditions = append(conditions, fmt.Sprintf("actor = %s", ph.Next()))
+		args = append(args, opts.Actor)
+	}
+	if opts.TargetType != "" {
+		conditions = append(conditions, fmt.Sprintf("target_type = %s", ph.Next()))
+		args = append(args, opts.TargetType)
+	}
+	if opts.TargetID != "" {
+		conditions = append(conditions, fmt.Sprintf("target_id = %s", ph.Next()))
+		args = append(args, opts.TargetID)
+	}
+	if !opts.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at >= %s", ph.Next()))
+		args = append(args, opts.From.UTC())
+	}
+	if !opts.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at < %s", ph.Next()))
+		args = append(args, opts.To.UTC())
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanAuditLog(rows *sql.Rows) (*domain.AuditLog, error) {
+	var (
+		log                            domain.AuditLog
+		actor, targetID, ip, userAgent sql.NullString
+		payload, prevHash, hash        sql.NullString
+		seq                            sql.NullInt64
+	)
+	if err := rows.Scan(&log.ID, &log.Action, &actor, &log.TargetType, &targetID, &ip, &userAgent, &payload, &log.CreatedAt, &seq, &prevHash, &hash); err != nil {
+		return nil, err
+	}
+	log.Actor = stringPtr(actor)
+	log.TargetID = stringPtr(targetID)
+	log.IP = stringPtr(ip)
+	log.UserAgent = stringPtr(userAgent)
+	if payload.Valid {
+		log.Payload = json.RawMessage(payload.String)
+	}
+	log.Seq = seq.Int64
+	log.PrevHash = prevHash.String
+	log.Hash = hash.String
+	return &log, nil
+}
+
+func nullableString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *value, Valid: true}
+}
+
+func stringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// benchPrompts 为基准测试预置的 Prompt 数量，使列表与搜索查询走到真实的索引与分页路径。
+const benchPrompts = 500
+
+// setupBenchRepos 在独立的内存 SQLite 中执行迁移并预置 count 个 Prompt 与激活版本。
+func setupBenchRepos(b *testing.B, count int) (*domain.Repositories, []*domain.Prompt) {
+	b.Helper()
+	dsn := fmt.Sprintf("file:bench_%d?mode=memory&cache=shared&_fk=1", conformanceSeq.Add(1))
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		b.Fatalf("open sqlite: %v", err)
+	}
+	b.Cleanup(func() { _ = db.Close() })
+	applyMigrations(b, db)
+
+	repos := NewSQLRepositories(db, database.NewDialect("sqlite"))
+	ctx := context.Background()
+	prompts := make([]*domain.Prompt, 0, count)
+	for i := 0; i < count; i++ {
+		prompt := &domain.Prompt{ID: fmt.Sprintf("bench-prompt-%04d", i), Name: fmt.Sprintf("bench-%04d", i), Tags: []byte(`["bench"]`)}
+		if err := repos.Prompts.Create(ctx, prompt); err != nil {
+			b.Fatalf("create prompt: %v", err)
+		}
+		version := &domain.PromptVersion{ID: fmt.Sprintf("bench-version-%04d", i), PromptID: prompt.ID, VersionNumber: 1, Body: "Hello {{ name }}", Status: domain.PromptVersionStatusPublished}
+		if err := repos.PromptVersions.Create(ctx, version); err != nil {
+			b.Fatalf("create version: %v", err)
+		}
+		if err := repos.Prompts.UpdateActiveVersion(ctx, prompt.ID, &version.ID, &version.Body); err != nil {
+			b.Fatalf("activate version: %v", err)
+		}
+		prompt.ActiveVersionID = &version.ID
+		prompts = append(prompts, prompt)
+	}
+	return repos, prompts
+}
+
+func BenchmarkPromptList(b *testing.B) {
+	repos, _ := setupBenchRepos(b, benchPrompts)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repos.Prompts.List(ctx, domain.PromptListOptions{Limit: 20, Offset: (i % 10) * 20}); err != nil {
+			b.Fatalf("list prompts: %v", err)
+		}
+	}
+}
+
+// BenchmarkPromptList1k 一次读取 1000 行，度量逐行扫描与映射的分配开销。
+func BenchmarkPromptList1k(b *testing.B) {
+	repos, _ := setupBenchRepos(b, 1000)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prompts, err := repos.Prompts.List(ctx, domain.PromptListOptions{Limit: 1000})
+		if err != nil {
+			b.Fatalf("list prompts: %v", err)
+		}
+		if len(prompts) != 1000 {
+			b.Fatalf("expected 1000 prompts, got %d", len(prompts))
+		}
+	}
+}
+
+func BenchmarkPromptGetByName(b *testing.B) {
+	repos, prompts := setupBenchRepos(b, benchPrompts)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repos.Prompts.GetByName(ctx, prompts[i%len(prompts)].Name, false); err != nil {
+			b.Fatalf("get prompt by name: %v", err)
+		}
+	}
+}
+
+// BenchmarkRenderLookup 覆盖渲染前的读取路径：按 ID 读取 Prompt 与其激活版本。
+func BenchmarkRenderLookup(b *testing.B) {
+	repos, prompts := setupBenchRepos(b, benchPrompts)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prompt, err := repos.Prompts.GetByID(ctx, prompts[i%len(prompts)].ID)
+		if err != nil {
+			b.Fatalf("get prompt: %v", err)
+		}
+		if _, err := repos.PromptVersions.GetByID(ctx, *prompt.ActiveVersionID); err != nil {
+			b.Fatalf("get version: %v", err)
+		}
+	}
+}
+
+func BenchmarkExecutionLogCreateBatch(b *testing.B) {
+	repos, prompts := setupBenchRepos(b, benchPrompts)
+	ctx := context.Background()
+	const batchSize = 10
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prompt := prompts[i%len(prompts)]
+		logs := make([]*domain.PromptExecutionLog, 0, batchSize)
+		for j := 0; j < batchSize; j++ {
+			logs = append(logs, &domain.PromptExecutionLog{
+				ID:              fmt.Sprintf("bench-log-%d-%d", i, j),
+				PromptID:        prompt.ID,
+				PromptVersionID: *prompt.ActiveVersionID,
+				Status:          "success",
+				DurationMs:      int64(50 + j),
+			})
+		}
+		if err := repos.PromptExecutionLog.CreateBatch(ctx, logs); err != nil {
+			b.Fatalf("create batch: %v", err)
+		}
+	}
+}
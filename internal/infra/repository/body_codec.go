@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressedBodyPrefix 为 zstd 帧魔数（28 B5 2F FD）经 base64 编码后的固定前缀。
+// 压缩后的正文以 base64 文本存放，使 TEXT 列在 SQLite 与 PostgreSQL 下均可保存。
+const compressedBodyPrefix = "KLUv/"
+
+// maxDecodedBodyBytes 限制单个正文解压后的大小，防止损坏或恶意数据耗尽内存。
+const maxDecodedBodyBytes = 64 << 20
+
+var (
+	zstdMagic   = []byte{0x28, 0xB5, 0x2F, 0xFD}
+	bodyEncoder *zstd.Encoder
+	bodyDecoder *zstd.Decoder
+)
+
+func init() {
+	var err error
+	// 仅使用 EncodeAll/DecodeAll，编解码器可在并发请求间共享。
+	bodyEncoder, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		panic(fmt.Sprintf("init zstd encoder: %v", err))
+	}
+	bodyDecoder, err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedBodyBytes))
+	if err != nil {
+		panic(fmt.Sprintf("init zstd decoder: %v", err))
+	}
+}
+
+// bodyCodec 在写入时压缩超过阈值的正文；threshold <= 0 表示不压缩。
+// 读取不依赖配置：decodeBody 按魔数识别压缩内容，调整或关闭阈值后历史数据仍可读取。
+type bodyCodec struct {
+	threshold int
+}
+
+// encode 返回写入数据库的正文。未达阈值的正文原样保存；
+// 恰好以压缩前缀开头的明文总是压缩，避免读取时被误判。
+func (c bodyCodec) encode(body string) string {
+	if !strings.HasPrefix(body, compressedBodyPrefix) && (c.threshold <= 0 || len(body) < c.threshold) {
+		return body
+	}
+	compressed := bodyEncoder.EncodeAll([]byte(body), nil)
+	return base64.StdEncoding.EncodeToString(compressed)
+}
+
+// encodeNullable 对可空正文执行 encode。
+func (c bodyCodec) encodeNullable(body *string) sql.NullString {
+	if body == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: c.encode(*body), Valid: true}
+}
+
+// decodeBody 还原数据库中的正文：带 zstd 魔数的内容解压，其余视为明文原样返回。
+func decodeBody(stored string) (string, error) {
+	if !strings.HasPrefix(stored, compressedBodyPrefix) {
+		return stored, nil
+	}
+	compressed, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil || !bytes.HasPrefix(compressed, zstdMagic) {
+		return stored, nil
+	}
+	body, err := bodyDecoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return "", fmt.Errorf("decompress prompt body: %w", err)
+	}
+	return string(body), nil
+}
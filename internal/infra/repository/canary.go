@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- Prompt 灰度激活仓储 ----
+
+type promptCanaryRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const canaryColumns = `id, prompt_id, version_id, baseline_version_id, percent, max_error_rate_delta, min_calls, window_minutes, webhook_url, status,
+canary_error_rate, baseline_error_rate, reason, started_by, started_at, last_evaluated_at, ended_at`
+
+func (r *promptCanaryRepository) Create(ctx context.Context, canary *domain.PromptCanary) error {
+	if canary.StartedAt.IsZero() {
+		canary.StartedAt = time.Now().UTC()
+	}
+	if canary.Status == "" {
+		canary.Status = domain.CanaryStatusRunning
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO prompt_canaries (id, prompt_id, version_id, baseline_version_id, percent, max_error_rate_delta, min_calls, window_minutes, webhook_url, status, started_by, started_at)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query,
+		canary.ID, canary.PromptID, canary.VersionID, canary.BaselineVersionID, canary.Percent, canary.MaxErrorRateDelta,
+		canary.MinCalls, canary.WindowMinutes, nullableString(canary.WebhookURL), canary.Status,
+		nullableString(canary.StartedBy), r.dialect.TimestampArg(canary.StartedAt.UTC()),
+	)
+	return err
+}
+
+func (r *promptCanaryRepository) GetRunning(ctx context.Context, promptID string) (*domain.PromptCanary, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM prompt_canaries WHERE prompt_id = %s AND status = %s`, canaryColumns, ph.Next(), ph.Next())
+	canary, err := scanCanary(r.db.QueryRowContext(ctx, query, promptID, domain.CanaryStatusRunning))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return canary, err
+}
+
+func (r *promptCanaryRepository) ListRunning(ctx context.Context) ([]*domain.PromptCanary, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM prompt_canaries WHERE status = %s ORDER BY started_at`, canaryColumns, ph.Next())
+	rows, err := r.db.QueryContext(ctx, query, domain.CanaryStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var canaries []*domain.PromptCanary
+	for rows.Next() {
+		canary, err := scanCanary(rows)
+		if err != nil {
+			return nil, err
+		}
+		canaries = append(canaries, canary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return canaries, nil
+}
+
+func (r *promptCanaryRepository) UpdateEvaluation(ctx context.Context, id string, canaryRate, baselineRate *float64, evaluatedAt time.Time) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE prompt_canaries SET canary_error_rate = %s, baseline_error_rate = %s, last_evaluated_at = %s WHERE id = %s`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query, nullableFloat(canaryRate), nullableFloat(baselineRate), r.dialect.TimestampArg(evaluatedAt.UTC()), id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptCanaryRepository) Finish(ctx context.Context, id, status string, reason *string, endedAt time.Time) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE prompt_canaries SET status = %s, reason = %s, ended_at = %s WHERE id = %s AND status = %s`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query, status, nullableString(reason), r.dialect.TimestampArg(endedAt.UTC()), id, domain.CanaryStatusRunning)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func nullableFloat(value *float64) sql.NullFloat64 {
+	if value == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *value, Valid: true}
+}
+
+func scanCanary(row rowScanner) (*domain.PromptCanary, error) {
+	var (
+		canary                   domain.PromptCanary
+		webhookURL, reason, by   sql.NullString
+		canaryRate, baselineRate sql.NullFloat64
+		evaluatedAt, endedAt     sql.NullTime
+	)
+	if err := row.Scan(&canary.ID, &canary.PromptID, &canary.VersionID, &canary.BaselineVersionID, &canary.Percent,
+		&canary.MaxErrorRateDelta, &canary.MinCalls, &canary.WindowMinutes, &webhookURL, &canary.Status,
+		&canaryRate, &baselineRate, &reason, &by, &canary.StartedAt, &evaluatedAt, &endedAt); err != nil {
+		return nil, err
+	}
+	canary.WebhookURL = stringPtr(webhookURL)
+	canary.Reason = stringPtr(reason)
+	canary.StartedBy = stringPtr(by)
+	if canaryRate.Valid {
+		value := canaryRate.Float64
+		canary.CanaryErrorRate = &value
+	}
+	if baselineRate.Valid {
+		value := baselineRate.Float64
+		canary.BaselineErrorRate = &value
+	}
+	if evaluatedAt.Valid {
+		t := evaluatedAt.Time
+		canary.LastEvaluatedAt = &t
+	}
+	if endedAt.Valid {
+		t := endedAt.Time
+		canary.EndedAt = &t
+	}
+	return &canary, nil
+}
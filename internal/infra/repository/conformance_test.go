@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository/repotest"
+)
+
+// postgresDSNEnv 指定运行 Postgres 契约测试的数据库，未设置时跳过。
+const postgresDSNEnv = "PROMPT_MANAGER_TEST_POSTGRES_DSN"
+
+var conformanceSeq atomic.Int64
+
+func TestSQLiteConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) *domain.Repositories {
+		dsn := fmt.Sprintf("file:conformance_%d?mode=memory&cache=shared&_fk=1", conformanceSeq.Add(1))
+		db, err := sql.Open("sqlite", dsn)
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		applyMigrations(t, db)
+		return NewSQLRepositories(db, database.NewDialect("sqlite"))
+	})
+}
+
+func TestPostgresConformance(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", postgresDSNEnv)
+	}
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+	if err := admin.Ping(); err != nil {
+		t.Fatalf("ping postgres: %v", err)
+	}
+
+	// 每个用例使用独立 schema，互不干扰，结束后整体删除。
+	repotest.Run(t, func(t *testing.T) *domain.Repositories {
+		schema := fmt.Sprintf("conformance_%d_%d", os.Getpid(), conformanceSeq.Add(1))
+		if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+		t.Cleanup(func() { _, _ = admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+		db, err := sql.Open("pgx", withSearchPath(dsn, schema))
+		if err != nil {
+			t.Fatalf("open postgres: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		applyMigrations(t, db)
+		return NewSQLRepositories(db, database.NewDialect("pgx"))
+	})
+}
+
+// withSearchPath 为 URL 或 key=value 形式的 DSN 追加 search_path 运行参数。
+func withSearchPath(dsn, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
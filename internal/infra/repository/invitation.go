@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 成员邀请仓储 ----
+
+type invitationRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const invitationColumns = `id, email, role, workspace_id, token_hash, invited_by, expires_at, accepted_at, accepted_user_id, created_at`
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO invitations (id, email, role, workspace_id, token_hash, invited_by, expires_at)
+VALUES (%s, %s, %s, %s, %s, %s, %s)`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query,
+		invitation.ID, invitation.Email, invitation.Role, invitation.WorkspaceID, invitation.TokenHash,
+		nullableString(invitation.InvitedBy), invitation.ExpiresAt.UTC(),
+	)
+	return err
+}
+
+func (r *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM invitations WHERE token_hash = %s`, invitationColumns, ph.Next())
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return invitation, err
+}
+
+func (r *invitationRepository) ListPending(ctx context.Context, now time.Time) ([]*domain.Invitation, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM invitations WHERE accepted_at IS NULL AND expires_at > %s ORDER BY created_at DESC`,
+		invitationColumns, ph.Next())
+	rows, err := r.db.QueryContext(ctx, query, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*domain.Invitation
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepository) MarkAccepted(ctx context.Context, invitationID, userID string, acceptedAt time.Time) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE invitations SET accepted_at = %s, accepted_user_id = %s WHERE id = %s AND accepted_at IS NULL`,
+		ph.Next(), ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query, acceptedAt.UTC(), userID, invitationID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanInvitation(row rowScanner) (*domain.Invitation, error) {
+	var (
+		invitation                domain.Invitation
+		invitedBy, acceptedUserID sql.NullString
+		acceptedAt                sql.NullTime
+	)
+	if err := row.Scan(&invitation.ID, &invitation.Email, &invitation.Role, &invitation.WorkspaceID, &invitation.TokenHash,
+		&invitedBy, &invitation.ExpiresAt, &acceptedAt, &acceptedUserID, &invitation.CreatedAt); err != nil {
+		return nil, err
+	}
+	invitation.InvitedBy = stringPtr(invitedBy)
+	invitation.AcceptedUserID = stringPtr(acceptedUserID)
+	if acceptedAt.Valid {
+		t := acceptedAt.Time
+		invitation.AcceptedAt = &t
+	}
+	return &invitation, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 登录事件仓储 ----
+
+type loginEventRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func (r *loginEventRepository) Create(ctx context.Context, event *domain.LoginEvent) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO login_events (id, user_id, event_type, provider, ip_address, user_agent, device_hash, new_device, created_at)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID, event.UserID, event.EventType, event.Provider,
+		nullableString(event.IPAddress), nullableString(event.UserAgent),
+		event.DeviceHash, event.NewDevice, event.CreatedAt.UTC(),
+	)
+	return err
+}
+
+func (r *loginEventRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*domain.LoginEvent, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, user_id, event_type, provider, ip_address, user_agent, device_hash, new_device, created_at
+FROM login_events WHERE user_id = %s ORDER BY created_at DESC LIMIT %s`, ph.Next(), ph.Next())
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.LoginEvent
+	for rows.Next() {
+		var (
+			event                domain.LoginEvent
+			ipAddress, userAgent sql.NullString
+		)
+		if err := rows.Scan(&event.ID, &event.UserID, &event.EventType, &event.Provider, &ipAddress, &userAgent,
+			&event.DeviceHash, &event.NewDevice, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.IPAddress = stringPtr(ipAddress)
+		event.UserAgent = stringPtr(userAgent)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *loginEventRepository) HasDevice(ctx context.Context, userID, deviceHash string) (bool, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT COUNT(1) FROM login_events WHERE user_id = %s AND device_hash = %s`, ph.Next(), ph.Next())
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID, deviceHash).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Prompt 告警规则仓储 ----
+
+type promptAlertRuleRepository struct {
+	s *store
+}
+
+func (r *promptAlertRuleRepository) Create(ctx context.Context, rule *domain.PromptAlertRule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.alertRules[rule.ID]; ok {
+		return uniqueViolation("prompt_alert_rules.id")
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = r.s.now()
+	}
+	if rule.State == "" {
+		rule.State = domain.AlertStateOK
+	}
+	stored := cloneAlertRule(rule)
+	stored.CreatedAt = rule.CreatedAt.UTC()
+	stored.LastValue, stored.LastEvaluatedAt, stored.StateChangedAt = nil, nil, nil
+	stored.PreviousWebhookSecret, stored.PreviousSecretExpiresAt = nil, nil
+	r.s.alertRules[stored.ID] = stored
+	return nil
+}
+
+func (r *promptAlertRuleRepository) GetByID(ctx context.Context, id string) (*domain.PromptAlertRule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rule, ok := r.s.alertRules[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneAlertRule(rule), nil
+}
+
+func (r *promptAlertRuleRepository) ListByPrompt(ctx context.Context, promptID string) ([]*domain.PromptAlertRule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var rules []*domain.PromptAlertRule
+	for _, rule := range r.s.alertRules {
+		if rule.PromptID == promptID {
+			rules = append(rules, cloneAlertRule(rule))
+		}
+	}
+	sortByTime(rules, func(r *domain.PromptAlertRule) time.Time { return r.CreatedAt }, func(r *domain.PromptAlertRule) string { return r.ID }, false)
+	return rules, nil
+}
+
+func (r *promptAlertRuleRepository) ListEnabled(ctx context.Context) ([]*domain.PromptAlertRule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var rules []*domain.PromptAlertRule
+	for _, rule := range r.s.alertRules {
+		if rule.Enabled {
+			rules = append(rules, cloneAlertRule(rule))
+		}
+	}
+	sortByTime(rules, func(r *domain.PromptAlertRule) time.Time { return r.CreatedAt }, func(r *domain.PromptAlertRule) string { return r.ID }, false)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].PromptID < rules[j].PromptID })
+	return rules, nil
+}
+
+func (r *promptAlertRuleRepository) UpdateState(ctx context.Context, id, state string, value *float64, evaluatedAt time.Time, changedAt *time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rule, ok := r.s.alertRules[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	evaluated := evaluatedAt.UTC()
+	rule.State = state
+	rule.LastValue = cloneFloat(value)
+	rule.LastEvaluatedAt = &evaluated
+	if changedAt != nil {
+		changed := changedAt.UTC()
+		rule.StateChangedAt = &changed
+	}
+	return nil
+}
+
+func (r *promptAlertRuleRepository) UpdateWebhookSecret(ctx context.Context, id, secret string, previous *string, previousExpiresAt *time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rule, ok := r.s.alertRules[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	rule.WebhookSecret = secret
+	rule.PreviousWebhookSecret = cloneString(previous)
+	rule.PreviousSecretExpiresAt = nil
+	if previousExpiresAt != nil {
+		expiresAt := previousExpiresAt.UTC()
+		rule.PreviousSecretExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+func (r *promptAlertRuleRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.alertRules[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.alertRules, id)
+	return nil
+}
+
+func cloneAlertRule(rule *domain.PromptAlertRule) *domain.PromptAlertRule {
+	clone := *rule
+	clone.WebhookURL = cloneString(rule.WebhookURL)
+	clone.PreviousWebhookSecret = cloneString(rule.PreviousWebhookSecret)
+	clone.PreviousSecretExpiresAt = cloneTime(rule.PreviousSecretExpiresAt)
+	clone.LastValue = cloneFloat(rule.LastValue)
+	clone.LastEvaluatedAt = cloneTime(rule.LastEvaluatedAt)
+	clone.StateChangedAt = cloneTime(rule.StateChangedAt)
+	clone.CreatedBy = cloneString(rule.CreatedBy)
+	return &clone
+}
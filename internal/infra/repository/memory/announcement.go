@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- 站内公告仓储 ----
+
+type announcementRepository struct {
+	s *store
+}
+
+func (r *announcementRepository) Create(ctx context.Context, announcement *domain.Announcement) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.announcements[announcement.ID]; ok {
+		return uniqueViolation("announcements.id")
+	}
+	if announcement.CreatedAt.IsZero() {
+		announcement.CreatedAt = r.s.now()
+	}
+	announcement.UpdatedAt = announcement.CreatedAt
+	stored := cloneAnnouncement(announcement)
+	stored.CreatedAt, stored.UpdatedAt = announcement.CreatedAt.UTC(), announcement.UpdatedAt.UTC()
+	r.s.announcements[stored.ID] = stored
+	return nil
+}
+
+func (r *announcementRepository) GetByID(ctx context.Context, id string) (*domain.Announcement, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	announcement, ok := r.s.announcements[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneAnnouncement(announcement), nil
+}
+
+func (r *announcementRepository) List(ctx context.Context) ([]*domain.Announcement, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var announcements []*domain.Announcement
+	for _, announcement := range r.s.announcements {
+		announcements = append(announcements, cloneAnnouncement(announcement))
+	}
+	sortByTime(announcements, func(a *domain.Announcement) time.Time { return a.CreatedAt }, func(a *domain.Announcement) string { return a.ID }, true)
+	return announcements, nil
+}
+
+func (r *announcementRepository) ListActive(ctx context.Context, at time.Time) ([]*domain.Announcement, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var announcements []*domain.Announcement
+	for _, announcement := range r.s.announcements {
+		if announcement.StartsAt != nil && announcement.StartsAt.After(at) {
+			continue
+		}
+		if announcement.EndsAt != nil && !announcement.EndsAt.After(at) {
+			continue
+		}
+		announcements = append(announcements, cloneAnnouncement(announcement))
+	}
+	sortByTime(announcements, func(a *domain.Announcement) time.Time {
+		if a.StartsAt != nil {
+			return *a.StartsAt
+		}
+		return a.CreatedAt
+	}, func(a *domain.Announcement) string { return a.ID }, true)
+	return announcements, nil
+}
+
+func (r *announcementRepository) Update(ctx context.Context, announcement *domain.Announcement) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	announcement.UpdatedAt = r.s.now()
+	existing, ok := r.s.announcements[announcement.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.Message = announcement.Message
+	existing.Severity = announcement.Severity
+	existing.StartsAt = cloneTime(announcement.StartsAt)
+	existing.EndsAt = cloneTime(announcement.EndsAt)
+	existing.UpdatedAt = announcement.UpdatedAt
+	return nil
+}
+
+func (r *announcementRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.announcements[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.announcements, id)
+	return nil
+}
+
+func cloneAnnouncement(announcement *domain.Announcement) *domain.Announcement {
+	clone := *announcement
+	clone.StartsAt = cloneTime(announcement.StartsAt)
+	clone.EndsAt = cloneTime(announcement.EndsAt)
+	clone.CreatedBy = cloneString(announcement.CreatedBy)
+	return &clone
+}
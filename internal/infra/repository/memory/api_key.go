@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- API Key 仓储 ----
+
+type apiKeyRepository struct {
+	s *store
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.apiKeys[key.ID]; ok {
+		return uniqueViolation("api_keys.id")
+	}
+	for _, existing := range r.s.apiKeys {
+		if existing.KeyHash == key.KeyHash {
+			return uniqueViolation("api_keys.key_hash")
+		}
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = r.s.now()
+	}
+	stored := cloneAPIKey(key)
+	stored.CreatedAt = key.CreatedAt.UTC()
+	stored.LastUsedAt, stored.RevokedAt = nil, nil
+	r.s.apiKeys[stored.ID] = stored
+	return nil
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	key, ok := r.s.apiKeys[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneAPIKey(key), nil
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, key := range r.s.apiKeys {
+		if key.KeyHash == keyHash {
+			return cloneAPIKey(key), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var keys []*domain.APIKey
+	for _, key := range r.s.apiKeys {
+		if key.UserID == userID {
+			keys = append(keys, cloneAPIKey(key))
+		}
+	}
+	sortByTime(keys, func(k *domain.APIKey) time.Time { return k.CreatedAt }, func(k *domain.APIKey) string { return k.ID }, true)
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key, ok := r.s.apiKeys[id]
+	if !ok || key.RevokedAt != nil {
+		return domain.ErrNotFound
+	}
+	revoked := revokedAt.UTC()
+	key.RevokedAt = &revoked
+	return nil
+}
+
+// TouchLastUsed 与 SQL 实现一致，密钥不存在时静默忽略。
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if key, ok := r.s.apiKeys[id]; ok {
+		used := usedAt.UTC()
+		key.LastUsedAt = &used
+	}
+	return nil
+}
+
+// cloneAPIKey 复制密钥；空的权限列表与数据库往返后一样表示为 nil。
+func cloneAPIKey(key *domain.APIKey) *domain.APIKey {
+	clone := *key
+	clone.Scopes = nil
+	if len(key.Scopes) > 0 {
+		clone.Scopes = append([]string(nil), key.Scopes...)
+	}
+	clone.CreatedBy = cloneString(key.CreatedBy)
+	clone.LastUsedAt = cloneTime(key.LastUsedAt)
+	clone.RevokedAt = cloneTime(key.RevokedAt)
+	return &clone
+}
@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// 两类审计日志都按写入顺序追加到切片，切片顺序即链序号顺序。
+
+// ---- Prompt 审计日志仓储 ----
+
+type promptAuditLogRepository struct {
+	s *store
+}
+
+func (r *promptAuditLogRepository) Create(ctx context.Context, log *domain.PromptAuditLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.promptAudit {
+		if existing.ID == log.ID {
+			return uniqueViolation("prompt_audit_logs.id")
+		}
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = r.s.now()
+	}
+	log.CreatedAt = log.CreatedAt.UTC().Truncate(time.Microsecond)
+	log.Seq, log.PrevHash = 1, ""
+	if n := len(r.s.promptAudit); n > 0 {
+		last := r.s.promptAudit[n-1]
+		log.Seq, log.PrevHash = last.Seq+1, last.Hash
+	}
+	log.Hash = log.ChainHash()
+	r.s.promptAudit = append(r.s.promptAudit, clonePromptAuditLog(log))
+	return nil
+}
+
+func (r *promptAuditLogRepository) ListByPrompt(ctx context.Context, promptID string, limit int) ([]*domain.PromptAuditLog, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var matches []*domain.PromptAuditLog
+	for _, log := range r.s.promptAudit {
+		if log.PromptID == promptID {
+			matches = append(matches, clonePromptAuditLog(log))
+		}
+	}
+	sortByTime(matches, func(l *domain.PromptAuditLog) time.Time { return l.CreatedAt }, func(l *domain.PromptAuditLog) string { return l.ID }, true)
+	return paginate(matches, limit, 0), nil
+}
+
+func (r *promptAuditLogRepository) Iterate(ctx context.Context, opts domain.AuditLogIterateOptions, fn func(*domain.PromptAuditLog) error) error {
+	r.s.mu.RLock()
+	var logs []*domain.PromptAuditLog
+	for _, log := range r.s.promptAudit {
+		if opts.PromptID != "" && log.PromptID != opts.PromptID {
+			continue
+		}
+		if !inTimeRange(log.CreatedAt, opts.From, opts.To) {
+			continue
+		}
+		logs = append(logs, clonePromptAuditLog(log))
+	}
+	r.s.mu.RUnlock()
+
+	for _, log := range logs {
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func clonePromptAuditLog(log *domain.PromptAuditLog) *domain.PromptAuditLog {
+	clone := *log
+	clone.Payload = cloneBytes(log.Payload)
+	clone.CreatedBy = cloneString(log.CreatedBy)
+	return &clone
+}
+
+// ---- 通用审计日志仓储 ----
+
+type auditLogRepository struct {
+	s *store
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, log *domain.AuditLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.auditLogs {
+		if existing.ID == log.ID {
+			return uniqueViolation("audit_logs.id")
+		}
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = r.s.now()
+	}
+	log.CreatedAt = log.CreatedAt.UTC().Truncate(time.Microsecond)
+	log.Seq, log.PrevHash = 1, ""
+	if n := len(r.s.auditLogs); n > 0 {
+		last := r.s.auditLogs[n-1]
+		log.Seq, log.PrevHash = last.Seq+1, last.Hash
+	}
+	log.Hash = log.ChainHash()
+	r.s.auditLogs = append(r.s.auditLogs, cloneAuditLog(log))
+	return nil
+}
+
+func (r *auditLogRepository) List(ctx context.Context, opts domain.AuditLogListOptions) ([]*domain.AuditLog, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	matches := r.s.filterAuditLogs(opts)
+	// 与 SQL 的 ORDER BY created_at DESC, seq DESC 一致：先反转为 seq 倒序，再按时间稳定排序。
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	sortByTime(matches, func(l *domain.AuditLog) time.Time { return l.CreatedAt }, func(*domain.AuditLog) string { return "" }, true)
+	var logs []*domain.AuditLog
+	for _, log := range paginate(matches, limit, opts.Offset) {
+		logs = append(logs, cloneAuditLog(log))
+	}
+	return logs, nil
+}
+
+func (r *auditLogRepository) Count(ctx context.Context, opts domain.AuditLogListOptions) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.filterAuditLogs(opts))), nil
+}
+
+func (r *auditLogRepository) Iterate(ctx context.Context, opts domain.AuditLogListOptions, fn func(*domain.AuditLog) error) error {
+	r.s.mu.RLock()
+	var logs []*domain.AuditLog
+	for _, log := range r.s.filterAuditLogs(opts) {
+		logs = append(logs, cloneAuditLog(log))
+	}
+	r.s.mu.RUnlock()
+
+	for _, log := range logs {
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterAuditLogs 按链序号升序返回满足条件的审计日志（未复制），调用方需持有读锁。
+func (s *store) filterAuditLogs(opts domain.AuditLogListOptions) []*domain.AuditLog {
+	action := strings.TrimSpace(opts.Action)
+	var logs []*domain.AuditLog
+	for _, log := range s.auditLogs {
+		if action != "" {
+			if strings.HasSuffix(action, ".") {
+				if !strings.HasPrefix(log.Action, action) {
+					continue
+				}
+			} else if log.Action != action {
+				continue
+			}
+		}
+		if opts.Actor != "" && derefString(log.Actor) != opts.Actor {
+			continue
+		}
+		if opts.TargetType != "" && log.TargetType != opts.TargetType {
+			continue
+		}
+		if opts.TargetID != "" && derefString(log.TargetID) != opts.TargetID {
+			continue
+		}
+		if !inTimeRange(log.CreatedAt, opts.From, opts.To) {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+// inTimeRange 判断 at 是否落在 [from, to) 内，零值表示不限。
+func inTimeRange(at, from, to time.Time) bool {
+	if !from.IsZero() && at.Before(from) {
+		return false
+	}
+	return to.IsZero() || at.Before(to)
+}
+
+func cloneAuditLog(log *domain.AuditLog) *domain.AuditLog {
+	clone := *log
+	clone.Actor = cloneString(log.Actor)
+	clone.TargetID = cloneString(log.TargetID)
+	clone.IP = cloneString(log.IP)
+	clone.UserAgent = cloneString(log.UserAgent)
+	clone.Payload = cloneBytes(log.Payload)
+	return &clone
+}
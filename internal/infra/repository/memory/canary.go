@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Prompt 灰度激活仓储 ----
+
+type promptCanaryRepository struct {
+	s *store
+}
+
+func (r *promptCanaryRepository) Create(ctx context.Context, canary *domain.PromptCanary) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.canaries[canary.ID]; ok {
+		return uniqueViolation("prompt_canaries.id")
+	}
+	if canary.StartedAt.IsZero() {
+		canary.StartedAt = r.s.now()
+	}
+	if canary.Status == "" {
+		canary.Status = domain.CanaryStatusRunning
+	}
+	// 与部分唯一索引一致：同一 Prompt 同时只能有一个进行中的灰度。
+	if canary.Status == domain.CanaryStatusRunning {
+		for _, existing := range r.s.canaries {
+			if existing.PromptID == canary.PromptID && existing.Status == domain.CanaryStatusRunning {
+				return uniqueViolation("prompt_canaries.prompt_id")
+			}
+		}
+	}
+	stored := cloneCanary(canary)
+	stored.StartedAt = canary.StartedAt.UTC()
+	stored.CanaryErrorRate, stored.BaselineErrorRate, stored.Reason = nil, nil, nil
+	stored.LastEvaluatedAt, stored.EndedAt = nil, nil
+	r.s.canaries[stored.ID] = stored
+	return nil
+}
+
+func (r *promptCanaryRepository) GetRunning(ctx context.Context, promptID string) (*domain.PromptCanary, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, canary := range r.s.canaries {
+		if canary.PromptID == promptID && canary.Status == domain.CanaryStatusRunning {
+			return cloneCanary(canary), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *promptCanaryRepository) ListRunning(ctx context.Context) ([]*domain.PromptCanary, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var canaries []*domain.PromptCanary
+	for _, canary := range r.s.canaries {
+		if canary.Status == domain.CanaryStatusRunning {
+			canaries = append(canaries, cloneCanary(canary))
+		}
+	}
+	sortByTime(canaries, func(c *domain.PromptCanary) time.Time { return c.StartedAt }, func(c *domain.PromptCanary) string { return c.ID }, false)
+	return canaries, nil
+}
+
+func (r *promptCanaryRepository) UpdateEvaluation(ctx context.Context, id string, canaryRate, baselineRate *float64, evaluatedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	canary, ok := r.s.canaries[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	evaluated := evaluatedAt.UTC()
+	canary.CanaryErrorRate = cloneFloat(canaryRate)
+	canary.BaselineErrorRate = cloneFloat(baselineRate)
+	canary.LastEvaluatedAt = &evaluated
+	return nil
+}
+
+func (r *promptCanaryRepository) Finish(ctx context.Context, id, status string, reason *string, endedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	canary, ok := r.s.canaries[id]
+	if !ok || canary.Status != domain.CanaryStatusRunning {
+		return domain.ErrNotFound
+	}
+	ended := endedAt.UTC()
+	canary.Status = status
+	canary.Reason = cloneString(reason)
+	canary.EndedAt = &ended
+	return nil
+}
+
+func cloneCanary(canary *domain.PromptCanary) *domain.PromptCanary {
+	clone := *canary
+	clone.WebhookURL = cloneString(canary.WebhookURL)
+	clone.CanaryErrorRate = cloneFloat(canary.CanaryErrorRate)
+	clone.BaselineErrorRate = cloneFloat(canary.BaselineErrorRate)
+	clone.Reason = cloneString(canary.Reason)
+	clone.StartedBy = cloneString(canary.StartedBy)
+	clone.LastEvaluatedAt = cloneTime(canary.LastEvaluatedAt)
+	clone.EndedAt = cloneTime(canary.EndedAt)
+	return &clone
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) *domain.Repositories {
+		return New()
+	})
+}
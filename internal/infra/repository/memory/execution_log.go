@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 执行日志仓储 ----
+
+type promptExecutionLogRepository struct {
+	s *store
+}
+
+func (r *promptExecutionLogRepository) Create(ctx context.Context, log *domain.PromptExecutionLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.executionLogs[log.ID]; ok {
+		return uniqueViolation("prompt_execution_logs.id")
+	}
+	stored := cloneExecutionLog(log)
+	stored.CreatedAt = r.s.now()
+	r.s.executionLogs[stored.ID] = stored
+	return nil
+}
+
+// CreateBatch 与 SQL 实现的 ON CONFLICT (id) DO NOTHING 一致，已存在的日志被跳过。
+func (r *promptExecutionLogRepository) CreateBatch(ctx context.Context, logs []*domain.PromptExecutionLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, log := range logs {
+		if _, ok := r.s.executionLogs[log.ID]; ok {
+			continue
+		}
+		stored := cloneExecutionLog(log)
+		if stored.CreatedAt.IsZero() {
+			stored.CreatedAt = r.s.now()
+		} else {
+			stored.CreatedAt = stored.CreatedAt.UTC()
+		}
+		r.s.executionLogs[stored.ID] = stored
+	}
+	return nil
+}
+
+func (r *promptExecutionLogRepository) GetByID(ctx context.Context, id string) (*domain.PromptExecutionLog, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	log, ok := r.s.executionLogs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneExecutionLog(log), nil
+}
+
+func (r *promptExecutionLogRepository) ListRecent(ctx context.Context, promptID string, limit int) ([]*domain.PromptExecutionLog, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var logs []*domain.PromptExecutionLog
+	for _, log := range paginate(r.s.promptExecutionLogs(promptID, time.Time{}, time.Time{}, true), limit, 0) {
+		logs = append(logs, cloneExecutionLog(log))
+	}
+	return logs, nil
+}
+
+// IterateSince 在锁外回调，回调中可以安全地访问其他仓储。
+func (r *promptExecutionLogRepository) IterateSince(ctx context.Context, promptID string, from time.Time, fn func(*domain.PromptExecutionLog) error) error {
+	r.s.mu.RLock()
+	var logs []*domain.PromptExecutionLog
+	for _, log := range r.s.promptExecutionLogs(promptID, from, time.Time{}, true) {
+		logs = append(logs, cloneExecutionLog(log))
+	}
+	r.s.mu.RUnlock()
+
+	for _, log := range logs {
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *promptExecutionLogRepository) AggregateUsage(ctx context.Context, promptID string, opts domain.ExecutionAggregateOptions) ([]*domain.PromptExecutionAggregate, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	unit := opts.Granularity
+	if unit == "" {
+		unit = domain.GranularityDay
+	}
+
+	r.s.mu.RLock()
+	logs := r.s.promptExecutionLogs(promptID, opts.From, opts.To, false)
+	type bucketSamples struct {
+		aggregate *domain.PromptExecutionAggregate
+		durations []float64
+		sum       float64
+	}
+	buckets := make(map[string]*bucketSamples)
+	for _, log := range logs {
+		key := localBucket(log.CreatedAt, unit, loc)
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &bucketSamples{aggregate: &domain.PromptExecutionAggregate{Bucket: key}}
+			buckets[key] = bucket
+		}
+		bucket.aggregate.TotalCalls++
+		if log.Status == "success" {
+			bucket.aggregate.SuccessCalls++
+		}
+		// 耗时为 0 时数据库存为 NULL，不参与平均值与分位数。
+		if log.DurationMs != 0 {
+			bucket.durations = append(bucket.durations, float64(log.DurationMs))
+			bucket.sum += float64(log.DurationMs)
+		}
+		if log.ErrorClass != nil {
+			if bucket.aggregate.ErrorClasses == nil {
+				bucket.aggregate.ErrorClasses = make(map[string]int)
+			}
+			bucket.aggregate.ErrorClasses[*log.ErrorClass]++
+		}
+	}
+	r.s.mu.RUnlock()
+
+	stats := make([]*domain.PromptExecutionAggregate, 0, len(buckets))
+	for key, bucket := range buckets {
+		aggregate := bucket.aggregate
+		if parsed, err := time.ParseInLocation(database.BucketLayout(unit), key, loc); err == nil {
+			aggregate.Day = parsed.UTC()
+		}
+		if len(bucket.durations) > 0 {
+			sort.Float64s(bucket.durations)
+			aggregate.AverageMillis = bucket.sum / float64(len(bucket.durations))
+			aggregate.P50Millis = percentileCont(bucket.durations, 0.5)
+			aggregate.P90Millis = percentileCont(bucket.durations, 0.9)
+			aggregate.P99Millis = percentileCont(bucket.durations, 0.99)
+		}
+		stats = append(stats, aggregate)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Bucket > stats[j].Bucket })
+	return stats, nil
+}
+
+func (r *promptExecutionLogRepository) SummarizeWindow(ctx context.Context, promptID string, from time.Time) (*domain.PromptExecutionWindow, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var (
+		window    domain.PromptExecutionWindow
+		durations []float64
+	)
+	for _, log := range r.s.promptExecutionLogs(promptID, from, time.Time{}, false) {
+		window.TotalCalls++
+		if log.Status != "success" {
+			window.FailedCalls++
+		}
+		if log.DurationMs != 0 {
+			durations = append(durations, float64(log.DurationMs))
+		}
+	}
+	sort.Float64s(durations)
+	window.P95Millis = percentileCont(durations, 0.95)
+	return &window, nil
+}
+
+func (r *promptExecutionLogRepository) SummarizeWindowByVersion(ctx context.Context, promptID string, from time.Time) (map[string]*domain.PromptExecutionWindow, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	windows := make(map[string]*domain.PromptExecutionWindow)
+	for _, log := range r.s.promptExecutionLogs(promptID, from, time.Time{}, false) {
+		window, ok := windows[log.PromptVersionID]
+		if !ok {
+			window = &domain.PromptExecutionWindow{}
+			windows[log.PromptVersionID] = window
+		}
+		window.TotalCalls++
+		if log.Status != "success" {
+			window.FailedCalls++
+		}
+	}
+	return windows, nil
+}
+
+// promptExecutionLogs 返回 Prompt 在 [from, to) 内的日志（零值表示不限），按创建时间排序且未复制，调用方需持有读锁。
+func (s *store) promptExecutionLogs(promptID string, from, to time.Time, desc bool) []*domain.PromptExecutionLog {
+	var logs []*domain.PromptExecutionLog
+	for _, log := range s.executionLogs {
+		if log.PromptID != promptID || log.CreatedAt.Before(from) || (!to.IsZero() && !log.CreatedAt.Before(to)) {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	sortByTime(logs, func(l *domain.PromptExecutionLog) time.Time { return l.CreatedAt }, func(l *domain.PromptExecutionLog) string { return l.ID }, desc)
+	return logs
+}
+
+// localBucket 与 database.Dialect.LocalBucket 一致：换算到 loc 后截断到 unit 起点，周以周一为起点，未知 unit 按天处理。
+func localBucket(at time.Time, unit string, loc *time.Location) string {
+	local := at.In(loc)
+	year, month, day := local.Date()
+	switch unit {
+	case database.BucketHour:
+		return time.Date(year, month, day, local.Hour(), 0, 0, 0, loc).Format(database.BucketLayout(unit))
+	case database.BucketWeek:
+		offset := (int(local.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, loc).Format(database.BucketLayout(unit))
+	case database.BucketMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, loc).Format(database.BucketLayout(unit))
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, loc).Format(database.BucketLayout(unit))
+	}
+}
+
+// percentileCont 对已升序排列的样本做线性插值，与 Postgres percentile_cont 语义一致。
+func percentileCont(sorted []float64, fraction float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := fraction * float64(len(sorted)-1)
+	lower := int(position)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	weight := position - float64(lower)
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*weight
+}
+
+func cloneExecutionLog(log *domain.PromptExecutionLog) *domain.PromptExecutionLog {
+	clone := *log
+	clone.UserID = cloneString(log.UserID)
+	clone.RequestPayload = cloneBytes(log.RequestPayload)
+	clone.ResponseMetadata = cloneBytes(log.ResponseMetadata)
+	clone.ErrorClass = cloneString(log.ErrorClass)
+	clone.ErrorCode = cloneString(log.ErrorCode)
+	return &clone
+}
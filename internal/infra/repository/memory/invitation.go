@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- 成员邀请仓储 ----
+
+type invitationRepository struct {
+	s *store
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.invitations[invitation.ID]; ok {
+		return uniqueViolation("invitations.id")
+	}
+	for _, existing := range r.s.invitations {
+		if existing.TokenHash == invitation.TokenHash {
+			return uniqueViolation("invitations.token_hash")
+		}
+	}
+	stored := cloneInvitation(invitation)
+	stored.ExpiresAt = invitation.ExpiresAt.UTC()
+	stored.AcceptedAt, stored.AcceptedUserID = nil, nil
+	stored.CreatedAt = r.s.now()
+	r.s.invitations[stored.ID] = stored
+	return nil
+}
+
+func (r *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, invitation := range r.s.invitations {
+		if invitation.TokenHash == tokenHash {
+			return cloneInvitation(invitation), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *invitationRepository) ListPending(ctx context.Context, now time.Time) ([]*domain.Invitation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var invitations []*domain.Invitation
+	for _, invitation := range r.s.invitations {
+		if invitation.AcceptedAt == nil && invitation.ExpiresAt.After(now) {
+			invitations = append(invitations, cloneInvitation(invitation))
+		}
+	}
+	sortByTime(invitations, func(i *domain.Invitation) time.Time { return i.CreatedAt }, func(i *domain.Invitation) string { return i.ID }, true)
+	return invitations, nil
+}
+
+func (r *invitationRepository) MarkAccepted(ctx context.Context, invitationID, userID string, acceptedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	invitation, ok := r.s.invitations[invitationID]
+	if !ok || invitation.AcceptedAt != nil {
+		return domain.ErrNotFound
+	}
+	accepted := acceptedAt.UTC()
+	invitation.AcceptedAt = &accepted
+	invitation.AcceptedUserID = &userID
+	return nil
+}
+
+func cloneInvitation(invitation *domain.Invitation) *domain.Invitation {
+	clone := *invitation
+	clone.InvitedBy = cloneString(invitation.InvitedBy)
+	clone.AcceptedAt = cloneTime(invitation.AcceptedAt)
+	clone.AcceptedUserID = cloneString(invitation.AcceptedUserID)
+	return &clone
+}
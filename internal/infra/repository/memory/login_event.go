@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- 登录事件仓储 ----
+
+type loginEventRepository struct {
+	s *store
+}
+
+func (r *loginEventRepository) Create(ctx context.Context, event *domain.LoginEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.loginEvents[event.ID]; ok {
+		return uniqueViolation("login_events.id")
+	}
+	stored := cloneLoginEvent(event)
+	stored.CreatedAt = event.CreatedAt.UTC()
+	r.s.loginEvents[stored.ID] = stored
+	return nil
+}
+
+func (r *loginEventRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*domain.LoginEvent, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var events []*domain.LoginEvent
+	for _, event := range r.s.loginEvents {
+		if event.UserID == userID {
+			events = append(events, cloneLoginEvent(event))
+		}
+	}
+	sortByTime(events, func(e *domain.LoginEvent) time.Time { return e.CreatedAt }, func(e *domain.LoginEvent) string { return e.ID }, true)
+	return paginate(events, limit, 0), nil
+}
+
+func (r *loginEventRepository) HasDevice(ctx context.Context, userID, deviceHash string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, event := range r.s.loginEvents {
+		if event.UserID == userID && event.DeviceHash == deviceHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func cloneLoginEvent(event *domain.LoginEvent) *domain.LoginEvent {
+	clone := *event
+	clone.IPAddress = cloneString(event.IPAddress)
+	clone.UserAgent = cloneString(event.UserAgent)
+	return &clone
+}
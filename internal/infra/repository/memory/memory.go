@@ -0,0 +1,203 @@
+// Package memory 提供 domain.Repositories 的纯内存实现，供单元测试与零依赖的演示模式使用。
+//
+// 各仓储共享同一份受读写锁保护的数据，行为与 SQL 实现保持一致：软删除、工作区隔离、
+// 唯一约束（错误信息包含 "UNIQUE constraint failed"，服务层据此识别冲突）与审计哈希链。
+// 进程退出后数据即丢失。
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// New 构建一套空的内存仓储，预置默认组织、默认工作区与内置脚手架模板（与数据库迁移一致）。
+func New() *domain.Repositories {
+	s := newStore()
+	return &domain.Repositories{
+		Users:              &userRepository{s: s},
+		UserIdentities:     &userIdentityRepository{s: s},
+		Prompts:            &promptRepository{s: s},
+		PromptVersions:     &promptVersionRepository{s: s},
+		PromptLocales:      &promptVersionLocaleRepository{s: s},
+		PromptDependencies: &promptDependencyRepository{s: s},
+		PromptExecutionLog: &promptExecutionLogRepository{s: s},
+		PromptAuditLog:     &promptAuditLogRepository{s: s},
+		Pipelines:          &pipelineRepository{s: s},
+		SigningKeys:        &signingKeyRepository{s: s},
+		AuditLogs:          &auditLogRepository{s: s},
+		Workspaces:         &workspaceRepository{s: s},
+		Invitations:        &invitationRepository{s: s},
+		LoginEvents:        &loginEventRepository{s: s},
+		PromptAlertRules:   &promptAlertRuleRepository{s: s},
+		PromptTemplates:    &promptTemplateRepository{s: s},
+		PromptDrafts:       &promptDraftRepository{s: s},
+		PromptReviews:      &promptReviewRepository{s: s},
+		APIKeys:            &apiKeyRepository{s: s},
+		Usage:              &usageRepository{s: s},
+		Announcements:      &announcementRepository{s: s},
+		PromptCanaries:     &promptCanaryRepository{s: s},
+	}
+}
+
+// store 保存全部表数据。读写均持有 mu；返回给调用方的总是副本，调用方修改返回值不会影响存储。
+type store struct {
+	mu      sync.RWMutex
+	lastNow time.Time
+
+	users         map[string]*domain.User
+	identities    map[string]*domain.UserIdentity
+	prompts       map[string]*domain.Prompt
+	versions      map[string]*domain.PromptVersion
+	locales       map[localeKey]*domain.PromptVersionLocale
+	dependencies  []*dependencyRow
+	executionLogs map[string]*domain.PromptExecutionLog
+	promptAudit   []*domain.PromptAuditLog
+	pipelines     map[string]*domain.Pipeline
+	pipelineVers  map[string]*domain.PipelineVersion
+	signingKeys   map[string]*domain.SigningKey
+	auditLogs     []*domain.AuditLog
+	organizations map[string]*domain.Organization
+	workspaces    map[string]*domain.Workspace
+	members       map[memberKey]*domain.WorkspaceMember
+	invitations   map[string]*domain.Invitation
+	loginEvents   map[string]*domain.LoginEvent
+	alertRules    map[string]*domain.PromptAlertRule
+	templates     map[string]*domain.PromptTemplate
+	drafts        map[draftKey]*domain.PromptDraft
+	policies      map[string]*domain.PromptReviewPolicy
+	approvals     map[approvalKey]*domain.PromptVersionApproval
+	apiKeys       map[string]*domain.APIKey
+	counters      map[counterKey]*domain.UsageCounter
+	exports       map[exportKey]*domain.UsageExport
+	announcements map[string]*domain.Announcement
+	canaries      map[string]*domain.PromptCanary
+}
+
+func newStore() *store {
+	s := &store{
+		users:         make(map[string]*domain.User),
+		identities:    make(map[string]*domain.UserIdentity),
+		prompts:       make(map[string]*domain.Prompt),
+		versions:      make(map[string]*domain.PromptVersion),
+		locales:       make(map[localeKey]*domain.PromptVersionLocale),
+		executionLogs: make(map[string]*domain.PromptExecutionLog),
+		pipelines:     make(map[string]*domain.Pipeline),
+		pipelineVers:  make(map[string]*domain.PipelineVersion),
+		signingKeys:   make(map[string]*domain.SigningKey),
+		organizations: make(map[string]*domain.Organization),
+		workspaces:    make(map[string]*domain.Workspace),
+		members:       make(map[memberKey]*domain.WorkspaceMember),
+		invitations:   make(map[string]*domain.Invitation),
+		loginEvents:   make(map[string]*domain.LoginEvent),
+		alertRules:    make(map[string]*domain.PromptAlertRule),
+		templates:     make(map[string]*domain.PromptTemplate),
+		drafts:        make(map[draftKey]*domain.PromptDraft),
+		policies:      make(map[string]*domain.PromptReviewPolicy),
+		approvals:     make(map[approvalKey]*domain.PromptVersionApproval),
+		apiKeys:       make(map[string]*domain.APIKey),
+		counters:      make(map[counterKey]*domain.UsageCounter),
+		exports:       make(map[exportKey]*domain.UsageExport),
+		announcements: make(map[string]*domain.Announcement),
+		canaries:      make(map[string]*domain.PromptCanary),
+	}
+
+	now := s.now()
+	s.organizations[domain.DefaultOrganizationID] = &domain.Organization{
+		ID: domain.DefaultOrganizationID, Name: "Default", Slug: "default", CreatedAt: now, UpdatedAt: now,
+	}
+	s.workspaces[domain.DefaultWorkspaceID] = &domain.Workspace{
+		ID: domain.DefaultWorkspaceID, OrganizationID: domain.DefaultOrganizationID, Name: "Default", Slug: "default", CreatedAt: now, UpdatedAt: now,
+	}
+	for _, template := range builtinTemplates() {
+		template.CreatedAt, template.UpdatedAt = now, now
+		s.templates[template.Slug] = template
+	}
+	return s
+}
+
+// now 返回严格递增的当前 UTC 时间（调用方需持有写锁），保证同一进程内按时间排序的结果稳定。
+func (s *store) now() time.Time {
+	now := time.Now().UTC()
+	if !now.After(s.lastNow) {
+		now = s.lastNow.Add(time.Nanosecond)
+	}
+	s.lastNow = now
+	return now
+}
+
+// uniqueViolation 模拟数据库的唯一约束错误。
+func uniqueViolation(constraint string) error {
+	return fmt.Errorf("memory: UNIQUE constraint failed: %s", constraint)
+}
+
+// paginate 按 SQL 的 LIMIT/OFFSET 语义截取结果。
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// sortByTime 按时间排序，时间相同时按 tiebreak 升序，保证结果确定。
+func sortByTime[T any](items []T, at func(T) time.Time, tiebreak func(T) string, desc bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := at(items[i]), at(items[j])
+		if !ti.Equal(tj) {
+			if desc {
+				return ti.After(tj)
+			}
+			return ti.Before(tj)
+		}
+		return tiebreak(items[i]) < tiebreak(items[j])
+	})
+}
+
+func cloneString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	v := *value
+	return &v
+}
+
+func cloneTime(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	v := *value
+	return &v
+}
+
+func cloneFloat(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	v := *value
+	return &v
+}
+
+// cloneBytes 复制 JSON 等字节内容，空值统一为 nil（对应数据库中的 NULL）。
+func cloneBytes[T ~[]byte](value T) T {
+	if len(value) == 0 {
+		return nil
+	}
+	return append(T(nil), value...)
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
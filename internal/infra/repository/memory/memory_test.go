@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func TestPromptUniqueNameAndSoftDelete(t *testing.T) {
+	repos := New()
+	ctx := context.Background()
+
+	if err := repos.Prompts.Create(ctx, &domain.Prompt{ID: "p1", Name: "greeting"}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	err := repos.Prompts.Create(ctx, &domain.Prompt{ID: "p2", Name: "greeting"})
+	if err == nil || !strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		t.Fatalf("expected unique violation on duplicate name, got %v", err)
+	}
+
+	if err := repos.Prompts.Delete(ctx, "p1"); err != nil {
+		t.Fatalf("delete prompt: %v", err)
+	}
+	if _, err := repos.Prompts.GetByID(ctx, "p1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected deleted prompt to be hidden, got %v", err)
+	}
+	deleted, err := repos.Prompts.GetByIDIncludeDeleted(ctx, "p1")
+	if err != nil || deleted.DeletedAt == nil || deleted.Status != domain.PromptStatusDeleted {
+		t.Fatalf("expected soft-deleted prompt, got %+v err=%v", deleted, err)
+	}
+}
+
+func TestReturnedValuesAreCopies(t *testing.T) {
+	repos := New()
+	ctx := context.Background()
+
+	description := "original"
+	if err := repos.Prompts.Create(ctx, &domain.Prompt{ID: "p1", Name: "copy", Description: &description}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	description = "mutated by caller"
+
+	got, err := repos.Prompts.GetByID(ctx, "p1")
+	if err != nil {
+		t.Fatalf("get prompt: %v", err)
+	}
+	*got.Description = "mutated after read"
+	got.Name = "renamed"
+
+	again, err := repos.Prompts.GetByID(ctx, "p1")
+	if err != nil {
+		t.Fatalf("get prompt again: %v", err)
+	}
+	if again.Name != "copy" || again.Description == nil || *again.Description != "original" {
+		t.Fatalf("store should not share memory with callers, got %+v", again)
+	}
+}
+
+func TestWorkspaceScopeHidesOtherWorkspaces(t *testing.T) {
+	repos := New()
+	ctx := context.Background()
+
+	if err := repos.Prompts.Create(ctx, &domain.Prompt{ID: "p1", Name: "scoped", WorkspaceID: "ws-a"}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if _, err := repos.Prompts.GetByID(domain.WithWorkspace(ctx, "ws-b"), "p1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected prompt in another workspace to be hidden, got %v", err)
+	}
+	if _, err := repos.Prompts.GetByID(domain.WithWorkspace(ctx, "ws-a"), "p1"); err != nil {
+		t.Fatalf("expected prompt visible in its workspace: %v", err)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	repos := New()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := &domain.User{ID: fmt.Sprintf("u%d", i), Email: fmt.Sprintf("u%d@example.com", i), Role: "viewer", Status: "active"}
+			if err := repos.Users.Create(ctx, user); err != nil {
+				t.Errorf("create user %d: %v", i, err)
+			}
+			if _, err := repos.Users.GetByID(ctx, user.ID); err != nil {
+				t.Errorf("get user %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 50; i++ {
+		if _, err := repos.Users.GetByEmail(ctx, fmt.Sprintf("u%d@example.com", i)); err != nil {
+			t.Fatalf("expected user %d to be stored: %v", i, err)
+		}
+	}
+}
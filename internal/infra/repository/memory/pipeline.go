@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Pipeline 仓储 ----
+
+type pipelineRepository struct {
+	s *store
+}
+
+func (r *pipelineRepository) Create(ctx context.Context, pipeline *domain.Pipeline) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.pipelines[pipeline.ID]; ok {
+		return uniqueViolation("pipelines.id")
+	}
+	for _, existing := range r.s.pipelines {
+		if existing.Name == pipeline.Name {
+			return uniqueViolation("pipelines.name")
+		}
+	}
+	if pipeline.WorkspaceID == "" {
+		pipeline.WorkspaceID = domain.WorkspaceFromContext(ctx)
+	}
+	if pipeline.WorkspaceID == "" {
+		pipeline.WorkspaceID = domain.DefaultWorkspaceID
+	}
+	stored := clonePipeline(pipeline)
+	stored.LatestVersion = 0
+	now := r.s.now()
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	r.s.pipelines[stored.ID] = stored
+	return nil
+}
+
+func (r *pipelineRepository) GetByID(ctx context.Context, pipelineID string) (*domain.Pipeline, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	pipeline, ok := r.s.pipelines[pipelineID]
+	if !ok || !inWorkspace(ctx, pipeline.WorkspaceID) {
+		return nil, domain.ErrNotFound
+	}
+	return clonePipeline(pipeline), nil
+}
+
+func (r *pipelineRepository) List(ctx context.Context, limit, offset int) ([]*domain.Pipeline, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var pipelines []*domain.Pipeline
+	for _, pipeline := range r.s.pipelines {
+		if inWorkspace(ctx, pipeline.WorkspaceID) {
+			pipelines = append(pipelines, clonePipeline(pipeline))
+		}
+	}
+	sortByTime(pipelines, func(p *domain.Pipeline) time.Time { return p.UpdatedAt }, func(p *domain.Pipeline) string { return p.ID }, true)
+	return paginate(pipelines, limit, offset), nil
+}
+
+func (r *pipelineRepository) Count(ctx context.Context) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var total int64
+	for _, pipeline := range r.s.pipelines {
+		if inWorkspace(ctx, pipeline.WorkspaceID) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (r *pipelineRepository) CreateVersion(ctx context.Context, version *domain.PipelineVersion) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.pipelineVers[version.ID]; ok {
+		return uniqueViolation("pipeline_versions.id")
+	}
+	for _, existing := range r.s.pipelineVers {
+		if existing.PipelineID == version.PipelineID && existing.VersionNumber == version.VersionNumber {
+			return uniqueViolation("pipeline_versions.pipeline_id, pipeline_versions.version_number")
+		}
+	}
+	pipeline, ok := r.s.pipelines[version.PipelineID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	stored := clonePipelineVersion(version)
+	stored.CreatedAt = r.s.now()
+	r.s.pipelineVers[stored.ID] = stored
+	pipeline.LatestVersion = version.VersionNumber
+	pipeline.UpdatedAt = stored.CreatedAt
+	return nil
+}
+
+func (r *pipelineRepository) GetVersion(ctx context.Context, pipelineID string, versionNumber int) (*domain.PipelineVersion, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, version := range r.s.pipelineVers {
+		if version.PipelineID == pipelineID && version.VersionNumber == versionNumber {
+			return clonePipelineVersion(version), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *pipelineRepository) ListVersions(ctx context.Context, pipelineID string) ([]*domain.PipelineVersion, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var versions []*domain.PipelineVersion
+	for _, version := range r.s.pipelineVers {
+		if version.PipelineID == pipelineID {
+			versions = append(versions, clonePipelineVersion(version))
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].VersionNumber > versions[j].VersionNumber })
+	return versions, nil
+}
+
+func clonePipeline(pipeline *domain.Pipeline) *domain.Pipeline {
+	clone := *pipeline
+	clone.Description = cloneString(pipeline.Description)
+	clone.CreatedBy = cloneString(pipeline.CreatedBy)
+	return &clone
+}
+
+func clonePipelineVersion(version *domain.PipelineVersion) *domain.PipelineVersion {
+	clone := *version
+	clone.Steps = append([]byte(nil), version.Steps...)
+	clone.CreatedBy = cloneString(version.CreatedBy)
+	return &clone
+}
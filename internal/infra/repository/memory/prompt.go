@@ -0,0 +1,360 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Prompt 仓储 ----
+
+type promptRepository struct {
+	s *store
+}
+
+func (r *promptRepository) Create(ctx context.Context, prompt *domain.Prompt) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.prompts[prompt.ID]; ok {
+		return uniqueViolation("prompts.id")
+	}
+	if r.s.promptNameTaken(prompt.Name, "") {
+		return uniqueViolation("prompts.name")
+	}
+
+	if prompt.WorkspaceID == "" {
+		prompt.WorkspaceID = domain.WorkspaceFromContext(ctx)
+	}
+	if prompt.WorkspaceID == "" {
+		prompt.WorkspaceID = domain.DefaultWorkspaceID
+	}
+
+	now := r.s.now()
+	stored := &domain.Prompt{
+		ID:              prompt.ID,
+		Name:            prompt.Name,
+		Description:     cloneString(prompt.Description),
+		Tags:            cloneBytes(prompt.Tags),
+		ActiveVersionID: cloneString(prompt.ActiveVersionID),
+		Body:            cloneString(prompt.Body),
+		CreatedBy:       cloneString(prompt.CreatedBy),
+		Status:          domain.PromptStatusActive,
+		Owner:           cloneOwner(prompt.Owner),
+		WorkspaceID:     prompt.WorkspaceID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	r.s.prompts[stored.ID] = stored
+	return nil
+}
+
+func (r *promptRepository) GetByID(ctx context.Context, promptID string) (*domain.Prompt, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	prompt, ok := r.s.prompts[promptID]
+	if !ok || prompt.DeletedAt != nil || !inWorkspaceScope(ctx, prompt) {
+		return nil, domain.ErrNotFound
+	}
+	return r.s.promptView(prompt), nil
+}
+
+func (r *promptRepository) GetByIDIncludeDeleted(ctx context.Context, promptID string) (*domain.Prompt, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	prompt, ok := r.s.prompts[promptID]
+	if !ok || !inWorkspaceScope(ctx, prompt) {
+		return nil, domain.ErrNotFound
+	}
+	return r.s.promptView(prompt), nil
+}
+
+func (r *promptRepository) GetByName(ctx context.Context, name string, includeDeleted bool) (*domain.Prompt, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var matches []*domain.Prompt
+	for _, prompt := range r.s.prompts {
+		if !strings.EqualFold(prompt.Name, name) || (!includeDeleted && prompt.DeletedAt != nil) || !inWorkspaceScope(ctx, prompt) {
+			continue
+		}
+		matches = append(matches, prompt)
+	}
+	if len(matches) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	sortByTime(matches, func(p *domain.Prompt) time.Time { return p.CreatedAt }, func(p *domain.Prompt) string { return p.ID }, false)
+	return r.s.promptView(matches[0]), nil
+}
+
+func (r *promptRepository) List(ctx context.Context, opts domain.PromptListOptions) ([]*domain.Prompt, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	matches := r.s.filterPrompts(ctx, opts)
+	sortByTime(matches, func(p *domain.Prompt) time.Time { return p.UpdatedAt }, func(p *domain.Prompt) string { return p.ID }, true)
+	var prompts []*domain.Prompt
+	for _, prompt := range paginate(matches, limit, opts.Offset) {
+		view := r.s.promptView(prompt)
+		if opts.OmitBody {
+			view.Body = nil
+		}
+		prompts = append(prompts, view)
+	}
+	return prompts, nil
+}
+
+func (r *promptRepository) Count(ctx context.Context, opts domain.PromptListOptions) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.filterPrompts(ctx, opts))), nil
+}
+
+// EstimateCount 内存中精确计数的代价很低，因此与 SQLite 一样不提供估计值。
+func (r *promptRepository) EstimateCount(ctx context.Context) (int64, bool, error) {
+	return 0, false, nil
+}
+
+func (r *promptRepository) UpdateActiveVersion(ctx context.Context, promptID string, versionID *string, body *string) error {
+	return r.update(promptID, func(prompt *domain.Prompt) error {
+		// 切换到不同版本时把原激活版本记入 PreviousActiveVersionID，重复激活同一版本不覆盖回滚目标。
+		if prompt.ActiveVersionID != nil && *prompt.ActiveVersionID != derefString(versionID) {
+			prompt.PreviousActiveVersionID = prompt.ActiveVersionID
+		}
+		prompt.ActiveVersionID = cloneString(versionID)
+		prompt.Body = cloneString(body)
+		return nil
+	})
+}
+
+func (r *promptRepository) Update(ctx context.Context, promptID string, params domain.PromptUpdateParams) error {
+	if params.HasName && params.Name == nil {
+		return fmt.Errorf("prompt name cannot be nil")
+	}
+	if !params.HasName && !params.HasDescription && !params.HasTags && !params.HasRenderMode {
+		return nil
+	}
+	return r.update(promptID, func(prompt *domain.Prompt) error {
+		if params.HasName {
+			if r.s.promptNameTaken(*params.Name, prompt.ID) {
+				return uniqueViolation("prompts.name")
+			}
+			prompt.Name = *params.Name
+		}
+		if params.HasDescription {
+			prompt.Description = cloneString(params.Description)
+		}
+		if params.HasTags {
+			prompt.Tags = tagsValue(params.Tags)
+		}
+		if params.HasRenderMode {
+			prompt.RenderMode = cloneString(params.RenderMode)
+		}
+		return nil
+	})
+}
+
+func (r *promptRepository) Delete(ctx context.Context, promptID string) error {
+	return r.update(promptID, func(prompt *domain.Prompt) error {
+		deletedAt := r.s.lastNow
+		prompt.Status = domain.PromptStatusDeleted
+		prompt.DeletedAt = &deletedAt
+		return nil
+	})
+}
+
+func (r *promptRepository) Restore(ctx context.Context, promptID string, params domain.PromptRestoreParams) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	prompt, ok := r.s.prompts[promptID]
+	if !ok || prompt.Status != domain.PromptStatusDeleted {
+		return domain.ErrNotFound
+	}
+	prompt.Status = domain.PromptStatusActive
+	prompt.DeletedAt = nil
+	prompt.UpdatedAt = r.s.now()
+	if params.HasDescription {
+		prompt.Description = cloneString(params.Description)
+	}
+	if params.HasTags {
+		prompt.Tags = tagsValue(params.Tags)
+	}
+	if params.HasCreatedBy {
+		prompt.CreatedBy = cloneString(params.CreatedBy)
+	}
+	if params.HasBody {
+		prompt.Body = cloneString(params.Body)
+	}
+	return nil
+}
+
+func (r *promptRepository) UpdateOwner(ctx context.Context, promptID string, owner *domain.PromptOwner) error {
+	return r.update(promptID, func(prompt *domain.Prompt) error {
+		prompt.Owner = cloneOwner(owner)
+		return nil
+	})
+}
+
+func (r *promptRepository) UpdateStatus(ctx context.Context, promptID, from, to string) error {
+	return r.update(promptID, func(prompt *domain.Prompt) error {
+		if prompt.Status != from {
+			return domain.ErrNotFound
+		}
+		prompt.Status = to
+		return nil
+	})
+}
+
+// update 在写锁内修改未删除的 Prompt 并刷新 updated_at；apply 返回错误时不做任何修改。
+func (r *promptRepository) update(promptID string, apply func(*domain.Prompt) error) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	prompt, ok := r.s.prompts[promptID]
+	if !ok || prompt.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	updated := *prompt
+	if err := apply(&updated); err != nil {
+		return err
+	}
+	updated.UpdatedAt = r.s.now()
+	if updated.DeletedAt != nil {
+		deletedAt := updated.UpdatedAt
+		updated.DeletedAt = &deletedAt
+	}
+	*prompt = updated
+	return nil
+}
+
+// filterPrompts 返回满足列表条件的 Prompt（未排序、未复制），调用方需持有读锁。
+func (s *store) filterPrompts(ctx context.Context, opts domain.PromptListOptions) []*domain.Prompt {
+	search := strings.TrimSpace(strings.ToLower(opts.Search))
+	workspaceID := opts.WorkspaceID
+	if workspaceID == "" {
+		workspaceID = domain.WorkspaceFromContext(ctx)
+	}
+	creators := make(map[string]bool, len(opts.CreatedBy))
+	for _, creator := range opts.CreatedBy {
+		creators[creator] = true
+	}
+
+	var matches []*domain.Prompt
+	for _, prompt := range s.prompts {
+		if !opts.IncludeDeleted && prompt.DeletedAt != nil {
+			continue
+		}
+		switch {
+		case opts.ArchivedOnly:
+			if prompt.Status != domain.PromptStatusArchived {
+				continue
+			}
+		case opts.IncludeArchived:
+		default:
+			if prompt.Status == domain.PromptStatusArchived {
+				continue
+			}
+		}
+		if search != "" && !strings.Contains(strings.ToLower(prompt.Name), search) {
+			continue
+		}
+		if workspaceID != "" && prompt.WorkspaceID != workspaceID {
+			continue
+		}
+		if len(creators) > 0 && (prompt.CreatedBy == nil || !creators[*prompt.CreatedBy]) {
+			continue
+		}
+		if len(opts.TargetModels) > 0 && !s.activeVersionTargets(prompt, opts.TargetModels) {
+			continue
+		}
+		matches = append(matches, prompt)
+	}
+	return matches
+}
+
+// activeVersionTargets 判断 Prompt 的激活版本是否声明了任一目标模型，与 SQL 仓储的 EXISTS 子查询一致。
+func (s *store) activeVersionTargets(prompt *domain.Prompt, models []string) bool {
+	if prompt.ActiveVersionID == nil {
+		return false
+	}
+	version, ok := s.versions[*prompt.ActiveVersionID]
+	if !ok {
+		return false
+	}
+	for _, model := range models {
+		if slices.Contains(version.TargetModels, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptNameTaken 判断名称是否已被其他 Prompt（含已删除的）占用，与 prompts.name 唯一约束一致。
+func (s *store) promptNameTaken(name, exceptID string) bool {
+	for _, prompt := range s.prompts {
+		if prompt.ID != exceptID && prompt.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// promptView 返回 Prompt 的副本；与 SQL 实现关联 users 表一致，创建人为用户 ID 时展示其邮箱。
+func (s *store) promptView(prompt *domain.Prompt) *domain.Prompt {
+	clone := *prompt
+	clone.Description = cloneString(prompt.Description)
+	clone.Tags = cloneBytes(prompt.Tags)
+	clone.ActiveVersionID = cloneString(prompt.ActiveVersionID)
+	clone.PreviousActiveVersionID = cloneString(prompt.PreviousActiveVersionID)
+	clone.Body = cloneString(prompt.Body)
+	clone.CreatedBy = cloneString(prompt.CreatedBy)
+	clone.DeletedAt = cloneTime(prompt.DeletedAt)
+	clone.RenderMode = cloneString(prompt.RenderMode)
+	clone.Owner = cloneOwner(prompt.Owner)
+	if prompt.CreatedBy != nil {
+		if user, ok := s.users[*prompt.CreatedBy]; ok {
+			email := user.Email
+			clone.CreatedBy = &email
+		}
+	}
+	return &clone
+}
+
+// inWorkspaceScope 在上下文携带工作区时校验 Prompt 归属，跨工作区访问表现为记录不存在。
+func inWorkspaceScope(ctx context.Context, prompt *domain.Prompt) bool {
+	return inWorkspace(ctx, prompt.WorkspaceID)
+}
+
+// inWorkspace 判断归属 workspaceID 的记录对上下文中的工作区是否可见，上下文未携带工作区时不限定。
+func inWorkspace(ctx context.Context, workspaceID string) bool {
+	scope := domain.WorkspaceFromContext(ctx)
+	return scope == "" || workspaceID == scope
+}
+
+func tagsValue(tags *string) json.RawMessage {
+	if tags == nil {
+		return nil
+	}
+	return json.RawMessage(*tags)
+}
+
+func cloneOwner(owner *domain.PromptOwner) *domain.PromptOwner {
+	if owner == nil {
+		return nil
+	}
+	clone := *owner
+	return &clone
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Prompt 草稿仓储 ----
+
+type draftKey struct {
+	promptID string
+	userID   string
+}
+
+type promptDraftRepository struct {
+	s *store
+}
+
+func (r *promptDraftRepository) Get(ctx context.Context, promptID, userID string) (*domain.PromptDraft, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	draft, ok := r.s.drafts[draftKey{promptID: promptID, userID: userID}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return clonePromptDraft(draft), nil
+}
+
+func (r *promptDraftRepository) Save(ctx context.Context, draft *domain.PromptDraft, expectedRevision *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := draftKey{promptID: draft.PromptID, userID: draft.UserID}
+	existing, ok := r.s.drafts[key]
+	switch {
+	case expectedRevision != nil && *expectedRevision > 0:
+		if !ok || existing.Revision != *expectedRevision {
+			return domain.ErrRevisionConflict
+		}
+	case expectedRevision != nil:
+		if ok {
+			return domain.ErrRevisionConflict
+		}
+	}
+
+	now := r.s.now()
+	stored := clonePromptDraft(draft)
+	stored.Revision, stored.CreatedAt, stored.UpdatedAt = 1, now, now
+	if ok {
+		stored.Revision = existing.Revision + 1
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.s.drafts[key] = stored
+	return nil
+}
+
+func (r *promptDraftRepository) Delete(ctx context.Context, promptID, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := draftKey{promptID: promptID, userID: userID}
+	if _, ok := r.s.drafts[key]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.drafts, key)
+	return nil
+}
+
+func clonePromptDraft(draft *domain.PromptDraft) *domain.PromptDraft {
+	clone := *draft
+	clone.VariablesSchema = cloneBytes(draft.VariablesSchema)
+	clone.Metadata = cloneBytes(draft.Metadata)
+	clone.BaseVersionID = cloneString(draft.BaseVersionID)
+	return &clone
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Prompt 评审仓储 ----
+
+type approvalKey struct {
+	versionID  string
+	reviewerID string
+}
+
+type promptReviewRepository struct {
+	s *store
+}
+
+func (r *promptReviewRepository) GetPolicy(ctx context.Context, promptID string) (*domain.PromptReviewPolicy, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	policy, ok := r.s.policies[promptID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneReviewPolicy(policy), nil
+}
+
+func (r *promptReviewRepository) SavePolicy(ctx context.Context, policy *domain.PromptReviewPolicy) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := cloneReviewPolicy(policy)
+	now := r.s.now()
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	if existing, ok := r.s.policies[policy.PromptID]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.s.policies[stored.PromptID] = stored
+	return nil
+}
+
+func (r *promptReviewRepository) DeletePolicy(ctx context.Context, promptID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.policies[promptID]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.policies, promptID)
+	return nil
+}
+
+func (r *promptReviewRepository) Approve(ctx context.Context, approval *domain.PromptVersionApproval) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if approval.CreatedAt.IsZero() {
+		approval.CreatedAt = r.s.now()
+	}
+	r.s.approvals[approvalKey{versionID: approval.VersionID, reviewerID: approval.ReviewerID}] = cloneApproval(approval)
+	return nil
+}
+
+func (r *promptReviewRepository) RevokeApproval(ctx context.Context, versionID, reviewerID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := approvalKey{versionID: versionID, reviewerID: reviewerID}
+	if _, ok := r.s.approvals[key]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.approvals, key)
+	return nil
+}
+
+func (r *promptReviewRepository) ListApprovals(ctx context.Context, versionID string) ([]*domain.PromptVersionApproval, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var approvals []*domain.PromptVersionApproval
+	for key, approval := range r.s.approvals {
+		if key.versionID == versionID {
+			approvals = append(approvals, cloneApproval(approval))
+		}
+	}
+	sortByTime(approvals, func(a *domain.PromptVersionApproval) time.Time { return a.CreatedAt }, func(a *domain.PromptVersionApproval) string { return a.ReviewerID }, false)
+	return approvals, nil
+}
+
+func cloneReviewPolicy(policy *domain.PromptReviewPolicy) *domain.PromptReviewPolicy {
+	clone := *policy
+	if policy.Reviewers != nil {
+		clone.Reviewers = append([]domain.PromptOwner{}, policy.Reviewers...)
+	}
+	clone.UpdatedBy = cloneString(policy.UpdatedBy)
+	return &clone
+}
+
+func cloneApproval(approval *domain.PromptVersionApproval) *domain.PromptVersionApproval {
+	clone := *approval
+	clone.Reviewer = cloneString(approval.Reviewer)
+	clone.Comment = cloneString(approval.Comment)
+	return &clone
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Prompt 脚手架模板仓储 ----
+
+type promptTemplateRepository struct {
+	s *store
+}
+
+func (r *promptTemplateRepository) Create(ctx context.Context, template *domain.PromptTemplate) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.templates[template.Slug]; ok {
+		return uniqueViolation("prompt_templates.slug")
+	}
+	for _, existing := range r.s.templates {
+		if existing.ID == template.ID {
+			return uniqueViolation("prompt_templates.id")
+		}
+	}
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = r.s.now()
+	}
+	template.UpdatedAt = template.CreatedAt
+	r.s.templates[template.Slug] = clonePromptTemplate(template)
+	return nil
+}
+
+func (r *promptTemplateRepository) GetBySlug(ctx context.Context, slug string) (*domain.PromptTemplate, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	template, ok := r.s.templates[slug]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return clonePromptTemplate(template), nil
+}
+
+func (r *promptTemplateRepository) List(ctx context.Context) ([]*domain.PromptTemplate, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var templates []*domain.PromptTemplate
+	for _, template := range r.s.templates {
+		templates = append(templates, clonePromptTemplate(template))
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Slug < templates[j].Slug })
+	return templates, nil
+}
+
+func (r *promptTemplateRepository) Update(ctx context.Context, template *domain.PromptTemplate) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	template.UpdatedAt = r.s.now()
+	existing, ok := r.s.templates[template.Slug]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.Name = template.Name
+	existing.Description = cloneString(template.Description)
+	existing.Body = template.Body
+	existing.VariablesSchema = cloneBytes(template.VariablesSchema)
+	existing.Metadata = cloneBytes(template.Metadata)
+	existing.Tags = cloneBytes(template.Tags)
+	existing.UpdatedAt = template.UpdatedAt
+	return nil
+}
+
+func (r *promptTemplateRepository) Delete(ctx context.Context, slug string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.templates[slug]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.templates, slug)
+	return nil
+}
+
+func clonePromptTemplate(template *domain.PromptTemplate) *domain.PromptTemplate {
+	clone := *template
+	clone.Description = cloneString(template.Description)
+	clone.VariablesSchema = cloneBytes(template.VariablesSchema)
+	clone.Metadata = cloneBytes(template.Metadata)
+	clone.Tags = cloneBytes(template.Tags)
+	clone.CreatedBy = cloneString(template.CreatedBy)
+	return &clone
+}
+
+// builtinTemplates 返回迁移 000019 预置的内置模板。
+func builtinTemplates() []*domain.PromptTemplate {
+	ragDescription := "基于检索到的上下文回答问题，上下文不足时明确说明。"
+	summarizerDescription := "把长文本压缩为指定长度的要点摘要。"
+	return []*domain.PromptTemplate{
+		{
+			ID:          "builtin-rag-qa",
+			Slug:        "rag-qa",
+			Name:        "RAG 问答",
+			Description: &ragDescription,
+			Body: `你是一名严谨的问答助手。请仅根据以下上下文回答问题，上下文中没有答案时回答“根据现有资料无法回答”。
+
+## 上下文
+{{context}}
+
+## 问题
+{{question}}
+
+## 回答要求
+- 使用与问题相同的语言
+- 引用上下文中的关键信息`,
+			VariablesSchema: json.RawMessage(`{"type":"object","properties":{"context":{"type":"string"},"question":{"type":"string"}},"required":["context","question"]}`),
+			Tags:            json.RawMessage(`["rag","qa"]`),
+		},
+		{
+			ID:          "builtin-summarizer",
+			Slug:        "summarizer",
+			Name:        "摘要生成",
+			Description: &summarizerDescription,
+			Body: `请把下面的内容总结为不超过 {{max_words}} 字的摘要，保留关键事实与结论，使用要点列表输出。
+
+## 内容
+{{text}}`,
+			VariablesSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"},"max_words":{"type":"integer"}},"required":["text"]}`),
+			Tags:            json.RawMessage(`["summarization"]`),
+		},
+	}
+}
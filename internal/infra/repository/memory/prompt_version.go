@@ -0,0 +1,391 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- Prompt 版本仓储 ----
+
+type promptVersionRepository struct {
+	s *store
+}
+
+func (r *promptVersionRepository) Create(ctx context.Context, version *domain.PromptVersion) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.versions[version.ID]; ok {
+		return uniqueViolation("prompt_versions.id")
+	}
+	for _, existing := range r.s.versions {
+		if existing.PromptID == version.PromptID && existing.VersionNumber == version.VersionNumber {
+			return uniqueViolation("prompt_versions.prompt_id, prompt_versions.version_number")
+		}
+	}
+
+	stored := clonePromptVersion(version)
+	if stored.Status == "" {
+		stored.Status = domain.PromptVersionStatusDraft
+	}
+	stored.CreatedAt = r.s.now()
+	r.s.versions[stored.ID] = stored
+	return nil
+}
+
+func (r *promptVersionRepository) GetByID(ctx context.Context, versionID string) (*domain.PromptVersion, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	version, ok := r.s.versions[versionID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return clonePromptVersion(version), nil
+}
+
+func (r *promptVersionRepository) MetadataByIDs(ctx context.Context, versionIDs []string) (map[string]json.RawMessage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	metadata := make(map[string]json.RawMessage, len(versionIDs))
+	for _, id := range versionIDs {
+		if version, ok := r.s.versions[id]; ok && len(version.Metadata) > 0 {
+			metadata[id] = cloneBytes(version.Metadata)
+		}
+	}
+	return metadata, nil
+}
+
+func (r *promptVersionRepository) ListByPrompt(ctx context.Context, promptID string, limit, offset int) ([]*domain.PromptVersion, error) {
+	return r.list(promptID, func(*domain.PromptVersion) bool { return true }, limit, offset), nil
+}
+
+func (r *promptVersionRepository) ListByPromptAndStatus(ctx context.Context, promptID string, status string, limit, offset int) ([]*domain.PromptVersion, error) {
+	return r.list(promptID, func(v *domain.PromptVersion) bool { return v.Status == status }, limit, offset), nil
+}
+
+// IterateByPrompt 在锁外回调，回调中可以安全地访问其他仓储。
+func (r *promptVersionRepository) IterateByPrompt(ctx context.Context, promptID string, status string, fn func(*domain.PromptVersion) error) error {
+	r.s.mu.RLock()
+	matches := r.s.promptVersions(promptID, func(v *domain.PromptVersion) bool { return status == "" || v.Status == status })
+	sort.Slice(matches, func(i, j int) bool { return matches[i].VersionNumber > matches[j].VersionNumber })
+	versions := make([]*domain.PromptVersion, 0, len(matches))
+	for _, version := range matches {
+		versions = append(versions, clonePromptVersion(version))
+	}
+	r.s.mu.RUnlock()
+
+	for _, version := range versions {
+		if err := fn(version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list 按版本号倒序返回 Prompt 下满足条件的版本，limit 缺省为 50。
+func (r *promptVersionRepository) list(promptID string, match func(*domain.PromptVersion) bool, limit, offset int) []*domain.PromptVersion {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	matches := r.s.promptVersions(promptID, match)
+	sort.Slice(matches, func(i, j int) bool { return matches[i].VersionNumber > matches[j].VersionNumber })
+	var versions []*domain.PromptVersion
+	for _, version := range paginate(matches, limit, offset) {
+		versions = append(versions, clonePromptVersion(version))
+	}
+	return versions
+}
+
+func (r *promptVersionRepository) CountByPrompt(ctx context.Context, promptID string) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.promptVersions(promptID, func(*domain.PromptVersion) bool { return true }))), nil
+}
+
+func (r *promptVersionRepository) CountByPromptAndStatus(ctx context.Context, promptID string, status string) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.promptVersions(promptID, func(v *domain.PromptVersion) bool { return v.Status == status }))), nil
+}
+
+func (r *promptVersionRepository) GetLatestVersionNumber(ctx context.Context, promptID string) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	latest := 0
+	for _, version := range r.s.promptVersions(promptID, func(*domain.PromptVersion) bool { return true }) {
+		if version.VersionNumber > latest {
+			latest = version.VersionNumber
+		}
+	}
+	return latest, nil
+}
+
+func (r *promptVersionRepository) GetPreviousVersion(ctx context.Context, promptID string, versionNumber int) (*domain.PromptVersion, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var previous *domain.PromptVersion
+	for _, version := range r.s.promptVersions(promptID, func(v *domain.PromptVersion) bool { return v.VersionNumber < versionNumber }) {
+		if previous == nil || version.VersionNumber > previous.VersionNumber {
+			previous = version
+		}
+	}
+	if previous == nil {
+		return nil, domain.ErrNotFound
+	}
+	return clonePromptVersion(previous), nil
+}
+
+// DeleteByIDs 与 SQL 实现一致：语言变体、调用日志与批准记录按版本 ID 清理，版本本身仅在属于 promptID 时删除。
+func (r *promptVersionRepository) DeleteByIDs(ctx context.Context, promptID string, versionIDs []string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var deleted int64
+	for _, versionID := range versionIDs {
+		for key := range r.s.locales {
+			if key.versionID == versionID {
+				delete(r.s.locales, key)
+			}
+		}
+		for id, log := range r.s.executionLogs {
+			if log.PromptVersionID == versionID {
+				delete(r.s.executionLogs, id)
+			}
+		}
+		for key := range r.s.approvals {
+			if key.versionID == versionID {
+				delete(r.s.approvals, key)
+			}
+		}
+		if version, ok := r.s.versions[versionID]; ok && version.PromptID == promptID {
+			delete(r.s.versions, versionID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *promptVersionRepository) UpdateStatus(ctx context.Context, versionID, from, to string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	version, ok := r.s.versions[versionID]
+	if !ok || version.Status != from {
+		return domain.ErrNotFound
+	}
+	version.Status = to
+	return nil
+}
+
+// promptVersions 返回 Prompt 下满足条件的版本（未排序、未复制），调用方需持有读锁。
+func (s *store) promptVersions(promptID string, match func(*domain.PromptVersion) bool) []*domain.PromptVersion {
+	var versions []*domain.PromptVersion
+	for _, version := range s.versions {
+		if version.PromptID == promptID && match(version) {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+func clonePromptVersion(version *domain.PromptVersion) *domain.PromptVersion {
+	clone := *version
+	clone.VariablesSchema = cloneBytes(version.VariablesSchema)
+	clone.Metadata = cloneBytes(version.Metadata)
+	clone.TargetModels = slices.Clone(version.TargetModels)
+	clone.CreatedBy = cloneString(version.CreatedBy)
+	return &clone
+}
+
+// ---- Prompt 版本语言变体仓储 ----
+
+type localeKey struct {
+	versionID string
+	locale    string
+}
+
+type promptVersionLocaleRepository struct {
+	s *store
+}
+
+func (r *promptVersionLocaleRepository) Upsert(ctx context.Context, locale *domain.PromptVersionLocale) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := localeKey{versionID: locale.VersionID, locale: locale.Locale}
+	now := r.s.now()
+	if existing, ok := r.s.locales[key]; ok {
+		existing.Body = locale.Body
+		existing.UpdatedAt = now
+		return nil
+	}
+	stored := clonePromptVersionLocale(locale)
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	r.s.locales[key] = stored
+	return nil
+}
+
+func (r *promptVersionLocaleRepository) GetByVersionAndLocale(ctx context.Context, versionID, locale string) (*domain.PromptVersionLocale, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	stored, ok := r.s.locales[localeKey{versionID: versionID, locale: locale}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return clonePromptVersionLocale(stored), nil
+}
+
+func (r *promptVersionLocaleRepository) ListByVersion(ctx context.Context, versionID string) ([]*domain.PromptVersionLocale, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var locales []*domain.PromptVersionLocale
+	for key, stored := range r.s.locales {
+		if key.versionID == versionID {
+			locales = append(locales, clonePromptVersionLocale(stored))
+		}
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i].Locale < locales[j].Locale })
+	return locales, nil
+}
+
+func (r *promptVersionLocaleRepository) Delete(ctx context.Context, versionID, locale string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := localeKey{versionID: versionID, locale: locale}
+	if _, ok := r.s.locales[key]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.locales, key)
+	return nil
+}
+
+func (r *promptVersionLocaleRepository) ListActiveCoverage(ctx context.Context) ([]*domain.PromptLocaleCoverage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var items []*domain.PromptLocaleCoverage
+	for _, prompt := range r.s.prompts {
+		if prompt.DeletedAt != nil || prompt.ActiveVersionID == nil {
+			continue
+		}
+		item := &domain.PromptLocaleCoverage{PromptID: prompt.ID, PromptName: prompt.Name, ActiveVersionID: *prompt.ActiveVersionID, Locales: []string{}}
+		for key := range r.s.locales {
+			if key.versionID == item.ActiveVersionID {
+				item.Locales = append(item.Locales, key.locale)
+			}
+		}
+		sort.Strings(item.Locales)
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].PromptName != items[j].PromptName {
+			return items[i].PromptName < items[j].PromptName
+		}
+		return items[i].PromptID < items[j].PromptID
+	})
+	return items, nil
+}
+
+func clonePromptVersionLocale(locale *domain.PromptVersionLocale) *domain.PromptVersionLocale {
+	clone := *locale
+	clone.CreatedBy = cloneString(locale.CreatedBy)
+	return &clone
+}
+
+// ---- Prompt 依赖仓储 ----
+
+// dependencyRow 对应 prompt_dependencies 表的一行。
+type dependencyRow struct {
+	promptID    string
+	dependsOnID string
+	source      string
+	createdBy   *string
+}
+
+type promptDependencyRepository struct {
+	s *store
+}
+
+func (r *promptDependencyRepository) ReplaceBySource(ctx context.Context, promptID, source string, dependsOn []string, createdBy *string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// 先校验再替换，重复的依赖与数据库主键冲突一样整体失败，原有依赖保持不变。
+	seen := make(map[string]bool, len(dependsOn))
+	for _, dependencyID := range dependsOn {
+		if seen[dependencyID] {
+			return uniqueViolation("prompt_dependencies.prompt_id, prompt_dependencies.depends_on_id, prompt_dependencies.source")
+		}
+		seen[dependencyID] = true
+	}
+
+	kept := r.s.dependencies[:0:0]
+	for _, row := range r.s.dependencies {
+		if row.promptID != promptID || row.source != source {
+			kept = append(kept, row)
+		}
+	}
+	for _, dependencyID := range dependsOn {
+		kept = append(kept, &dependencyRow{promptID: promptID, dependsOnID: dependencyID, source: source, createdBy: cloneString(createdBy)})
+	}
+	r.s.dependencies = kept
+	return nil
+}
+
+func (r *promptDependencyRepository) ListDependencies(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	links := []*domain.PromptDependencyLink{}
+	for _, row := range r.s.dependencies {
+		if row.promptID != promptID {
+			continue
+		}
+		if prompt, ok := r.s.prompts[row.dependsOnID]; ok {
+			links = append(links, &domain.PromptDependencyLink{PromptID: prompt.ID, Name: prompt.Name, Status: prompt.Status, Source: row.source})
+		}
+	}
+	sortDependencyLinks(links)
+	return links, nil
+}
+
+func (r *promptDependencyRepository) ListDependents(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	links := []*domain.PromptDependencyLink{}
+	for _, row := range r.s.dependencies {
+		if row.dependsOnID != promptID {
+			continue
+		}
+		if prompt, ok := r.s.prompts[row.promptID]; ok && prompt.DeletedAt == nil {
+			links = append(links, &domain.PromptDependencyLink{PromptID: prompt.ID, Name: prompt.Name, Status: prompt.Status, Source: row.source})
+		}
+	}
+	sortDependencyLinks(links)
+	return links, nil
+}
+
+func sortDependencyLinks(links []*domain.PromptDependencyLink) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Name != links[j].Name {
+			return links[i].Name < links[j].Name
+		}
+		return links[i].Source < links[j].Source
+	})
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- 签名密钥仓储 ----
+
+type signingKeyRepository struct {
+	s *store
+}
+
+func (r *signingKeyRepository) Rotate(ctx context.Context, key *domain.SigningKey, retiresAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.signingKeys[key.ID]; ok {
+		return uniqueViolation("signing_keys.id")
+	}
+	if key.OrganizationID == "" {
+		key.OrganizationID = domain.DefaultOrganizationID
+	}
+	for _, existing := range r.s.signingKeys {
+		if existing.Status == "active" && existing.OrganizationID == key.OrganizationID {
+			retires := retiresAt
+			existing.Status = "retired"
+			existing.RetiresAt = &retires
+		}
+	}
+	r.s.signingKeys[key.ID] = &domain.SigningKey{
+		ID:             key.ID,
+		OrganizationID: key.OrganizationID,
+		Algorithm:      key.Algorithm,
+		EncryptedKey:   key.EncryptedKey,
+		Status:         "active",
+		CreatedBy:      cloneString(key.CreatedBy),
+		CreatedAt:      r.s.now(),
+	}
+	return nil
+}
+
+func (r *signingKeyRepository) ListUsable(ctx context.Context, now time.Time) ([]*domain.SigningKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var keys []*domain.SigningKey
+	for _, key := range r.s.signingKeys {
+		if key.Status != "active" && (key.RetiresAt == nil || !key.RetiresAt.After(now)) {
+			continue
+		}
+		clone := *key
+		clone.CreatedBy = cloneString(key.CreatedBy)
+		clone.RetiresAt = cloneTime(key.RetiresAt)
+		keys = append(keys, &clone)
+	}
+	sortByTime(keys, func(k *domain.SigningKey) time.Time { return k.CreatedAt }, func(k *domain.SigningKey) string { return k.ID }, true)
+	return keys, nil
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- 计量仓储 ----
+
+type counterKey struct {
+	workspaceID string
+	period      string
+	metric      string
+}
+
+type exportKey struct {
+	organizationID string
+	period         string
+	metric         string
+	exporter       string
+}
+
+type usageRepository struct {
+	s *store
+}
+
+func (r *usageRepository) AddCounters(ctx context.Context, counters []domain.UsageCounter) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, counter := range counters {
+		key := counterKey{workspaceID: counter.WorkspaceID, period: counter.Period, metric: counter.Metric}
+		if existing, ok := r.s.counters[key]; ok {
+			existing.Value += counter.Value
+			continue
+		}
+		stored := counter
+		r.s.counters[key] = &stored
+	}
+	return nil
+}
+
+func (r *usageRepository) TenantUsage(ctx context.Context, period string, from, to time.Time) ([]*domain.TenantUsage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var usages []*domain.TenantUsage
+	byOrg := make(map[string]*domain.TenantUsage)
+	for _, org := range r.s.organizations {
+		usage := &domain.TenantUsage{OrganizationID: org.ID, OrganizationName: org.Name, Period: period}
+		usages = append(usages, usage)
+		byOrg[org.ID] = usage
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].OrganizationName != usages[j].OrganizationName {
+			return usages[i].OrganizationName < usages[j].OrganizationName
+		}
+		return usages[i].OrganizationID < usages[j].OrganizationID
+	})
+
+	// orgOf 沿 Prompt → 工作区 → 组织 找到归属组织，对应 SQL 实现中的 JOIN。
+	orgOf := func(promptID string) *domain.TenantUsage {
+		prompt, ok := r.s.prompts[promptID]
+		if !ok {
+			return nil
+		}
+		workspace, ok := r.s.workspaces[prompt.WorkspaceID]
+		if !ok {
+			return nil
+		}
+		return byOrg[workspace.OrganizationID]
+	}
+
+	for key, counter := range r.s.counters {
+		if key.period != period || key.metric != domain.UsageMetricAPICalls {
+			continue
+		}
+		if workspace, ok := r.s.workspaces[key.workspaceID]; ok {
+			if usage := byOrg[workspace.OrganizationID]; usage != nil {
+				usage.APICalls += counter.Value
+			}
+		}
+	}
+	for _, log := range r.s.executionLogs {
+		if !inTimeRange(log.CreatedAt, from, to) {
+			continue
+		}
+		if usage := orgOf(log.PromptID); usage != nil {
+			usage.Executions++
+		}
+	}
+	for _, version := range r.s.versions {
+		if usage := orgOf(version.PromptID); usage != nil {
+			usage.StorageBytes += int64(len(version.Body))
+		}
+	}
+	return usages, nil
+}
+
+func (r *usageRepository) StorageReport(ctx context.Context, top int) (*domain.StorageReport, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var tenants []*domain.TenantStorage
+	for _, org := range r.s.organizations {
+		tenants = append(tenants, &domain.TenantStorage{OrganizationID: org.ID, OrganizationName: org.Name})
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		if tenants[i].OrganizationName != tenants[j].OrganizationName {
+			return tenants[i].OrganizationName < tenants[j].OrganizationName
+		}
+		return tenants[i].OrganizationID < tenants[j].OrganizationID
+	})
+
+	var prompts []*domain.PromptStorage
+	byPrompt := make(map[string]*domain.PromptStorage)
+	for _, prompt := range r.s.prompts {
+		workspace, ok := r.s.workspaces[prompt.WorkspaceID]
+		if !ok {
+			continue
+		}
+		usage := &domain.PromptStorage{
+			PromptID:       prompt.ID,
+			PromptName:     prompt.Name,
+			WorkspaceID:    prompt.WorkspaceID,
+			OrganizationID: workspace.OrganizationID,
+			Prompt: domain.StorageUsage{
+				Rows:  1,
+				Bytes: int64(len(prompt.Name) + len(derefString(prompt.Description)) + len(prompt.Tags) + len(derefString(prompt.Body))),
+			},
+		}
+		prompts = append(prompts, usage)
+		byPrompt[prompt.ID] = usage
+	}
+
+	for _, version := range r.s.versions {
+		usage, ok := byPrompt[version.PromptID]
+		if !ok {
+			continue
+		}
+		row := domain.StorageUsage{Rows: 1, Bytes: int64(len(version.Body) + len(version.VariablesSchema) + len(version.Metadata))}
+		usage.Versions.Add(row)
+		// 非激活版本记为历史版本，即版本保留策略可回收的部分。
+		if active := r.s.prompts[version.PromptID].ActiveVersionID; active == nil || *active != version.ID {
+			usage.HistoryVersions.Add(row)
+		}
+	}
+	for _, log := range r.s.executionLogs {
+		if usage, ok := byPrompt[log.PromptID]; ok {
+			usage.ExecutionLogs.Add(domain.StorageUsage{Rows: 1, Bytes: int64(len(log.RequestPayload) + len(log.ResponseMetadata))})
+		}
+	}
+	for _, log := range r.s.promptAudit {
+		if usage, ok := byPrompt[log.PromptID]; ok {
+			usage.AuditLogs.Add(domain.StorageUsage{Rows: 1, Bytes: int64(len(log.Payload))})
+		}
+	}
+	var systemAudit domain.StorageUsage
+	for _, log := range r.s.auditLogs {
+		systemAudit.Add(domain.StorageUsage{Rows: 1, Bytes: int64(len(log.Payload))})
+	}
+
+	return domain.NewStorageReport(tenants, prompts, systemAudit, top), nil
+}
+
+func (r *usageRepository) ListExports(ctx context.Context, exporter, period string) ([]*domain.UsageExport, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var exports []*domain.UsageExport
+	for key, export := range r.s.exports {
+		if key.exporter == exporter && key.period == period {
+			clone := *export
+			exports = append(exports, &clone)
+		}
+	}
+	sort.Slice(exports, func(i, j int) bool {
+		if exports[i].OrganizationID != exports[j].OrganizationID {
+			return exports[i].OrganizationID < exports[j].OrganizationID
+		}
+		return exports[i].Metric < exports[j].Metric
+	})
+	return exports, nil
+}
+
+func (r *usageRepository) SaveExports(ctx context.Context, exports []*domain.UsageExport) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, export := range exports {
+		clone := *export
+		r.s.exports[exportKey{organizationID: export.OrganizationID, period: export.Period, metric: export.Metric, exporter: export.Exporter}] = &clone
+	}
+	return nil
+}
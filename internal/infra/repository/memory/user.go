@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- 用户仓储 ----
+
+type userRepository struct {
+	s *store
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[user.ID]; ok {
+		return uniqueViolation("users.id")
+	}
+	for _, existing := range r.s.users {
+		if existing.Email == user.Email {
+			return uniqueViolation("users.email")
+		}
+	}
+
+	clone := *user
+	if clone.Role == "" {
+		clone.Role = "viewer"
+	}
+	if clone.Status == "" {
+		clone.Status = "active"
+	}
+	now := r.s.now()
+	clone.LastLoginAt = nil
+	clone.CreatedAt, clone.UpdatedAt = now, now
+	r.s.users[clone.ID] = &clone
+	return nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	user, ok := r.s.users[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneUser(user), nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, user := range r.s.users {
+		if user.Email == email {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *userRepository) UpdateStatus(ctx context.Context, userID, status string) error {
+	return r.update(userID, func(user *domain.User) { user.Status = status })
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userID, hashedPassword string) error {
+	return r.update(userID, func(user *domain.User) { user.HashedPassword = hashedPassword })
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, userID, role string) error {
+	return r.update(userID, func(user *domain.User) { user.Role = role })
+}
+
+func (r *userRepository) UpdateLastLogin(ctx context.Context, userID string) error {
+	return r.update(userID, func(user *domain.User) {
+		now := user.UpdatedAt
+		user.LastLoginAt = &now
+	})
+}
+
+// update 在写锁内修改用户并刷新 updated_at，用户不存在时返回 ErrNotFound。
+func (r *userRepository) update(userID string, apply func(*domain.User)) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user.UpdatedAt = r.s.now()
+	apply(user)
+	return nil
+}
+
+func (r *userRepository) ListByStatus(ctx context.Context, status string) ([]*domain.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var users []*domain.User
+	for _, user := range r.s.users {
+		if user.Status == status {
+			users = append(users, cloneUser(user))
+		}
+	}
+	sortByTime(users, func(u *domain.User) time.Time { return u.CreatedAt }, func(u *domain.User) string { return u.ID }, false)
+	return users, nil
+}
+
+func cloneUser(user *domain.User) *domain.User {
+	clone := *user
+	clone.LastLoginAt = cloneTime(user.LastLoginAt)
+	return &clone
+}
+
+// ---- 用户身份仓储 ----
+
+type userIdentityRepository struct {
+	s *store
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.identities[identity.ID]; ok {
+		return uniqueViolation("user_identities.id")
+	}
+	for _, existing := range r.s.identities {
+		if existing.Provider == identity.Provider && existing.ProviderUserID == identity.ProviderUserID {
+			return uniqueViolation("user_identities.provider, user_identities.provider_user_id")
+		}
+	}
+	clone := cloneUserIdentity(identity)
+	now := r.s.now()
+	clone.CreatedAt, clone.UpdatedAt = now, now
+	r.s.identities[clone.ID] = clone
+	return nil
+}
+
+func (r *userIdentityRepository) GetByProviderAndExternalID(ctx context.Context, provider, externalID string) (*domain.UserIdentity, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, identity := range r.s.identities {
+		if identity.Provider == provider && identity.ProviderUserID == externalID {
+			return cloneUserIdentity(identity), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *userIdentityRepository) ListByUser(ctx context.Context, userID string) ([]*domain.UserIdentity, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var identities []*domain.UserIdentity
+	for _, identity := range r.s.identities {
+		if identity.UserID == userID {
+			identities = append(identities, cloneUserIdentity(identity))
+		}
+	}
+	sortByTime(identities, func(i *domain.UserIdentity) time.Time { return i.CreatedAt }, func(i *domain.UserIdentity) string { return i.ID }, false)
+	return identities, nil
+}
+
+func cloneUserIdentity(identity *domain.UserIdentity) *domain.UserIdentity {
+	clone := *identity
+	clone.ProviderLogin = cloneString(identity.ProviderLogin)
+	clone.AvatarURL = cloneString(identity.AvatarURL)
+	return &clone
+}
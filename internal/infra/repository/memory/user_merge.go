@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// 与 SQL 实现一致，返回的统计以表名为键且只包含实际迁移了数据的表；审计日志带哈希链，不做改写。
+
+func (r *userRepository) Merge(ctx context.Context, merge domain.UserReassignment) (map[string]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// 先确认原用户存在，保证失败时不留下部分迁移的数据。
+	from, ok := r.s.users[merge.FromUserID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	moved := r.s.reassignCreatedBy(merge.FromActors, merge.ToActor)
+	r.s.reassignPromptOwner(moved, merge.FromUserID, merge.ToUserID)
+
+	count := func(key string, matched bool) {
+		if matched {
+			moved[key]++
+		}
+	}
+	for _, identity := range r.s.identities {
+		count("user_identities", reassignID(&identity.UserID, merge.FromUserID, merge.ToUserID))
+	}
+	for _, event := range r.s.loginEvents {
+		count("login_events", reassignID(&event.UserID, merge.FromUserID, merge.ToUserID))
+	}
+	for _, key := range r.s.apiKeys {
+		count("api_keys", reassignID(&key.UserID, merge.FromUserID, merge.ToUserID))
+	}
+	for _, log := range r.s.executionLogs {
+		count("prompt_execution_logs", log.UserID != nil && reassignID(log.UserID, merge.FromUserID, merge.ToUserID))
+	}
+	for _, invitation := range r.s.invitations {
+		count("invitations", invitation.AcceptedUserID != nil && reassignID(invitation.AcceptedUserID, merge.FromUserID, merge.ToUserID))
+	}
+
+	// 目标用户已是成员的工作区保留其原角色，其余成员关系整体迁移。
+	now := r.s.now()
+	for key, member := range r.s.members {
+		if key.userID != merge.FromUserID {
+			continue
+		}
+		delete(r.s.members, key)
+		target := memberKey{workspaceID: key.workspaceID, userID: merge.ToUserID}
+		if _, exists := r.s.members[target]; exists {
+			continue
+		}
+		member.UserID = merge.ToUserID
+		member.UpdatedAt = now
+		r.s.members[target] = member
+		moved["workspace_members"]++
+	}
+
+	// 草稿同理：目标用户在同一 Prompt 上已有草稿时保留目标用户的版本。
+	for key, draft := range r.s.drafts {
+		if key.userID != merge.FromUserID {
+			continue
+		}
+		delete(r.s.drafts, key)
+		target := draftKey{promptID: key.promptID, userID: merge.ToUserID}
+		if _, exists := r.s.drafts[target]; exists {
+			continue
+		}
+		draft.UserID = merge.ToUserID
+		r.s.drafts[target] = draft
+		moved["prompt_drafts"]++
+	}
+
+	// 评审批准同理：两人批准过同一版本时只保留目标用户的批准，避免合并后重复计数。
+	for key, approval := range r.s.approvals {
+		if key.reviewerID != merge.FromUserID {
+			continue
+		}
+		delete(r.s.approvals, key)
+		target := approvalKey{versionID: key.versionID, reviewerID: merge.ToUserID}
+		if _, exists := r.s.approvals[target]; exists {
+			continue
+		}
+		approval.ReviewerID = merge.ToUserID
+		r.s.approvals[target] = approval
+		moved["prompt_version_approvals"]++
+	}
+	for _, policy := range r.s.policies {
+		if reviewers, ok := reassignReviewers(policy.Reviewers, merge.FromUserID, merge.ToUserID); ok {
+			policy.Reviewers = reviewers
+			moved["prompt_reviewers"]++
+		}
+	}
+
+	from.Status = merge.FromStatus
+	from.UpdatedAt = now
+	return moved, nil
+}
+
+func (r *userRepository) TransferOwnership(ctx context.Context, transfer domain.UserReassignment) (map[string]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	from, ok := r.s.users[transfer.FromUserID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	moved := r.s.reassignCreatedBy(transfer.FromActors, transfer.ToActor)
+	r.s.reassignPromptOwner(moved, transfer.FromUserID, transfer.ToUserID)
+	from.Status = transfer.FromStatus
+	from.UpdatedAt = r.s.now()
+	return moved, nil
+}
+
+// reassignCreatedBy 把 fromActors 名下的归属列改写为 toActor，返回按表统计的行数；调用方需持有写锁。
+func (s *store) reassignCreatedBy(fromActors []string, toActor string) map[string]int64 {
+	moved := make(map[string]int64)
+	if len(fromActors) == 0 {
+		return moved
+	}
+	actors := make(map[string]bool, len(fromActors))
+	for _, actor := range fromActors {
+		actors[actor] = true
+	}
+	rewrite := func(table string, column *string) {
+		if column != nil && actors[*column] {
+			*column = toActor
+			moved[table]++
+		}
+	}
+
+	for _, prompt := range s.prompts {
+		rewrite("prompts", prompt.CreatedBy)
+	}
+	for _, version := range s.versions {
+		rewrite("prompt_versions", version.CreatedBy)
+	}
+	for _, locale := range s.locales {
+		rewrite("prompt_version_locales", locale.CreatedBy)
+	}
+	for _, dependency := range s.dependencies {
+		rewrite("prompt_dependencies", dependency.createdBy)
+	}
+	for _, pipeline := range s.pipelines {
+		rewrite("pipelines", pipeline.CreatedBy)
+	}
+	for _, version := range s.pipelineVers {
+		rewrite("pipeline_versions", version.CreatedBy)
+	}
+	for _, org := range s.organizations {
+		rewrite("organizations", org.CreatedBy)
+	}
+	for _, workspace := range s.workspaces {
+		rewrite("workspaces", workspace.CreatedBy)
+	}
+	for _, invitation := range s.invitations {
+		rewrite("invitations", invitation.InvitedBy)
+	}
+	for _, rule := range s.alertRules {
+		rewrite("prompt_alert_rules", rule.CreatedBy)
+	}
+	for _, key := range s.apiKeys {
+		rewrite("api_keys", key.CreatedBy)
+	}
+	for _, template := range s.templates {
+		rewrite("prompt_templates", template.CreatedBy)
+	}
+	for _, policy := range s.policies {
+		rewrite("prompt_review_policies", policy.UpdatedBy)
+	}
+	for _, approval := range s.approvals {
+		rewrite("prompt_version_approvals", approval.Reviewer)
+	}
+	return moved
+}
+
+// reassignPromptOwner 把负责人为 fromUserID 的 Prompt 转交给 toUserID，团队负责人不受影响。
+func (s *store) reassignPromptOwner(moved map[string]int64, fromUserID, toUserID string) {
+	for _, prompt := range s.prompts {
+		if prompt.Owner != nil && prompt.Owner.Type == domain.PromptOwnerUser && prompt.Owner.ID == fromUserID {
+			prompt.Owner = &domain.PromptOwner{Type: domain.PromptOwnerUser, ID: toUserID}
+			moved["prompt_owners"]++
+		}
+	}
+}
+
+// reassignReviewers 返回把用户评审人 from 替换为 to 后的列表，列表中没有 from 时返回 false。
+func reassignReviewers(reviewers []domain.PromptOwner, from, to string) ([]domain.PromptOwner, bool) {
+	source := domain.PromptOwner{Type: domain.PromptOwnerUser, ID: from}
+	target := domain.PromptOwner{Type: domain.PromptOwnerUser, ID: to}
+	if !slices.Contains(reviewers, source) {
+		return reviewers, false
+	}
+	rewritten := make([]domain.PromptOwner, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if reviewer == source {
+			reviewer = target
+		}
+		if !slices.Contains(rewritten, reviewer) {
+			rewritten = append(rewritten, reviewer)
+		}
+	}
+	return rewritten, true
+}
+
+// reassignID 在 *id 等于 from 时改写为 to 并返回 true。
+func reassignID(id *string, from, to string) bool {
+	if *id != from {
+		return false
+	}
+	*id = to
+	return true
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// ---- 组织与工作区仓储 ----
+
+type memberKey struct {
+	workspaceID string
+	userID      string
+}
+
+type workspaceRepository struct {
+	s *store
+}
+
+func (r *workspaceRepository) CreateOrganization(ctx context.Context, org *domain.Organization) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.organizations[org.ID]; ok {
+		return uniqueViolation("organizations.id")
+	}
+	for _, existing := range r.s.organizations {
+		if existing.Slug == org.Slug {
+			return uniqueViolation("organizations.slug")
+		}
+	}
+	stored := cloneOrganization(org)
+	now := r.s.now()
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	r.s.organizations[stored.ID] = stored
+	return nil
+}
+
+func (r *workspaceRepository) GetOrganization(ctx context.Context, orgID string) (*domain.Organization, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	org, ok := r.s.organizations[orgID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneOrganization(org), nil
+}
+
+func (r *workspaceRepository) ListOrganizations(ctx context.Context) ([]*domain.Organization, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var orgs []*domain.Organization
+	for _, org := range r.s.organizations {
+		orgs = append(orgs, cloneOrganization(org))
+	}
+	sortByTime(orgs, func(o *domain.Organization) time.Time { return o.CreatedAt }, func(o *domain.Organization) string { return o.Name }, false)
+	return orgs, nil
+}
+
+func (r *workspaceRepository) CreateWorkspace(ctx context.Context, workspace *domain.Workspace, owner *domain.WorkspaceMember) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.workspaces[workspace.ID]; ok {
+		return uniqueViolation("workspaces.id")
+	}
+	for _, existing := range r.s.workspaces {
+		if existing.OrganizationID == workspace.OrganizationID && existing.Slug == workspace.Slug {
+			return uniqueViolation("workspaces.organization_id, workspaces.slug")
+		}
+	}
+	stored := cloneWorkspace(workspace)
+	stored.Role = ""
+	now := r.s.now()
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	r.s.workspaces[stored.ID] = stored
+	if owner != nil {
+		r.s.members[memberKey{workspaceID: stored.ID, userID: owner.UserID}] = &domain.WorkspaceMember{
+			WorkspaceID: stored.ID, UserID: owner.UserID, Role: owner.Role, CreatedAt: now, UpdatedAt: now,
+		}
+	}
+	return nil
+}
+
+func (r *workspaceRepository) GetWorkspace(ctx context.Context, workspaceID string) (*domain.Workspace, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	workspace, ok := r.s.workspaces[workspaceID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneWorkspace(workspace), nil
+}
+
+func (r *workspaceRepository) ListWorkspaces(ctx context.Context, orgID string) ([]*domain.Workspace, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var workspaces []*domain.Workspace
+	for _, workspace := range r.s.workspaces {
+		if orgID == "" || workspace.OrganizationID == orgID {
+			workspaces = append(workspaces, cloneWorkspace(workspace))
+		}
+	}
+	sortWorkspaces(workspaces)
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) ListWorkspacesForUser(ctx context.Context, userID string) ([]*domain.Workspace, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var workspaces []*domain.Workspace
+	for key, member := range r.s.members {
+		if key.userID != userID {
+			continue
+		}
+		if workspace, ok := r.s.workspaces[key.workspaceID]; ok {
+			clone := cloneWorkspace(workspace)
+			clone.Role = member.Role
+			workspaces = append(workspaces, clone)
+		}
+	}
+	sortWorkspaces(workspaces)
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) UpsertMember(ctx context.Context, member *domain.WorkspaceMember) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := memberKey{workspaceID: member.WorkspaceID, userID: member.UserID}
+	now := r.s.now()
+	if existing, ok := r.s.members[key]; ok {
+		existing.Role = member.Role
+		existing.UpdatedAt = now
+		return nil
+	}
+	r.s.members[key] = &domain.WorkspaceMember{
+		WorkspaceID: member.WorkspaceID, UserID: member.UserID, Role: member.Role, CreatedAt: now, UpdatedAt: now,
+	}
+	return nil
+}
+
+func (r *workspaceRepository) GetMember(ctx context.Context, workspaceID, userID string) (*domain.WorkspaceMember, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	member, ok := r.s.members[memberKey{workspaceID: workspaceID, userID: userID}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return r.s.memberView(member), nil
+}
+
+func (r *workspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]*domain.WorkspaceMember, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var members []*domain.WorkspaceMember
+	for key, member := range r.s.members {
+		if key.workspaceID == workspaceID {
+			members = append(members, r.s.memberView(member))
+		}
+	}
+	sortByTime(members, func(m *domain.WorkspaceMember) time.Time { return m.CreatedAt }, func(m *domain.WorkspaceMember) string { return m.UserID }, false)
+	return members, nil
+}
+
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := memberKey{workspaceID: workspaceID, userID: userID}
+	if _, ok := r.s.members[key]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.members, key)
+	return nil
+}
+
+// memberView 返回成员副本，并与 SQL 实现关联 users 表一致填充邮箱。
+func (s *store) memberView(member *domain.WorkspaceMember) *domain.WorkspaceMember {
+	clone := *member
+	clone.Email = ""
+	if user, ok := s.users[member.UserID]; ok {
+		clone.Email = user.Email
+	}
+	return &clone
+}
+
+func sortWorkspaces(workspaces []*domain.Workspace) {
+	sortByTime(workspaces, func(w *domain.Workspace) time.Time { return w.CreatedAt }, func(w *domain.Workspace) string { return w.Name }, false)
+}
+
+func cloneOrganization(org *domain.Organization) *domain.Organization {
+	clone := *org
+	clone.CreatedBy = cloneString(org.CreatedBy)
+	return &clone
+}
+
+func cloneWorkspace(workspace *domain.Workspace) *domain.Workspace {
+	clone := *workspace
+	clone.CreatedBy = cloneString(workspace.CreatedBy)
+	return &clone
+}
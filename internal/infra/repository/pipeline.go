@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- Pipeline 仓储 ----
+
+const pipelineColumns = `p.id, p.name, p.description, p.latest_version, p.workspace_id, p.created_by, p.created_at, p.updated_at`
+
+type pipelineRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+type pipelineRow struct {
+	id            string
+	name          string
+	description   sql.NullString
+	latestVersion int
+	workspaceID   string
+	createdBy     sql.NullString
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+func (row pipelineRow) toDomain() *domain.Pipeline {
+	pipeline := &domain.Pipeline{
+		ID:            row.id,
+		Name:          row.name,
+		LatestVersion: row.latestVersion,
+		WorkspaceID:   row.workspaceID,
+		CreatedAt:     row.createdAt,
+		UpdatedAt:     row.updatedAt,
+	}
+	if row.description.Valid {
+		pipeline.Description = &row.description.String
+	}
+	if row.createdBy.Valid {
+		pipeline.CreatedBy = &row.createdBy.String
+	}
+	return pipeline
+}
+
+type pipelineVersionRow struct {
+	id            string
+	pipelineID    string
+	versionNumber int
+	steps         string
+	createdBy     sql.NullString
+	createdAt     time.Time
+}
+
+func (row pipelineVersionRow) toDomain() *domain.PipelineVersion {
+	version := &domain.PipelineVersion{
+		ID:            row.id,
+		PipelineID:    row.pipelineID,
+		VersionNumber: row.versionNumber,
+		Steps:         json.RawMessage(row.steps),
+		CreatedAt:     row.createdAt,
+	}
+	if row.createdBy.Valid {
+		version.CreatedBy = &row.createdBy.String
+	}
+	return version
+}
+
+func (r *pipelineRepository) Create(ctx context.Context, pipeline *domain.Pipeline) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO pipelines (id, name, description, created_by, workspace_id)
+VALUES (%s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	description := sql.NullString{}
+	if pipeline.Description != nil {
+		description = sql.NullString{String: *pipeline.Description, Valid: true}
+	}
+	createdBy := sql.NullString{}
+	if pipeline.CreatedBy != nil {
+		createdBy = sql.NullString{String: *pipeline.CreatedBy, Valid: true}
+	}
+
+	if pipeline.WorkspaceID == "" {
+		pipeline.WorkspaceID = domain.WorkspaceFromContext(ctx)
+	}
+	if pipeline.WorkspaceID == "" {
+		pipeline.WorkspaceID = domain.DefaultWorkspaceID
+	}
+
+	_, err := r.db.ExecContext(ctx, query, pipeline.ID, pipeline.Name, description, createdBy, pipeline.WorkspaceID)
+	return err
+}
+
+func (r *pipelineRepository) GetByID(ctx context.Context, pipelineID string) (*domain.Pipeline, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT `+pipelineColumns+`
+FROM pipelines p WHERE p.id = %s`, ph.Next())
+	args := []interface{}{pipelineID}
+	query, args = scopeToWorkspace(ctx, ph, query, args)
+
+	var row pipelineRow
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&row.id, &row.name, &row.description, &row.latestVersion, &row.workspaceID, &row.createdBy, &row.createdAt, &row.updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return row.toDomain(), nil
+}
+
+func (r *pipelineRepository) List(ctx context.Context, limit, offset int) ([]*domain.Pipeline, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query, args := scopeToWorkspace(ctx, ph, `SELECT `+pipelineColumns+`
+FROM pipelines p WHERE 1 = 1`, nil)
+	query += fmt.Sprintf(" ORDER BY p.updated_at DESC LIMIT %s OFFSET %s", ph.Next(), ph.Next())
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pipelines []*domain.Pipeline
+	for rows.Next() {
+		var row pipelineRow
+		if err := rows.Scan(&row.id, &row.name, &row.description, &row.latestVersion, &row.workspaceID, &row.createdBy, &row.createdAt, &row.updatedAt); err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, row.toDomain())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
+func (r *pipelineRepository) Count(ctx context.Context) (int64, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query, args := scopeToWorkspace(ctx, ph, `SELECT COUNT(1) FROM pipelines p WHERE 1 = 1`, nil)
+	var total int64
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *pipelineRepository) CreateVersion(ctx context.Context, version *domain.PipelineVersion) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	insertQuery := fmt.Sprintf(`INSERT INTO pipeline_versions (id, pipeline_id, version_number, steps, created_by)
+VALUES (%s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	createdBy := sql.NullString{}
+	if version.CreatedBy != nil {
+		createdBy = sql.NullString{String: *version.CreatedBy, Valid: true}
+	}
+	if _, err = tx.ExecContext(ctx, insertQuery, version.ID, version.PipelineID, version.VersionNumber, string(version.Steps), createdBy); err != nil {
+		return err
+	}
+
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	updateQuery := fmt.Sprintf(`UPDATE pipelines SET latest_version = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s`, ph.Next(), ph.Next())
+	result, err := tx.ExecContext(ctx, updateQuery, version.VersionNumber, version.PipelineID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		err = domain.ErrNotFound
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *pipelineRepository) GetVersion(ctx context.Context, pipelineID string, versionNumber int) (*domain.PipelineVersion, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, pipeline_id, version_number, steps, created_by, created_at
+FROM pipeline_versions WHERE pipeline_id = %s AND version_number = %s`, ph.Next(), ph.Next())
+
+	var row pipelineVersionRow
+	err := r.db.QueryRowContext(ctx, query, pipelineID, versionNumber).Scan(&row.id, &row.pipelineID, &row.versionNumber, &row.steps, &row.createdBy, &row.createdAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return row.toDomain(), nil
+}
+
+func (r *pipelineRepository) ListVersions(ctx context.Context, pipelineID string) ([]*domain.PipelineVersion, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, pipeline_id, version_number, steps, created_by, created_at
+FROM pipeline_versions WHERE pipeline_id = %s ORDER BY version_number DESC`, ph.Next())
+
+	rows, err := r.db.QueryContext(ctx, query, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*domain.PipelineVersion
+	for rows.Next() {
+		var row pipelineVersionRow
+		if err := rows.Scan(&row.id, &row.pipelineID, &row.versionNumber, &row.steps, &row.createdBy, &row.createdAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, row.toDomain())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
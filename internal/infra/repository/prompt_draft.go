@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- Prompt 草稿仓储 ----
+
+type promptDraftRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const promptDraftColumns = `prompt_id, user_id, body, variables_schema, metadata, base_version_id, revision, created_at, updated_at`
+
+func (r *promptDraftRepository) Get(ctx context.Context, promptID, userID string) (*domain.PromptDraft, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM prompt_drafts WHERE prompt_id = %s AND user_id = %s`, promptDraftColumns, ph.Next(), ph.Next())
+	var (
+		draft                     domain.PromptDraft
+		variablesSchema, metadata sql.NullString
+		baseVersionID             sql.NullString
+	)
+	err := r.db.QueryRowContext(ctx, query, promptID, userID).Scan(&draft.PromptID, &draft.UserID, &draft.Body,
+		&variablesSchema, &metadata, &baseVersionID, &draft.Revision, &draft.CreatedAt, &draft.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if variablesSchema.Valid {
+		draft.VariablesSchema = []byte(variablesSchema.String)
+	}
+	if metadata.Valid {
+		draft.Metadata = []byte(metadata.String)
+	}
+	draft.BaseVersionID = stringPtr(baseVersionID)
+	return &draft, nil
+}
+
+func (r *promptDraftRepository) Save(ctx context.Context, draft *domain.PromptDraft, expectedRevision *int) error {
+	now := time.Now().UTC()
+	ph := database.NewPlaceholderBuilder(r.dialect)
+
+	var (
+		query string
+		args  []interface{}
+	)
+	values := []interface{}{
+		draft.Body, nullableJSON(draft.VariablesSchema), nullableJSON(draft.Metadata), nullableString(draft.BaseVersionID),
+	}
+	switch {
+	case expectedRevision != nil && *expectedRevision > 0:
+		query = fmt.Sprintf(`UPDATE prompt_drafts SET body = %s, variables_schema = %s, metadata = %s, base_version_id = %s,
+revision = revision + 1, updated_at = %s WHERE prompt_id = %s AND user_id = %s AND revision = %s`,
+			ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+		args = append(values, now, draft.PromptID, draft.UserID, *expectedRevision)
+	default:
+		conflict := `DO UPDATE SET body = excluded.body, variables_schema = excluded.variables_schema, metadata = excluded.metadata,
+base_version_id = excluded.base_version_id, revision = prompt_drafts.revision + 1, updated_at = excluded.updated_at`
+		if expectedRevision != nil {
+			conflict = `DO NOTHING`
+		}
+		query = fmt.Sprintf(`INSERT INTO prompt_drafts (%s) VALUES (%s, %s, %s, %s, %s, %s, 1, %s, %s)
+ON CONFLICT (prompt_id, user_id) %s`, promptDraftColumns,
+			ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), conflict)
+		args = append([]interface{}{draft.PromptID, draft.UserID}, values...)
+		args = append(args, now, now)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrRevisionConflict
+	}
+	return nil
+}
+
+func (r *promptDraftRepository) Delete(ctx context.Context, promptID, userID string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM prompt_drafts WHERE prompt_id = %s AND user_id = %s`, ph.Next(), ph.Next()), promptID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
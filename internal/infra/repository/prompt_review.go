@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- Prompt 评审仓储 ----
+
+type promptReviewRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func (r *promptReviewRepository) GetPolicy(ctx context.Context, promptID string) (*domain.PromptReviewPolicy, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT prompt_id, required_approvals, reviewers, updated_by, created_at, updated_at
+FROM prompt_review_policies WHERE prompt_id = %s`, ph.Next())
+	var (
+		policy    domain.PromptReviewPolicy
+		reviewers sql.NullString
+		updatedBy sql.NullString
+	)
+	err := r.db.QueryRowContext(ctx, query, promptID).Scan(&policy.PromptID, &policy.RequiredApprovals, &reviewers, &updatedBy, &policy.CreatedAt, &policy.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reviewers.Valid && reviewers.String != "" {
+		if err := json.Unmarshal([]byte(reviewers.String), &policy.Reviewers); err != nil {
+			return nil, err
+		}
+	}
+	policy.UpdatedBy = stringPtr(updatedBy)
+	return &policy, nil
+}
+
+func (r *promptReviewRepository) SavePolicy(ctx context.Context, policy *domain.PromptReviewPolicy) error {
+	reviewers, err := json.Marshal(policy.Reviewers)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO prompt_review_policies (prompt_id, required_approvals, reviewers, updated_by, created_at, updated_at)
+VALUES (%s, %s, %s, %s, %s, %s)
+ON CONFLICT (prompt_id) DO UPDATE SET required_approvals = excluded.required_approvals, reviewers = excluded.reviewers,
+updated_by = excluded.updated_by, updated_at = excluded.updated_at`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err = r.db.ExecContext(ctx, query, policy.PromptID, policy.RequiredApprovals, string(reviewers), nullableString(policy.UpdatedBy), now, now)
+	return err
+}
+
+func (r *promptReviewRepository) DeletePolicy(ctx context.Context, promptID string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM prompt_review_policies WHERE prompt_id = %s`, ph.Next()), promptID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptReviewRepository) Approve(ctx context.Context, approval *domain.PromptVersionApproval) error {
+	if approval.CreatedAt.IsZero() {
+		approval.CreatedAt = time.Now().UTC()
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO prompt_version_approvals (version_id, prompt_id, reviewer_id, reviewer, comment, created_at)
+VALUES (%s, %s, %s, %s, %s, %s)
+ON CONFLICT (version_id, reviewer_id) DO UPDATE SET reviewer = excluded.reviewer, comment = excluded.comment, created_at = excluded.created_at`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query, approval.VersionID, approval.PromptID, approval.ReviewerID,
+		nullableString(approval.Reviewer), nullableString(approval.Comment), approval.CreatedAt)
+	return err
+}
+
+func (r *promptReviewRepository) RevokeApproval(ctx context.Context, versionID, reviewerID string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM prompt_version_approvals WHERE version_id = %s AND reviewer_id = %s`, ph.Next(), ph.Next()), versionID, reviewerID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptReviewRepository) ListApprovals(ctx context.Context, versionID string) ([]*domain.PromptVersionApproval, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT version_id, prompt_id, reviewer_id, reviewer, comment, created_at
+FROM prompt_version_approvals WHERE version_id = %s ORDER BY created_at ASC`, ph.Next())
+	rows, err := r.db.QueryContext(ctx, query, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []*domain.PromptVersionApproval
+	for rows.Next() {
+		var (
+			approval          domain.PromptVersionApproval
+			reviewer, comment sql.NullString
+		)
+		if err := rows.Scan(&approval.VersionID, &approval.PromptID, &approval.ReviewerID, &reviewer, &comment, &approval.CreatedAt); err != nil {
+			return nil, err
+		}
+		approval.Reviewer = stringPtr(reviewer)
+		approval.Comment = stringPtr(comment)
+		approvals = append(approvals, &approval)
+	}
+	return approvals, rows.Err()
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- Prompt 脚手架模板仓储 ----
+
+type promptTemplateRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const promptTemplateColumns = `id, slug, name, description, body, variables_schema, metadata, tags, created_by, created_at, updated_at`
+
+func (r *promptTemplateRepository) Create(ctx context.Context, template *domain.PromptTemplate) error {
+	now := time.Now().UTC()
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = now
+	}
+	template.UpdatedAt = template.CreatedAt
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO prompt_templates (%s) VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`, promptTemplateColumns,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query,
+		template.ID, template.Slug, template.Name, nullableString(template.Description), template.Body,
+		nullableJSON(template.VariablesSchema), nullableJSON(template.Metadata), nullableJSON(template.Tags),
+		nullableString(template.CreatedBy), template.CreatedAt.UTC(), template.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+func (r *promptTemplateRepository) GetBySlug(ctx context.Context, slug string) (*domain.PromptTemplate, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM prompt_templates WHERE slug = %s`, promptTemplateColumns, ph.Next())
+	template, err := scanPromptTemplate(r.db.QueryRowContext(ctx, query, slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return template, err
+}
+
+func (r *promptTemplateRepository) List(ctx context.Context) ([]*domain.PromptTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM prompt_templates ORDER BY slug`, promptTemplateColumns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*domain.PromptTemplate
+	for rows.Next() {
+		template, err := scanPromptTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *promptTemplateRepository) Update(ctx context.Context, template *domain.PromptTemplate) error {
+	template.UpdatedAt = time.Now().UTC()
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE prompt_templates SET name = %s, description = %s, body = %s, variables_schema = %s, metadata = %s, tags = %s, updated_at = %s WHERE slug = %s`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query,
+		template.Name, nullableString(template.Description), template.Body,
+		nullableJSON(template.VariablesSchema), nullableJSON(template.Metadata), nullableJSON(template.Tags),
+		template.UpdatedAt, template.Slug,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptTemplateRepository) Delete(ctx context.Context, slug string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM prompt_templates WHERE slug = %s`, ph.Next()), slug)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanPromptTemplate(row rowScanner) (*domain.PromptTemplate, error) {
+	var (
+		template                             domain.PromptTemplate
+		description, createdBy               sql.NullString
+		variablesSchema, metadata, tagsValue sql.NullString
+	)
+	if err := row.Scan(&template.ID, &template.Slug, &template.Name, &description, &template.Body,
+		&variablesSchema, &metadata, &tagsValue, &createdBy, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		return nil, err
+	}
+	template.Description = stringPtr(description)
+	template.CreatedBy = stringPtr(createdBy)
+	if variablesSchema.Valid {
+		template.VariablesSchema = json.RawMessage(variablesSchema.String)
+	}
+	if metadata.Valid {
+		template.Metadata = json.RawMessage(metadata.String)
+	}
+	if tagsValue.Valid {
+		template.Tags = json.RawMessage(tagsValue.String)
+	}
+	return &template, nil
+}
+
+// nullableJSON 把空 JSON 映射为 NULL。
+func nullableJSON(value json.RawMessage) sql.NullString {
+	if len(value) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(value), Valid: true}
+}
@@ -0,0 +1,222 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testPipelines(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+
+	pipeline := &domain.Pipeline{ID: newID("pipeline"), Name: "summarize-then-translate", Description: ptr("two steps"), CreatedBy: ptr("author")}
+	must(t, repos.Pipelines.Create(ctx, pipeline), "create pipeline")
+	expectError(t, repos.Pipelines.Create(ctx, &domain.Pipeline{ID: newID("pipeline"), Name: pipeline.Name}), "create duplicate pipeline name")
+	must(t, repos.Pipelines.Create(ctx, &domain.Pipeline{ID: newID("pipeline"), Name: "second"}), "create second pipeline")
+
+	stored, err := repos.Pipelines.GetByID(ctx, pipeline.ID)
+	must(t, err, "get pipeline")
+	if stored.Name != pipeline.Name || stored.LatestVersion != 0 || stored.Description == nil {
+		t.Fatalf("unexpected pipeline %+v", stored)
+	}
+	_, err = repos.Pipelines.GetByID(ctx, "missing")
+	expectNotFound(t, err, "get missing pipeline")
+
+	total, err := repos.Pipelines.Count(ctx)
+	must(t, err, "count pipelines")
+	page, err := repos.Pipelines.List(ctx, 1, 1)
+	must(t, err, "list pipelines")
+	if total != 2 || len(page) != 1 {
+		t.Fatalf("expected 2 pipelines and a page of 1, got %d and %d", total, len(page))
+	}
+
+	steps := json.RawMessage(`[{"id":"a","prompt":"p1"}]`)
+	for number := 1; number <= 2; number++ {
+		version := &domain.PipelineVersion{ID: newID("pipeline-version"), PipelineID: pipeline.ID, VersionNumber: number, Steps: steps, CreatedBy: ptr("author")}
+		must(t, repos.Pipelines.CreateVersion(ctx, version), "create pipeline version")
+	}
+	expectError(t, repos.Pipelines.CreateVersion(ctx, &domain.PipelineVersion{ID: newID("pipeline-version"), PipelineID: pipeline.ID, VersionNumber: 2, Steps: steps}), "create duplicate pipeline version")
+	expectError(t, repos.Pipelines.CreateVersion(ctx, &domain.PipelineVersion{ID: newID("pipeline-version"), PipelineID: "missing", VersionNumber: 1, Steps: steps}), "create version of missing pipeline")
+
+	stored, err = repos.Pipelines.GetByID(ctx, pipeline.ID)
+	must(t, err, "reload pipeline")
+	if stored.LatestVersion != 2 {
+		t.Fatalf("expected latest version 2, got %d", stored.LatestVersion)
+	}
+	version, err := repos.Pipelines.GetVersion(ctx, pipeline.ID, 1)
+	must(t, err, "get pipeline version")
+	if version.VersionNumber != 1 || string(version.Steps) != string(steps) {
+		t.Fatalf("unexpected pipeline version %+v", version)
+	}
+	_, err = repos.Pipelines.GetVersion(ctx, pipeline.ID, 3)
+	expectNotFound(t, err, "get missing pipeline version")
+	versions, err := repos.Pipelines.ListVersions(ctx, pipeline.ID)
+	must(t, err, "list pipeline versions")
+	if len(versions) != 2 || versions[0].VersionNumber != 2 {
+		t.Fatalf("expected versions in descending order, got %d", len(versions))
+	}
+
+	// 携带工作区的上下文只能看到本工作区的 Pipeline，未指定工作区的记录归入默认工作区。
+	if stored.WorkspaceID != domain.DefaultWorkspaceID {
+		t.Fatalf("expected pipeline in default workspace, got %q", stored.WorkspaceID)
+	}
+	wsCtx := domain.WithWorkspace(ctx, newID("ws"))
+	scoped := &domain.Pipeline{ID: newID("pipeline"), Name: "workspace-pipeline"}
+	must(t, repos.Pipelines.Create(wsCtx, scoped), "create workspace pipeline")
+	if scoped.WorkspaceID != domain.WorkspaceFromContext(wsCtx) {
+		t.Fatalf("expected pipeline to inherit context workspace, got %q", scoped.WorkspaceID)
+	}
+	_, err = repos.Pipelines.GetByID(wsCtx, pipeline.ID)
+	expectNotFound(t, err, "get pipeline from another workspace")
+	if _, err := repos.Pipelines.GetByID(wsCtx, scoped.ID); err != nil {
+		t.Fatalf("get workspace pipeline: %v", err)
+	}
+	wsTotal, err := repos.Pipelines.Count(wsCtx)
+	must(t, err, "count workspace pipelines")
+	wsPage, err := repos.Pipelines.List(wsCtx, 10, 0)
+	must(t, err, "list workspace pipelines")
+	if wsTotal != 1 || len(wsPage) != 1 || wsPage[0].ID != scoped.ID {
+		t.Fatalf("expected only the workspace pipeline, got %d and %d", wsTotal, len(wsPage))
+	}
+	defaultTotal, err := repos.Pipelines.Count(domain.WithWorkspace(ctx, domain.DefaultWorkspaceID))
+	must(t, err, "count default workspace pipelines")
+	if defaultTotal != 2 {
+		t.Fatalf("expected 2 pipelines in default workspace, got %d", defaultTotal)
+	}
+}
+
+func testSigningKeys(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	usable, err := repos.SigningKeys.ListUsable(ctx, now)
+	must(t, err, "list usable keys")
+	if len(usable) != 0 {
+		t.Fatalf("expected no keys, got %d", len(usable))
+	}
+
+	first := &domain.SigningKey{ID: newID("kid"), Algorithm: "EdDSA", EncryptedKey: "enc-1", CreatedBy: ptr("admin")}
+	must(t, repos.SigningKeys.Rotate(ctx, first, now.Add(time.Hour)), "first rotation")
+	second := &domain.SigningKey{ID: newID("kid"), Algorithm: "EdDSA", EncryptedKey: "enc-2"}
+	must(t, repos.SigningKeys.Rotate(ctx, second, now.Add(time.Hour)), "second rotation")
+
+	// 轮换后旧密钥在 retiresAt 之前仍可用于验签。
+	usable, err = repos.SigningKeys.ListUsable(ctx, now)
+	must(t, err, "list usable keys after rotation")
+	if len(usable) != 2 {
+		t.Fatalf("expected active and retiring keys, got %d", len(usable))
+	}
+	statuses := map[string]string{}
+	for _, key := range usable {
+		statuses[key.ID] = key.Status
+	}
+	if statuses[first.ID] != "retired" || statuses[second.ID] != "active" {
+		t.Fatalf("unexpected key statuses %v", statuses)
+	}
+
+	usable, err = repos.SigningKeys.ListUsable(ctx, now.Add(2*time.Hour))
+	must(t, err, "list usable keys after retirement")
+	if len(usable) != 1 || usable[0].ID != second.ID || usable[0].EncryptedKey != "enc-2" || usable[0].OrganizationID != domain.DefaultOrganizationID {
+		t.Fatalf("expected only the active key, got %+v", usable)
+	}
+
+	// 各组织独立轮换，不影响其他组织的当前密钥。
+	orgKey := &domain.SigningKey{ID: newID("kid"), OrganizationID: "org-acme", Algorithm: "EdDSA", EncryptedKey: "enc-acme"}
+	must(t, repos.SigningKeys.Rotate(ctx, orgKey, now.Add(time.Hour)), "organization rotation")
+	usable, err = repos.SigningKeys.ListUsable(ctx, now.Add(2*time.Hour))
+	must(t, err, "list usable keys across organizations")
+	statuses = map[string]string{}
+	for _, key := range usable {
+		statuses[key.ID] = key.OrganizationID + "/" + key.Status
+	}
+	if len(usable) != 2 || statuses[second.ID] != domain.DefaultOrganizationID+"/active" || statuses[orgKey.ID] != "org-acme/active" {
+		t.Fatalf("expected one active key per organization, got %v", statuses)
+	}
+}
+
+func testLoginEvents(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	user := seedUser(t, repos, "login@example.com")
+
+	for i, device := range []string{"laptop", "phone", "laptop"} {
+		event := &domain.LoginEvent{
+			ID:         newID("login"),
+			UserID:     user.ID,
+			EventType:  "login",
+			Provider:   "password",
+			IPAddress:  ptr("10.0.0.1"),
+			UserAgent:  ptr(device),
+			DeviceHash: device,
+			NewDevice:  i < 2,
+			CreatedAt:  minute(i),
+		}
+		must(t, repos.LoginEvents.Create(ctx, event), "create login event")
+	}
+
+	events, err := repos.LoginEvents.ListByUser(ctx, user.ID, 2)
+	must(t, err, "list login events")
+	if len(events) != 2 || !events[0].CreatedAt.Equal(minute(2)) || events[0].NewDevice || !events[1].NewDevice {
+		t.Fatalf("expected newest events first, got %+v", events)
+	}
+	if events[0].IPAddress == nil || events[0].UserAgent == nil || *events[0].UserAgent != "laptop" {
+		t.Fatalf("event details not stored: %+v", events[0])
+	}
+
+	seen, err := repos.LoginEvents.HasDevice(ctx, user.ID, "phone")
+	must(t, err, "has known device")
+	unseen, err := repos.LoginEvents.HasDevice(ctx, user.ID, "tablet")
+	must(t, err, "has unknown device")
+	if !seen || unseen {
+		t.Fatalf("expected phone known and tablet unknown, got %v and %v", seen, unseen)
+	}
+}
+
+func testAPIKeys(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	user := seedUser(t, repos, "machine@example.com")
+
+	key := &domain.APIKey{
+		ID:         newID("key"),
+		UserID:     user.ID,
+		Name:       "ci",
+		Prefix:     "pm_ci",
+		KeyHash:    "hash-ci",
+		Scopes:     []string{domain.APIKeyScopeRead, domain.APIKeyScopeExecute},
+		RateLimit:  600,
+		DailyQuota: 5000,
+		CreatedBy:  ptr(user.Email),
+		CreatedAt:  minute(0),
+	}
+	must(t, repos.APIKeys.Create(ctx, key), "create api key")
+	expectError(t, repos.APIKeys.Create(ctx, &domain.APIKey{ID: newID("key"), UserID: user.ID, Name: "dup", Prefix: "pm_dup", KeyHash: "hash-ci", Scopes: []string{domain.APIKeyScopeRead}, CreatedAt: minute(1)}), "create duplicate key hash")
+	must(t, repos.APIKeys.Create(ctx, &domain.APIKey{ID: newID("key"), UserID: user.ID, Name: "cron", Prefix: "pm_cron", KeyHash: "hash-cron", Scopes: []string{domain.APIKeyScopeAdmin}, CreatedAt: minute(1)}), "create second api key")
+
+	stored, err := repos.APIKeys.GetByHash(ctx, "hash-ci")
+	must(t, err, "get api key by hash")
+	if stored.ID != key.ID || len(stored.Scopes) != 2 || stored.Scopes[1] != domain.APIKeyScopeExecute || stored.RateLimit != 600 || stored.DailyQuota != 5000 || stored.RevokedAt != nil {
+		t.Fatalf("unexpected api key %+v", stored)
+	}
+	_, err = repos.APIKeys.GetByHash(ctx, "missing")
+	expectNotFound(t, err, "get missing key hash")
+	_, err = repos.APIKeys.GetByID(ctx, "missing")
+	expectNotFound(t, err, "get missing key id")
+
+	keys, err := repos.APIKeys.ListByUser(ctx, user.ID)
+	must(t, err, "list api keys")
+	if len(keys) != 2 || keys[0].Name != "cron" {
+		t.Fatalf("expected newest key first, got %d", len(keys))
+	}
+
+	must(t, repos.APIKeys.TouchLastUsed(ctx, key.ID, minute(5)), "touch last used")
+	must(t, repos.APIKeys.Revoke(ctx, key.ID, minute(10)), "revoke api key")
+	expectNotFound(t, repos.APIKeys.Revoke(ctx, key.ID, minute(11)), "revoke api key twice")
+	expectNotFound(t, repos.APIKeys.Revoke(ctx, "missing", minute(11)), "revoke missing api key")
+	stored, err = repos.APIKeys.GetByID(ctx, key.ID)
+	must(t, err, "reload api key")
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(minute(5)) || stored.RevokedAt == nil || !stored.RevokedAt.Equal(minute(10)) {
+		t.Fatalf("usage or revocation not recorded: %+v", stored)
+	}
+}
@@ -0,0 +1,137 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testPromptAuditLogs(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	first := seedPrompt(t, repos, "audited")
+	second := seedPrompt(t, repos, "audited-too")
+
+	entries := []*domain.PromptAuditLog{
+		{ID: newID("audit"), PromptID: first.ID, Action: "prompt.created", Payload: json.RawMessage(`{"name":"audited"}`), CreatedBy: ptr("author"), CreatedAt: minute(0)},
+		{ID: newID("audit"), PromptID: second.ID, Action: "prompt.created", CreatedAt: minute(1)},
+		{ID: newID("audit"), PromptID: first.ID, Action: "prompt.updated", CreatedAt: minute(2)},
+	}
+	for _, entry := range entries {
+		must(t, repos.PromptAuditLog.Create(ctx, entry), "create prompt audit log")
+	}
+	// 写入时分配连续序号并串联哈希。
+	for i, entry := range entries {
+		if entry.Seq != int64(i+1) || entry.Hash != entry.ChainHash() {
+			t.Fatalf("entry %d not chained: seq=%d", i, entry.Seq)
+		}
+		if i > 0 && entry.PrevHash != entries[i-1].Hash {
+			t.Fatalf("entry %d does not link to previous hash", i)
+		}
+	}
+
+	logs, err := repos.PromptAuditLog.ListByPrompt(ctx, first.ID, 10)
+	must(t, err, "list prompt audit logs")
+	if len(logs) != 2 || logs[0].ID != entries[2].ID || logs[1].ID != entries[0].ID {
+		t.Fatalf("expected newest-first logs of the prompt, got %d", len(logs))
+	}
+	if string(logs[1].Payload) != `{"name":"audited"}` || logs[1].CreatedBy == nil || logs[1].Hash != logs[1].ChainHash() {
+		t.Fatalf("stored entry does not round-trip: %+v", logs[1])
+	}
+	limited, err := repos.PromptAuditLog.ListByPrompt(ctx, first.ID, 1)
+	must(t, err, "list limited prompt audit logs")
+	if len(limited) != 1 {
+		t.Fatalf("expected limit to apply, got %d", len(limited))
+	}
+
+	var seqs []int64
+	must(t, repos.PromptAuditLog.Iterate(ctx, domain.AuditLogIterateOptions{}, func(log *domain.PromptAuditLog) error {
+		seqs = append(seqs, log.Seq)
+		return nil
+	}), "iterate prompt audit logs")
+	if len(seqs) != 3 || seqs[0] != 1 || seqs[2] != 3 {
+		t.Fatalf("expected ascending chain order, got %v", seqs)
+	}
+	var filtered []string
+	must(t, repos.PromptAuditLog.Iterate(ctx, domain.AuditLogIterateOptions{PromptID: first.ID, From: minute(1), To: minute(3)}, func(log *domain.PromptAuditLog) error {
+		filtered = append(filtered, log.ID)
+		return nil
+	}), "iterate filtered prompt audit logs")
+	if len(filtered) != 1 || filtered[0] != entries[2].ID {
+		t.Fatalf("unexpected filtered iteration %v", filtered)
+	}
+}
+
+func testAuditLogs(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+
+	entries := []*domain.AuditLog{
+		{ID: newID("audit"), Action: "auth.login", Actor: ptr("alice"), TargetType: "user", TargetID: ptr("u1"), IP: ptr("10.0.0.1"), UserAgent: ptr("curl"), CreatedAt: minute(0)},
+		{ID: newID("audit"), Action: "auth.logout", Actor: ptr("alice"), TargetType: "user", TargetID: ptr("u1"), CreatedAt: minute(1)},
+		{ID: newID("audit"), Action: "user.role_changed", Actor: ptr("admin"), TargetType: "user", TargetID: ptr("u2"), Payload: json.RawMessage(`{"role":"editor"}`), CreatedAt: minute(2)},
+		{ID: newID("audit"), Action: "auth", Actor: ptr("bob"), CreatedAt: minute(3)},
+	}
+	for _, entry := range entries {
+		must(t, repos.AuditLogs.Create(ctx, entry), "create audit log")
+	}
+	for i, entry := range entries {
+		if entry.Seq != int64(i+1) || entry.Hash != entry.ChainHash() || (i > 0 && entry.PrevHash != entries[i-1].Hash) {
+			t.Fatalf("entry %d not chained", i)
+		}
+	}
+
+	all, err := repos.AuditLogs.List(ctx, domain.AuditLogListOptions{})
+	must(t, err, "list audit logs")
+	if len(all) != 4 || all[0].ID != entries[3].ID || all[3].ID != entries[0].ID {
+		t.Fatalf("expected newest-first audit logs, got %d", len(all))
+	}
+	if all[3].IP == nil || *all[3].IP != "10.0.0.1" || all[3].UserAgent == nil || all[3].Hash != all[3].ChainHash() {
+		t.Fatalf("stored entry does not round-trip: %+v", all[3])
+	}
+
+	cases := []struct {
+		name string
+		opts domain.AuditLogListOptions
+		want int
+	}{
+		// 以 "." 结尾的 Action 为前缀匹配，不匹配同名的精确动作。
+		{"action prefix", domain.AuditLogListOptions{Action: "auth."}, 2},
+		{"exact action", domain.AuditLogListOptions{Action: "auth"}, 1},
+		{"actor", domain.AuditLogListOptions{Actor: "alice"}, 2},
+		{"target", domain.AuditLogListOptions{TargetType: "user", TargetID: "u2"}, 1},
+		{"time range", domain.AuditLogListOptions{From: minute(1), To: minute(3)}, 2},
+		{"no match", domain.AuditLogListOptions{Actor: "nobody"}, 0},
+	}
+	for _, tc := range cases {
+		logs, err := repos.AuditLogs.List(ctx, tc.opts)
+		must(t, err, tc.name)
+		count, err := repos.AuditLogs.Count(ctx, tc.opts)
+		must(t, err, tc.name)
+		if len(logs) != tc.want || count != int64(tc.want) {
+			t.Fatalf("%s: expected %d logs, got %d (count %d)", tc.name, tc.want, len(logs), count)
+		}
+	}
+
+	page, err := repos.AuditLogs.List(ctx, domain.AuditLogListOptions{Limit: 2, Offset: 1})
+	must(t, err, "page audit logs")
+	if len(page) != 2 || page[0].ID != entries[2].ID {
+		t.Fatalf("unexpected page %d", len(page))
+	}
+	// Count 忽略分页参数。
+	count, err := repos.AuditLogs.Count(ctx, domain.AuditLogListOptions{Limit: 1})
+	must(t, err, "count audit logs")
+	if count != 4 {
+		t.Fatalf("expected count 4, got %d", count)
+	}
+
+	// Iterate 按链序号升序，同样忽略分页参数。
+	var iterated []string
+	must(t, repos.AuditLogs.Iterate(ctx, domain.AuditLogListOptions{Actor: "alice", Limit: 1}, func(log *domain.AuditLog) error {
+		iterated = append(iterated, log.ID)
+		return nil
+	}), "iterate audit logs")
+	if len(iterated) != 2 || iterated[0] != entries[0].ID || iterated[1] != entries[1].ID {
+		t.Fatalf("unexpected iteration %v", iterated)
+	}
+}
@@ -0,0 +1,158 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testPromptTemplates(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+
+	// 迁移预置内置模板，列表按 slug 排序。
+	builtin, err := repos.PromptTemplates.List(ctx)
+	must(t, err, "list builtin templates")
+	if len(builtin) == 0 {
+		t.Fatalf("expected builtin templates to be seeded")
+	}
+	for i := 1; i < len(builtin); i++ {
+		if builtin[i-1].Slug > builtin[i].Slug {
+			t.Fatalf("templates not sorted by slug: %s before %s", builtin[i-1].Slug, builtin[i].Slug)
+		}
+	}
+
+	template := &domain.PromptTemplate{
+		ID:              newID("template"),
+		Slug:            "zz-custom",
+		Name:            "Custom",
+		Description:     ptr("custom scaffold"),
+		Body:            "Hello {{name}}",
+		VariablesSchema: json.RawMessage(`{"type":"object"}`),
+		Tags:            json.RawMessage(`["custom"]`),
+		CreatedBy:       ptr("admin"),
+		CreatedAt:       minute(0),
+	}
+	must(t, repos.PromptTemplates.Create(ctx, template), "create template")
+	expectError(t, repos.PromptTemplates.Create(ctx, &domain.PromptTemplate{ID: newID("template"), Slug: "zz-custom", Name: "Dup", Body: "x"}), "create duplicate slug")
+
+	stored, err := repos.PromptTemplates.GetBySlug(ctx, "zz-custom")
+	must(t, err, "get template")
+	if stored.ID != template.ID || stored.Body != template.Body || string(stored.Tags) != `["custom"]` || stored.Metadata != nil || !stored.CreatedAt.Equal(minute(0)) {
+		t.Fatalf("unexpected template %+v", stored)
+	}
+	_, err = repos.PromptTemplates.GetBySlug(ctx, "missing")
+	expectNotFound(t, err, "get missing template")
+
+	template.Name = "Custom v2"
+	template.Description = nil
+	template.Metadata = json.RawMessage(`{"v":2}`)
+	template.UpdatedAt = minute(5)
+	must(t, repos.PromptTemplates.Update(ctx, template), "update template")
+	stored, err = repos.PromptTemplates.GetBySlug(ctx, "zz-custom")
+	must(t, err, "reload template")
+	if stored.Name != "Custom v2" || stored.Description != nil || string(stored.Metadata) != `{"v":2}` {
+		t.Fatalf("update not applied: %+v", stored)
+	}
+	expectNotFound(t, repos.PromptTemplates.Update(ctx, &domain.PromptTemplate{Slug: "missing", Name: "x", Body: "x"}), "update missing template")
+
+	all, err := repos.PromptTemplates.List(ctx)
+	must(t, err, "list templates")
+	if len(all) != len(builtin)+1 || all[len(all)-1].Slug != "zz-custom" {
+		t.Fatalf("expected custom template listed last, got %d templates", len(all))
+	}
+
+	must(t, repos.PromptTemplates.Delete(ctx, "zz-custom"), "delete template")
+	expectNotFound(t, repos.PromptTemplates.Delete(ctx, "zz-custom"), "delete template twice")
+}
+
+func testPromptDrafts(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	prompt := seedPrompt(t, repos, "drafted")
+	user := seedUser(t, repos, "drafter@example.com")
+	base := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusPublished)
+
+	_, err := repos.PromptDrafts.Get(ctx, prompt.ID, user.ID)
+	expectNotFound(t, err, "get missing draft")
+
+	// expectedRevision 为 0 时要求草稿尚不存在。
+	draft := &domain.PromptDraft{PromptID: prompt.ID, UserID: user.ID, Body: "first", BaseVersionID: &base.ID, Metadata: json.RawMessage(`{"k":1}`)}
+	must(t, repos.PromptDrafts.Save(ctx, draft, ptr(0)), "create draft")
+	if err := repos.PromptDrafts.Save(ctx, draft, ptr(0)); err != domain.ErrRevisionConflict {
+		t.Fatalf("expected revision conflict when draft exists, got %v", err)
+	}
+	stored, err := repos.PromptDrafts.Get(ctx, prompt.ID, user.ID)
+	must(t, err, "get draft")
+	if stored.Revision != 1 || stored.Body != "first" || stored.BaseVersionID == nil || *stored.BaseVersionID != base.ID || string(stored.Metadata) != `{"k":1}` {
+		t.Fatalf("unexpected draft %+v", stored)
+	}
+
+	// 指定修订号时必须与当前一致。
+	draft.Body = "second"
+	must(t, repos.PromptDrafts.Save(ctx, draft, ptr(1)), "update draft")
+	if err := repos.PromptDrafts.Save(ctx, draft, ptr(1)); err != domain.ErrRevisionConflict {
+		t.Fatalf("expected revision conflict on stale revision, got %v", err)
+	}
+	// nil 表示无条件覆盖。
+	draft.Body = "third"
+	must(t, repos.PromptDrafts.Save(ctx, draft, nil), "overwrite draft")
+	stored, err = repos.PromptDrafts.Get(ctx, prompt.ID, user.ID)
+	must(t, err, "reload draft")
+	if stored.Revision != 3 || stored.Body != "third" {
+		t.Fatalf("expected revision 3 with body third, got %+v", stored)
+	}
+	if err := repos.PromptDrafts.Save(ctx, &domain.PromptDraft{PromptID: prompt.ID, UserID: "someone-else", Body: "x"}, ptr(1)); err != domain.ErrRevisionConflict {
+		t.Fatalf("expected revision conflict for missing draft, got %v", err)
+	}
+
+	must(t, repos.PromptDrafts.Delete(ctx, prompt.ID, user.ID), "delete draft")
+	expectNotFound(t, repos.PromptDrafts.Delete(ctx, prompt.ID, user.ID), "delete draft twice")
+}
+
+func testPromptReviews(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	prompt := seedPrompt(t, repos, "reviewed")
+	version := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusDraft)
+
+	_, err := repos.PromptReviews.GetPolicy(ctx, prompt.ID)
+	expectNotFound(t, err, "get missing policy")
+
+	policy := &domain.PromptReviewPolicy{
+		PromptID:          prompt.ID,
+		RequiredApprovals: 2,
+		Reviewers:         []domain.PromptOwner{{Type: domain.PromptOwnerUser, ID: "u1"}, {Type: domain.PromptOwnerTeam, ID: "org/qa"}},
+		UpdatedBy:         ptr("admin"),
+	}
+	must(t, repos.PromptReviews.SavePolicy(ctx, policy), "save policy")
+	policy.RequiredApprovals = 1
+	policy.Reviewers = nil
+	must(t, repos.PromptReviews.SavePolicy(ctx, policy), "overwrite policy")
+	stored, err := repos.PromptReviews.GetPolicy(ctx, prompt.ID)
+	must(t, err, "get policy")
+	if stored.RequiredApprovals != 1 || len(stored.Reviewers) != 0 || stored.UpdatedBy == nil {
+		t.Fatalf("unexpected policy %+v", stored)
+	}
+
+	must(t, repos.PromptReviews.Approve(ctx, &domain.PromptVersionApproval{VersionID: version.ID, PromptID: prompt.ID, ReviewerID: "u1", Reviewer: ptr("u1@example.com"), CreatedAt: minute(0)}), "approve")
+	must(t, repos.PromptReviews.Approve(ctx, &domain.PromptVersionApproval{VersionID: version.ID, PromptID: prompt.ID, ReviewerID: "u2", CreatedAt: minute(1)}), "second approval")
+	// 同一评审人重复批准覆盖评论。
+	must(t, repos.PromptReviews.Approve(ctx, &domain.PromptVersionApproval{VersionID: version.ID, PromptID: prompt.ID, ReviewerID: "u1", Reviewer: ptr("u1@example.com"), Comment: ptr("lgtm"), CreatedAt: minute(0)}), "repeat approval")
+
+	approvals, err := repos.PromptReviews.ListApprovals(ctx, version.ID)
+	must(t, err, "list approvals")
+	if len(approvals) != 2 || approvals[0].ReviewerID != "u1" || approvals[0].Comment == nil || *approvals[0].Comment != "lgtm" {
+		t.Fatalf("unexpected approvals %+v", approvals)
+	}
+
+	must(t, repos.PromptReviews.RevokeApproval(ctx, version.ID, "u1"), "revoke approval")
+	expectNotFound(t, repos.PromptReviews.RevokeApproval(ctx, version.ID, "u1"), "revoke approval twice")
+	approvals, err = repos.PromptReviews.ListApprovals(ctx, version.ID)
+	must(t, err, "list approvals after revoke")
+	if len(approvals) != 1 || approvals[0].ReviewerID != "u2" {
+		t.Fatalf("expected only u2 approval, got %+v", approvals)
+	}
+
+	must(t, repos.PromptReviews.DeletePolicy(ctx, prompt.ID), "delete policy")
+	expectNotFound(t, repos.PromptReviews.DeletePolicy(ctx, prompt.ID), "delete policy twice")
+}
@@ -0,0 +1,147 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testExecutionLogs(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	prompt := seedPrompt(t, repos, "executed")
+	v1 := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusPublished)
+	v2 := seedVersion(t, repos, prompt.ID, 2, domain.PromptVersionStatusPublished)
+
+	logAt := func(version *domain.PromptVersion, at time.Time, status string, durationMs int64) *domain.PromptExecutionLog {
+		log := &domain.PromptExecutionLog{
+			ID:              newID("log"),
+			PromptID:        prompt.ID,
+			PromptVersionID: version.ID,
+			UserID:          ptr("user-1"),
+			Status:          status,
+			DurationMs:      durationMs,
+			CreatedAt:       at,
+		}
+		if status != "success" {
+			log.ErrorClass = ptr(domain.ExecutionErrorTimeout)
+			log.ErrorCode = ptr("deadline_exceeded")
+		}
+		return log
+	}
+
+	// Create 总是以写入时刻作为 created_at，只有 CreateBatch 保留调用方给定的时间。
+	other := seedPrompt(t, repos, "executed-now")
+	otherVersion := seedVersion(t, repos, other.ID, 1, domain.PromptVersionStatusPublished)
+	must(t, repos.PromptExecutionLog.Create(ctx, &domain.PromptExecutionLog{ID: newID("log"), PromptID: other.ID, PromptVersionID: otherVersion.ID, Status: "success", CreatedAt: minute(0)}), "create log")
+	stamped, err := repos.PromptExecutionLog.ListRecent(ctx, other.ID, 10)
+	must(t, err, "list logs of other prompt")
+	if len(stamped) != 1 || !stamped[0].CreatedAt.After(minute(0)) {
+		t.Fatalf("expected log stamped with insertion time, got %+v", stamped)
+	}
+
+	first := logAt(v1, minute(0), "success", 100)
+	must(t, repos.PromptExecutionLog.CreateBatch(ctx, []*domain.PromptExecutionLog{first}), "create first log")
+	expectError(t, repos.PromptExecutionLog.Create(ctx, first), "create duplicate log")
+
+	// 批量写入跳过已存在的 ID，便于重投递。
+	batch := []*domain.PromptExecutionLog{
+		first,
+		logAt(v1, minute(10), "failed", 300),
+		logAt(v2, minute(20), "success", 200),
+		logAt(v2, minute(24*60), "success", 400),
+	}
+	must(t, repos.PromptExecutionLog.CreateBatch(ctx, batch), "create batch")
+	must(t, repos.PromptExecutionLog.CreateBatch(ctx, batch), "replay batch")
+	must(t, repos.PromptExecutionLog.CreateBatch(ctx, nil), "create empty batch")
+
+	fetched, err := repos.PromptExecutionLog.GetByID(ctx, batch[1].ID)
+	must(t, err, "get log")
+	if fetched.PromptVersionID != v1.ID || fetched.Status != "failed" || fetched.DurationMs != 300 || !fetched.CreatedAt.Equal(minute(10)) {
+		t.Fatalf("unexpected log %+v", fetched)
+	}
+	_, err = repos.PromptExecutionLog.GetByID(ctx, "missing-log")
+	expectNotFound(t, err, "get missing log")
+
+	recent, err := repos.PromptExecutionLog.ListRecent(ctx, prompt.ID, 2)
+	must(t, err, "list recent logs")
+	if len(recent) != 2 || recent[0].ID != batch[3].ID || recent[1].ID != batch[2].ID {
+		t.Fatalf("expected two newest logs in descending order, got %d", len(recent))
+	}
+	failed, err := repos.PromptExecutionLog.ListRecent(ctx, prompt.ID, 10)
+	must(t, err, "list all logs")
+	if len(failed) != 4 {
+		t.Fatalf("expected 4 logs after replayed batch, got %d", len(failed))
+	}
+	for _, log := range failed {
+		if log.ID == batch[1].ID && (log.ErrorClass == nil || *log.ErrorClass != domain.ExecutionErrorTimeout || log.ErrorCode == nil || log.UserID == nil) {
+			t.Fatalf("error details not stored: %+v", log)
+		}
+	}
+
+	// IterateSince 含下界、按时间倒序，回调出错时中止并返回该错误。
+	var iterated []string
+	must(t, repos.PromptExecutionLog.IterateSince(ctx, prompt.ID, minute(10), func(log *domain.PromptExecutionLog) error {
+		iterated = append(iterated, log.ID)
+		return nil
+	}), "iterate logs")
+	if len(iterated) != 3 || iterated[0] != batch[3].ID || iterated[2] != batch[1].ID {
+		t.Fatalf("unexpected iteration order %v", iterated)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = repos.PromptExecutionLog.IterateSince(ctx, prompt.ID, minute(0), func(*domain.PromptExecutionLog) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected iteration to stop on callback error, got %v after %d calls", err, calls)
+	}
+
+	// 按天分桶，倒序返回；To 为开区间。
+	aggregates, err := repos.PromptExecutionLog.AggregateUsage(ctx, prompt.ID, domain.ExecutionAggregateOptions{From: minute(0), To: minute(2 * 24 * 60)})
+	must(t, err, "aggregate usage")
+	if len(aggregates) != 2 {
+		t.Fatalf("expected 2 daily buckets, got %d", len(aggregates))
+	}
+	latest, earliest := aggregates[0], aggregates[1]
+	if !latest.Day.Equal(time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)) || latest.TotalCalls != 1 {
+		t.Fatalf("unexpected latest bucket %+v", latest)
+	}
+	if earliest.TotalCalls != 3 || earliest.SuccessCalls != 2 || earliest.AverageMillis != 200 || earliest.P50Millis != 200 {
+		t.Fatalf("unexpected earliest bucket %+v", earliest)
+	}
+	if earliest.ErrorClasses[domain.ExecutionErrorTimeout] != 1 || latest.ErrorClasses != nil {
+		t.Fatalf("unexpected error classes %v / %v", earliest.ErrorClasses, latest.ErrorClasses)
+	}
+	hourly, err := repos.PromptExecutionLog.AggregateUsage(ctx, prompt.ID, domain.ExecutionAggregateOptions{From: minute(0), To: minute(60), Granularity: domain.GranularityHour})
+	must(t, err, "aggregate hourly usage")
+	if len(hourly) != 1 || hourly[0].TotalCalls != 3 || !hourly[0].Day.Equal(minute(0)) {
+		t.Fatalf("unexpected hourly buckets %+v", hourly)
+	}
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	local, err := repos.PromptExecutionLog.AggregateUsage(ctx, prompt.ID, domain.ExecutionAggregateOptions{From: minute(0), To: minute(2 * 24 * 60), Location: shanghai})
+	must(t, err, "aggregate usage in time zone")
+	if len(local) != 2 || !local[1].Day.Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, shanghai)) {
+		t.Fatalf("expected buckets aligned to local midnight, got %+v", local)
+	}
+
+	window, err := repos.PromptExecutionLog.SummarizeWindow(ctx, prompt.ID, minute(0))
+	must(t, err, "summarize window")
+	if window.TotalCalls != 4 || window.FailedCalls != 1 || window.P95Millis != 385 {
+		t.Fatalf("unexpected window %+v", window)
+	}
+	empty, err := repos.PromptExecutionLog.SummarizeWindow(ctx, prompt.ID, minute(3*24*60))
+	must(t, err, "summarize empty window")
+	if empty.TotalCalls != 0 || empty.FailedCalls != 0 || empty.P95Millis != 0 {
+		t.Fatalf("expected empty window, got %+v", empty)
+	}
+
+	byVersion, err := repos.PromptExecutionLog.SummarizeWindowByVersion(ctx, prompt.ID, minute(0))
+	must(t, err, "summarize by version")
+	if len(byVersion) != 2 || byVersion[v1.ID].TotalCalls != 2 || byVersion[v1.ID].FailedCalls != 1 || byVersion[v2.ID].TotalCalls != 2 || byVersion[v2.ID].FailedCalls != 0 {
+		t.Fatalf("unexpected per-version windows %+v", byVersion)
+	}
+}
@@ -0,0 +1,339 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testAlertRules(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	first := seedPrompt(t, repos, "alerted")
+	second := seedPrompt(t, repos, "alerted-too")
+
+	rule := &domain.PromptAlertRule{
+		ID:            newID("rule"),
+		PromptID:      first.ID,
+		Name:          "error spike",
+		Metric:        domain.AlertMetricErrorRate,
+		Threshold:     5,
+		WindowMinutes: 15,
+		WebhookURL:    ptr("https://hooks.example.com/alert"),
+		Enabled:       true,
+		CreatedBy:     ptr("admin"),
+		CreatedAt:     minute(0),
+	}
+	must(t, repos.PromptAlertRules.Create(ctx, rule), "create rule")
+	must(t, repos.PromptAlertRules.Create(ctx, &domain.PromptAlertRule{ID: newID("rule"), PromptID: first.ID, Name: "slow", Metric: domain.AlertMetricP95Latency, Threshold: 800, WindowMinutes: 5, CreatedAt: minute(1)}), "create disabled rule")
+	must(t, repos.PromptAlertRules.Create(ctx, &domain.PromptAlertRule{ID: newID("rule"), PromptID: second.ID, Name: "other", Metric: domain.AlertMetricErrorRate, Threshold: 1, WindowMinutes: 5, Enabled: true, CreatedAt: minute(2)}), "create rule on second prompt")
+
+	stored, err := repos.PromptAlertRules.GetByID(ctx, rule.ID)
+	must(t, err, "get rule")
+	if stored.State != domain.AlertStateOK || stored.Threshold != 5 || !stored.Enabled || stored.WebhookURL == nil || stored.LastValue != nil {
+		t.Fatalf("unexpected rule %+v", stored)
+	}
+	_, err = repos.PromptAlertRules.GetByID(ctx, "missing")
+	expectNotFound(t, err, "get missing rule")
+
+	byPrompt, err := repos.PromptAlertRules.ListByPrompt(ctx, first.ID)
+	must(t, err, "list rules by prompt")
+	if len(byPrompt) != 2 || byPrompt[0].ID != rule.ID {
+		t.Fatalf("expected 2 rules in creation order, got %d", len(byPrompt))
+	}
+	enabled, err := repos.PromptAlertRules.ListEnabled(ctx)
+	must(t, err, "list enabled rules")
+	if len(enabled) != 2 {
+		t.Fatalf("expected 2 enabled rules, got %d", len(enabled))
+	}
+
+	// changedAt 为空时保留上一次状态切换时间。
+	must(t, repos.PromptAlertRules.UpdateState(ctx, rule.ID, domain.AlertStateFiring, ptr(12.5), minute(10), ptr(minute(10))), "fire rule")
+	must(t, repos.PromptAlertRules.UpdateState(ctx, rule.ID, domain.AlertStateFiring, nil, minute(11), nil), "re-evaluate rule")
+	stored, err = repos.PromptAlertRules.GetByID(ctx, rule.ID)
+	must(t, err, "reload rule")
+	if stored.State != domain.AlertStateFiring || stored.LastValue != nil || stored.LastEvaluatedAt == nil || !stored.LastEvaluatedAt.Equal(minute(11)) {
+		t.Fatalf("evaluation not recorded: %+v", stored)
+	}
+	if stored.StateChangedAt == nil || !stored.StateChangedAt.Equal(minute(10)) {
+		t.Fatalf("expected state change time kept, got %v", stored.StateChangedAt)
+	}
+	expectNotFound(t, repos.PromptAlertRules.UpdateState(ctx, "missing", domain.AlertStateOK, nil, minute(12), nil), "update missing rule")
+
+	// 轮换签名密钥时保留旧密钥至过期时间，再次轮换可清除旧密钥。
+	signed := &domain.PromptAlertRule{ID: newID("rule"), PromptID: second.ID, Name: "signed", Metric: domain.AlertMetricErrorRate, Threshold: 1, WindowMinutes: 5, WebhookSecret: "whsec_initial", CreatedAt: minute(3)}
+	must(t, repos.PromptAlertRules.Create(ctx, signed), "create signed rule")
+	stored, err = repos.PromptAlertRules.GetByID(ctx, signed.ID)
+	must(t, err, "get signed rule")
+	if stored.WebhookSecret != "whsec_initial" || stored.PreviousWebhookSecret != nil {
+		t.Fatalf("unexpected webhook secret %+v", stored)
+	}
+	must(t, repos.PromptAlertRules.UpdateWebhookSecret(ctx, rule.ID, "whsec_next", ptr("whsec_old"), ptr(minute(60))), "rotate secret")
+	stored, err = repos.PromptAlertRules.GetByID(ctx, rule.ID)
+	must(t, err, "reload rotated rule")
+	if stored.WebhookSecret != "whsec_next" || stored.PreviousWebhookSecret == nil || *stored.PreviousWebhookSecret != "whsec_old" ||
+		stored.PreviousSecretExpiresAt == nil || !stored.PreviousSecretExpiresAt.Equal(minute(60)) {
+		t.Fatalf("unexpected rotated secret %+v", stored)
+	}
+	must(t, repos.PromptAlertRules.UpdateWebhookSecret(ctx, rule.ID, "whsec_last", nil, nil), "rotate without overlap")
+	stored, err = repos.PromptAlertRules.GetByID(ctx, rule.ID)
+	must(t, err, "reload rule without overlap")
+	if stored.WebhookSecret != "whsec_last" || stored.PreviousWebhookSecret != nil || stored.PreviousSecretExpiresAt != nil {
+		t.Fatalf("expected previous secret cleared, got %+v", stored)
+	}
+	expectNotFound(t, repos.PromptAlertRules.UpdateWebhookSecret(ctx, "missing", "whsec_x", nil, nil), "rotate missing rule")
+
+	must(t, repos.PromptAlertRules.Delete(ctx, rule.ID), "delete rule")
+	expectNotFound(t, repos.PromptAlertRules.Delete(ctx, rule.ID), "delete rule twice")
+}
+
+func testUsage(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	period := "2025-03"
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	org := &domain.Organization{ID: newID("org"), Name: "Zeta", Slug: newID("zeta")}
+	must(t, repos.Workspaces.CreateOrganization(ctx, org), "create organization")
+	workspace := &domain.Workspace{ID: newID("ws"), OrganizationID: org.ID, Name: "Zeta", Slug: "zeta"}
+	must(t, repos.Workspaces.CreateWorkspace(ctx, workspace, nil), "create workspace")
+
+	// 计数器按 (工作区, 周期, 指标) 累加。
+	must(t, repos.Usage.AddCounters(ctx, []domain.UsageCounter{
+		{WorkspaceID: workspace.ID, Period: period, Metric: domain.UsageMetricAPICalls, Value: 3},
+		{WorkspaceID: workspace.ID, Period: period, Metric: domain.UsageMetricAPICalls, Value: 4},
+		{WorkspaceID: workspace.ID, Period: "2025-02", Metric: domain.UsageMetricAPICalls, Value: 100},
+	}), "add counters")
+	must(t, repos.Usage.AddCounters(ctx, nil), "add no counters")
+
+	prompt := &domain.Prompt{ID: newID("prompt"), Name: "metered", WorkspaceID: workspace.ID}
+	must(t, repos.Prompts.Create(ctx, prompt), "create prompt")
+	version := &domain.PromptVersion{ID: newID("version"), PromptID: prompt.ID, VersionNumber: 1, Body: "héllo", Status: domain.PromptVersionStatusPublished}
+	must(t, repos.PromptVersions.Create(ctx, version), "create version")
+	var logs []*domain.PromptExecutionLog
+	for _, at := range []time.Time{minute(0), minute(1), to} {
+		logs = append(logs, &domain.PromptExecutionLog{ID: newID("log"), PromptID: prompt.ID, PromptVersionID: version.ID, Status: "success", CreatedAt: at})
+	}
+	must(t, repos.PromptExecutionLog.CreateBatch(ctx, logs), "create logs")
+
+	usages, err := repos.Usage.TenantUsage(ctx, period, from, to)
+	must(t, err, "tenant usage")
+	var zeta, fallback *domain.TenantUsage
+	for _, usage := range usages {
+		switch usage.OrganizationID {
+		case org.ID:
+			zeta = usage
+		case domain.DefaultOrganizationID:
+			fallback = usage
+		}
+	}
+	if zeta == nil || fallback == nil {
+		t.Fatalf("expected usage for every organization, got %d entries", len(usages))
+	}
+	// 存储按 UTF-8 字节数计算："héllo" 为 6 字节。
+	if zeta.Period != period || zeta.APICalls != 7 || zeta.Executions != 2 || zeta.StorageBytes != 6 {
+		t.Fatalf("unexpected tenant usage %+v", zeta)
+	}
+	if fallback.APICalls != 0 || fallback.Executions != 0 {
+		t.Fatalf("expected empty usage for default organization, got %+v", fallback)
+	}
+
+	exports, err := repos.Usage.ListExports(ctx, "billing", period)
+	must(t, err, "list exports")
+	if len(exports) != 0 {
+		t.Fatalf("expected no exports, got %d", len(exports))
+	}
+	must(t, repos.Usage.SaveExports(ctx, []*domain.UsageExport{
+		{OrganizationID: org.ID, Period: period, Metric: domain.UsageMetricAPICalls, Exporter: "billing", Value: 5},
+		{OrganizationID: org.ID, Period: period, Metric: domain.UsageMetricExecutions, Exporter: "billing", Value: 1},
+	}), "save exports")
+	must(t, repos.Usage.SaveExports(ctx, []*domain.UsageExport{
+		{OrganizationID: org.ID, Period: period, Metric: domain.UsageMetricAPICalls, Exporter: "billing", Value: 7},
+	}), "overwrite export")
+	must(t, repos.Usage.SaveExports(ctx, nil), "save no exports")
+	exports, err = repos.Usage.ListExports(ctx, "billing", period)
+	must(t, err, "list exports after save")
+	values := map[string]int64{}
+	for _, export := range exports {
+		values[export.Metric] = export.Value
+	}
+	if len(exports) != 2 || values[domain.UsageMetricAPICalls] != 7 || values[domain.UsageMetricExecutions] != 1 {
+		t.Fatalf("unexpected exports %v", values)
+	}
+	other, err := repos.Usage.ListExports(ctx, "other", period)
+	must(t, err, "list exports of another exporter")
+	if len(other) != 0 {
+		t.Fatalf("expected exports scoped to exporter, got %d", len(other))
+	}
+}
+
+func testStorage(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	org := &domain.Organization{ID: newID("org"), Name: "Storage", Slug: newID("storage")}
+	must(t, repos.Workspaces.CreateOrganization(ctx, org), "create organization")
+	workspace := &domain.Workspace{ID: newID("ws"), OrganizationID: org.ID, Name: "Storage", Slug: "storage"}
+	must(t, repos.Workspaces.CreateWorkspace(ctx, workspace, nil), "create workspace")
+
+	big := &domain.Prompt{ID: newID("prompt"), Name: "stored", WorkspaceID: workspace.ID}
+	must(t, repos.Prompts.Create(ctx, big), "create prompt")
+	old := &domain.PromptVersion{ID: newID("version"), PromptID: big.ID, VersionNumber: 1, Body: "abc", Status: domain.PromptVersionStatusArchived}
+	active := &domain.PromptVersion{ID: newID("version"), PromptID: big.ID, VersionNumber: 2, Body: "héllo", Status: domain.PromptVersionStatusPublished}
+	must(t, repos.PromptVersions.Create(ctx, old), "create old version")
+	must(t, repos.PromptVersions.Create(ctx, active), "create active version")
+	must(t, repos.Prompts.UpdateActiveVersion(ctx, big.ID, &active.ID, &active.Body), "activate version")
+	must(t, repos.PromptExecutionLog.CreateBatch(ctx, []*domain.PromptExecutionLog{
+		{ID: newID("log"), PromptID: big.ID, PromptVersionID: active.ID, Status: "success", RequestPayload: []byte(`{"a":1}`), CreatedAt: minute(0)},
+	}), "create log")
+	must(t, repos.PromptAuditLog.Create(ctx, &domain.PromptAuditLog{ID: newID("audit"), PromptID: big.ID, Action: "prompt.created", Payload: []byte(`{"k":"v"}`), CreatedAt: minute(0)}), "create prompt audit log")
+	must(t, repos.AuditLogs.Create(ctx, &domain.AuditLog{ID: newID("audit"), Action: "auth.login", Payload: []byte(`{}`), CreatedAt: minute(0)}), "create audit log")
+
+	small := &domain.Prompt{ID: newID("prompt"), Name: "tiny", WorkspaceID: workspace.ID}
+	must(t, repos.Prompts.Create(ctx, small), "create small prompt")
+
+	report, err := repos.Usage.StorageReport(ctx, 1)
+	must(t, err, "storage report")
+	var tenant *domain.TenantStorage
+	for _, candidate := range report.Tenants {
+		if candidate.OrganizationID == org.ID {
+			tenant = candidate
+		}
+	}
+	if tenant == nil || len(report.Tenants) < 2 {
+		t.Fatalf("expected storage for every organization, got %d entries", len(report.Tenants))
+	}
+	// 字节数按 UTF-8 计算："stored" + 激活正文 "héllo" 为 12 字节，两个版本正文为 9 字节，其中历史版本 "abc" 为 3 字节。
+	want := domain.TenantStorage{
+		OrganizationID:   org.ID,
+		OrganizationName: "Storage",
+		Prompts:          domain.StorageUsage{Rows: 2, Bytes: 16},
+		Versions:         domain.StorageUsage{Rows: 2, Bytes: 9},
+		HistoryVersions:  domain.StorageUsage{Rows: 1, Bytes: 3},
+		ExecutionLogs:    domain.StorageUsage{Rows: 1, Bytes: 7},
+		AuditLogs:        domain.StorageUsage{Rows: 1, Bytes: 9},
+		TotalBytes:       41,
+	}
+	if *tenant != want {
+		t.Fatalf("unexpected tenant storage %+v", tenant)
+	}
+	if len(report.TopPrompts) != 1 || report.TopPrompts[0].PromptID != big.ID || report.TopPrompts[0].TotalBytes != 37 {
+		t.Fatalf("expected largest prompt first, got %+v", report.TopPrompts)
+	}
+	if report.SystemAuditLogs.Rows != 1 || report.SystemAuditLogs.Bytes != 2 {
+		t.Fatalf("unexpected system audit storage %+v", report.SystemAuditLogs)
+	}
+}
+
+func testAnnouncements(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+
+	always := &domain.Announcement{ID: newID("announcement"), Message: "welcome", Severity: domain.AnnouncementSeverityInfo, CreatedBy: ptr("admin"), CreatedAt: minute(0)}
+	window := &domain.Announcement{ID: newID("announcement"), Message: "maintenance", Severity: domain.AnnouncementSeverityWarning, StartsAt: ptr(minute(60)), EndsAt: ptr(minute(120)), CreatedAt: minute(1)}
+	must(t, repos.Announcements.Create(ctx, always), "create announcement")
+	must(t, repos.Announcements.Create(ctx, window), "create scheduled announcement")
+
+	stored, err := repos.Announcements.GetByID(ctx, window.ID)
+	must(t, err, "get announcement")
+	if stored.Message != "maintenance" || stored.StartsAt == nil || !stored.StartsAt.Equal(minute(60)) || stored.EndsAt == nil || !stored.EndsAt.Equal(minute(120)) {
+		t.Fatalf("unexpected announcement %+v", stored)
+	}
+	_, err = repos.Announcements.GetByID(ctx, "missing")
+	expectNotFound(t, err, "get missing announcement")
+
+	all, err := repos.Announcements.List(ctx)
+	must(t, err, "list announcements")
+	if len(all) != 2 || all[0].ID != window.ID {
+		t.Fatalf("expected newest announcement first, got %d", len(all))
+	}
+
+	// 展示窗口含开始时间、不含结束时间。
+	for _, tc := range []struct {
+		at   time.Time
+		want []string
+	}{
+		{minute(30), []string{always.ID}},
+		{minute(60), []string{window.ID, always.ID}},
+		{minute(120), []string{always.ID}},
+	} {
+		active, err := repos.Announcements.ListActive(ctx, tc.at)
+		must(t, err, "list active announcements")
+		if len(active) != len(tc.want) {
+			t.Fatalf("at %s: expected %d active announcements, got %d", tc.at, len(tc.want), len(active))
+		}
+		for i, id := range tc.want {
+			if active[i].ID != id {
+				t.Fatalf("at %s: unexpected order of active announcements", tc.at)
+			}
+		}
+	}
+
+	window.Message = "maintenance extended"
+	window.Severity = domain.AnnouncementSeverityCritical
+	window.EndsAt = nil
+	must(t, repos.Announcements.Update(ctx, window), "update announcement")
+	stored, err = repos.Announcements.GetByID(ctx, window.ID)
+	must(t, err, "reload announcement")
+	if stored.Message != "maintenance extended" || stored.Severity != domain.AnnouncementSeverityCritical || stored.EndsAt != nil {
+		t.Fatalf("update not applied: %+v", stored)
+	}
+	expectNotFound(t, repos.Announcements.Update(ctx, &domain.Announcement{ID: "missing", Message: "x", Severity: domain.AnnouncementSeverityInfo}), "update missing announcement")
+
+	must(t, repos.Announcements.Delete(ctx, always.ID), "delete announcement")
+	expectNotFound(t, repos.Announcements.Delete(ctx, always.ID), "delete announcement twice")
+}
+
+func testPromptCanaries(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	prompt := seedPrompt(t, repos, "canaried")
+	baseline := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusPublished)
+	candidate := seedVersion(t, repos, prompt.ID, 2, domain.PromptVersionStatusPublished)
+
+	_, err := repos.PromptCanaries.GetRunning(ctx, prompt.ID)
+	expectNotFound(t, err, "get running canary before start")
+
+	canary := &domain.PromptCanary{
+		ID:                newID("canary"),
+		PromptID:          prompt.ID,
+		VersionID:         candidate.ID,
+		BaselineVersionID: baseline.ID,
+		Percent:           10,
+		MaxErrorRateDelta: 2.5,
+		MinCalls:          50,
+		WindowMinutes:     30,
+		StartedBy:         ptr("admin"),
+		StartedAt:         minute(0),
+	}
+	must(t, repos.PromptCanaries.Create(ctx, canary), "create canary")
+	if canary.Status != domain.CanaryStatusRunning {
+		t.Fatalf("expected status defaulted to running, got %q", canary.Status)
+	}
+	// 同一 Prompt 同时只能有一个进行中的灰度。
+	expectError(t, repos.PromptCanaries.Create(ctx, &domain.PromptCanary{ID: newID("canary"), PromptID: prompt.ID, VersionID: candidate.ID, BaselineVersionID: baseline.ID, Percent: 20, StartedAt: minute(1)}), "create second running canary")
+
+	running, err := repos.PromptCanaries.GetRunning(ctx, prompt.ID)
+	must(t, err, "get running canary")
+	if running.ID != canary.ID || running.Percent != 10 || running.MaxErrorRateDelta != 2.5 || !running.StartedAt.Equal(minute(0)) || running.CanaryErrorRate != nil {
+		t.Fatalf("unexpected canary %+v", running)
+	}
+	list, err := repos.PromptCanaries.ListRunning(ctx)
+	must(t, err, "list running canaries")
+	if len(list) != 1 {
+		t.Fatalf("expected 1 running canary, got %d", len(list))
+	}
+
+	must(t, repos.PromptCanaries.UpdateEvaluation(ctx, canary.ID, ptr(3.0), ptr(1.0), minute(5)), "update evaluation")
+	expectNotFound(t, repos.PromptCanaries.UpdateEvaluation(ctx, "missing", nil, nil, minute(5)), "update evaluation of missing canary")
+	running, err = repos.PromptCanaries.GetRunning(ctx, prompt.ID)
+	must(t, err, "reload canary")
+	if running.CanaryErrorRate == nil || *running.CanaryErrorRate != 3 || running.BaselineErrorRate == nil || running.LastEvaluatedAt == nil || !running.LastEvaluatedAt.Equal(minute(5)) {
+		t.Fatalf("evaluation not recorded: %+v", running)
+	}
+
+	must(t, repos.PromptCanaries.Finish(ctx, canary.ID, domain.CanaryStatusRolledBack, ptr("error rate exceeded"), minute(6)), "finish canary")
+	expectNotFound(t, repos.PromptCanaries.Finish(ctx, canary.ID, domain.CanaryStatusAborted, nil, minute(7)), "finish canary twice")
+	_, err = repos.PromptCanaries.GetRunning(ctx, prompt.ID)
+	expectNotFound(t, err, "get running canary after finish")
+
+	// 结束后可以开始新的灰度。
+	must(t, repos.PromptCanaries.Create(ctx, &domain.PromptCanary{ID: newID("canary"), PromptID: prompt.ID, VersionID: candidate.ID, BaselineVersionID: baseline.ID, Percent: 5, StartedAt: minute(8)}), "restart canary")
+}
@@ -0,0 +1,241 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testPrompts(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	author := seedUser(t, repos, "author@example.com")
+
+	prompt := &domain.Prompt{
+		ID:          newID("prompt"),
+		Name:        "Greeting",
+		Description: ptr("says hello"),
+		Tags:        json.RawMessage(`["demo"]`),
+		CreatedBy:   ptr(author.ID),
+	}
+	must(t, repos.Prompts.Create(ctx, prompt), "create prompt")
+	expectError(t, repos.Prompts.Create(ctx, &domain.Prompt{ID: newID("prompt"), Name: "Greeting"}), "create duplicate name")
+	expectError(t, repos.Prompts.Create(ctx, &domain.Prompt{ID: prompt.ID, Name: "other"}), "create duplicate id")
+
+	stored, err := repos.Prompts.GetByID(ctx, prompt.ID)
+	must(t, err, "get prompt")
+	if stored.Name != "Greeting" || stored.Status != domain.PromptStatusActive || stored.WorkspaceID != domain.DefaultWorkspaceID {
+		t.Fatalf("unexpected prompt %+v", stored)
+	}
+	// created_by 记录用户 ID 时读取结果展示其邮箱。
+	if stored.CreatedBy == nil || *stored.CreatedBy != author.Email {
+		t.Fatalf("expected created_by resolved to email, got %v", stored.CreatedBy)
+	}
+	if stored.Description == nil || *stored.Description != "says hello" || string(stored.Tags) != `["demo"]` {
+		t.Fatalf("unexpected description/tags %+v", stored)
+	}
+	byName, err := repos.Prompts.GetByName(ctx, "Greeting", false)
+	must(t, err, "get prompt by name")
+	if byName.ID != prompt.ID {
+		t.Fatalf("expected %s by name, got %s", prompt.ID, byName.ID)
+	}
+	_, err = repos.Prompts.GetByID(ctx, "missing")
+	expectNotFound(t, err, "get missing prompt")
+	_, err = repos.Prompts.GetByName(ctx, "missing", true)
+	expectNotFound(t, err, "get missing prompt by name")
+
+	// Update 只修改显式给出的字段。
+	must(t, repos.Prompts.Update(ctx, prompt.ID, domain.PromptUpdateParams{
+		Name: ptr("Greeting v2"), HasName: true,
+		Description: nil, HasDescription: true,
+		RenderMode: ptr("markdown"), HasRenderMode: true,
+	}), "update prompt")
+	stored, err = repos.Prompts.GetByID(ctx, prompt.ID)
+	must(t, err, "reload prompt")
+	if stored.Name != "Greeting v2" || stored.Description != nil || string(stored.Tags) != `["demo"]` || stored.RenderMode == nil || *stored.RenderMode != "markdown" {
+		t.Fatalf("update not applied as expected: %+v", stored)
+	}
+	expectNotFound(t, repos.Prompts.Update(ctx, "missing", domain.PromptUpdateParams{Name: ptr("x"), HasName: true}), "update missing prompt")
+
+	version := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusPublished)
+	must(t, repos.Prompts.UpdateActiveVersion(ctx, prompt.ID, &version.ID, &version.Body), "activate version")
+	stored, err = repos.Prompts.GetByID(ctx, prompt.ID)
+	must(t, err, "reload prompt")
+	if stored.ActiveVersionID == nil || *stored.ActiveVersionID != version.ID || stored.Body == nil || *stored.Body != version.Body {
+		t.Fatalf("active version not set: %+v", stored)
+	}
+	expectNotFound(t, repos.Prompts.UpdateActiveVersion(ctx, "missing", &version.ID, nil), "activate on missing prompt")
+
+	// OmitBody 只跳过正文，其余字段照常返回。
+	listed, err := repos.Prompts.List(ctx, domain.PromptListOptions{OmitBody: true})
+	must(t, err, "list prompts without body")
+	if len(listed) != 1 || listed[0].Body != nil || listed[0].ActiveVersionID == nil || listed[0].Name != "Greeting v2" {
+		t.Fatalf("expected prompt without body, got %+v", listed)
+	}
+
+	must(t, repos.Prompts.UpdateOwner(ctx, prompt.ID, &domain.PromptOwner{Type: domain.PromptOwnerTeam, ID: "org/platform"}), "set owner")
+	stored, err = repos.Prompts.GetByID(ctx, prompt.ID)
+	must(t, err, "reload prompt")
+	if stored.Owner == nil || stored.Owner.Type != domain.PromptOwnerTeam || stored.Owner.ID != "org/platform" {
+		t.Fatalf("owner not set: %+v", stored.Owner)
+	}
+	must(t, repos.Prompts.UpdateOwner(ctx, prompt.ID, nil), "clear owner")
+	stored, err = repos.Prompts.GetByID(ctx, prompt.ID)
+	must(t, err, "reload prompt")
+	if stored.Owner != nil {
+		t.Fatalf("owner not cleared: %+v", stored.Owner)
+	}
+
+	// 归档的 Prompt 默认不在列表中。
+	archived := seedPrompt(t, repos, "archived-one")
+	must(t, repos.Prompts.UpdateStatus(ctx, archived.ID, domain.PromptStatusActive, domain.PromptStatusArchived), "archive prompt")
+	expectNotFound(t, repos.Prompts.UpdateStatus(ctx, archived.ID, domain.PromptStatusActive, domain.PromptStatusArchived), "archive twice")
+	expectPromptIDs(t, repos, domain.PromptListOptions{}, prompt.ID)
+	expectPromptIDs(t, repos, domain.PromptListOptions{IncludeArchived: true}, prompt.ID, archived.ID)
+	expectPromptIDs(t, repos, domain.PromptListOptions{ArchivedOnly: true}, archived.ID)
+
+	// 软删除后单条查询与默认列表均不可见，IncludeDeleted 时可见，且不能重复删除或修改。
+	must(t, repos.Prompts.Delete(ctx, prompt.ID), "delete prompt")
+	expectNotFound(t, repos.Prompts.Delete(ctx, prompt.ID), "delete twice")
+	_, err = repos.Prompts.GetByID(ctx, prompt.ID)
+	expectNotFound(t, err, "get deleted prompt")
+	_, err = repos.Prompts.GetByName(ctx, "Greeting v2", false)
+	expectNotFound(t, err, "get deleted prompt by name")
+	if _, err := repos.Prompts.GetByName(ctx, "Greeting v2", true); err != nil {
+		t.Fatalf("get deleted prompt by name including deleted: %v", err)
+	}
+	deleted, err := repos.Prompts.GetByIDIncludeDeleted(ctx, prompt.ID)
+	must(t, err, "get deleted prompt including deleted")
+	if deleted.Status != domain.PromptStatusDeleted || deleted.DeletedAt == nil {
+		t.Fatalf("expected soft-deleted prompt, got %+v", deleted)
+	}
+	expectNotFound(t, repos.Prompts.Update(ctx, prompt.ID, domain.PromptUpdateParams{Name: ptr("x"), HasName: true}), "update deleted prompt")
+	expectNotFound(t, repos.Prompts.UpdateOwner(ctx, prompt.ID, nil), "set owner of deleted prompt")
+	expectNotFound(t, repos.Prompts.UpdateStatus(ctx, prompt.ID, domain.PromptStatusDeleted, domain.PromptStatusActive), "update status of deleted prompt")
+	expectPromptIDs(t, repos, domain.PromptListOptions{})
+	expectPromptIDs(t, repos, domain.PromptListOptions{IncludeDeleted: true}, prompt.ID)
+	// 软删除的 Prompt 仍占用名称。
+	expectError(t, repos.Prompts.Create(ctx, &domain.Prompt{ID: newID("prompt"), Name: "Greeting v2"}), "reuse name of deleted prompt")
+
+	// Restore 只作用于已删除的 Prompt，并按参数覆盖字段。
+	expectNotFound(t, repos.Prompts.Restore(ctx, archived.ID, domain.PromptRestoreParams{}), "restore non-deleted prompt")
+	must(t, repos.Prompts.Restore(ctx, prompt.ID, domain.PromptRestoreParams{
+		Description: ptr("restored"), HasDescription: true,
+		Tags: ptr(`["restored"]`), HasTags: true,
+		CreatedBy: ptr("restorer@example.com"), HasCreatedBy: true,
+	}), "restore prompt")
+	restored, err := repos.Prompts.GetByID(ctx, prompt.ID)
+	must(t, err, "get restored prompt")
+	if restored.Status != domain.PromptStatusActive || restored.DeletedAt != nil {
+		t.Fatalf("expected active prompt after restore, got %+v", restored)
+	}
+	if restored.Description == nil || *restored.Description != "restored" || string(restored.Tags) != `["restored"]` || restored.CreatedBy == nil || *restored.CreatedBy != "restorer@example.com" {
+		t.Fatalf("restore params not applied: %+v", restored)
+	}
+	if restored.Body == nil || *restored.Body != version.Body {
+		t.Fatalf("body should be kept when HasBody is false, got %v", restored.Body)
+	}
+	expectNotFound(t, repos.Prompts.Restore(ctx, prompt.ID, domain.PromptRestoreParams{}), "restore twice")
+
+	// 搜索、创建人过滤与分页。
+	seedPrompt(t, repos, "search-alpha")
+	seedPrompt(t, repos, "search-beta")
+	found, err := repos.Prompts.List(ctx, domain.PromptListOptions{Search: "SEARCH-"})
+	must(t, err, "search prompts")
+	if len(found) != 2 {
+		t.Fatalf("expected 2 search results, got %d", len(found))
+	}
+	count, err := repos.Prompts.Count(ctx, domain.PromptListOptions{Search: "search-"})
+	must(t, err, "count search results")
+	if count != 2 {
+		t.Fatalf("expected count 2, got %d", count)
+	}
+	page, err := repos.Prompts.List(ctx, domain.PromptListOptions{Search: "search-", Limit: 1, Offset: 1})
+	must(t, err, "page prompts")
+	if len(page) != 1 {
+		t.Fatalf("expected 1 prompt on second page, got %d", len(page))
+	}
+	expectPromptIDs(t, repos, domain.PromptListOptions{CreatedBy: []string{"restorer@example.com"}}, prompt.ID)
+
+	// 按激活版本声明的目标模型过滤，只匹配完整元素，未激活的版本不参与匹配。
+	targeted := seedPrompt(t, repos, "targeted")
+	targetedVersion := &domain.PromptVersion{ID: newID("version"), PromptID: targeted.ID, VersionNumber: 1, Body: "targeted", Status: domain.PromptVersionStatusPublished, TargetModels: []string{"gpt-4o", "claude_3"}}
+	must(t, repos.PromptVersions.Create(ctx, targetedVersion), "create targeted version")
+	storedVersion, err := repos.PromptVersions.GetByID(ctx, targetedVersion.ID)
+	must(t, err, "get targeted version")
+	if len(storedVersion.TargetModels) != 2 || storedVersion.TargetModels[1] != "claude_3" {
+		t.Fatalf("expected target models to round-trip, got %v", storedVersion.TargetModels)
+	}
+	expectPromptIDs(t, repos, domain.PromptListOptions{TargetModels: []string{"gpt-4o"}})
+	must(t, repos.Prompts.UpdateActiveVersion(ctx, targeted.ID, &targetedVersion.ID, &targetedVersion.Body), "activate targeted version")
+	expectPromptIDs(t, repos, domain.PromptListOptions{TargetModels: []string{"gpt-4o"}}, targeted.ID)
+	expectPromptIDs(t, repos, domain.PromptListOptions{TargetModels: []string{"gpt-4", "claude-3"}})
+	expectPromptIDs(t, repos, domain.PromptListOptions{TargetModels: []string{"mistral", "claude_3"}}, targeted.ID)
+
+	if _, _, err := repos.Prompts.EstimateCount(ctx); err != nil {
+		t.Fatalf("estimate count: %v", err)
+	}
+
+	testPromptWorkspaceScope(t, repos)
+}
+
+// testPromptWorkspaceScope 校验上下文中的工作区限定单条查询与列表，显式 WorkspaceID 优先于上下文。
+func testPromptWorkspaceScope(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	org := &domain.Organization{ID: newID("org"), Name: "Scoped", Slug: newID("scoped")}
+	must(t, repos.Workspaces.CreateOrganization(ctx, org), "create organization")
+	workspace := &domain.Workspace{ID: newID("ws"), OrganizationID: org.ID, Name: "Team", Slug: "team"}
+	must(t, repos.Workspaces.CreateWorkspace(ctx, workspace, nil), "create workspace")
+
+	scoped := &domain.Prompt{ID: newID("prompt"), Name: "scoped-prompt"}
+	wsCtx := domain.WithWorkspace(ctx, workspace.ID)
+	must(t, repos.Prompts.Create(wsCtx, scoped), "create prompt in workspace")
+	if scoped.WorkspaceID != workspace.ID {
+		t.Fatalf("expected workspace taken from context, got %q", scoped.WorkspaceID)
+	}
+
+	defaultCtx := domain.WithWorkspace(ctx, domain.DefaultWorkspaceID)
+	_, err := repos.Prompts.GetByID(defaultCtx, scoped.ID)
+	expectNotFound(t, err, "get prompt from another workspace")
+	_, err = repos.Prompts.GetByName(defaultCtx, "scoped-prompt", false)
+	expectNotFound(t, err, "get prompt by name from another workspace")
+	if _, err := repos.Prompts.GetByID(wsCtx, scoped.ID); err != nil {
+		t.Fatalf("get prompt in own workspace: %v", err)
+	}
+	if _, err := repos.Prompts.GetByID(ctx, scoped.ID); err != nil {
+		t.Fatalf("get prompt without workspace scope: %v", err)
+	}
+
+	listed, err := repos.Prompts.List(wsCtx, domain.PromptListOptions{})
+	must(t, err, "list workspace prompts")
+	if len(listed) != 1 || listed[0].ID != scoped.ID {
+		t.Fatalf("expected only the scoped prompt, got %d prompts", len(listed))
+	}
+	count, err := repos.Prompts.Count(defaultCtx, domain.PromptListOptions{WorkspaceID: workspace.ID})
+	must(t, err, "count with explicit workspace")
+	if count != 1 {
+		t.Fatalf("explicit workspace should override context, got count %d", count)
+	}
+}
+
+func expectPromptIDs(t *testing.T, repos *domain.Repositories, opts domain.PromptListOptions, ids ...string) {
+	t.Helper()
+	ctx := context.Background()
+	prompts, err := repos.Prompts.List(ctx, opts)
+	must(t, err, "list prompts")
+	count, err := repos.Prompts.Count(ctx, opts)
+	must(t, err, "count prompts")
+	if len(prompts) != len(ids) || count != int64(len(ids)) {
+		t.Fatalf("expected %d prompts for %+v, got %d (count %d)", len(ids), opts, len(prompts), count)
+	}
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	for _, prompt := range prompts {
+		if !want[prompt.ID] {
+			t.Fatalf("unexpected prompt %s for %+v", prompt.ID, opts)
+		}
+	}
+}
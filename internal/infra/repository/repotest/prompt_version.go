@@ -0,0 +1,241 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testPromptVersions(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	prompt := seedPrompt(t, repos, "versioned")
+
+	latest, err := repos.PromptVersions.GetLatestVersionNumber(ctx, prompt.ID)
+	must(t, err, "latest version of empty prompt")
+	if latest != 0 {
+		t.Fatalf("expected latest version 0, got %d", latest)
+	}
+
+	v1 := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusPublished)
+	v2 := seedVersion(t, repos, prompt.ID, 2, domain.PromptVersionStatusDraft)
+	v3 := seedVersion(t, repos, prompt.ID, 3, domain.PromptVersionStatusDraft)
+	expectError(t, repos.PromptVersions.Create(ctx, &domain.PromptVersion{ID: newID("version"), PromptID: prompt.ID, VersionNumber: 2, Body: "dup", Status: domain.PromptVersionStatusDraft}), "create duplicate version number")
+
+	stored, err := repos.PromptVersions.GetByID(ctx, v1.ID)
+	must(t, err, "get version")
+	if stored.PromptID != prompt.ID || stored.VersionNumber != 1 || stored.Body != v1.Body || stored.Status != domain.PromptVersionStatusPublished {
+		t.Fatalf("unexpected version %+v", stored)
+	}
+	_, err = repos.PromptVersions.GetByID(ctx, "missing")
+	expectNotFound(t, err, "get missing version")
+	metadata, err := repos.PromptVersions.MetadataByIDs(ctx, []string{v1.ID, v2.ID, "missing"})
+	must(t, err, "batch get version metadata")
+	if len(metadata) != 2 || string(metadata[v1.ID]) != "{}" {
+		t.Fatalf("expected metadata of v1 and v2, got %v", metadata)
+	}
+
+	latest, err = repos.PromptVersions.GetLatestVersionNumber(ctx, prompt.ID)
+	must(t, err, "latest version")
+	if latest != 3 {
+		t.Fatalf("expected latest version 3, got %d", latest)
+	}
+
+	// 列表按版本号倒序分页。
+	versions, err := repos.PromptVersions.ListByPrompt(ctx, prompt.ID, 2, 0)
+	must(t, err, "list versions")
+	if len(versions) != 2 || versions[0].ID != v3.ID || versions[1].ID != v2.ID {
+		t.Fatalf("expected [v3 v2], got %d versions", len(versions))
+	}
+	versions, err = repos.PromptVersions.ListByPrompt(ctx, prompt.ID, 2, 2)
+	must(t, err, "list versions second page")
+	if len(versions) != 1 || versions[0].ID != v1.ID {
+		t.Fatalf("expected [v1] on second page, got %d versions", len(versions))
+	}
+	drafts, err := repos.PromptVersions.ListByPromptAndStatus(ctx, prompt.ID, domain.PromptVersionStatusDraft, 10, 0)
+	must(t, err, "list draft versions")
+	if len(drafts) != 2 || drafts[0].ID != v3.ID {
+		t.Fatalf("expected 2 drafts starting with v3, got %d", len(drafts))
+	}
+	// IterateByPrompt 不分页、按版本号倒序，回调出错时中止并返回该错误。
+	var iterated []string
+	must(t, repos.PromptVersions.IterateByPrompt(ctx, prompt.ID, "", func(version *domain.PromptVersion) error {
+		iterated = append(iterated, version.ID)
+		return nil
+	}), "iterate versions")
+	if len(iterated) != 3 || iterated[0] != v3.ID || iterated[2] != v1.ID {
+		t.Fatalf("expected [v3 v2 v1], got %v", iterated)
+	}
+	iterated = nil
+	must(t, repos.PromptVersions.IterateByPrompt(ctx, prompt.ID, domain.PromptVersionStatusPublished, func(version *domain.PromptVersion) error {
+		iterated = append(iterated, version.ID)
+		return nil
+	}), "iterate published versions")
+	if len(iterated) != 1 || iterated[0] != v1.ID {
+		t.Fatalf("expected [v1] when filtering published, got %v", iterated)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = repos.PromptVersions.IterateByPrompt(ctx, prompt.ID, "", func(*domain.PromptVersion) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected iteration to stop after first callback error, got %v after %d calls", err, calls)
+	}
+
+	total, err := repos.PromptVersions.CountByPrompt(ctx, prompt.ID)
+	must(t, err, "count versions")
+	draftCount, err := repos.PromptVersions.CountByPromptAndStatus(ctx, prompt.ID, domain.PromptVersionStatusDraft)
+	must(t, err, "count draft versions")
+	if total != 3 || draftCount != 2 {
+		t.Fatalf("expected 3 versions and 2 drafts, got %d and %d", total, draftCount)
+	}
+
+	previous, err := repos.PromptVersions.GetPreviousVersion(ctx, prompt.ID, 3)
+	must(t, err, "previous version")
+	if previous.ID != v2.ID {
+		t.Fatalf("expected v2 before v3, got %s", previous.ID)
+	}
+	_, err = repos.PromptVersions.GetPreviousVersion(ctx, prompt.ID, 1)
+	expectNotFound(t, err, "previous of first version")
+
+	// 状态按 from 条件更新，不匹配时返回 ErrNotFound。
+	must(t, repos.PromptVersions.UpdateStatus(ctx, v2.ID, domain.PromptVersionStatusDraft, domain.PromptVersionStatusPublished), "publish v2")
+	expectNotFound(t, repos.PromptVersions.UpdateStatus(ctx, v2.ID, domain.PromptVersionStatusDraft, domain.PromptVersionStatusPublished), "publish v2 twice")
+	expectNotFound(t, repos.PromptVersions.UpdateStatus(ctx, "missing", domain.PromptVersionStatusDraft, domain.PromptVersionStatusPublished), "publish missing version")
+
+	// DeleteByIDs 级联删除语言变体、执行日志与批准记录，忽略其他 Prompt 的版本。
+	other := seedPrompt(t, repos, "other")
+	otherVersion := seedVersion(t, repos, other.ID, 1, domain.PromptVersionStatusDraft)
+	must(t, repos.PromptLocales.Upsert(ctx, &domain.PromptVersionLocale{ID: newID("locale"), VersionID: v3.ID, Locale: "fr", Body: "bonjour"}), "upsert locale")
+	must(t, repos.PromptExecutionLog.Create(ctx, &domain.PromptExecutionLog{ID: newID("log"), PromptID: prompt.ID, PromptVersionID: v3.ID, Status: "success", CreatedAt: minute(0)}), "create log")
+	must(t, repos.PromptReviews.Approve(ctx, &domain.PromptVersionApproval{VersionID: v3.ID, PromptID: prompt.ID, ReviewerID: "reviewer"}), "approve version")
+
+	deleted, err := repos.PromptVersions.DeleteByIDs(ctx, prompt.ID, []string{v3.ID, otherVersion.ID, "missing"})
+	must(t, err, "delete versions")
+	if deleted != 1 {
+		t.Fatalf("expected 1 deleted version, got %d", deleted)
+	}
+	deleted, err = repos.PromptVersions.DeleteByIDs(ctx, prompt.ID, nil)
+	must(t, err, "delete no versions")
+	if deleted != 0 {
+		t.Fatalf("expected nothing deleted, got %d", deleted)
+	}
+	_, err = repos.PromptVersions.GetByID(ctx, v3.ID)
+	expectNotFound(t, err, "get deleted version")
+	if _, err := repos.PromptVersions.GetByID(ctx, otherVersion.ID); err != nil {
+		t.Fatalf("version of another prompt must be kept: %v", err)
+	}
+	_, err = repos.PromptLocales.GetByVersionAndLocale(ctx, v3.ID, "fr")
+	expectNotFound(t, err, "locale of deleted version")
+	logs, err := repos.PromptExecutionLog.ListRecent(ctx, prompt.ID, 10)
+	must(t, err, "list logs")
+	if len(logs) != 0 {
+		t.Fatalf("expected logs of deleted version removed, got %d", len(logs))
+	}
+	approvals, err := repos.PromptReviews.ListApprovals(ctx, v3.ID)
+	must(t, err, "list approvals")
+	if len(approvals) != 0 {
+		t.Fatalf("expected approvals of deleted version removed, got %d", len(approvals))
+	}
+}
+
+func testPromptLocales(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	prompt := seedPrompt(t, repos, "b-localized")
+	version := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusPublished)
+
+	first := &domain.PromptVersionLocale{ID: newID("locale"), VersionID: version.ID, Locale: "fr", Body: "bonjour", CreatedBy: ptr("author")}
+	must(t, repos.PromptLocales.Upsert(ctx, first), "upsert locale")
+	must(t, repos.PromptLocales.Upsert(ctx, &domain.PromptVersionLocale{ID: newID("locale"), VersionID: version.ID, Locale: "fr", Body: "salut", CreatedBy: ptr("editor")}), "overwrite locale")
+	must(t, repos.PromptLocales.Upsert(ctx, &domain.PromptVersionLocale{ID: newID("locale"), VersionID: version.ID, Locale: "de", Body: "hallo"}), "upsert second locale")
+
+	// 覆盖只更新正文，保留首次写入的标识与创建人。
+	stored, err := repos.PromptLocales.GetByVersionAndLocale(ctx, version.ID, "fr")
+	must(t, err, "get locale")
+	if stored.ID != first.ID || stored.Body != "salut" || stored.CreatedBy == nil || *stored.CreatedBy != "author" {
+		t.Fatalf("unexpected locale after overwrite %+v", stored)
+	}
+	_, err = repos.PromptLocales.GetByVersionAndLocale(ctx, version.ID, "es")
+	expectNotFound(t, err, "get missing locale")
+
+	locales, err := repos.PromptLocales.ListByVersion(ctx, version.ID)
+	must(t, err, "list locales")
+	if len(locales) != 2 || locales[0].Locale != "de" || locales[1].Locale != "fr" {
+		t.Fatalf("expected locales sorted [de fr], got %d", len(locales))
+	}
+
+	// 覆盖率只统计未删除且已激活版本的 Prompt，按名称排序。
+	must(t, repos.Prompts.UpdateActiveVersion(ctx, prompt.ID, &version.ID, &version.Body), "activate version")
+	bare := seedPrompt(t, repos, "a-bare")
+	bareVersion := seedVersion(t, repos, bare.ID, 1, domain.PromptVersionStatusPublished)
+	must(t, repos.Prompts.UpdateActiveVersion(ctx, bare.ID, &bareVersion.ID, &bareVersion.Body), "activate bare version")
+	seedPrompt(t, repos, "c-inactive")
+	gone := seedPrompt(t, repos, "d-deleted")
+	goneVersion := seedVersion(t, repos, gone.ID, 1, domain.PromptVersionStatusPublished)
+	must(t, repos.Prompts.UpdateActiveVersion(ctx, gone.ID, &goneVersion.ID, &goneVersion.Body), "activate deleted version")
+	must(t, repos.Prompts.Delete(ctx, gone.ID), "delete prompt")
+
+	coverage, err := repos.PromptLocales.ListActiveCoverage(ctx)
+	must(t, err, "list coverage")
+	if len(coverage) != 2 {
+		t.Fatalf("expected 2 prompts in coverage, got %d", len(coverage))
+	}
+	if coverage[0].PromptID != bare.ID || len(coverage[0].Locales) != 0 {
+		t.Fatalf("expected bare prompt first without locales, got %+v", coverage[0])
+	}
+	if coverage[1].PromptID != prompt.ID || coverage[1].ActiveVersionID != version.ID || len(coverage[1].Locales) != 2 || coverage[1].Locales[0] != "de" {
+		t.Fatalf("unexpected coverage %+v", coverage[1])
+	}
+
+	must(t, repos.PromptLocales.Delete(ctx, version.ID, "fr"), "delete locale")
+	expectNotFound(t, repos.PromptLocales.Delete(ctx, version.ID, "fr"), "delete locale twice")
+}
+
+func testPromptDependencies(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	root := seedPrompt(t, repos, "root")
+	alpha := seedPrompt(t, repos, "alpha")
+	beta := seedPrompt(t, repos, "beta")
+
+	must(t, repos.PromptDependencies.ReplaceBySource(ctx, root.ID, "explicit", []string{beta.ID, alpha.ID}, ptr("author")), "set explicit dependencies")
+	must(t, repos.PromptDependencies.ReplaceBySource(ctx, root.ID, "include", []string{alpha.ID}, nil), "set include dependencies")
+
+	links, err := repos.PromptDependencies.ListDependencies(ctx, root.ID)
+	must(t, err, "list dependencies")
+	if len(links) != 3 {
+		t.Fatalf("expected 3 dependency links, got %d", len(links))
+	}
+	// 按名称、来源排序。
+	if links[0].PromptID != alpha.ID || links[0].Source != "explicit" || links[1].Source != "include" || links[2].PromptID != beta.ID {
+		t.Fatalf("unexpected dependency order %+v %+v %+v", links[0], links[1], links[2])
+	}
+	if links[0].Name != "alpha" || links[0].Status != domain.PromptStatusActive {
+		t.Fatalf("expected link to carry prompt name and status, got %+v", links[0])
+	}
+
+	// 覆盖只影响同一来源。
+	must(t, repos.PromptDependencies.ReplaceBySource(ctx, root.ID, "explicit", []string{beta.ID}, nil), "replace explicit dependencies")
+	links, err = repos.PromptDependencies.ListDependencies(ctx, root.ID)
+	must(t, err, "list dependencies after replace")
+	if len(links) != 2 {
+		t.Fatalf("expected 2 dependency links after replace, got %d", len(links))
+	}
+	expectError(t, repos.PromptDependencies.ReplaceBySource(ctx, root.ID, "explicit", []string{alpha.ID, alpha.ID}, nil), "duplicate dependency")
+
+	dependents, err := repos.PromptDependencies.ListDependents(ctx, alpha.ID)
+	must(t, err, "list dependents")
+	if len(dependents) != 1 || dependents[0].PromptID != root.ID || dependents[0].Source != "include" {
+		t.Fatalf("unexpected dependents %+v", dependents)
+	}
+
+	// 已删除的 Prompt 不出现在依赖方列表中。
+	must(t, repos.Prompts.Delete(ctx, root.ID), "delete root")
+	dependents, err = repos.PromptDependencies.ListDependents(ctx, alpha.ID)
+	must(t, err, "list dependents after delete")
+	if dependents == nil || len(dependents) != 0 {
+		t.Fatalf("expected empty non-nil dependents, got %v", dependents)
+	}
+}
@@ -0,0 +1,125 @@
+// Package repotest 提供 domain.Repositories 的一致性测试套件。
+//
+// 各存储后端（SQLite、PostgreSQL、内存实现）在自己的测试中调用 Run 并传入构建空仓储的工厂函数，
+// 即可用同一组用例校验接口语义：软删除与恢复、唯一约束、工作区隔离、排序与分页等。
+// 用例只断言接口契约，不依赖具体后端的时间精度或错误文本（唯一约束仅要求返回错误）。
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// Factory 为每个用例构建一套互不共享数据的空仓储（已完成迁移）。
+type Factory func(t *testing.T) *domain.Repositories
+
+// Run 针对 newRepos 构建的仓储执行全部一致性用例，每个仓储接口对应一个子测试，各自使用一套新仓储。
+func Run(t *testing.T, newRepos Factory) {
+	suites := []struct {
+		name string
+		run  func(t *testing.T, repos *domain.Repositories)
+	}{
+		{"Users", testUsers},
+		{"UserIdentities", testUserIdentities},
+		{"UserMerge", testUserMerge},
+		{"Prompts", testPrompts},
+		{"PromptVersions", testPromptVersions},
+		{"PromptLocales", testPromptLocales},
+		{"PromptDependencies", testPromptDependencies},
+		{"PromptExecutionLog", testExecutionLogs},
+		{"PromptAuditLog", testPromptAuditLogs},
+		{"AuditLogs", testAuditLogs},
+		{"Pipelines", testPipelines},
+		{"SigningKeys", testSigningKeys},
+		{"Workspaces", testWorkspaces},
+		{"Invitations", testInvitations},
+		{"LoginEvents", testLoginEvents},
+		{"PromptAlertRules", testAlertRules},
+		{"PromptTemplates", testPromptTemplates},
+		{"PromptDrafts", testPromptDrafts},
+		{"PromptReviews", testPromptReviews},
+		{"APIKeys", testAPIKeys},
+		{"Usage", testUsage},
+		{"Storage", testStorage},
+		{"Announcements", testAnnouncements},
+		{"PromptCanaries", testPromptCanaries},
+	}
+	for _, suite := range suites {
+		t.Run(suite.name, func(t *testing.T) {
+			suite.run(t, newRepos(t))
+		})
+	}
+}
+
+var seq atomic.Int64
+
+// newID 返回全局唯一的标识，带上前缀便于排查失败用例。
+func newID(prefix string) string {
+	return fmt.Sprintf("%s-%04d", prefix, seq.Add(1))
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func must(t *testing.T, err error, action string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", action, err)
+	}
+}
+
+func expectNotFound(t *testing.T, err error, action string) {
+	t.Helper()
+	if err != domain.ErrNotFound {
+		t.Fatalf("%s: expected ErrNotFound, got %v", action, err)
+	}
+}
+
+func expectError(t *testing.T, err error, action string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("%s: expected error, got nil", action)
+	}
+}
+
+func seedUser(t *testing.T, repos *domain.Repositories, email string) *domain.User {
+	t.Helper()
+	user := &domain.User{ID: newID("user"), Email: email, HashedPassword: "hashed", Role: "editor", Status: "active"}
+	must(t, repos.Users.Create(context.Background(), user), "create user")
+	return user
+}
+
+func seedPrompt(t *testing.T, repos *domain.Repositories, name string) *domain.Prompt {
+	t.Helper()
+	prompt := &domain.Prompt{ID: newID("prompt"), Name: name, Tags: json.RawMessage(`["demo"]`)}
+	must(t, repos.Prompts.Create(context.Background(), prompt), "create prompt")
+	return prompt
+}
+
+func seedVersion(t *testing.T, repos *domain.Repositories, promptID string, number int, status string) *domain.PromptVersion {
+	t.Helper()
+	version := &domain.PromptVersion{
+		ID:              newID("version"),
+		PromptID:        promptID,
+		VersionNumber:   number,
+		Body:            fmt.Sprintf("body v%d", number),
+		VariablesSchema: json.RawMessage(`{}`),
+		Status:          status,
+		Metadata:        json.RawMessage(`{}`),
+	}
+	must(t, repos.PromptVersions.Create(context.Background(), version), "create version")
+	return version
+}
+
+// minute 返回基准时间之后 n 分钟的 UTC 时间，用于构造确定的时间序列；
+// 基准取整秒，避免不同后端的时间精度差异。
+func minute(n int) time.Time {
+	return time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC).Add(time.Duration(n) * time.Minute)
+}
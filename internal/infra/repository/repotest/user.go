@@ -0,0 +1,220 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testUsers(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+
+	user := &domain.User{ID: newID("user"), Email: "alice@example.com", HashedPassword: "hashed"}
+	must(t, repos.Users.Create(ctx, user), "create user")
+	expectError(t, repos.Users.Create(ctx, &domain.User{ID: newID("user"), Email: "alice@example.com", HashedPassword: "hashed"}), "create duplicate email")
+
+	stored, err := repos.Users.GetByID(ctx, user.ID)
+	must(t, err, "get user by id")
+	if stored.Email != user.Email || stored.Role != "viewer" || stored.Status != "active" {
+		t.Fatalf("expected defaults role=viewer status=active, got %+v", stored)
+	}
+	if stored.CreatedAt.IsZero() || stored.LastLoginAt != nil {
+		t.Fatalf("expected created_at set and no last login, got %+v", stored)
+	}
+	byEmail, err := repos.Users.GetByEmail(ctx, "alice@example.com")
+	must(t, err, "get user by email")
+	if byEmail.ID != user.ID {
+		t.Fatalf("expected user %s, got %s", user.ID, byEmail.ID)
+	}
+	_, err = repos.Users.GetByID(ctx, "missing")
+	expectNotFound(t, err, "get missing user")
+	_, err = repos.Users.GetByEmail(ctx, "missing@example.com")
+	expectNotFound(t, err, "get missing email")
+
+	must(t, repos.Users.UpdateLastLogin(ctx, user.ID), "update last login")
+	must(t, repos.Users.UpdatePassword(ctx, user.ID, "rehashed"), "update password")
+	must(t, repos.Users.UpdateRole(ctx, user.ID, "admin"), "update role")
+	must(t, repos.Users.UpdateStatus(ctx, user.ID, "disabled"), "update status")
+	stored, err = repos.Users.GetByID(ctx, user.ID)
+	must(t, err, "reload user")
+	if stored.LastLoginAt == nil || stored.HashedPassword != "rehashed" || stored.Role != "admin" || stored.Status != "disabled" {
+		t.Fatalf("updates not applied: %+v", stored)
+	}
+
+	expectNotFound(t, repos.Users.UpdateLastLogin(ctx, "missing"), "update last login of missing user")
+	expectNotFound(t, repos.Users.UpdatePassword(ctx, "missing", "x"), "update password of missing user")
+	expectNotFound(t, repos.Users.UpdateRole(ctx, "missing", "admin"), "update role of missing user")
+	expectNotFound(t, repos.Users.UpdateStatus(ctx, "missing", "active"), "update status of missing user")
+
+	seedUser(t, repos, "bob@example.com")
+	seedUser(t, repos, "carol@example.com")
+	active, err := repos.Users.ListByStatus(ctx, "active")
+	must(t, err, "list active users")
+	if len(active) != 2 {
+		t.Fatalf("expected 2 active users, got %d", len(active))
+	}
+	disabled, err := repos.Users.ListByStatus(ctx, "disabled")
+	must(t, err, "list disabled users")
+	if len(disabled) != 1 || disabled[0].ID != user.ID {
+		t.Fatalf("expected only %s disabled, got %+v", user.ID, disabled)
+	}
+}
+
+func testUserIdentities(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	user := seedUser(t, repos, "octo@example.com")
+
+	github := &domain.UserIdentity{ID: newID("identity"), UserID: user.ID, Provider: "github", ProviderUserID: "42", ProviderLogin: ptr("octocat")}
+	must(t, repos.UserIdentities.Create(ctx, github), "create identity")
+	expectError(t, repos.UserIdentities.Create(ctx, &domain.UserIdentity{ID: newID("identity"), UserID: user.ID, Provider: "github", ProviderUserID: "42"}), "create duplicate provider identity")
+	must(t, repos.UserIdentities.Create(ctx, &domain.UserIdentity{ID: newID("identity"), UserID: user.ID, Provider: "gitlab", ProviderUserID: "42"}), "create second provider identity")
+
+	stored, err := repos.UserIdentities.GetByProviderAndExternalID(ctx, "github", "42")
+	must(t, err, "get identity")
+	if stored.UserID != user.ID || stored.ProviderLogin == nil || *stored.ProviderLogin != "octocat" || stored.AvatarURL != nil {
+		t.Fatalf("unexpected identity %+v", stored)
+	}
+	_, err = repos.UserIdentities.GetByProviderAndExternalID(ctx, "github", "43")
+	expectNotFound(t, err, "get missing identity")
+
+	identities, err := repos.UserIdentities.ListByUser(ctx, user.ID)
+	must(t, err, "list identities")
+	if len(identities) != 2 {
+		t.Fatalf("expected 2 identities, got %d", len(identities))
+	}
+	none, err := repos.UserIdentities.ListByUser(ctx, "missing")
+	must(t, err, "list identities of missing user")
+	if len(none) != 0 {
+		t.Fatalf("expected no identities, got %d", len(none))
+	}
+}
+
+func testUserMerge(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	from := seedUser(t, repos, "old@example.com")
+	to := seedUser(t, repos, "new@example.com")
+
+	prompt := &domain.Prompt{ID: newID("prompt"), Name: "merge-me", CreatedBy: ptr(from.Email), Owner: &domain.PromptOwner{Type: domain.PromptOwnerUser, ID: from.ID}}
+	must(t, repos.Prompts.Create(ctx, prompt), "create prompt")
+	teamPrompt := &domain.Prompt{ID: newID("prompt"), Name: "team-owned", CreatedBy: ptr(to.Email), Owner: &domain.PromptOwner{Type: domain.PromptOwnerTeam, ID: from.ID}}
+	must(t, repos.Prompts.Create(ctx, teamPrompt), "create team prompt")
+	must(t, repos.UserIdentities.Create(ctx, &domain.UserIdentity{ID: newID("identity"), UserID: from.ID, Provider: "github", ProviderUserID: "7"}), "create identity")
+	must(t, repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{WorkspaceID: domain.DefaultWorkspaceID, UserID: from.ID, Role: "editor"}), "add from member")
+	must(t, repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{WorkspaceID: domain.DefaultWorkspaceID, UserID: to.ID, Role: "admin"}), "add to member")
+
+	// 草稿、评审批准与评审人列表也随用户迁移；目标用户已有的同键记录优先保留。
+	v1 := seedVersion(t, repos, prompt.ID, 1, domain.PromptVersionStatusPublished)
+	v2 := seedVersion(t, repos, prompt.ID, 2, domain.PromptVersionStatusDraft)
+	must(t, repos.PromptDrafts.Save(ctx, &domain.PromptDraft{PromptID: prompt.ID, UserID: from.ID, Body: "from draft"}, nil), "save from draft")
+	must(t, repos.PromptDrafts.Save(ctx, &domain.PromptDraft{PromptID: prompt.ID, UserID: to.ID, Body: "to draft"}, nil), "save to draft")
+	must(t, repos.PromptDrafts.Save(ctx, &domain.PromptDraft{PromptID: teamPrompt.ID, UserID: from.ID, Body: "team draft"}, nil), "save team draft")
+	must(t, repos.PromptReviews.Approve(ctx, &domain.PromptVersionApproval{VersionID: v1.ID, PromptID: prompt.ID, ReviewerID: from.ID, Reviewer: ptr(from.Email)}), "approve v1 as from")
+	must(t, repos.PromptReviews.Approve(ctx, &domain.PromptVersionApproval{VersionID: v1.ID, PromptID: prompt.ID, ReviewerID: to.ID, Reviewer: ptr(to.Email)}), "approve v1 as to")
+	must(t, repos.PromptReviews.Approve(ctx, &domain.PromptVersionApproval{VersionID: v2.ID, PromptID: prompt.ID, ReviewerID: from.ID, Reviewer: ptr(from.Email)}), "approve v2 as from")
+	must(t, repos.PromptReviews.SavePolicy(ctx, &domain.PromptReviewPolicy{PromptID: prompt.ID, RequiredApprovals: 1, UpdatedBy: ptr(from.Email), Reviewers: []domain.PromptOwner{
+		{Type: domain.PromptOwnerUser, ID: from.ID},
+		{Type: domain.PromptOwnerUser, ID: to.ID},
+		{Type: domain.PromptOwnerTeam, ID: from.ID},
+	}}), "save review policy")
+
+	_, err := repos.Users.Merge(ctx, domain.UserReassignment{FromUserID: "missing", ToUserID: to.ID, FromActors: []string{"missing"}, ToActor: to.Email, FromStatus: "disabled"})
+	expectNotFound(t, err, "merge missing user")
+
+	moved, err := repos.Users.Merge(ctx, domain.UserReassignment{
+		FromUserID: from.ID,
+		ToUserID:   to.ID,
+		FromActors: []string{from.Email, from.ID},
+		ToActor:    to.Email,
+		FromStatus: "disabled",
+	})
+	must(t, err, "merge users")
+	if moved["prompts"] != 1 || moved["prompt_owners"] != 1 || moved["user_identities"] != 1 {
+		t.Fatalf("unexpected merge counts %v", moved)
+	}
+
+	merged, err := repos.Prompts.GetByID(ctx, prompt.ID)
+	must(t, err, "reload prompt")
+	if merged.CreatedBy == nil || *merged.CreatedBy != to.Email || merged.Owner == nil || merged.Owner.ID != to.ID {
+		t.Fatalf("prompt not reassigned: %+v", merged)
+	}
+	team, err := repos.Prompts.GetByID(ctx, teamPrompt.ID)
+	must(t, err, "reload team prompt")
+	if team.Owner == nil || team.Owner.Type != domain.PromptOwnerTeam || team.Owner.ID != from.ID {
+		t.Fatalf("team owner should be untouched: %+v", team.Owner)
+	}
+	identity, err := repos.UserIdentities.GetByProviderAndExternalID(ctx, "github", "7")
+	must(t, err, "reload identity")
+	if identity.UserID != to.ID {
+		t.Fatalf("identity not moved: %+v", identity)
+	}
+	member, err := repos.Workspaces.GetMember(ctx, domain.DefaultWorkspaceID, to.ID)
+	must(t, err, "reload target member")
+	if member.Role != "admin" {
+		t.Fatalf("existing membership of target should keep its role, got %s", member.Role)
+	}
+	_, err = repos.Workspaces.GetMember(ctx, domain.DefaultWorkspaceID, from.ID)
+	expectNotFound(t, err, "source membership after merge")
+
+	draft, err := repos.PromptDrafts.Get(ctx, prompt.ID, to.ID)
+	must(t, err, "reload target draft")
+	if draft.Body != "to draft" {
+		t.Fatalf("existing draft of target should be kept, got %q", draft.Body)
+	}
+	draft, err = repos.PromptDrafts.Get(ctx, teamPrompt.ID, to.ID)
+	must(t, err, "reload moved draft")
+	if draft.Body != "team draft" {
+		t.Fatalf("draft not moved: %+v", draft)
+	}
+	for _, promptID := range []string{prompt.ID, teamPrompt.ID} {
+		_, err = repos.PromptDrafts.Get(ctx, promptID, from.ID)
+		expectNotFound(t, err, "source draft after merge")
+	}
+	for _, version := range []*domain.PromptVersion{v1, v2} {
+		approvals, err := repos.PromptReviews.ListApprovals(ctx, version.ID)
+		must(t, err, "list approvals")
+		if len(approvals) != 1 || approvals[0].ReviewerID != to.ID || approvals[0].Reviewer == nil || *approvals[0].Reviewer != to.Email {
+			t.Fatalf("expected a single approval by the target on version %d, got %+v", version.VersionNumber, approvals)
+		}
+	}
+	policy, err := repos.PromptReviews.GetPolicy(ctx, prompt.ID)
+	must(t, err, "reload review policy")
+	wantReviewers := []domain.PromptOwner{{Type: domain.PromptOwnerUser, ID: to.ID}, {Type: domain.PromptOwnerTeam, ID: from.ID}}
+	if len(policy.Reviewers) != len(wantReviewers) || policy.Reviewers[0] != wantReviewers[0] || policy.Reviewers[1] != wantReviewers[1] {
+		t.Fatalf("unexpected reviewers after merge: %+v", policy.Reviewers)
+	}
+	if policy.UpdatedBy == nil || *policy.UpdatedBy != to.Email {
+		t.Fatalf("policy updated_by not reassigned: %v", policy.UpdatedBy)
+	}
+
+	source, err := repos.Users.GetByID(ctx, from.ID)
+	must(t, err, "reload source user")
+	if source.Status != "disabled" {
+		t.Fatalf("expected source user disabled, got %s", source.Status)
+	}
+
+	// TransferOwnership 只改写归属，身份与成员关系保持不变。
+	third := seedUser(t, repos, "third@example.com")
+	must(t, repos.UserIdentities.Create(ctx, &domain.UserIdentity{ID: newID("identity"), UserID: to.ID, Provider: "gitlab", ProviderUserID: "8"}), "create identity")
+	_, err = repos.Users.TransferOwnership(ctx, domain.UserReassignment{FromUserID: "missing", ToUserID: third.ID, ToActor: third.Email, FromStatus: "disabled"})
+	expectNotFound(t, err, "transfer from missing user")
+	moved, err = repos.Users.TransferOwnership(ctx, domain.UserReassignment{
+		FromUserID: to.ID,
+		ToUserID:   third.ID,
+		FromActors: []string{to.Email},
+		ToActor:    third.Email,
+		FromStatus: "disabled",
+	})
+	must(t, err, "transfer ownership")
+	if moved["prompts"] != 2 || moved["prompt_owners"] != 1 || moved["user_identities"] != 0 {
+		t.Fatalf("unexpected transfer counts %v", moved)
+	}
+	identity, err = repos.UserIdentities.GetByProviderAndExternalID(ctx, "gitlab", "8")
+	must(t, err, "reload identity")
+	if identity.UserID != to.ID {
+		t.Fatalf("transfer must not move identities: %+v", identity)
+	}
+	if _, err := repos.Workspaces.GetMember(ctx, domain.DefaultWorkspaceID, to.ID); err != nil {
+		t.Fatalf("transfer must not move memberships: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+func testWorkspaces(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	owner := seedUser(t, repos, "owner@example.com")
+	member := seedUser(t, repos, "member@example.com")
+
+	// 迁移预置默认组织与默认工作区。
+	if _, err := repos.Workspaces.GetWorkspace(ctx, domain.DefaultWorkspaceID); err != nil {
+		t.Fatalf("default workspace should exist: %v", err)
+	}
+	orgs, err := repos.Workspaces.ListOrganizations(ctx)
+	must(t, err, "list organizations")
+	if len(orgs) != 1 {
+		t.Fatalf("expected only the default organization, got %d", len(orgs))
+	}
+
+	org := &domain.Organization{ID: newID("org"), Name: "Acme", Slug: "acme", CreatedBy: ptr(owner.Email)}
+	must(t, repos.Workspaces.CreateOrganization(ctx, org), "create organization")
+	expectError(t, repos.Workspaces.CreateOrganization(ctx, &domain.Organization{ID: newID("org"), Name: "Acme 2", Slug: "acme"}), "create duplicate organization slug")
+	stored, err := repos.Workspaces.GetOrganization(ctx, org.ID)
+	must(t, err, "get organization")
+	if stored.Name != "Acme" || stored.Slug != "acme" || stored.CreatedBy == nil || stored.CreatedAt.IsZero() {
+		t.Fatalf("unexpected organization %+v", stored)
+	}
+	_, err = repos.Workspaces.GetOrganization(ctx, "missing")
+	expectNotFound(t, err, "get missing organization")
+
+	// 创建工作区时同时写入负责人的成员关系。
+	workspace := &domain.Workspace{ID: newID("ws"), OrganizationID: org.ID, Name: "Research", Slug: "research"}
+	must(t, repos.Workspaces.CreateWorkspace(ctx, workspace, &domain.WorkspaceMember{WorkspaceID: workspace.ID, UserID: owner.ID, Role: "admin"}), "create workspace")
+	expectError(t, repos.Workspaces.CreateWorkspace(ctx, &domain.Workspace{ID: newID("ws"), OrganizationID: org.ID, Name: "Dup", Slug: "research"}, nil), "create duplicate workspace slug")
+	other := &domain.Workspace{ID: newID("ws"), OrganizationID: org.ID, Name: "Ops", Slug: "ops"}
+	must(t, repos.Workspaces.CreateWorkspace(ctx, other, nil), "create workspace without owner")
+
+	got, err := repos.Workspaces.GetWorkspace(ctx, workspace.ID)
+	must(t, err, "get workspace")
+	if got.OrganizationID != org.ID || got.Slug != "research" {
+		t.Fatalf("unexpected workspace %+v", got)
+	}
+	_, err = repos.Workspaces.GetWorkspace(ctx, "missing")
+	expectNotFound(t, err, "get missing workspace")
+
+	inOrg, err := repos.Workspaces.ListWorkspaces(ctx, org.ID)
+	must(t, err, "list workspaces of organization")
+	if len(inOrg) != 2 {
+		t.Fatalf("expected 2 workspaces in organization, got %d", len(inOrg))
+	}
+	all, err := repos.Workspaces.ListWorkspaces(ctx, "")
+	must(t, err, "list all workspaces")
+	if len(all) != 3 {
+		t.Fatalf("expected 3 workspaces in total, got %d", len(all))
+	}
+
+	forOwner, err := repos.Workspaces.ListWorkspacesForUser(ctx, owner.ID)
+	must(t, err, "list workspaces for owner")
+	if len(forOwner) != 1 || forOwner[0].ID != workspace.ID || forOwner[0].Role != "admin" {
+		t.Fatalf("expected owner to be admin of the new workspace, got %+v", forOwner)
+	}
+
+	// UpsertMember 覆盖已有成员的角色。
+	must(t, repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{WorkspaceID: workspace.ID, UserID: member.ID, Role: "viewer"}), "add member")
+	must(t, repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{WorkspaceID: workspace.ID, UserID: member.ID, Role: "editor"}), "change member role")
+	got2, err := repos.Workspaces.GetMember(ctx, workspace.ID, member.ID)
+	must(t, err, "get member")
+	if got2.Role != "editor" || got2.Email != member.Email {
+		t.Fatalf("unexpected member %+v", got2)
+	}
+	_, err = repos.Workspaces.GetMember(ctx, other.ID, member.ID)
+	expectNotFound(t, err, "get member of another workspace")
+
+	members, err := repos.Workspaces.ListMembers(ctx, workspace.ID)
+	must(t, err, "list members")
+	if len(members) != 2 {
+		t.Fatalf("expected 2 members, got %d", len(members))
+	}
+	for _, m := range members {
+		if m.Email == "" {
+			t.Fatalf("expected member email to be filled, got %+v", m)
+		}
+	}
+
+	must(t, repos.Workspaces.RemoveMember(ctx, workspace.ID, member.ID), "remove member")
+	expectNotFound(t, repos.Workspaces.RemoveMember(ctx, workspace.ID, member.ID), "remove member twice")
+	forMember, err := repos.Workspaces.ListWorkspacesForUser(ctx, member.ID)
+	must(t, err, "list workspaces for removed member")
+	if len(forMember) != 0 {
+		t.Fatalf("expected no workspaces after removal, got %d", len(forMember))
+	}
+}
+
+func testInvitations(t *testing.T, repos *domain.Repositories) {
+	ctx := context.Background()
+	invitee := seedUser(t, repos, "invitee@example.com")
+	now := minute(0)
+
+	pending := &domain.Invitation{ID: newID("inv"), Email: "invitee@example.com", Role: "editor", WorkspaceID: domain.DefaultWorkspaceID, TokenHash: "hash-pending", InvitedBy: ptr("admin"), ExpiresAt: now.Add(time.Hour)}
+	expired := &domain.Invitation{ID: newID("inv"), Email: "late@example.com", Role: "viewer", WorkspaceID: domain.DefaultWorkspaceID, TokenHash: "hash-expired", ExpiresAt: now.Add(-time.Hour)}
+	must(t, repos.Invitations.Create(ctx, pending), "create invitation")
+	must(t, repos.Invitations.Create(ctx, expired), "create expired invitation")
+	expectError(t, repos.Invitations.Create(ctx, &domain.Invitation{ID: newID("inv"), Email: "x@example.com", Role: "viewer", WorkspaceID: domain.DefaultWorkspaceID, TokenHash: "hash-pending", ExpiresAt: now}), "create duplicate token hash")
+
+	stored, err := repos.Invitations.GetByTokenHash(ctx, "hash-pending")
+	must(t, err, "get invitation")
+	if stored.ID != pending.ID || stored.Role != "editor" || stored.InvitedBy == nil || !stored.ExpiresAt.Equal(pending.ExpiresAt) || stored.AcceptedAt != nil {
+		t.Fatalf("unexpected invitation %+v", stored)
+	}
+	_, err = repos.Invitations.GetByTokenHash(ctx, "missing")
+	expectNotFound(t, err, "get missing invitation")
+
+	list, err := repos.Invitations.ListPending(ctx, now)
+	must(t, err, "list pending invitations")
+	if len(list) != 1 || list[0].ID != pending.ID {
+		t.Fatalf("expected only the unexpired invitation, got %d", len(list))
+	}
+
+	must(t, repos.Invitations.MarkAccepted(ctx, pending.ID, invitee.ID, now), "accept invitation")
+	expectNotFound(t, repos.Invitations.MarkAccepted(ctx, pending.ID, invitee.ID, now), "accept invitation twice")
+	expectNotFound(t, repos.Invitations.MarkAccepted(ctx, "missing", invitee.ID, now), "accept missing invitation")
+	stored, err = repos.Invitations.GetByTokenHash(ctx, "hash-pending")
+	must(t, err, "reload invitation")
+	if stored.AcceptedAt == nil || !stored.AcceptedAt.Equal(now) || stored.AcceptedUserID == nil || *stored.AcceptedUserID != invitee.ID {
+		t.Fatalf("acceptance not recorded: %+v", stored)
+	}
+	list, err = repos.Invitations.ListPending(ctx, now)
+	must(t, err, "list pending after acceptance")
+	if len(list) != 0 {
+		t.Fatalf("expected no pending invitations, got %d", len(list))
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 签名密钥仓储 ----
+
+type signingKeyRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func (r *signingKeyRepository) Rotate(ctx context.Context, key *domain.SigningKey, retiresAt time.Time) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if key.OrganizationID == "" {
+		key.OrganizationID = domain.DefaultOrganizationID
+	}
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	retireQuery := fmt.Sprintf(`UPDATE signing_keys SET status = 'retired', retires_at = %s WHERE status = 'active' AND organization_id = %s`, ph.Next(), ph.Next())
+	if _, err = tx.ExecContext(ctx, retireQuery, retiresAt, key.OrganizationID); err != nil {
+		return err
+	}
+
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	insertQuery := fmt.Sprintf(`INSERT INTO signing_keys (id, organization_id, algorithm, encrypted_key, status, created_by)
+VALUES (%s, %s, %s, %s, 'active', %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	createdBy := sql.NullString{}
+	if key.CreatedBy != nil {
+		createdBy = sql.NullString{String: *key.CreatedBy, Valid: true}
+	}
+	if _, err = tx.ExecContext(ctx, insertQuery, key.ID, key.OrganizationID, key.Algorithm, key.EncryptedKey, createdBy); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *signingKeyRepository) ListUsable(ctx context.Context, now time.Time) ([]*domain.SigningKey, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, organization_id, algorithm, encrypted_key, status, created_by, created_at, retires_at
+FROM signing_keys WHERE status = 'active' OR retires_at > %s ORDER BY created_at DESC`, ph.Next())
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.SigningKey
+	for rows.Next() {
+		var (
+			key       domain.SigningKey
+			createdBy sql.NullString
+			retiresAt sql.NullTime
+		)
+		if err := rows.Scan(&key.ID, &key.OrganizationID, &key.Algorithm, &key.EncryptedKey, &key.Status, &createdBy, &key.CreatedAt, &retiresAt); err != nil {
+			return nil, err
+		}
+		if createdBy.Valid {
+			key.CreatedBy = &createdBy.String
+		}
+		if retiresAt.Valid {
+			key.RetiresAt = &retiresAt.Time
+		}
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
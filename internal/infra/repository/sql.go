@@ -7,28 +7,81 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/zacharykka/prompt-manager/internal/domain"
 	"github.com/zacharykka/prompt-manager/internal/infra/database"
 )
 
+// SQLOption 自定义 SQL 仓储行为。
+type SQLOption func(*sqlOptions)
+
+type sqlOptions struct {
+	codec bodyCodec
+}
+
+// WithBodyCompression 对不小于 threshold 字节的 Prompt、版本与多语言正文启用 zstd 压缩存储；threshold <= 0 表示不压缩。
+// 读取时按魔数识别压缩内容，未压缩的历史数据不受影响。
+func WithBodyCompression(threshold int) SQLOption {
+	return func(o *sqlOptions) {
+		o.codec = bodyCodec{threshold: threshold}
+	}
+}
+
 // NewSQLRepositories 构建基于 *sql.DB 的仓储集合。
-func NewSQLRepositories(db *sql.DB, dialect database.Dialect) *domain.Repositories {
+func NewSQLRepositories(db *sql.DB, dialect database.Dialect, opts ...SQLOption) *domain.Repositories {
+	var options sqlOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	userRepo := &userRepository{db: db, dialect: dialect}
 	identityRepo := &userIdentityRepository{db: db, dialect: dialect}
-	promptRepo := &promptRepository{db: db, dialect: dialect}
-	promptVersionRepo := &promptVersionRepository{db: db, dialect: dialect}
+	stmts := newStmtCache(db)
+	promptRepo := &promptRepository{db: db, dialect: dialect, stmts: stmts, codec: options.codec}
+	promptVersionRepo := &promptVersionRepository{db: db, dialect: dialect, stmts: stmts, codec: options.codec}
+	localeRepo := &promptVersionLocaleRepository{db: db, dialect: dialect, codec: options.codec}
+	dependencyRepo := &promptDependencyRepository{db: db, dialect: dialect}
 	execLogRepo := &promptExecutionLogRepository{db: db, dialect: dialect}
 	auditRepo := &promptAuditLogRepository{db: db, dialect: dialect}
+	pipelineRepo := &pipelineRepository{db: db, dialect: dialect}
+	signingKeyRepo := &signingKeyRepository{db: db, dialect: dialect}
+	auditLogRepo := &auditLogRepository{db: db, dialect: dialect}
+	workspaceRepo := &workspaceRepository{db: db, dialect: dialect}
+	invitationRepo := &invitationRepository{db: db, dialect: dialect}
+	loginEventRepo := &loginEventRepository{db: db, dialect: dialect}
+	alertRuleRepo := &promptAlertRuleRepository{db: db, dialect: dialect}
+	templateRepo := &promptTemplateRepository{db: db, dialect: dialect}
+	draftRepo := &promptDraftRepository{db: db, dialect: dialect}
+	reviewRepo := &promptReviewRepository{db: db, dialect: dialect}
+	apiKeyRepo := &apiKeyRepository{db: db, dialect: dialect}
+	usageRepo := &usageRepository{db: db, dialect: dialect}
+	announcementRepo := &announcementRepository{db: db, dialect: dialect}
+	canaryRepo := &promptCanaryRepository{db: db, dialect: dialect}
 
 	return &domain.Repositories{
 		Users:              userRepo,
 		UserIdentities:     identityRepo,
 		Prompts:            promptRepo,
 		PromptVersions:     promptVersionRepo,
+		PromptLocales:      localeRepo,
+		PromptDependencies: dependencyRepo,
 		PromptExecutionLog: execLogRepo,
 		PromptAuditLog:     auditRepo,
+		Pipelines:          pipelineRepo,
+		SigningKeys:        signingKeyRepo,
+		AuditLogs:          auditLogRepo,
+		Workspaces:         workspaceRepo,
+		Invitations:        invitationRepo,
+		LoginEvents:        loginEventRepo,
+		PromptAlertRules:   alertRuleRepo,
+		PromptTemplates:    templateRepo,
+		PromptDrafts:       draftRepo,
+		PromptReviews:      reviewRepo,
+		APIKeys:            apiKeyRepo,
+		Usage:              usageRepo,
+		Announcements:      announcementRepo,
+		PromptCanaries:     canaryRepo,
 	}
 }
 
@@ -126,6 +179,98 @@ FROM users WHERE email = %s`, ph.Next())
 	return user, nil
 }
 
+func (r *userRepository) UpdateStatus(ctx context.Context, userID, status string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE users SET status = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s`, ph.Next(), ph.Next())
+
+	result, err := r.db.ExecContext(ctx, query, status, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userID, hashedPassword string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE users SET hashed_password = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s`, ph.Next(), ph.Next())
+
+	result, err := r.db.ExecContext(ctx, query, hashedPassword, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, userID, role string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE users SET role = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s`, ph.Next(), ph.Next())
+
+	result, err := r.db.ExecContext(ctx, query, role, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) ListByStatus(ctx context.Context, status string) ([]*domain.User, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, email, hashed_password, role, status, last_login_at, created_at, updated_at
+FROM users WHERE status = %s ORDER BY created_at ASC`, ph.Next())
+
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		var row userRow
+		if err := rows.Scan(&row.id, &row.email, &row.hashedPassword, &row.role, &row.status, &row.lastLoginAt, &row.createdAt, &row.updatedAt); err != nil {
+			return nil, err
+		}
+		user := &domain.User{
+			ID:             row.id,
+			Email:          row.email,
+			HashedPassword: row.hashedPassword,
+			Role:           row.role,
+			Status:         row.status,
+			CreatedAt:      row.createdAt,
+			UpdatedAt:      row.updatedAt,
+		}
+		if row.lastLoginAt.Valid {
+			lastLoginAt := row.lastLoginAt.Time
+			user.LastLoginAt = &lastLoginAt
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepository) UpdateLastLogin(ctx context.Context, userID string) error {
 	ph := database.NewPlaceholderBuilder(r.dialect)
 	query := fmt.Sprintf(`UPDATE users SET last_login_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = %s`, ph.Next())
@@ -185,14 +330,43 @@ func (r *userIdentityRepository) GetByProviderAndExternalID(ctx context.Context,
 	query := fmt.Sprintf(`SELECT id, user_id, provider, provider_user_id, provider_login, avatar_url, created_at, updated_at
 FROM user_identities WHERE provider = %s AND provider_user_id = %s`, ph.Next(), ph.Next())
 
-	var row userIdentityRow
-	err := r.db.QueryRowContext(ctx, query, provider, externalID).Scan(&row.id, &row.userID, &row.provider, &row.providerUserID, &row.providerLogin, &row.avatarURL, &row.createdAt, &row.updatedAt)
+	identity, err := scanUserIdentity(r.db.QueryRowContext(ctx, query, provider, externalID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
+	return identity, nil
+}
+
+func (r *userIdentityRepository) ListByUser(ctx context.Context, userID string) ([]*domain.UserIdentity, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, user_id, provider, provider_user_id, provider_login, avatar_url, created_at, updated_at
+FROM user_identities WHERE user_id = %s ORDER BY created_at ASC, id ASC`, ph.Next())
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*domain.UserIdentity
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, rows.Err()
+}
+
+func scanUserIdentity(scanner rowScanner) (*domain.UserIdentity, error) {
+	var row userIdentityRow
+	if err := scanner.Scan(&row.id, &row.userID, &row.provider, &row.providerUserID, &row.providerLogin, &row.avatarURL, &row.createdAt, &row.updatedAt); err != nil {
+		return nil, err
+	}
 
 	identity := &domain.UserIdentity{
 		ID:             row.id,
@@ -216,27 +390,129 @@ FROM user_identities WHERE provider = %s AND provider_user_id = %s`, ph.Next(),
 type promptRepository struct {
 	db      *sql.DB
 	dialect database.Dialect
+	// stmts 缓存 Create/GetByID/GetByName/List 等高频查询的预编译语句。
+	stmts *stmtCache
+	codec bodyCodec
 }
 
+// promptColumns 为 Prompt 查询的列顺序，与 scanPrompt 的扫描目标一一对应。
+const promptColumns = `p.id, p.name, p.description, p.tags, p.active_version_id, p.previous_active_version_id, p.body, p.created_by, u.email, p.status, p.deleted_at, p.render_mode, p.owner_type, p.owner_id, p.workspace_id, p.created_at, p.updated_at`
+
+// promptColumnsWithoutBody 以 NULL 占位正文列，列顺序与 promptColumns 一致，供 OmitBody 的列表查询使用。
+var promptColumnsWithoutBody = strings.Replace(promptColumns, "p.body,", "NULL,", 1)
+
+// maxPromptListPrealloc 限制 List 预分配的结果容量，避免超大 limit 导致一次性分配。
+const maxPromptListPrealloc = 1000
+
+// promptRow 是扫描 Prompt 行的中间结构，dest 预先绑定各字段地址，经 promptRowPool 复用，
+// 避免逐行分配扫描缓冲与参数切片。字符串在映射时按值拷出，归还后不会与返回的 Prompt 共享内存。
 type promptRow struct {
-	id              string
-	name            string
-	description     sql.NullString
-	tags            sql.NullString
-	activeVersionID sql.NullString
-	body            sql.NullString
-	createdBy       sql.NullString
-	createdByEmail  sql.NullString
-	status          string
-	deletedAt       sql.NullTime
-	createdAt       time.Time
-	updatedAt       time.Time
+	id                      string
+	name                    string
+	description             sql.NullString
+	tags                    []byte
+	activeVersionID         sql.NullString
+	previousActiveVersionID sql.NullString
+	body                    sql.NullString
+	createdBy               sql.NullString
+	createdByEmail          sql.NullString
+	status                  string
+	deletedAt               sql.NullTime
+	renderMode              sql.NullString
+	ownerType               sql.NullString
+	ownerID                 sql.NullString
+	workspaceID             string
+	createdAt               time.Time
+	updatedAt               time.Time
+
+	dest []interface{}
+}
+
+var promptRowPool = sync.Pool{
+	New: func() interface{} {
+		row := &promptRow{}
+		row.dest = []interface{}{&row.id, &row.name, &row.description, &row.tags, &row.activeVersionID, &row.previousActiveVersionID, &row.body, &row.createdBy, &row.createdByEmail, &row.status, &row.deletedAt, &row.renderMode, &row.ownerType, &row.ownerID, &row.workspaceID, &row.createdAt, &row.updatedAt}
+		return row
+	},
+}
+
+// promptRecord 将 Prompt 与其可空字段的存储放在同一块内存中，每行只需一次分配。
+type promptRecord struct {
+	prompt                  domain.Prompt
+	description             string
+	activeVersionID         string
+	previousActiveVersionID string
+	body                    string
+	createdBy               string
+	renderMode              string
+	deletedAt               time.Time
+	owner                   domain.PromptOwner
+}
+
+// scanPrompt 按 promptColumns 的列顺序扫描一行并映射为领域对象。
+func scanPrompt(scanner rowScanner) (*domain.Prompt, error) {
+	row := promptRowPool.Get().(*promptRow)
+	defer func() {
+		// tags 由驱动重新分配并移交给返回的 Prompt，归还前断开引用。
+		row.tags = nil
+		promptRowPool.Put(row)
+	}()
+	if err := scanner.Scan(row.dest...); err != nil {
+		return nil, err
+	}
+
+	rec := &promptRecord{}
+	prompt := &rec.prompt
+	prompt.ID = row.id
+	prompt.Name = row.name
+	prompt.CreatedAt = row.createdAt
+	prompt.UpdatedAt = row.updatedAt
+	prompt.Status = row.status
+	prompt.WorkspaceID = row.workspaceID
+	if row.tags != nil {
+		prompt.Tags = json.RawMessage(row.tags)
+	}
+	prompt.Description = stringInto(row.description, &rec.description)
+	prompt.ActiveVersionID = stringInto(row.activeVersionID, &rec.activeVersionID)
+	prompt.PreviousActiveVersionID = stringInto(row.previousActiveVersionID, &rec.previousActiveVersionID)
+	prompt.Body = stringInto(row.body, &rec.body)
+	if prompt.Body != nil {
+		body, err := decodeBody(rec.body)
+		if err != nil {
+			return nil, err
+		}
+		rec.body = body
+	}
+	prompt.RenderMode = stringInto(row.renderMode, &rec.renderMode)
+	if row.createdByEmail.Valid {
+		prompt.CreatedBy = stringInto(row.createdByEmail, &rec.createdBy)
+	} else {
+		prompt.CreatedBy = stringInto(row.createdBy, &rec.createdBy)
+	}
+	if row.deletedAt.Valid {
+		rec.deletedAt = row.deletedAt.Time
+		prompt.DeletedAt = &rec.deletedAt
+	}
+	if row.ownerType.Valid && row.ownerID.Valid {
+		rec.owner = domain.PromptOwner{Type: row.ownerType.String, ID: row.ownerID.String}
+		prompt.Owner = &rec.owner
+	}
+	return prompt, nil
+}
+
+// stringInto 将有效的可空字符串拷贝到 dst 并返回其地址，无效时返回 nil。
+func stringInto(value sql.NullString, dst *string) *string {
+	if !value.Valid {
+		return nil
+	}
+	*dst = value.String
+	return dst
 }
 
 func (r *promptRepository) Create(ctx context.Context, prompt *domain.Prompt) error {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`INSERT INTO prompts (id, name, description, tags, active_version_id, body, created_by)
-VALUES (%s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	query := fmt.Sprintf(`INSERT INTO prompts (id, name, description, tags, active_version_id, body, created_by, owner_type, owner_id, workspace_id)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
 
 	desc := sql.NullString{}
 	if prompt.Description != nil {
@@ -250,114 +526,70 @@ VALUES (%s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next()
 	if prompt.ActiveVersionID != nil {
 		active = sql.NullString{String: *prompt.ActiveVersionID, Valid: true}
 	}
-	body := sql.NullString{}
-	if prompt.Body != nil {
-		body = sql.NullString{String: *prompt.Body, Valid: true}
-	}
+	body := r.codec.encodeNullable(prompt.Body)
 	createdBy := sql.NullString{}
 	if prompt.CreatedBy != nil {
 		createdBy = sql.NullString{String: *prompt.CreatedBy, Valid: true}
 	}
 
-	_, err := r.db.ExecContext(ctx, query, prompt.ID, prompt.Name, desc, tags, active, body, createdBy)
+	if prompt.WorkspaceID == "" {
+		prompt.WorkspaceID = domain.WorkspaceFromContext(ctx)
+	}
+	if prompt.WorkspaceID == "" {
+		prompt.WorkspaceID = domain.DefaultWorkspaceID
+	}
+
+	ownerType, ownerID := sql.NullString{}, sql.NullString{}
+	if prompt.Owner != nil {
+		ownerType = sql.NullString{String: prompt.Owner.Type, Valid: true}
+		ownerID = sql.NullString{String: prompt.Owner.ID, Valid: true}
+	}
+
+	_, err := r.stmts.ExecContext(ctx, query, prompt.ID, prompt.Name, desc, tags, active, body, createdBy, ownerType, ownerID, prompt.WorkspaceID)
 	return err
 }
 
 func (r *promptRepository) GetByID(ctx context.Context, promptID string) (*domain.Prompt, error) {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT p.id, p.name, p.description, p.tags, p.active_version_id, p.body, p.created_by, u.email, p.status, p.deleted_at, p.created_at, p.updated_at
+	query := fmt.Sprintf(`SELECT `+promptColumns+`
 FROM prompts p
 LEFT JOIN users u ON p.created_by = u.id
 WHERE p.id = %s AND p.deleted_at IS NULL`, ph.Next())
+	args := []interface{}{promptID}
+	query, args = scopeToWorkspace(ctx, ph, query, args)
 
-	var row promptRow
-	err := r.db.QueryRowContext(ctx, query, promptID).Scan(&row.id, &row.name, &row.description, &row.tags, &row.activeVersionID, &row.body, &row.createdBy, &row.createdByEmail, &row.status, &row.deletedAt, &row.createdAt, &row.updatedAt)
+	prompt, err := scanPrompt(r.stmts.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
-
-	prompt := &domain.Prompt{
-		ID:        row.id,
-		Name:      row.name,
-		CreatedAt: row.createdAt,
-		UpdatedAt: row.updatedAt,
-		Status:    row.status,
-	}
-	if row.description.Valid {
-		prompt.Description = &row.description.String
-	}
-	if row.tags.Valid {
-		prompt.Tags = json.RawMessage(row.tags.String)
-	}
-	if row.activeVersionID.Valid {
-		prompt.ActiveVersionID = &row.activeVersionID.String
-	}
-	if row.body.Valid {
-		prompt.Body = &row.body.String
-	}
-	if row.createdByEmail.Valid {
-		prompt.CreatedBy = &row.createdByEmail.String
-	} else if row.createdBy.Valid {
-		prompt.CreatedBy = &row.createdBy.String
-	}
-	if row.deletedAt.Valid {
-		prompt.DeletedAt = &row.deletedAt.Time
-	}
 	return prompt, nil
 }
 
 func (r *promptRepository) GetByIDIncludeDeleted(ctx context.Context, promptID string) (*domain.Prompt, error) {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT p.id, p.name, p.description, p.tags, p.active_version_id, p.body, p.created_by, u.email, p.status, p.deleted_at, p.created_at, p.updated_at
+	query := fmt.Sprintf(`SELECT `+promptColumns+`
 FROM prompts p
 LEFT JOIN users u ON p.created_by = u.id
 WHERE p.id = %s`, ph.Next())
+	args := []interface{}{promptID}
+	query, args = scopeToWorkspace(ctx, ph, query, args)
 
-	var row promptRow
-	err := r.db.QueryRowContext(ctx, query, promptID).Scan(&row.id, &row.name, &row.description, &row.tags, &row.activeVersionID, &row.body, &row.createdBy, &row.createdByEmail, &row.status, &row.deletedAt, &row.createdAt, &row.updatedAt)
+	prompt, err := scanPrompt(r.stmts.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
-
-	prompt := &domain.Prompt{
-		ID:        row.id,
-		Name:      row.name,
-		CreatedAt: row.createdAt,
-		UpdatedAt: row.updatedAt,
-		Status:    row.status,
-	}
-	if row.description.Valid {
-		prompt.Description = &row.description.String
-	}
-	if row.tags.Valid {
-		prompt.Tags = json.RawMessage(row.tags.String)
-	}
-	if row.activeVersionID.Valid {
-		prompt.ActiveVersionID = &row.activeVersionID.String
-	}
-	if row.body.Valid {
-		prompt.Body = &row.body.String
-	}
-	if row.createdByEmail.Valid {
-		prompt.CreatedBy = &row.createdByEmail.String
-	} else if row.createdBy.Valid {
-		prompt.CreatedBy = &row.createdBy.String
-	}
-	if row.deletedAt.Valid {
-		prompt.DeletedAt = &row.deletedAt.Time
-	}
 	return prompt, nil
 }
 
 func (r *promptRepository) GetByName(ctx context.Context, name string, includeDeleted bool) (*domain.Prompt, error) {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT p.id, p.name, p.description, p.tags, p.active_version_id, p.body, p.created_by, u.email, p.status, p.deleted_at, p.created_at, p.updated_at
+	query := fmt.Sprintf(`SELECT `+promptColumns+`
 FROM prompts p
 LEFT JOIN users u ON p.created_by = u.id
 WHERE LOWER(p.name) = LOWER(%s)`, ph.Next())
@@ -365,43 +597,16 @@ WHERE LOWER(p.name) = LOWER(%s)`, ph.Next())
 	if !includeDeleted {
 		query += " AND p.deleted_at IS NULL"
 	}
+	args := []interface{}{name}
+	query, args = scopeToWorkspace(ctx, ph, query, args)
 
-	var row promptRow
-	err := r.db.QueryRowContext(ctx, query, name).Scan(&row.id, &row.name, &row.description, &row.tags, &row.activeVersionID, &row.body, &row.createdBy, &row.createdByEmail, &row.status, &row.deletedAt, &row.createdAt, &row.updatedAt)
+	prompt, err := scanPrompt(r.stmts.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
-
-	prompt := &domain.Prompt{
-		ID:        row.id,
-		Name:      row.name,
-		CreatedAt: row.createdAt,
-		UpdatedAt: row.updatedAt,
-		Status:    row.status,
-	}
-	if row.description.Valid {
-		prompt.Description = &row.description.String
-	}
-	if row.tags.Valid {
-		prompt.Tags = json.RawMessage(row.tags.String)
-	}
-	if row.activeVersionID.Valid {
-		prompt.ActiveVersionID = &row.activeVersionID.String
-	}
-	if row.body.Valid {
-		prompt.Body = &row.body.String
-	}
-	if row.createdByEmail.Valid {
-		prompt.CreatedBy = &row.createdByEmail.String
-	} else if row.createdBy.Valid {
-		prompt.CreatedBy = &row.createdBy.String
-	}
-	if row.deletedAt.Valid {
-		prompt.DeletedAt = &row.deletedAt.Time
-	}
 	return prompt, nil
 }
 
@@ -421,16 +626,37 @@ func (r *promptRepository) List(ctx context.Context, opts domain.PromptListOptio
 	var args []interface{}
 	var conditions []string
 
-	builder.WriteString(`SELECT p.id, p.name, p.description, p.tags, p.active_version_id, p.body, p.created_by, u.email, p.status, p.deleted_at, p.created_at, p.updated_at FROM prompts p`)
+	columns := promptColumns
+	if opts.OmitBody {
+		columns = promptColumnsWithoutBody
+	}
+	builder.WriteString(`SELECT ` + columns + ` FROM prompts p`)
 	builder.WriteString(" LEFT JOIN users u ON p.created_by = u.id")
 
 	if !opts.IncludeDeleted {
 		conditions = append(conditions, "p.deleted_at IS NULL")
 	}
+	conditions = append(conditions, archivedCondition(opts)...)
 	if search != "" {
 		conditions = append(conditions, fmt.Sprintf("LOWER(p.name) LIKE %s", ph.Next()))
 		args = append(args, fmt.Sprintf("%%%s%%", search))
 	}
+	if workspaceID := listWorkspace(ctx, opts); workspaceID != "" {
+		conditions = append(conditions, fmt.Sprintf("p.workspace_id = %s", ph.Next()))
+		args = append(args, workspaceID)
+	}
+	if len(opts.CreatedBy) > 0 {
+		conditions = append(conditions, createdByCondition(ph, len(opts.CreatedBy)))
+		for _, creator := range opts.CreatedBy {
+			args = append(args, creator)
+		}
+	}
+	if len(opts.TargetModels) > 0 {
+		conditions = append(conditions, targetModelsCondition(ph, len(opts.TargetModels)))
+		for _, model := range opts.TargetModels {
+			args = append(args, jsonElementPattern(model))
+		}
+	}
 
 	if len(conditions) > 0 {
 		builder.WriteString(" WHERE ")
@@ -444,45 +670,18 @@ func (r *promptRepository) List(ctx context.Context, opts domain.PromptListOptio
 
 	args = append(args, limit, offset)
 
-	rows, err := r.db.QueryContext(ctx, builder.String(), args...)
+	rows, err := r.stmts.QueryContext(ctx, builder.String(), args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var prompts []*domain.Prompt
+	prompts := make([]*domain.Prompt, 0, min(limit, maxPromptListPrealloc))
 	for rows.Next() {
-		var row promptRow
-		if err := rows.Scan(&row.id, &row.name, &row.description, &row.tags, &row.activeVersionID, &row.body, &row.createdBy, &row.createdByEmail, &row.status, &row.deletedAt, &row.createdAt, &row.updatedAt); err != nil {
+		prompt, err := scanPrompt(rows)
+		if err != nil {
 			return nil, err
 		}
-		prompt := &domain.Prompt{
-			ID:        row.id,
-			Name:      row.name,
-			CreatedAt: row.createdAt,
-			UpdatedAt: row.updatedAt,
-			Status:    row.status,
-		}
-		if row.description.Valid {
-			prompt.Description = &row.description.String
-		}
-		if row.tags.Valid {
-			prompt.Tags = json.RawMessage(row.tags.String)
-		}
-		if row.activeVersionID.Valid {
-			prompt.ActiveVersionID = &row.activeVersionID.String
-		}
-		if row.body.Valid {
-			prompt.Body = &row.body.String
-		}
-		if row.createdByEmail.Valid {
-			prompt.CreatedBy = &row.createdByEmail.String
-		} else if row.createdBy.Valid {
-			prompt.CreatedBy = &row.createdBy.String
-		}
-		if row.deletedAt.Valid {
-			prompt.DeletedAt = &row.deletedAt.Time
-		}
 		prompts = append(prompts, prompt)
 	}
 	if err := rows.Err(); err != nil {
@@ -491,20 +690,73 @@ func (r *promptRepository) List(ctx context.Context, opts domain.PromptListOptio
 	return prompts, nil
 }
 
+// archivedCondition 根据列表选项生成归档状态过滤条件。
+func archivedCondition(opts domain.PromptListOptions) []string {
+	switch {
+	case opts.ArchivedOnly:
+		return []string{fmt.Sprintf("p.status = '%s'", domain.PromptStatusArchived)}
+	case opts.IncludeArchived:
+		return nil
+	default:
+		return []string{fmt.Sprintf("p.status <> '%s'", domain.PromptStatusArchived)}
+	}
+}
+
+// scopeToWorkspace 在上下文携带工作区时为 Prompt 或 Pipeline 查询（表别名 p）追加工作区条件，跨工作区访问表现为记录不存在。
+func scopeToWorkspace(ctx context.Context, ph *database.PlaceholderBuilder, query string, args []interface{}) (string, []interface{}) {
+	workspaceID := domain.WorkspaceFromContext(ctx)
+	if workspaceID == "" {
+		return query, args
+	}
+	return query + fmt.Sprintf(" AND p.workspace_id = %s", ph.Next()), append(args, workspaceID)
+}
+
+// createdByCondition 生成按创建人过滤的 IN 条件。
+func createdByCondition(ph *database.PlaceholderBuilder, count int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = ph.Next()
+	}
+	return fmt.Sprintf("p.created_by IN (%s)", strings.Join(placeholders, ", "))
+}
+
+// targetModelsCondition 生成按激活版本目标模型过滤的条件。target_models 为 JSON 字符串数组，
+// 按带引号的完整元素匹配，避免 gpt-4 命中 gpt-4o。
+func targetModelsCondition(ph *database.PlaceholderBuilder, count int) string {
+	matches := make([]string, count)
+	for i := range matches {
+		matches[i] = fmt.Sprintf(`v.target_models LIKE %s ESCAPE '\'`, ph.Next())
+	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM prompt_versions v WHERE v.id = p.active_version_id AND (%s))", strings.Join(matches, " OR "))
+}
+
+// jsonElementPattern 返回匹配 JSON 中某个带引号字符串的 LIKE 模式，转义其中的通配符。
+func jsonElementPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+	return `%"` + escaped + `"%`
+}
+
+// listWorkspace 返回列表查询的工作区条件，显式指定的 WorkspaceID 优先于上下文。
+func listWorkspace(ctx context.Context, opts domain.PromptListOptions) string {
+	if opts.WorkspaceID != "" {
+		return opts.WorkspaceID
+	}
+	return domain.WorkspaceFromContext(ctx)
+}
+
 func (r *promptRepository) UpdateActiveVersion(ctx context.Context, promptID string, versionID *string, body *string) error {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`UPDATE prompts SET active_version_id = %s, body = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s AND deleted_at IS NULL`, ph.Next(), ph.Next(), ph.Next())
+	// 切换到不同版本时把原激活版本记入 previous_active_version_id，重复激活同一版本不覆盖回滚目标。
+	query := fmt.Sprintf(`UPDATE prompts SET previous_active_version_id = CASE
+	WHEN active_version_id IS NOT NULL AND active_version_id <> COALESCE(%s, '') THEN active_version_id
+	ELSE previous_active_version_id END,
+active_version_id = %s, body = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s AND deleted_at IS NULL`, ph.Next(), ph.Next(), ph.Next(), ph.Next())
 
 	active := sql.NullString{}
 	if versionID != nil {
 		active = sql.NullString{String: *versionID, Valid: true}
 	}
-	bodyValue := sql.NullString{}
-	if body != nil {
-		bodyValue = sql.NullString{String: *body, Valid: true}
-	}
-
-	result, err := r.db.ExecContext(ctx, query, active, bodyValue, promptID)
+	result, err := r.db.ExecContext(ctx, query, active, active, r.codec.encodeNullable(body), promptID)
 	if err != nil {
 		return err
 	}
@@ -529,10 +781,27 @@ func (r *promptRepository) Count(ctx context.Context, opts domain.PromptListOpti
 	if !opts.IncludeDeleted {
 		conditions = append(conditions, "p.deleted_at IS NULL")
 	}
+	conditions = append(conditions, archivedCondition(opts)...)
 	if search != "" {
 		conditions = append(conditions, fmt.Sprintf("LOWER(p.name) LIKE %s", ph.Next()))
 		args = append(args, fmt.Sprintf("%%%s%%", search))
 	}
+	if workspaceID := listWorkspace(ctx, opts); workspaceID != "" {
+		conditions = append(conditions, fmt.Sprintf("p.workspace_id = %s", ph.Next()))
+		args = append(args, workspaceID)
+	}
+	if len(opts.CreatedBy) > 0 {
+		conditions = append(conditions, createdByCondition(ph, len(opts.CreatedBy)))
+		for _, creator := range opts.CreatedBy {
+			args = append(args, creator)
+		}
+	}
+	if len(opts.TargetModels) > 0 {
+		conditions = append(conditions, targetModelsCondition(ph, len(opts.TargetModels)))
+		for _, model := range opts.TargetModels {
+			args = append(args, jsonElementPattern(model))
+		}
+	}
 	if len(conditions) > 0 {
 		builder.WriteString(" WHERE ")
 		builder.WriteString(strings.Join(conditions, " AND "))
@@ -545,6 +814,26 @@ func (r *promptRepository) Count(ctx context.Context, opts domain.PromptListOpti
 	return total, nil
 }
 
+// EstimateCount 读取 Postgres 的 pg_class.reltuples，避免大表上的全表 COUNT；
+// SQLite 无对应统计，未执行过 ANALYZE 时 reltuples 为 -1（PG14 之前为 0）。
+func (r *promptRepository) EstimateCount(ctx context.Context) (int64, bool, error) {
+	if !r.dialect.IsPostgres() {
+		return 0, false, nil
+	}
+	var estimate float64
+	err := r.db.QueryRowContext(ctx, `SELECT reltuples FROM pg_class WHERE oid = to_regclass('prompts')`).Scan(&estimate)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if estimate <= 0 {
+		return 0, false, nil
+	}
+	return int64(estimate), true, nil
+}
+
 func (r *promptRepository) Update(ctx context.Context, promptID string, params domain.PromptUpdateParams) error {
 	ph := database.NewPlaceholderBuilder(r.dialect)
 	var sets []string
@@ -573,6 +862,14 @@ func (r *promptRepository) Update(ctx context.Context, promptID string, params d
 		sets = append(sets, fmt.Sprintf("tags = %s", ph.Next()))
 		args = append(args, tags)
 	}
+	if params.HasRenderMode {
+		mode := sql.NullString{}
+		if params.RenderMode != nil {
+			mode = sql.NullString{String: *params.RenderMode, Valid: true}
+		}
+		sets = append(sets, fmt.Sprintf("render_mode = %s", ph.Next()))
+		args = append(args, mode)
+	}
 
 	if len(sets) == 0 {
 		return nil
@@ -651,12 +948,8 @@ func (r *promptRepository) Restore(ctx context.Context, promptID string, params
 	}
 
 	if params.HasBody {
-		body := sql.NullString{}
-		if params.Body != nil {
-			body = sql.NullString{String: *params.Body, Valid: true}
-		}
 		sets = append(sets, fmt.Sprintf("body = %s", ph.Next()))
-		args = append(args, body)
+		args = append(args, r.codec.encodeNullable(params.Body))
 	}
 
 	query := fmt.Sprintf("UPDATE prompts SET %s WHERE id = %s AND status = 'deleted'", strings.Join(sets, ", "), ph.Next())
@@ -676,11 +969,54 @@ func (r *promptRepository) Restore(ctx context.Context, promptID string, params
 	return nil
 }
 
+func (r *promptRepository) UpdateOwner(ctx context.Context, promptID string, owner *domain.PromptOwner) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE prompts SET owner_type = %s, owner_id = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s AND deleted_at IS NULL`, ph.Next(), ph.Next(), ph.Next())
+
+	ownerType, ownerID := sql.NullString{}, sql.NullString{}
+	if owner != nil {
+		ownerType = sql.NullString{String: owner.Type, Valid: true}
+		ownerID = sql.NullString{String: owner.ID, Valid: true}
+	}
+	result, err := r.db.ExecContext(ctx, query, ownerType, ownerID, promptID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptRepository) UpdateStatus(ctx context.Context, promptID, from, to string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE prompts SET status = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s AND status = %s AND deleted_at IS NULL`, ph.Next(), ph.Next(), ph.Next())
+
+	result, err := r.db.ExecContext(ctx, query, to, promptID, from)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 // ---- Prompt Version 仓储 ----
 
 type promptVersionRepository struct {
 	db      *sql.DB
 	dialect database.Dialect
+	stmts   *stmtCache
+	codec   bodyCodec
 }
 
 type promptVersionRow struct {
@@ -691,23 +1027,70 @@ type promptVersionRow struct {
 	variablesSchema sql.NullString
 	status          string
 	metadata        sql.NullString
+	targetModels    sql.NullString
 	createdBy       sql.NullString
 	createdAt       time.Time
 }
 
-func (r *promptVersionRepository) Create(ctx context.Context, version *domain.PromptVersion) error {
-	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`INSERT INTO prompt_versions (id, prompt_id, version_number, body, variables_schema, status, metadata, created_by)
-VALUES (%s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+// promptVersionColumns 为版本查询的列顺序，与 scanPromptVersion 的扫描目标一一对应。
+const promptVersionColumns = `id, prompt_id, version_number, body, variables_schema, status, metadata, target_models, created_by, created_at`
 
-	variables := sql.NullString{}
-	if len(version.VariablesSchema) > 0 {
-		variables = sql.NullString{String: string(version.VariablesSchema), Valid: true}
+// scanPromptVersion 按 promptVersionColumns 的列顺序扫描一行版本记录。
+func scanPromptVersion(scanner rowScanner) (*domain.PromptVersion, error) {
+	var row promptVersionRow
+	if err := scanner.Scan(&row.id, &row.promptID, &row.versionNumber, &row.body, &row.variablesSchema, &row.status, &row.metadata, &row.targetModels, &row.createdBy, &row.createdAt); err != nil {
+		return nil, err
 	}
-	metadata := sql.NullString{}
+	body, err := decodeBody(row.body)
+	if err != nil {
+		return nil, err
+	}
+	version := &domain.PromptVersion{
+		ID:            row.id,
+		PromptID:      row.promptID,
+		VersionNumber: row.versionNumber,
+		Body:          body,
+		Status:        row.status,
+		CreatedAt:     row.createdAt,
+	}
+	if row.variablesSchema.Valid {
+		version.VariablesSchema = json.RawMessage(row.variablesSchema.String)
+	}
+	if row.metadata.Valid {
+		version.Metadata = json.RawMessage(row.metadata.String)
+	}
+	if row.targetModels.Valid {
+		if err := json.Unmarshal([]byte(row.targetModels.String), &version.TargetModels); err != nil {
+			return nil, fmt.Errorf("decode target models: %w", err)
+		}
+	}
+	if row.createdBy.Valid {
+		version.CreatedBy = &row.createdBy.String
+	}
+	return version, nil
+}
+
+func (r *promptVersionRepository) Create(ctx context.Context, version *domain.PromptVersion) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO prompt_versions (id, prompt_id, version_number, body, variables_schema, status, metadata, target_models, created_by)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	variables := sql.NullString{}
+	if len(version.VariablesSchema) > 0 {
+		variables = sql.NullString{String: string(version.VariablesSchema), Valid: true}
+	}
+	metadata := sql.NullString{}
 	if len(version.Metadata) > 0 {
 		metadata = sql.NullString{String: string(version.Metadata), Valid: true}
 	}
+	targetModels := sql.NullString{}
+	if len(version.TargetModels) > 0 {
+		data, err := json.Marshal(version.TargetModels)
+		if err != nil {
+			return err
+		}
+		targetModels = sql.NullString{String: string(data), Valid: true}
+	}
 	createdBy := sql.NullString{}
 	if version.CreatedBy != nil {
 		createdBy = sql.NullString{String: *version.CreatedBy, Valid: true}
@@ -718,41 +1101,57 @@ VALUES (%s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Ne
 		status = "draft"
 	}
 
-	_, err := r.db.ExecContext(ctx, query, version.ID, version.PromptID, version.VersionNumber, version.Body, variables, status, metadata, createdBy)
+	_, err := r.stmts.ExecContext(ctx, query, version.ID, version.PromptID, version.VersionNumber, r.codec.encode(version.Body), variables, status, metadata, targetModels, createdBy)
 	return err
 }
 
+func (r *promptVersionRepository) MetadataByIDs(ctx context.Context, versionIDs []string) (map[string]json.RawMessage, error) {
+	metadata := make(map[string]json.RawMessage, len(versionIDs))
+	if len(versionIDs) == 0 {
+		return metadata, nil
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	placeholders := make([]string, len(versionIDs))
+	args := make([]interface{}, len(versionIDs))
+	for i, id := range versionIDs {
+		placeholders[i] = ph.Next()
+		args[i] = id
+	}
+	query := fmt.Sprintf(`SELECT id, metadata FROM prompt_versions WHERE id IN (%s) AND metadata IS NOT NULL`, strings.Join(placeholders, ", "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id   string
+			data string
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		metadata[id] = json.RawMessage(data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
 func (r *promptVersionRepository) GetByID(ctx context.Context, versionID string) (*domain.PromptVersion, error) {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT id, prompt_id, version_number, body, variables_schema, status, metadata, created_by, created_at
+	query := fmt.Sprintf(`SELECT `+promptVersionColumns+`
 FROM prompt_versions WHERE id = %s`, ph.Next())
 
-	var row promptVersionRow
-	err := r.db.QueryRowContext(ctx, query, versionID).Scan(&row.id, &row.promptID, &row.versionNumber, &row.body, &row.variablesSchema, &row.status, &row.metadata, &row.createdBy, &row.createdAt)
+	version, err := scanPromptVersion(r.stmts.QueryRowContext(ctx, query, versionID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
-
-	version := &domain.PromptVersion{
-		ID:            row.id,
-		PromptID:      row.promptID,
-		VersionNumber: row.versionNumber,
-		Body:          row.body,
-		Status:        row.status,
-		CreatedAt:     row.createdAt,
-	}
-	if row.variablesSchema.Valid {
-		version.VariablesSchema = json.RawMessage(row.variablesSchema.String)
-	}
-	if row.metadata.Valid {
-		version.Metadata = json.RawMessage(row.metadata.String)
-	}
-	if row.createdBy.Valid {
-		version.CreatedBy = &row.createdBy.String
-	}
 	return version, nil
 }
 
@@ -764,7 +1163,7 @@ func (r *promptVersionRepository) ListByPrompt(ctx context.Context, promptID str
 		offset = 0
 	}
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT id, prompt_id, version_number, body, variables_schema, status, metadata, created_by, created_at
+	query := fmt.Sprintf(`SELECT `+promptVersionColumns+`
 FROM prompt_versions WHERE prompt_id = %s ORDER BY version_number DESC LIMIT %s OFFSET %s`, ph.Next(), ph.Next(), ph.Next())
 
 	rows, err := r.db.QueryContext(ctx, query, promptID, limit, offset)
@@ -775,27 +1174,10 @@ FROM prompt_versions WHERE prompt_id = %s ORDER BY version_number DESC LIMIT %s
 
 	var versions []*domain.PromptVersion
 	for rows.Next() {
-		var row promptVersionRow
-		if err := rows.Scan(&row.id, &row.promptID, &row.versionNumber, &row.body, &row.variablesSchema, &row.status, &row.metadata, &row.createdBy, &row.createdAt); err != nil {
+		version, err := scanPromptVersion(rows)
+		if err != nil {
 			return nil, err
 		}
-		version := &domain.PromptVersion{
-			ID:            row.id,
-			PromptID:      row.promptID,
-			VersionNumber: row.versionNumber,
-			Body:          row.body,
-			Status:        row.status,
-			CreatedAt:     row.createdAt,
-		}
-		if row.variablesSchema.Valid {
-			version.VariablesSchema = json.RawMessage(row.variablesSchema.String)
-		}
-		if row.metadata.Valid {
-			version.Metadata = json.RawMessage(row.metadata.String)
-		}
-		if row.createdBy.Valid {
-			version.CreatedBy = &row.createdBy.String
-		}
 		versions = append(versions, version)
 	}
 	if err := rows.Err(); err != nil {
@@ -813,7 +1195,7 @@ func (r *promptVersionRepository) ListByPromptAndStatus(ctx context.Context, pro
 		offset = 0
 	}
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT id, prompt_id, version_number, body, variables_schema, status, metadata, created_by, created_at
+	query := fmt.Sprintf(`SELECT `+promptVersionColumns+`
 FROM prompt_versions WHERE prompt_id = %s AND status = %s ORDER BY version_number DESC LIMIT %s OFFSET %s`, ph.Next(), ph.Next(), ph.Next(), ph.Next())
 
 	rows, err := r.db.QueryContext(ctx, query, promptID, status, limit, offset)
@@ -824,27 +1206,10 @@ FROM prompt_versions WHERE prompt_id = %s AND status = %s ORDER BY version_numbe
 
 	var versions []*domain.PromptVersion
 	for rows.Next() {
-		var row promptVersionRow
-		if err := rows.Scan(&row.id, &row.promptID, &row.versionNumber, &row.body, &row.variablesSchema, &row.status, &row.metadata, &row.createdBy, &row.createdAt); err != nil {
+		version, err := scanPromptVersion(rows)
+		if err != nil {
 			return nil, err
 		}
-		version := &domain.PromptVersion{
-			ID:            row.id,
-			PromptID:      row.promptID,
-			VersionNumber: row.versionNumber,
-			Body:          row.body,
-			Status:        row.status,
-			CreatedAt:     row.createdAt,
-		}
-		if row.variablesSchema.Valid {
-			version.VariablesSchema = json.RawMessage(row.variablesSchema.String)
-		}
-		if row.metadata.Valid {
-			version.Metadata = json.RawMessage(row.metadata.String)
-		}
-		if row.createdBy.Valid {
-			version.CreatedBy = &row.createdBy.String
-		}
 		versions = append(versions, version)
 	}
 	if err := rows.Err(); err != nil {
@@ -853,6 +1218,35 @@ FROM prompt_versions WHERE prompt_id = %s AND status = %s ORDER BY version_numbe
 	return versions, nil
 }
 
+func (r *promptVersionRepository) IterateByPrompt(ctx context.Context, promptID string, status string, fn func(*domain.PromptVersion) error) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT `+promptVersionColumns+`
+FROM prompt_versions WHERE prompt_id = %s`, ph.Next())
+	args := []interface{}{promptID}
+	if status != "" {
+		query += fmt.Sprintf(" AND status = %s", ph.Next())
+		args = append(args, status)
+	}
+	query += " ORDER BY version_number DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		version, err := scanPromptVersion(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(version); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *promptVersionRepository) GetLatestVersionNumber(ctx context.Context, promptID string) (int, error) {
 	ph := database.NewPlaceholderBuilder(r.dialect)
 	query := fmt.Sprintf(`SELECT COALESCE(MAX(version_number), 0) FROM prompt_versions WHERE prompt_id = %s`, ph.Next())
@@ -889,41 +1283,326 @@ func (r *promptVersionRepository) CountByPromptAndStatus(ctx context.Context, pr
 	return total, nil
 }
 
+func (r *promptVersionRepository) UpdateStatus(ctx context.Context, versionID, from, to string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`UPDATE prompt_versions SET status = %s WHERE id = %s AND status = %s`, ph.Next(), ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query, to, versionID, from)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 func (r *promptVersionRepository) GetPreviousVersion(ctx context.Context, promptID string, versionNumber int) (*domain.PromptVersion, error) {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT id, prompt_id, version_number, body, variables_schema, status, metadata, created_by, created_at
+	query := fmt.Sprintf(`SELECT `+promptVersionColumns+`
 FROM prompt_versions
 WHERE prompt_id = %s AND version_number < %s
 ORDER BY version_number DESC LIMIT 1`, ph.Next(), ph.Next())
 
-	row := promptVersionRow{}
-	err := r.db.QueryRowContext(ctx, query, promptID, versionNumber).Scan(&row.id, &row.promptID, &row.versionNumber, &row.body, &row.variablesSchema, &row.status, &row.metadata, &row.createdBy, &row.createdAt)
+	version, err := scanPromptVersion(r.db.QueryRowContext(ctx, query, promptID, versionNumber))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
+	return version, nil
+}
 
-	version := &domain.PromptVersion{
-		ID:            row.id,
-		PromptID:      row.promptID,
-		VersionNumber: row.versionNumber,
-		Body:          row.body,
-		Status:        row.status,
-		CreatedAt:     row.createdAt,
+// DeleteByIDs 在事务内删除版本及其语言变体、调用日志与批准记录，保证 SQLite 与 Postgres 行为一致。
+func (r *promptVersionRepository) DeleteByIDs(ctx context.Context, promptID string, versionIDs []string) (deleted int64, err error) {
+	if len(versionIDs) == 0 {
+		return 0, nil
 	}
-	if row.variablesSchema.Valid {
-		version.VariablesSchema = json.RawMessage(row.variablesSchema.String)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
 	}
-	if row.metadata.Valid {
-		version.Metadata = json.RawMessage(row.metadata.String)
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	localeQuery := fmt.Sprintf(`DELETE FROM prompt_version_locales WHERE version_id = %s`, ph.Next())
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	logQuery := fmt.Sprintf(`DELETE FROM prompt_execution_logs WHERE prompt_version_id = %s`, ph.Next())
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	approvalQuery := fmt.Sprintf(`DELETE FROM prompt_version_approvals WHERE version_id = %s`, ph.Next())
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	versionQuery := fmt.Sprintf(`DELETE FROM prompt_versions WHERE id = %s AND prompt_id = %s`, ph.Next(), ph.Next())
+
+	for _, versionID := range versionIDs {
+		if _, err = tx.ExecContext(ctx, localeQuery, versionID); err != nil {
+			return 0, err
+		}
+		if _, err = tx.ExecContext(ctx, logQuery, versionID); err != nil {
+			return 0, err
+		}
+		if _, err = tx.ExecContext(ctx, approvalQuery, versionID); err != nil {
+			return 0, err
+		}
+		var res sql.Result
+		if res, err = tx.ExecContext(ctx, versionQuery, versionID, promptID); err != nil {
+			return 0, err
+		}
+		affected, affErr := res.RowsAffected()
+		if affErr != nil {
+			err = affErr
+			return 0, err
+		}
+		deleted += affected
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// ---- Prompt 版本语言变体仓储 ----
+
+type promptVersionLocaleRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+	codec   bodyCodec
+}
+
+type promptVersionLocaleRow struct {
+	id        string
+	versionID string
+	locale    string
+	body      string
+	createdBy sql.NullString
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func (row promptVersionLocaleRow) toDomain() (*domain.PromptVersionLocale, error) {
+	body, err := decodeBody(row.body)
+	if err != nil {
+		return nil, err
+	}
+	locale := &domain.PromptVersionLocale{
+		ID:        row.id,
+		VersionID: row.versionID,
+		Locale:    row.locale,
+		Body:      body,
+		CreatedAt: row.createdAt,
+		UpdatedAt: row.updatedAt,
 	}
 	if row.createdBy.Valid {
-		version.CreatedBy = &row.createdBy.String
+		locale.CreatedBy = &row.createdBy.String
 	}
+	return locale, nil
+}
 
-	return version, nil
+func (r *promptVersionLocaleRepository) Upsert(ctx context.Context, locale *domain.PromptVersionLocale) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO prompt_version_locales (id, version_id, locale, body, created_by)
+VALUES (%s, %s, %s, %s, %s)
+ON CONFLICT (version_id, locale) DO UPDATE SET body = excluded.body, updated_at = CURRENT_TIMESTAMP`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	createdBy := sql.NullString{}
+	if locale.CreatedBy != nil {
+		createdBy = sql.NullString{String: *locale.CreatedBy, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, query, locale.ID, locale.VersionID, locale.Locale, r.codec.encode(locale.Body), createdBy)
+	return err
+}
+
+func (r *promptVersionLocaleRepository) GetByVersionAndLocale(ctx context.Context, versionID, locale string) (*domain.PromptVersionLocale, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, version_id, locale, body, created_by, created_at, updated_at
+FROM prompt_version_locales WHERE version_id = %s AND locale = %s`, ph.Next(), ph.Next())
+
+	var row promptVersionLocaleRow
+	err := r.db.QueryRowContext(ctx, query, versionID, locale).Scan(&row.id, &row.versionID, &row.locale, &row.body, &row.createdBy, &row.createdAt, &row.updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return row.toDomain()
+}
+
+func (r *promptVersionLocaleRepository) ListByVersion(ctx context.Context, versionID string) ([]*domain.PromptVersionLocale, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, version_id, locale, body, created_by, created_at, updated_at
+FROM prompt_version_locales WHERE version_id = %s ORDER BY locale ASC`, ph.Next())
+
+	rows, err := r.db.QueryContext(ctx, query, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locales []*domain.PromptVersionLocale
+	for rows.Next() {
+		var row promptVersionLocaleRow
+		if err := rows.Scan(&row.id, &row.versionID, &row.locale, &row.body, &row.createdBy, &row.createdAt, &row.updatedAt); err != nil {
+			return nil, err
+		}
+		locale, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		locales = append(locales, locale)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return locales, nil
+}
+
+func (r *promptVersionLocaleRepository) Delete(ctx context.Context, versionID, locale string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`DELETE FROM prompt_version_locales WHERE version_id = %s AND locale = %s`, ph.Next(), ph.Next())
+
+	result, err := r.db.ExecContext(ctx, query, versionID, locale)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promptVersionLocaleRepository) ListActiveCoverage(ctx context.Context) ([]*domain.PromptLocaleCoverage, error) {
+	query := `SELECT p.id, p.name, p.active_version_id, l.locale
+FROM prompts p
+LEFT JOIN prompt_version_locales l ON l.version_id = p.active_version_id
+WHERE p.deleted_at IS NULL AND p.active_version_id IS NOT NULL
+ORDER BY p.name ASC, l.locale ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*domain.PromptLocaleCoverage
+	index := make(map[string]*domain.PromptLocaleCoverage)
+	for rows.Next() {
+		var (
+			promptID, name, versionID string
+			locale                    sql.NullString
+		)
+		if err := rows.Scan(&promptID, &name, &versionID, &locale); err != nil {
+			return nil, err
+		}
+		item, ok := index[promptID]
+		if !ok {
+			item = &domain.PromptLocaleCoverage{PromptID: promptID, PromptName: name, ActiveVersionID: versionID, Locales: []string{}}
+			index[promptID] = item
+			items = append(items, item)
+		}
+		if locale.Valid {
+			item.Locales = append(item.Locales, locale.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ---- Prompt 依赖仓储 ----
+
+type promptDependencyRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func (r *promptDependencyRepository) ReplaceBySource(ctx context.Context, promptID, source string, dependsOn []string, createdBy *string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	deleteQuery := fmt.Sprintf(`DELETE FROM prompt_dependencies WHERE prompt_id = %s AND source = %s`, ph.Next(), ph.Next())
+	if _, err = tx.ExecContext(ctx, deleteQuery, promptID, source); err != nil {
+		return err
+	}
+
+	creator := sql.NullString{}
+	if createdBy != nil {
+		creator = sql.NullString{String: *createdBy, Valid: true}
+	}
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	insertQuery := fmt.Sprintf(`INSERT INTO prompt_dependencies (prompt_id, depends_on_id, source, created_by)
+VALUES (%s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	for _, dependencyID := range dependsOn {
+		if _, err = tx.ExecContext(ctx, insertQuery, promptID, dependencyID, source, creator); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *promptDependencyRepository) ListDependencies(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT p.id, p.name, p.status, d.source
+FROM prompt_dependencies d
+JOIN prompts p ON p.id = d.depends_on_id
+WHERE d.prompt_id = %s
+ORDER BY p.name ASC, d.source ASC`, ph.Next())
+	return r.queryLinks(ctx, query, promptID)
+}
+
+func (r *promptDependencyRepository) ListDependents(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT p.id, p.name, p.status, d.source
+FROM prompt_dependencies d
+JOIN prompts p ON p.id = d.prompt_id
+WHERE d.depends_on_id = %s AND p.deleted_at IS NULL
+ORDER BY p.name ASC, d.source ASC`, ph.Next())
+	return r.queryLinks(ctx, query, promptID)
+}
+
+func (r *promptDependencyRepository) queryLinks(ctx context.Context, query string, args ...interface{}) ([]*domain.PromptDependencyLink, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*domain.PromptDependencyLink{}
+	for rows.Next() {
+		link := &domain.PromptDependencyLink{}
+		if err := rows.Scan(&link.PromptID, &link.Name, &link.Status, &link.Source); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return links, nil
 }
 
 // ---- 执行日志仓储 ----
@@ -942,6 +1621,8 @@ type executionLogRow struct {
 	durationMs       sql.NullInt64
 	requestPayload   sql.NullString
 	responseMetadata sql.NullString
+	errorClass       sql.NullString
+	errorCode        sql.NullString
 	createdAt        time.Time
 }
 
@@ -950,13 +1631,63 @@ type executionAggregateRow struct {
 	totalCalls   int
 	successCalls int
 	averageMs    sql.NullFloat64
+	p50Ms        sql.NullFloat64
+	p90Ms        sql.NullFloat64
+	p99Ms        sql.NullFloat64
 }
 
 func (r *promptExecutionLogRepository) Create(ctx context.Context, log *domain.PromptExecutionLog) error {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`INSERT INTO prompt_execution_logs (id, prompt_id, prompt_version_id, user_id, status, duration_ms, request_payload, response_metadata)
-VALUES (%s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	query := fmt.Sprintf(`INSERT INTO prompt_execution_logs (id, prompt_id, prompt_version_id, user_id, status, duration_ms, request_payload, response_metadata, error_class, error_code)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	_, err := r.db.ExecContext(ctx, query, executionLogArgs(log)...)
+	return err
+}
+
+// executionLogBatchRows 控制单条 INSERT 的行数：11 列 × 100 行远低于 SQLite 与 Postgres 的参数上限。
+const executionLogBatchRows = 100
+
+func (r *promptExecutionLogRepository) CreateBatch(ctx context.Context, logs []*domain.PromptExecutionLog) (err error) {
+	if len(logs) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for start := 0; start < len(logs); start += executionLogBatchRows {
+		chunk := logs[start:min(start+executionLogBatchRows, len(logs))]
+		ph := database.NewPlaceholderBuilder(r.dialect)
+		values := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk)*11)
+		for _, log := range chunk {
+			values = append(values, fmt.Sprintf("(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, COALESCE(%s, CURRENT_TIMESTAMP))",
+				ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next()))
+			var createdAt interface{}
+			if !log.CreatedAt.IsZero() {
+				createdAt = r.dialect.TimestampArg(log.CreatedAt)
+			}
+			args = append(append(args, executionLogArgs(log)...), createdAt)
+		}
+		query := `INSERT INTO prompt_execution_logs (id, prompt_id, prompt_version_id, user_id, status, duration_ms, request_payload, response_metadata, error_class, error_code, created_at)
+VALUES ` + strings.Join(values, ", ") + `
+ON CONFLICT (id) DO NOTHING`
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
 
+// executionLogArgs 按 INSERT 列顺序（不含 created_at）返回日志字段，空值写为 NULL。
+func executionLogArgs(log *domain.PromptExecutionLog) []interface{} {
 	userID := sql.NullString{}
 	if log.UserID != nil {
 		userID = sql.NullString{String: *log.UserID, Valid: true}
@@ -973,9 +1704,22 @@ VALUES (%s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Ne
 	if len(log.ResponseMetadata) > 0 {
 		response = sql.NullString{String: string(log.ResponseMetadata), Valid: true}
 	}
+	return []interface{}{log.ID, log.PromptID, log.PromptVersionID, userID, log.Status, duration, request, response, nullableString(log.ErrorClass), nullableString(log.ErrorCode)}
+}
 
-	_, err := r.db.ExecContext(ctx, query, log.ID, log.PromptID, log.PromptVersionID, userID, log.Status, duration, request, response)
-	return err
+func (r *promptExecutionLogRepository) GetByID(ctx context.Context, id string) (*domain.PromptExecutionLog, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT id, prompt_id, prompt_version_id, user_id, status, duration_ms, request_payload, response_metadata, error_class, error_code, created_at
+FROM prompt_execution_logs WHERE id = %s`, ph.Next())
+
+	log, err := scanExecutionLog(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return log, nil
 }
 
 func (r *promptExecutionLogRepository) ListRecent(ctx context.Context, promptID string, limit int) ([]*domain.PromptExecutionLog, error) {
@@ -983,7 +1727,7 @@ func (r *promptExecutionLogRepository) ListRecent(ctx context.Context, promptID
 		limit = 20
 	}
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT id, prompt_id, prompt_version_id, user_id, status, duration_ms, request_payload, response_metadata, created_at
+	query := fmt.Sprintf(`SELECT id, prompt_id, prompt_version_id, user_id, status, duration_ms, request_payload, response_metadata, error_class, error_code, created_at
 FROM prompt_execution_logs WHERE prompt_id = %s ORDER BY created_at DESC LIMIT %s`, ph.Next(), ph.Next())
 
 	rows, err := r.db.QueryContext(ctx, query, promptID, limit)
@@ -994,29 +1738,10 @@ FROM prompt_execution_logs WHERE prompt_id = %s ORDER BY created_at DESC LIMIT %
 
 	var logs []*domain.PromptExecutionLog
 	for rows.Next() {
-		var row executionLogRow
-		if err := rows.Scan(&row.id, &row.promptID, &row.promptVersionID, &row.userID, &row.status, &row.durationMs, &row.requestPayload, &row.responseMetadata, &row.createdAt); err != nil {
+		log, err := scanExecutionLog(rows)
+		if err != nil {
 			return nil, err
 		}
-		log := &domain.PromptExecutionLog{
-			ID:              row.id,
-			PromptID:        row.promptID,
-			PromptVersionID: row.promptVersionID,
-			Status:          row.status,
-			CreatedAt:       row.createdAt,
-		}
-		if row.userID.Valid {
-			log.UserID = &row.userID.String
-		}
-		if row.durationMs.Valid {
-			log.DurationMs = row.durationMs.Int64
-		}
-		if row.requestPayload.Valid {
-			log.RequestPayload = json.RawMessage(row.requestPayload.String)
-		}
-		if row.responseMetadata.Valid {
-			log.ResponseMetadata = json.RawMessage(row.responseMetadata.String)
-		}
 		logs = append(logs, log)
 	}
 	if err := rows.Err(); err != nil {
@@ -1025,51 +1750,319 @@ FROM prompt_execution_logs WHERE prompt_id = %s ORDER BY created_at DESC LIMIT %
 	return logs, nil
 }
 
-func (r *promptExecutionLogRepository) AggregateUsage(ctx context.Context, promptID string, from time.Time) ([]*domain.PromptExecutionAggregate, error) {
+func (r *promptExecutionLogRepository) IterateSince(ctx context.Context, promptID string, from time.Time, fn func(*domain.PromptExecutionLog) error) error {
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT DATE(created_at) as day,
+	query := fmt.Sprintf(`SELECT id, prompt_id, prompt_version_id, user_id, status, duration_ms, request_payload, response_metadata, error_class, error_code, created_at
+FROM prompt_execution_logs WHERE prompt_id = %s AND created_at >= %s ORDER BY created_at DESC`, ph.Next(), ph.Next())
+
+	rows, err := r.db.QueryContext(ctx, query, promptID, r.dialect.TimestampArg(from))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanExecutionLog(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanExecutionLog(scanner rowScanner) (*domain.PromptExecutionLog, error) {
+	var row executionLogRow
+	if err := scanner.Scan(&row.id, &row.promptID, &row.promptVersionID, &row.userID, &row.status, &row.durationMs, &row.requestPayload, &row.responseMetadata, &row.errorClass, &row.errorCode, &row.createdAt); err != nil {
+		return nil, err
+	}
+	log := &domain.PromptExecutionLog{
+		ID:              row.id,
+		PromptID:        row.promptID,
+		PromptVersionID: row.promptVersionID,
+		Status:          row.status,
+		CreatedAt:       row.createdAt,
+	}
+	if row.userID.Valid {
+		log.UserID = &row.userID.String
+	}
+	if row.durationMs.Valid {
+		log.DurationMs = row.durationMs.Int64
+	}
+	if row.requestPayload.Valid {
+		log.RequestPayload = json.RawMessage(row.requestPayload.String)
+	}
+	if row.responseMetadata.Valid {
+		log.ResponseMetadata = json.RawMessage(row.responseMetadata.String)
+	}
+	if row.errorClass.Valid {
+		log.ErrorClass = &row.errorClass.String
+	}
+	if row.errorCode.Valid {
+		log.ErrorCode = &row.errorCode.String
+	}
+	return log, nil
+}
+
+func (r *promptExecutionLogRepository) AggregateUsage(ctx context.Context, promptID string, opts domain.ExecutionAggregateOptions) ([]*domain.PromptExecutionAggregate, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	unit := opts.Granularity
+	if unit == "" {
+		unit = domain.GranularityDay
+	}
+	ref := time.Now()
+	if !opts.To.IsZero() {
+		ref = opts.To
+	}
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	bucket := r.dialect.LocalBucket("created_at", unit, ph.Next())
+	args := []interface{}{r.dialect.TimezoneArg(loc, ref), promptID, r.dialect.TimestampArg(opts.From)}
+	conditions := fmt.Sprintf("prompt_id = %s AND created_at >= %s", ph.Next(), ph.Next())
+	if !opts.To.IsZero() {
+		conditions += fmt.Sprintf(" AND created_at < %s", ph.Next())
+		args = append(args, r.dialect.TimestampArg(opts.To))
+	}
+	// SQLite 不支持 percentile_cont，分位数在查询后按桶内耗时计算。
+	percentiles := "NULL, NULL, NULL"
+	if r.dialect.IsPostgres() {
+		percentiles = `percentile_cont(0.5) WITHIN GROUP (ORDER BY duration_ms),
+        percentile_cont(0.9) WITHIN GROUP (ORDER BY duration_ms),
+        percentile_cont(0.99) WITHIN GROUP (ORDER BY duration_ms)`
+	}
+	query := fmt.Sprintf(`SELECT %s as bucket,
         COUNT(*) as total_calls,
         SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as success_calls,
-        AVG(duration_ms) as average_ms
+        AVG(duration_ms) as average_ms,
+        %s
       FROM prompt_execution_logs
-      WHERE prompt_id = %s AND created_at >= %s
-      GROUP BY DATE(created_at)
-      ORDER BY DATE(created_at) DESC`, ph.Next(), ph.Next())
+      WHERE %s
+      GROUP BY 1
+      ORDER BY 1 DESC`, bucket, percentiles, conditions)
 
-	rows, err := r.db.QueryContext(ctx, query, promptID, from)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var stats []*domain.PromptExecutionAggregate
+	byBucket := make(map[string]*domain.PromptExecutionAggregate)
 	for rows.Next() {
 		var row executionAggregateRow
-		if err := rows.Scan(&row.dayStr, &row.totalCalls, &row.successCalls, &row.averageMs); err != nil {
+		if err := rows.Scan(&row.dayStr, &row.totalCalls, &row.successCalls, &row.averageMs, &row.p50Ms, &row.p90Ms, &row.p99Ms); err != nil {
 			return nil, err
 		}
 		aggregate := &domain.PromptExecutionAggregate{
+			Bucket:       row.dayStr,
 			TotalCalls:   row.totalCalls,
 			SuccessCalls: row.successCalls,
 		}
 		if row.dayStr != "" {
-			if parsed, err := time.Parse("2006-01-02", row.dayStr); err == nil {
-				aggregate.Day = parsed
+			if parsed, err := time.ParseInLocation(database.BucketLayout(unit), row.dayStr, loc); err == nil {
+				aggregate.Day = parsed.UTC()
 			}
 		}
 		if row.averageMs.Valid {
 			aggregate.AverageMillis = row.averageMs.Float64
 		}
+		aggregate.P50Millis = row.p50Ms.Float64
+		aggregate.P90Millis = row.p90Ms.Float64
+		aggregate.P99Millis = row.p99Ms.Float64
 		stats = append(stats, aggregate)
+		byBucket[row.dayStr] = aggregate
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
+
+	classQuery := fmt.Sprintf(`SELECT %s as bucket, error_class, COUNT(*)
+      FROM prompt_execution_logs
+      WHERE %s AND error_class IS NOT NULL
+      GROUP BY 1, 2`, bucket, conditions)
+	classRows, err := r.db.QueryContext(ctx, classQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer classRows.Close()
+
+	for classRows.Next() {
+		var (
+			bucketKey string
+			class     string
+			count     int
+		)
+		if err := classRows.Scan(&bucketKey, &class, &count); err != nil {
+			return nil, err
+		}
+		aggregate, ok := byBucket[bucketKey]
+		if !ok {
+			continue
+		}
+		if aggregate.ErrorClasses == nil {
+			aggregate.ErrorClasses = make(map[string]int)
+		}
+		aggregate.ErrorClasses[class] = count
+	}
+	if err := classRows.Err(); err != nil {
+		return nil, err
+	}
+	classRows.Close()
+
+	if !r.dialect.IsPostgres() {
+		if err := r.fillDurationPercentiles(ctx, bucket, conditions, args, byBucket); err != nil {
+			return nil, err
+		}
+	}
 
 	return stats, nil
 }
 
+func (r *promptExecutionLogRepository) SummarizeWindow(ctx context.Context, promptID string, from time.Time) (*domain.PromptExecutionWindow, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	conditions := fmt.Sprintf("prompt_id = %s AND created_at >= %s", ph.Next(), ph.Next())
+	args := []interface{}{promptID, r.dialect.TimestampArg(from)}
+
+	p95 := "NULL"
+	if r.dialect.IsPostgres() {
+		p95 = "percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms)"
+	}
+	query := fmt.Sprintf(`SELECT COUNT(*),
+        COALESCE(SUM(CASE WHEN status = 'success' THEN 0 ELSE 1 END), 0),
+        %s
+      FROM prompt_execution_logs
+      WHERE %s`, p95, conditions)
+
+	var (
+		window   domain.PromptExecutionWindow
+		p95Value sql.NullFloat64
+	)
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&window.TotalCalls, &window.FailedCalls, &p95Value); err != nil {
+		return nil, err
+	}
+	window.P95Millis = p95Value.Float64
+	if r.dialect.IsPostgres() || window.TotalCalls == 0 {
+		return &window, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT duration_ms FROM prompt_execution_logs
+WHERE %s AND duration_ms IS NOT NULL ORDER BY duration_ms`, conditions), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var durations []float64
+	for rows.Next() {
+		var duration int64
+		if err := rows.Scan(&duration); err != nil {
+			return nil, err
+		}
+		durations = append(durations, float64(duration))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	window.P95Millis = percentileCont(durations, 0.95)
+	return &window, nil
+}
+
+func (r *promptExecutionLogRepository) SummarizeWindowByVersion(ctx context.Context, promptID string, from time.Time) (map[string]*domain.PromptExecutionWindow, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT prompt_version_id, COUNT(*),
+        COALESCE(SUM(CASE WHEN status = 'success' THEN 0 ELSE 1 END), 0)
+      FROM prompt_execution_logs
+      WHERE prompt_id = %s AND created_at >= %s
+      GROUP BY prompt_version_id`, ph.Next(), ph.Next())
+	rows, err := r.db.QueryContext(ctx, query, promptID, r.dialect.TimestampArg(from.UTC()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := make(map[string]*domain.PromptExecutionWindow)
+	for rows.Next() {
+		var (
+			versionID string
+			window    domain.PromptExecutionWindow
+		)
+		if err := rows.Scan(&versionID, &window.TotalCalls, &window.FailedCalls); err != nil {
+			return nil, err
+		}
+		windows[versionID] = &window
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// fillDurationPercentiles 逐桶读取有序耗时并按 percentile_cont 的线性插值计算分位数，供不支持该函数的方言使用。
+func (r *promptExecutionLogRepository) fillDurationPercentiles(ctx context.Context, bucket, conditions string, args []interface{}, byBucket map[string]*domain.PromptExecutionAggregate) error {
+	query := fmt.Sprintf(`SELECT %s as bucket, duration_ms
+      FROM prompt_execution_logs
+      WHERE %s AND duration_ms IS NOT NULL
+      ORDER BY 1, 2`, bucket, conditions)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		current   string
+		durations []float64
+	)
+	flush := func() {
+		if aggregate, ok := byBucket[current]; ok && len(durations) > 0 {
+			aggregate.P50Millis = percentileCont(durations, 0.5)
+			aggregate.P90Millis = percentileCont(durations, 0.9)
+			aggregate.P99Millis = percentileCont(durations, 0.99)
+		}
+		durations = durations[:0]
+	}
+	for rows.Next() {
+		var (
+			bucketKey string
+			duration  int64
+		)
+		if err := rows.Scan(&bucketKey, &duration); err != nil {
+			return err
+		}
+		if bucketKey != current {
+			flush()
+			current = bucketKey
+		}
+		durations = append(durations, float64(duration))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
+
+// percentileCont 对已升序排列的样本做线性插值，与 Postgres percentile_cont 语义一致。
+func percentileCont(sorted []float64, fraction float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := fraction * float64(len(sorted)-1)
+	lower := int(position)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	weight := position - float64(lower)
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*weight
+}
+
 // ---- Prompt 审计日志仓储 ----
 
 type promptAuditLogRepository struct {
@@ -1084,12 +2077,61 @@ type promptAuditRow struct {
 	payload   sql.NullString
 	createdBy sql.NullString
 	createdAt time.Time
+	seq       sql.NullInt64
+	prevHash  sql.NullString
+	hash      sql.NullString
 }
 
+const promptAuditColumns = `id, prompt_id, action, payload, created_by, created_at, seq, prev_hash, hash`
+
+// auditChainRetries 为并发写入争用同一序号（唯一索引冲突）时的重试次数。
+const auditChainRetries = 3
+
+// Create 在事务内读取链尾并写入带链式哈希的审计记录。
 func (r *promptAuditLogRepository) Create(ctx context.Context, log *domain.PromptAuditLog) error {
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+	log.CreatedAt = log.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	var err error
+	for attempt := 0; attempt < auditChainRetries; attempt++ {
+		if err = r.appendChained(ctx, log); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (r *promptAuditLogRepository) appendChained(ctx context.Context, log *domain.PromptAuditLog) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		lastSeq  sql.NullInt64
+		lastHash sql.NullString
+	)
+	err = tx.QueryRowContext(ctx, `SELECT seq, hash FROM prompt_audit_logs WHERE seq IS NOT NULL ORDER BY seq DESC LIMIT 1`).Scan(&lastSeq, &lastHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	log.Seq = lastSeq.Int64 + 1
+	log.PrevHash = lastHash.String
+	log.Hash = log.ChainHash()
+
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`INSERT INTO prompt_audit_logs (id, prompt_id, action, payload, created_by)
-VALUES (%s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	query := fmt.Sprintf(`INSERT INTO prompt_audit_logs (id, prompt_id, action, payload, created_by, created_at, seq, prev_hash, hash)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
 
 	payload := sql.NullString{}
 	if len(log.Payload) > 0 {
@@ -1100,8 +2142,10 @@ VALUES (%s, %s, %s, %s, %s)`, ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Nex
 		createdBy = sql.NullString{String: *log.CreatedBy, Valid: true}
 	}
 
-	_, err := r.db.ExecContext(ctx, query, log.ID, log.PromptID, log.Action, payload, createdBy)
-	return err
+	if _, err = tx.ExecContext(ctx, query, log.ID, log.PromptID, log.Action, payload, createdBy, log.CreatedAt, log.Seq, log.PrevHash, log.Hash); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *promptAuditLogRepository) ListByPrompt(ctx context.Context, promptID string, limit int) ([]*domain.PromptAuditLog, error) {
@@ -1109,8 +2153,8 @@ func (r *promptAuditLogRepository) ListByPrompt(ctx context.Context, promptID st
 		limit = 20
 	}
 	ph := database.NewPlaceholderBuilder(r.dialect)
-	query := fmt.Sprintf(`SELECT id, prompt_id, action, payload, created_by, created_at
-FROM prompt_audit_logs WHERE prompt_id = %s ORDER BY created_at DESC LIMIT %s`, ph.Next(), ph.Next())
+	query := fmt.Sprintf(`SELECT %s
+FROM prompt_audit_logs WHERE prompt_id = %s ORDER BY created_at DESC LIMIT %s`, promptAuditColumns, ph.Next(), ph.Next())
 
 	rows, err := r.db.QueryContext(ctx, query, promptID, limit)
 	if err != nil {
@@ -1120,22 +2164,10 @@ FROM prompt_audit_logs WHERE prompt_id = %s ORDER BY created_at DESC LIMIT %s`,
 
 	var logs []*domain.PromptAuditLog
 	for rows.Next() {
-		var row promptAuditRow
-		if err := rows.Scan(&row.id, &row.promptID, &row.action, &row.payload, &row.createdBy, &row.createdAt); err != nil {
+		log, err := scanPromptAuditLog(rows)
+		if err != nil {
 			return nil, err
 		}
-		log := &domain.PromptAuditLog{
-			ID:        row.id,
-			PromptID:  row.promptID,
-			Action:    row.action,
-			CreatedAt: row.createdAt,
-		}
-		if row.payload.Valid {
-			log.Payload = json.RawMessage(row.payload.String)
-		}
-		if row.createdBy.Valid {
-			log.CreatedBy = &row.createdBy.String
-		}
 		logs = append(logs, log)
 	}
 	if err := rows.Err(); err != nil {
@@ -1143,3 +2175,69 @@ FROM prompt_audit_logs WHERE prompt_id = %s ORDER BY created_at DESC LIMIT %s`,
 	}
 	return logs, nil
 }
+
+func (r *promptAuditLogRepository) Iterate(ctx context.Context, opts domain.AuditLogIterateOptions, fn func(*domain.PromptAuditLog) error) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if opts.PromptID != "" {
+		conditions = append(conditions, fmt.Sprintf("prompt_id = %s", ph.Next()))
+		args = append(args, opts.PromptID)
+	}
+	if !opts.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at >= %s", ph.Next()))
+		args = append(args, opts.From.UTC())
+	}
+	if !opts.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at < %s", ph.Next()))
+		args = append(args, opts.To.UTC())
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	query := fmt.Sprintf(`SELECT %s FROM prompt_audit_logs %s
+ORDER BY CASE WHEN seq IS NULL THEN 0 ELSE 1 END, seq, created_at`, promptAuditColumns, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanPromptAuditLog(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanPromptAuditLog(rows *sql.Rows) (*domain.PromptAuditLog, error) {
+	var row promptAuditRow
+	if err := rows.Scan(&row.id, &row.promptID, &row.action, &row.payload, &row.createdBy, &row.createdAt, &row.seq, &row.prevHash, &row.hash); err != nil {
+		return nil, err
+	}
+	log := &domain.PromptAuditLog{
+		ID:        row.id,
+		PromptID:  row.promptID,
+		Action:    row.action,
+		CreatedAt: row.createdAt,
+		Seq:       row.seq.Int64,
+		PrevHash:  row.prevHash.String,
+		Hash:      row.hash.String,
+	}
+	if row.payload.Valid {
+		log.Payload = json.RawMessage(row.payload.String)
+	}
+	if row.createdBy.Valid {
+		log.CreatedBy = &row.createdBy.String
+	}
+	return log, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,31 +23,7 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-
-	migrationPath := filepath.Join("..", "..", "..", "db", "migrations", "000001_init.up.sql")
-	migrationSQL, err := os.ReadFile(migrationPath)
-	if err != nil {
-		t.Fatalf("read migration: %v", err)
-	}
-	if _, err := db.Exec(string(migrationSQL)); err != nil {
-		t.Fatalf("exec migration: %v", err)
-	}
-	migration2Path := filepath.Join("..", "..", "..", "db", "migrations", "000002_add_prompt_body.up.sql")
-	migration2SQL, err := os.ReadFile(migration2Path)
-	if err != nil {
-		t.Fatalf("read migration 2: %v", err)
-	}
-	if _, err := db.Exec(string(migration2SQL)); err != nil {
-		t.Fatalf("exec migration 2: %v", err)
-	}
-	migration3Path := filepath.Join("..", "..", "..", "db", "migrations", "000003_prompt_soft_delete.up.sql")
-	migration3SQL, err := os.ReadFile(migration3Path)
-	if err != nil {
-		t.Fatalf("read migration 3: %v", err)
-	}
-	if _, err := db.Exec(string(migration3SQL)); err != nil {
-		t.Fatalf("exec migration 3: %v", err)
-	}
+	applyMigrations(t, db)
 
 	cleanup := func() {
 		_ = db.Close()
@@ -53,6 +31,24 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 	return db, cleanup
 }
 
+// applyMigrations 依次执行 db/migrations 下的全部 up 迁移。
+func applyMigrations(t testing.TB, db *sql.DB) {
+	t.Helper()
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("glob migrations: %v", err)
+	}
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
+	}
+}
+
 func TestUserRepository_CreateAndGet(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -186,6 +182,10 @@ func TestPromptRepositories_Workflow(t *testing.T) {
 	if err := repos.PromptExecutionLog.Create(ctx, execLog); err != nil {
 		t.Fatalf("create exec log: %v", err)
 	}
+	// 队列重投递同一条日志时应被跳过而不是使整批失败。
+	if err := repos.PromptExecutionLog.CreateBatch(ctx, []*domain.PromptExecutionLog{execLog}); err != nil {
+		t.Fatalf("re-deliver exec log: %v", err)
+	}
 
 	logs, err := repos.PromptExecutionLog.ListRecent(ctx, promptID, 10)
 	if err != nil {
@@ -198,7 +198,7 @@ func TestPromptRepositories_Workflow(t *testing.T) {
 		t.Fatalf("unexpected duration: %d", logs[0].DurationMs)
 	}
 
-	stats, err := repos.PromptExecutionLog.AggregateUsage(ctx, promptID, time.Now().Add(-24*time.Hour))
+	stats, err := repos.PromptExecutionLog.AggregateUsage(ctx, promptID, domain.ExecutionAggregateOptions{From: time.Now().Add(-24 * time.Hour)})
 	if err != nil {
 		t.Fatalf("aggregate usage: %v", err)
 	}
@@ -275,3 +275,286 @@ func TestPromptRepositories_Workflow(t *testing.T) {
 		t.Fatalf("expected no prompts after delete got %d", len(listed))
 	}
 }
+
+func TestPromptRepository_WorkspaceScope(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repos := NewSQLRepositories(db, database.NewDialect("sqlite"))
+	ctx := context.Background()
+
+	workspace := &domain.Workspace{ID: uuid.NewString(), OrganizationID: domain.DefaultOrganizationID, Name: "Team A", Slug: "team-a"}
+	if err := repos.Workspaces.CreateWorkspace(ctx, workspace, nil); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+
+	defaultPrompt := &domain.Prompt{ID: uuid.NewString(), Name: "default-prompt"}
+	if err := repos.Prompts.Create(ctx, defaultPrompt); err != nil {
+		t.Fatalf("create default prompt: %v", err)
+	}
+	teamCtx := domain.WithWorkspace(ctx, workspace.ID)
+	teamPrompt := &domain.Prompt{ID: uuid.NewString(), Name: "team-prompt"}
+	if err := repos.Prompts.Create(teamCtx, teamPrompt); err != nil {
+		t.Fatalf("create team prompt: %v", err)
+	}
+	if teamPrompt.WorkspaceID != workspace.ID {
+		t.Fatalf("expected prompt to be created in workspace %s, got %s", workspace.ID, teamPrompt.WorkspaceID)
+	}
+
+	items, err := repos.Prompts.List(teamCtx, domain.PromptListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("list team prompts: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != teamPrompt.ID {
+		t.Fatalf("expected only team prompt, got %+v", items)
+	}
+	total, err := repos.Prompts.Count(domain.WithWorkspace(ctx, domain.DefaultWorkspaceID), domain.PromptListOptions{})
+	if err != nil || total != 1 {
+		t.Fatalf("expected 1 prompt in default workspace, got %d (%v)", total, err)
+	}
+
+	if _, err := repos.Prompts.GetByID(teamCtx, defaultPrompt.ID); err != domain.ErrNotFound {
+		t.Fatalf("expected prompt from other workspace to be hidden, got %v", err)
+	}
+	if _, err := repos.Prompts.GetByName(teamCtx, "team-prompt", false); err != nil {
+		t.Fatalf("get team prompt by name: %v", err)
+	}
+	if all, err := repos.Prompts.Count(ctx, domain.PromptListOptions{}); err != nil || all != 2 {
+		t.Fatalf("expected unscoped count 2, got %d (%v)", all, err)
+	}
+}
+
+func TestUsageRepository_TenantUsage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repos := NewSQLRepositories(db, database.NewDialect("sqlite"))
+	ctx := context.Background()
+
+	org := &domain.Organization{ID: uuid.NewString(), Name: "Acme", Slug: "acme"}
+	if err := repos.Workspaces.CreateOrganization(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	workspace := &domain.Workspace{ID: uuid.NewString(), OrganizationID: org.ID, Name: "Acme Main", Slug: "main"}
+	if err := repos.Workspaces.CreateWorkspace(ctx, workspace, nil); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	prompt := &domain.Prompt{ID: uuid.NewString(), Name: "acme-prompt"}
+	if err := repos.Prompts.Create(domain.WithWorkspace(ctx, workspace.ID), prompt); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version := &domain.PromptVersion{ID: uuid.NewString(), PromptID: prompt.ID, VersionNumber: 1, Body: "héllo", Status: "published"}
+	if err := repos.PromptVersions.Create(ctx, version); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, createdAt := range []time.Time{now, now.AddDate(0, -2, 0)} {
+		log := &domain.PromptExecutionLog{ID: uuid.NewString(), PromptID: prompt.ID, PromptVersionID: version.ID, Status: "success", CreatedAt: createdAt}
+		if err := repos.PromptExecutionLog.CreateBatch(ctx, []*domain.PromptExecutionLog{log}); err != nil {
+			t.Fatalf("create exec log: %v", err)
+		}
+	}
+
+	period := now.Format("2006-01")
+	for i := 0; i < 2; i++ {
+		if err := repos.Usage.AddCounters(ctx, []domain.UsageCounter{
+			{WorkspaceID: workspace.ID, Period: period, Metric: domain.UsageMetricAPICalls, Value: 3},
+			{WorkspaceID: domain.DefaultWorkspaceID, Period: period, Metric: domain.UsageMetricAPICalls, Value: 1},
+		}); err != nil {
+			t.Fatalf("add counters: %v", err)
+		}
+	}
+
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	usages, err := repos.Usage.TenantUsage(ctx, period, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("tenant usage: %v", err)
+	}
+	byOrg := make(map[string]*domain.TenantUsage)
+	for _, usage := range usages {
+		byOrg[usage.OrganizationID] = usage
+	}
+	acme := byOrg[org.ID]
+	if acme == nil || acme.APICalls != 6 || acme.Executions != 1 || acme.StorageBytes != int64(len("héllo")) {
+		t.Fatalf("unexpected acme usage: %+v", acme)
+	}
+	if def := byOrg[domain.DefaultOrganizationID]; def == nil || def.APICalls != 2 || def.Executions != 0 {
+		t.Fatalf("unexpected default usage: %+v", def)
+	}
+
+	if err := repos.Usage.SaveExports(ctx, []*domain.UsageExport{{OrganizationID: org.ID, Period: period, Metric: domain.UsageMetricAPICalls, Exporter: "webhook", Value: 6}}); err != nil {
+		t.Fatalf("save exports: %v", err)
+	}
+	exports, err := repos.Usage.ListExports(ctx, "webhook", period)
+	if err != nil || len(exports) != 1 || exports[0].Value != 6 {
+		t.Fatalf("unexpected exports: %+v (%v)", exports, err)
+	}
+}
+
+func TestStmtCache(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cache := newStmtCache(db)
+
+	first, err := cache.prepare(ctx, "SELECT COUNT(*) FROM prompts")
+	if err != nil || first == nil {
+		t.Fatalf("prepare statement: %v", err)
+	}
+	second, err := cache.prepare(ctx, "SELECT COUNT(*) FROM prompts")
+	if err != nil || second != first {
+		t.Fatalf("expected cached statement to be reused, got %p vs %p (%v)", second, first, err)
+	}
+
+	var count int
+	if err := cache.QueryRowContext(ctx, "SELECT COUNT(*) FROM missing_table").Scan(&count); err == nil {
+		t.Fatalf("expected invalid query to surface an error on scan")
+	}
+
+	for i := len(cache.stmts); i < stmtCacheMaxEntries; i++ {
+		if _, err := cache.prepare(ctx, fmt.Sprintf("SELECT %d", i)); err != nil {
+			t.Fatalf("prepare filler %d: %v", i, err)
+		}
+	}
+	if stmt, err := cache.prepare(ctx, "SELECT 'overflow'"); err != nil || stmt != nil {
+		t.Fatalf("expected full cache to skip preparing, got %v (%v)", stmt, err)
+	}
+	var value string
+	if err := cache.QueryRowContext(ctx, "SELECT 'overflow'").Scan(&value); err != nil || value != "overflow" {
+		t.Fatalf("expected fallback query to run, got %q (%v)", value, err)
+	}
+}
+
+func TestHotQueryPlansUseIndexes(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cases := []struct {
+		query string
+		index string
+	}{
+		{`SELECT id FROM prompts p WHERE p.deleted_at IS NULL ORDER BY p.updated_at DESC LIMIT 20`, "prompts_deleted_updated_idx"},
+		{`SELECT id FROM prompt_versions WHERE prompt_id = 'p' AND status = 'draft' ORDER BY version_number DESC LIMIT 20`, "prompt_versions_status_idx"},
+		{`SELECT id FROM prompt_versions WHERE prompt_id = 'p' ORDER BY version_number DESC LIMIT 20`, "prompt_versions_unique_version"},
+		{`SELECT id FROM prompt_execution_logs WHERE prompt_id = 'p' AND created_at >= '2024-01-01' ORDER BY created_at DESC`, "prompt_execution_logs_lookup_idx"},
+		{`SELECT id FROM prompt_audit_logs WHERE prompt_id = 'p' ORDER BY created_at DESC LIMIT 20`, "prompt_audit_logs_prompt_idx"},
+		{`SELECT id FROM audit_logs ORDER BY created_at DESC, seq DESC LIMIT 20`, "audit_logs_created_idx"},
+	}
+	for _, tc := range cases {
+		rows, err := db.Query("EXPLAIN QUERY PLAN " + tc.query)
+		if err != nil {
+			t.Fatalf("explain %q: %v", tc.query, err)
+		}
+		var plan []string
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+				rows.Close()
+				t.Fatalf("scan plan: %v", err)
+			}
+			plan = append(plan, detail)
+		}
+		rows.Close()
+		if joined := strings.Join(plan, "; "); !strings.Contains(joined, tc.index) {
+			t.Fatalf("expected %q to use %s, plan: %s", tc.query, tc.index, joined)
+		}
+	}
+}
+
+func TestPromptBodyCompression(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repos := NewSQLRepositories(db, database.NewDialect("sqlite"), WithBodyCompression(64))
+	ctx := context.Background()
+
+	long := strings.Repeat("You are a helpful assistant. ", 100)
+	prompt := &domain.Prompt{ID: uuid.NewString(), Name: "compressed", Body: &long}
+	if err := repos.Prompts.Create(ctx, prompt); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	versions := map[string]string{
+		uuid.NewString(): long,
+		uuid.NewString(): "short body",
+		// 以压缩前缀开头的明文即使未达阈值也需压缩，读取时才不会被误判。
+		uuid.NewString(): compressedBodyPrefix + "AAAA",
+	}
+	number := 0
+	for id, body := range versions {
+		number++
+		if err := repos.PromptVersions.Create(ctx, &domain.PromptVersion{ID: id, PromptID: prompt.ID, VersionNumber: number, Body: body}); err != nil {
+			t.Fatalf("create version: %v", err)
+		}
+	}
+	var localeVersion string
+	for id := range versions {
+		localeVersion = id
+		break
+	}
+	if err := repos.PromptLocales.Upsert(ctx, &domain.PromptVersionLocale{ID: uuid.NewString(), VersionID: localeVersion, Locale: "en", Body: long}); err != nil {
+		t.Fatalf("upsert locale: %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRow(`SELECT body FROM prompts WHERE id = ?`, prompt.ID).Scan(&stored); err != nil {
+		t.Fatalf("read raw prompt body: %v", err)
+	}
+	if !strings.HasPrefix(stored, compressedBodyPrefix) || len(stored) >= len(long) {
+		t.Fatalf("expected compressed prompt body, got %d bytes", len(stored))
+	}
+	if err := db.QueryRow(`SELECT body FROM prompt_version_locales WHERE version_id = ?`, localeVersion).Scan(&stored); err != nil {
+		t.Fatalf("read raw locale body: %v", err)
+	}
+	if !strings.HasPrefix(stored, compressedBodyPrefix) {
+		t.Fatalf("expected compressed locale body")
+	}
+
+	// 读取不依赖阈值配置：关闭压缩的仓储同样能还原压缩内容。
+	for _, reader := range []*domain.Repositories{repos, NewSQLRepositories(db, database.NewDialect("sqlite"))} {
+		fetched, err := reader.Prompts.GetByID(ctx, prompt.ID)
+		if err != nil {
+			t.Fatalf("get prompt: %v", err)
+		}
+		if fetched.Body == nil || *fetched.Body != long {
+			t.Fatalf("prompt body not restored")
+		}
+		for id, body := range versions {
+			version, err := reader.PromptVersions.GetByID(ctx, id)
+			if err != nil {
+				t.Fatalf("get version: %v", err)
+			}
+			if version.Body != body {
+				t.Fatalf("expected version body %q, got %q", body, version.Body)
+			}
+		}
+		locale, err := reader.PromptLocales.GetByVersionAndLocale(ctx, localeVersion, "en")
+		if err != nil {
+			t.Fatalf("get locale: %v", err)
+		}
+		if locale.Body != long {
+			t.Fatalf("locale body not restored")
+		}
+	}
+
+	// 历史明文正文原样读取。
+	legacy := uuid.NewString()
+	if _, err := db.Exec(`INSERT INTO prompt_versions (id, prompt_id, version_number, body, status) VALUES (?, ?, ?, ?, 'draft')`, legacy, prompt.ID, 99, long); err != nil {
+		t.Fatalf("insert legacy version: %v", err)
+	}
+	version, err := repos.PromptVersions.GetByID(ctx, legacy)
+	if err != nil || version.Body != long {
+		t.Fatalf("expected legacy plaintext body, got %v", err)
+	}
+
+	// 损坏的压缩内容应返回错误而不是乱码。
+	encoded := bodyCodec{threshold: 1}.encode(long)
+	corrupt := encoded[:len(encoded)/8*4]
+	if _, err := db.Exec(`UPDATE prompt_versions SET body = ? WHERE id = ?`, corrupt, legacy); err != nil {
+		t.Fatalf("corrupt version: %v", err)
+	}
+	if _, err := repos.PromptVersions.GetByID(ctx, legacy); err == nil {
+		t.Fatalf("expected error for corrupt compressed body")
+	}
+}
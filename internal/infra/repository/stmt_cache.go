@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// stmtCacheMaxEntries 限制缓存的预编译语句数量。按条件拼接的查询（如按创建人过滤的列表）
+// 文本组合有限但并非固定，超出上限后直接执行，避免语句无限增长。
+const stmtCacheMaxEntries = 256
+
+// stmtCache 按 SQL 文本缓存 *sql.Stmt，供高频路径复用，省去每次请求的解析与规划开销。
+// *sql.Stmt 会在连接池的新连接上自动重新预编译，可安全地被多个 goroutine 共享。
+type stmtCache struct {
+	db    *sql.DB
+	mu    sync.RWMutex
+	stmts map[string]*sql.Stmt
+}
+
+func newStmtCache(db *sql.DB) *stmtCache {
+	return &stmtCache{db: db, stmts: make(map[string]*sql.Stmt)}
+}
+
+// prepare 返回 query 对应的预编译语句；缓存已满时返回 nil，调用方回退到直接执行。
+func (c *stmtCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mu.RLock()
+	stmt, ok := c.stmts[query]
+	c.mu.RUnlock()
+	if ok {
+		return stmt, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	if len(c.stmts) >= stmtCacheMaxEntries {
+		return nil, nil
+	}
+	// 预编译不应随单个请求取消而失败，缓存的语句会被后续请求复用。
+	stmt, err := c.db.PrepareContext(context.WithoutCancel(ctx), query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (c *stmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return c.db.ExecContext(ctx, query, args...)
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+func (c *stmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return c.db.QueryContext(ctx, query, args...)
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// QueryRowContext 与 *sql.DB 同名方法一致；预编译失败时回退为直接查询，错误在 Scan 时返回。
+func (c *stmtCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil || stmt == nil {
+		return c.db.QueryRowContext(ctx, query, args...)
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 计量仓储 ----
+
+type usageRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func (r *usageRepository) AddCounters(ctx context.Context, counters []domain.UsageCounter) (err error) {
+	if len(counters) == 0 {
+		return nil
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO usage_counters (workspace_id, period, metric, value, updated_at)
+VALUES (%s, %s, %s, %s, %s)
+ON CONFLICT (workspace_id, period, metric) DO UPDATE SET value = usage_counters.value + excluded.value, updated_at = excluded.updated_at`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := time.Now().UTC()
+	for _, counter := range counters {
+		if _, err = tx.ExecContext(ctx, query, counter.WorkspaceID, counter.Period, counter.Metric, counter.Value, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *usageRepository) TenantUsage(ctx context.Context, period string, from, to time.Time) ([]*domain.TenantUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name FROM organizations ORDER BY name ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	var usages []*domain.TenantUsage
+	byOrg := make(map[string]*domain.TenantUsage)
+	for rows.Next() {
+		usage := &domain.TenantUsage{Period: period}
+		if err := rows.Scan(&usage.OrganizationID, &usage.OrganizationName); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		usages = append(usages, usage)
+		byOrg[usage.OrganizationID] = usage
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	counterQuery := fmt.Sprintf(`SELECT w.organization_id, c.metric, SUM(c.value)
+FROM usage_counters c JOIN workspaces w ON w.id = c.workspace_id
+WHERE c.period = %s GROUP BY w.organization_id, c.metric`, ph.Next())
+	if err := r.scanOrgMetrics(ctx, counterQuery, []interface{}{period}, func(usage *domain.TenantUsage, metric string, value int64) {
+		if metric == domain.UsageMetricAPICalls {
+			usage.APICalls += value
+		}
+	}, byOrg); err != nil {
+		return nil, err
+	}
+
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	executionQuery := fmt.Sprintf(`SELECT w.organization_id, '%s', COUNT(1)
+FROM prompt_execution_logs l
+JOIN prompts p ON p.id = l.prompt_id
+JOIN workspaces w ON w.id = p.workspace_id
+WHERE l.created_at >= %s AND l.created_at < %s GROUP BY w.organization_id`, domain.UsageMetricExecutions, ph.Next(), ph.Next())
+	if err := r.scanOrgMetrics(ctx, executionQuery, []interface{}{r.dialect.TimestampArg(from), r.dialect.TimestampArg(to)}, func(usage *domain.TenantUsage, _ string, value int64) {
+		usage.Executions += value
+	}, byOrg); err != nil {
+		return nil, err
+	}
+
+	// SQLite 的 LENGTH 对 TEXT 按字符计数，转为 BLOB 后才是字节数。
+	bodyBytes := "LENGTH(CAST(v.body AS BLOB))"
+	if r.dialect.IsPostgres() {
+		bodyBytes = "OCTET_LENGTH(v.body)"
+	}
+	storageQuery := fmt.Sprintf(`SELECT w.organization_id, '%s', COALESCE(SUM(%s), 0)
+FROM prompt_versions v
+JOIN prompts p ON p.id = v.prompt_id
+JOIN workspaces w ON w.id = p.workspace_id
+GROUP BY w.organization_id`, domain.UsageMetricStorageBytes, bodyBytes)
+	if err := r.scanOrgMetrics(ctx, storageQuery, nil, func(usage *domain.TenantUsage, _ string, value int64) {
+		usage.StorageBytes += value
+	}, byOrg); err != nil {
+		return nil, err
+	}
+
+	return usages, nil
+}
+
+// scanOrgMetrics 执行返回 (organization_id, metric, value) 的聚合查询，并将结果累加到对应组织。
+func (r *usageRepository) scanOrgMetrics(ctx context.Context, query string, args []interface{}, apply func(*domain.TenantUsage, string, int64), byOrg map[string]*domain.TenantUsage) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			orgID, metric string
+			value         int64
+		)
+		if err := rows.Scan(&orgID, &metric, &value); err != nil {
+			return err
+		}
+		if usage, ok := byOrg[orgID]; ok {
+			apply(usage, metric, value)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *usageRepository) StorageReport(ctx context.Context, top int) (*domain.StorageReport, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name FROM organizations ORDER BY name ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	var tenants []*domain.TenantStorage
+	for rows.Next() {
+		tenant := &domain.TenantStorage{}
+		if err := rows.Scan(&tenant.OrganizationID, &tenant.OrganizationName); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	promptQuery := fmt.Sprintf(`SELECT p.id, p.name, p.workspace_id, w.organization_id, %s
+FROM prompts p JOIN workspaces w ON w.id = p.workspace_id`, r.byteLength("p.name", "p.description", "p.tags", "p.body"))
+	rows, err = r.db.QueryContext(ctx, promptQuery)
+	if err != nil {
+		return nil, err
+	}
+	var prompts []*domain.PromptStorage
+	byPrompt := make(map[string]*domain.PromptStorage)
+	for rows.Next() {
+		prompt := &domain.PromptStorage{Prompt: domain.StorageUsage{Rows: 1}}
+		if err := rows.Scan(&prompt.PromptID, &prompt.PromptName, &prompt.WorkspaceID, &prompt.OrganizationID, &prompt.Prompt.Bytes); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		prompts = append(prompts, prompt)
+		byPrompt[prompt.PromptID] = prompt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 非激活版本记为历史版本，即版本保留策略可回收的部分。
+	historyFlag := "CASE WHEN p.active_version_id = v.id THEN 0 ELSE 1 END"
+	versionQuery := fmt.Sprintf(`SELECT v.prompt_id, %s, COUNT(1), %s
+FROM prompt_versions v JOIN prompts p ON p.id = v.prompt_id
+GROUP BY v.prompt_id, %s`, historyFlag, r.byteSum("v.body", "v.variables_schema", "v.metadata"), historyFlag)
+	if err := r.scanPromptStorage(ctx, versionQuery, func(prompt *domain.PromptStorage, history bool, usage domain.StorageUsage) {
+		prompt.Versions.Add(usage)
+		if history {
+			prompt.HistoryVersions.Add(usage)
+		}
+	}, byPrompt); err != nil {
+		return nil, err
+	}
+
+	executionQuery := fmt.Sprintf(`SELECT prompt_id, 0, COUNT(1), %s FROM prompt_execution_logs GROUP BY prompt_id`,
+		r.byteSum("request_payload", "response_metadata"))
+	if err := r.scanPromptStorage(ctx, executionQuery, func(prompt *domain.PromptStorage, _ bool, usage domain.StorageUsage) {
+		prompt.ExecutionLogs.Add(usage)
+	}, byPrompt); err != nil {
+		return nil, err
+	}
+
+	auditQuery := fmt.Sprintf(`SELECT prompt_id, 0, COUNT(1), %s FROM prompt_audit_logs GROUP BY prompt_id`, r.byteSum("payload"))
+	if err := r.scanPromptStorage(ctx, auditQuery, func(prompt *domain.PromptStorage, _ bool, usage domain.StorageUsage) {
+		prompt.AuditLogs.Add(usage)
+	}, byPrompt); err != nil {
+		return nil, err
+	}
+
+	var systemAudit domain.StorageUsage
+	systemQuery := fmt.Sprintf(`SELECT COUNT(1), %s FROM audit_logs`, r.byteSum("payload"))
+	if err := r.db.QueryRowContext(ctx, systemQuery).Scan(&systemAudit.Rows, &systemAudit.Bytes); err != nil {
+		return nil, err
+	}
+
+	return domain.NewStorageReport(tenants, prompts, systemAudit, top), nil
+}
+
+// scanPromptStorage 执行返回 (prompt_id, history, rows, bytes) 的聚合查询，并将结果累加到对应 Prompt。
+func (r *usageRepository) scanPromptStorage(ctx context.Context, query string, apply func(*domain.PromptStorage, bool, domain.StorageUsage), byPrompt map[string]*domain.PromptStorage) error {
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			promptID string
+			history  int
+			usage    domain.StorageUsage
+		)
+		if err := rows.Scan(&promptID, &history, &usage.Rows, &usage.Bytes); err != nil {
+			return err
+		}
+		if prompt, ok := byPrompt[promptID]; ok {
+			apply(prompt, history == 1, usage)
+		}
+	}
+	return rows.Err()
+}
+
+// byteLength 返回各列字节数之和的 SQL 表达式，NULL 按 0 计算。
+func (r *usageRepository) byteLength(columns ...string) string {
+	parts := make([]string, 0, len(columns))
+	for _, column := range columns {
+		// SQLite 的 LENGTH 对 TEXT 按字符计数，转为 BLOB 后才是字节数。
+		expr := fmt.Sprintf("LENGTH(CAST(%s AS BLOB))", column)
+		if r.dialect.IsPostgres() {
+			expr = fmt.Sprintf("OCTET_LENGTH(%s)", column)
+		}
+		parts = append(parts, fmt.Sprintf("COALESCE(%s, 0)", expr))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// byteSum 返回各列字节数合计的聚合表达式。
+func (r *usageRepository) byteSum(columns ...string) string {
+	return fmt.Sprintf("COALESCE(SUM(%s), 0)", r.byteLength(columns...))
+}
+
+func (r *usageRepository) ListExports(ctx context.Context, exporter, period string) ([]*domain.UsageExport, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT organization_id, period, metric, exporter, value
+FROM usage_exports WHERE exporter = %s AND period = %s`, ph.Next(), ph.Next())
+	rows, err := r.db.QueryContext(ctx, query, exporter, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []*domain.UsageExport
+	for rows.Next() {
+		var export domain.UsageExport
+		if err := rows.Scan(&export.OrganizationID, &export.Period, &export.Metric, &export.Exporter, &export.Value); err != nil {
+			return nil, err
+		}
+		exports = append(exports, &export)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *usageRepository) SaveExports(ctx context.Context, exports []*domain.UsageExport) (err error) {
+	if len(exports) == 0 {
+		return nil
+	}
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO usage_exports (organization_id, period, metric, exporter, value, exported_at)
+VALUES (%s, %s, %s, %s, %s, %s)
+ON CONFLICT (organization_id, period, metric, exporter) DO UPDATE SET value = excluded.value, exported_at = excluded.exported_at`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := time.Now().UTC()
+	for _, export := range exports {
+		if _, err = tx.ExecContext(ctx, query, export.OrganizationID, export.Period, export.Metric, export.Exporter, export.Value, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
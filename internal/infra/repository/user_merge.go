@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// createdByColumns 列出以操作者标识记录归属的列，迁移用户时统一改写。
+// 审计表（audit_logs、prompt_audit_logs）带哈希链，改写会破坏防篡改校验，因此不在此列。
+var createdByColumns = []struct {
+	table  string
+	column string
+}{
+	{"prompts", "created_by"},
+	{"prompt_versions", "created_by"},
+	{"prompt_version_locales", "created_by"},
+	{"prompt_dependencies", "created_by"},
+	{"pipelines", "created_by"},
+	{"pipeline_versions", "created_by"},
+	{"organizations", "created_by"},
+	{"workspaces", "created_by"},
+	{"invitations", "invited_by"},
+	{"prompt_alert_rules", "created_by"},
+	{"api_keys", "created_by"},
+	{"prompt_templates", "created_by"},
+	{"prompt_review_policies", "updated_by"},
+	{"prompt_version_approvals", "reviewer"},
+}
+
+// userIDColumns 列出直接引用用户 ID 的列。
+var userIDColumns = []struct {
+	table  string
+	column string
+}{
+	{"user_identities", "user_id"},
+	{"login_events", "user_id"},
+	{"api_keys", "user_id"},
+	{"prompt_execution_logs", "user_id"},
+	{"invitations", "accepted_user_id"},
+}
+
+func (r *userRepository) Merge(ctx context.Context, merge domain.UserReassignment) (moved map[string]int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	moved, err = reassignCreatedBy(ctx, tx, r.dialect, merge.FromActors, merge.ToActor)
+	if err != nil {
+		return nil, err
+	}
+	if err = reassignPromptOwner(ctx, tx, r.dialect, moved, merge.FromUserID, merge.ToUserID); err != nil {
+		return nil, err
+	}
+
+	for _, ref := range userIDColumns {
+		ph := database.NewPlaceholderBuilder(r.dialect)
+		query := fmt.Sprintf(`UPDATE %s SET %s = %s WHERE %s = %s`, ref.table, ref.column, ph.Next(), ref.column, ph.Next())
+		if err = execCount(ctx, tx, moved, ref.table, query, merge.ToUserID, merge.FromUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 目标用户已是成员的工作区保留其原角色，其余成员关系整体迁移。
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	memberQuery := fmt.Sprintf(`UPDATE workspace_members SET user_id = %s, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = %s AND workspace_id NOT IN (SELECT workspace_id FROM workspace_members WHERE user_id = %s)`,
+		ph.Next(), ph.Next(), ph.Next())
+	if err = execCount(ctx, tx, moved, "workspace_members", memberQuery, merge.ToUserID, merge.FromUserID, merge.ToUserID); err != nil {
+		return nil, err
+	}
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM workspace_members WHERE user_id = %s`, ph.Next()), merge.FromUserID); err != nil {
+		return nil, err
+	}
+
+	// 草稿同理：目标用户在同一 Prompt 上已有草稿时保留目标用户的版本。
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	draftQuery := fmt.Sprintf(`UPDATE prompt_drafts SET user_id = %s
+WHERE user_id = %s AND prompt_id NOT IN (SELECT prompt_id FROM prompt_drafts WHERE user_id = %s)`,
+		ph.Next(), ph.Next(), ph.Next())
+	if err = execCount(ctx, tx, moved, "prompt_drafts", draftQuery, merge.ToUserID, merge.FromUserID, merge.ToUserID); err != nil {
+		return nil, err
+	}
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM prompt_drafts WHERE user_id = %s`, ph.Next()), merge.FromUserID); err != nil {
+		return nil, err
+	}
+
+	// 评审批准同理：两人批准过同一版本时只保留目标用户的批准，避免合并后重复计数。
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	approvalQuery := fmt.Sprintf(`UPDATE prompt_version_approvals SET reviewer_id = %s
+WHERE reviewer_id = %s AND version_id NOT IN (SELECT version_id FROM prompt_version_approvals WHERE reviewer_id = %s)`,
+		ph.Next(), ph.Next(), ph.Next())
+	if err = execCount(ctx, tx, moved, "prompt_version_approvals", approvalQuery, merge.ToUserID, merge.FromUserID, merge.ToUserID); err != nil {
+		return nil, err
+	}
+	ph = database.NewPlaceholderBuilder(r.dialect)
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM prompt_version_approvals WHERE reviewer_id = %s`, ph.Next()), merge.FromUserID); err != nil {
+		return nil, err
+	}
+	if err = reassignPolicyReviewers(ctx, tx, r.dialect, moved, merge.FromUserID, merge.ToUserID); err != nil {
+		return nil, err
+	}
+
+	if err = updateUserStatusTx(ctx, tx, r.dialect, merge.FromUserID, merge.FromStatus); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+func (r *userRepository) TransferOwnership(ctx context.Context, transfer domain.UserReassignment) (moved map[string]int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	moved, err = reassignCreatedBy(ctx, tx, r.dialect, transfer.FromActors, transfer.ToActor)
+	if err != nil {
+		return nil, err
+	}
+	if err = reassignPromptOwner(ctx, tx, r.dialect, moved, transfer.FromUserID, transfer.ToUserID); err != nil {
+		return nil, err
+	}
+	if err = updateUserStatusTx(ctx, tx, r.dialect, transfer.FromUserID, transfer.FromStatus); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+func updateUserStatusTx(ctx context.Context, tx *sql.Tx, dialect database.Dialect, userID, status string) error {
+	ph := database.NewPlaceholderBuilder(dialect)
+	query := fmt.Sprintf(`UPDATE users SET status = %s, updated_at = CURRENT_TIMESTAMP WHERE id = %s`, ph.Next(), ph.Next())
+	result, err := tx.ExecContext(ctx, query, status, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// reassignCreatedBy 把 fromActors 名下的归属列改写为 toActor，返回按表统计的行数。
+func reassignCreatedBy(ctx context.Context, tx *sql.Tx, dialect database.Dialect, fromActors []string, toActor string) (map[string]int64, error) {
+	moved := make(map[string]int64)
+	if len(fromActors) == 0 {
+		return moved, nil
+	}
+	for _, ref := range createdByColumns {
+		ph := database.NewPlaceholderBuilder(dialect)
+		args := []interface{}{toActor}
+		target := ph.Next()
+		placeholders := make([]string, 0, len(fromActors))
+		for _, actor := range fromActors {
+			placeholders = append(placeholders, ph.Next())
+			args = append(args, actor)
+		}
+		query := fmt.Sprintf(`UPDATE %s SET %s = %s WHERE %s IN (%s)`, ref.table, ref.column, target, ref.column, strings.Join(placeholders, ", "))
+		if err := execCount(ctx, tx, moved, ref.table, query, args...); err != nil {
+			return nil, err
+		}
+	}
+	return moved, nil
+}
+
+// reassignPromptOwner 把负责人为 fromUserID 的 Prompt 转交给 toUserID，团队负责人不受影响。
+func reassignPromptOwner(ctx context.Context, tx *sql.Tx, dialect database.Dialect, moved map[string]int64, fromUserID, toUserID string) error {
+	ph := database.NewPlaceholderBuilder(dialect)
+	query := fmt.Sprintf(`UPDATE prompts SET owner_id = %s WHERE owner_type = %s AND owner_id = %s`, ph.Next(), ph.Next(), ph.Next())
+	return execCount(ctx, tx, moved, "prompt_owners", query, toUserID, domain.PromptOwnerUser, fromUserID)
+}
+
+// reassignPolicyReviewers 把评审策略 reviewers 列表中的用户 fromUserID 替换为 toUserID（已在列表中时去重），
+// 团队评审人不受影响。reviewers 为 JSON 数组，先按引号包围的 ID 粗筛，再解码后精确改写。
+func reassignPolicyReviewers(ctx context.Context, tx *sql.Tx, dialect database.Dialect, moved map[string]int64, fromUserID, toUserID string) error {
+	ph := database.NewPlaceholderBuilder(dialect)
+	query := fmt.Sprintf(`SELECT prompt_id, reviewers FROM prompt_review_policies WHERE reviewers LIKE %s ESCAPE '\'`, ph.Next())
+	rows, err := tx.QueryContext(ctx, query, jsonElementPattern(fromUserID))
+	if err != nil {
+		return err
+	}
+	updates := make(map[string][]domain.PromptOwner)
+	for rows.Next() {
+		var promptID, raw string
+		if err := rows.Scan(&promptID, &raw); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		var reviewers []domain.PromptOwner
+		if err := json.Unmarshal([]byte(raw), &reviewers); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if rewritten, ok := reassignReviewers(reviewers, fromUserID, toUserID); ok {
+			updates[promptID] = rewritten
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for promptID, reviewers := range updates {
+		encoded, err := json.Marshal(reviewers)
+		if err != nil {
+			return err
+		}
+		ph := database.NewPlaceholderBuilder(dialect)
+		update := fmt.Sprintf(`UPDATE prompt_review_policies SET reviewers = %s WHERE prompt_id = %s`, ph.Next(), ph.Next())
+		if err := execCount(ctx, tx, moved, "prompt_reviewers", update, string(encoded), promptID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reassignReviewers 返回把用户评审人 from 替换为 to 后的列表，列表中没有 from 时返回 false。
+func reassignReviewers(reviewers []domain.PromptOwner, from, to string) ([]domain.PromptOwner, bool) {
+	source := domain.PromptOwner{Type: domain.PromptOwnerUser, ID: from}
+	target := domain.PromptOwner{Type: domain.PromptOwnerUser, ID: to}
+	if !slices.Contains(reviewers, source) {
+		return reviewers, false
+	}
+	rewritten := make([]domain.PromptOwner, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if reviewer == source {
+			reviewer = target
+		}
+		if !slices.Contains(rewritten, reviewer) {
+			rewritten = append(rewritten, reviewer)
+		}
+	}
+	return rewritten, true
+}
+
+func execCount(ctx context.Context, tx *sql.Tx, counts map[string]int64, key, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		counts[key] += rows
+	}
+	return nil
+}
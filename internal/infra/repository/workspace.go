@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+)
+
+// ---- 组织与工作区仓储 ----
+
+type workspaceRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+const (
+	organizationColumns = `id, name, slug, created_by, created_at, updated_at`
+	workspaceColumns    = `w.id, w.organization_id, w.name, w.slug, w.created_by, w.created_at, w.updated_at`
+)
+
+func (r *workspaceRepository) CreateOrganization(ctx context.Context, org *domain.Organization) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO organizations (id, name, slug, created_by) VALUES (%s, %s, %s, %s)`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query, org.ID, org.Name, org.Slug, nullableString(org.CreatedBy))
+	return err
+}
+
+func (r *workspaceRepository) GetOrganization(ctx context.Context, orgID string) (*domain.Organization, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM organizations WHERE id = %s`, organizationColumns, ph.Next())
+	org, err := scanOrganization(r.db.QueryRowContext(ctx, query, orgID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return org, err
+}
+
+func (r *workspaceRepository) ListOrganizations(ctx context.Context) ([]*domain.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM organizations ORDER BY created_at ASC, name ASC`, organizationColumns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []*domain.Organization
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (r *workspaceRepository) CreateWorkspace(ctx context.Context, workspace *domain.Workspace, owner *domain.WorkspaceMember) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO workspaces (id, organization_id, name, slug, created_by) VALUES (%s, %s, %s, %s, %s)`,
+		ph.Next(), ph.Next(), ph.Next(), ph.Next(), ph.Next())
+	if _, err = tx.ExecContext(ctx, query, workspace.ID, workspace.OrganizationID, workspace.Name, workspace.Slug, nullableString(workspace.CreatedBy)); err != nil {
+		return err
+	}
+
+	if owner != nil {
+		ph = database.NewPlaceholderBuilder(r.dialect)
+		memberQuery := fmt.Sprintf(`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (%s, %s, %s)`,
+			ph.Next(), ph.Next(), ph.Next())
+		if _, err = tx.ExecContext(ctx, memberQuery, workspace.ID, owner.UserID, owner.Role); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *workspaceRepository) GetWorkspace(ctx context.Context, workspaceID string) (*domain.Workspace, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM workspaces w WHERE w.id = %s`, workspaceColumns, ph.Next())
+	workspace, err := scanWorkspace(r.db.QueryRowContext(ctx, query, workspaceID), false)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return workspace, err
+}
+
+func (r *workspaceRepository) ListWorkspaces(ctx context.Context, orgID string) ([]*domain.Workspace, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s FROM workspaces w`, workspaceColumns)
+	var args []interface{}
+	if orgID != "" {
+		query += fmt.Sprintf(" WHERE w.organization_id = %s", ph.Next())
+		args = append(args, orgID)
+	}
+	query += " ORDER BY w.created_at ASC, w.name ASC"
+	return r.queryWorkspaces(ctx, query, false, args...)
+}
+
+func (r *workspaceRepository) ListWorkspacesForUser(ctx context.Context, userID string) ([]*domain.Workspace, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT %s, m.role FROM workspaces w
+JOIN workspace_members m ON m.workspace_id = w.id
+WHERE m.user_id = %s ORDER BY w.created_at ASC, w.name ASC`, workspaceColumns, ph.Next())
+	return r.queryWorkspaces(ctx, query, true, userID)
+}
+
+func (r *workspaceRepository) queryWorkspaces(ctx context.Context, query string, withRole bool, args ...interface{}) ([]*domain.Workspace, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []*domain.Workspace
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows, withRole)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) UpsertMember(ctx context.Context, member *domain.WorkspaceMember) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (%s, %s, %s)
+ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = excluded.role, updated_at = CURRENT_TIMESTAMP`,
+		ph.Next(), ph.Next(), ph.Next())
+	_, err := r.db.ExecContext(ctx, query, member.WorkspaceID, member.UserID, member.Role)
+	return err
+}
+
+func (r *workspaceRepository) GetMember(ctx context.Context, workspaceID, userID string) (*domain.WorkspaceMember, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT m.workspace_id, m.user_id, u.email, m.role, m.created_at, m.updated_at
+FROM workspace_members m LEFT JOIN users u ON u.id = m.user_id
+WHERE m.workspace_id = %s AND m.user_id = %s`, ph.Next(), ph.Next())
+	member, err := scanWorkspaceMember(r.db.QueryRowContext(ctx, query, workspaceID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return member, err
+}
+
+func (r *workspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]*domain.WorkspaceMember, error) {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`SELECT m.workspace_id, m.user_id, u.email, m.role, m.created_at, m.updated_at
+FROM workspace_members m LEFT JOIN users u ON u.id = m.user_id
+WHERE m.workspace_id = %s ORDER BY m.created_at ASC`, ph.Next())
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*domain.WorkspaceMember
+	for rows.Next() {
+		member, err := scanWorkspaceMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	ph := database.NewPlaceholderBuilder(r.dialect)
+	query := fmt.Sprintf(`DELETE FROM workspace_members WHERE workspace_id = %s AND user_id = %s`, ph.Next(), ph.Next())
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrganization(row rowScanner) (*domain.Organization, error) {
+	var (
+		org       domain.Organization
+		createdBy sql.NullString
+	)
+	if err := row.Scan(&org.ID, &org.Name, &org.Slug, &createdBy, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		return nil, err
+	}
+	org.CreatedBy = stringPtr(createdBy)
+	return &org, nil
+}
+
+func scanWorkspace(row rowScanner, withRole bool) (*domain.Workspace, error) {
+	var (
+		workspace domain.Workspace
+		createdBy sql.NullString
+	)
+	dest := []interface{}{&workspace.ID, &workspace.OrganizationID, &workspace.Name, &workspace.Slug, &createdBy, &workspace.CreatedAt, &workspace.UpdatedAt}
+	if withRole {
+		dest = append(dest, &workspace.Role)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	workspace.CreatedBy = stringPtr(createdBy)
+	return &workspace, nil
+}
+
+func scanWorkspaceMember(row rowScanner) (*domain.WorkspaceMember, error) {
+	var (
+		member domain.WorkspaceMember
+		email  sql.NullString
+	)
+	if err := row.Scan(&member.WorkspaceID, &member.UserID, &email, &member.Role, &member.CreatedAt, &member.UpdatedAt); err != nil {
+		return nil, err
+	}
+	member.Email = email.String
+	return &member, nil
+}
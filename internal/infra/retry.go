@@ -0,0 +1,44 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/internal/config"
+	"go.uber.org/zap"
+)
+
+// connectWithRetry 按指数退避重复调用 connect，直至成功、累计等待超过 MaxWait 或 ctx 取消。
+// 每次失败都记录尝试次数，便于在编排启动顺序问题中定位依赖何时就绪。
+func connectWithRetry(ctx context.Context, cfg config.StartupConfig, logger *zap.Logger, dependency string, connect func(ctx context.Context) error) error {
+	deadline := time.Now().Add(cfg.MaxWait)
+	backoff := cfg.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := connect(ctx)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("dependency connected after retry", zap.String("dependency", dependency), zap.Int("attempts", attempt))
+			}
+			return nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("%s unavailable after %d attempts: %w", dependency, attempt, err)
+		}
+		logger.Warn("dependency unavailable, retrying",
+			zap.String("dependency", dependency),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s unavailable after %d attempts: %w", dependency, attempt, ctx.Err())
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, cfg.MaxBackoff)
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+const (
+	// APIKeyHeader 为携带 API Key 的请求头。
+	APIKeyHeader = "X-API-Key"
+	// APIKeyContextKey 在上下文中存储通过认证的 API Key ID，Bearer 请求中为空。
+	APIKeyContextKey = "api_key_id"
+	// APIKeyScopesContextKey 在上下文中存储 API Key 的权限范围。
+	APIKeyScopesContextKey = "api_key_scopes"
+	// APIKeyRateLimitContextKey 在上下文中存储 API Key 的每分钟请求上限（0 表示默认值）。
+	APIKeyRateLimitContextKey = "api_key_rate_limit"
+	// APIKeyDailyQuotaContextKey 在上下文中存储 API Key 的每 24 小时请求配额（0 表示不限）。
+	APIKeyDailyQuotaContextKey = "api_key_daily_quota"
+)
+
+// APIKeyPrincipal 为 API Key 校验通过后注入上下文的身份信息。
+type APIKeyPrincipal struct {
+	KeyID     string
+	UserID    string
+	Email     string
+	Role      string
+	Scopes    []string
+	RateLimit int
+	// DailyQuota 为每 24 小时的请求配额，0 表示不限。
+	DailyQuota int
+}
+
+// APIKeyAuthenticator 校验 API Key 明文并返回所属身份。
+type APIKeyAuthenticator func(ctx context.Context, key string) (*APIKeyPrincipal, error)
+
+// AuthGuardWithAPIKeys 请求携带 X-API-Key 时按 API Key 认证，否则交给 bearer 校验访问令牌。
+func AuthGuardWithAPIKeys(bearer gin.HandlerFunc, authenticate APIKeyAuthenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(APIKeyHeader)
+		if key == "" {
+			bearer(ctx)
+			return
+		}
+
+		principal, err := authenticate(ctx, key)
+		if err != nil || principal == nil {
+			httpx.RespondError(ctx, http.StatusUnauthorized, "UNAUTHORIZED", "API Key 无效", nil)
+			return
+		}
+
+		ctx.Set(UserContextKey, principal.UserID)
+		ctx.Set(UserEmailContextKey, principal.Email)
+		ctx.Set(UserRoleContextKey, principal.Role)
+		ctx.Set(APIKeyContextKey, principal.KeyID)
+		ctx.Set(APIKeyScopesContextKey, principal.Scopes)
+		ctx.Set(APIKeyRateLimitContextKey, principal.RateLimit)
+		ctx.Set(APIKeyDailyQuotaContextKey, principal.DailyQuota)
+		ctx.Next()
+	}
+}
+
+// RequireScopes 要求 API Key 请求具备任一指定范围（admin 视为全部范围）；Bearer 请求不受影响，仍按角色控制。
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString(APIKeyContextKey) == "" {
+			ctx.Next()
+			return
+		}
+		for _, granted := range ctx.GetStringSlice(APIKeyScopesContextKey) {
+			if granted == domain.APIKeyScopeAdmin {
+				ctx.Next()
+				return
+			}
+			for _, scope := range scopes {
+				if granted == scope {
+					ctx.Next()
+					return
+				}
+			}
+		}
+		httpx.RespondError(ctx, http.StatusForbidden, "INSUFFICIENT_SCOPE", "API Key 缺少所需权限范围", gin.H{"required": scopes})
+	}
+}
@@ -25,8 +25,18 @@ const (
 	RoleViewer = "viewer"
 )
 
-// AuthGuard 校验 Bearer Token 并注入用户/租户信息。
+// TokenParser 解析并校验访问令牌。
+type TokenParser func(token string) (*authutil.Claims, error)
+
+// AuthGuard 使用共享密钥校验 Bearer Token 并注入用户/租户信息。
 func AuthGuard(accessSecret string) gin.HandlerFunc {
+	return AuthGuardWithParser(func(token string) (*authutil.Claims, error) {
+		return authutil.ParseToken(token, accessSecret)
+	})
+}
+
+// AuthGuardWithParser 使用自定义解析器（例如支持 kid 与密钥轮换）校验 Bearer Token。
+func AuthGuardWithParser(parse TokenParser) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		header := ctx.GetHeader("Authorization")
 		if header == "" {
@@ -39,7 +49,7 @@ func AuthGuard(accessSecret string) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := authutil.ParseToken(parts[1], accessSecret)
+		claims, err := parse(parts[1])
 		if err != nil || claims.TokenType != "access" {
 			httpx.RespondError(ctx, http.StatusUnauthorized, "UNAUTHORIZED", "令牌无效", nil)
 			return
@@ -8,8 +8,14 @@ import (
 
 // LimitRequestBody 限制请求体大小，超出时返回 413。
 func LimitRequestBody(maxBytes int64) gin.HandlerFunc {
+    return LimitRequestBodyBy(func(*gin.Context) int64 { return maxBytes })
+}
+
+// LimitRequestBodyBy 按请求决定请求体上限，便于不同路由分组使用不同限制。
+// MaxBytesReader 嵌套时以较小者为准，因此需在引擎级统一挂载，而不是在分组上叠加。
+func LimitRequestBodyBy(limitFor func(ctx *gin.Context) int64) gin.HandlerFunc {
     return func(ctx *gin.Context) {
-        if maxBytes > 0 {
+        if maxBytes := limitFor(ctx); maxBytes > 0 {
             ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
         }
         ctx.Next()
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// FreezeOverride 为指定角色的用户在请求上下文中标记冻结豁免，需挂在认证之后。
+func FreezeOverride(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[strings.ToLower(role)] = struct{}{}
+	}
+
+	return func(ctx *gin.Context) {
+		if _, ok := allowed[strings.ToLower(ctx.GetString(UserRoleContextKey))]; ok {
+			ctx.Request = ctx.Request.WithContext(domain.WithFreezeOverride(ctx.Request.Context()))
+		}
+		ctx.Next()
+	}
+}
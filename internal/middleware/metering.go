@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// UsageRecorder 记录一次计量事件，实现需并发安全且不阻塞请求。
+type UsageRecorder func(workspaceID, metric string, n int64)
+
+// MeterAPICalls 在请求处理完成后按当前工作区计一次 API 调用；认证失败（401/403）与服务端错误不计费。
+// 需置于 WorkspaceResolver 之后，未解析工作区的接口计入默认工作区。
+func MeterAPICalls(record UsageRecorder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		if status == http.StatusUnauthorized || status == http.StatusForbidden || status >= http.StatusInternalServerError {
+			return
+		}
+		workspaceID := ctx.GetString(WorkspaceContextKey)
+		if workspaceID == "" {
+			workspaceID = domain.DefaultWorkspaceID
+		}
+		record(workspaceID, domain.UsageMetricAPICalls, 1)
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProbeContextKey 在上下文中标记健康检查、指标抓取等探测请求，限流与请求日志据此跳过。
+const ProbeContextKey = "probe_request"
+
+// ProbeAllowlist 描述哪些请求视为探测：Paths 为精确匹配的路径（如 /healthz、/metrics），
+// 这些请求不限流也不记录请求日志；来自 TrustedNetworks 的请求（如内网的合成监控）只跳过限流。
+// TrustedNetworks 按 TCP 对端地址匹配，不读取 X-Forwarded-For 等可由客户端伪造的头。
+type ProbeAllowlist struct {
+	Paths           []string
+	TrustedNetworks []*net.IPNet
+}
+
+// probeKind 区分探测请求的豁免范围。
+type probeKind int
+
+const (
+	probeNone probeKind = iota
+	// probeTrusted 来自可信网络，只跳过限流。
+	probeTrusted
+	// probePath 命中探测路径，跳过限流与请求日志。
+	probePath
+)
+
+// MarkProbes 按白名单标记探测请求，需挂在限流与请求日志之前（通常为引擎的第一批中间件）。
+func MarkProbes(allowlist ProbeAllowlist) gin.HandlerFunc {
+	paths := make(map[string]struct{}, len(allowlist.Paths))
+	for _, path := range allowlist.Paths {
+		paths[path] = struct{}{}
+	}
+
+	return func(ctx *gin.Context) {
+		if _, ok := paths[ctx.Request.URL.Path]; ok {
+			ctx.Set(ProbeContextKey, probePath)
+		} else if ip := net.ParseIP(ctx.RemoteIP()); ip != nil {
+			for _, network := range allowlist.TrustedNetworks {
+				if network.Contains(ip) {
+					ctx.Set(ProbeContextKey, probeTrusted)
+					break
+				}
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// SkipRateLimit 表示请求命中探测白名单，不参与限流。
+func SkipRateLimit(ctx *gin.Context) bool {
+	kind, _ := ctx.Get(ProbeContextKey)
+	return kind == probePath || kind == probeTrusted
+}
+
+// SkipRequestLog 表示请求命中探测路径，不记录请求日志。
+func SkipRequestLog(ctx *gin.Context) bool {
+	kind, _ := ctx.Get(ProbeContextKey)
+	return kind == probePath
+}
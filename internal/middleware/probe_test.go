@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ulule/limiter/v3"
+	memorystore "github.com/ulule/limiter/v3/drivers/store/memory"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMarkProbesBypassesRateLimitAndRequestLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, trusted, _ := net.ParseCIDR("10.0.0.0/8")
+	core, logs := observer.New(zap.InfoLevel)
+	l := limiter.New(memorystore.NewStore(), limiter.Rate{Period: time.Hour, Limit: 1})
+
+	router := gin.New()
+	router.Use(MarkProbes(ProbeAllowlist{Paths: []string{"/healthz"}, TrustedNetworks: []*net.IPNet{trusted}}))
+	router.Use(RequestLogger(zap.New(core)))
+	router.Use(RateLimit(l, KeyByClientIP()))
+	for _, path := range []string{"/healthz", "/api"} {
+		router.GET(path, func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, "ok")
+		})
+	}
+
+	serve := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := serve("/healthz", "192.0.2.1:1234"); code != http.StatusOK {
+			t.Fatalf("probe path should never be limited, got %d", code)
+		}
+		if code := serve("/api", "10.1.2.3:1234"); code != http.StatusOK {
+			t.Fatalf("trusted network should never be limited, got %d", code)
+		}
+	}
+	if logs.FilterField(zap.String("path", "/healthz")).Len() != 0 {
+		t.Fatalf("probe path should not be logged")
+	}
+	if logs.FilterField(zap.String("path", "/api")).Len() != 3 {
+		t.Fatalf("trusted network requests should still be logged")
+	}
+
+	if code := serve("/api", "192.0.2.1:1234"); code != http.StatusOK {
+		t.Fatalf("first regular request should pass, got %d", code)
+	}
+
+	if code := serve("/api", "192.0.2.1:1234"); code != http.StatusTooManyRequests {
+		t.Fatalf("regular requests should still be limited, got %d", code)
+	}
+}
+
+func TestMarkProbesIgnoresForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, trusted, _ := net.ParseCIDR("10.0.0.0/8")
+
+	router := gin.New()
+	router.Use(MarkProbes(ProbeAllowlist{TrustedNetworks: []*net.IPNet{trusted}}))
+	router.GET("/api", func(ctx *gin.Context) {
+		if SkipRateLimit(ctx) {
+			ctx.String(http.StatusOK, "trusted")
+			return
+		}
+		ctx.String(http.StatusOK, "regular")
+	})
+
+	serve := func(remoteAddr, forwardedFor string) string {
+		req := httptest.NewRequest(http.MethodGet, "/api", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	// 按 TCP 对端地址判断，伪造 X-Forwarded-For 不能冒充可信网段。
+	if got := serve("192.0.2.1:1234", "10.0.0.1"); got != "regular" {
+		t.Fatalf("spoofed X-Forwarded-For should not be trusted, got %s", got)
+	}
+	if got := serve("10.1.2.3:1234", "192.0.2.1"); got != "trusted" {
+		t.Fatalf("trusted peer should be marked, got %s", got)
+	}
+}
@@ -1,50 +1,175 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ulule/limiter/v3"
 	"github.com/zacharykka/prompt-manager/pkg/httpx"
+	"github.com/zacharykka/prompt-manager/pkg/metrics"
 )
 
 // KeyFunc 提取用于限流的 key。
 type KeyFunc func(*gin.Context) string
 
+// RateLimitDecisions 按 key 类别统计限流放行（allowed）、拒绝（blocked）与探测请求豁免（bypassed）次数。
+var RateLimitDecisions = metrics.NewCounterVec(
+	"prompt_manager_rate_limit_requests_total",
+	"Requests evaluated by the rate limiter, by key class and outcome.",
+	"key_class", "outcome",
+)
+
+func init() {
+	metrics.Default.MustRegister(RateLimitDecisions)
+}
+
+// RateLimitOption 定义限流中间件的可选项。
+type RateLimitOption func(*rateLimitConfig)
+
+type rateLimitConfig struct {
+	keyClass string
+}
+
+// WithKeyClass 设置指标中的 key_class 标签，用于区分不同限流器（如 general、login），默认 default。
+func WithKeyClass(class string) RateLimitOption {
+	return func(cfg *rateLimitConfig) {
+		if class != "" {
+			cfg.keyClass = class
+		}
+	}
+}
+
 // RateLimit 返回基于 limiter 的 Gin 中间件。
-func RateLimit(l *limiter.Limiter, keyFunc KeyFunc) gin.HandlerFunc {
+// 同时输出 X-RateLimit-*（Reset 为 Unix 时间戳）与 IETF 草案的 RateLimit-*（Reset 为剩余秒数），429 时附带 Retry-After。
+func RateLimit(l *limiter.Limiter, keyFunc KeyFunc, opts ...RateLimitOption) gin.HandlerFunc {
 	if keyFunc == nil {
 		keyFunc = KeyByClientIP()
 	}
+	cfg := rateLimitConfig{keyClass: "default"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	policy := fmt.Sprintf("%d;w=%d", l.Rate.Limit, int64(l.Rate.Period/time.Second))
 
 	return func(ctx *gin.Context) {
 		key := keyFunc(ctx)
 		if key == "" {
 			key = ctx.ClientIP()
 		}
-
-		context, err := l.Get(ctx, key)
-		if err != nil {
-			httpx.RespondError(ctx, http.StatusInternalServerError, "RATE_LIMIT_ERROR", err.Error(), nil)
-			ctx.Abort()
-			return
+		if applyRateLimit(ctx, l, key, cfg.keyClass, policy) {
+			ctx.Next()
 		}
+	}
+}
 
-		ctx.Writer.Header().Set("X-RateLimit-Limit", strconv.FormatInt(context.Limit, 10))
-		ctx.Writer.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(context.Remaining, 10))
-		ctx.Writer.Header().Set("X-RateLimit-Reset", strconv.FormatInt(context.Reset, 10))
+// RateLimitByAPIKey 仅对 API Key 请求生效，按密钥独立计数；密钥未设置 rate_limit 时使用 defaultRate。
+// 密钥设置了 daily_quota 时另按 24 小时窗口计数，耗尽后返回 429 API_KEY_QUOTA_EXCEEDED。
+// 需挂在认证中间件之后，使一个失控的集成不会挤占其他密钥与普通用户的配额。
+func RateLimitByAPIKey(store limiter.Store, defaultRate limiter.Rate, opts ...RateLimitOption) gin.HandlerFunc {
+	cfg := rateLimitConfig{keyClass: "api_key"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	keyFunc := KeyByAPIKey()
+	var limiters, quotas sync.Map
 
-		if context.Reached {
-			httpx.RespondError(ctx, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "请求过于频繁，请稍后再试", nil)
-			ctx.Abort()
+	return func(ctx *gin.Context) {
+		if ctx.GetString(APIKeyContextKey) == "" {
+			ctx.Next()
+			return
+		}
+		rate := defaultRate
+		if limit := ctx.GetInt(APIKeyRateLimitContextKey); limit > 0 {
+			rate.Limit = int64(limit)
+		}
+		cached, _ := limiters.LoadOrStore(rate.Limit, limiter.New(store, rate))
+		l := cached.(*limiter.Limiter)
+		if !applyRateLimit(ctx, l, keyFunc(ctx), cfg.keyClass, fmt.Sprintf("%d;w=%d", rate.Limit, int64(rate.Period/time.Second))) {
+			return
+		}
+		// 每日配额在每分钟限流之后计数，被限流拒绝的请求不消耗配额。
+		if quota := int64(ctx.GetInt(APIKeyDailyQuotaContextKey)); quota > 0 && !applyDailyQuota(ctx, &quotas, store, quota, cfg.keyClass) {
 			return
 		}
-
 		ctx.Next()
 	}
 }
 
+// applyDailyQuota 按密钥累计 24 小时窗口内的请求数，配额耗尽时以 429 终止请求并返回 false。
+// 配额计数不写入 RateLimit-* 响应头，避免覆盖每分钟限流的信息；探测请求同样豁免。
+func applyDailyQuota(ctx *gin.Context, quotas *sync.Map, store limiter.Store, quota int64, keyClass string) bool {
+	class := keyClass + "_quota"
+	if SkipRateLimit(ctx) {
+		RateLimitDecisions.Inc(class, "bypassed")
+		return true
+	}
+	cached, _ := quotas.LoadOrStore(quota, limiter.New(store, limiter.Rate{Period: 24 * time.Hour, Limit: quota}))
+	context, err := cached.(*limiter.Limiter).Get(ctx, "api_key_quota:"+ctx.GetString(APIKeyContextKey))
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusInternalServerError, "RATE_LIMIT_ERROR", err.Error(), nil)
+		ctx.Abort()
+		return false
+	}
+	if context.Reached {
+		RateLimitDecisions.Inc(class, "blocked")
+		resetAfter := context.Reset - time.Now().Unix()
+		if resetAfter < 1 {
+			resetAfter = 1
+		}
+		ctx.Header("Retry-After", strconv.FormatInt(resetAfter, 10))
+		httpx.RespondError(ctx, http.StatusTooManyRequests, "API_KEY_QUOTA_EXCEEDED", "API Key 今日请求配额已用尽", gin.H{"daily_quota": quota})
+		ctx.Abort()
+		return false
+	}
+	RateLimitDecisions.Inc(class, "allowed")
+	return true
+}
+
+// applyRateLimit 计数并写入限流响应头，超限时以 429 终止请求并返回 false；探测请求（见 MarkProbes）直接放行。
+func applyRateLimit(ctx *gin.Context, l *limiter.Limiter, key, keyClass, policy string) bool {
+	if SkipRateLimit(ctx) {
+		RateLimitDecisions.Inc(keyClass, "bypassed")
+		return true
+	}
+	context, err := l.Get(ctx, key)
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusInternalServerError, "RATE_LIMIT_ERROR", err.Error(), nil)
+		ctx.Abort()
+		return false
+	}
+
+	resetAfter := context.Reset - time.Now().Unix()
+	if resetAfter < 0 {
+		resetAfter = 0
+	}
+	header := ctx.Writer.Header()
+	header.Set("X-RateLimit-Limit", strconv.FormatInt(context.Limit, 10))
+	header.Set("X-RateLimit-Remaining", strconv.FormatInt(context.Remaining, 10))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(context.Reset, 10))
+	header.Set("RateLimit-Limit", strconv.FormatInt(context.Limit, 10))
+	header.Set("RateLimit-Remaining", strconv.FormatInt(context.Remaining, 10))
+	header.Set("RateLimit-Reset", strconv.FormatInt(resetAfter, 10))
+	header.Set("RateLimit-Policy", policy)
+
+	if context.Reached {
+		RateLimitDecisions.Inc(keyClass, "blocked")
+		if resetAfter < 1 {
+			resetAfter = 1
+		}
+		header.Set("Retry-After", strconv.FormatInt(resetAfter, 10))
+		httpx.RespondError(ctx, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "请求过于频繁，请稍后再试", nil)
+		ctx.Abort()
+		return false
+	}
+
+	RateLimitDecisions.Inc(keyClass, "allowed")
+	return true
+}
+
 // KeyByClientIP 使用客户端 IP 作为限流 key。
 func KeyByClientIP() KeyFunc {
 	return func(ctx *gin.Context) string {
@@ -61,3 +186,16 @@ func KeyByUserOrIP() KeyFunc {
 		return ctx.ClientIP()
 	}
 }
+
+// KeyByAPIKey 优先使用 API Key ID，其次用户 ID，最后回退到 IP。
+func KeyByAPIKey() KeyFunc {
+	return func(ctx *gin.Context) string {
+		if keyID := ctx.GetString(APIKeyContextKey); keyID != "" {
+			return "api_key:" + keyID
+		}
+		if userID := ctx.GetString(UserContextKey); userID != "" {
+			return userID
+		}
+		return ctx.ClientIP()
+	}
+}
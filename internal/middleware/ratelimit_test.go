@@ -1,14 +1,19 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ulule/limiter/v3"
 	memorystore "github.com/ulule/limiter/v3/drivers/store/memory"
+	"github.com/zacharykka/prompt-manager/pkg/metrics"
 )
 
 func TestRateLimit_AllowsWithinLimit(t *testing.T) {
@@ -113,3 +118,197 @@ func TestRateLimit_CustomKeyFallback(t *testing.T) {
 		t.Fatalf("expected success, got %d", rec.Code)
 	}
 }
+
+func TestRateLimit_StandardHeadersAndMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memorystore.NewStore()
+	l := limiter.New(store, limiter.Rate{Period: time.Minute, Limit: 1})
+
+	router := gin.New()
+	router.Use(RateLimit(l, KeyByClientIP(), WithKeyClass("headers-test")))
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec1 := httptest.NewRecorder()
+	router.ServeHTTP(rec1, req)
+	if rec1.Code != http.StatusOK {
+		t.Fatalf("first request should pass, got %d", rec1.Code)
+	}
+	if rec1.Header().Get("RateLimit-Limit") != "1" || rec1.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("unexpected RateLimit headers: %v", rec1.Header())
+	}
+	if rec1.Header().Get("RateLimit-Policy") != "1;w=60" {
+		t.Fatalf("unexpected RateLimit-Policy %q", rec1.Header().Get("RateLimit-Policy"))
+	}
+	if reset, err := strconv.Atoi(rec1.Header().Get("RateLimit-Reset")); err != nil || reset < 0 || reset > 60 {
+		t.Fatalf("RateLimit-Reset should be delta seconds, got %q", rec1.Header().Get("RateLimit-Reset"))
+	}
+	if rec1.Header().Get("Retry-After") != "" {
+		t.Fatalf("Retry-After should only be set on 429")
+	}
+
+	rec2 := httptest.NewRecorder()
+	router.ServeHTTP(rec2, req)
+	if rec2.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 got %d", rec2.Code)
+	}
+	if retry, err := strconv.Atoi(rec2.Header().Get("Retry-After")); err != nil || retry < 1 || retry > 60 {
+		t.Fatalf("unexpected Retry-After %q", rec2.Header().Get("Retry-After"))
+	}
+
+	if allowed := RateLimitDecisions.Value("headers-test", "allowed"); allowed != 1 {
+		t.Fatalf("expected 1 allowed, got %d", allowed)
+	}
+	if blocked := RateLimitDecisions.Value("headers-test", "blocked"); blocked != 1 {
+		t.Fatalf("expected 1 blocked, got %d", blocked)
+	}
+
+	var out strings.Builder
+	if err := metrics.Default.WriteText(&out); err != nil {
+		t.Fatalf("write metrics: %v", err)
+	}
+	if !strings.Contains(out.String(), `prompt_manager_rate_limit_requests_total{key_class="headers-test",outcome="blocked"} 1`) {
+		t.Fatalf("metrics output missing blocked counter:\n%s", out.String())
+	}
+}
+
+func TestRateLimitByAPIKey_PerKeyLimitAndScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memorystore.NewStore()
+
+	principals := map[string]*APIKeyPrincipal{
+		"key-a": {KeyID: "a", UserID: "u1", Scopes: []string{"read"}, RateLimit: 1},
+		"key-b": {KeyID: "b", UserID: "u1", Scopes: []string{"render"}},
+	}
+	authenticate := func(_ context.Context, key string) (*APIKeyPrincipal, error) {
+		if p, ok := principals[key]; ok {
+			return p, nil
+		}
+		return nil, errors.New("invalid")
+	}
+	bearer := func(ctx *gin.Context) { ctx.Next() }
+
+	router := gin.New()
+	router.Use(AuthGuardWithAPIKeys(bearer, authenticate), RateLimitByAPIKey(store, limiter.Rate{Period: time.Minute, Limit: 5}))
+	router.GET("/read", RequireScopes("read"), func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+
+	do := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/read", nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("key-a"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Policy") != "1;w=60" {
+		t.Fatalf("expected first request to pass with per-key policy, got %d %q", rec.Code, rec.Header().Get("RateLimit-Policy"))
+	}
+	if rec := do("key-a"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected key-a to be throttled, got %d", rec.Code)
+	}
+	if rec := do("key-b"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected key-b without read scope to be forbidden, got %d", rec.Code)
+	}
+	if rec := do("unknown"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unknown key to be rejected, got %d", rec.Code)
+	}
+	if rec := do(""); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Policy") != "" {
+		t.Fatalf("expected bearer request to bypass api key limits, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitByAPIKey_DailyQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memorystore.NewStore()
+
+	principals := map[string]*APIKeyPrincipal{
+		"key-q": {KeyID: "q", UserID: "u1", Scopes: []string{"write"}, DailyQuota: 2},
+		"key-u": {KeyID: "u", UserID: "u1", Scopes: []string{"write"}},
+	}
+	authenticate := func(_ context.Context, key string) (*APIKeyPrincipal, error) {
+		if p, ok := principals[key]; ok {
+			return p, nil
+		}
+		return nil, errors.New("invalid")
+	}
+	bearer := func(ctx *gin.Context) { ctx.Next() }
+
+	router := gin.New()
+	router.Use(AuthGuardWithAPIKeys(bearer, authenticate), RateLimitByAPIKey(store, limiter.Rate{Period: time.Minute, Limit: 100}))
+	router.POST("/write", RequireScopes("write"), func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+
+	do := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/write", nil)
+		req.Header.Set(APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := do("key-q"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d within quota should pass, got %d", i+1, rec.Code)
+		}
+	}
+	rec := do("key-q")
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "API_KEY_QUOTA_EXCEEDED") {
+		t.Fatalf("expected quota exhaustion, got %d %s", rec.Code, rec.Body.String())
+	}
+	if retry, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || retry < 1 {
+		t.Fatalf("expected Retry-After on quota exhaustion, got %q", rec.Header().Get("Retry-After"))
+	}
+	for i := 0; i < 3; i++ {
+		if rec := do("key-u"); rec.Code != http.StatusOK {
+			t.Fatalf("key without quota should not be limited, got %d", rec.Code)
+		}
+	}
+}
+
+func TestRateLimitByAPIKey_RateLimitedRequestsDoNotConsumeQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memorystore.NewStore()
+
+	principal := &APIKeyPrincipal{KeyID: "q", UserID: "u1", Scopes: []string{"write"}, RateLimit: 1, DailyQuota: 5}
+	authenticate := func(_ context.Context, key string) (*APIKeyPrincipal, error) {
+		if key == "key-q" {
+			return principal, nil
+		}
+		return nil, errors.New("invalid")
+	}
+	bearer := func(ctx *gin.Context) { ctx.Next() }
+
+	router := gin.New()
+	router.Use(AuthGuardWithAPIKeys(bearer, authenticate), RateLimitByAPIKey(store, limiter.Rate{Period: time.Minute, Limit: 100}))
+	router.POST("/write", RequireScopes("write"), func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/write", nil)
+		req.Header.Set(APIKeyHeader, "key-q")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(); rec.Code != http.StatusOK {
+		t.Fatalf("first request should pass, got %d", rec.Code)
+	}
+	for i := 0; i < 3; i++ {
+		rec := do()
+		if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "RATE_LIMIT_EXCEEDED") {
+			t.Fatalf("expected per-minute limit, got %d %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	quota := limiter.New(store, limiter.Rate{Period: 24 * time.Hour, Limit: 5})
+	usage, err := quota.Peek(context.Background(), "api_key_quota:q")
+	if err != nil {
+		t.Fatalf("peek quota: %v", err)
+	}
+	if usage.Remaining != 4 {
+		t.Fatalf("rate limited requests must not consume quota, remaining %d", usage.Remaining)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// ReadOnly 拒绝 GET、HEAD、OPTIONS 以外的请求（503 READ_ONLY），用于数据库结构版本高于当前程序时只提供读取。
+// allowPaths 为仍允许写入的路由（FullPath 精确匹配），如登录与刷新令牌，使只读实例仍可签发会话。
+func ReadOnly(allowPaths ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(allowPaths))
+	for _, path := range allowPaths {
+		allowed[path] = struct{}{}
+	}
+
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+		if _, ok := allowed[ctx.FullPath()]; ok {
+			ctx.Next()
+			return
+		}
+		httpx.RespondError(ctx, http.StatusServiceUnavailable, "READ_ONLY", "instance is read-only until it is upgraded to the database schema version", nil)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(ReadOnly("/api/v1/auth/login"))
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	engine.GET("/api/v1/prompts", ok)
+	engine.POST("/api/v1/prompts", ok)
+	engine.DELETE("/api/v1/prompts/:id", ok)
+	engine.POST("/api/v1/auth/login", ok)
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v1/prompts", http.StatusOK},
+		{http.MethodPost, "/api/v1/prompts", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/v1/prompts/p1", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/auth/login", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, w.Code)
+		}
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"go.uber.org/zap"
 )
 
-// RequestLogger 负责记录每一次 HTTP 请求，便于排查与审计。
+// RequestLogger 负责记录每一次 HTTP 请求，便于排查与审计；健康检查等探测路径（见 MarkProbes）不记录。
 func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		start := time.Now()
 		ctx.Next()
+		if SkipRequestLog(ctx) {
+			return
+		}
 
 		duration := time.Since(start)
 
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseCacheHeader 标记响应是否来自缓存（HIT/MISS）。
+const ResponseCacheHeader = "X-Cache"
+
+// ResponseCacheStore 存取缓存的响应体，并维护各失效作用域的代数。
+type ResponseCacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, body []byte, ttl time.Duration) error
+	Generations(ctx context.Context, scopes ...string) ([]int64, error)
+}
+
+// ResponseCacheKey 返回请求的缓存键与失效作用域，ok 为 false 时该请求不走缓存。
+type ResponseCacheKey func(ctx *gin.Context) (key string, scopes []string, ok bool)
+
+// CacheResponses 在 ttl 内缓存 200 的 JSON 响应，缓存键附带各作用域的当前代数，作用域失效后自然错过旧条目。
+// 需挂在认证与工作区解析之后；缓存读写失败时直接回源，不影响请求。
+func CacheResponses(store ResponseCacheStore, ttl time.Duration, keyFn ResponseCacheKey) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key, scopes, ok := keyFn(ctx)
+		if !ok || ctx.Request.Method != http.MethodGet {
+			ctx.Next()
+			return
+		}
+		reqCtx := ctx.Request.Context()
+		generations, err := store.Generations(reqCtx, scopes...)
+		if err != nil {
+			ctx.Next()
+			return
+		}
+		parts := make([]string, 0, len(generations)+1)
+		parts = append(parts, key)
+		for _, generation := range generations {
+			parts = append(parts, strconv.FormatInt(generation, 10))
+		}
+		key = strings.Join(parts, "|")
+
+		if body, hit, err := store.Get(reqCtx, key); err == nil && hit {
+			ctx.Header(ResponseCacheHeader, "HIT")
+			ctx.Data(http.StatusOK, "application/json; charset=utf-8", body)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Header(ResponseCacheHeader, "MISS")
+		recorder := &bodyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+		if ctx.Writer.Status() == http.StatusOK && len(ctx.Errors) == 0 {
+			_ = store.Set(reqCtx, key, recorder.body.Bytes(), ttl)
+		}
+	}
+}
+
+// bodyRecorder 在写出响应的同时保留一份响应体。
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryResponseCache struct {
+	entries     map[string][]byte
+	generations map[string]int64
+}
+
+func (c *memoryResponseCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	body, ok := c.entries[key]
+	return body, ok, nil
+}
+
+func (c *memoryResponseCache) Set(_ context.Context, key string, body []byte, _ time.Duration) error {
+	c.entries[key] = append([]byte(nil), body...)
+	return nil
+}
+
+func (c *memoryResponseCache) Generations(_ context.Context, scopes ...string) ([]int64, error) {
+	generations := make([]int64, len(scopes))
+	for i, scope := range scopes {
+		generations[i] = c.generations[scope]
+	}
+	return generations, nil
+}
+
+func TestCacheResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &memoryResponseCache{entries: map[string][]byte{}, generations: map[string]int64{}}
+	calls := 0
+	router := gin.New()
+	router.GET("/items", CacheResponses(store, time.Minute, func(ctx *gin.Context) (string, []string, bool) {
+		return "items|" + ctx.Request.URL.Query().Encode(), []string{"workspace:a"}, true
+	}), func(ctx *gin.Context) {
+		calls++
+		if ctx.Query("fail") != "" {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"calls": calls})
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	first := get("/items?limit=10")
+	second := get("/items?limit=10")
+	if first.Header().Get(ResponseCacheHeader) != "MISS" || second.Header().Get(ResponseCacheHeader) != "HIT" {
+		t.Fatalf("expected MISS then HIT, got %q and %q", first.Header().Get(ResponseCacheHeader), second.Header().Get(ResponseCacheHeader))
+	}
+	if second.Body.String() != first.Body.String() || calls != 1 {
+		t.Fatalf("expected cached body %q, got %q after %d calls", first.Body.String(), second.Body.String(), calls)
+	}
+
+	if get("/items?limit=20").Header().Get(ResponseCacheHeader) != "MISS" {
+		t.Fatalf("expected different query to miss")
+	}
+	get("/items?fail=1")
+	if get("/items?fail=1").Header().Get(ResponseCacheHeader) != "MISS" {
+		t.Fatalf("expected error responses not to be cached")
+	}
+
+	// 作用域代数递增后旧条目不再命中。
+	store.generations["workspace:a"]++
+	refreshed := get("/items?limit=10")
+	if refreshed.Header().Get(ResponseCacheHeader) != "MISS" || refreshed.Body.String() != `{"calls":`+strconv.Itoa(calls)+`}` {
+		t.Fatalf("expected invalidated entry to be refreshed, got %q", refreshed.Body.String())
+	}
+}
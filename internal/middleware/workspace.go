@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+const (
+	// WorkspaceContextKey 在上下文中存储当前工作区 ID。
+	WorkspaceContextKey = "workspace_id"
+	// WorkspaceHeader 允许单个请求覆盖令牌中选中的工作区。
+	WorkspaceHeader = "X-Workspace-ID"
+)
+
+// WorkspaceRoleResolver 返回用户在工作区内的有效角色。
+// 工作区不存在时返回 domain.ErrWorkspaceNotFound，非成员返回 domain.ErrWorkspaceForbidden。
+type WorkspaceRoleResolver func(ctx context.Context, userID, globalRole, workspaceID string) (string, error)
+
+// WorkspaceResolver 依次从 X-Workspace-ID 请求头、令牌 workspace_id 声明中确定当前工作区，缺省为默认工作区；
+// 校验访问权限后以工作区角色覆盖用户角色，并将工作区写入请求上下文供仓储限定范围。需置于 AuthGuard 之后。
+func WorkspaceResolver(resolve WorkspaceRoleResolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		workspaceID := strings.TrimSpace(ctx.GetHeader(WorkspaceHeader))
+		if workspaceID == "" {
+			if claims, ok := ctx.Get("auth_claims"); ok {
+				if c, ok := claims.(*authutil.Claims); ok {
+					workspaceID = c.WorkspaceID
+				}
+			}
+		}
+		if workspaceID == "" {
+			workspaceID = domain.DefaultWorkspaceID
+		}
+
+		role, err := resolve(ctx.Request.Context(), ctx.GetString(UserContextKey), ctx.GetString(UserRoleContextKey), workspaceID)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrWorkspaceNotFound):
+				httpx.RespondError(ctx, http.StatusNotFound, "WORKSPACE_NOT_FOUND", "工作区不存在", nil)
+			case errors.Is(err, domain.ErrWorkspaceForbidden):
+				httpx.RespondError(ctx, http.StatusForbidden, "WORKSPACE_FORBIDDEN", "无权访问该工作区", nil)
+			default:
+				httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", "解析工作区失败", nil)
+			}
+			return
+		}
+
+		ctx.Set(WorkspaceContextKey, workspaceID)
+		ctx.Set(UserRoleContextKey, role)
+		ctx.Request = ctx.Request.WithContext(domain.WithWorkspace(ctx.Request.Context(), workspaceID))
+		ctx.Next()
+	}
+}
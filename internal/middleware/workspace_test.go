@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+)
+
+func TestWorkspaceResolver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resolve := func(_ context.Context, userID, globalRole, workspaceID string) (string, error) {
+		switch workspaceID {
+		case domain.DefaultWorkspaceID:
+			return globalRole, nil
+		case "team":
+			return RoleEditor, nil
+		case "private":
+			return "", domain.ErrWorkspaceForbidden
+		default:
+			return "", domain.ErrWorkspaceNotFound
+		}
+	}
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(UserContextKey, "user")
+		ctx.Set(UserRoleContextKey, RoleViewer)
+		ctx.Set("auth_claims", &authutil.Claims{UserID: "user", WorkspaceID: ctx.Query("claim")})
+	}, WorkspaceResolver(resolve))
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, domain.WorkspaceFromContext(ctx)+":"+ctx.GetString(UserRoleContextKey))
+	})
+
+	cases := []struct {
+		name   string
+		url    string
+		header string
+		status int
+		body   string
+	}{
+		{name: "default", url: "/", status: http.StatusOK, body: "default:viewer"},
+		{name: "claim", url: "/?claim=team", status: http.StatusOK, body: "team:editor"},
+		{name: "header overrides claim", url: "/?claim=team", header: domain.DefaultWorkspaceID, status: http.StatusOK, body: "default:viewer"},
+		{name: "forbidden", url: "/", header: "private", status: http.StatusForbidden},
+		{name: "not found", url: "/", header: "missing", status: http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		if tc.header != "" {
+			req.Header.Set(WorkspaceHeader, tc.header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Fatalf("%s: expected %d got %d", tc.name, tc.status, rec.Code)
+		}
+		if tc.body != "" && rec.Body.String() != tc.body {
+			t.Fatalf("%s: expected body %q got %q", tc.name, tc.body, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	announcementsvc "github.com/zacharykka/prompt-manager/internal/service/announcement"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// AnnouncementHandler 处理站内公告的查询与管理。
+type AnnouncementHandler struct {
+	service *announcementsvc.Service
+}
+
+// NewAnnouncementHandler 创建 AnnouncementHandler。
+func NewAnnouncementHandler(service *announcementsvc.Service) *AnnouncementHandler {
+	return &AnnouncementHandler{service: service}
+}
+
+// RegisterAdminRoutes 注册公告管理路由，调用方负责挂载管理员权限校验。
+func (h *AnnouncementHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.ListAnnouncements)
+	rg.POST("", h.CreateAnnouncement)
+	rg.PUT("/:id", h.UpdateAnnouncement)
+	rg.DELETE("/:id", h.DeleteAnnouncement)
+}
+
+type announcementRequest struct {
+	Message  string     `json:"message" binding:"required"`
+	Severity string     `json:"severity"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// ListActiveAnnouncements 返回当前展示窗口内的公告，无需登录，便于登录页同样展示维护通知。
+func (h *AnnouncementHandler) ListActiveAnnouncements(ctx *gin.Context) {
+	items, err := h.service.ListActive(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "no-cache")
+	httpx.RespondOK(ctx, gin.H{"items": items})
+}
+
+// ListAnnouncements 返回全部公告（含已过期与未开始的）。
+func (h *AnnouncementHandler) ListAnnouncements(ctx *gin.Context) {
+	items, err := h.service.List(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": items})
+}
+
+// CreateAnnouncement 发布公告。
+func (h *AnnouncementHandler) CreateAnnouncement(ctx *gin.Context) {
+	var req announcementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	announcement, err := h.service.Create(auditContext(ctx), req.input(actorFromContext(ctx)))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, announcement)
+}
+
+// UpdateAnnouncement 覆盖公告内容与展示窗口。
+func (h *AnnouncementHandler) UpdateAnnouncement(ctx *gin.Context) {
+	var req announcementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	announcement, err := h.service.Update(auditContext(ctx), ctx.Param("id"), req.input(actorFromContext(ctx)))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, announcement)
+}
+
+// DeleteAnnouncement 删除公告。
+func (h *AnnouncementHandler) DeleteAnnouncement(ctx *gin.Context) {
+	if err := h.service.Delete(auditContext(ctx), ctx.Param("id"), actorFromContext(ctx)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"id": ctx.Param("id")})
+}
+
+func (r announcementRequest) input(actor string) announcementsvc.Input {
+	return announcementsvc.Input{
+		Message:  r.Message,
+		Severity: r.Severity,
+		StartsAt: r.StartsAt,
+		EndsAt:   r.EndsAt,
+		Actor:    actor,
+	}
+}
+
+func (h *AnnouncementHandler) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, announcementsvc.ErrMessageRequired),
+		errors.Is(err, announcementsvc.ErrInvalidSeverity),
+		errors.Is(err, announcementsvc.ErrInvalidWindow):
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_ANNOUNCEMENT", err.Error(), nil)
+	case errors.Is(err, announcementsvc.ErrAnnouncementNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "ANNOUNCEMENT_NOT_FOUND", err.Error(), nil)
+	default:
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+	}
+}
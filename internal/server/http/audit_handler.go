@@ -0,0 +1,115 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	auditsvc "github.com/zacharykka/prompt-manager/internal/service/audit"
+	"github.com/zacharykka/prompt-manager/pkg/audit"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// AuditHandler 处理通用审计日志（认证、用户与管理操作）的查询、校验与导出。
+type AuditHandler struct {
+	service *auditsvc.Service
+}
+
+// NewAuditHandler 创建 AuditHandler。
+func NewAuditHandler(service *auditsvc.Service) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// RegisterRoutes 注册通用审计日志路由，调用方负责挂载管理员权限校验。
+func (h *AuditHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.ListAuditLogs)
+	rg.GET("/verify", h.VerifyAuditChain)
+	rg.GET("/export", h.ExportAuditLogs)
+}
+
+// ListAuditLogs 分页查询审计日志，支持 action（以 "." 结尾时按前缀匹配）、actor、target_type、target_id、from、to 过滤。
+func (h *AuditHandler) ListAuditLogs(ctx *gin.Context) {
+	opts, ok := parseAuditLogFilter(ctx)
+	if !ok {
+		return
+	}
+	opts.Limit, opts.Offset = parsePagination(ctx.Query("limit"), ctx.Query("offset"))
+
+	items, total, err := h.service.List(ctx, opts)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{
+		"items": items,
+		"meta": gin.H{
+			"total":   total,
+			"limit":   opts.Limit,
+			"offset":  opts.Offset,
+			"hasMore": int64(opts.Offset)+int64(len(items)) < total,
+		},
+	})
+}
+
+// VerifyAuditChain 校验通用审计日志的哈希链。
+func (h *AuditHandler) VerifyAuditChain(ctx *gin.Context) {
+	report, err := h.service.VerifyChain(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, report)
+}
+
+// ExportAuditLogs 以 NDJSON 流式导出符合过滤条件的审计日志。
+func (h *AuditHandler) ExportAuditLogs(ctx *gin.Context) {
+	opts, ok := parseAuditLogFilter(ctx)
+	if !ok {
+		return
+	}
+
+	stream := newNDJSONStream(ctx, "admin-audit-logs-"+time.Now().UTC().Format("20060102T150405Z")+".ndjson")
+	err := h.service.Iterate(ctx, opts, func(log *domain.AuditLog) error {
+		return stream.Encode(log)
+	})
+	stream.finish(err, h.handleError)
+}
+
+func (h *AuditHandler) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auditsvc.ErrAuditLogUnavailable):
+		httpx.RespondError(ctx, http.StatusServiceUnavailable, "AUDIT_UNAVAILABLE", err.Error(), nil)
+	default:
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+	}
+}
+
+func parseAuditLogFilter(ctx *gin.Context) (domain.AuditLogListOptions, bool) {
+	opts := domain.AuditLogListOptions{
+		Action:     strings.TrimSpace(ctx.Query("action")),
+		Actor:      strings.TrimSpace(ctx.Query("actor")),
+		TargetType: strings.TrimSpace(ctx.Query("target_type")),
+		TargetID:   strings.TrimSpace(ctx.Query("target_id")),
+	}
+	var ok bool
+	if opts.From, ok = parseQueryTime(ctx, "from"); !ok {
+		return opts, false
+	}
+	if opts.To, ok = parseQueryTime(ctx, "to"); !ok {
+		return opts, false
+	}
+	return opts, true
+}
+
+// auditContext 返回携带客户端 IP 与 User-Agent 的请求上下文，供业务层写入审计记录。
+func auditContext(ctx *gin.Context) context.Context {
+	return audit.WithClient(ctx.Request.Context(), audit.Client{
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	})
+}
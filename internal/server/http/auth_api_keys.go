@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type createAPIKeyRequest struct {
+	Name       string   `json:"name" binding:"required,max=128"`
+	Scopes     []string `json:"scopes" binding:"required,min=1"`
+	RateLimit  int      `json:"rate_limit"`
+	DailyQuota int      `json:"daily_quota"`
+}
+
+// ListMyAPIKeys 返回当前用户的 API Key（不含明文）。
+func (h *AuthHandler) ListMyAPIKeys(ctx *gin.Context) {
+	keys, err := h.service.ListAPIKeys(ctx, ctx.GetString(middleware.UserContextKey))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": keys})
+}
+
+// CreateMyAPIKey 为当前用户签发 API Key，明文 secret 仅在本次响应中返回。
+func (h *AuthHandler) CreateMyAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	created, err := h.service.CreateAPIKey(ctx, authsvc.CreateAPIKeyInput{
+		UserID:     ctx.GetString(middleware.UserContextKey),
+		Name:       req.Name,
+		Scopes:     req.Scopes,
+		RateLimit:  req.RateLimit,
+		DailyQuota: req.DailyQuota,
+		Actor:      actorFromContext(ctx),
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, created)
+}
+
+// RevokeMyAPIKey 吊销当前用户的 API Key。
+func (h *AuthHandler) RevokeMyAPIKey(ctx *gin.Context) {
+	if err := h.service.RevokeAPIKey(ctx, ctx.GetString(middleware.UserContextKey), ctx.Param("keyId"), actorFromContext(ctx)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"api_key_id": ctx.Param("keyId")})
+}
+
+// APIKeyAuthenticator 把认证服务适配为中间件使用的 API Key 校验函数。
+func APIKeyAuthenticator(service AuthService) middleware.APIKeyAuthenticator {
+	return func(ctx context.Context, secret string) (*middleware.APIKeyPrincipal, error) {
+		key, user, err := service.AuthenticateAPIKey(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+		return &middleware.APIKeyPrincipal{
+			KeyID:      key.ID,
+			UserID:     user.ID,
+			Email:      user.Email,
+			Role:       user.Role,
+			Scopes:     key.Scopes,
+			RateLimit:  key.RateLimit,
+			DailyQuota: key.DailyQuota,
+		}, nil
+	}
+}
@@ -1,12 +1,7 @@
 package http
 
 import (
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
 	"net/http"
-	"net/url"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
@@ -15,18 +10,20 @@ import (
 
 // AuthHandler 处理认证相关请求。
 type AuthHandler struct {
-	service *authsvc.Service
+	service AuthService
 }
 
 // NewAuthHandler 构造认证处理器。
-func NewAuthHandler(service *authsvc.Service) *AuthHandler {
+func NewAuthHandler(service AuthService) *AuthHandler {
 	return &AuthHandler{service: service}
 }
 
 // RegisterRoutes 注册认证相关路由。
 func (h *AuthHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("/register", h.Register)
 	rg.POST("/login", h.Login)
 	rg.POST("/refresh", h.Refresh)
+	rg.GET("/password-policy", h.PasswordPolicy)
 	rg.GET("/github/login", h.GitHubLogin)
 	rg.GET("/github/callback", h.GitHubCallback)
 }
@@ -50,7 +47,7 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	tokens, user, err := h.service.Login(ctx, req.Email, req.Password)
+	tokens, user, err := h.service.Login(auditContext(ctx), req.Email, req.Password)
 	if err != nil {
 		h.handleError(ctx, err)
 		return
@@ -70,7 +67,7 @@ func (h *AuthHandler) Refresh(ctx *gin.Context) {
 		return
 	}
 
-	tokens, user, err := h.service.Refresh(ctx, req.RefreshToken)
+	tokens, user, err := h.service.Refresh(auditContext(ctx), req.RefreshToken)
 	if err != nil {
 		h.handleError(ctx, err)
 		return
@@ -96,12 +93,17 @@ func (h *AuthHandler) GitHubLogin(ctx *gin.Context) {
 	ctx.Redirect(http.StatusFound, authorizeURL)
 }
 
-// GitHubCallback 处理 GitHub OAuth 回调并返回本地令牌。
+// GitHubCallback 处理 GitHub OAuth 回调并返回本地令牌；绑定模式只返回绑定结果，不签发令牌。
 func (h *AuthHandler) GitHubCallback(ctx *gin.Context) {
+	binding, _ := ctx.Cookie(identityLinkCookie)
+	if binding != "" {
+		setIdentityLinkCookie(ctx, "", -1)
+	}
 	tokens, user, redirectURI, responseMode, clientOrigin, err := h.service.HandleGitHubCallback(
-		ctx.Request.Context(),
+		auditContext(ctx),
 		ctx.Query("code"),
 		ctx.Query("state"),
+		binding,
 	)
 	if err != nil {
 		h.handleError(ctx, err)
@@ -112,6 +114,13 @@ func (h *AuthHandler) GitHubCallback(ctx *gin.Context) {
 		"tokens": tokens,
 		"user":   user,
 	}
+	if tokens == nil {
+		payload = gin.H{
+			"linked":   true,
+			"provider": "github",
+			"user":     user,
+		}
+	}
 	if redirectURI != "" {
 		payload["redirect_uri"] = redirectURI
 	}
@@ -124,95 +133,10 @@ func (h *AuthHandler) GitHubCallback(ctx *gin.Context) {
 	httpx.RespondOK(ctx, payload)
 }
 
-func (h *AuthHandler) respondWebMessage(ctx *gin.Context, payload gin.H, redirectURI, clientOrigin string) {
-	jsonBytes, err := json.Marshal(payload)
-	if err != nil {
-		h.handleError(ctx, fmt.Errorf("marshal web message payload: %w", err))
+func (h *AuthHandler) handleError(ctx *gin.Context, err error) {
+	if handlePasswordPolicyError(ctx, err) {
 		return
 	}
-
-	encoded := base64.StdEncoding.EncodeToString(jsonBytes)
-
-	// Use clientOrigin as the target origin for postMessage
-	targetOrigin := "*"
-	if strings.TrimSpace(clientOrigin) != "" {
-		targetOrigin = clientOrigin
-	} else if redirectURI != "" {
-		if parsed, err := url.Parse(redirectURI); err == nil && parsed.Scheme != "" && parsed.Host != "" {
-			targetOrigin = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
-		}
-	}
-
-	fallbackRedirect := strings.TrimSpace(clientOrigin)
-
-	html := fmt.Sprintf(`<!DOCTYPE html>
-<html lang="zh-CN">
-<head>
-  <meta charset="utf-8" />
-  <title>GitHub 登录完成</title>
-</head>
-<body>
-  <script>
-    (function () {
-      const encodedPayload = '%s';
-      console.log('OAuth callback received, payload length:', encodedPayload.length);
-
-      try {
-        const data = JSON.parse(atob(encodedPayload));
-        console.log('OAuth payload decoded:', { user: data.user?.email, hasTokens: !!data.tokens });
-
-        console.log('Window opener status:', {
-          hasOpener: !!window.opener,
-          openerClosed: window.opener ? window.opener.closed : 'no opener',
-          windowName: window.name
-        });
-
-        if (window.opener && !window.opener.closed) {
-          try {
-            console.log('Attempting postMessage to opener:', '%s');
-            window.opener.postMessage({ source: 'prompt-manager', payload: data }, '%s');
-            document.body.innerText = '登录成功，正在返回主窗口...';
-            console.log('Posted message to opener window successfully');
-            setTimeout(function () {
-              window.close();
-            }, 1000);
-            return;
-          } catch (error) {
-            console.error('postMessage failed:', error);
-            console.log('postMessage failed, trying localStorage fallback');
-          }
-        } else {
-          console.log('No valid opener window found (opener:', !!window.opener, ', closed:', window.opener ? window.opener.closed : 'no opener', '), using localStorage fallback');
-        }
-
-        // Force hash redirect for cross-origin scenarios
-        console.log('Using hash redirect fallback');
-
-        var clientOrigin = '%s';
-        if (clientOrigin && clientOrigin !== '') {
-          console.log('Redirecting to client with hash, clientOrigin:', clientOrigin);
-          var fallbackTarget = clientOrigin + '/auth/login';
-          var redirectURL = fallbackTarget + '#pm_oauth=' + encodeURIComponent(encodedPayload);
-          console.log('Final redirect URL:', redirectURL);
-          document.body.innerText = '正在返回主窗口...';
-          window.location.replace(redirectURL);
-        } else {
-          console.error('No client origin available for redirect');
-          document.body.innerText = '登录完成，请手动返回应用。';
-        }
-      } catch (error) {
-        console.error('Failed to process OAuth callback:', error);
-        document.body.innerText = '登录处理失败，请返回应用重试。';
-      }
-    })();
-  </script>
-</body>
-</html>`, encoded, targetOrigin, targetOrigin, fallbackRedirect)
-
-	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
-}
-
-func (h *AuthHandler) handleError(ctx *gin.Context, err error) {
 	switch err {
 	case authsvc.ErrInvalidInput:
 		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
@@ -220,18 +144,46 @@ func (h *AuthHandler) handleError(ctx *gin.Context, err error) {
 		httpx.RespondError(ctx, http.StatusUnauthorized, "INVALID_CREDENTIALS", "邮箱或密码错误", nil)
 	case authsvc.ErrUserDisabled:
 		httpx.RespondError(ctx, http.StatusForbidden, "USER_DISABLED", err.Error(), nil)
+	case authsvc.ErrUserPending:
+		httpx.RespondError(ctx, http.StatusForbidden, "USER_PENDING", err.Error(), nil)
+	case authsvc.ErrInvalidRole:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_ROLE", err.Error(), nil)
+	case authsvc.ErrUserExists:
+		httpx.RespondError(ctx, http.StatusConflict, "USER_EXISTS", err.Error(), nil)
+	case authsvc.ErrRegistrationDisabled:
+		httpx.RespondError(ctx, http.StatusForbidden, "REGISTRATION_DISABLED", err.Error(), nil)
+	case authsvc.ErrEmailDomainNotAllowed:
+		httpx.RespondError(ctx, http.StatusForbidden, "EMAIL_DOMAIN_NOT_ALLOWED", err.Error(), nil)
+	case authsvc.ErrUserNotFound:
+		httpx.RespondError(ctx, http.StatusNotFound, "USER_NOT_FOUND", err.Error(), nil)
+	case authsvc.ErrUserNotPending:
+		httpx.RespondError(ctx, http.StatusConflict, "USER_NOT_PENDING", err.Error(), nil)
 	case authsvc.ErrTokenInvalid:
 		httpx.RespondError(ctx, http.StatusUnauthorized, "TOKEN_INVALID", err.Error(), nil)
 	case authsvc.ErrOAuthDisabled:
 		httpx.RespondError(ctx, http.StatusBadRequest, "OAUTH_DISABLED", err.Error(), nil)
 	case authsvc.ErrOAuthStateInvalid:
 		httpx.RespondError(ctx, http.StatusBadRequest, "OAUTH_STATE_INVALID", err.Error(), nil)
+	case authsvc.ErrOAuthRedirectNotAllowed:
+		httpx.RespondError(ctx, http.StatusBadRequest, "OAUTH_REDIRECT_NOT_ALLOWED", err.Error(), nil)
 	case authsvc.ErrOAuthExchangeFailed:
 		httpx.RespondError(ctx, http.StatusBadGateway, "OAUTH_EXCHANGE_FAILED", err.Error(), nil)
 	case authsvc.ErrOAuthEmailMissing:
 		httpx.RespondError(ctx, http.StatusBadRequest, "OAUTH_EMAIL_MISSING", err.Error(), nil)
 	case authsvc.ErrOAuthOrgUnauthorized:
 		httpx.RespondError(ctx, http.StatusForbidden, "OAUTH_ORG_FORBIDDEN", err.Error(), nil)
+	case authsvc.ErrOAuthTeamUnauthorized:
+		httpx.RespondError(ctx, http.StatusForbidden, "OAUTH_TEAM_FORBIDDEN", err.Error(), nil)
+	case authsvc.ErrProviderUnsupported:
+		httpx.RespondError(ctx, http.StatusBadRequest, "PROVIDER_UNSUPPORTED", err.Error(), nil)
+	case authsvc.ErrIdentityConflict:
+		httpx.RespondError(ctx, http.StatusConflict, "IDENTITY_CONFLICT", err.Error(), nil)
+	case authsvc.ErrInvalidScope:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_SCOPE", err.Error(), nil)
+	case authsvc.ErrAPIKeyNotFound:
+		httpx.RespondError(ctx, http.StatusNotFound, "API_KEY_NOT_FOUND", err.Error(), nil)
+	case authsvc.ErrAPIKeyInvalid:
+		httpx.RespondError(ctx, http.StatusUnauthorized, "UNAUTHORIZED", err.Error(), nil)
 	default:
 		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
 	}
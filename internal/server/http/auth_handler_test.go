@@ -13,9 +13,13 @@ import (
 
     "github.com/gin-gonic/gin"
     "github.com/zacharykka/prompt-manager/internal/config"
+    domain "github.com/zacharykka/prompt-manager/internal/domain"
     "github.com/zacharykka/prompt-manager/internal/infra/database"
     "github.com/zacharykka/prompt-manager/internal/infra/repository"
+    "github.com/zacharykka/prompt-manager/internal/middleware"
+    "github.com/zacharykka/prompt-manager/internal/server/http/mocks"
     "github.com/zacharykka/prompt-manager/internal/service/auth"
+    "go.uber.org/mock/gomock"
     _ "modernc.org/sqlite"
 )
 
@@ -45,6 +49,14 @@ func setupAuthHandler(t *testing.T) (*AuthHandler, func()) {
         t.Fatalf("apply migration: %v", err)
     }
 
+	auditSchema, err := os.ReadFile(filepath.Join("..", "..", "..", "db", "migrations", "000011_audit_logs.up.sql"))
+	if err != nil {
+		t.Fatalf("read audit migration: %v", err)
+	}
+	if _, err := db.Exec(string(auditSchema)); err != nil {
+		t.Fatalf("apply audit migration: %v", err)
+	}
+
     repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
     svc := auth.NewService(repos, config.AuthConfig{
         AccessTokenSecret:  "abcdefghijklmnopqrstuvwxyz123456",
@@ -178,3 +190,71 @@ func TestAuthHandler_RefreshInvalidToken(t *testing.T) {
 		t.Fatalf("expected 401 got %d", rec.Code)
 	}
 }
+
+func TestAuthHandlerLoginWithMockService(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    service := mocks.NewMockAuthService(ctrl)
+    handler := NewAuthHandler(service)
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    handler.RegisterRoutes(router.Group("/auth"))
+
+    service.EXPECT().Login(gomock.Any(), "user@example.com", "wrong-password").Return(nil, nil, auth.ErrInvalidCredentials)
+
+    body, _ := json.Marshal(map[string]string{"email": "user@example.com", "password": "wrong-password"})
+    req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, req)
+
+    if rec.Code != http.StatusUnauthorized {
+        t.Fatalf("expected 401 got %d, body=%s", rec.Code, rec.Body.String())
+    }
+}
+
+func TestAuthHandlerIdentityLinkBindsBrowser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockAuthService(ctrl)
+	handler := NewAuthHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/auth"))
+	router.POST("/me/identities/link/:provider", func(ctx *gin.Context) {
+		ctx.Set(middleware.UserContextKey, "user-1")
+	}, handler.LinkIdentity)
+
+	service.EXPECT().IdentityLinkURL(gomock.Any(), "user-1", "github", "", "", "").Return("https://github.example/authorize", "binding-1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/me/identities/link/github", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d, body=%s", rec.Code, rec.Body.String())
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != identityLinkCookie || cookies[0].Value != "binding-1" || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("expected HttpOnly SameSite link cookie, got %+v", cookies)
+	}
+
+	// 回调把 Cookie 中的绑定值交给服务校验，绑定模式只返回绑定结果并清除 Cookie。
+	service.EXPECT().HandleGitHubCallback(gomock.Any(), "code", "state", "binding-1").Return(nil, &domain.User{ID: "user-1"}, "", "", "", nil)
+	req := httptest.NewRequest(http.MethodGet, "/auth/github/callback?code=code&state=state", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode callback: %v", err)
+	}
+	if _, ok := resp.Data["tokens"]; ok || string(resp.Data["linked"]) != "true" {
+		t.Fatalf("expected link result without tokens, got %s", rec.Body.String())
+	}
+	if cleared := rec.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Fatalf("expected link cookie to be cleared, got %+v", cleared)
+	}
+}
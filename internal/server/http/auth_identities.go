@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// identityLinkCookie 保存发起身份绑定的浏览器所持有的绑定值，回调时与 state 比对。
+// 使用 SameSite=Lax：从 GitHub 跳回回调地址属于顶层 GET 导航，Lax 下仍会携带。
+const identityLinkCookie = "pm_identity_link"
+
+type linkIdentityRequest struct {
+	RedirectURI  string `json:"redirect_uri"`
+	ResponseMode string `json:"response_mode"`
+	ClientOrigin string `json:"client_origin"`
+}
+
+// ListMyIdentities 返回当前用户已绑定的外部身份。
+func (h *AuthHandler) ListMyIdentities(ctx *gin.Context) {
+	identities, err := h.service.ListIdentities(ctx, ctx.GetString(middleware.UserContextKey))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": identities})
+}
+
+// LinkIdentity 返回绑定模式的授权地址，前端跳转后回调会把外部身份挂到当前账号。
+func (h *AuthHandler) LinkIdentity(ctx *gin.Context) {
+	var req linkIdentityRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+
+	authorizeURL, binding, err := h.service.IdentityLinkURL(
+		ctx,
+		ctx.GetString(middleware.UserContextKey),
+		ctx.Param("provider"),
+		req.RedirectURI,
+		req.ResponseMode,
+		req.ClientOrigin,
+	)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	setIdentityLinkCookie(ctx, binding, 0)
+	httpx.RespondOK(ctx, gin.H{"authorize_url": authorizeURL})
+}
+
+// setIdentityLinkCookie 写入（maxAge 为 0，随浏览器会话失效）或清除（maxAge 为 -1）绑定 Cookie。
+func setIdentityLinkCookie(ctx *gin.Context, value string, maxAge int) {
+	secure := ctx.Request.TLS != nil || strings.EqualFold(ctx.GetHeader("X-Forwarded-Proto"), "https")
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     identityLinkCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
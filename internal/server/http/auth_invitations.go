@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type createInvitationRequest struct {
+	Email       string `json:"email" binding:"required,email,max=255"`
+	Role        string `json:"role" binding:"required"`
+	WorkspaceID string `json:"workspace_id"`
+}
+
+type acceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,max=1024"`
+}
+
+// CreateInvitation 生成成员邀请（仅管理员），明文令牌仅在响应中返回一次。
+func (h *AuthHandler) CreateInvitation(ctx *gin.Context) {
+	var req createInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	invitation, token, err := h.service.CreateInvitation(auditContext(ctx), authsvc.CreateInvitationInput{
+		Email:       req.Email,
+		Role:        req.Role,
+		WorkspaceID: req.WorkspaceID,
+		InvitedBy:   actorFromContext(ctx),
+	})
+	if err != nil {
+		h.handleInvitationError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{
+		"invitation": invitation,
+		"token":      token,
+	})
+}
+
+// ListInvitations 列出尚未接受且未过期的邀请（仅管理员）。
+func (h *AuthHandler) ListInvitations(ctx *gin.Context) {
+	items, err := h.service.ListPendingInvitations(ctx)
+	if err != nil {
+		h.handleInvitationError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": items})
+}
+
+// AcceptInvitation 接受邀请：创建新账号或关联已有账号，并返回登录令牌。
+func (h *AuthHandler) AcceptInvitation(ctx *gin.Context) {
+	var req acceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	tokens, user, err := h.service.AcceptInvitation(auditContext(ctx), req.Token, req.Password)
+	if err != nil {
+		h.handleInvitationError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{
+		"tokens": tokens,
+		"user":   user,
+	})
+}
+
+func (h *AuthHandler) handleInvitationError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrWorkspaceNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "WORKSPACE_NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, authsvc.ErrInvitationInvalid):
+		httpx.RespondError(ctx, http.StatusNotFound, "INVITATION_INVALID", err.Error(), nil)
+	case errors.Is(err, authsvc.ErrInvitationExpired):
+		httpx.RespondError(ctx, http.StatusGone, "INVITATION_EXPIRED", err.Error(), nil)
+	default:
+		h.handleError(ctx, err)
+	}
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type rotateSigningKeyRequest struct {
+	OrganizationID string `json:"organization_id"`
+	Algorithm      string `json:"algorithm"`
+}
+
+// ListSigningKeys 列出当前与仍可验签的签名密钥（仅管理员）。
+func (h *AuthHandler) ListSigningKeys(ctx *gin.Context) {
+	keys, err := h.service.ListSigningKeys(ctx)
+	if err != nil {
+		h.handleKeyError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": keys})
+}
+
+// RotateSigningKey 为组织（缺省为默认组织）生成新的签名密钥，旧密钥在宽限期内继续用于验签（仅管理员）。
+func (h *AuthHandler) RotateSigningKey(ctx *gin.Context) {
+	var req rotateSigningKeyRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+
+	actor := ctx.GetString(middleware.UserEmailContextKey)
+	if actor == "" {
+		actor = ctx.GetString(middleware.UserContextKey)
+	}
+	key, err := h.service.RotateSigningKey(auditContext(ctx), req.OrganizationID, req.Algorithm, actor)
+	if err != nil {
+		h.handleKeyError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"key": key})
+}
+
+func (h *AuthHandler) handleKeyError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, authsvc.ErrInvalidAlgorithm):
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_ALGORITHM", err.Error(), nil)
+	case errors.Is(err, authsvc.ErrSigningKeysUnavailable):
+		httpx.RespondError(ctx, http.StatusServiceUnavailable, "SIGNING_KEYS_UNAVAILABLE", err.Error(), nil)
+	case errors.Is(err, authsvc.ErrOrganizationNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "ORGANIZATION_NOT_FOUND", err.Error(), nil)
+	default:
+		h.handleError(ctx, err)
+	}
+}
+
+// JWKS 暴露访问令牌的验签公钥，供其他服务本地校验令牌（仅包含 RS256/EdDSA 密钥）。
+func (h *AuthHandler) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.service.SigningKeys().JWKS())
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// ListMyLogins 返回当前用户最近的登录与令牌刷新记录，limit 默认 20、最大 100。
+func (h *AuthHandler) ListMyLogins(ctx *gin.Context) {
+	events, err := h.service.ListLoginEvents(ctx, ctx.GetString(middleware.UserContextKey), parseQueryInt(ctx.Query("limit"), 0))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": events})
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=1024"`
+	NewPassword     string `json:"new_password" binding:"required,max=1024"`
+}
+
+// PasswordPolicy 返回当前密码策略，供前端展示规则并做本地预校验。
+func (h *AuthHandler) PasswordPolicy(ctx *gin.Context) {
+	httpx.RespondOK(ctx, h.service.PasswordPolicy())
+}
+
+// ChangePassword 校验当前密码后修改为符合策略的新密码。
+func (h *AuthHandler) ChangePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	if err := h.service.ChangePassword(auditContext(ctx), ctx.GetString(middleware.UserContextKey), req.CurrentPassword, req.NewPassword); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"changed": true})
+}
+
+// handlePasswordPolicyError 将策略校验失败映射为 400 WEAK_PASSWORD，并返回违规代码列表。
+func handlePasswordPolicyError(ctx *gin.Context, err error) bool {
+	var policyErr *authsvc.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	httpx.RespondError(ctx, http.StatusBadRequest, "WEAK_PASSWORD", "密码不符合安全策略", gin.H{"violations": policyErr.Violations})
+	return true
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type registerRequest struct {
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,max=1024"`
+	Role     string `json:"role"`
+}
+
+// Register 按注册策略创建账号；审批模式下返回的用户 status 为 pending，需管理员批准后才能登录。
+func (h *AuthHandler) Register(ctx *gin.Context) {
+	var req registerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	user, err := h.service.Register(auditContext(ctx), req.Email, req.Password, req.Role)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"user": user})
+}
+
+// ListPendingUsers 列出等待审批的注册用户（仅管理员）。
+func (h *AuthHandler) ListPendingUsers(ctx *gin.Context) {
+	users, err := h.service.ListPendingUsers(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": users})
+}
+
+// ApproveUser 批准待审批用户（仅管理员）。
+func (h *AuthHandler) ApproveUser(ctx *gin.Context) {
+	user, err := h.service.ApproveUser(auditContext(ctx), ctx.Param("id"), actorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"user": user})
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type mergeUserRequest struct {
+	TargetUserID string `json:"target_user_id" binding:"required"`
+}
+
+// MergeUser 把路径中的重复账号合并到 target_user_id 并停用原账号（仅管理员）。
+func (h *AuthHandler) MergeUser(ctx *gin.Context) {
+	var req mergeUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	result, err := h.service.MergeUsers(auditContext(ctx), ctx.Param("id"), req.TargetUserID, actorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, result)
+}
+
+type deactivateUserRequest struct {
+	TransferToUserID string `json:"transfer_to_user_id" binding:"required"`
+}
+
+// DeactivateUser 停用路径中的用户并把其创建的资源移交给 transfer_to_user_id（仅管理员）。
+func (h *AuthHandler) DeactivateUser(ctx *gin.Context) {
+	var req deactivateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	result, err := h.service.DeactivateUser(auditContext(ctx), ctx.Param("id"), req.TransferToUserID, actorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, result)
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"bytes"
+	"crypto/rand"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/oauth_web_message.html
+var webMessageTemplateSource string
+
+var webMessageTemplate = template.Must(template.New("oauth_web_message").Parse(webMessageTemplateSource))
+
+type webMessageData struct {
+	Nonce        string
+	Payload      string
+	TargetOrigin string
+	ClientOrigin string
+}
+
+// respondWebMessage 渲染通过 postMessage 回传登录结果的页面。
+// 每次响应生成独立 nonce 并写入 CSP，页面仅允许执行带该 nonce 的内联脚本。
+func (h *AuthHandler) respondWebMessage(ctx *gin.Context, payload gin.H, redirectURI, clientOrigin string) {
+	jsonBytes, err := json.Marshal(payload)
+	if err != nil {
+		h.handleError(ctx, fmt.Errorf("marshal web message payload: %w", err))
+		return
+	}
+
+	targetOrigin := "*"
+	if strings.TrimSpace(clientOrigin) != "" {
+		targetOrigin = clientOrigin
+	} else if redirectURI != "" {
+		if parsed, err := url.Parse(redirectURI); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+			targetOrigin = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+		}
+	}
+
+	nonce, err := newCSPNonce()
+	if err != nil {
+		h.handleError(ctx, fmt.Errorf("generate csp nonce: %w", err))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := webMessageTemplate.Execute(&buf, webMessageData{
+		Nonce:        nonce,
+		Payload:      base64.StdEncoding.EncodeToString(jsonBytes),
+		TargetOrigin: targetOrigin,
+		ClientOrigin: strings.TrimSpace(clientOrigin),
+	}); err != nil {
+		h.handleError(ctx, fmt.Errorf("render web message page: %w", err))
+		return
+	}
+
+	headers := ctx.Writer.Header()
+	headers.Set("Content-Security-Policy", fmt.Sprintf("default-src 'none'; script-src 'nonce-%s'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'", nonce))
+	// 页面携带令牌，禁止缓存。
+	headers.Set("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func newCSPNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
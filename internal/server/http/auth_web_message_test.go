@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondWebMessageUsesCSPNonce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/api/v1/auth/github/callback", nil)
+
+	h := &AuthHandler{}
+	h.respondWebMessage(ctx, gin.H{"tokens": gin.H{"access_token": "t"}}, "", `https://app.example.com"</script><script>alert(1)//`)
+
+	csp := w.Header().Get("Content-Security-Policy")
+	match := regexp.MustCompile(`script-src 'nonce-([^']+)'`).FindStringSubmatch(csp)
+	if match == nil {
+		t.Fatalf("expected nonce-based script-src, got %q", csp)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `<script nonce="`+match[1]+`">`) {
+		t.Fatalf("expected script tag to carry CSP nonce")
+	}
+	if strings.Contains(body, "<script>alert(1)") {
+		t.Fatalf("expected client origin to be escaped in script context")
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected token page not to be cached")
+	}
+
+	second := httptest.NewRecorder()
+	ctx2, _ := gin.CreateTestContext(second)
+	ctx2.Request = httptest.NewRequest("GET", "/api/v1/auth/github/callback", nil)
+	h.respondWebMessage(ctx2, gin.H{}, "", "")
+	if second.Header().Get("Content-Security-Policy") == csp {
+		t.Fatalf("expected a fresh nonce per response")
+	}
+}
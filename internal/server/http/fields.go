@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+var (
+	promptFieldNames  = jsonFieldNames(reflect.TypeOf(domain.Prompt{}))
+	versionFieldNames = jsonFieldNames(reflect.TypeOf(domain.PromptVersion{}))
+)
+
+// fieldSet 为 ?fields= 指定的稀疏字段集（JSON:API 风格），nil 表示返回全部字段。
+type fieldSet map[string]bool
+
+// parseFieldSet 解析逗号分隔的 ?fields=，allowed 为资源可选的 JSON 字段名；id 总是返回。
+// 包含未知字段时返回 400 并列出可选字段。
+func parseFieldSet(ctx *gin.Context, allowed map[string]bool) (fieldSet, bool) {
+	names := splitQueryList(ctx.Query("fields"))
+	if len(names) == 0 {
+		return nil, true
+	}
+	fields := fieldSet{"id": true}
+	for _, name := range names {
+		if !allowed[name] {
+			options := make([]string, 0, len(allowed))
+			for option := range allowed {
+				options = append(options, option)
+			}
+			sort.Strings(options)
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_FIELDS", fmt.Sprintf("unknown field %q", name), gin.H{"allowed": options})
+			return nil, false
+		}
+		fields[name] = true
+	}
+	return fields, true
+}
+
+// apply 仅保留 v 编码后 JSON 对象中被选中的字段；字段集为空时原样返回。
+func (f fieldSet) apply(v interface{}) (interface{}, error) {
+	if f == nil {
+		return v, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, err
+	}
+	for key := range object {
+		if !f[key] {
+			delete(object, key)
+		}
+	}
+	return object, nil
+}
+
+// applyEach 对列表中的每一项应用 apply。
+func applyEach[T any](f fieldSet, items []T) (interface{}, error) {
+	if f == nil {
+		return items, nil
+	}
+	selected := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		object, err := f.apply(item)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, object)
+	}
+	return selected, nil
+}
+
+// jsonFieldNames 返回结构体导出字段的 JSON 名称。
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if before, _, _ := strings.Cut(tag, ","); before != "" {
+				name = before
+			}
+		}
+		names[name] = true
+	}
+	return names
+}
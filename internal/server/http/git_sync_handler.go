@@ -0,0 +1,66 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gitsyncsvc "github.com/zacharykka/prompt-manager/internal/service/gitsync"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// GitSyncHandler 接收 GitHub webhook，将推送到配置仓库的 Prompt 文件同步为 Prompt 版本。
+type GitSyncHandler struct {
+	service *gitsyncsvc.Service
+}
+
+// NewGitSyncHandler 创建 GitSyncHandler。
+func NewGitSyncHandler(service *gitsyncsvc.Service) *GitSyncHandler {
+	return &GitSyncHandler{service: service}
+}
+
+// HandleGitHub 校验 X-Hub-Signature-256 后按 X-GitHub-Event 分发：ping 直接应答，push 触发同步，其余事件忽略。
+func (h *GitSyncHandler) HandleGitHub(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			httpx.RespondError(ctx, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "webhook payload exceeds size limit", gin.H{"limit": maxErr.Limit})
+			return
+		}
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	if err := h.service.VerifySignature(body, ctx.GetHeader(gitsyncsvc.SignatureHeader)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	switch event := ctx.GetHeader("X-GitHub-Event"); event {
+	case "ping":
+		httpx.RespondOK(ctx, gin.H{"pong": true})
+	case "push":
+		result, err := h.service.HandlePush(ctx.Request.Context(), body)
+		if err != nil {
+			h.handleError(ctx, err)
+			return
+		}
+		httpx.RespondOK(ctx, result)
+	default:
+		httpx.RespondOK(ctx, gin.H{"ignored": "unsupported event " + event})
+	}
+}
+
+func (h *GitSyncHandler) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gitsyncsvc.ErrInvalidSignature):
+		httpx.RespondError(ctx, http.StatusUnauthorized, "INVALID_SIGNATURE", err.Error(), nil)
+	case errors.Is(err, gitsyncsvc.ErrInvalidPayload):
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+	case errors.Is(err, gitsyncsvc.ErrFetchFailed):
+		httpx.RespondError(ctx, http.StatusBadGateway, "GIT_FETCH_FAILED", err.Error(), nil)
+	default:
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+	}
+}
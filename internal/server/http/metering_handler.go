@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	meteringsvc "github.com/zacharykka/prompt-manager/internal/service/metering"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// MeteringHandler 提供按租户汇总的用量查询、存储占用报告与手动推送接口。
+type MeteringHandler struct {
+	service *meteringsvc.Service
+}
+
+// NewMeteringHandler 创建 MeteringHandler。
+func NewMeteringHandler(service *meteringsvc.Service) *MeteringHandler {
+	return &MeteringHandler{service: service}
+}
+
+// RegisterRoutes 注册计量路由，调用方负责挂载管理员权限校验。
+func (h *MeteringHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.GetUsage)
+	rg.POST("/export", h.ExportUsage)
+}
+
+// GetUsage 返回各租户在 period（YYYY-MM，UTC）的用量，默认当前月份。
+func (h *MeteringHandler) GetUsage(ctx *gin.Context) {
+	period := strings.TrimSpace(ctx.Query("period"))
+	if period == "" {
+		period = meteringsvc.PeriodOf(time.Now())
+	}
+	usages, err := h.service.Usage(ctx, period)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"period": period, "items": usages})
+}
+
+// RegisterStorageRoutes 注册存储占用报告路由，调用方负责挂载管理员权限校验。
+func (h *MeteringHandler) RegisterStorageRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.GetStorage)
+}
+
+// GetStorage 返回各租户的行数与估算存储字节数，以及占用最多的 ?top= 个 Prompt（默认 10，最多 100）。
+func (h *MeteringHandler) GetStorage(ctx *gin.Context) {
+	report, err := h.service.Storage(ctx, parseQueryInt(ctx.Query("top"), 0))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, report)
+}
+
+// ExportUsage 立即向已配置的计费系统推送当前与上一个月份的用量。
+func (h *MeteringHandler) ExportUsage(ctx *gin.Context) {
+	if !h.service.HasExporters() {
+		httpx.RespondError(ctx, http.StatusConflict, "METERING_EXPORT_DISABLED", "未配置计费推送目标", nil)
+		return
+	}
+	if err := h.service.Export(ctx); err != nil {
+		httpx.RespondError(ctx, http.StatusBadGateway, "METERING_EXPORT_FAILED", err.Error(), nil)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"exported": true})
+}
+
+func (h *MeteringHandler) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, meteringsvc.ErrInvalidPeriod):
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PERIOD", err.Error(), nil)
+	default:
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+	}
+}
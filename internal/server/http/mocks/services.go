@@ -0,0 +1,1465 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services.go
+//
+// Generated by this command:
+//
+//	mockgen -source=services.go -destination=mocks/services.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	zip "archive/zip"
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	auth "github.com/zacharykka/prompt-manager/internal/service/auth"
+	prompt "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	audit "github.com/zacharykka/prompt-manager/pkg/audit"
+	auth0 "github.com/zacharykka/prompt-manager/pkg/auth"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPromptService is a mock of PromptService interface.
+type MockPromptService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromptServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockPromptServiceMockRecorder is the mock recorder for MockPromptService.
+type MockPromptServiceMockRecorder struct {
+	mock *MockPromptService
+}
+
+// NewMockPromptService creates a new mock instance.
+func NewMockPromptService(ctrl *gomock.Controller) *MockPromptService {
+	mock := &MockPromptService{ctrl: ctrl}
+	mock.recorder = &MockPromptServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromptService) EXPECT() *MockPromptServiceMockRecorder {
+	return m.recorder
+}
+
+// AbortCanary mocks base method.
+func (m *MockPromptService) AbortCanary(ctx context.Context, promptID, abortedBy string) (*domain.PromptCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortCanary", ctx, promptID, abortedBy)
+	ret0, _ := ret[0].(*domain.PromptCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AbortCanary indicates an expected call of AbortCanary.
+func (mr *MockPromptServiceMockRecorder) AbortCanary(ctx, promptID, abortedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortCanary", reflect.TypeOf((*MockPromptService)(nil).AbortCanary), ctx, promptID, abortedBy)
+}
+
+// AcquireEditLock mocks base method.
+func (m *MockPromptService) AcquireEditLock(ctx context.Context, promptID, holderID, holder string) (*domain.EditLock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireEditLock", ctx, promptID, holderID, holder)
+	ret0, _ := ret[0].(*domain.EditLock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireEditLock indicates an expected call of AcquireEditLock.
+func (mr *MockPromptServiceMockRecorder) AcquireEditLock(ctx, promptID, holderID, holder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireEditLock", reflect.TypeOf((*MockPromptService)(nil).AcquireEditLock), ctx, promptID, holderID, holder)
+}
+
+// ActivatePreviousVersion mocks base method.
+func (m *MockPromptService) ActivatePreviousVersion(ctx context.Context, input prompt.ActivateVersionInput) (*prompt.ActivationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivatePreviousVersion", ctx, input)
+	ret0, _ := ret[0].(*prompt.ActivationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivatePreviousVersion indicates an expected call of ActivatePreviousVersion.
+func (mr *MockPromptServiceMockRecorder) ActivatePreviousVersion(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivatePreviousVersion", reflect.TypeOf((*MockPromptService)(nil).ActivatePreviousVersion), ctx, input)
+}
+
+// ActivateVersion mocks base method.
+func (m *MockPromptService) ActivateVersion(ctx context.Context, input prompt.ActivateVersionInput) (*prompt.ActivationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateVersion", ctx, input)
+	ret0, _ := ret[0].(*prompt.ActivationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivateVersion indicates an expected call of ActivateVersion.
+func (mr *MockPromptServiceMockRecorder) ActivateVersion(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateVersion", reflect.TypeOf((*MockPromptService)(nil).ActivateVersion), ctx, input)
+}
+
+// ApproveVersion mocks base method.
+func (m *MockPromptService) ApproveVersion(ctx context.Context, input prompt.ApproveVersionInput) (*prompt.VersionReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveVersion", ctx, input)
+	ret0, _ := ret[0].(*prompt.VersionReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveVersion indicates an expected call of ApproveVersion.
+func (mr *MockPromptServiceMockRecorder) ApproveVersion(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveVersion", reflect.TypeOf((*MockPromptService)(nil).ApproveVersion), ctx, input)
+}
+
+// ArchivePrompt mocks base method.
+func (m *MockPromptService) ArchivePrompt(ctx context.Context, promptID, archivedBy string) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchivePrompt", ctx, promptID, archivedBy)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchivePrompt indicates an expected call of ArchivePrompt.
+func (mr *MockPromptServiceMockRecorder) ArchivePrompt(ctx, promptID, archivedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchivePrompt", reflect.TypeOf((*MockPromptService)(nil).ArchivePrompt), ctx, promptID, archivedBy)
+}
+
+// BlamePrompt mocks base method.
+func (m *MockPromptService) BlamePrompt(ctx context.Context, promptID, versionID string) (*prompt.PromptBlame, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlamePrompt", ctx, promptID, versionID)
+	ret0, _ := ret[0].(*prompt.PromptBlame)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlamePrompt indicates an expected call of BlamePrompt.
+func (mr *MockPromptServiceMockRecorder) BlamePrompt(ctx, promptID, versionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlamePrompt", reflect.TypeOf((*MockPromptService)(nil).BlamePrompt), ctx, promptID, versionID)
+}
+
+// CompareVersions mocks base method.
+func (m *MockPromptService) CompareVersions(ctx context.Context, promptID string, versionIDs []string) (*prompt.VersionComparison, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareVersions", ctx, promptID, versionIDs)
+	ret0, _ := ret[0].(*prompt.VersionComparison)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompareVersions indicates an expected call of CompareVersions.
+func (mr *MockPromptServiceMockRecorder) CompareVersions(ctx, promptID, versionIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareVersions", reflect.TypeOf((*MockPromptService)(nil).CompareVersions), ctx, promptID, versionIDs)
+}
+
+// CreateAlertRule mocks base method.
+func (m *MockPromptService) CreateAlertRule(ctx context.Context, input prompt.CreateAlertRuleInput) (*domain.PromptAlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlertRule", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptAlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlertRule indicates an expected call of CreateAlertRule.
+func (mr *MockPromptServiceMockRecorder) CreateAlertRule(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlertRule", reflect.TypeOf((*MockPromptService)(nil).CreateAlertRule), ctx, input)
+}
+
+// CreatePrompt mocks base method.
+func (m *MockPromptService) CreatePrompt(ctx context.Context, input prompt.CreatePromptInput) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePrompt", ctx, input)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePrompt indicates an expected call of CreatePrompt.
+func (mr *MockPromptServiceMockRecorder) CreatePrompt(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePrompt", reflect.TypeOf((*MockPromptService)(nil).CreatePrompt), ctx, input)
+}
+
+// CreatePromptFromTemplate mocks base method.
+func (m *MockPromptService) CreatePromptFromTemplate(ctx context.Context, input prompt.CreatePromptFromTemplateInput) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromptFromTemplate", ctx, input)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromptFromTemplate indicates an expected call of CreatePromptFromTemplate.
+func (mr *MockPromptServiceMockRecorder) CreatePromptFromTemplate(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromptFromTemplate", reflect.TypeOf((*MockPromptService)(nil).CreatePromptFromTemplate), ctx, input)
+}
+
+// CreatePromptVersion mocks base method.
+func (m *MockPromptService) CreatePromptVersion(ctx context.Context, input prompt.CreatePromptVersionInput) (*domain.PromptVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromptVersion", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromptVersion indicates an expected call of CreatePromptVersion.
+func (mr *MockPromptServiceMockRecorder) CreatePromptVersion(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromptVersion", reflect.TypeOf((*MockPromptService)(nil).CreatePromptVersion), ctx, input)
+}
+
+// CreateTemplate mocks base method.
+func (m *MockPromptService) CreateTemplate(ctx context.Context, input prompt.PromptTemplateInput) (*domain.PromptTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTemplate", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTemplate indicates an expected call of CreateTemplate.
+func (mr *MockPromptServiceMockRecorder) CreateTemplate(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTemplate", reflect.TypeOf((*MockPromptService)(nil).CreateTemplate), ctx, input)
+}
+
+// DeleteAlertRule mocks base method.
+func (m *MockPromptService) DeleteAlertRule(ctx context.Context, promptID, ruleID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlertRule", ctx, promptID, ruleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAlertRule indicates an expected call of DeleteAlertRule.
+func (mr *MockPromptServiceMockRecorder) DeleteAlertRule(ctx, promptID, ruleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlertRule", reflect.TypeOf((*MockPromptService)(nil).DeleteAlertRule), ctx, promptID, ruleID)
+}
+
+// DeleteDraft mocks base method.
+func (m *MockPromptService) DeleteDraft(ctx context.Context, promptID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDraft", ctx, promptID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDraft indicates an expected call of DeleteDraft.
+func (mr *MockPromptServiceMockRecorder) DeleteDraft(ctx, promptID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDraft", reflect.TypeOf((*MockPromptService)(nil).DeleteDraft), ctx, promptID, userID)
+}
+
+// DeletePrompt mocks base method.
+func (m *MockPromptService) DeletePrompt(ctx context.Context, promptID, deletedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePrompt", ctx, promptID, deletedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePrompt indicates an expected call of DeletePrompt.
+func (mr *MockPromptServiceMockRecorder) DeletePrompt(ctx, promptID, deletedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePrompt", reflect.TypeOf((*MockPromptService)(nil).DeletePrompt), ctx, promptID, deletedBy)
+}
+
+// DeleteReviewPolicy mocks base method.
+func (m *MockPromptService) DeleteReviewPolicy(ctx context.Context, promptID string, by prompt.ReviewActor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReviewPolicy", ctx, promptID, by)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReviewPolicy indicates an expected call of DeleteReviewPolicy.
+func (mr *MockPromptServiceMockRecorder) DeleteReviewPolicy(ctx, promptID, by any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReviewPolicy", reflect.TypeOf((*MockPromptService)(nil).DeleteReviewPolicy), ctx, promptID, by)
+}
+
+// DeleteTemplate mocks base method.
+func (m *MockPromptService) DeleteTemplate(ctx context.Context, slug string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplate", ctx, slug)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplate indicates an expected call of DeleteTemplate.
+func (mr *MockPromptServiceMockRecorder) DeleteTemplate(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplate", reflect.TypeOf((*MockPromptService)(nil).DeleteTemplate), ctx, slug)
+}
+
+// DeleteVersionLocale mocks base method.
+func (m *MockPromptService) DeleteVersionLocale(ctx context.Context, promptID, versionID, rawLocale, deletedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVersionLocale", ctx, promptID, versionID, rawLocale, deletedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVersionLocale indicates an expected call of DeleteVersionLocale.
+func (mr *MockPromptServiceMockRecorder) DeleteVersionLocale(ctx, promptID, versionID, rawLocale, deletedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVersionLocale", reflect.TypeOf((*MockPromptService)(nil).DeleteVersionLocale), ctx, promptID, versionID, rawLocale, deletedBy)
+}
+
+// DiffPromptVersion mocks base method.
+func (m *MockPromptService) DiffPromptVersion(ctx context.Context, promptID, baseVersionID string, opts prompt.DiffPromptVersionOptions) (*prompt.PromptVersionDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffPromptVersion", ctx, promptID, baseVersionID, opts)
+	ret0, _ := ret[0].(*prompt.PromptVersionDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffPromptVersion indicates an expected call of DiffPromptVersion.
+func (mr *MockPromptServiceMockRecorder) DiffPromptVersion(ctx, promptID, baseVersionID, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffPromptVersion", reflect.TypeOf((*MockPromptService)(nil).DiffPromptVersion), ctx, promptID, baseVersionID, opts)
+}
+
+// DiffRenderedVersions mocks base method.
+func (m *MockPromptService) DiffRenderedVersions(ctx context.Context, input prompt.RenderedDiffInput) (*prompt.RenderedVersionDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffRenderedVersions", ctx, input)
+	ret0, _ := ret[0].(*prompt.RenderedVersionDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffRenderedVersions indicates an expected call of DiffRenderedVersions.
+func (mr *MockPromptServiceMockRecorder) DiffRenderedVersions(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffRenderedVersions", reflect.TypeOf((*MockPromptService)(nil).DiffRenderedVersions), ctx, input)
+}
+
+// ExportArchive mocks base method.
+func (m *MockPromptService) ExportArchive(ctx context.Context, zw *zip.Writer, opts prompt.ExportArchiveOptions) (*prompt.ExportManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportArchive", ctx, zw, opts)
+	ret0, _ := ret[0].(*prompt.ExportManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportArchive indicates an expected call of ExportArchive.
+func (mr *MockPromptServiceMockRecorder) ExportArchive(ctx, zw, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportArchive", reflect.TypeOf((*MockPromptService)(nil).ExportArchive), ctx, zw, opts)
+}
+
+// GetCanary mocks base method.
+func (m *MockPromptService) GetCanary(ctx context.Context, promptID string) (*domain.PromptCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCanary", ctx, promptID)
+	ret0, _ := ret[0].(*domain.PromptCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCanary indicates an expected call of GetCanary.
+func (mr *MockPromptServiceMockRecorder) GetCanary(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCanary", reflect.TypeOf((*MockPromptService)(nil).GetCanary), ctx, promptID)
+}
+
+// GetDraft mocks base method.
+func (m *MockPromptService) GetDraft(ctx context.Context, promptID, userID string) (*prompt.PromptDraftView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraft", ctx, promptID, userID)
+	ret0, _ := ret[0].(*prompt.PromptDraftView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDraft indicates an expected call of GetDraft.
+func (mr *MockPromptServiceMockRecorder) GetDraft(ctx, promptID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraft", reflect.TypeOf((*MockPromptService)(nil).GetDraft), ctx, promptID, userID)
+}
+
+// GetEditLock mocks base method.
+func (m *MockPromptService) GetEditLock(ctx context.Context, promptID string) (*domain.EditLock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEditLock", ctx, promptID)
+	ret0, _ := ret[0].(*domain.EditLock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEditLock indicates an expected call of GetEditLock.
+func (mr *MockPromptServiceMockRecorder) GetEditLock(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEditLock", reflect.TypeOf((*MockPromptService)(nil).GetEditLock), ctx, promptID)
+}
+
+// GetExecutionStats mocks base method.
+func (m *MockPromptService) GetExecutionStats(ctx context.Context, promptID string, opts prompt.ExecutionStatsOptions) ([]*domain.PromptExecutionAggregate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExecutionStats", ctx, promptID, opts)
+	ret0, _ := ret[0].([]*domain.PromptExecutionAggregate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExecutionStats indicates an expected call of GetExecutionStats.
+func (mr *MockPromptServiceMockRecorder) GetExecutionStats(ctx, promptID, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExecutionStats", reflect.TypeOf((*MockPromptService)(nil).GetExecutionStats), ctx, promptID, opts)
+}
+
+// GetLocaleCoverage mocks base method.
+func (m *MockPromptService) GetLocaleCoverage(ctx context.Context, requested string) (*prompt.LocaleCoverageReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocaleCoverage", ctx, requested)
+	ret0, _ := ret[0].(*prompt.LocaleCoverageReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocaleCoverage indicates an expected call of GetLocaleCoverage.
+func (mr *MockPromptServiceMockRecorder) GetLocaleCoverage(ctx, requested any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocaleCoverage", reflect.TypeOf((*MockPromptService)(nil).GetLocaleCoverage), ctx, requested)
+}
+
+// GetPrompt mocks base method.
+func (m *MockPromptService) GetPrompt(ctx context.Context, promptID string) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrompt", ctx, promptID)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrompt indicates an expected call of GetPrompt.
+func (mr *MockPromptServiceMockRecorder) GetPrompt(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrompt", reflect.TypeOf((*MockPromptService)(nil).GetPrompt), ctx, promptID)
+}
+
+// GetPromptWithLocale mocks base method.
+func (m *MockPromptService) GetPromptWithLocale(ctx context.Context, promptID, requested string) (*domain.Prompt, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromptWithLocale", ctx, promptID, requested)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPromptWithLocale indicates an expected call of GetPromptWithLocale.
+func (mr *MockPromptServiceMockRecorder) GetPromptWithLocale(ctx, promptID, requested any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromptWithLocale", reflect.TypeOf((*MockPromptService)(nil).GetPromptWithLocale), ctx, promptID, requested)
+}
+
+// GetReviewPolicy mocks base method.
+func (m *MockPromptService) GetReviewPolicy(ctx context.Context, promptID string) (*domain.PromptReviewPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewPolicy", ctx, promptID)
+	ret0, _ := ret[0].(*domain.PromptReviewPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewPolicy indicates an expected call of GetReviewPolicy.
+func (mr *MockPromptServiceMockRecorder) GetReviewPolicy(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewPolicy", reflect.TypeOf((*MockPromptService)(nil).GetReviewPolicy), ctx, promptID)
+}
+
+// GetTemplate mocks base method.
+func (m *MockPromptService) GetTemplate(ctx context.Context, slug string) (*domain.PromptTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplate", ctx, slug)
+	ret0, _ := ret[0].(*domain.PromptTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplate indicates an expected call of GetTemplate.
+func (mr *MockPromptServiceMockRecorder) GetTemplate(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplate", reflect.TypeOf((*MockPromptService)(nil).GetTemplate), ctx, slug)
+}
+
+// GetVersionReview mocks base method.
+func (m *MockPromptService) GetVersionReview(ctx context.Context, promptID, versionID string) (*prompt.VersionReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersionReview", ctx, promptID, versionID)
+	ret0, _ := ret[0].(*prompt.VersionReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersionReview indicates an expected call of GetVersionReview.
+func (mr *MockPromptServiceMockRecorder) GetVersionReview(ctx, promptID, versionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersionReview", reflect.TypeOf((*MockPromptService)(nil).GetVersionReview), ctx, promptID, versionID)
+}
+
+// ImportArchive mocks base method.
+func (m *MockPromptService) ImportArchive(ctx context.Context, r io.ReaderAt, size int64, opts prompt.ImportOptions) (*prompt.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportArchive", ctx, r, size, opts)
+	ret0, _ := ret[0].(*prompt.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportArchive indicates an expected call of ImportArchive.
+func (mr *MockPromptServiceMockRecorder) ImportArchive(ctx, r, size, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportArchive", reflect.TypeOf((*MockPromptService)(nil).ImportArchive), ctx, r, size, opts)
+}
+
+// ImportCSV mocks base method.
+func (m *MockPromptService) ImportCSV(ctx context.Context, r io.Reader, opts prompt.ImportOptions) (*prompt.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCSV", ctx, r, opts)
+	ret0, _ := ret[0].(*prompt.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportCSV indicates an expected call of ImportCSV.
+func (mr *MockPromptServiceMockRecorder) ImportCSV(ctx, r, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCSV", reflect.TypeOf((*MockPromptService)(nil).ImportCSV), ctx, r, opts)
+}
+
+// IterateAuditLogs mocks base method.
+func (m *MockPromptService) IterateAuditLogs(ctx context.Context, opts domain.AuditLogIterateOptions, fn func(*domain.PromptAuditLog) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateAuditLogs", ctx, opts, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateAuditLogs indicates an expected call of IterateAuditLogs.
+func (mr *MockPromptServiceMockRecorder) IterateAuditLogs(ctx, opts, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateAuditLogs", reflect.TypeOf((*MockPromptService)(nil).IterateAuditLogs), ctx, opts, fn)
+}
+
+// IterateExecutionLogs mocks base method.
+func (m *MockPromptService) IterateExecutionLogs(ctx context.Context, promptID string, days int, fn func(*domain.PromptExecutionLog) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateExecutionLogs", ctx, promptID, days, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateExecutionLogs indicates an expected call of IterateExecutionLogs.
+func (mr *MockPromptServiceMockRecorder) IterateExecutionLogs(ctx, promptID, days, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateExecutionLogs", reflect.TypeOf((*MockPromptService)(nil).IterateExecutionLogs), ctx, promptID, days, fn)
+}
+
+// IterateVersions mocks base method.
+func (m *MockPromptService) IterateVersions(ctx context.Context, promptID, status string, fn func(*domain.PromptVersion) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateVersions", ctx, promptID, status, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateVersions indicates an expected call of IterateVersions.
+func (mr *MockPromptServiceMockRecorder) IterateVersions(ctx, promptID, status, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateVersions", reflect.TypeOf((*MockPromptService)(nil).IterateVersions), ctx, promptID, status, fn)
+}
+
+// ListActivations mocks base method.
+func (m *MockPromptService) ListActivations(ctx context.Context, promptID string) ([]prompt.ActivationEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActivations", ctx, promptID)
+	ret0, _ := ret[0].([]prompt.ActivationEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActivations indicates an expected call of ListActivations.
+func (mr *MockPromptServiceMockRecorder) ListActivations(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActivations", reflect.TypeOf((*MockPromptService)(nil).ListActivations), ctx, promptID)
+}
+
+// ListAlertRules mocks base method.
+func (m *MockPromptService) ListAlertRules(ctx context.Context, promptID string) ([]*domain.PromptAlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlertRules", ctx, promptID)
+	ret0, _ := ret[0].([]*domain.PromptAlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlertRules indicates an expected call of ListAlertRules.
+func (mr *MockPromptServiceMockRecorder) ListAlertRules(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlertRules", reflect.TypeOf((*MockPromptService)(nil).ListAlertRules), ctx, promptID)
+}
+
+// ListExecutionLogs mocks base method.
+func (m *MockPromptService) ListExecutionLogs(ctx context.Context, promptID string, limit int) ([]*domain.PromptExecutionLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExecutionLogs", ctx, promptID, limit)
+	ret0, _ := ret[0].([]*domain.PromptExecutionLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExecutionLogs indicates an expected call of ListExecutionLogs.
+func (mr *MockPromptServiceMockRecorder) ListExecutionLogs(ctx, promptID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExecutionLogs", reflect.TypeOf((*MockPromptService)(nil).ListExecutionLogs), ctx, promptID, limit)
+}
+
+// ListPromptDependencies mocks base method.
+func (m *MockPromptService) ListPromptDependencies(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromptDependencies", ctx, promptID)
+	ret0, _ := ret[0].([]*domain.PromptDependencyLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromptDependencies indicates an expected call of ListPromptDependencies.
+func (mr *MockPromptServiceMockRecorder) ListPromptDependencies(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromptDependencies", reflect.TypeOf((*MockPromptService)(nil).ListPromptDependencies), ctx, promptID)
+}
+
+// ListPromptDependents mocks base method.
+func (m *MockPromptService) ListPromptDependents(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromptDependents", ctx, promptID)
+	ret0, _ := ret[0].([]*domain.PromptDependencyLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromptDependents indicates an expected call of ListPromptDependents.
+func (mr *MockPromptServiceMockRecorder) ListPromptDependents(ctx, promptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromptDependents", reflect.TypeOf((*MockPromptService)(nil).ListPromptDependents), ctx, promptID)
+}
+
+// ListPromptVersionsEx mocks base method.
+func (m *MockPromptService) ListPromptVersionsEx(ctx context.Context, promptID string, limit, offset int, status string) (*prompt.PromptVersionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromptVersionsEx", ctx, promptID, limit, offset, status)
+	ret0, _ := ret[0].(*prompt.PromptVersionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromptVersionsEx indicates an expected call of ListPromptVersionsEx.
+func (mr *MockPromptServiceMockRecorder) ListPromptVersionsEx(ctx, promptID, limit, offset, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromptVersionsEx", reflect.TypeOf((*MockPromptService)(nil).ListPromptVersionsEx), ctx, promptID, limit, offset, status)
+}
+
+// ListPromptsPage mocks base method.
+func (m *MockPromptService) ListPromptsPage(ctx context.Context, opts prompt.ListPromptsOptions) (*prompt.PromptPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromptsPage", ctx, opts)
+	ret0, _ := ret[0].(*prompt.PromptPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromptsPage indicates an expected call of ListPromptsPage.
+func (mr *MockPromptServiceMockRecorder) ListPromptsPage(ctx, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromptsPage", reflect.TypeOf((*MockPromptService)(nil).ListPromptsPage), ctx, opts)
+}
+
+// ListTemplates mocks base method.
+func (m *MockPromptService) ListTemplates(ctx context.Context) ([]*domain.PromptTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTemplates", ctx)
+	ret0, _ := ret[0].([]*domain.PromptTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTemplates indicates an expected call of ListTemplates.
+func (mr *MockPromptServiceMockRecorder) ListTemplates(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTemplates", reflect.TypeOf((*MockPromptService)(nil).ListTemplates), ctx)
+}
+
+// ListVersionLocales mocks base method.
+func (m *MockPromptService) ListVersionLocales(ctx context.Context, promptID, versionID string) ([]*domain.PromptVersionLocale, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersionLocales", ctx, promptID, versionID)
+	ret0, _ := ret[0].([]*domain.PromptVersionLocale)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersionLocales indicates an expected call of ListVersionLocales.
+func (mr *MockPromptServiceMockRecorder) ListVersionLocales(ctx, promptID, versionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersionLocales", reflect.TypeOf((*MockPromptService)(nil).ListVersionLocales), ctx, promptID, versionID)
+}
+
+// PreviewPromptVersion mocks base method.
+func (m *MockPromptService) PreviewPromptVersion(ctx context.Context, promptID, versionID, format string) (*prompt.PromptVersionPreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewPromptVersion", ctx, promptID, versionID, format)
+	ret0, _ := ret[0].(*prompt.PromptVersionPreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewPromptVersion indicates an expected call of PreviewPromptVersion.
+func (mr *MockPromptServiceMockRecorder) PreviewPromptVersion(ctx, promptID, versionID, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewPromptVersion", reflect.TypeOf((*MockPromptService)(nil).PreviewPromptVersion), ctx, promptID, versionID, format)
+}
+
+// PromoteCanary mocks base method.
+func (m *MockPromptService) PromoteCanary(ctx context.Context, input prompt.ActivateVersionInput) (*prompt.ActivationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteCanary", ctx, input)
+	ret0, _ := ret[0].(*prompt.ActivationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoteCanary indicates an expected call of PromoteCanary.
+func (mr *MockPromptServiceMockRecorder) PromoteCanary(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteCanary", reflect.TypeOf((*MockPromptService)(nil).PromoteCanary), ctx, input)
+}
+
+// RecordExecutions mocks base method.
+func (m *MockPromptService) RecordExecutions(ctx context.Context, inputs []prompt.ExecutionRecordInput, userID string) (*prompt.ExecutionBatchReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordExecutions", ctx, inputs, userID)
+	ret0, _ := ret[0].(*prompt.ExecutionBatchReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordExecutions indicates an expected call of RecordExecutions.
+func (mr *MockPromptServiceMockRecorder) RecordExecutions(ctx, inputs, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordExecutions", reflect.TypeOf((*MockPromptService)(nil).RecordExecutions), ctx, inputs, userID)
+}
+
+// ReleaseEditLock mocks base method.
+func (m *MockPromptService) ReleaseEditLock(ctx context.Context, promptID, holderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseEditLock", ctx, promptID, holderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseEditLock indicates an expected call of ReleaseEditLock.
+func (mr *MockPromptServiceMockRecorder) ReleaseEditLock(ctx, promptID, holderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseEditLock", reflect.TypeOf((*MockPromptService)(nil).ReleaseEditLock), ctx, promptID, holderID)
+}
+
+// RenderPrompt mocks base method.
+func (m *MockPromptService) RenderPrompt(ctx context.Context, input prompt.RenderPromptInput) (*prompt.RenderResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderPrompt", ctx, input)
+	ret0, _ := ret[0].(*prompt.RenderResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderPrompt indicates an expected call of RenderPrompt.
+func (mr *MockPromptServiceMockRecorder) RenderPrompt(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderPrompt", reflect.TypeOf((*MockPromptService)(nil).RenderPrompt), ctx, input)
+}
+
+// ReplayExecution mocks base method.
+func (m *MockPromptService) ReplayExecution(ctx context.Context, input prompt.ReplayExecutionInput) (*prompt.ExecutionReplay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayExecution", ctx, input)
+	ret0, _ := ret[0].(*prompt.ExecutionReplay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayExecution indicates an expected call of ReplayExecution.
+func (mr *MockPromptServiceMockRecorder) ReplayExecution(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayExecution", reflect.TypeOf((*MockPromptService)(nil).ReplayExecution), ctx, input)
+}
+
+// RestorePrompt mocks base method.
+func (m *MockPromptService) RestorePrompt(ctx context.Context, promptID, restoredBy string) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePrompt", ctx, promptID, restoredBy)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestorePrompt indicates an expected call of RestorePrompt.
+func (mr *MockPromptServiceMockRecorder) RestorePrompt(ctx, promptID, restoredBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePrompt", reflect.TypeOf((*MockPromptService)(nil).RestorePrompt), ctx, promptID, restoredBy)
+}
+
+// RevokeApproval mocks base method.
+func (m *MockPromptService) RevokeApproval(ctx context.Context, promptID, versionID string, by prompt.ReviewActor) (*prompt.VersionReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeApproval", ctx, promptID, versionID, by)
+	ret0, _ := ret[0].(*prompt.VersionReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeApproval indicates an expected call of RevokeApproval.
+func (mr *MockPromptServiceMockRecorder) RevokeApproval(ctx, promptID, versionID, by any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApproval", reflect.TypeOf((*MockPromptService)(nil).RevokeApproval), ctx, promptID, versionID, by)
+}
+
+// RotateAlertWebhookSecret mocks base method.
+func (m *MockPromptService) RotateAlertWebhookSecret(ctx context.Context, promptID, ruleID string, overlap time.Duration) (*domain.PromptAlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAlertWebhookSecret", ctx, promptID, ruleID, overlap)
+	ret0, _ := ret[0].(*domain.PromptAlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAlertWebhookSecret indicates an expected call of RotateAlertWebhookSecret.
+func (mr *MockPromptServiceMockRecorder) RotateAlertWebhookSecret(ctx, promptID, ruleID, overlap any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAlertWebhookSecret", reflect.TypeOf((*MockPromptService)(nil).RotateAlertWebhookSecret), ctx, promptID, ruleID, overlap)
+}
+
+// SaveDraft mocks base method.
+func (m *MockPromptService) SaveDraft(ctx context.Context, input prompt.SaveDraftInput) (*prompt.PromptDraftView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDraft", ctx, input)
+	ret0, _ := ret[0].(*prompt.PromptDraftView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveDraft indicates an expected call of SaveDraft.
+func (mr *MockPromptServiceMockRecorder) SaveDraft(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDraft", reflect.TypeOf((*MockPromptService)(nil).SaveDraft), ctx, input)
+}
+
+// SetPromptDependencies mocks base method.
+func (m *MockPromptService) SetPromptDependencies(ctx context.Context, promptID string, refs []string, updatedBy string) ([]*domain.PromptDependencyLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPromptDependencies", ctx, promptID, refs, updatedBy)
+	ret0, _ := ret[0].([]*domain.PromptDependencyLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPromptDependencies indicates an expected call of SetPromptDependencies.
+func (mr *MockPromptServiceMockRecorder) SetPromptDependencies(ctx, promptID, refs, updatedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPromptDependencies", reflect.TypeOf((*MockPromptService)(nil).SetPromptDependencies), ctx, promptID, refs, updatedBy)
+}
+
+// SetReviewPolicy mocks base method.
+func (m *MockPromptService) SetReviewPolicy(ctx context.Context, input prompt.SetReviewPolicyInput) (*domain.PromptReviewPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReviewPolicy", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptReviewPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetReviewPolicy indicates an expected call of SetReviewPolicy.
+func (mr *MockPromptServiceMockRecorder) SetReviewPolicy(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReviewPolicy", reflect.TypeOf((*MockPromptService)(nil).SetReviewPolicy), ctx, input)
+}
+
+// SetVersionLocale mocks base method.
+func (m *MockPromptService) SetVersionLocale(ctx context.Context, input prompt.SetVersionLocaleInput) (*domain.PromptVersionLocale, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVersionLocale", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptVersionLocale)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVersionLocale indicates an expected call of SetVersionLocale.
+func (mr *MockPromptServiceMockRecorder) SetVersionLocale(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersionLocale", reflect.TypeOf((*MockPromptService)(nil).SetVersionLocale), ctx, input)
+}
+
+// StartCanary mocks base method.
+func (m *MockPromptService) StartCanary(ctx context.Context, input prompt.StartCanaryInput) (*domain.PromptCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartCanary", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartCanary indicates an expected call of StartCanary.
+func (mr *MockPromptServiceMockRecorder) StartCanary(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartCanary", reflect.TypeOf((*MockPromptService)(nil).StartCanary), ctx, input)
+}
+
+// TestAlertWebhook mocks base method.
+func (m *MockPromptService) TestAlertWebhook(ctx context.Context, promptID, ruleID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TestAlertWebhook", ctx, promptID, ruleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TestAlertWebhook indicates an expected call of TestAlertWebhook.
+func (mr *MockPromptServiceMockRecorder) TestAlertWebhook(ctx, promptID, ruleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TestAlertWebhook", reflect.TypeOf((*MockPromptService)(nil).TestAlertWebhook), ctx, promptID, ruleID)
+}
+
+// TransferOwner mocks base method.
+func (m *MockPromptService) TransferOwner(ctx context.Context, promptID string, owner *domain.PromptOwner, actor string) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwner", ctx, promptID, owner, actor)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferOwner indicates an expected call of TransferOwner.
+func (mr *MockPromptServiceMockRecorder) TransferOwner(ctx, promptID, owner, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwner", reflect.TypeOf((*MockPromptService)(nil).TransferOwner), ctx, promptID, owner, actor)
+}
+
+// TransitionVersionStatus mocks base method.
+func (m *MockPromptService) TransitionVersionStatus(ctx context.Context, input prompt.TransitionVersionStatusInput) (*domain.PromptVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionVersionStatus", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitionVersionStatus indicates an expected call of TransitionVersionStatus.
+func (mr *MockPromptServiceMockRecorder) TransitionVersionStatus(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionVersionStatus", reflect.TypeOf((*MockPromptService)(nil).TransitionVersionStatus), ctx, input)
+}
+
+// UnarchivePrompt mocks base method.
+func (m *MockPromptService) UnarchivePrompt(ctx context.Context, promptID, unarchivedBy string) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnarchivePrompt", ctx, promptID, unarchivedBy)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnarchivePrompt indicates an expected call of UnarchivePrompt.
+func (mr *MockPromptServiceMockRecorder) UnarchivePrompt(ctx, promptID, unarchivedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnarchivePrompt", reflect.TypeOf((*MockPromptService)(nil).UnarchivePrompt), ctx, promptID, unarchivedBy)
+}
+
+// UpdatePrompt mocks base method.
+func (m *MockPromptService) UpdatePrompt(ctx context.Context, input prompt.UpdatePromptInput) (*domain.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrompt", ctx, input)
+	ret0, _ := ret[0].(*domain.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePrompt indicates an expected call of UpdatePrompt.
+func (mr *MockPromptServiceMockRecorder) UpdatePrompt(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrompt", reflect.TypeOf((*MockPromptService)(nil).UpdatePrompt), ctx, input)
+}
+
+// UpdateTemplate mocks base method.
+func (m *MockPromptService) UpdateTemplate(ctx context.Context, input prompt.PromptTemplateInput) (*domain.PromptTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplate", ctx, input)
+	ret0, _ := ret[0].(*domain.PromptTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplate indicates an expected call of UpdateTemplate.
+func (mr *MockPromptServiceMockRecorder) UpdateTemplate(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplate", reflect.TypeOf((*MockPromptService)(nil).UpdateTemplate), ctx, input)
+}
+
+// ValidatePromptVersion mocks base method.
+func (m *MockPromptService) ValidatePromptVersion(ctx context.Context, input prompt.CreatePromptVersionInput) (*prompt.ValidationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidatePromptVersion", ctx, input)
+	ret0, _ := ret[0].(*prompt.ValidationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidatePromptVersion indicates an expected call of ValidatePromptVersion.
+func (mr *MockPromptServiceMockRecorder) ValidatePromptVersion(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePromptVersion", reflect.TypeOf((*MockPromptService)(nil).ValidatePromptVersion), ctx, input)
+}
+
+// VerifyAuditChain mocks base method.
+func (m *MockPromptService) VerifyAuditChain(ctx context.Context) (*audit.ChainReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAuditChain", ctx)
+	ret0, _ := ret[0].(*audit.ChainReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAuditChain indicates an expected call of VerifyAuditChain.
+func (mr *MockPromptServiceMockRecorder) VerifyAuditChain(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAuditChain", reflect.TypeOf((*MockPromptService)(nil).VerifyAuditChain), ctx)
+}
+
+// MockAuthService is a mock of AuthService interface.
+type MockAuthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAuthServiceMockRecorder is the mock recorder for MockAuthService.
+type MockAuthServiceMockRecorder struct {
+	mock *MockAuthService
+}
+
+// NewMockAuthService creates a new mock instance.
+func NewMockAuthService(ctrl *gomock.Controller) *MockAuthService {
+	mock := &MockAuthService{ctrl: ctrl}
+	mock.recorder = &MockAuthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockAuthService) AcceptInvitation(ctx context.Context, token, password string) (*auth.Tokens, *domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, token, password)
+	ret0, _ := ret[0].(*auth.Tokens)
+	ret1, _ := ret[1].(*domain.User)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockAuthServiceMockRecorder) AcceptInvitation(ctx, token, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockAuthService)(nil).AcceptInvitation), ctx, token, password)
+}
+
+// ApproveUser mocks base method.
+func (m *MockAuthService) ApproveUser(ctx context.Context, userID, actor string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveUser", ctx, userID, actor)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveUser indicates an expected call of ApproveUser.
+func (mr *MockAuthServiceMockRecorder) ApproveUser(ctx, userID, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveUser", reflect.TypeOf((*MockAuthService)(nil).ApproveUser), ctx, userID, actor)
+}
+
+// AuthenticateAPIKey mocks base method.
+func (m *MockAuthService) AuthenticateAPIKey(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, secret)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(*domain.User)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockAuthServiceMockRecorder) AuthenticateAPIKey(ctx, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockAuthService)(nil).AuthenticateAPIKey), ctx, secret)
+}
+
+// ChangePassword mocks base method.
+func (m *MockAuthService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, currentPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthServiceMockRecorder) ChangePassword(ctx, userID, currentPassword, newPassword any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthService)(nil).ChangePassword), ctx, userID, currentPassword, newPassword)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAuthService) CreateAPIKey(ctx context.Context, input auth.CreateAPIKeyInput) (*auth.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, input)
+	ret0, _ := ret[0].(*auth.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAuthServiceMockRecorder) CreateAPIKey(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAuthService)(nil).CreateAPIKey), ctx, input)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockAuthService) CreateInvitation(ctx context.Context, input auth.CreateInvitationInput) (*domain.Invitation, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, input)
+	ret0, _ := ret[0].(*domain.Invitation)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockAuthServiceMockRecorder) CreateInvitation(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockAuthService)(nil).CreateInvitation), ctx, input)
+}
+
+// DeactivateUser mocks base method.
+func (m *MockAuthService) DeactivateUser(ctx context.Context, userID, transferToID, actor string) (*auth.UserDeactivationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", ctx, userID, transferToID, actor)
+	ret0, _ := ret[0].(*auth.UserDeactivationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockAuthServiceMockRecorder) DeactivateUser(ctx, userID, transferToID, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockAuthService)(nil).DeactivateUser), ctx, userID, transferToID, actor)
+}
+
+// GitHubAuthorizeURL mocks base method.
+func (m *MockAuthService) GitHubAuthorizeURL(redirectURI, responseMode, clientOrigin string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GitHubAuthorizeURL", redirectURI, responseMode, clientOrigin)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GitHubAuthorizeURL indicates an expected call of GitHubAuthorizeURL.
+func (mr *MockAuthServiceMockRecorder) GitHubAuthorizeURL(redirectURI, responseMode, clientOrigin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GitHubAuthorizeURL", reflect.TypeOf((*MockAuthService)(nil).GitHubAuthorizeURL), redirectURI, responseMode, clientOrigin)
+}
+
+// HandleGitHubCallback mocks base method.
+func (m *MockAuthService) HandleGitHubCallback(ctx context.Context, code, state, linkBinding string) (*auth.Tokens, *domain.User, string, string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleGitHubCallback", ctx, code, state, linkBinding)
+	ret0, _ := ret[0].(*auth.Tokens)
+	ret1, _ := ret[1].(*domain.User)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(string)
+	ret4, _ := ret[4].(string)
+	ret5, _ := ret[5].(error)
+	return ret0, ret1, ret2, ret3, ret4, ret5
+}
+
+// HandleGitHubCallback indicates an expected call of HandleGitHubCallback.
+func (mr *MockAuthServiceMockRecorder) HandleGitHubCallback(ctx, code, state, linkBinding any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleGitHubCallback", reflect.TypeOf((*MockAuthService)(nil).HandleGitHubCallback), ctx, code, state, linkBinding)
+}
+
+// IdentityLinkURL mocks base method.
+func (m *MockAuthService) IdentityLinkURL(ctx context.Context, userID, provider, redirectURI, responseMode, clientOrigin string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IdentityLinkURL", ctx, userID, provider, redirectURI, responseMode, clientOrigin)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IdentityLinkURL indicates an expected call of IdentityLinkURL.
+func (mr *MockAuthServiceMockRecorder) IdentityLinkURL(ctx, userID, provider, redirectURI, responseMode, clientOrigin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IdentityLinkURL", reflect.TypeOf((*MockAuthService)(nil).IdentityLinkURL), ctx, userID, provider, redirectURI, responseMode, clientOrigin)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAuthService) ListAPIKeys(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAuthServiceMockRecorder) ListAPIKeys(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAuthService)(nil).ListAPIKeys), ctx, userID)
+}
+
+// ListIdentities mocks base method.
+func (m *MockAuthService) ListIdentities(ctx context.Context, userID string) ([]*domain.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIdentities", ctx, userID)
+	ret0, _ := ret[0].([]*domain.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIdentities indicates an expected call of ListIdentities.
+func (mr *MockAuthServiceMockRecorder) ListIdentities(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIdentities", reflect.TypeOf((*MockAuthService)(nil).ListIdentities), ctx, userID)
+}
+
+// ListLoginEvents mocks base method.
+func (m *MockAuthService) ListLoginEvents(ctx context.Context, userID string, limit int) ([]*domain.LoginEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginEvents", ctx, userID, limit)
+	ret0, _ := ret[0].([]*domain.LoginEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoginEvents indicates an expected call of ListLoginEvents.
+func (mr *MockAuthServiceMockRecorder) ListLoginEvents(ctx, userID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginEvents", reflect.TypeOf((*MockAuthService)(nil).ListLoginEvents), ctx, userID, limit)
+}
+
+// ListPendingInvitations mocks base method.
+func (m *MockAuthService) ListPendingInvitations(ctx context.Context) ([]*domain.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingInvitations", ctx)
+	ret0, _ := ret[0].([]*domain.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingInvitations indicates an expected call of ListPendingInvitations.
+func (mr *MockAuthServiceMockRecorder) ListPendingInvitations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingInvitations", reflect.TypeOf((*MockAuthService)(nil).ListPendingInvitations), ctx)
+}
+
+// ListPendingUsers mocks base method.
+func (m *MockAuthService) ListPendingUsers(ctx context.Context) ([]*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingUsers", ctx)
+	ret0, _ := ret[0].([]*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingUsers indicates an expected call of ListPendingUsers.
+func (mr *MockAuthServiceMockRecorder) ListPendingUsers(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingUsers", reflect.TypeOf((*MockAuthService)(nil).ListPendingUsers), ctx)
+}
+
+// ListSigningKeys mocks base method.
+func (m *MockAuthService) ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSigningKeys", ctx)
+	ret0, _ := ret[0].([]*domain.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSigningKeys indicates an expected call of ListSigningKeys.
+func (mr *MockAuthServiceMockRecorder) ListSigningKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSigningKeys", reflect.TypeOf((*MockAuthService)(nil).ListSigningKeys), ctx)
+}
+
+// Login mocks base method.
+func (m *MockAuthService) Login(ctx context.Context, email, password string) (*auth.Tokens, *domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, email, password)
+	ret0, _ := ret[0].(*auth.Tokens)
+	ret1, _ := ret[1].(*domain.User)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockAuthServiceMockRecorder) Login(ctx, email, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), ctx, email, password)
+}
+
+// MergeUsers mocks base method.
+func (m *MockAuthService) MergeUsers(ctx context.Context, sourceID, targetID, actor string) (*auth.UserMergeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeUsers", ctx, sourceID, targetID, actor)
+	ret0, _ := ret[0].(*auth.UserMergeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeUsers indicates an expected call of MergeUsers.
+func (mr *MockAuthServiceMockRecorder) MergeUsers(ctx, sourceID, targetID, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeUsers", reflect.TypeOf((*MockAuthService)(nil).MergeUsers), ctx, sourceID, targetID, actor)
+}
+
+// PasswordPolicy mocks base method.
+func (m *MockAuthService) PasswordPolicy() auth0.PasswordPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PasswordPolicy")
+	ret0, _ := ret[0].(auth0.PasswordPolicy)
+	return ret0
+}
+
+// PasswordPolicy indicates an expected call of PasswordPolicy.
+func (mr *MockAuthServiceMockRecorder) PasswordPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordPolicy", reflect.TypeOf((*MockAuthService)(nil).PasswordPolicy))
+}
+
+// Refresh mocks base method.
+func (m *MockAuthService) Refresh(ctx context.Context, refreshToken string) (*auth.Tokens, *domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(*auth.Tokens)
+	ret1, _ := ret[1].(*domain.User)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthServiceMockRecorder) Refresh(ctx, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthService)(nil).Refresh), ctx, refreshToken)
+}
+
+// Register mocks base method.
+func (m *MockAuthService) Register(ctx context.Context, email, password, role string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, email, password, role)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockAuthServiceMockRecorder) Register(ctx, email, password, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthService)(nil).Register), ctx, email, password, role)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAuthService) RevokeAPIKey(ctx context.Context, userID, keyID, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, userID, keyID, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAuthServiceMockRecorder) RevokeAPIKey(ctx, userID, keyID, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAuthService)(nil).RevokeAPIKey), ctx, userID, keyID, actor)
+}
+
+// RotateSigningKey mocks base method.
+func (m *MockAuthService) RotateSigningKey(ctx context.Context, organizationID, algorithm, actor string) (*domain.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSigningKey", ctx, organizationID, algorithm, actor)
+	ret0, _ := ret[0].(*domain.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSigningKey indicates an expected call of RotateSigningKey.
+func (mr *MockAuthServiceMockRecorder) RotateSigningKey(ctx, organizationID, algorithm, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSigningKey", reflect.TypeOf((*MockAuthService)(nil).RotateSigningKey), ctx, organizationID, algorithm, actor)
+}
+
+// SigningKeys mocks base method.
+func (m *MockAuthService) SigningKeys() *auth0.KeySet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningKeys")
+	ret0, _ := ret[0].(*auth0.KeySet)
+	return ret0
+}
+
+// SigningKeys indicates an expected call of SigningKeys.
+func (mr *MockAuthServiceMockRecorder) SigningKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningKeys", reflect.TypeOf((*MockAuthService)(nil).SigningKeys))
+}
+
+// SwitchWorkspace mocks base method.
+func (m *MockAuthService) SwitchWorkspace(ctx context.Context, userID, workspaceID string) (*auth.Tokens, *domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwitchWorkspace", ctx, userID, workspaceID)
+	ret0, _ := ret[0].(*auth.Tokens)
+	ret1, _ := ret[1].(*domain.User)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SwitchWorkspace indicates an expected call of SwitchWorkspace.
+func (mr *MockAuthServiceMockRecorder) SwitchWorkspace(ctx, userID, workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwitchWorkspace", reflect.TypeOf((*MockAuthService)(nil).SwitchWorkspace), ctx, userID, workspaceID)
+}
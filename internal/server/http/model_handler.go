@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// ModelHandler 公开配置中的已知模型及其生命周期状态，供前端选择模型与提示迁移。
+type ModelHandler struct {
+	registry *llm.Registry
+}
+
+// NewModelHandler 创建 ModelHandler；registry 为 nil 时返回空列表。
+func NewModelHandler(registry *llm.Registry) *ModelHandler {
+	return &ModelHandler{registry: registry}
+}
+
+// ListModels 按配置顺序返回已知模型，可通过 status 查询参数只返回某一生命周期状态的模型。
+func (h *ModelHandler) ListModels(ctx *gin.Context) {
+	status := strings.ToLower(strings.TrimSpace(ctx.Query("status")))
+	if status != "" && !llm.ValidModelStatus(status) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_STATUS", "status must be available, deprecated or retired", nil)
+		return
+	}
+	items := make([]llm.Model, 0)
+	for _, model := range h.registry.Models() {
+		if status == "" || model.Status == status {
+			items = append(items, model)
+		}
+	}
+	httpx.RespondOK(ctx, gin.H{"items": items})
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	pipelinesvc "github.com/zacharykka/prompt-manager/internal/service/pipeline"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// PipelineHandler 处理 Pipeline 相关 HTTP 请求。
+type PipelineHandler struct {
+	service *pipelinesvc.Service
+}
+
+// NewPipelineHandler 创建 PipelineHandler。
+func NewPipelineHandler(service *pipelinesvc.Service) *PipelineHandler {
+	return &PipelineHandler{service: service}
+}
+
+// RegisterRoutes 注册 Pipeline 相关路由。
+func (h *PipelineHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	// API Key 请求按范围授权：读取需 read、调用需 execute、修改需 write；Bearer 请求不受影响。
+	read := middleware.RequireScopes(domain.APIKeyScopeRead)
+	write := middleware.RequireScopes(domain.APIKeyScopeWrite)
+	rg.GET("", read, h.ListPipelines)
+	rg.POST("", write, h.CreatePipeline)
+	rg.GET("/:id", read, h.GetPipeline)
+	rg.PUT("/:id", write, h.UpdatePipeline)
+	rg.GET("/:id/versions", read, h.ListPipelineVersions)
+	rg.POST("/:id/invoke", middleware.RequireScopes(domain.APIKeyScopeExecute), h.InvokePipeline)
+}
+
+type createPipelineRequest struct {
+	Name        string             `json:"name" binding:"required,min=1,max=128"`
+	Description *string            `json:"description"`
+	Steps       []pipelinesvc.Step `json:"steps" binding:"required,min=1,max=50"`
+}
+
+type updatePipelineRequest struct {
+	Steps []pipelinesvc.Step `json:"steps" binding:"required,min=1,max=50"`
+}
+
+type invokePipelineRequest struct {
+	Version int                    `json:"version"`
+	Inputs  map[string]interface{} `json:"inputs"`
+	Model   string                 `json:"model"`
+}
+
+// CreatePipeline 创建 Pipeline 及其第一个版本。
+func (h *PipelineHandler) CreatePipeline(ctx *gin.Context) {
+	var req createPipelineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	createdBy := ctx.GetString(middleware.UserEmailContextKey)
+	if createdBy == "" {
+		createdBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	detail, err := h.service.CreatePipeline(ctx, pipelinesvc.CreatePipelineInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Steps:       req.Steps,
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, detail)
+}
+
+// ListPipelines 列出 Pipeline。
+func (h *PipelineHandler) ListPipelines(ctx *gin.Context) {
+	limit, offset := parsePagination(ctx.Query("limit"), ctx.Query("offset"))
+
+	items, total, err := h.service.ListPipelines(ctx, limit, offset)
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusInternalServerError, "LIST_FAILED", err.Error(), nil)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{
+		"items": items,
+		"meta": gin.H{
+			"total":   total,
+			"limit":   limit,
+			"offset":  offset,
+			"hasMore": int64(offset)+int64(len(items)) < total,
+		},
+	})
+}
+
+// GetPipeline 返回 Pipeline 及指定版本（?version=，默认最新）。
+func (h *PipelineHandler) GetPipeline(ctx *gin.Context) {
+	detail, err := h.service.GetPipeline(ctx, ctx.Param("id"), parseQueryInt(ctx.Query("version"), 0))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, detail)
+}
+
+// UpdatePipeline 保存新的步骤定义为新版本。
+func (h *PipelineHandler) UpdatePipeline(ctx *gin.Context) {
+	var req updatePipelineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	updatedBy := ctx.GetString(middleware.UserEmailContextKey)
+	if updatedBy == "" {
+		updatedBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	detail, err := h.service.UpdatePipelineSteps(ctx, ctx.Param("id"), req.Steps, updatedBy)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, detail)
+}
+
+// ListPipelineVersions 列出 Pipeline 的版本。
+func (h *PipelineHandler) ListPipelineVersions(ctx *gin.Context) {
+	versions, err := h.service.ListPipelineVersions(ctx, ctx.Param("id"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"items": versions})
+}
+
+// InvokePipeline 执行 Pipeline，步骤失败时返回已执行步骤的结果。
+func (h *PipelineHandler) InvokePipeline(ctx *gin.Context) {
+	var req invokePipelineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	result, err := h.service.Invoke(ctx, pipelinesvc.InvokeInput{
+		PipelineID: ctx.Param("id"),
+		Version:    req.Version,
+		Inputs:     req.Inputs,
+		UserID:     ctx.GetString(middleware.UserContextKey),
+		Model:      req.Model,
+	})
+	if err != nil {
+		if errors.Is(err, pipelinesvc.ErrStepExecutionFailed) && result != nil {
+			httpx.RespondError(ctx, http.StatusBadGateway, "STEP_FAILED", err.Error(), gin.H{"result": result})
+			return
+		}
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"result": result})
+}
+
+func (h *PipelineHandler) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, pipelinesvc.ErrNameRequired), errors.Is(err, pipelinesvc.ErrStepsRequired), errors.Is(err, pipelinesvc.ErrInvalidStep):
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrPipelineCycle):
+		httpx.RespondError(ctx, http.StatusBadRequest, "PIPELINE_CYCLE", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrPromptNotFound), errors.Is(err, pipelinesvc.ErrPromptNotActive):
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_STEP_PROMPT", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrPromptArchived):
+		httpx.RespondError(ctx, http.StatusConflict, "PROMPT_ARCHIVED", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrPipelineExists):
+		httpx.RespondError(ctx, http.StatusConflict, "PIPELINE_EXISTS", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrPipelineNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "PIPELINE_NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrVersionNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "VERSION_NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrRenderFailed):
+		httpx.RespondError(ctx, http.StatusUnprocessableEntity, "RENDER_FAILED", err.Error(), nil)
+	case errors.Is(err, pipelinesvc.ErrGatewayUnavailable):
+		httpx.RespondError(ctx, http.StatusServiceUnavailable, "GATEWAY_UNAVAILABLE", err.Error(), nil)
+	default:
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+	}
+}
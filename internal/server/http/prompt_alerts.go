@@ -0,0 +1,104 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type createAlertRuleRequest struct {
+	Name          string  `json:"name" binding:"required,max=128"`
+	Metric        string  `json:"metric" binding:"required,oneof=error_rate p95_latency"`
+	Threshold     float64 `json:"threshold" binding:"required"`
+	WindowMinutes int     `json:"window_minutes" binding:"required"`
+	WebhookURL    string  `json:"webhook_url"`
+}
+
+type rotateWebhookSecretRequest struct {
+	// OverlapMinutes 为旧密钥继续参与签名的分钟数，缺省 1440（24 小时），0 表示立即失效。
+	OverlapMinutes *int `json:"overlap_minutes"`
+}
+
+// defaultSecretOverlap 为轮换签名密钥时旧密钥的默认保留时长。
+const defaultSecretOverlap = 24 * time.Hour
+
+// ListAlertRules 返回 Prompt 的告警规则及当前状态（ok/firing）。
+func (h *PromptHandler) ListAlertRules(ctx *gin.Context) {
+	rules, err := h.service.ListAlertRules(ctx, ctx.Param("id"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": rules})
+}
+
+// CreateAlertRule 为 Prompt 新增告警规则。
+func (h *PromptHandler) CreateAlertRule(ctx *gin.Context) {
+	var req createAlertRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	rule, err := h.service.CreateAlertRule(ctx, promptsvc.CreateAlertRuleInput{
+		PromptID:      ctx.Param("id"),
+		Name:          req.Name,
+		Metric:        req.Metric,
+		Threshold:     req.Threshold,
+		WindowMinutes: req.WindowMinutes,
+		WebhookURL:    req.WebhookURL,
+		CreatedBy:     actorFromContext(ctx),
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	response := gin.H{"rule": rule}
+	if rule.WebhookSecret != "" {
+		response["webhook_secret"] = rule.WebhookSecret
+	}
+	httpx.RespondOK(ctx, response)
+}
+
+// DeleteAlertRule 删除 Prompt 的告警规则。
+func (h *PromptHandler) DeleteAlertRule(ctx *gin.Context) {
+	if err := h.service.DeleteAlertRule(ctx, ctx.Param("id"), ctx.Param("alertId")); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"alert_id": ctx.Param("alertId")})
+}
+
+// RotateAlertWebhookSecret 轮换告警 webhook 的签名密钥，新密钥只在响应中返回一次。
+func (h *PromptHandler) RotateAlertWebhookSecret(ctx *gin.Context) {
+	var req rotateWebhookSecretRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+	overlap := defaultSecretOverlap
+	if req.OverlapMinutes != nil {
+		overlap = time.Duration(*req.OverlapMinutes) * time.Minute
+	}
+
+	rule, err := h.service.RotateAlertWebhookSecret(ctx, ctx.Param("id"), ctx.Param("alertId"), overlap)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"rule": rule, "webhook_secret": rule.WebhookSecret})
+}
+
+// TestAlertWebhook 向告警 webhook 发送一条签名的示例事件，接收端返回错误时响应 502。
+func (h *PromptHandler) TestAlertWebhook(ctx *gin.Context) {
+	if err := h.service.TestAlertWebhook(ctx, ctx.Param("id"), ctx.Param("alertId")); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"alert_id": ctx.Param("alertId"), "delivered": true})
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// ArchivePrompt 归档 Prompt，归档后默认列表隐藏且禁止渲染。
+func (h *PromptHandler) ArchivePrompt(ctx *gin.Context) {
+	archived, err := h.service.ArchivePrompt(ctx, ctx.Param("id"), actorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"prompt": archived})
+}
+
+// UnarchivePrompt 取消归档，恢复为可用状态。
+func (h *PromptHandler) UnarchivePrompt(ctx *gin.Context) {
+	restored, err := h.service.UnarchivePrompt(ctx, ctx.Param("id"), actorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"prompt": restored})
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// VerifyAuditChain 校验审计日志哈希链，链完整时 verified 为 true，否则返回检测到的问题。
+func (h *PromptHandler) VerifyAuditChain(ctx *gin.Context) {
+	report, err := h.service.VerifyAuditChain(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, report)
+}
+
+// ExportAuditLogs 以 NDJSON 流式导出审计日志（含 seq/prev_hash/hash），支持 prompt_id、from、to 过滤。
+func (h *PromptHandler) ExportAuditLogs(ctx *gin.Context) {
+	opts := domain.AuditLogIterateOptions{PromptID: strings.TrimSpace(ctx.Query("prompt_id"))}
+	var ok bool
+	if opts.From, ok = parseQueryTime(ctx, "from"); !ok {
+		return
+	}
+	if opts.To, ok = parseQueryTime(ctx, "to"); !ok {
+		return
+	}
+
+	stream := newNDJSONStream(ctx, "audit-logs-"+time.Now().UTC().Format("20060102T150405Z")+".ndjson")
+	err := h.service.IterateAuditLogs(ctx, opts, func(log *domain.PromptAuditLog) error {
+		return stream.Encode(log)
+	})
+	stream.finish(err, h.handleError)
+}
+
+// parseQueryTime 解析 RFC3339 或 YYYY-MM-DD 格式的查询参数，为空时返回零值。
+func parseQueryTime(ctx *gin.Context, key string) (time.Time, bool) {
+	value := strings.TrimSpace(ctx.Query(key))
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true
+	}
+	httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_INPUT", fmt.Sprintf("invalid %s: expected RFC3339 or YYYY-MM-DD", key), nil)
+	return time.Time{}, false
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/infra/cache"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+)
+
+// promptListCacheKey 按工作区与规范化后的查询参数缓存 Prompt 列表；createdBy=me 的结果因人而异，键中附带当前用户。
+func promptListCacheKey(ctx *gin.Context) (string, []string, bool) {
+	workspaceID := ctx.GetString(middleware.WorkspaceContextKey)
+	query := ctx.Request.URL.Query()
+	key := "prompts|" + workspaceID + "|" + query.Encode()
+	if strings.TrimSpace(query.Get("createdBy")) == "me" {
+		key += "|" + ctx.GetString(middleware.UserContextKey)
+	}
+	return key, []string{cache.WorkspaceScope(workspaceID)}, true
+}
+
+// promptVersionsCacheKey 按工作区、Prompt 与查询参数缓存版本列表；NDJSON 等流式输出不缓存，避免整体缓冲响应。
+func promptVersionsCacheKey(ctx *gin.Context) (string, []string, bool) {
+	if format := strings.ToLower(strings.TrimSpace(ctx.Query("format"))); format != "" && format != "json" {
+		return "", nil, false
+	}
+	promptID := ctx.Param("id")
+	key := "versions|" + ctx.GetString(middleware.WorkspaceContextKey) + "|" + promptID + "|" + ctx.Request.URL.Query().Encode()
+	return key, []string{cache.PromptScope(promptID)}, true
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type startCanaryRequest struct {
+	Percent           int     `json:"percent" binding:"required,min=1,max=99"`
+	MaxErrorRateDelta float64 `json:"max_error_rate_delta" binding:"required,gt=0,lte=100"`
+	MinCalls          int     `json:"min_calls" binding:"omitempty,min=1"`
+	WindowMinutes     int     `json:"window_minutes" binding:"omitempty,min=1"`
+	WebhookURL        string  `json:"webhook_url" binding:"omitempty,url"`
+}
+
+func canaryActorFromContext(ctx *gin.Context) string {
+	actor := ctx.GetString(middleware.UserEmailContextKey)
+	if actor == "" {
+		actor = ctx.GetString(middleware.UserContextKey)
+	}
+	return actor
+}
+
+// StartCanary 以灰度方式激活版本，按比例分流并在错误率超限时自动回滚。
+func (h *PromptHandler) StartCanary(ctx *gin.Context) {
+	var req startCanaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	canary, err := h.service.StartCanary(ctx, promptsvc.StartCanaryInput{
+		PromptID:          ctx.Param("id"),
+		VersionID:         ctx.Param("versionId"),
+		Percent:           req.Percent,
+		MaxErrorRateDelta: req.MaxErrorRateDelta,
+		MinCalls:          req.MinCalls,
+		WindowMinutes:     req.WindowMinutes,
+		WebhookURL:        req.WebhookURL,
+		StartedBy:         canaryActorFromContext(ctx),
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"canary": canary})
+}
+
+// GetCanary 返回进行中的灰度及最近一次评估的错误率。
+func (h *PromptHandler) GetCanary(ctx *gin.Context) {
+	canary, err := h.service.GetCanary(ctx, ctx.Param("id"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"canary": canary})
+}
+
+// PromoteCanary 将灰度版本全量激活，可附带发布说明。
+func (h *PromptHandler) PromoteCanary(ctx *gin.Context) {
+	var req activateVersionRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+	promptID := ctx.Param("id")
+	result, err := h.service.PromoteCanary(ctx, promptsvc.ActivateVersionInput{
+		PromptID:    promptID,
+		ActivatedBy: canaryActorFromContext(ctx),
+		ReleaseNote: req.ReleaseNote,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	response := gin.H{"prompt_id": promptID, "active_version_id": result.Event.VersionID}
+	if result.NotifyErr != nil {
+		response["warnings"] = []string{fmt.Sprintf("activation notification failed: %v", result.NotifyErr)}
+	}
+	httpx.RespondOK(ctx, response)
+}
+
+// AbortCanary 终止灰度，流量全部回到激活版本。
+func (h *PromptHandler) AbortCanary(ctx *gin.Context) {
+	canary, err := h.service.AbortCanary(ctx, ctx.Param("id"), canaryActorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"canary": canary})
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type setPromptDependenciesRequest struct {
+	DependsOn []string `json:"depends_on" binding:"max=50"`
+}
+
+// ListPromptDependencies 返回 Prompt 依赖的其他 Prompt。
+func (h *PromptHandler) ListPromptDependencies(ctx *gin.Context) {
+	links, err := h.service.ListPromptDependencies(ctx, ctx.Param("id"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"items": links})
+}
+
+// ListPromptDependents 返回依赖该 Prompt 的其他 Prompt。
+func (h *PromptHandler) ListPromptDependents(ctx *gin.Context) {
+	links, err := h.service.ListPromptDependents(ctx, ctx.Param("id"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"items": links})
+}
+
+// SetPromptDependencies 覆盖 Prompt 显式声明的依赖。
+func (h *PromptHandler) SetPromptDependencies(ctx *gin.Context) {
+	var req setPromptDependenciesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	updatedBy := ctx.GetString(middleware.UserEmailContextKey)
+	if updatedBy == "" {
+		updatedBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	links, err := h.service.SetPromptDependencies(ctx, ctx.Param("id"), req.DependsOn, updatedBy)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"items": links})
+}
+
+// dependentWarnings 在删除或切换版本前查询依赖方，查询失败时不阻断主流程。
+func (h *PromptHandler) dependentWarnings(ctx *gin.Context, promptID string) []string {
+	dependents, err := h.service.ListPromptDependents(ctx, promptID)
+	if err != nil || len(dependents) == 0 {
+		return nil
+	}
+	warnings := make([]string, 0, len(dependents))
+	for _, dependent := range dependents {
+		warnings = append(warnings, fmt.Sprintf("prompt %q depends on this prompt (%s)", dependent.Name, dependent.Source))
+	}
+	return warnings
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type saveDraftRequest struct {
+	Body            string      `json:"body"`
+	VariablesSchema interface{} `json:"variables_schema"`
+	Metadata        interface{} `json:"metadata"`
+	BaseVersionID   *string     `json:"base_version_id"`
+	// Revision 为客户端最后看到的草稿版本号，省略时直接覆盖。
+	Revision *int `json:"revision" binding:"omitempty,min=0"`
+}
+
+// GetPromptDraft 返回当前用户在该 Prompt 上的草稿。
+func (h *PromptHandler) GetPromptDraft(ctx *gin.Context) {
+	draft, err := h.service.GetDraft(ctx, ctx.Param("id"), ctx.GetString(middleware.UserContextKey))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"draft": draft})
+}
+
+// SavePromptDraft 创建或覆盖当前用户的草稿，revision 不一致时返回 409 与服务端草稿。
+func (h *PromptHandler) SavePromptDraft(ctx *gin.Context) {
+	var req saveDraftRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	promptID := ctx.Param("id")
+	userID := ctx.GetString(middleware.UserContextKey)
+	draft, err := h.service.SaveDraft(ctx, promptsvc.SaveDraftInput{
+		PromptID:        promptID,
+		UserID:          userID,
+		Body:            req.Body,
+		VariablesSchema: req.VariablesSchema,
+		Metadata:        req.Metadata,
+		BaseVersionID:   req.BaseVersionID,
+		Revision:        req.Revision,
+	})
+	if err != nil {
+		if errors.Is(err, promptsvc.ErrDraftConflict) {
+			var details gin.H
+			if current, getErr := h.service.GetDraft(ctx, promptID, userID); getErr == nil {
+				details = gin.H{"draft": current}
+			}
+			httpx.RespondError(ctx, http.StatusConflict, "DRAFT_CONFLICT", err.Error(), details)
+			return
+		}
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"draft": draft})
+}
+
+// DeletePromptDraft 丢弃当前用户的草稿。
+func (h *PromptHandler) DeletePromptDraft(ctx *gin.Context) {
+	promptID := ctx.Param("id")
+	if err := h.service.DeleteDraft(ctx, promptID, ctx.GetString(middleware.UserContextKey)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"prompt_id": promptID})
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type executionRecordRequest struct {
+	PromptID         string          `json:"prompt_id"`
+	VersionID        string          `json:"version_id"`
+	Status           string          `json:"status"`
+	DurationMs       int64           `json:"duration_ms"`
+	RequestPayload   json.RawMessage `json:"request_payload"`
+	ResponseMetadata json.RawMessage `json:"response_metadata"`
+	ErrorClass       string          `json:"error_class"`
+	ErrorCode        string          `json:"error_code"`
+	ExecutedAt       *time.Time      `json:"executed_at"`
+}
+
+type executionBatchRequest struct {
+	Records []executionRecordRequest `json:"records" binding:"required"`
+}
+
+// RecordExecutionBatch 批量写入外部上报的执行日志，逐条返回写入结果。
+func (h *PromptHandler) RecordExecutionBatch(ctx *gin.Context) {
+	var req executionBatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	inputs := make([]promptsvc.ExecutionRecordInput, 0, len(req.Records))
+	for _, record := range req.Records {
+		inputs = append(inputs, promptsvc.ExecutionRecordInput{
+			PromptID:         record.PromptID,
+			VersionID:        record.VersionID,
+			Status:           record.Status,
+			DurationMs:       record.DurationMs,
+			RequestPayload:   record.RequestPayload,
+			ResponseMetadata: record.ResponseMetadata,
+			ErrorClass:       record.ErrorClass,
+			ErrorCode:        record.ErrorCode,
+			ExecutedAt:       record.ExecutedAt,
+		})
+	}
+
+	report, err := h.service.RecordExecutions(ctx, inputs, ctx.GetString(middleware.UserContextKey))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, report)
+}
+
+type replayExecutionRequest struct {
+	Invoke      bool   `json:"invoke"`
+	Model       string `json:"model"`
+	Granularity string `json:"granularity"`
+	Unified     bool   `json:"unified"`
+}
+
+// ReplayExecution 使用执行日志记录的变量重新渲染原版本，invoke 为 true 时重新调用网关，返回与原执行的差异。
+func (h *PromptHandler) ReplayExecution(ctx *gin.Context) {
+	var req replayExecutionRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+
+	replayedBy := ctx.GetString(middleware.UserEmailContextKey)
+	if replayedBy == "" {
+		replayedBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	replay, err := h.service.ReplayExecution(ctx, promptsvc.ReplayExecutionInput{
+		ExecutionID: ctx.Param("id"),
+		Invoke:      req.Invoke,
+		Model:       req.Model,
+		Granularity: req.Granularity,
+		Unified:     req.Unified,
+		ReplayedBy:  replayedBy,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"replay": replay})
+}
@@ -0,0 +1,241 @@
+package http
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+const csvFlushEvery = 100
+
+var executionLogCSVHeader = []string{"id", "prompt_id", "prompt_version_id", "user_id", "status", "duration_ms", "created_at", "error_class", "error_code"}
+
+// responseFormat 解析 ?format= 参数，json 为默认格式，其余仅接受 allowed 中列出的格式。
+func responseFormat(ctx *gin.Context, allowed ...string) (string, bool) {
+	format := strings.ToLower(strings.TrimSpace(ctx.Query("format")))
+	if format == "" || format == "json" {
+		return "json", true
+	}
+	for _, candidate := range allowed {
+		if format == candidate {
+			return format, true
+		}
+	}
+	httpx.RespondError(ctx, http.StatusBadRequest, "UNSUPPORTED_FORMAT", fmt.Sprintf("unsupported format %q", format), gin.H{"supported": append([]string{"json"}, allowed...)})
+	return "", false
+}
+
+// startCSV 写入 CSV 响应头并返回 writer，文件名通过 Content-Disposition 提示下载。
+func startCSV(ctx *gin.Context, filename string, header []string) *csv.Writer {
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	_ = writer.Write(header)
+	return writer
+}
+
+// ndjsonStream 以 NDJSON（每行一个 JSON 对象）流式输出集合，逐条编码并定期刷新，
+// 内存占用与结果集大小无关。响应头在写出第一行（或 finish）时才发送，
+// 因此迭代开始前的错误仍可返回结构化错误。
+type ndjsonStream struct {
+	ctx      *gin.Context
+	filename string
+	encoder  *json.Encoder
+	count    int
+}
+
+// newNDJSONStream 创建 NDJSON 输出，filename 非空时通过 Content-Disposition 提示下载。
+func newNDJSONStream(ctx *gin.Context, filename string) *ndjsonStream {
+	return &ndjsonStream{ctx: ctx, filename: filename}
+}
+
+func (s *ndjsonStream) start() {
+	s.ctx.Header("Content-Type", "application/x-ndjson")
+	if s.filename != "" {
+		s.ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", s.filename))
+	}
+	s.ctx.Header("Cache-Control", "no-store")
+	s.ctx.Status(http.StatusOK)
+	s.encoder = json.NewEncoder(s.ctx.Writer)
+}
+
+// Encode 写出一行。
+func (s *ndjsonStream) Encode(v interface{}) error {
+	if s.encoder == nil {
+		s.start()
+	}
+	if err := s.encoder.Encode(v); err != nil {
+		return err
+	}
+	s.count++
+	if s.count%csvFlushEvery == 0 {
+		s.ctx.Writer.Flush()
+	}
+	return nil
+}
+
+// finish 结束输出：尚未写出任何行时由 handleError 返回结构化错误，否则只能中断连接并记录错误。
+func (s *ndjsonStream) finish(err error, handleError func(*gin.Context, error)) {
+	switch {
+	case err == nil:
+		if s.encoder == nil {
+			s.start()
+		}
+	case s.encoder == nil:
+		handleError(s.ctx, err)
+	default:
+		_ = s.ctx.Error(err)
+		s.ctx.Abort()
+	}
+}
+
+// ListExecutionLogs 列出最近的执行日志，?format=csv 或 ndjson 时流式输出最近 days 天的全部记录。
+func (h *PromptHandler) ListExecutionLogs(ctx *gin.Context) {
+	format, ok := responseFormat(ctx, "csv", "ndjson")
+	if !ok {
+		return
+	}
+	promptID := ctx.Param("id")
+
+	if format == "json" {
+		logs, err := h.service.ListExecutionLogs(ctx, promptID, parseQueryInt(ctx.Query("limit"), 20))
+		if err != nil {
+			h.handleError(ctx, err)
+			return
+		}
+		httpx.RespondOK(ctx, gin.H{"items": logs})
+		return
+	}
+
+	if _, err := h.service.GetPrompt(ctx, promptID); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	days := parseQueryInt(ctx.Query("days"), 7)
+	if format == "ndjson" {
+		stream := newNDJSONStream(ctx, "")
+		err := h.service.IterateExecutionLogs(ctx, promptID, days, func(log *domain.PromptExecutionLog) error {
+			return stream.Encode(log)
+		})
+		stream.finish(err, h.handleError)
+		return
+	}
+
+	writer := startCSV(ctx, fmt.Sprintf("prompt-%s-executions.csv", promptID), executionLogCSVHeader)
+	count := 0
+	err := h.service.IterateExecutionLogs(ctx, promptID, days, func(log *domain.PromptExecutionLog) error {
+		userID, errorClass, errorCode := "", "", ""
+		if log.UserID != nil {
+			userID = *log.UserID
+		}
+		if log.ErrorClass != nil {
+			errorClass = *log.ErrorClass
+		}
+		if log.ErrorCode != nil {
+			errorCode = *log.ErrorCode
+		}
+		if err := writer.Write([]string{
+			log.ID,
+			log.PromptID,
+			log.PromptVersionID,
+			userID,
+			log.Status,
+			strconv.FormatInt(log.DurationMs, 10),
+			log.CreatedAt.UTC().Format(time.RFC3339),
+			errorClass,
+			errorCode,
+		}); err != nil {
+			return err
+		}
+		count++
+		if count%csvFlushEvery == 0 {
+			writer.Flush()
+			ctx.Writer.Flush()
+			return writer.Error()
+		}
+		return nil
+	})
+	writer.Flush()
+	if err != nil {
+		// 响应头已发送，只能中断连接并记录错误。
+		_ = ctx.Error(err)
+		ctx.Abort()
+	}
+}
+
+func writeStatsCSV(ctx *gin.Context, promptID string, stats []*domain.PromptExecutionAggregate) {
+	writer := startCSV(ctx, fmt.Sprintf("prompt-%s-stats.csv", promptID), []string{"day", "total_calls", "success_calls", "average_ms", "p50_ms", "p90_ms", "p99_ms"})
+	for _, item := range stats {
+		_ = writer.Write([]string{
+			item.Bucket,
+			strconv.Itoa(item.TotalCalls),
+			strconv.Itoa(item.SuccessCalls),
+			strconv.FormatFloat(item.AverageMillis, 'f', 2, 64),
+			strconv.FormatFloat(item.P50Millis, 'f', 2, 64),
+			strconv.FormatFloat(item.P90Millis, 'f', 2, 64),
+			strconv.FormatFloat(item.P99Millis, 'f', 2, 64),
+		})
+	}
+	writer.Flush()
+}
+
+// ExportWorkspace 以 zip 流式导出当前工作区的全部 Prompt 与版本，可按名称通配过滤并附带执行统计。
+func (h *PromptHandler) ExportWorkspace(ctx *gin.Context) {
+	opts := promptsvc.ExportArchiveOptions{
+		Include:   splitQueryList(ctx.Query("include")),
+		Exclude:   splitQueryList(ctx.Query("exclude")),
+		StatsDays: parseQueryInt(ctx.Query("stats_days"), 0),
+	}
+	if raw := strings.TrimSpace(ctx.Query("stats")); raw != "" {
+		stats, err := strconv.ParseBool(raw)
+		if err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_QUERY", "stats must be a boolean", nil)
+			return
+		}
+		opts.Stats = stats
+	}
+
+	filename := fmt.Sprintf("prompt-manager-export-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Header("Cache-Control", "no-store")
+
+	zw := zip.NewWriter(ctx.Writer)
+	if _, err := h.service.ExportArchive(ctx, zw, opts); err != nil {
+		// 尚未写出内容时仍可返回结构化错误；否则只能中断连接，客户端会得到不完整的压缩包。
+		if !ctx.Writer.Written() {
+			ctx.Writer.Header().Del("Content-Disposition")
+			h.handleError(ctx, err)
+			return
+		}
+		_ = ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+	_ = zw.Close()
+}
+
+// splitQueryList 解析逗号分隔的查询参数，忽略空项。
+func splitQueryList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,24 +1,47 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
 	"github.com/zacharykka/prompt-manager/internal/middleware"
 	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
 	"github.com/zacharykka/prompt-manager/pkg/httpx"
+	"github.com/zacharykka/prompt-manager/pkg/render"
 )
 
+const defaultUploadLimit int64 = 3 * 1024 * 1024
+
 // PromptHandler 处理 Prompt 相关 HTTP 请求。
 type PromptHandler struct {
-	service *promptsvc.Service
+	service     PromptService
+	uploadLimit int64
+}
+
+// PromptHandlerOption 定义 PromptHandler 可选项。
+type PromptHandlerOption func(*PromptHandler)
+
+// WithUploadLimit 设置版本文件上传允许的最大字节数，通常与 server.bodyLimits.prompts 保持一致。
+func WithUploadLimit(limit int64) PromptHandlerOption {
+	return func(h *PromptHandler) {
+		if limit > 0 {
+			h.uploadLimit = limit
+		}
+	}
 }
 
 // NewPromptHandler 创建 PromptHandler。
-func NewPromptHandler(service *promptsvc.Service) *PromptHandler {
-	return &PromptHandler{service: service}
+func NewPromptHandler(service PromptService, opts ...PromptHandlerOption) *PromptHandler {
+	handler := &PromptHandler{service: service, uploadLimit: defaultUploadLimit}
+	for _, opt := range opts {
+		opt(handler)
+	}
+	return handler
 }
 
 // RegisterRoutes 注册 Prompt 相关路由。
@@ -27,16 +50,53 @@ func (h *PromptHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.POST("/", h.CreatePrompt)
 	rg.GET("", h.ListPrompts)
 	rg.GET("/", h.ListPrompts)
+	rg.GET("/locales/coverage", h.GetLocaleCoverage)
 	rg.GET("/:id", h.GetPrompt)
 	rg.PUT("/:id", h.UpdatePrompt)
 	rg.PATCH("/:id", h.UpdatePrompt)
 	rg.POST("/:id/versions", h.CreatePromptVersion)
+	rg.POST("/:id/versions/upload", h.UploadPromptVersion)
+	rg.POST("/:id/versions/validate", h.ValidatePromptVersion)
 	rg.GET("/:id/versions", h.ListPromptVersions)
+	rg.GET("/:id/versions/compare", h.CompareVersions)
+	rg.GET("/:id/blame", h.BlamePrompt)
+	rg.GET("/:id/activations", h.ListActivations)
+	rg.GET("/:id/draft", h.GetPromptDraft)
+	rg.GET("/:id/review-policy", h.GetReviewPolicy)
+	rg.PUT("/:id/review-policy", h.SetReviewPolicy)
+	rg.DELETE("/:id/review-policy", h.DeleteReviewPolicy)
+	rg.GET("/:id/versions/:versionId/review", h.GetVersionReview)
+	rg.POST("/:id/versions/:versionId/approve", h.ApproveVersion)
+	rg.DELETE("/:id/versions/:versionId/approve", h.RevokeVersionApproval)
+	rg.PUT("/:id/draft", h.SavePromptDraft)
+	rg.DELETE("/:id/draft", h.DeletePromptDraft)
+	rg.POST("/:id/lock", h.AcquireEditLock)
+	rg.DELETE("/:id/lock", h.ReleaseEditLock)
 	rg.GET("/:id/versions/:versionId/diff", h.DiffPromptVersion)
+	rg.GET("/:id/versions/:versionId/preview", h.PreviewPromptVersion)
+	rg.POST("/:id/render", h.RenderPrompt)
+	rg.POST("/:id/versions/:versionId/diff/rendered", h.DiffRenderedVersions)
 	rg.POST("/:id/versions/:versionId/activate", h.SetActiveVersion)
+	rg.GET("/:id/versions/:versionId/locales", h.ListVersionLocales)
+	rg.PUT("/:id/versions/:versionId/locales/:locale", h.SetVersionLocale)
+	rg.DELETE("/:id/versions/:versionId/locales/:locale", h.DeleteVersionLocale)
 	rg.GET("/:id/stats", h.GetPromptStats)
+	rg.GET("/:id/executions", h.ListExecutionLogs)
+	rg.GET("/:id/alerts", h.ListAlertRules)
+	rg.POST("/:id/alerts", h.CreateAlertRule)
+	rg.DELETE("/:id/alerts/:alertId", h.DeleteAlertRule)
+	rg.POST("/:id/alerts/:alertId/webhook-secret/rotate", h.RotateAlertWebhookSecret)
+	rg.POST("/:id/alerts/:alertId/test", h.TestAlertWebhook)
+	rg.GET("/:id/dependencies", h.ListPromptDependencies)
+	rg.PUT("/:id/dependencies", h.SetPromptDependencies)
+	rg.GET("/:id/dependents", h.ListPromptDependents)
 	rg.DELETE("/:id", h.DeletePrompt)
 	rg.POST("/:id/restore", h.RestorePrompt)
+	rg.POST("/:id/archive", h.ArchivePrompt)
+	rg.POST("/:id/owner", h.TransferPromptOwner)
+	rg.POST("/:id/unarchive", h.UnarchivePrompt)
+	rg.POST("/import", h.ImportPrompts)
+	rg.POST("/import/archive", h.ImportPromptArchive)
 }
 
 type createPromptRequest struct {
@@ -44,12 +104,15 @@ type createPromptRequest struct {
 	Description *string  `json:"description"`
 	Tags        []string `json:"tags" binding:"max=10"`
 	Body        string   `json:"body" binding:"omitempty,min=1"`
+	// Owner 为可选负责人，type 为 user 时 id 可为用户 ID 或邮箱。
+	Owner *promptOwnerRequest `json:"owner"`
 }
 
 type updatePromptRequest struct {
 	Name        *string   `json:"name" binding:"omitempty,min=1,max=128"`
 	Description *string   `json:"description"`
 	Tags        *[]string `json:"tags" binding:"max=10"`
+	RenderMode  *string   `json:"render_mode"`
 }
 
 type createPromptVersionRequest struct {
@@ -58,6 +121,7 @@ type createPromptVersionRequest struct {
 	Metadata        interface{} `json:"metadata"`
 	Status          string      `json:"status" binding:"omitempty,oneof=draft published archived"`
 	Activate        bool        `json:"activate"`
+	AllowDuplicate  bool        `json:"allow_duplicate"`
 }
 
 // CreatePrompt 处理创建 Prompt 请求。
@@ -73,12 +137,29 @@ func (h *PromptHandler) CreatePrompt(ctx *gin.Context) {
 		createdBy = ctx.GetString(middleware.UserContextKey)
 	}
 
-	prompt, err := h.service.CreatePrompt(ctx, promptsvc.CreatePromptInput{
+	createInput := promptsvc.CreatePromptInput{
 		Name:        req.Name,
 		Description: req.Description,
 		Tags:        req.Tags,
 		CreatedBy:   createdBy,
-	})
+		Owner:       req.Owner.toDomain(),
+	}
+
+	if template := strings.TrimSpace(ctx.Query("template")); template != "" {
+		prompt, err := h.service.CreatePromptFromTemplate(ctx, promptsvc.CreatePromptFromTemplateInput{
+			CreatePromptInput: createInput,
+			Template:          template,
+			Body:              req.Body,
+		})
+		if err != nil {
+			h.handleError(ctx, err)
+			return
+		}
+		httpx.RespondOK(ctx, gin.H{"prompt": prompt})
+		return
+	}
+
+	prompt, err := h.service.CreatePrompt(ctx, createInput)
 	if err != nil {
 		h.handleError(ctx, err)
 		return
@@ -114,7 +195,7 @@ func (h *PromptHandler) UpdatePrompt(ctx *gin.Context) {
 		return
 	}
 
-	if req.Name == nil && req.Description == nil && req.Tags == nil {
+	if req.Name == nil && req.Description == nil && req.Tags == nil && req.RenderMode == nil {
 		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", "至少需要提供一个需要更新的字段", nil)
 		return
 	}
@@ -124,6 +205,7 @@ func (h *PromptHandler) UpdatePrompt(ctx *gin.Context) {
 		Name:        req.Name,
 		Description: req.Description,
 		Tags:        req.Tags,
+		RenderMode:  req.RenderMode,
 	})
 	if err != nil {
 		h.handleError(ctx, err)
@@ -133,8 +215,12 @@ func (h *PromptHandler) UpdatePrompt(ctx *gin.Context) {
 	httpx.RespondOK(ctx, gin.H{"prompt": updated})
 }
 
-// ListPrompts 列出 Prompt。
+// ListPrompts 列出 Prompt，默认不读取正文列；?fields= 可只返回部分字段。
 func (h *PromptHandler) ListPrompts(ctx *gin.Context) {
+	fields, ok := parseFieldSet(ctx, promptFieldNames)
+	if !ok {
+		return
+	}
 	limit, offset := parsePagination(ctx.Query("limit"), ctx.Query("offset"))
 	search := strings.TrimSpace(ctx.Query("search"))
 
@@ -144,38 +230,92 @@ func (h *PromptHandler) ListPrompts(ctx *gin.Context) {
 			includeDeleted = parsed
 		}
 	}
+	// 默认隐藏已归档的 Prompt；archived=true 只看归档，includeArchived=true 一并返回。
+	includeArchived, _ := strconv.ParseBool(strings.TrimSpace(ctx.Query("includeArchived")))
+	archivedOnly, _ := strconv.ParseBool(strings.TrimSpace(ctx.Query("archived")))
+	// 正文可达数十 KB，列表默认不返回，需要时通过 includeBody=true 或 fields 包含 body 获取，详情接口始终返回。
+	includeBody, _ := strconv.ParseBool(strings.TrimSpace(ctx.Query("includeBody")))
+
+	// createdBy=me 表示当前登录用户，便于前端实现“我的 Prompt”。
+	createdBy := strings.TrimSpace(ctx.Query("createdBy"))
+	if createdBy == "me" {
+		createdBy = ctx.GetString(middleware.UserContextKey)
+	}
 
-	prompts, total, err := h.service.ListPrompts(ctx, promptsvc.ListPromptsOptions{
-		Limit:          limit,
-		Offset:         offset,
-		Search:         search,
-		IncludeDeleted: includeDeleted,
+	// count=none 跳过 COUNT 只返回 hasMore，count=estimated 使用数据库统计估计总数。
+	page, err := h.service.ListPromptsPage(ctx, promptsvc.ListPromptsOptions{
+		Limit:           limit,
+		Offset:          offset,
+		Search:          search,
+		IncludeDeleted:  includeDeleted,
+		IncludeArchived: includeArchived,
+		ArchivedOnly:    archivedOnly,
+		CreatedBy:       createdBy,
+		CountMode:       strings.ToLower(strings.TrimSpace(ctx.Query("count"))),
+		OmitBody:        !includeBody && !fields["body"],
+		Model:           ctx.Query("model"),
 	})
 	if err != nil {
+		if errors.Is(err, promptsvc.ErrInvalidCountMode) {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_COUNT_MODE", err.Error(), nil)
+			return
+		}
+		if errors.Is(err, promptsvc.ErrUnknownModel) {
+			httpx.RespondError(ctx, http.StatusBadRequest, "UNKNOWN_MODEL", err.Error(), nil)
+			return
+		}
 		httpx.RespondError(ctx, http.StatusInternalServerError, "LIST_FAILED", err.Error(), nil)
 		return
 	}
 
+	meta := gin.H{
+		"limit":   limit,
+		"offset":  offset,
+		"hasMore": page.HasMore,
+	}
+	if page.Total != nil {
+		meta["total"] = *page.Total
+	}
+	if page.TotalEstimated {
+		meta["totalEstimated"] = true
+	}
+	items, err := applyEach(fields, page.Items)
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusInternalServerError, "LIST_FAILED", err.Error(), nil)
+		return
+	}
 	httpx.RespondOK(ctx, gin.H{
-		"items": prompts,
-		"meta": gin.H{
-			"total":   total,
-			"limit":   limit,
-			"offset":  offset,
-			"hasMore": int64(offset)+int64(len(prompts)) < total,
-		},
+		"items": items,
+		"meta":  meta,
 	})
 }
 
-// GetPrompt 获取指定 Prompt。
+// GetPrompt 获取指定 Prompt，?fields= 可只返回部分字段。
 func (h *PromptHandler) GetPrompt(ctx *gin.Context) {
-	prompt, err := h.service.GetPrompt(ctx, ctx.Param("id"))
+	fields, ok := parseFieldSet(ctx, promptFieldNames)
+	if !ok {
+		return
+	}
+	prompt, resolvedLocale, err := h.service.GetPromptWithLocale(ctx, ctx.Param("id"), ctx.Query("locale"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	selected, err := fields.apply(prompt)
 	if err != nil {
 		h.handleError(ctx, err)
 		return
 	}
 
-	httpx.RespondOK(ctx, gin.H{"prompt": prompt})
+	response := gin.H{"prompt": selected}
+	if ctx.Query("locale") != "" {
+		response["locale"] = resolvedLocale
+	}
+	// 编辑锁仅作提示，读取失败不影响 Prompt 详情。
+	if lock, err := h.service.GetEditLock(ctx, prompt.ID); err == nil && lock != nil {
+		response["editing_lock"] = lock
+	}
+	httpx.RespondOK(ctx, response)
 }
 
 // CreatePromptVersion 创建新的 Prompt 版本。
@@ -199,6 +339,7 @@ func (h *PromptHandler) CreatePromptVersion(ctx *gin.Context) {
 		Status:          req.Status,
 		CreatedBy:       createdBy,
 		Activate:        req.Activate,
+		AllowDuplicate:  req.AllowDuplicate,
 	})
 	if err != nil {
 		h.handleError(ctx, err)
@@ -208,19 +349,46 @@ func (h *PromptHandler) CreatePromptVersion(ctx *gin.Context) {
 	httpx.RespondOK(ctx, gin.H{"version": version})
 }
 
-// ListPromptVersions 列出 Prompt 的版本。
+// ListPromptVersions 列出 Prompt 的版本，?format=ndjson 时流式输出全部版本，?fields= 可只返回部分字段。
 func (h *PromptHandler) ListPromptVersions(ctx *gin.Context) {
+    format, ok := responseFormat(ctx, "ndjson")
+    if !ok {
+        return
+    }
+    fields, ok := parseFieldSet(ctx, versionFieldNames)
+    if !ok {
+        return
+    }
     limit, offset := parsePagination(ctx.Query("limit"), ctx.Query("offset"))
     status := strings.TrimSpace(ctx.Query("status"))
 
+    if format == "ndjson" {
+        // NDJSON 忽略分页参数，按版本号倒序逐行输出全部版本。
+        stream := newNDJSONStream(ctx, "")
+        err := h.service.IterateVersions(ctx, ctx.Param("id"), status, func(version *domain.PromptVersion) error {
+            selected, err := fields.apply(version)
+            if err != nil {
+                return err
+            }
+            return stream.Encode(selected)
+        })
+        stream.finish(err, h.handleError)
+        return
+    }
+
     page, err := h.service.ListPromptVersionsEx(ctx, ctx.Param("id"), limit, offset, status)
     if err != nil {
         h.handleError(ctx, err)
         return
     }
 
+    items, err := applyEach(fields, page.Items)
+    if err != nil {
+        h.handleError(ctx, err)
+        return
+    }
     httpx.RespondOK(ctx, gin.H{
-        "items": page.Items,
+        "items": items,
         "meta": gin.H{
             "limit":   page.Limit,
             "offset":  page.Offset,
@@ -231,12 +399,40 @@ func (h *PromptHandler) ListPromptVersions(ctx *gin.Context) {
     })
 }
 
+// CompareVersions 返回 ?ids=a,b,c 指定版本的关键属性对比矩阵。
+func (h *PromptHandler) CompareVersions(ctx *gin.Context) {
+	comparison, err := h.service.CompareVersions(ctx, ctx.Param("id"), splitQueryList(ctx.Query("ids")))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, comparison)
+}
+
+// BlamePrompt 返回激活版本（或 versionId 指定版本）正文的逐行溯源。
+func (h *PromptHandler) BlamePrompt(ctx *gin.Context) {
+	blame, err := h.service.BlamePrompt(ctx, ctx.Param("id"), strings.TrimSpace(ctx.Query("versionId")))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"blame": blame})
+}
+
 // DiffPromptVersion 对比指定 Prompt 版本与目标版本差异。
 func (h *PromptHandler) DiffPromptVersion(ctx *gin.Context) {
 	compareTo := strings.TrimSpace(strings.ToLower(ctx.Query("compareTo")))
 	targetID := strings.TrimSpace(ctx.Query("targetVersionId"))
+	format := strings.TrimSpace(strings.ToLower(ctx.Query("format")))
+	if format != "" && format != "json" && format != "unified" {
+		httpx.RespondError(ctx, http.StatusBadRequest, "UNSUPPORTED_FORMAT", fmt.Sprintf("unsupported format %q", format), nil)
+		return
+	}
 
-	options := promptsvc.DiffPromptVersionOptions{}
+	options := promptsvc.DiffPromptVersionOptions{
+		Granularity: ctx.Query("granularity"),
+		Unified:     format == "unified" || ctx.Query("unified") == "true",
+	}
 	if targetID != "" {
 		options.TargetVersionID = &targetID
 	} else if compareTo == "active" {
@@ -251,10 +447,29 @@ func (h *PromptHandler) DiffPromptVersion(ctx *gin.Context) {
 		return
 	}
 
+	if format == "unified" {
+		ctx.Data(http.StatusOK, "text/x-diff; charset=utf-8", []byte(diff.Unified))
+		return
+	}
 	httpx.RespondOK(ctx, gin.H{"diff": diff})
 }
 
-// SetActiveVersion 设定当前使用的版本。
+// PreviewPromptVersion 渲染指定版本正文，format=html 时输出经过净化的 HTML。
+func (h *PromptHandler) PreviewPromptVersion(ctx *gin.Context) {
+	preview, err := h.service.PreviewPromptVersion(ctx, ctx.Param("id"), ctx.Param("versionId"), ctx.Query("format"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"preview": preview})
+}
+
+type activateVersionRequest struct {
+	ReleaseNote string `json:"release_note"`
+}
+
+// SetActiveVersion 设定当前使用的版本，可附带发布说明。
 func (h *PromptHandler) SetActiveVersion(ctx *gin.Context) {
 	promptID := ctx.Param("id")
 	versionID := ctx.Param("versionId")
@@ -263,24 +478,123 @@ func (h *PromptHandler) SetActiveVersion(ctx *gin.Context) {
 		activatedBy = ctx.GetString(middleware.UserContextKey)
 	}
 
-	if err := h.service.SetActiveVersion(ctx, promptID, versionID, activatedBy); err != nil {
+	var req activateVersionRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+
+	result, err := h.service.ActivateVersion(ctx, promptsvc.ActivateVersionInput{
+		PromptID:    promptID,
+		VersionID:   versionID,
+		ActivatedBy: activatedBy,
+		ReleaseNote: req.ReleaseNote,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	response := gin.H{"prompt_id": promptID, "active_version_id": versionID}
+	if result.Event.ReleaseNote != "" {
+		response["release_note"] = result.Event.ReleaseNote
+	}
+	warnings := h.dependentWarnings(ctx, promptID)
+	if result.NotifyErr != nil {
+		warnings = append(warnings, fmt.Sprintf("activation notification failed: %v", result.NotifyErr))
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	httpx.RespondOK(ctx, response)
+}
+
+// ActivatePreviousVersion 一键回滚到上一次切换前的激活版本，可附带发布说明。
+func (h *PromptHandler) ActivatePreviousVersion(ctx *gin.Context) {
+	promptID := ctx.Param("id")
+	activatedBy := ctx.GetString(middleware.UserEmailContextKey)
+	if activatedBy == "" {
+		activatedBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	var req activateVersionRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+
+	result, err := h.service.ActivatePreviousVersion(ctx, promptsvc.ActivateVersionInput{
+		PromptID:    promptID,
+		ActivatedBy: activatedBy,
+		ReleaseNote: req.ReleaseNote,
+	})
+	if err != nil {
 		h.handleError(ctx, err)
 		return
 	}
 
-	httpx.RespondOK(ctx, gin.H{"prompt_id": promptID, "active_version_id": versionID})
+	response := gin.H{"prompt_id": promptID, "active_version_id": result.Event.VersionID}
+	if result.Event.PreviousVersionID != nil {
+		response["previous_version_id"] = *result.Event.PreviousVersionID
+	}
+	if result.Event.ReleaseNote != "" {
+		response["release_note"] = result.Event.ReleaseNote
+	}
+	warnings := h.dependentWarnings(ctx, promptID)
+	if result.NotifyErr != nil {
+		warnings = append(warnings, fmt.Sprintf("activation notification failed: %v", result.NotifyErr))
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	httpx.RespondOK(ctx, response)
+}
+
+// ListActivations 返回 Prompt 的激活历史（含发布说明），按时间倒序。
+func (h *PromptHandler) ListActivations(ctx *gin.Context) {
+	activations, err := h.service.ListActivations(ctx, ctx.Param("id"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": activations})
 }
 
-// GetPromptStats 返回执行统计数据。
+// GetPromptStats 返回执行统计数据，支持 granularity、from/to 与 tz，?format=csv 时以 CSV 下载。
 func (h *PromptHandler) GetPromptStats(ctx *gin.Context) {
-	days := parseQueryInt(ctx.Query("days"), 7)
+	format, ok := responseFormat(ctx, "csv")
+	if !ok {
+		return
+	}
+	from, ok := parseQueryTime(ctx, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseQueryTime(ctx, "to")
+	if !ok {
+		return
+	}
 
-	stats, err := h.service.GetExecutionStats(ctx, ctx.Param("id"), days)
+	stats, err := h.service.GetExecutionStats(ctx, ctx.Param("id"), promptsvc.ExecutionStatsOptions{
+		Days:        parseQueryInt(ctx.Query("days"), 7),
+		From:        from,
+		To:          to,
+		Granularity: ctx.Query("granularity"),
+		Timezone:    ctx.Query("tz"),
+	})
 	if err != nil {
 		h.handleError(ctx, err)
 		return
 	}
 
+	if format == "csv" {
+		writeStatsCSV(ctx, ctx.Param("id"), stats)
+		return
+	}
 	httpx.RespondOK(ctx, gin.H{"items": stats})
 }
 
@@ -290,12 +604,17 @@ func (h *PromptHandler) DeletePrompt(ctx *gin.Context) {
 	if deletedBy == "" {
 		deletedBy = ctx.GetString(middleware.UserContextKey)
 	}
+	warnings := h.dependentWarnings(ctx, ctx.Param("id"))
 	if err := h.service.DeletePrompt(ctx, ctx.Param("id"), deletedBy); err != nil {
 		h.handleError(ctx, err)
 		return
 	}
 
-	httpx.RespondOK(ctx, gin.H{"prompt_id": ctx.Param("id")})
+	response := gin.H{"prompt_id": ctx.Param("id")}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	httpx.RespondOK(ctx, response)
 }
 
 // RestorePrompt 恢复软删除的 Prompt。
@@ -315,6 +634,205 @@ func (h *PromptHandler) RestorePrompt(ctx *gin.Context) {
 }
 
 func (h *PromptHandler) handleError(ctx *gin.Context, err error) {
+	var missing *render.MissingVariablesError
+	if errors.As(err, &missing) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "MISSING_VARIABLES", err.Error(), gin.H{"missing": missing.Keys})
+		return
+	}
+	if errors.Is(err, promptsvc.ErrRenderFailed) {
+		httpx.RespondError(ctx, http.StatusUnprocessableEntity, "RENDER_FAILED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidTargetModels) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_TARGET_MODELS", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidArchive) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_ARCHIVE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidCSV) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_CSV", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrExecutionNotFound) {
+		httpx.RespondError(ctx, http.StatusNotFound, "EXECUTION_NOT_FOUND", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrExecutionNotReplayable) {
+		httpx.RespondError(ctx, http.StatusUnprocessableEntity, "EXECUTION_NOT_REPLAYABLE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrGatewayUnavailable) {
+		httpx.RespondError(ctx, http.StatusServiceUnavailable, "GATEWAY_UNAVAILABLE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrGatewayFailed) {
+		httpx.RespondError(ctx, http.StatusBadGateway, "GATEWAY_FAILED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidExecutionBatch) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_EXECUTION_BATCH", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidTemplate) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_TEMPLATE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidComparison) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_COMPARISON", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrReleaseNoteRequired) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "RELEASE_NOTE_REQUIRED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidReleaseNote) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_RELEASE_NOTE", "release note must be at most 2000 characters", nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrDraftNotFound) {
+		httpx.RespondError(ctx, http.StatusNotFound, "DRAFT_NOT_FOUND", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrDraftConflict) {
+		httpx.RespondError(ctx, http.StatusConflict, "DRAFT_CONFLICT", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidDraft) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_DRAFT", "draft requires an authenticated user", nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrEditLocksDisabled) {
+		httpx.RespondError(ctx, http.StatusServiceUnavailable, "EDIT_LOCKS_UNAVAILABLE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrEditLockNotHeld) {
+		httpx.RespondError(ctx, http.StatusConflict, "EDIT_LOCK_NOT_HELD", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidEditLock) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_EDIT_LOCK", "edit lock requires an authenticated user", nil)
+		return
+	}
+	var duplicate *promptsvc.DuplicateVersionError
+	if errors.As(err, &duplicate) {
+		httpx.RespondError(ctx, http.StatusConflict, "DUPLICATE_VERSION", err.Error(), gin.H{
+			"version_id":     duplicate.Version.ID,
+			"version_number": duplicate.Version.VersionNumber,
+		})
+		return
+	}
+	var tooLarge *promptsvc.BodyTooLargeError
+	if errors.As(err, &tooLarge) {
+		httpx.RespondError(ctx, http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE", err.Error(), gin.H{"size": tooLarge.Size, "limit": tooLarge.Limit})
+		return
+	}
+	var notPublishable *promptsvc.VersionNotPublishableError
+	if errors.As(err, &notPublishable) {
+		details := gin.H{"status": notPublishable.Status, "required_status": domain.PromptVersionStatusPublished}
+		if notPublishable.Review != nil {
+			details["required_approvals"] = notPublishable.Review.RequiredApprovals
+			details["valid_approvals"] = notPublishable.Review.ValidApprovals
+		}
+		httpx.RespondError(ctx, http.StatusConflict, "VERSION_NOT_PUBLISHABLE", err.Error(), details)
+		return
+	}
+	var frozen *promptsvc.ChangeFreezeError
+	if errors.As(err, &frozen) {
+		httpx.RespondError(ctx, http.StatusLocked, "CHANGE_FREEZE", err.Error(), gin.H{
+			"window":    frozen.Window,
+			"until":     frozen.Until,
+			"operation": frozen.Operation,
+		})
+		return
+	}
+	var transition *promptsvc.StatusTransitionError
+	if errors.As(err, &transition) {
+		httpx.RespondError(ctx, http.StatusConflict, "INVALID_STATUS_TRANSITION", err.Error(), gin.H{
+			"from":    transition.From,
+			"to":      transition.To,
+			"allowed": promptsvc.AllowedVersionStatusTransitions(transition.From),
+		})
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidVersionStatus) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_VERSION_STATUS", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrVersionArchived) {
+		httpx.RespondError(ctx, http.StatusConflict, "VERSION_ARCHIVED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidCanary) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_CANARY", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrCanaryRunning) {
+		httpx.RespondError(ctx, http.StatusConflict, "CANARY_RUNNING", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrCanaryNotFound) {
+		httpx.RespondError(ctx, http.StatusNotFound, "CANARY_NOT_FOUND", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrNoPreviousVersion) {
+		httpx.RespondError(ctx, http.StatusConflict, "NO_PREVIOUS_VERSION", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrVersionActive) {
+		httpx.RespondError(ctx, http.StatusConflict, "VERSION_ACTIVE", err.Error(), nil)
+		return
+	}
+	var reviewRequired *promptsvc.ReviewRequiredError
+	if errors.As(err, &reviewRequired) {
+		httpx.RespondError(ctx, http.StatusConflict, "REVIEW_REQUIRED", err.Error(), gin.H{
+			"required_approvals": reviewRequired.Review.RequiredApprovals,
+			"valid_approvals":    reviewRequired.Review.ValidApprovals,
+		})
+		return
+	}
+	if errors.Is(err, promptsvc.ErrReviewPolicyNotFound) {
+		httpx.RespondError(ctx, http.StatusNotFound, "REVIEW_POLICY_NOT_FOUND", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidReviewPolicy) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_REVIEW_POLICY", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrNotPromptOwner) {
+		httpx.RespondError(ctx, http.StatusForbidden, "NOT_PROMPT_OWNER", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrSelfApproval) {
+		httpx.RespondError(ctx, http.StatusForbidden, "SELF_APPROVAL", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrNotEligibleReviewer) {
+		httpx.RespondError(ctx, http.StatusForbidden, "NOT_ELIGIBLE_REVIEWER", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrApprovalNotFound) {
+		httpx.RespondError(ctx, http.StatusNotFound, "APPROVAL_NOT_FOUND", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidReview) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_REVIEW", "review requires an authenticated user", nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidDiffGranularity) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_GRANULARITY", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrInvalidExportFilter) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_FILTER", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, promptsvc.ErrAlertWebhookFailed) {
+		httpx.RespondError(ctx, http.StatusBadGateway, "ALERT_WEBHOOK_FAILED", err.Error(), nil)
+		return
+	}
+
 	switch err {
 	case promptsvc.ErrNameRequired, promptsvc.ErrBodyRequired:
 		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
@@ -322,12 +840,48 @@ func (h *PromptHandler) handleError(ctx *gin.Context, err error) {
 		httpx.RespondError(ctx, http.StatusConflict, "PROMPT_EXISTS", err.Error(), nil)
 	case promptsvc.ErrPromptNotDeleted:
 		httpx.RespondError(ctx, http.StatusBadRequest, "PROMPT_NOT_DELETED", err.Error(), nil)
+	case promptsvc.ErrPromptArchived:
+		httpx.RespondError(ctx, http.StatusConflict, "PROMPT_ARCHIVED", err.Error(), nil)
+	case promptsvc.ErrPromptNotArchived:
+		httpx.RespondError(ctx, http.StatusBadRequest, "PROMPT_NOT_ARCHIVED", err.Error(), nil)
+	case promptsvc.ErrInvalidOwner:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_OWNER", "owner must be an existing user or a team identifier", nil)
 	case promptsvc.ErrPromptNotFound:
 		httpx.RespondError(ctx, http.StatusNotFound, "PROMPT_NOT_FOUND", err.Error(), nil)
 	case promptsvc.ErrVersionNotFound:
 		httpx.RespondError(ctx, http.StatusNotFound, "VERSION_NOT_FOUND", err.Error(), nil)
 	case promptsvc.ErrNoFieldsToUpdate:
 		httpx.RespondError(ctx, http.StatusBadRequest, "NO_FIELDS_TO_UPDATE", err.Error(), nil)
+	case promptsvc.ErrUnsupportedPreviewFormat:
+		httpx.RespondError(ctx, http.StatusBadRequest, "UNSUPPORTED_FORMAT", err.Error(), nil)
+	case promptsvc.ErrInvalidRenderMode:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_RENDER_MODE", err.Error(), nil)
+	case promptsvc.ErrInvalidLocale:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_LOCALE", err.Error(), nil)
+	case promptsvc.ErrLocaleNotFound:
+		httpx.RespondError(ctx, http.StatusNotFound, "LOCALE_NOT_FOUND", err.Error(), nil)
+	case promptsvc.ErrDependencyNotFound:
+		httpx.RespondError(ctx, http.StatusBadRequest, "DEPENDENCY_NOT_FOUND", err.Error(), nil)
+	case promptsvc.ErrDependencyCycle:
+		httpx.RespondError(ctx, http.StatusConflict, "DEPENDENCY_CYCLE", err.Error(), nil)
+	case promptsvc.ErrInvalidTimezone:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_TIMEZONE", err.Error(), nil)
+	case promptsvc.ErrInvalidGranularity:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_GRANULARITY", err.Error(), nil)
+	case promptsvc.ErrInvalidTimeRange:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_TIME_RANGE", err.Error(), nil)
+	case promptsvc.ErrInvalidAlertRule:
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_ALERT_RULE", err.Error(), nil)
+	case promptsvc.ErrAlertRuleNotFound:
+		httpx.RespondError(ctx, http.StatusNotFound, "ALERT_RULE_NOT_FOUND", err.Error(), nil)
+	case promptsvc.ErrAlertWebhookMissing:
+		httpx.RespondError(ctx, http.StatusConflict, "ALERT_WEBHOOK_MISSING", err.Error(), nil)
+	case promptsvc.ErrTemplateNotFound:
+		httpx.RespondError(ctx, http.StatusNotFound, "TEMPLATE_NOT_FOUND", err.Error(), nil)
+	case promptsvc.ErrTemplateAlreadyExists:
+		httpx.RespondError(ctx, http.StatusConflict, "TEMPLATE_EXISTS", err.Error(), nil)
+	case promptsvc.ErrAuditLogUnavailable:
+		httpx.RespondError(ctx, http.StatusServiceUnavailable, "AUDIT_UNAVAILABLE", err.Error(), nil)
 	default:
 		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
 	}
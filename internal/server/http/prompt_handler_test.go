@@ -2,19 +2,29 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
 	"github.com/zacharykka/prompt-manager/internal/infra/database"
 	"github.com/zacharykka/prompt-manager/internal/infra/repository"
 	"github.com/zacharykka/prompt-manager/internal/middleware"
+	"github.com/zacharykka/prompt-manager/internal/server/http/mocks"
 	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"go.uber.org/mock/gomock"
 )
 
 func setupPromptHandler(t *testing.T) (*PromptHandler, func()) {
@@ -25,29 +35,18 @@ func setupPromptHandler(t *testing.T) (*PromptHandler, func()) {
 		t.Fatalf("open sqlite: %v", err)
 	}
 
-	migrationPath := filepath.Join("..", "..", "..", "db", "migrations", "000001_init.up.sql")
-	migrationSQL, err := os.ReadFile(migrationPath)
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
 	if err != nil {
-		t.Fatalf("read migration: %v", err)
+		t.Fatalf("glob migrations: %v", err)
 	}
-	if _, err := db.Exec(string(migrationSQL)); err != nil {
-		t.Fatalf("exec migration: %v", err)
-	}
-	migration2Path := filepath.Join("..", "..", "..", "db", "migrations", "000002_add_prompt_body.up.sql")
-	migration2SQL, err := os.ReadFile(migration2Path)
-	if err != nil {
-		t.Fatalf("read migration 2: %v", err)
-	}
-	if _, err := db.Exec(string(migration2SQL)); err != nil {
-		t.Fatalf("exec migration 2: %v", err)
-	}
-	migration3Path := filepath.Join("..", "..", "..", "db", "migrations", "000003_prompt_soft_delete.up.sql")
-	migration3SQL, err := os.ReadFile(migration3Path)
-	if err != nil {
-		t.Fatalf("read migration 3: %v", err)
-	}
-	if _, err := db.Exec(string(migration3SQL)); err != nil {
-		t.Fatalf("exec migration 3: %v", err)
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
 	}
 
 	repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
@@ -122,8 +121,17 @@ func TestPromptHandler_CreateAndList(t *testing.T) {
 	if len(listResp.Data.Items) != 1 || listResp.Data.Items[0].Name != "Greeting" {
 		t.Fatalf("unexpected list response: %s", listRec.Body.String())
 	}
-	if listResp.Data.Items[0].Body != "Hello there" {
-		t.Fatalf("expected active version body, got %s", listRec.Body.String())
+	if listResp.Data.Items[0].Body != "" {
+		t.Fatalf("expected list without body by default, got %s", listRec.Body.String())
+	}
+
+	listRec = httptest.NewRecorder()
+	router.ServeHTTP(listRec, httptest.NewRequest(http.MethodGet, "/prompts?search=Gree&includeBody=true", nil))
+	if err := json.Unmarshal(listRec.Body.Bytes(), &listResp); err != nil {
+		t.Fatalf("unmarshal list response: %v", err)
+	}
+	if len(listResp.Data.Items) != 1 || listResp.Data.Items[0].Body != "Hello there" {
+		t.Fatalf("expected active version body with includeBody=true, got %s", listRec.Body.String())
 	}
 }
 
@@ -660,3 +668,393 @@ func TestPromptHandler_GetStats(t *testing.T) {
 		t.Fatalf("stats failed: %d %s", statsRec.Code, statsRec.Body.String())
 	}
 }
+
+func TestPromptHandler_ExportCSV(t *testing.T) {
+	handler, cleanup := setupPromptHandler(t)
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(middleware.UserContextKey, "tester-id")
+		ctx.Set(middleware.UserEmailContextKey, "tester@example.com")
+		ctx.Set(middleware.UserRoleContextKey, middleware.RoleAdmin)
+		ctx.Next()
+	})
+	handler.RegisterRoutes(router.Group("/prompts"))
+
+	createBody, _ := json.Marshal(map[string]interface{}{"name": "ExportCSV", "body": "Hello"})
+	req := httptest.NewRequest(http.MethodPost, "/prompts", bytes.NewReader(createBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create prompt failed: %d %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Prompt struct {
+				ID string `json:"id"`
+			} `json:"prompt"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	prompt, err := handler.service.GetPrompt(context.Background(), resp.Data.Prompt.ID)
+	if err != nil || prompt.ActiveVersionID == nil {
+		t.Fatalf("expected active version: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:prompt_handler_test.db?mode=memory&cache=shared&_fk=1")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
+	for _, status := range []string{"success", "error"} {
+		if err := repos.PromptExecutionLog.Create(context.Background(), &domain.PromptExecutionLog{
+			ID:              uuid.NewString(),
+			PromptID:        prompt.ID,
+			PromptVersionID: *prompt.ActiveVersionID,
+			Status:          status,
+			DurationMs:      42,
+		}); err != nil {
+			t.Fatalf("create execution log: %v", err)
+		}
+	}
+
+	execReq := httptest.NewRequest(http.MethodGet, "/prompts/"+prompt.ID+"/executions?format=csv", nil)
+	execRec := httptest.NewRecorder()
+	router.ServeHTTP(execRec, execReq)
+	if execRec.Code != http.StatusOK {
+		t.Fatalf("export executions failed: %d %s", execRec.Code, execRec.Body.String())
+	}
+	if ct := execRec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("unexpected content type %s", ct)
+	}
+	if cd := execRec.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") || !strings.Contains(cd, ".csv") {
+		t.Fatalf("unexpected content disposition %s", cd)
+	}
+	records, err := csv.NewReader(execRec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "id" || records[1][5] != "42" {
+		t.Fatalf("unexpected execution csv %v", records)
+	}
+
+	statsReq := httptest.NewRequest(http.MethodGet, "/prompts/"+prompt.ID+"/stats?format=csv", nil)
+	statsRec := httptest.NewRecorder()
+	router.ServeHTTP(statsRec, statsReq)
+	if statsRec.Code != http.StatusOK || !strings.HasPrefix(statsRec.Body.String(), "day,total_calls,success_calls,average_ms,p50_ms,p90_ms,p99_ms\n") {
+		t.Fatalf("unexpected stats csv: %d %s", statsRec.Code, statsRec.Body.String())
+	}
+
+	badReq := httptest.NewRequest(http.MethodGet, "/prompts/"+prompt.ID+"/stats?format=xml", nil)
+	badRec := httptest.NewRecorder()
+	router.ServeHTTP(badRec, badReq)
+	if badRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format got %d", badRec.Code)
+	}
+
+	missingReq := httptest.NewRequest(http.MethodGet, "/prompts/unknown/executions?format=csv", nil)
+	missingRec := httptest.NewRecorder()
+	router.ServeHTTP(missingRec, missingReq)
+	if missingRec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown prompt got %d", missingRec.Code)
+	}
+}
+
+func TestPromptHandler_AuditExportAndVerify(t *testing.T) {
+	handler, cleanup := setupPromptHandler(t)
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(middleware.UserContextKey, "tester-id")
+		ctx.Set(middleware.UserEmailContextKey, "tester@example.com")
+		ctx.Set(middleware.UserRoleContextKey, middleware.RoleAdmin)
+		ctx.Next()
+	})
+	handler.RegisterRoutes(router.Group("/prompts"))
+	router.GET("/audit/verify", handler.VerifyAuditChain)
+	router.GET("/audit/export", handler.ExportAuditLogs)
+
+	createBody, _ := json.Marshal(map[string]interface{}{"name": "Audited", "body": "Hello"})
+	req := httptest.NewRequest(http.MethodPost, "/prompts", bytes.NewReader(createBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create prompt failed: %d %s", rec.Code, rec.Body.String())
+	}
+
+	exportRec := httptest.NewRecorder()
+	router.ServeHTTP(exportRec, httptest.NewRequest(http.MethodGet, "/audit/export?from=2000-01-01", nil))
+	if exportRec.Code != http.StatusOK {
+		t.Fatalf("export audit logs failed: %d %s", exportRec.Code, exportRec.Body.String())
+	}
+	if ct := exportRec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("unexpected content type %s", ct)
+	}
+	lines := strings.Split(strings.TrimSpace(exportRec.Body.String()), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		t.Fatalf("expected audit records in export")
+	}
+	var first domain.PromptAuditLog
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("unmarshal ndjson line: %v", err)
+	}
+	if first.Seq != 1 || first.Hash == "" {
+		t.Fatalf("expected chained record, got %+v", first)
+	}
+
+	verifyRec := httptest.NewRecorder()
+	router.ServeHTTP(verifyRec, httptest.NewRequest(http.MethodGet, "/audit/verify", nil))
+	var verifyResp struct {
+		Data struct {
+			Verified bool  `json:"verified"`
+			Checked  int   `json:"checked"`
+			HeadSeq  int64 `json:"head_seq"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(verifyRec.Body.Bytes(), &verifyResp); err != nil {
+		t.Fatalf("unmarshal verify: %v", err)
+	}
+	if verifyRec.Code != http.StatusOK || !verifyResp.Data.Verified || verifyResp.Data.Checked != len(lines) {
+		t.Fatalf("unexpected verify response: %d %s", verifyRec.Code, verifyRec.Body.String())
+	}
+
+	badRec := httptest.NewRecorder()
+	router.ServeHTTP(badRec, httptest.NewRequest(http.MethodGet, "/audit/export?from=yesterday", nil))
+	if badRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid from, got %d", badRec.Code)
+	}
+}
+
+func TestPromptHandler_GetPromptWithMockService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockPromptService(ctrl)
+	handler := NewPromptHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/prompts"))
+
+	lock := &domain.EditLock{PromptID: "p-1", HolderID: "u-2", Holder: "editor@example.com"}
+	service.EXPECT().GetPromptWithLocale(gomock.Any(), "p-1", "").Return(&domain.Prompt{ID: "p-1", Name: "Greeting"}, "", nil)
+	service.EXPECT().GetEditLock(gomock.Any(), "p-1").Return(lock, nil)
+	service.EXPECT().GetPromptWithLocale(gomock.Any(), "missing", "").Return(nil, "", promptsvc.ErrPromptNotFound)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/p-1", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"editing_lock"`) || !strings.Contains(rec.Body.String(), `"Greeting"`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 got %d, body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPromptHandler_StreamNDJSONWithMockService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockPromptService(ctrl)
+	handler := NewPromptHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/prompts"))
+
+	service.EXPECT().IterateVersions(gomock.Any(), "p-1", "draft", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ string, fn func(*domain.PromptVersion) error) error {
+			for i := 3; i >= 1; i-- {
+				if err := fn(&domain.PromptVersion{ID: fmt.Sprintf("v-%d", i), PromptID: "p-1", VersionNumber: i, Status: "draft"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/p-1/versions?format=ndjson&status=draft&limit=1", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected all 3 versions regardless of limit, got %q", rec.Body.String())
+	}
+	var first domain.PromptVersion
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.ID != "v-3" {
+		t.Fatalf("unexpected first line %q: %v", lines[0], err)
+	}
+
+	// 尚未输出任何行时仍返回结构化错误。
+	service.EXPECT().IterateVersions(gomock.Any(), "missing", "", gomock.Any()).Return(promptsvc.ErrPromptNotFound)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/missing/versions?format=ndjson", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"code"`) {
+		t.Fatalf("expected 404 error body, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/p-1/versions?format=csv", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format got %d", rec.Code)
+	}
+
+	service.EXPECT().GetPrompt(gomock.Any(), "p-1").Return(&domain.Prompt{ID: "p-1"}, nil)
+	service.EXPECT().IterateExecutionLogs(gomock.Any(), "p-1", 3, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ int, fn func(*domain.PromptExecutionLog) error) error {
+			if err := fn(&domain.PromptExecutionLog{ID: "log-1", PromptID: "p-1", Status: "success"}); err != nil {
+				return err
+			}
+			return errors.New("connection reset")
+		})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/p-1/executions?format=ndjson&days=3", nil))
+	// 已输出的行保留，之后的错误只能中断输出。
+	if rec.Code != http.StatusOK || strings.Count(rec.Body.String(), "\n") != 1 || !strings.Contains(rec.Body.String(), `"log-1"`) {
+		t.Fatalf("unexpected execution stream %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPromptHandler_SparseFieldsWithMockService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockPromptService(ctrl)
+	handler := NewPromptHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/prompts"))
+
+	body := "Hello {{name}}"
+	prompt := &domain.Prompt{ID: "p-1", Name: "Greeting", Body: &body, Status: domain.PromptStatusActive, UpdatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	service.EXPECT().ListPromptsPage(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, opts promptsvc.ListPromptsOptions) (*promptsvc.PromptPage, error) {
+			if !opts.OmitBody {
+				t.Fatalf("expected body to be skipped when not requested")
+			}
+			return &promptsvc.PromptPage{Items: []*domain.Prompt{prompt}}, nil
+		})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts?fields=name,updated_at", nil))
+	var listResp struct {
+		Data struct {
+			Items []map[string]interface{} `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listResp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("unexpected list response %d %s", rec.Code, rec.Body.String())
+	}
+	// id 总是返回，未选择的字段被省略。
+	if item := listResp.Data.Items[0]; len(item) != 3 || item["id"] != "p-1" || item["name"] != "Greeting" || item["updated_at"] == nil {
+		t.Fatalf("unexpected sparse item %v", item)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts?fields=name,secret", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "INVALID_FIELDS") {
+		t.Fatalf("expected 400 for unknown field, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	service.EXPECT().GetPromptWithLocale(gomock.Any(), "p-1", "").Return(prompt, "", nil)
+	service.EXPECT().GetEditLock(gomock.Any(), "p-1").Return(nil, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/p-1?fields=body", nil))
+	var getResp struct {
+		Data struct {
+			Prompt map[string]interface{} `json:"prompt"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &getResp); err != nil || len(getResp.Data.Prompt) != 2 || getResp.Data.Prompt["body"] != body {
+		t.Fatalf("unexpected sparse prompt %d %s", rec.Code, rec.Body.String())
+	}
+
+	service.EXPECT().ListPromptVersionsEx(gomock.Any(), "p-1", gomock.Any(), gomock.Any(), "").Return(&promptsvc.PromptVersionPage{
+		Items: []*domain.PromptVersion{{ID: "v-1", PromptID: "p-1", VersionNumber: 1, Body: body}},
+	}, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prompts/p-1/versions?fields=version_number", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "Hello") || !strings.Contains(rec.Body.String(), `"version_number":1`) {
+		t.Fatalf("unexpected sparse versions %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPromptHandler_AlertWebhookSecretWithMockService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockPromptService(ctrl)
+	handler := NewPromptHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/prompts"))
+
+	// 未提供请求体时旧密钥默认保留 24 小时。
+	service.EXPECT().RotateAlertWebhookSecret(gomock.Any(), "p-1", "r-1", 24*time.Hour).
+		Return(&domain.PromptAlertRule{ID: "r-1", PromptID: "p-1", WebhookSecret: "whsec_new"}, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/prompts/p-1/alerts/r-1/webhook-secret/rotate", nil))
+	if rec.Code != http.StatusOK || strings.Count(rec.Body.String(), "whsec_new") != 1 {
+		t.Fatalf("expected secret returned once, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	service.EXPECT().RotateAlertWebhookSecret(gomock.Any(), "p-1", "r-1", time.Duration(0)).Return(nil, promptsvc.ErrAlertWebhookMissing)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/prompts/p-1/alerts/r-1/webhook-secret/rotate", strings.NewReader(`{"overlap_minutes":0}`)))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without webhook, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	service.EXPECT().TestAlertWebhook(gomock.Any(), "p-1", "r-1").Return(nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/prompts/p-1/alerts/r-1/test", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"delivered":true`) {
+		t.Fatalf("unexpected test-fire response %d %s", rec.Code, rec.Body.String())
+	}
+
+	service.EXPECT().TestAlertWebhook(gomock.Any(), "p-1", "r-1").Return(fmt.Errorf("%w: alert webhook responded 500", promptsvc.ErrAlertWebhookFailed))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/prompts/p-1/alerts/r-1/test", nil))
+	if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "ALERT_WEBHOOK_FAILED") {
+		t.Fatalf("expected 502 for failing receiver, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPromptHandler_ReplayExecutionWithMockService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockPromptService(ctrl)
+	handler := NewPromptHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/executions/:id/replay", handler.ReplayExecution)
+
+	service.EXPECT().ReplayExecution(gomock.Any(), promptsvc.ReplayExecutionInput{ExecutionID: "log-1", Invoke: true, Unified: true}).
+		Return(&promptsvc.ExecutionReplay{ExecutionID: "log-1", Invoked: true, Changed: true, Compared: promptsvc.ReplayCompareOutput}, nil)
+	service.EXPECT().ReplayExecution(gomock.Any(), promptsvc.ReplayExecutionInput{ExecutionID: "log-1"}).Return(nil, promptsvc.ErrGatewayUnavailable)
+	service.EXPECT().ReplayExecution(gomock.Any(), promptsvc.ReplayExecutionInput{ExecutionID: "missing"}).Return(nil, promptsvc.ErrExecutionNotFound)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/executions/log-1/replay", strings.NewReader(`{"invoke":true,"unified":true}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"changed":true`) {
+		t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	// 请求体可省略。
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/executions/log-1/replay", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 got %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/executions/missing/replay", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "EXECUTION_NOT_FOUND") {
+		t.Fatalf("expected 404 got %d, body=%s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// ImportPrompts 批量导入简单 Prompt，目前仅支持 ?format=csv；CSV 可作为 multipart 的 file 字段上传，也可直接作为请求体。
+func (h *PromptHandler) ImportPrompts(ctx *gin.Context) {
+	if format := strings.ToLower(strings.TrimSpace(ctx.Query("format"))); format != "csv" {
+		httpx.RespondError(ctx, http.StatusBadRequest, "UNSUPPORTED_FORMAT", "unsupported import format, expected format=csv", nil)
+		return
+	}
+	opts, ok := importOptionsFromRequest(ctx)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = ctx.Request.Body
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			respondImportUploadError(ctx, err)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_FILE", err.Error(), nil)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := h.service.ImportCSV(ctx, body, opts)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			httpx.RespondError(ctx, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "uploaded csv exceeds size limit", gin.H{"limit": maxErr.Limit})
+			return
+		}
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, report)
+}
+
+// ImportPromptArchive 通过 multipart 上传 zip 压缩包批量导入 Prompt，返回逐文件的导入报告。
+func (h *PromptHandler) ImportPromptArchive(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		respondImportUploadError(ctx, err)
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".zip") {
+		httpx.RespondError(ctx, http.StatusBadRequest, "UNSUPPORTED_FILE_TYPE", "unsupported file type, expected .zip", nil)
+		return
+	}
+	opts, ok := importOptionsFromRequest(ctx)
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_FILE", err.Error(), nil)
+		return
+	}
+	defer file.Close()
+
+	report, err := h.service.ImportArchive(ctx, file, fileHeader.Size, opts)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, report)
+}
+
+// importOptionsFromRequest 读取 on_conflict 与 dry_run，表单字段优先于查询参数。
+func importOptionsFromRequest(ctx *gin.Context) (promptsvc.ImportOptions, bool) {
+	opts := promptsvc.ImportOptions{
+		OnConflict: strings.TrimSpace(ctx.DefaultPostForm("on_conflict", ctx.Query("on_conflict"))),
+		CreatedBy:  actorFromContext(ctx),
+	}
+	if raw := strings.TrimSpace(ctx.DefaultPostForm("dry_run", ctx.Query("dry_run"))); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", "dry_run must be a boolean", nil)
+			return opts, false
+		}
+		opts.DryRun = dryRun
+	}
+	return opts, true
+}
+
+func respondImportUploadError(ctx *gin.Context, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		httpx.RespondError(ctx, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "uploaded file exceeds size limit", gin.H{"limit": maxErr.Limit})
+		return
+	}
+	httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", "multipart field 'file' is required", nil)
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type setVersionLocaleRequest struct {
+	Body string `json:"body" binding:"required,min=1"`
+}
+
+// ListVersionLocales 列出版本的语言变体。
+func (h *PromptHandler) ListVersionLocales(ctx *gin.Context) {
+	locales, err := h.service.ListVersionLocales(ctx, ctx.Param("id"), ctx.Param("versionId"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"items": locales})
+}
+
+// SetVersionLocale 创建或覆盖版本的语言变体。
+func (h *PromptHandler) SetVersionLocale(ctx *gin.Context) {
+	var req setVersionLocaleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	createdBy := ctx.GetString(middleware.UserEmailContextKey)
+	if createdBy == "" {
+		createdBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	locale, err := h.service.SetVersionLocale(ctx, promptsvc.SetVersionLocaleInput{
+		PromptID:  ctx.Param("id"),
+		VersionID: ctx.Param("versionId"),
+		Locale:    ctx.Param("locale"),
+		Body:      req.Body,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"locale": locale})
+}
+
+// DeleteVersionLocale 删除版本的语言变体。
+func (h *PromptHandler) DeleteVersionLocale(ctx *gin.Context) {
+	deletedBy := ctx.GetString(middleware.UserEmailContextKey)
+	if deletedBy == "" {
+		deletedBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	if err := h.service.DeleteVersionLocale(ctx, ctx.Param("id"), ctx.Param("versionId"), ctx.Param("locale"), deletedBy); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"version_id": ctx.Param("versionId"), "locale": ctx.Param("locale")})
+}
+
+// GetLocaleCoverage 报告激活版本中缺少指定语言的 Prompt。
+func (h *PromptHandler) GetLocaleCoverage(ctx *gin.Context) {
+	report, err := h.service.GetLocaleCoverage(ctx, ctx.Query("locale"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"coverage": report})
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// AcquireEditLock 获取或续期 Prompt 的编辑锁，已被他人持有时返回 409 与持有者信息。
+func (h *PromptHandler) AcquireEditLock(ctx *gin.Context) {
+	lock, err := h.service.AcquireEditLock(ctx, ctx.Param("id"), ctx.GetString(middleware.UserContextKey), actorFromContext(ctx))
+	if err != nil {
+		var held *promptsvc.EditLockHeldError
+		if errors.As(err, &held) {
+			httpx.RespondError(ctx, http.StatusConflict, "PROMPT_LOCKED", err.Error(), gin.H{"lock": held.Lock})
+			return
+		}
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"lock": lock})
+}
+
+// ReleaseEditLock 释放当前用户持有的编辑锁。
+func (h *PromptHandler) ReleaseEditLock(ctx *gin.Context) {
+	promptID := ctx.Param("id")
+	if err := h.service.ReleaseEditLock(ctx, promptID, ctx.GetString(middleware.UserContextKey)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"prompt_id": promptID})
+}
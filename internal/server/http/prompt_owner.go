@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type promptOwnerRequest struct {
+	Type string `json:"type" binding:"required,oneof=user team"`
+	ID   string `json:"id" binding:"required"`
+}
+
+func (r *promptOwnerRequest) toDomain() *domain.PromptOwner {
+	if r == nil {
+		return nil
+	}
+	return &domain.PromptOwner{Type: r.Type, ID: r.ID}
+}
+
+// TransferPromptOwner 将 Prompt 转交给指定用户或团队。
+func (h *PromptHandler) TransferPromptOwner(ctx *gin.Context) {
+	var req promptOwnerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	prompt, err := h.service.TransferOwner(ctx, ctx.Param("id"), req.toDomain(), actorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"prompt": prompt})
+}
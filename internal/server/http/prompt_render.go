@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type renderPromptRequest struct {
+	VersionID string                 `json:"version_id"`
+	Locale    string                 `json:"locale"`
+	Mode      string                 `json:"mode"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// RenderPrompt 渲染 Prompt 模板，默认使用激活版本。
+func (h *PromptHandler) RenderPrompt(ctx *gin.Context) {
+	var req renderPromptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	result, err := h.service.RenderPrompt(ctx, promptsvc.RenderPromptInput{
+		PromptID:  ctx.Param("id"),
+		VersionID: req.VersionID,
+		Locale:    req.Locale,
+		Mode:      req.Mode,
+		Variables: req.Variables,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"render": result})
+}
+
+type renderedDiffRequest struct {
+	TargetVersionID string                 `json:"target_version_id"`
+	CompareTo       string                 `json:"compare_to" binding:"omitempty,oneof=previous active"`
+	Granularity     string                 `json:"granularity"`
+	Unified         bool                   `json:"unified"`
+	Locale          string                 `json:"locale"`
+	Mode            string                 `json:"mode"`
+	Variables       map[string]interface{} `json:"variables"`
+}
+
+// DiffRenderedVersions 使用同一组变量渲染两个版本并返回输出差异。
+func (h *PromptHandler) DiffRenderedVersions(ctx *gin.Context) {
+	var req renderedDiffRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	options := promptsvc.DiffPromptVersionOptions{
+		Granularity: req.Granularity,
+		Unified:     req.Unified,
+	}
+	if targetID := strings.TrimSpace(req.TargetVersionID); targetID != "" {
+		options.TargetVersionID = &targetID
+	} else if req.CompareTo == "active" {
+		options.CompareToActive = true
+	} else {
+		options.CompareToPrevious = true
+	}
+
+	diff, err := h.service.DiffRenderedVersions(ctx, promptsvc.RenderedDiffInput{
+		PromptID:      ctx.Param("id"),
+		BaseVersionID: ctx.Param("versionId"),
+		Diff:          options,
+		Locale:        req.Locale,
+		Mode:          req.Mode,
+		Variables:     req.Variables,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"diff": diff})
+}
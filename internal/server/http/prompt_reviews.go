@@ -0,0 +1,116 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type reviewPolicyRequest struct {
+	RequiredApprovals int                  `json:"required_approvals" binding:"required,min=1"`
+	Reviewers         []promptOwnerRequest `json:"reviewers" binding:"omitempty,dive"`
+}
+
+type approveVersionRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// reviewActorFromContext 从请求上下文构造评审操作者。
+func reviewActorFromContext(ctx *gin.Context) promptsvc.ReviewActor {
+	return promptsvc.ReviewActor{
+		UserID:  ctx.GetString(middleware.UserContextKey),
+		Actor:   actorFromContext(ctx),
+		IsAdmin: ctx.GetString(middleware.UserRoleContextKey) == middleware.RoleAdmin,
+	}
+}
+
+// GetReviewPolicy 返回 Prompt 的评审策略。
+func (h *PromptHandler) GetReviewPolicy(ctx *gin.Context) {
+	policy, err := h.service.GetReviewPolicy(ctx, ctx.Param("id"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"policy": policy})
+}
+
+// SetReviewPolicy 创建或覆盖评审策略，仅负责人或管理员可操作。
+func (h *PromptHandler) SetReviewPolicy(ctx *gin.Context) {
+	var req reviewPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	reviewers := make([]domain.PromptOwner, 0, len(req.Reviewers))
+	for i := range req.Reviewers {
+		reviewers = append(reviewers, *req.Reviewers[i].toDomain())
+	}
+
+	policy, err := h.service.SetReviewPolicy(ctx, promptsvc.SetReviewPolicyInput{
+		PromptID:          ctx.Param("id"),
+		RequiredApprovals: req.RequiredApprovals,
+		Reviewers:         reviewers,
+		By:                reviewActorFromContext(ctx),
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"policy": policy})
+}
+
+// DeleteReviewPolicy 移除评审策略。
+func (h *PromptHandler) DeleteReviewPolicy(ctx *gin.Context) {
+	if err := h.service.DeleteReviewPolicy(ctx, ctx.Param("id"), reviewActorFromContext(ctx)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"prompt_id": ctx.Param("id")})
+}
+
+// GetVersionReview 返回版本的批准情况。
+func (h *PromptHandler) GetVersionReview(ctx *gin.Context) {
+	review, err := h.service.GetVersionReview(ctx, ctx.Param("id"), ctx.Param("versionId"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"review": review})
+}
+
+// ApproveVersion 以当前用户身份批准版本，请求体可选。
+func (h *PromptHandler) ApproveVersion(ctx *gin.Context) {
+	var req approveVersionRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+
+	review, err := h.service.ApproveVersion(ctx, promptsvc.ApproveVersionInput{
+		PromptID:  ctx.Param("id"),
+		VersionID: ctx.Param("versionId"),
+		By:        reviewActorFromContext(ctx),
+		Comment:   req.Comment,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"review": review})
+}
+
+// RevokeVersionApproval 撤回当前用户的批准。
+func (h *PromptHandler) RevokeVersionApproval(ctx *gin.Context) {
+	review, err := h.service.RevokeApproval(ctx, ctx.Param("id"), ctx.Param("versionId"), reviewActorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"review": review})
+}
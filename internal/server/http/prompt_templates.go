@@ -0,0 +1,91 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type promptTemplateRequest struct {
+	Slug            string      `json:"slug"`
+	Name            string      `json:"name" binding:"required,max=128"`
+	Description     *string     `json:"description"`
+	Body            string      `json:"body" binding:"required"`
+	VariablesSchema interface{} `json:"variables_schema"`
+	Metadata        interface{} `json:"metadata"`
+	Tags            []string    `json:"tags" binding:"max=10"`
+}
+
+func (r promptTemplateRequest) input(slug, actor string) promptsvc.PromptTemplateInput {
+	return promptsvc.PromptTemplateInput{
+		Slug:            slug,
+		Name:            r.Name,
+		Description:     r.Description,
+		Body:            r.Body,
+		VariablesSchema: r.VariablesSchema,
+		Metadata:        r.Metadata,
+		Tags:            r.Tags,
+		CreatedBy:       actor,
+	}
+}
+
+// ListPromptTemplates 列出可在创建 Prompt 时选用的脚手架模板。
+func (h *PromptHandler) ListPromptTemplates(ctx *gin.Context) {
+	templates, err := h.service.ListTemplates(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": templates})
+}
+
+// GetPromptTemplate 返回单个脚手架模板。
+func (h *PromptHandler) GetPromptTemplate(ctx *gin.Context) {
+	template, err := h.service.GetTemplate(ctx, ctx.Param("slug"))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"template": template})
+}
+
+// CreatePromptTemplate 新建脚手架模板（仅管理员）。
+func (h *PromptHandler) CreatePromptTemplate(ctx *gin.Context) {
+	var req promptTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	template, err := h.service.CreateTemplate(ctx, req.input(req.Slug, actorFromContext(ctx)))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"template": template})
+}
+
+// UpdatePromptTemplate 整体替换脚手架模板内容（仅管理员），slug 取自路径。
+func (h *PromptHandler) UpdatePromptTemplate(ctx *gin.Context) {
+	var req promptTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	template, err := h.service.UpdateTemplate(ctx, req.input(ctx.Param("slug"), actorFromContext(ctx)))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"template": template})
+}
+
+// DeletePromptTemplate 删除脚手架模板（仅管理员）。
+func (h *PromptHandler) DeletePromptTemplate(ctx *gin.Context) {
+	if err := h.service.DeleteTemplate(ctx, ctx.Param("slug")); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"slug": ctx.Param("slug")})
+}
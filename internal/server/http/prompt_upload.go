@@ -0,0 +1,209 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+const (
+	uploadKindText = "text"
+	uploadKindJSON = "json"
+)
+
+var (
+	errUploadTooLarge      = errors.New("uploaded file exceeds size limit")
+	errUploadUnsupported   = errors.New("unsupported file type, expected .txt, .md or .json")
+	errUploadNotText       = errors.New("uploaded file is not valid UTF-8 text")
+	utf8BOM                = []byte{0xEF, 0xBB, 0xBF}
+	allowedUploadExtension = map[string]string{
+		".txt":      uploadKindText,
+		".md":       uploadKindText,
+		".markdown": uploadKindText,
+		".json":     uploadKindJSON,
+	}
+)
+
+// uploadedVersionDocument 描述 JSON 文件上传时支持的字段，与 JSON 创建版本接口保持一致。
+type uploadedVersionDocument struct {
+	Body            string      `json:"body"`
+	VariablesSchema interface{} `json:"variables_schema"`
+	Metadata        interface{} `json:"metadata"`
+	Status          string      `json:"status"`
+	Activate        *bool       `json:"activate"`
+}
+
+// UploadPromptVersion 通过 multipart 上传 txt/md/json 文件创建 Prompt 版本。
+func (h *PromptHandler) UploadPromptVersion(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			httpx.RespondError(ctx, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", errUploadTooLarge.Error(), gin.H{"limit": h.uploadLimit})
+			return
+		}
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", "multipart field 'file' is required", nil)
+		return
+	}
+
+	kind, ok := allowedUploadExtension[strings.ToLower(filepath.Ext(fileHeader.Filename))]
+	if !ok {
+		httpx.RespondError(ctx, http.StatusBadRequest, "UNSUPPORTED_FILE_TYPE", errUploadUnsupported.Error(), nil)
+		return
+	}
+
+	content, err := readUploadedFile(fileHeader, h.uploadLimit)
+	if err != nil {
+		if errors.Is(err, errUploadTooLarge) {
+			httpx.RespondError(ctx, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", err.Error(), gin.H{"limit": h.uploadLimit})
+			return
+		}
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_FILE", err.Error(), nil)
+		return
+	}
+
+	if err := sniffTextContent(content); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_FILE", err.Error(), nil)
+		return
+	}
+
+	var doc uploadedVersionDocument
+	if kind == uploadKindJSON {
+		if err := json.Unmarshal(content, &doc); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_FILE", fmt.Sprintf("invalid json document: %v", err), nil)
+			return
+		}
+	} else {
+		doc.Body = string(content)
+		if doc.VariablesSchema, err = parseJSONFormField(ctx, "variables_schema"); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+		if doc.Metadata, err = parseJSONFormField(ctx, "metadata"); err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+			return
+		}
+	}
+
+	// 表单字段优先于文件内声明的 status/activate，便于同一文件以不同方式发布。
+	if status := strings.TrimSpace(ctx.PostForm("status")); status != "" {
+		doc.Status = status
+	}
+	if !isAllowedVersionStatus(doc.Status) {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", "status must be one of draft, published, archived", nil)
+		return
+	}
+	activate := doc.Activate != nil && *doc.Activate
+	if raw, ok := ctx.GetPostForm("activate"); ok && strings.TrimSpace(raw) != "" {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", "activate must be a boolean", nil)
+			return
+		}
+		activate = parsed
+	}
+	allowDuplicate := false
+	if raw := strings.TrimSpace(ctx.PostForm("allow_duplicate")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", "allow_duplicate must be a boolean", nil)
+			return
+		}
+		allowDuplicate = parsed
+	}
+
+	createdBy := ctx.GetString(middleware.UserEmailContextKey)
+	if createdBy == "" {
+		createdBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	version, err := h.service.CreatePromptVersion(ctx, promptsvc.CreatePromptVersionInput{
+		PromptID:        ctx.Param("id"),
+		Body:            doc.Body,
+		VariablesSchema: doc.VariablesSchema,
+		Metadata:        doc.Metadata,
+		Status:          doc.Status,
+		CreatedBy:       createdBy,
+		Activate:        activate,
+		AllowDuplicate:  allowDuplicate,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"version": version})
+}
+
+// readUploadedFile 读取上传文件内容，超过 limit 时返回 errUploadTooLarge。
+func readUploadedFile(fileHeader *multipart.FileHeader, limit int64) ([]byte, error) {
+	if limit > 0 && fileHeader.Size > limit {
+		return nil, errUploadTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open uploaded file: %w", err)
+	}
+	defer file.Close()
+
+	reader := io.Reader(file)
+	if limit > 0 {
+		reader = io.LimitReader(file, limit+1)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read uploaded file: %w", err)
+	}
+	if limit > 0 && int64(len(content)) > limit {
+		return nil, errUploadTooLarge
+	}
+
+	return bytes.TrimPrefix(content, utf8BOM), nil
+}
+
+// sniffTextContent 基于内容嗅探拒绝二进制文件，不信任客户端声明的 Content-Type。
+func sniffTextContent(content []byte) error {
+	if len(content) == 0 {
+		return nil
+	}
+	detected := http.DetectContentType(content)
+	if !strings.HasPrefix(detected, "text/") || !utf8.Valid(content) {
+		return errUploadNotText
+	}
+	return nil
+}
+
+func parseJSONFormField(ctx *gin.Context, field string) (interface{}, error) {
+	raw := strings.TrimSpace(ctx.PostForm(field))
+	if raw == "" {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return nil, fmt.Errorf("%s must be valid json: %v", field, err)
+	}
+	return value, nil
+}
+
+func isAllowedVersionStatus(status string) bool {
+	switch status {
+	case "", "draft", "published", "archived":
+		return true
+	default:
+		return false
+	}
+}
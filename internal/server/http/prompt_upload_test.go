@@ -0,0 +1,124 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+)
+
+func newUploadRequest(t *testing.T, target, filename string, content []byte, fields map[string]string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			t.Fatalf("write field: %v", err)
+		}
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, target, &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestPromptHandler_UploadPromptVersion(t *testing.T) {
+	handler, cleanup := setupPromptHandler(t)
+	defer cleanup()
+	handler.uploadLimit = 64
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(middleware.UserContextKey, "tester-id")
+		ctx.Set(middleware.UserEmailContextKey, "tester@example.com")
+		ctx.Next()
+	})
+	handler.RegisterRoutes(router.Group("/prompts"))
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "uploaded"})
+	createReq := httptest.NewRequest(http.MethodPost, "/prompts", bytes.NewReader(body))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", createRec.Code, createRec.Body.String())
+	}
+	var created struct {
+		Data struct {
+			Prompt struct {
+				ID string `json:"id"`
+			} `json:"prompt"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("unmarshal create response: %v", err)
+	}
+	target := "/prompts/" + created.Data.Prompt.ID + "/versions/upload"
+
+	// markdown 文件作为正文，并通过表单字段激活
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newUploadRequest(t, target, "prompt.md", []byte("# Hi\nHello {{name}}"), map[string]string{
+		"status":   "published",
+		"activate": "true",
+	}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", rec.Code, rec.Body.String())
+	}
+	var uploaded struct {
+		Data struct {
+			Version struct {
+				Body          string `json:"body"`
+				Status        string `json:"status"`
+				VersionNumber int    `json:"version_number"`
+			} `json:"version"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &uploaded); err != nil {
+		t.Fatalf("unmarshal upload response: %v", err)
+	}
+	if uploaded.Data.Version.Body != "# Hi\nHello {{name}}" || uploaded.Data.Version.Status != "published" {
+		t.Fatalf("unexpected uploaded version: %s", rec.Body.String())
+	}
+
+	// json 文件解析 body 与 metadata
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newUploadRequest(t, target, "prompt.json", []byte(`{"body":"from json","metadata":{"k":"v"}}`), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	cases := []struct {
+		name     string
+		filename string
+		content  []byte
+		status   int
+	}{
+		{name: "unsupported extension", filename: "prompt.exe", content: []byte("hello"), status: http.StatusBadRequest},
+		{name: "binary content", filename: "prompt.txt", content: []byte{0x00, 0x01, 0x02, 0xff, 0xfe}, status: http.StatusBadRequest},
+		{name: "too large", filename: "prompt.txt", content: bytes.Repeat([]byte("a"), 65), status: http.StatusRequestEntityTooLarge},
+		{name: "invalid json", filename: "prompt.json", content: []byte("{not json"), status: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, newUploadRequest(t, target, tc.filename, tc.content, nil))
+		if rec.Code != tc.status {
+			t.Fatalf("%s: expected %d got %d body=%s", tc.name, tc.status, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// ValidatePromptVersion 对版本草稿执行全部检查并返回报告，不会创建版本。
+func (h *PromptHandler) ValidatePromptVersion(ctx *gin.Context) {
+	var req createPromptVersionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	report, err := h.service.ValidatePromptVersion(ctx, promptsvc.CreatePromptVersionInput{
+		PromptID:        ctx.Param("id"),
+		Body:            req.Body,
+		VariablesSchema: req.VariablesSchema,
+		Metadata:        req.Metadata,
+		Status:          req.Status,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{"report": report})
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+type versionStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// UpdateVersionStatus 按 draft → published → archived 流转版本状态。
+func (h *PromptHandler) UpdateVersionStatus(ctx *gin.Context) {
+	var req versionStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	changedBy := ctx.GetString(middleware.UserEmailContextKey)
+	if changedBy == "" {
+		changedBy = ctx.GetString(middleware.UserContextKey)
+	}
+
+	version, err := h.service.TransitionVersionStatus(ctx, promptsvc.TransitionVersionStatusInput{
+		PromptID:  ctx.Param("id"),
+		VersionID: ctx.Param("versionId"),
+		Status:    req.Status,
+		ChangedBy: changedBy,
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"version": version})
+}
@@ -1,9 +1,13 @@
 package http
 
 import (
+	"context"
 	"database/sql"
+	"net"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,9 +15,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/zacharykka/prompt-manager/internal/config"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
 	"github.com/zacharykka/prompt-manager/internal/infra/cache"
 	"github.com/zacharykka/prompt-manager/internal/infra/database"
 	"github.com/zacharykka/prompt-manager/internal/middleware"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
 	"go.uber.org/zap"
 )
 
@@ -21,18 +27,59 @@ import (
 type HealthDependencies struct {
 	DB    *sql.DB
 	Redis *redis.Client
+	// RedisDegraded 表示服务以降级模式启动（Redis 不可达），此时 Redis 为 nil。
+	RedisDegraded bool
+	// MemoryStore 表示仓储运行在内存模式（database.driver=memory），此时 DB 为 nil。
+	MemoryStore bool
 }
 
 // RouterOptions 用于自定义路由行为，例如注入中间件。
 type RouterOptions struct {
-	Middlewares    []gin.HandlerFunc
-	HealthHandler  gin.HandlerFunc
-	HealthDeps     *HealthDependencies
-	AuthHandler    *AuthHandler
-	PromptHandler  *PromptHandler
-	RateLimiter    gin.HandlerFunc
-	AuthRateLimit  gin.HandlerFunc
-	LoginRateLimit gin.HandlerFunc
+	Middlewares     []gin.HandlerFunc
+	HealthHandler   gin.HandlerFunc
+	HealthDeps      *HealthDependencies
+	AuthHandler     *AuthHandler
+	PromptHandler   *PromptHandler
+	PipelineHandler *PipelineHandler
+	AuditHandler    *AuditHandler
+	// WorkspaceHandler 提供组织、工作区与成员管理接口。
+	WorkspaceHandler *WorkspaceHandler
+	RateLimiter      gin.HandlerFunc
+	AuthRateLimit    gin.HandlerFunc
+	LoginRateLimit   gin.HandlerFunc
+	// TokenParser 自定义访问令牌校验（支持 kid 与密钥轮换），为空时使用 accessTokenSecret。
+	TokenParser middleware.TokenParser
+	// WorkspaceResolver 非空时 Prompt 接口按当前工作区限定范围，并按工作区角色校验写权限。
+	WorkspaceResolver middleware.WorkspaceRoleResolver
+	// MetricsHandler 非空时在 /metrics 暴露 Prometheus 指标。
+	MetricsHandler http.Handler
+	// APIKeyAuthenticator 非空时 Prompt 与 Pipeline 接口额外接受 X-API-Key 认证，并按密钥范围授权。
+	APIKeyAuthenticator middleware.APIKeyAuthenticator
+	// APIKeyRateLimit 在认证后按 API Key 独立限流，仅对 API Key 请求生效。
+	APIKeyRateLimit gin.HandlerFunc
+	// MeteringHandler 非空时在 /admin/metering 提供租户用量查询，在 /admin/storage 提供存储占用报告。
+	MeteringHandler *MeteringHandler
+	// UsageRecorder 非空时按工作区统计 Prompt、执行上报、导出与 Pipeline 接口的 API 调用次数。
+	UsageRecorder middleware.UsageRecorder
+	// TelemetryHandler 非空时在 /admin/telemetry/preview 预览匿名统计上报内容。
+	TelemetryHandler *TelemetryHandler
+	// AnnouncementHandler 非空时提供公开的 /announcements 与管理员的 /admin/announcements。
+	AnnouncementHandler *AnnouncementHandler
+	// GitSyncHandler 非空时在 /webhooks/github 接收 GitHub push 事件，凭签名而非登录态鉴权。
+	GitSyncHandler *GitSyncHandler
+	// SlackHandler 非空时在 /integrations/slack/commands 处理 Slack 斜杠命令，凭 Slack 签名鉴权。
+	SlackHandler *SlackHandler
+	// ModelHandler 非空时在 /models 向登录用户列出已知模型及其生命周期状态。
+	ModelHandler *ModelHandler
+	// StatusHandler 非空时在 /status 公开构建信息、运行时长与功能开关。
+	StatusHandler *StatusHandler
+	// FreezeOverrideRoles 为可在变更冻结窗口内继续激活与删除 Prompt 的角色。
+	FreezeOverrideRoles []string
+	// ResponseCache 非空时在 ResponseCacheTTL 内缓存 Prompt 列表与版本列表，由服务层事件失效。
+	ResponseCache    middleware.ResponseCacheStore
+	ResponseCacheTTL time.Duration
+	// ReadOnly 为 true 时除登录与刷新令牌外拒绝全部写请求，用于数据库结构版本高于当前程序的实例。
+	ReadOnly bool
 }
 
 // NewEngine 根据环境配置初始化 Gin 引擎，并注册基础路由。
@@ -45,14 +92,21 @@ func NewEngine(cfg *config.Config, logger *zap.Logger, opts RouterOptions) *gin.
 
 	engine := gin.New()
 	engine.RedirectTrailingSlash = false
+	// 服务层直接接收 *gin.Context，需回退到请求上下文以读取中间件写入的工作区等值。
+	engine.ContextWithFallback = true
 
 	engine.Use(gin.Recovery())
+	// 探测请求先于限流与请求日志标记，使其无论挂在哪条中间件链上都不受影响。
+	engine.Use(middleware.MarkProbes(probeAllowlist(cfg.Server.Probes)))
 	engine.Use(middleware.SecurityHeaders(cfg.Server.SecurityHeaders))
 	if cfg.Server.MaxRequestBody > 0 {
 		engine.MaxMultipartMemory = cfg.Server.MaxRequestBody
-		engine.Use(middleware.LimitRequestBody(cfg.Server.MaxRequestBody))
+		engine.Use(middleware.LimitRequestBodyBy(bodyLimitFor(cfg.Server)))
+	}
+	engine.Use(corsMiddleware(cfg.Server))
+	if opts.ReadOnly {
+		engine.Use(middleware.ReadOnly("/api/v1/auth/login", "/api/v1/auth/refresh"))
 	}
-	engine.Use(cors.New(buildCORSConfig(cfg.Server)))
 
 	for _, mw := range opts.Middlewares {
 		if mw != nil {
@@ -66,6 +120,38 @@ func NewEngine(cfg *config.Config, logger *zap.Logger, opts RouterOptions) *gin.
 	}
 
 	engine.GET("/healthz", healthHandler)
+	if opts.MetricsHandler != nil {
+		engine.GET("/metrics", gin.WrapH(opts.MetricsHandler))
+	}
+	if opts.AuthHandler != nil {
+		engine.GET("/.well-known/jwks.json", opts.AuthHandler.JWKS)
+	}
+
+	authGuard := middleware.AuthGuard(cfg.Auth.AccessTokenSecret)
+	if opts.TokenParser != nil {
+		authGuard = middleware.AuthGuardWithParser(opts.TokenParser)
+	}
+
+	// integrationGuards 用于允许 API Key 访问的接口组：先认证，再按密钥限流。
+	integrationGuards := []gin.HandlerFunc{authGuard}
+	if opts.APIKeyAuthenticator != nil {
+		integrationGuards = []gin.HandlerFunc{middleware.AuthGuardWithAPIKeys(authGuard, opts.APIKeyAuthenticator)}
+		if opts.APIKeyRateLimit != nil {
+			integrationGuards = append(integrationGuards, opts.APIKeyRateLimit)
+		}
+	}
+
+	// workspaceScoped 返回工作区解析与 API 调用计量中间件，按此顺序挂在认证之后。
+	workspaceScoped := func() []gin.HandlerFunc {
+		var handlers []gin.HandlerFunc
+		if opts.WorkspaceResolver != nil {
+			handlers = append(handlers, middleware.WorkspaceResolver(opts.WorkspaceResolver))
+		}
+		if opts.UsageRecorder != nil {
+			handlers = append(handlers, middleware.MeterAPICalls(opts.UsageRecorder))
+		}
+		return handlers
+	}
 
 	api := engine.Group("/api/v1")
 	if opts.RateLimiter != nil {
@@ -85,27 +171,195 @@ func NewEngine(cfg *config.Config, logger *zap.Logger, opts RouterOptions) *gin.
 		}
 		authGroup.POST("/refresh", opts.AuthHandler.Refresh)
 		authGroup.GET("/github/callback", opts.AuthHandler.GitHubCallback)
+		authGroup.GET("/password-policy", opts.AuthHandler.PasswordPolicy)
+		meGroup := api.Group("/me", authGuard)
+		meGroup.GET("/logins", opts.AuthHandler.ListMyLogins)
+		meGroup.GET("/identities", opts.AuthHandler.ListMyIdentities)
+		meGroup.GET("/api-keys", opts.AuthHandler.ListMyAPIKeys)
+		meGroup.POST("/api-keys", opts.AuthHandler.CreateMyAPIKey)
+		meGroup.DELETE("/api-keys/:keyId", opts.AuthHandler.RevokeMyAPIKey)
+		meGroup.POST("/identities/link/:provider", opts.AuthHandler.LinkIdentity)
+		if opts.LoginRateLimit != nil {
+			meGroup.POST("/password", opts.LoginRateLimit, opts.AuthHandler.ChangePassword)
+		} else {
+			meGroup.POST("/password", opts.AuthHandler.ChangePassword)
+		}
+		if opts.LoginRateLimit != nil {
+			authGroup.POST("/register", opts.LoginRateLimit, opts.AuthHandler.Register)
+			authGroup.POST("/invitations/accept", opts.LoginRateLimit, opts.AuthHandler.AcceptInvitation)
+		} else {
+			authGroup.POST("/register", opts.AuthHandler.Register)
+			authGroup.POST("/invitations/accept", opts.AuthHandler.AcceptInvitation)
+		}
+
+		keyGroup := authGroup.Group("/keys", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		keyGroup.GET("", opts.AuthHandler.ListSigningKeys)
+		keyGroup.POST("/rotate", opts.AuthHandler.RotateSigningKey)
+
+		invitationGroup := api.Group("/admin/invitations", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		invitationGroup.GET("", opts.AuthHandler.ListInvitations)
+		invitationGroup.POST("", opts.AuthHandler.CreateInvitation)
+
+		userAdminGroup := api.Group("/admin/users", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		userAdminGroup.GET("/pending", opts.AuthHandler.ListPendingUsers)
+		userAdminGroup.POST("/:id/approve", opts.AuthHandler.ApproveUser)
+		userAdminGroup.POST("/:id/merge", opts.AuthHandler.MergeUser)
+		userAdminGroup.POST("/:id/deactivate", opts.AuthHandler.DeactivateUser)
 	}
+	api.GET("/admin/config", authGuard, middleware.RequireRoles(middleware.RoleAdmin), configDumpHandler(cfg))
 	if opts.PromptHandler != nil {
 		promptGroup := api.Group("/prompts")
-		promptGroup.Use(middleware.AuthGuard(cfg.Auth.AccessTokenSecret))
-		promptGroup.GET("", opts.PromptHandler.ListPrompts)
-		promptGroup.GET("/", opts.PromptHandler.ListPrompts)
-		promptGroup.GET("/:id", opts.PromptHandler.GetPrompt)
-		promptGroup.GET("/:id/versions", opts.PromptHandler.ListPromptVersions)
-		promptGroup.GET("/:id/versions/:versionId/diff", opts.PromptHandler.DiffPromptVersion)
-		promptGroup.GET("/:id/stats", opts.PromptHandler.GetPromptStats)
-
-		// Write operations - no role restriction in single-user mode
-		writeGroup := promptGroup.Group("")
+		promptGroup.Use(integrationGuards...)
+		promptGroup.Use(workspaceScoped()...)
+		promptGroup.Use(middleware.FreezeOverride(opts.FreezeOverrideRoles...))
+		readGroup := promptGroup.Group("", middleware.RequireScopes(domain.APIKeyScopeRead))
+		listPrompts := []gin.HandlerFunc{opts.PromptHandler.ListPrompts}
+		listVersions := []gin.HandlerFunc{opts.PromptHandler.ListPromptVersions}
+		if opts.ResponseCache != nil {
+			listPrompts = append([]gin.HandlerFunc{middleware.CacheResponses(opts.ResponseCache, opts.ResponseCacheTTL, promptListCacheKey)}, listPrompts...)
+			listVersions = append([]gin.HandlerFunc{middleware.CacheResponses(opts.ResponseCache, opts.ResponseCacheTTL, promptVersionsCacheKey)}, listVersions...)
+		}
+		readGroup.GET("", listPrompts...)
+		readGroup.GET("/", listPrompts...)
+		readGroup.GET("/locales/coverage", opts.PromptHandler.GetLocaleCoverage)
+		readGroup.GET("/:id", opts.PromptHandler.GetPrompt)
+		readGroup.GET("/:id/versions", listVersions...)
+		readGroup.GET("/:id/versions/compare", opts.PromptHandler.CompareVersions)
+		readGroup.GET("/:id/blame", opts.PromptHandler.BlamePrompt)
+		readGroup.GET("/:id/activations", opts.PromptHandler.ListActivations)
+		readGroup.GET("/:id/draft", opts.PromptHandler.GetPromptDraft)
+		readGroup.GET("/:id/review-policy", opts.PromptHandler.GetReviewPolicy)
+		readGroup.GET("/:id/versions/:versionId/review", opts.PromptHandler.GetVersionReview)
+		readGroup.GET("/:id/canary", opts.PromptHandler.GetCanary)
+		readGroup.GET("/:id/versions/:versionId/diff", opts.PromptHandler.DiffPromptVersion)
+		readGroup.GET("/:id/versions/:versionId/preview", opts.PromptHandler.PreviewPromptVersion)
+		promptGroup.POST("/:id/render", middleware.RequireScopes(domain.APIKeyScopeRender), opts.PromptHandler.RenderPrompt)
+		promptGroup.POST("/:id/versions/:versionId/diff/rendered", middleware.RequireScopes(domain.APIKeyScopeRender), opts.PromptHandler.DiffRenderedVersions)
+		readGroup.GET("/:id/versions/:versionId/locales", opts.PromptHandler.ListVersionLocales)
+		readGroup.GET("/:id/stats", opts.PromptHandler.GetPromptStats)
+		readGroup.GET("/:id/executions", opts.PromptHandler.ListExecutionLogs)
+		readGroup.GET("/:id/alerts", opts.PromptHandler.ListAlertRules)
+		readGroup.GET("/:id/dependencies", opts.PromptHandler.ListPromptDependencies)
+		readGroup.GET("/:id/dependents", opts.PromptHandler.ListPromptDependents)
+
+		// Write operations - no role restriction in single-user mode;
+		// 启用工作区后按工作区角色限制，viewer 只读。
+		writeGroup := promptGroup.Group("", middleware.RequireScopes(domain.APIKeyScopeWrite))
+		if opts.WorkspaceResolver != nil {
+			writeGroup.Use(middleware.RequireRoles(middleware.RoleAdmin, middleware.RoleEditor))
+		}
 		writeGroup.POST("", opts.PromptHandler.CreatePrompt)
 		writeGroup.POST("/", opts.PromptHandler.CreatePrompt)
 		writeGroup.PUT("/:id", opts.PromptHandler.UpdatePrompt)
 		writeGroup.PATCH("/:id", opts.PromptHandler.UpdatePrompt)
 		writeGroup.POST("/:id/versions", opts.PromptHandler.CreatePromptVersion)
+		writeGroup.POST("/:id/versions/upload", opts.PromptHandler.UploadPromptVersion)
+		writeGroup.POST("/:id/versions/validate", opts.PromptHandler.ValidatePromptVersion)
 		writeGroup.POST("/:id/versions/:versionId/activate", opts.PromptHandler.SetActiveVersion)
+		writeGroup.POST("/:id/activate-previous", opts.PromptHandler.ActivatePreviousVersion)
+		writeGroup.POST("/:id/versions/:versionId/canary", opts.PromptHandler.StartCanary)
+		writeGroup.POST("/:id/canary/promote", opts.PromptHandler.PromoteCanary)
+		writeGroup.DELETE("/:id/canary", opts.PromptHandler.AbortCanary)
+		writeGroup.PATCH("/:id/versions/:versionId/status", opts.PromptHandler.UpdateVersionStatus)
+		writeGroup.PUT("/:id/versions/:versionId/locales/:locale", opts.PromptHandler.SetVersionLocale)
+		writeGroup.DELETE("/:id/versions/:versionId/locales/:locale", opts.PromptHandler.DeleteVersionLocale)
+		writeGroup.PUT("/:id/dependencies", opts.PromptHandler.SetPromptDependencies)
+		writeGroup.PUT("/:id/draft", opts.PromptHandler.SavePromptDraft)
+		writeGroup.DELETE("/:id/draft", opts.PromptHandler.DeletePromptDraft)
+		writeGroup.POST("/:id/lock", opts.PromptHandler.AcquireEditLock)
+		writeGroup.PUT("/:id/review-policy", opts.PromptHandler.SetReviewPolicy)
+		writeGroup.DELETE("/:id/review-policy", opts.PromptHandler.DeleteReviewPolicy)
+		writeGroup.POST("/:id/versions/:versionId/approve", opts.PromptHandler.ApproveVersion)
+		writeGroup.DELETE("/:id/versions/:versionId/approve", opts.PromptHandler.RevokeVersionApproval)
+		writeGroup.DELETE("/:id/lock", opts.PromptHandler.ReleaseEditLock)
+		writeGroup.POST("/:id/alerts", opts.PromptHandler.CreateAlertRule)
+		writeGroup.DELETE("/:id/alerts/:alertId", opts.PromptHandler.DeleteAlertRule)
+		writeGroup.POST("/:id/alerts/:alertId/webhook-secret/rotate", opts.PromptHandler.RotateAlertWebhookSecret)
+		writeGroup.POST("/:id/alerts/:alertId/test", opts.PromptHandler.TestAlertWebhook)
 		writeGroup.DELETE("/:id", opts.PromptHandler.DeletePrompt)
 		writeGroup.POST("/:id/restore", opts.PromptHandler.RestorePrompt)
+		writeGroup.POST("/:id/archive", opts.PromptHandler.ArchivePrompt)
+		writeGroup.POST("/:id/owner", opts.PromptHandler.TransferPromptOwner)
+		writeGroup.POST("/:id/unarchive", opts.PromptHandler.UnarchivePrompt)
+		writeGroup.POST("/import", opts.PromptHandler.ImportPrompts)
+		writeGroup.POST("/import/archive", opts.PromptHandler.ImportPromptArchive)
+
+		templateGroup := api.Group("/prompt-templates", authGuard)
+		templateGroup.GET("", opts.PromptHandler.ListPromptTemplates)
+		templateGroup.GET("/:slug", opts.PromptHandler.GetPromptTemplate)
+		templateAdminGroup := templateGroup.Group("", middleware.RequireRoles(middleware.RoleAdmin))
+		templateAdminGroup.POST("", opts.PromptHandler.CreatePromptTemplate)
+		templateAdminGroup.PUT("/:slug", opts.PromptHandler.UpdatePromptTemplate)
+		templateAdminGroup.DELETE("/:slug", opts.PromptHandler.DeletePromptTemplate)
+
+		// 外部执行结果上报，支持 API Key（需 execute 范围），Prompt 按当前工作区校验。
+		executionGroup := api.Group("/executions")
+		executionGroup.Use(integrationGuards...)
+		executionGroup.Use(workspaceScoped()...)
+		executionGroup.POST("/batch", middleware.RequireScopes(domain.APIKeyScopeExecute), opts.PromptHandler.RecordExecutionBatch)
+		executionGroup.POST("/:id/replay", middleware.RequireScopes(domain.APIKeyScopeExecute), opts.PromptHandler.ReplayExecution)
+
+		exportGroup := api.Group("/export")
+		exportGroup.Use(integrationGuards...)
+		exportGroup.Use(workspaceScoped()...)
+		exportGroup.GET("", middleware.RequireScopes(domain.APIKeyScopeRead), opts.PromptHandler.ExportWorkspace)
+
+		auditGroup := api.Group("/audit", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		auditGroup.GET("/verify", opts.PromptHandler.VerifyAuditChain)
+		auditGroup.GET("/export", opts.PromptHandler.ExportAuditLogs)
+	}
+
+	if opts.AuditHandler != nil {
+		auditLogGroup := api.Group("/audit/logs", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		opts.AuditHandler.RegisterRoutes(auditLogGroup)
+	}
+
+	if opts.WorkspaceHandler != nil {
+		workspaceGroup := api.Group("/workspaces", authGuard)
+		opts.WorkspaceHandler.RegisterRoutes(workspaceGroup)
+
+		organizationGroup := api.Group("/organizations", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		opts.WorkspaceHandler.RegisterOrganizationRoutes(organizationGroup)
+	}
+
+	if opts.MeteringHandler != nil {
+		meteringGroup := api.Group("/admin/metering", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		opts.MeteringHandler.RegisterRoutes(meteringGroup)
+		storageGroup := api.Group("/admin/storage", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		opts.MeteringHandler.RegisterStorageRoutes(storageGroup)
+	}
+
+	if opts.AnnouncementHandler != nil {
+		api.GET("/announcements", opts.AnnouncementHandler.ListActiveAnnouncements)
+		announcementGroup := api.Group("/admin/announcements", authGuard, middleware.RequireRoles(middleware.RoleAdmin))
+		opts.AnnouncementHandler.RegisterAdminRoutes(announcementGroup)
+	}
+
+	if opts.TelemetryHandler != nil {
+		api.GET("/admin/telemetry/preview", authGuard, middleware.RequireRoles(middleware.RoleAdmin), opts.TelemetryHandler.Preview)
+	}
+
+	if opts.ModelHandler != nil {
+		api.GET("/models", authGuard, opts.ModelHandler.ListModels)
+	}
+
+	if opts.StatusHandler != nil {
+		api.GET("/status", opts.StatusHandler.GetStatus)
+	}
+
+	if opts.GitSyncHandler != nil {
+		api.POST("/webhooks/github", opts.GitSyncHandler.HandleGitHub)
+	}
+
+	if opts.SlackHandler != nil {
+		api.POST("/integrations/slack/commands", opts.SlackHandler.HandleCommand)
+	}
+
+	if opts.PipelineHandler != nil {
+		pipelineGroup := api.Group("/pipelines")
+		pipelineGroup.Use(integrationGuards...)
+		pipelineGroup.Use(workspaceScoped()...)
+		opts.PipelineHandler.RegisterRoutes(pipelineGroup)
 	}
 
 	logger.Info("http router ready", zap.String("env", cfg.App.Env))
@@ -113,6 +367,7 @@ func NewEngine(cfg *config.Config, logger *zap.Logger, opts RouterOptions) *gin.
 	return engine
 }
 
+// defaultHealthHandler 汇总数据库与 Redis 的健康状态；?verbose=1 时为每个依赖附带本次检查耗时 latency_ms。
 func defaultHealthHandler(cfg *config.Config, deps *HealthDependencies) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		httpStatus := http.StatusOK
@@ -121,35 +376,48 @@ func defaultHealthHandler(cfg *config.Config, deps *HealthDependencies) gin.Hand
 			"service": cfg.App.Name,
 			"env":     cfg.App.Env,
 		}
+		verbose, _ := strconv.ParseBool(ctx.Query("verbose"))
+
+		// probe 执行一次依赖检查，verbose 模式下记录耗时。
+		probe := func(check func(context.Context) error) gin.H {
+			start := time.Now()
+			err := check(ctx.Request.Context())
+			entry := gin.H{"status": "ok"}
+			if err != nil {
+				entry = gin.H{"status": "error", "error": err.Error()}
+			}
+			if verbose {
+				entry["latency_ms"] = float64(time.Since(start).Microseconds()) / 1000
+			}
+			return entry
+		}
 
 		if deps != nil {
 			dependencies := gin.H{}
 			if deps.DB != nil {
-				if err := database.Health(ctx.Request.Context(), deps.DB); err != nil {
+				entry := probe(func(c context.Context) error { return database.Health(c, deps.DB) })
+				if entry["status"] != "ok" {
 					httpStatus = http.StatusServiceUnavailable
 					result["status"] = "degraded"
-					dependencies["database"] = gin.H{
-						"status": "error",
-						"error":  err.Error(),
-					}
-				} else {
-					dependencies["database"] = gin.H{"status": "ok"}
 				}
+				dependencies["database"] = entry
+			} else if deps.MemoryStore {
+				dependencies["database"] = gin.H{"status": "ok", "driver": config.DatabaseDriverMemory}
 			} else {
 				dependencies["database"] = gin.H{"status": "missing"}
 			}
 
+			// Redis 仅支撑缓存、编辑锁等辅助功能，不可用时核心 Prompt 接口照常工作：
+			// 只标记 degraded，不返回 503，避免负载均衡器摘除实例。
 			if deps.Redis != nil {
-				if err := cache.Health(ctx.Request.Context(), deps.Redis); err != nil {
-					httpStatus = http.StatusServiceUnavailable
+				entry := probe(func(c context.Context) error { return cache.Health(c, deps.Redis) })
+				if entry["status"] != "ok" {
 					result["status"] = "degraded"
-					dependencies["redis"] = gin.H{
-						"status": "error",
-						"error":  err.Error(),
-					}
-				} else {
-					dependencies["redis"] = gin.H{"status": "ok"}
 				}
+				dependencies["redis"] = entry
+			} else if deps.RedisDegraded {
+				result["status"] = "degraded"
+				dependencies["redis"] = gin.H{"status": "unavailable"}
 			} else {
 				dependencies["redis"] = gin.H{"status": "missing"}
 			}
@@ -161,16 +429,110 @@ func defaultHealthHandler(cfg *config.Config, deps *HealthDependencies) gin.Hand
 	}
 }
 
+// configDumpHandler 返回脱敏后的生效配置，用于核对环境变量与 Secret 文件引用是否按预期解析。
+func configDumpHandler(cfg *config.Config) gin.HandlerFunc {
+	redacted := config.Redacted(cfg)
+	return func(ctx *gin.Context) {
+		httpx.RespondOK(ctx, redacted)
+	}
+}
+
+// bodyLimitFor 按匹配到的路由分组返回请求体上限：流水线调用、导入导出、认证与 Prompt 各自独立，其余沿用 maxRequestBody。
+// 路由在中间件执行前已完成匹配，因此可直接使用 FullPath 判断分组。
+func bodyLimitFor(serverCfg config.ServerConfig) func(ctx *gin.Context) int64 {
+	limits := serverCfg.BodyLimits
+	pick := func(limit int64) int64 {
+		if limit > 0 {
+			return limit
+		}
+		return serverCfg.MaxRequestBody
+	}
+	return func(ctx *gin.Context) int64 {
+		path := ctx.FullPath()
+		switch {
+		case strings.HasPrefix(path, "/api/v1/pipelines/") && strings.HasSuffix(path, "/invoke"):
+			return pick(limits.Invoke)
+		case strings.HasPrefix(path, "/api/v1/prompts/import"), strings.HasPrefix(path, "/api/v1/export"), strings.HasPrefix(path, "/api/v1/webhooks/"):
+			return pick(limits.ImportExport)
+		case strings.HasPrefix(path, "/api/v1/auth"), strings.HasPrefix(path, "/api/v1/me"):
+			return pick(limits.Auth)
+		case strings.HasPrefix(path, "/api/v1/prompts"):
+			return pick(limits.Prompts)
+		default:
+			return serverCfg.MaxRequestBody
+		}
+	}
+}
+
+// corsMiddleware 按请求路径选择跨域策略：匹配最长的覆盖前缀，未匹配时使用全局配置。
+// 预检请求不会命中具体路由，因此在引擎级统一分发，而非挂载到各路由分组。
+func corsMiddleware(serverCfg config.ServerConfig) gin.HandlerFunc {
+	base := cors.New(buildCORSConfig(serverCfg))
+	if len(serverCfg.CORS.Overrides) == 0 {
+		return base
+	}
+
+	type prefixHandler struct {
+		prefix  string
+		handler gin.HandlerFunc
+	}
+	overrides := make([]prefixHandler, 0, len(serverCfg.CORS.Overrides))
+	for _, override := range serverCfg.CORS.Overrides {
+		overrides = append(overrides, prefixHandler{
+			prefix:  strings.TrimRight(override.PathPrefix, "/"),
+			handler: cors.New(buildCORSOverrideConfig(serverCfg.CORS, override)),
+		})
+	}
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return len(overrides[i].prefix) > len(overrides[j].prefix)
+	})
+
+	return func(ctx *gin.Context) {
+		path := ctx.Request.URL.Path
+		for _, override := range overrides {
+			if path == override.prefix || strings.HasPrefix(path, override.prefix+"/") {
+				override.handler(ctx)
+				return
+			}
+		}
+		base(ctx)
+	}
+}
+
 func buildCORSConfig(serverCfg config.ServerConfig) cors.Config {
+	return newCORSConfig(serverCfg.CORS.AllowOrigins, serverCfg.CORS.AllowCredentials, serverCfg.CORS.AllowHeaders, serverCfg.CORS.MaxAge)
+}
+
+func buildCORSOverrideConfig(base config.CORSConfig, override config.CORSOverrideConfig) cors.Config {
+	headers := append(append([]string{}, base.AllowHeaders...), override.AllowHeaders...)
+	maxAge := override.MaxAge
+	if maxAge <= 0 {
+		maxAge = base.MaxAge
+	}
+	return newCORSConfig(override.AllowOrigins, override.AllowCredentials, headers, maxAge)
+}
+
+func newCORSConfig(origins []string, allowCredentials bool, extraHeaders []string, maxAge time.Duration) cors.Config {
+	if maxAge <= 0 {
+		maxAge = 12 * time.Hour
+	}
 	config := cors.Config{
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
-		AllowCredentials: serverCfg.CORS.AllowCredentials,
-		MaxAge:           12 * time.Hour,
+		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders: []string{"Authorization", "Content-Type", middleware.WorkspaceHeader},
+		ExposeHeaders: []string{
+			"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After",
+		},
+		AllowCredentials: allowCredentials,
+		MaxAge:           maxAge,
+	}
+	for _, header := range extraHeaders {
+		if clean := strings.TrimSpace(header); clean != "" {
+			config.AllowHeaders = append(config.AllowHeaders, clean)
+		}
 	}
 
-	exactOrigins, patternOrigins, allowAll := classifyAllowedOrigins(serverCfg.CORS.AllowOrigins)
+	exactOrigins, patternOrigins, allowAll := classifyAllowedOrigins(origins)
 	switch {
 	case allowAll:
 		config.AllowAllOrigins = true
@@ -227,3 +589,14 @@ func classifyAllowedOrigins(origins []string) (exact []string, patterns []*regex
 	}
 	return exact, patterns, false
 }
+
+// probeAllowlist 将配置转换为探测白名单，网段已在加载配置时校验。
+func probeAllowlist(cfg config.ProbesConfig) middleware.ProbeAllowlist {
+	allowlist := middleware.ProbeAllowlist{Paths: cfg.Paths}
+	for _, cidr := range cfg.TrustedCIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			allowlist.TrustedNetworks = append(allowlist.TrustedNetworks, network)
+		}
+	}
+	return allowlist
+}
@@ -1,12 +1,26 @@
 package http
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zacharykka/prompt-manager/internal/config"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/server/http/mocks"
+	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
+	gitsyncsvc "github.com/zacharykka/prompt-manager/internal/service/gitsync"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +35,11 @@ func TestBuildCORSConfigExactOrigins(t *testing.T) {
 	if len(corsCfg.AllowOrigins) != 1 || corsCfg.AllowOrigins[0] != "https://app.example.com" {
 		t.Fatalf("expected exact allow origins, got %+v", corsCfg.AllowOrigins)
 	}
+	for _, header := range []string{"X-RateLimit-Remaining", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"} {
+		if !slices.Contains(corsCfg.ExposeHeaders, header) {
+			t.Fatalf("expected %s to be exposed, got %v", header, corsCfg.ExposeHeaders)
+		}
+	}
 }
 
 func TestBuildCORSConfigAllowsWildcardPattern(t *testing.T) {
@@ -49,6 +68,53 @@ func TestBuildCORSConfigAllowAll(t *testing.T) {
 	}
 }
 
+func TestCORSOverridesByPathPrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		App: config.AppConfig{Name: "test", Env: "test"},
+		Server: config.ServerConfig{
+			CORS: config.CORSConfig{
+				AllowOrigins: []string{"https://admin.example.com"},
+				AllowHeaders: []string{"X-Request-ID"},
+				MaxAge:       time.Hour,
+				Overrides: []config.CORSOverrideConfig{
+					{PathPrefix: "/api/v1/public", AllowOrigins: []string{"*"}, MaxAge: 24 * time.Hour},
+				},
+			},
+		},
+	}
+	router := NewEngine(cfg, zapLoggerForTest(t), RouterOptions{})
+
+	preflight := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", "https://gallery.example.org")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	public := preflight("/api/v1/public/prompts")
+	if got := public.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("expected public prefix to allow any origin, got %q", got)
+	}
+	if got := public.Header().Get("Access-Control-Max-Age"); got != "86400" {
+		t.Fatalf("expected override max age, got %q", got)
+	}
+	if got := public.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(got, "X-Request-Id") {
+		t.Fatalf("expected global extra header to be inherited, got %q", got)
+	}
+
+	admin := preflight("/api/v1/prompts")
+	if got := admin.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected management API to reject unknown origin, got %q", got)
+	}
+	if admin.Code != http.StatusForbidden {
+		t.Fatalf("expected forbidden preflight for unknown origin, got %d", admin.Code)
+	}
+}
+
 func TestSecurityHeadersIntegration(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -102,7 +168,262 @@ func TestRouterRegistersPromptRestoreRoute(t *testing.T) {
 	}
 }
 
+func TestRouterRegistersAlertWebhookRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		App:    config.AppConfig{Name: "test", Env: "test"},
+		Auth:   config.AuthConfig{AccessTokenSecret: "secret"},
+		Server: config.ServerConfig{CORS: config.CORSConfig{AllowOrigins: []string{"*"}}},
+	}
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockPromptService(ctrl)
+	service.EXPECT().RotateAlertWebhookSecret(gomock.Any(), "p1", "a1", gomock.Any()).Return(&domain.PromptAlertRule{ID: "a1", PromptID: "p1"}, nil)
+	service.EXPECT().TestAlertWebhook(gomock.Any(), "p1", "a1").Return(nil)
+	router := NewEngine(cfg, zapLoggerForTest(t), RouterOptions{PromptHandler: NewPromptHandler(service)})
+
+	token, err := authutil.GenerateToken("secret", time.Minute, authutil.Claims{UserID: "user", Role: "admin", TokenType: "access"})
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	for _, path := range []string{
+		"/api/v1/prompts/p1/alerts/a1/webhook-secret/rotate",
+		"/api/v1/prompts/p1/alerts/a1/test",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRouterGitHubWebhookRequiresSignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		App:    config.AppConfig{Name: "test", Env: "test"},
+		Auth:   config.AuthConfig{AccessTokenSecret: "secret"},
+		Server: config.ServerConfig{CORS: config.CORSConfig{AllowOrigins: []string{"*"}}},
+	}
+	service := gitsyncsvc.NewService(nil, "acme/prompts", "hook-secret")
+	router := NewEngine(cfg, zapLoggerForTest(t), RouterOptions{GitSyncHandler: NewGitSyncHandler(service)})
+
+	body := `{"zen":"Keep it logically awesome."}`
+	send := func(signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "ping")
+		req.Header.Set(gitsyncsvc.SignatureHeader, signature)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("sha256=00"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad signature, got %d", w.Code)
+	}
+	mac := hmac.New(sha256.New, []byte("hook-secret"))
+	mac.Write([]byte(body))
+	if w := send("sha256=" + hex.EncodeToString(mac.Sum(nil))); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"pong":true`) {
+		t.Fatalf("expected pong, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRouterServesPublicStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		App:    config.AppConfig{Name: "test", Env: "test"},
+		Auth:   config.AuthConfig{AccessTokenSecret: "secret"},
+		Server: config.ServerConfig{CORS: config.CORSConfig{AllowOrigins: []string{"*"}}},
+	}
+	handler := NewStatusHandler(map[string]bool{"slack": true, "metering": false})
+	handler.startedAt = time.Now().Add(-90 * time.Second)
+	router := NewEngine(cfg, zapLoggerForTest(t), RouterOptions{StatusHandler: handler})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected public status, got %d", w.Code)
+	}
+	var resp struct {
+		Data struct {
+			Version       string          `json:"version"`
+			GoVersion     string          `json:"go_version"`
+			UptimeSeconds int64           `json:"uptime_seconds"`
+			Features      map[string]bool `json:"features"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if resp.Data.Version == "" || resp.Data.GoVersion == "" || resp.Data.UptimeSeconds < 90 {
+		t.Fatalf("unexpected build info %+v", resp.Data)
+	}
+	if !resp.Data.Features["slack"] || resp.Data.Features["metering"] {
+		t.Fatalf("unexpected features %v", resp.Data.Features)
+	}
+}
+
+func TestRouterServesJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		App: config.AppConfig{Name: "test", Env: "test"},
+		Auth: config.AuthConfig{
+			AccessTokenSecret: "secret",
+		},
+		Server: config.ServerConfig{
+			CORS: config.CORSConfig{AllowOrigins: []string{"*"}},
+		},
+	}
+
+	service := authsvc.NewService(nil, cfg.Auth)
+	key, err := authutil.GenerateSigningKey("kid-1", authutil.AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	service.SigningKeys().Replace(key, nil)
+
+	router := NewEngine(cfg, zapLoggerForTest(t), RouterOptions{
+		AuthHandler: NewAuthHandler(service),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	var set authutil.JWKSet
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("unmarshal jwks: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != "kid-1" || set.Keys[0].Curve != "Ed25519" {
+		t.Fatalf("unexpected jwks %+v", set)
+	}
+}
+
 func zapLoggerForTest(t *testing.T) *zap.Logger {
 	t.Helper()
 	return zap.NewNop()
 }
+
+func TestBodyLimitForRouteGroups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serverCfg := config.ServerConfig{
+		MaxRequestBody: 100,
+		BodyLimits:     config.BodyLimitsConfig{Auth: 10, ImportExport: 1000, Invoke: 50},
+	}
+	limitFor := bodyLimitFor(serverCfg)
+
+	engine := gin.New()
+	var got int64
+	record := func(ctx *gin.Context) { got = limitFor(ctx) }
+	engine.POST("/api/v1/auth/login", record)
+	engine.POST("/api/v1/prompts/:id", record)
+	engine.POST("/api/v1/prompts/import/archive", record)
+	engine.POST("/api/v1/pipelines/:id/invoke", record)
+	engine.POST("/api/v1/pipelines/:id", record)
+	engine.POST("/api/v1/webhooks/github", record)
+
+	cases := map[string]int64{
+		"/api/v1/auth/login":             10,
+		"/api/v1/prompts/abc":            100,
+		"/api/v1/prompts/import/archive": 1000,
+		"/api/v1/pipelines/p1/invoke":    50,
+		"/api/v1/pipelines/p1":           100,
+		"/api/v1/webhooks/github":        1000,
+	}
+	for path, want := range cases {
+		got = 0
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+		if got != want {
+			t.Fatalf("%s: expected limit %d got %d", path, want, got)
+		}
+	}
+}
+
+func TestHealthReportsDegradedRedisWithoutFailing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		App:    config.AppConfig{Name: "test", Env: "test"},
+		Server: config.ServerConfig{CORS: config.CORSConfig{AllowOrigins: []string{"*"}}},
+	}
+	router := NewEngine(cfg, zapLoggerForTest(t), RouterOptions{
+		HealthDeps: &HealthDependencies{RedisDegraded: true, MemoryStore: true},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("degraded redis should not fail health check, got %d", w.Code)
+	}
+	var body struct {
+		Status       string                       `json:"status"`
+		Dependencies map[string]map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode health response: %v", err)
+	}
+	if body.Status != "degraded" || body.Dependencies["redis"]["status"] != "unavailable" {
+		t.Fatalf("unexpected health response %s", w.Body.String())
+	}
+	if db := body.Dependencies["database"]; db["status"] != "ok" || db["driver"] != "memory" {
+		t.Fatalf("expected in-memory database to be reported healthy, got %s", w.Body.String())
+	}
+}
+
+func TestHealthVerboseReportsDependencyLatency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := sql.Open("sqlite", "file:health_verbose?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	cfg := &config.Config{
+		App:    config.AppConfig{Name: "test", Env: "test"},
+		Server: config.ServerConfig{CORS: config.CORSConfig{AllowOrigins: []string{"*"}}},
+	}
+	deps := &HealthDependencies{DB: db}
+	router := NewEngine(cfg, zapLoggerForTest(t), RouterOptions{HealthDeps: deps})
+
+	type healthBody struct {
+		Status       string                            `json:"status"`
+		Dependencies map[string]map[string]interface{} `json:"dependencies"`
+	}
+	get := func(path string) (int, healthBody) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body healthBody
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode health response: %v", err)
+		}
+		return w.Code, body
+	}
+
+	code, body := get("/healthz")
+	if _, ok := body.Dependencies["database"]["latency_ms"]; code != http.StatusOK || ok {
+		t.Fatalf("expected latency only in verbose mode, got %d %+v", code, body)
+	}
+	code, body = get("/healthz?verbose=1")
+	if latency, ok := body.Dependencies["database"]["latency_ms"].(float64); code != http.StatusOK || !ok || latency < 0 {
+		t.Fatalf("expected database latency in verbose mode, got %d %+v", code, body)
+	}
+
+	// 检查失败时同样报告耗时，并返回 503。
+	_ = db.Close()
+	code, body = get("/healthz?verbose=true")
+	database := body.Dependencies["database"]
+	if _, ok := database["latency_ms"]; code != http.StatusServiceUnavailable || database["status"] != "error" || !ok {
+		t.Fatalf("expected failing database with latency, got %d %+v", code, body)
+	}
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+	"github.com/zacharykka/prompt-manager/pkg/audit"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+)
+
+//go:generate mockgen -source=services.go -destination=mocks/services.go -package=mocks
+
+// PromptService 为 PromptHandler 依赖的 Prompt 服务能力，由 *promptsvc.Service 实现；
+// Handler 测试可注入 mocks.MockPromptService，无需真实数据库。
+type PromptService interface {
+	AbortCanary(ctx context.Context, promptID string, abortedBy string) (*domain.PromptCanary, error)
+	AcquireEditLock(ctx context.Context, promptID string, holderID string, holder string) (*domain.EditLock, error)
+	ActivatePreviousVersion(ctx context.Context, input promptsvc.ActivateVersionInput) (*promptsvc.ActivationResult, error)
+	ActivateVersion(ctx context.Context, input promptsvc.ActivateVersionInput) (*promptsvc.ActivationResult, error)
+	ApproveVersion(ctx context.Context, input promptsvc.ApproveVersionInput) (*promptsvc.VersionReview, error)
+	ArchivePrompt(ctx context.Context, promptID string, archivedBy string) (*domain.Prompt, error)
+	BlamePrompt(ctx context.Context, promptID string, versionID string) (*promptsvc.PromptBlame, error)
+	CompareVersions(ctx context.Context, promptID string, versionIDs []string) (*promptsvc.VersionComparison, error)
+	CreateAlertRule(ctx context.Context, input promptsvc.CreateAlertRuleInput) (*domain.PromptAlertRule, error)
+	CreatePrompt(ctx context.Context, input promptsvc.CreatePromptInput) (*domain.Prompt, error)
+	CreatePromptFromTemplate(ctx context.Context, input promptsvc.CreatePromptFromTemplateInput) (*domain.Prompt, error)
+	CreatePromptVersion(ctx context.Context, input promptsvc.CreatePromptVersionInput) (*domain.PromptVersion, error)
+	CreateTemplate(ctx context.Context, input promptsvc.PromptTemplateInput) (*domain.PromptTemplate, error)
+	DeleteAlertRule(ctx context.Context, promptID string, ruleID string) error
+	DeleteDraft(ctx context.Context, promptID string, userID string) error
+	DeletePrompt(ctx context.Context, promptID string, deletedBy string) error
+	DeleteReviewPolicy(ctx context.Context, promptID string, by promptsvc.ReviewActor) error
+	DeleteTemplate(ctx context.Context, slug string) error
+	DeleteVersionLocale(ctx context.Context, promptID string, versionID string, rawLocale string, deletedBy string) error
+	DiffPromptVersion(ctx context.Context, promptID string, baseVersionID string, opts promptsvc.DiffPromptVersionOptions) (*promptsvc.PromptVersionDiff, error)
+	DiffRenderedVersions(ctx context.Context, input promptsvc.RenderedDiffInput) (*promptsvc.RenderedVersionDiff, error)
+	ExportArchive(ctx context.Context, zw *zip.Writer, opts promptsvc.ExportArchiveOptions) (*promptsvc.ExportManifest, error)
+	GetCanary(ctx context.Context, promptID string) (*domain.PromptCanary, error)
+	GetDraft(ctx context.Context, promptID string, userID string) (*promptsvc.PromptDraftView, error)
+	GetEditLock(ctx context.Context, promptID string) (*domain.EditLock, error)
+	GetExecutionStats(ctx context.Context, promptID string, opts promptsvc.ExecutionStatsOptions) ([]*domain.PromptExecutionAggregate, error)
+	GetLocaleCoverage(ctx context.Context, requested string) (*promptsvc.LocaleCoverageReport, error)
+	GetPrompt(ctx context.Context, promptID string) (*domain.Prompt, error)
+	GetPromptWithLocale(ctx context.Context, promptID string, requested string) (*domain.Prompt, string, error)
+	GetReviewPolicy(ctx context.Context, promptID string) (*domain.PromptReviewPolicy, error)
+	GetTemplate(ctx context.Context, slug string) (*domain.PromptTemplate, error)
+	GetVersionReview(ctx context.Context, promptID string, versionID string) (*promptsvc.VersionReview, error)
+	ImportArchive(ctx context.Context, r io.ReaderAt, size int64, opts promptsvc.ImportOptions) (*promptsvc.ImportReport, error)
+	ImportCSV(ctx context.Context, r io.Reader, opts promptsvc.ImportOptions) (*promptsvc.ImportReport, error)
+	IterateAuditLogs(ctx context.Context, opts domain.AuditLogIterateOptions, fn func(*domain.PromptAuditLog) error) error
+	IterateExecutionLogs(ctx context.Context, promptID string, days int, fn func(*domain.PromptExecutionLog) error) error
+	IterateVersions(ctx context.Context, promptID string, status string, fn func(*domain.PromptVersion) error) error
+	ListActivations(ctx context.Context, promptID string) ([]promptsvc.ActivationEvent, error)
+	ListAlertRules(ctx context.Context, promptID string) ([]*domain.PromptAlertRule, error)
+	ListExecutionLogs(ctx context.Context, promptID string, limit int) ([]*domain.PromptExecutionLog, error)
+	ListPromptDependencies(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error)
+	ListPromptDependents(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error)
+	ListPromptVersionsEx(ctx context.Context, promptID string, limit int, offset int, status string) (*promptsvc.PromptVersionPage, error)
+	ListPromptsPage(ctx context.Context, opts promptsvc.ListPromptsOptions) (*promptsvc.PromptPage, error)
+	ListTemplates(ctx context.Context) ([]*domain.PromptTemplate, error)
+	ListVersionLocales(ctx context.Context, promptID string, versionID string) ([]*domain.PromptVersionLocale, error)
+	PreviewPromptVersion(ctx context.Context, promptID string, versionID string, format string) (*promptsvc.PromptVersionPreview, error)
+	PromoteCanary(ctx context.Context, input promptsvc.ActivateVersionInput) (*promptsvc.ActivationResult, error)
+	RecordExecutions(ctx context.Context, inputs []promptsvc.ExecutionRecordInput, userID string) (*promptsvc.ExecutionBatchReport, error)
+	ReleaseEditLock(ctx context.Context, promptID string, holderID string) error
+	ReplayExecution(ctx context.Context, input promptsvc.ReplayExecutionInput) (*promptsvc.ExecutionReplay, error)
+	RenderPrompt(ctx context.Context, input promptsvc.RenderPromptInput) (*promptsvc.RenderResult, error)
+	RestorePrompt(ctx context.Context, promptID string, restoredBy string) (*domain.Prompt, error)
+	RevokeApproval(ctx context.Context, promptID string, versionID string, by promptsvc.ReviewActor) (*promptsvc.VersionReview, error)
+	RotateAlertWebhookSecret(ctx context.Context, promptID string, ruleID string, overlap time.Duration) (*domain.PromptAlertRule, error)
+	SaveDraft(ctx context.Context, input promptsvc.SaveDraftInput) (*promptsvc.PromptDraftView, error)
+	SetPromptDependencies(ctx context.Context, promptID string, refs []string, updatedBy string) ([]*domain.PromptDependencyLink, error)
+	SetReviewPolicy(ctx context.Context, input promptsvc.SetReviewPolicyInput) (*domain.PromptReviewPolicy, error)
+	SetVersionLocale(ctx context.Context, input promptsvc.SetVersionLocaleInput) (*domain.PromptVersionLocale, error)
+	StartCanary(ctx context.Context, input promptsvc.StartCanaryInput) (*domain.PromptCanary, error)
+	TestAlertWebhook(ctx context.Context, promptID string, ruleID string) error
+	TransferOwner(ctx context.Context, promptID string, owner *domain.PromptOwner, actor string) (*domain.Prompt, error)
+	TransitionVersionStatus(ctx context.Context, input promptsvc.TransitionVersionStatusInput) (*domain.PromptVersion, error)
+	UnarchivePrompt(ctx context.Context, promptID string, unarchivedBy string) (*domain.Prompt, error)
+	UpdatePrompt(ctx context.Context, input promptsvc.UpdatePromptInput) (*domain.Prompt, error)
+	UpdateTemplate(ctx context.Context, input promptsvc.PromptTemplateInput) (*domain.PromptTemplate, error)
+	ValidatePromptVersion(ctx context.Context, input promptsvc.CreatePromptVersionInput) (*promptsvc.ValidationReport, error)
+	VerifyAuditChain(ctx context.Context) (*audit.ChainReport, error)
+}
+
+// AuthService 为 AuthHandler、WorkspaceHandler 与 API Key 认证依赖的认证服务能力，由 *authsvc.Service 实现。
+type AuthService interface {
+	AcceptInvitation(ctx context.Context, token string, password string) (*authsvc.Tokens, *domain.User, error)
+	ApproveUser(ctx context.Context, userID string, actor string) (*domain.User, error)
+	ChangePassword(ctx context.Context, userID string, currentPassword string, newPassword string) error
+	CreateAPIKey(ctx context.Context, input authsvc.CreateAPIKeyInput) (*authsvc.CreatedAPIKey, error)
+	CreateInvitation(ctx context.Context, input authsvc.CreateInvitationInput) (*domain.Invitation, string, error)
+	DeactivateUser(ctx context.Context, userID string, transferToID string, actor string) (*authsvc.UserDeactivationResult, error)
+	GitHubAuthorizeURL(redirectURI string, responseMode string, clientOrigin string) (string, error)
+	HandleGitHubCallback(ctx context.Context, code string, state string, linkBinding string) (*authsvc.Tokens, *domain.User, string, string, string, error)
+	IdentityLinkURL(ctx context.Context, userID string, provider string, redirectURI string, responseMode string, clientOrigin string) (string, string, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]*domain.APIKey, error)
+	ListIdentities(ctx context.Context, userID string) ([]*domain.UserIdentity, error)
+	ListLoginEvents(ctx context.Context, userID string, limit int) ([]*domain.LoginEvent, error)
+	ListPendingInvitations(ctx context.Context) ([]*domain.Invitation, error)
+	ListPendingUsers(ctx context.Context) ([]*domain.User, error)
+	ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error)
+	Login(ctx context.Context, email string, password string) (*authsvc.Tokens, *domain.User, error)
+	MergeUsers(ctx context.Context, sourceID string, targetID string, actor string) (*authsvc.UserMergeResult, error)
+	PasswordPolicy() authutil.PasswordPolicy
+	Refresh(ctx context.Context, refreshToken string) (*authsvc.Tokens, *domain.User, error)
+	Register(ctx context.Context, email string, password string, role string) (*domain.User, error)
+	RevokeAPIKey(ctx context.Context, userID string, keyID string, actor string) error
+	RotateSigningKey(ctx context.Context, organizationID string, algorithm string, actor string) (*domain.SigningKey, error)
+	SigningKeys() *authutil.KeySet
+	SwitchWorkspace(ctx context.Context, userID string, workspaceID string) (*authsvc.Tokens, *domain.User, error)
+	AuthenticateAPIKey(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error)
+}
+
+var (
+	_ PromptService = (*promptsvc.Service)(nil)
+	_ AuthService   = (*authsvc.Service)(nil)
+)
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	slacksvc "github.com/zacharykka/prompt-manager/internal/service/slack"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// SlackHandler 处理 Slack 斜杠命令，响应体为 Slack 消息格式而非统一的 data 包装。
+type SlackHandler struct {
+	service *slacksvc.Service
+}
+
+// NewSlackHandler 创建 SlackHandler。
+func NewSlackHandler(service *slacksvc.Service) *SlackHandler {
+	return &SlackHandler{service: service}
+}
+
+// HandleCommand 校验 Slack 签名后执行命令；Slack 只展示 200 响应的内容，因此查询失败也以消息返回。
+func (h *SlackHandler) HandleCommand(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	if err := h.service.VerifyRequest(body, ctx.GetHeader(slacksvc.TimestampHeader), ctx.GetHeader(slacksvc.SignatureHeader)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	message, err := h.service.HandleCommand(ctx.Request.Context(), form.Get("text"))
+	if err != nil {
+		ctx.JSON(http.StatusOK, slacksvc.Message{ResponseType: "ephemeral", Text: "Prompt lookup failed, please try again later."})
+		return
+	}
+	ctx.JSON(http.StatusOK, message)
+}
+
+func (h *SlackHandler) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, slacksvc.ErrInvalidSignature), errors.Is(err, slacksvc.ErrRequestExpired):
+		httpx.RespondError(ctx, http.StatusUnauthorized, "INVALID_SIGNATURE", err.Error(), nil)
+	default:
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+	}
+}
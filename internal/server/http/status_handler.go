@@ -0,0 +1,39 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/pkg/buildinfo"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// StatusHandler 公开当前部署的构建信息、运行时长与可选功能开关，供运维与前端展示。
+type StatusHandler struct {
+	build     buildinfo.Info
+	features  map[string]bool
+	startedAt time.Time
+	now       func() time.Time
+}
+
+// NewStatusHandler 创建 StatusHandler，运行时长自创建时起算；features 为各可选功能是否启用。
+func NewStatusHandler(features map[string]bool) *StatusHandler {
+	return &StatusHandler{build: buildinfo.Get(), features: features, startedAt: time.Now(), now: time.Now}
+}
+
+// GetStatus 返回版本、提交、构建时间、运行时长与功能开关；不含任何配置值或业务数据，无需认证。
+func (h *StatusHandler) GetStatus(ctx *gin.Context) {
+	features := h.features
+	if features == nil {
+		features = map[string]bool{}
+	}
+	httpx.RespondOK(ctx, gin.H{
+		"version":        h.build.Version,
+		"commit":         h.build.Commit,
+		"build_date":     h.build.Date,
+		"go_version":     h.build.GoVersion,
+		"started_at":     h.startedAt.UTC(),
+		"uptime_seconds": int64(h.now().Sub(h.startedAt).Seconds()),
+		"features":       features,
+	})
+}
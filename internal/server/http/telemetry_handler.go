@@ -0,0 +1,33 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	telemetrysvc "github.com/zacharykka/prompt-manager/internal/service/telemetry"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// TelemetryHandler 提供匿名使用统计的本地预览。
+type TelemetryHandler struct {
+	reporter *telemetrysvc.Reporter
+}
+
+// NewTelemetryHandler 创建 TelemetryHandler。
+func NewTelemetryHandler(reporter *telemetrysvc.Reporter) *TelemetryHandler {
+	return &TelemetryHandler{reporter: reporter}
+}
+
+// Preview 返回是否启用、上报地址以及此刻将要发送的完整报告，未启用时同样可用。
+func (h *TelemetryHandler) Preview(ctx *gin.Context) {
+	report, err := h.reporter.Collect(ctx)
+	if err != nil {
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{
+		"enabled":  h.reporter.Enabled(),
+		"endpoint": h.reporter.Endpoint(),
+		"report":   report,
+	})
+}
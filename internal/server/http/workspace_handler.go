@@ -0,0 +1,255 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zacharykka/prompt-manager/internal/middleware"
+	authsvc "github.com/zacharykka/prompt-manager/internal/service/auth"
+	workspacesvc "github.com/zacharykka/prompt-manager/internal/service/workspace"
+	"github.com/zacharykka/prompt-manager/pkg/httpx"
+)
+
+// WorkspaceHandler 处理组织、工作区、成员管理与工作区切换请求。
+type WorkspaceHandler struct {
+	service *workspacesvc.Service
+	auth    AuthService
+}
+
+// NewWorkspaceHandler 创建 WorkspaceHandler，auth 用于切换工作区时签发新令牌。
+func NewWorkspaceHandler(service *workspacesvc.Service, auth AuthService) *WorkspaceHandler {
+	return &WorkspaceHandler{service: service, auth: auth}
+}
+
+// RegisterRoutes 注册工作区路由，调用方负责挂载认证校验。
+func (h *WorkspaceHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.ListWorkspaces)
+	rg.POST("/switch", h.SwitchWorkspace)
+	rg.GET("/:id/members", h.ListMembers)
+	rg.PUT("/:id/members/:userId", h.SetMemberRole)
+	rg.DELETE("/:id/members/:userId", h.RemoveMember)
+}
+
+// RegisterOrganizationRoutes 注册组织管理路由，调用方负责挂载管理员权限校验。
+func (h *WorkspaceHandler) RegisterOrganizationRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.ListOrganizations)
+	rg.POST("", h.CreateOrganization)
+	rg.POST("/:id/workspaces", h.CreateWorkspace)
+}
+
+type createOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=128"`
+	Slug string `json:"slug" binding:"max=63"`
+}
+
+type createWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,max=128"`
+	Slug string `json:"slug" binding:"max=63"`
+}
+
+type switchWorkspaceRequest struct {
+	WorkspaceID string `json:"workspace_id" binding:"required"`
+}
+
+type setMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ListWorkspaces 返回当前用户可切换的工作区及其在各工作区的角色。
+func (h *WorkspaceHandler) ListWorkspaces(ctx *gin.Context) {
+	items, err := h.service.ListWorkspacesForUser(ctx, ctx.GetString(middleware.UserContextKey), ctx.GetString(middleware.UserRoleContextKey))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": items})
+}
+
+// SwitchWorkspace 校验访问权限后签发选中目标工作区的新令牌。
+func (h *WorkspaceHandler) SwitchWorkspace(ctx *gin.Context) {
+	var req switchWorkspaceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	userID := ctx.GetString(middleware.UserContextKey)
+	workspaceID := strings.TrimSpace(req.WorkspaceID)
+	role, err := h.service.ResolveRole(ctx, userID, ctx.GetString(middleware.UserRoleContextKey), workspaceID)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	tokens, user, err := h.auth.SwitchWorkspace(ctx, userID, workspaceID)
+	if err != nil {
+		switch err {
+		case authsvc.ErrTokenInvalid:
+			httpx.RespondError(ctx, http.StatusUnauthorized, "TOKEN_INVALID", err.Error(), nil)
+		case authsvc.ErrUserDisabled:
+			httpx.RespondError(ctx, http.StatusForbidden, "USER_DISABLED", err.Error(), nil)
+		default:
+			httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+		}
+		return
+	}
+
+	httpx.RespondOK(ctx, gin.H{
+		"tokens":         tokens,
+		"user":           user,
+		"workspace_id":   workspaceID,
+		"workspace_role": role,
+	})
+}
+
+// ListMembers 返回工作区成员，仅工作区成员可查看。
+func (h *WorkspaceHandler) ListMembers(ctx *gin.Context) {
+	workspaceID := ctx.Param("id")
+	if _, err := h.service.ResolveRole(ctx, ctx.GetString(middleware.UserContextKey), ctx.GetString(middleware.UserRoleContextKey), workspaceID); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	items, err := h.service.ListMembers(ctx, workspaceID)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": items})
+}
+
+// SetMemberRole 添加成员或调整成员角色，需为全局管理员或该工作区管理员。
+func (h *WorkspaceHandler) SetMemberRole(ctx *gin.Context) {
+	var req setMemberRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+	workspaceID := ctx.Param("id")
+	if !h.requireWorkspaceAdmin(ctx, workspaceID) {
+		return
+	}
+
+	member, err := h.service.SetMemberRole(auditContext(ctx), workspaceID, ctx.Param("userId"), req.Role, actorFromContext(ctx))
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, member)
+}
+
+// RemoveMember 将用户移出工作区，需为全局管理员或该工作区管理员。
+func (h *WorkspaceHandler) RemoveMember(ctx *gin.Context) {
+	workspaceID := ctx.Param("id")
+	if !h.requireWorkspaceAdmin(ctx, workspaceID) {
+		return
+	}
+
+	if err := h.service.RemoveMember(auditContext(ctx), workspaceID, ctx.Param("userId"), actorFromContext(ctx)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"removed": true})
+}
+
+// ListOrganizations 返回全部组织。
+func (h *WorkspaceHandler) ListOrganizations(ctx *gin.Context) {
+	items, err := h.service.ListOrganizations(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, gin.H{"items": items})
+}
+
+// CreateOrganization 创建组织。
+func (h *WorkspaceHandler) CreateOrganization(ctx *gin.Context) {
+	var req createOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	org, err := h.service.CreateOrganization(auditContext(ctx), workspacesvc.CreateOrganizationInput{
+		Name:      req.Name,
+		Slug:      req.Slug,
+		CreatedBy: actorFromContext(ctx),
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, org)
+}
+
+// CreateWorkspace 在组织下创建工作区，创建者自动成为工作区管理员。
+func (h *WorkspaceHandler) CreateWorkspace(ctx *gin.Context) {
+	var req createWorkspaceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_PAYLOAD", err.Error(), nil)
+		return
+	}
+
+	workspace, err := h.service.CreateWorkspace(auditContext(ctx), workspacesvc.CreateWorkspaceInput{
+		OrganizationID: ctx.Param("id"),
+		Name:           req.Name,
+		Slug:           req.Slug,
+		OwnerUserID:    ctx.GetString(middleware.UserContextKey),
+		CreatedBy:      actorFromContext(ctx),
+	})
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	httpx.RespondOK(ctx, workspace)
+}
+
+func (h *WorkspaceHandler) requireWorkspaceAdmin(ctx *gin.Context, workspaceID string) bool {
+	role, err := h.service.ResolveRole(ctx, ctx.GetString(middleware.UserContextKey), ctx.GetString(middleware.UserRoleContextKey), workspaceID)
+	if err != nil {
+		h.handleError(ctx, err)
+		return false
+	}
+	if role != workspacesvc.RoleAdmin {
+		httpx.RespondError(ctx, http.StatusForbidden, "FORBIDDEN", "当前角色无权限执行该操作", nil)
+		return false
+	}
+	return true
+}
+
+func (h *WorkspaceHandler) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, workspacesvc.ErrNameRequired),
+		errors.Is(err, workspacesvc.ErrInvalidSlug),
+		errors.Is(err, workspacesvc.ErrInvalidRole):
+		httpx.RespondError(ctx, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrDefaultWorkspaceFixed):
+		httpx.RespondError(ctx, http.StatusBadRequest, "DEFAULT_WORKSPACE_FIXED", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrOrganizationNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "ORGANIZATION_NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrWorkspaceNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "WORKSPACE_NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrMemberNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "MEMBER_NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrUserNotFound):
+		httpx.RespondError(ctx, http.StatusNotFound, "USER_NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrWorkspaceForbidden):
+		httpx.RespondError(ctx, http.StatusForbidden, "WORKSPACE_FORBIDDEN", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrOrganizationExists):
+		httpx.RespondError(ctx, http.StatusConflict, "ORGANIZATION_EXISTS", err.Error(), nil)
+	case errors.Is(err, workspacesvc.ErrWorkspaceExists):
+		httpx.RespondError(ctx, http.StatusConflict, "WORKSPACE_EXISTS", err.Error(), nil)
+	default:
+		httpx.RespondError(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
+	}
+}
+
+// actorFromContext 返回当前操作人，优先使用邮箱。
+func actorFromContext(ctx *gin.Context) string {
+	if actor := ctx.GetString(middleware.UserEmailContextKey); actor != "" {
+		return actor
+	}
+	return ctx.GetString(middleware.UserContextKey)
+}
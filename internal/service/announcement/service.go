@@ -0,0 +1,169 @@
+// Package announcement 管理站内公告（如维护通知），前端按展示窗口读取，无需重新部署即可更新。
+package announcement
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	auditsvc "github.com/zacharykka/prompt-manager/internal/service/audit"
+)
+
+// maxMessageLength 为公告正文的最大字符数。
+const maxMessageLength = 2000
+
+// 公告相关的审计动作。
+const (
+	AuditAnnouncementCreated = "announcement.created"
+	AuditAnnouncementUpdated = "announcement.updated"
+	AuditAnnouncementDeleted = "announcement.deleted"
+)
+
+var (
+	// ErrMessageRequired 表示公告正文为空或过长。
+	ErrMessageRequired = errors.New("announcement message is required and must not exceed 2000 characters")
+	// ErrInvalidSeverity 表示公告级别不合法。
+	ErrInvalidSeverity = errors.New("announcement severity must be info, warning or critical")
+	// ErrInvalidWindow 表示展示窗口的结束时间不晚于开始时间。
+	ErrInvalidWindow = errors.New("announcement ends_at must be after starts_at")
+	// ErrAnnouncementNotFound 表示公告不存在。
+	ErrAnnouncementNotFound = errors.New("announcement not found")
+)
+
+// Service 管理站内公告。
+type Service struct {
+	repos *domain.Repositories
+	now   func() time.Time
+}
+
+// NewService 创建公告服务。
+func NewService(repos *domain.Repositories) *Service {
+	return &Service{repos: repos, now: time.Now}
+}
+
+// Input 描述创建或更新公告的字段，Severity 为空时使用 info。
+type Input struct {
+	Message  string
+	Severity string
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	Actor    string
+}
+
+// ListActive 返回当前展示窗口内的公告。
+func (s *Service) ListActive(ctx context.Context) ([]*domain.Announcement, error) {
+	return s.repos.Announcements.ListActive(ctx, s.now())
+}
+
+// List 返回全部公告（含已过期与未开始的）。
+func (s *Service) List(ctx context.Context) ([]*domain.Announcement, error) {
+	return s.repos.Announcements.List(ctx)
+}
+
+// Create 发布公告。
+func (s *Service) Create(ctx context.Context, input Input) (*domain.Announcement, error) {
+	announcement := &domain.Announcement{ID: uuid.NewString(), CreatedAt: s.now().UTC()}
+	if err := applyInput(announcement, input); err != nil {
+		return nil, err
+	}
+	if input.Actor != "" {
+		actor := input.Actor
+		announcement.CreatedBy = &actor
+	}
+	if err := s.repos.Announcements.Create(ctx, announcement); err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditAnnouncementCreated, input.Actor, announcement)
+	return announcement, nil
+}
+
+// Update 覆盖公告的正文、级别与展示窗口。
+func (s *Service) Update(ctx context.Context, id string, input Input) (*domain.Announcement, error) {
+	announcement, err := s.repos.Announcements.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrAnnouncementNotFound
+		}
+		return nil, err
+	}
+	if err := applyInput(announcement, input); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Announcements.Update(ctx, announcement); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrAnnouncementNotFound
+		}
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditAnnouncementUpdated, input.Actor, announcement)
+	return announcement, nil
+}
+
+// Delete 删除公告。
+func (s *Service) Delete(ctx context.Context, id, actor string) error {
+	if err := s.repos.Announcements.Delete(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrAnnouncementNotFound
+		}
+		return err
+	}
+	_ = auditsvc.Record(ctx, s.repos.AuditLogs, auditsvc.Entry{
+		Action:     AuditAnnouncementDeleted,
+		Actor:      actor,
+		TargetType: "announcement",
+		TargetID:   id,
+		CreatedAt:  s.now(),
+	})
+	return nil
+}
+
+func applyInput(announcement *domain.Announcement, input Input) error {
+	message := strings.TrimSpace(input.Message)
+	if message == "" || utf8.RuneCountInString(message) > maxMessageLength {
+		return ErrMessageRequired
+	}
+	severity := strings.ToLower(strings.TrimSpace(input.Severity))
+	switch severity {
+	case "":
+		severity = domain.AnnouncementSeverityInfo
+	case domain.AnnouncementSeverityInfo, domain.AnnouncementSeverityWarning, domain.AnnouncementSeverityCritical:
+	default:
+		return ErrInvalidSeverity
+	}
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return ErrInvalidWindow
+	}
+	announcement.Message = message
+	announcement.Severity = severity
+	announcement.StartsAt = utcPtr(input.StartsAt)
+	announcement.EndsAt = utcPtr(input.EndsAt)
+	return nil
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := t.UTC()
+	return &value
+}
+
+// recordAudit 写入通用审计日志；审计失败不影响已完成的操作。
+func (s *Service) recordAudit(ctx context.Context, action, actor string, announcement *domain.Announcement) {
+	_ = auditsvc.Record(ctx, s.repos.AuditLogs, auditsvc.Entry{
+		Action:     action,
+		Actor:      actor,
+		TargetType: "announcement",
+		TargetID:   announcement.ID,
+		Payload: map[string]interface{}{
+			"severity":  announcement.Severity,
+			"starts_at": announcement.StartsAt,
+			"ends_at":   announcement.EndsAt,
+		},
+		CreatedAt: s.now(),
+	})
+}
@@ -0,0 +1,139 @@
+package announcement
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	_ "modernc.org/sqlite"
+)
+
+func setupAnnouncementService(t *testing.T) (*Service, func()) {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:announcement_service_test.db?mode=memory&cache=shared&_fk=1")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("glob migrations: %v", err)
+	}
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
+	return NewService(repos), func() { _ = db.Close() }
+}
+
+func TestAnnouncementActiveWindow(t *testing.T) {
+	svc, cleanup := setupAnnouncementService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	at := func(offset time.Duration) *time.Time {
+		t := now.Add(offset)
+		return &t
+	}
+
+	current, err := svc.Create(ctx, Input{Message: "  计划维护：今晚 22:00  ", Severity: "Warning", StartsAt: at(-time.Hour), EndsAt: at(time.Hour), Actor: "admin@example.com"})
+	if err != nil {
+		t.Fatalf("create current: %v", err)
+	}
+	if current.Message != "计划维护：今晚 22:00" || current.Severity != domain.AnnouncementSeverityWarning {
+		t.Fatalf("unexpected announcement: %+v", current)
+	}
+	if _, err := svc.Create(ctx, Input{Message: "open ended"}); err != nil {
+		t.Fatalf("create open ended: %v", err)
+	}
+	if _, err := svc.Create(ctx, Input{Message: "expired", EndsAt: at(-time.Minute)}); err != nil {
+		t.Fatalf("create expired: %v", err)
+	}
+	future, err := svc.Create(ctx, Input{Message: "upcoming", StartsAt: at(time.Hour)})
+	if err != nil {
+		t.Fatalf("create future: %v", err)
+	}
+
+	active, err := svc.ListActive(ctx)
+	if err != nil {
+		t.Fatalf("list active: %v", err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("expected 2 active announcements, got %d", len(active))
+	}
+	all, err := svc.List(ctx)
+	if err != nil || len(all) != 4 {
+		t.Fatalf("expected 4 announcements, got %d (%v)", len(all), err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	active, err = svc.ListActive(ctx)
+	if err != nil {
+		t.Fatalf("list active later: %v", err)
+	}
+	if len(active) != 2 || active[0].ID != future.ID {
+		t.Fatalf("expected upcoming announcement to start and current to end, got %+v", active)
+	}
+
+	logs, err := svc.repos.AuditLogs.List(ctx, domain.AuditLogListOptions{Action: AuditAnnouncementCreated, Limit: 10})
+	if err != nil || len(logs) != 4 {
+		t.Fatalf("expected 4 audit entries, got %d (%v)", len(logs), err)
+	}
+}
+
+func TestAnnouncementValidation(t *testing.T) {
+	svc, cleanup := setupAnnouncementService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	start := time.Now()
+	end := start.Add(-time.Minute)
+	cases := []struct {
+		input Input
+		want  error
+	}{
+		{Input{Message: "   "}, ErrMessageRequired},
+		{Input{Message: "hello", Severity: "urgent"}, ErrInvalidSeverity},
+		{Input{Message: "hello", StartsAt: &start, EndsAt: &end}, ErrInvalidWindow},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Create(ctx, tc.input); !errors.Is(err, tc.want) {
+			t.Fatalf("create %+v: expected %v, got %v", tc.input, tc.want, err)
+		}
+	}
+
+	if _, err := svc.Update(ctx, "missing", Input{Message: "hello"}); !errors.Is(err, ErrAnnouncementNotFound) {
+		t.Fatalf("expected not found on update, got %v", err)
+	}
+	if err := svc.Delete(ctx, "missing", "admin"); !errors.Is(err, ErrAnnouncementNotFound) {
+		t.Fatalf("expected not found on delete, got %v", err)
+	}
+
+	created, err := svc.Create(ctx, Input{Message: "hello"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	updated, err := svc.Update(ctx, created.ID, Input{Message: "updated", Severity: domain.AnnouncementSeverityCritical})
+	if err != nil || updated.Severity != domain.AnnouncementSeverityCritical {
+		t.Fatalf("update: %+v (%v)", updated, err)
+	}
+	if err := svc.Delete(ctx, created.ID, "admin"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
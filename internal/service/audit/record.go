@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	auditutil "github.com/zacharykka/prompt-manager/pkg/audit"
+)
+
+// Entry 描述一条待写入的通用审计事件。
+type Entry struct {
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   string
+	Payload    map[string]interface{}
+	CreatedAt  time.Time
+}
+
+// Record 写入通用审计日志，客户端 IP/User-Agent 取自上下文（见 pkg/audit.WithClient）；repo 为 nil 时跳过。
+func Record(ctx context.Context, repo domain.AuditLogRepository, entry Entry) error {
+	if repo == nil {
+		return nil
+	}
+	var payload json.RawMessage
+	if len(entry.Payload) > 0 {
+		data, err := json.Marshal(entry.Payload)
+		if err != nil {
+			return err
+		}
+		payload = data
+	}
+	client := auditutil.ClientFromContext(ctx)
+	return repo.Create(ctx, &domain.AuditLog{
+		ID:         uuid.NewString(),
+		Action:     entry.Action,
+		Actor:      optionalString(entry.Actor),
+		TargetType: entry.TargetType,
+		TargetID:   optionalString(entry.TargetID),
+		IP:         optionalString(client.IP),
+		UserAgent:  optionalString(client.UserAgent),
+		Payload:    payload,
+		CreatedAt:  entry.CreatedAt,
+	})
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	auditutil "github.com/zacharykka/prompt-manager/pkg/audit"
+)
+
+// ErrAuditLogUnavailable 表示未配置通用审计日志仓储。
+var ErrAuditLogUnavailable = errors.New("audit log not configured")
+
+const maxListLimit = 200
+
+// Service 提供通用审计日志（认证、用户与管理操作）的查询、校验与导出。
+type Service struct {
+	repos *domain.Repositories
+}
+
+// NewService 创建审计服务。
+func NewService(repos *domain.Repositories) *Service {
+	return &Service{repos: repos}
+}
+
+// List 按过滤条件分页返回审计日志（按时间倒序）及总数。
+func (s *Service) List(ctx context.Context, opts domain.AuditLogListOptions) ([]*domain.AuditLog, int64, error) {
+	if s.repos.AuditLogs == nil {
+		return nil, 0, ErrAuditLogUnavailable
+	}
+	if opts.Limit <= 0 || opts.Limit > maxListLimit {
+		opts.Limit = maxListLimit
+	}
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	items, err := s.repos.AuditLogs.List(ctx, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repos.AuditLogs.Count(ctx, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if items == nil {
+		items = []*domain.AuditLog{}
+	}
+	return items, total, nil
+}
+
+// VerifyChain 校验通用审计日志的哈希链。
+func (s *Service) VerifyChain(ctx context.Context) (*auditutil.ChainReport, error) {
+	if s.repos.AuditLogs == nil {
+		return nil, ErrAuditLogUnavailable
+	}
+	verifier := auditutil.NewChainVerifier()
+	err := s.repos.AuditLogs.Iterate(ctx, domain.AuditLogListOptions{}, func(log *domain.AuditLog) error {
+		verifier.Add(log.ID, log.Seq, log.PrevHash, log.Hash, log.ChainHash())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Report(), nil
+}
+
+// Iterate 按链序号逐行回调符合条件的审计日志，用于 NDJSON 导出。
+func (s *Service) Iterate(ctx context.Context, opts domain.AuditLogListOptions, fn func(*domain.AuditLog) error) error {
+	if s.repos.AuditLogs == nil {
+		return ErrAuditLogUnavailable
+	}
+	return s.repos.AuditLogs.Iterate(ctx, opts, fn)
+}
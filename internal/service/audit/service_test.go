@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	auditutil "github.com/zacharykka/prompt-manager/pkg/audit"
+	_ "modernc.org/sqlite"
+)
+
+func setupAuditService(t *testing.T) (*Service, *sql.DB, func()) {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:audit_service_test.db?mode=memory&cache=shared&_fk=1")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("glob migrations: %v", err)
+	}
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
+	return NewService(repos), db, func() { _ = db.Close() }
+}
+
+func TestListAndVerifyAuditLogs(t *testing.T) {
+	svc, db, cleanup := setupAuditService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	admin := "admin@example.com"
+	base := time.Now().Add(-time.Hour)
+	entries := []string{"auth.login.succeeded", "auth.login.failed", "user.created", "auth.signing_key.rotated"}
+	for i, action := range entries {
+		if err := svc.repos.AuditLogs.Create(ctx, &domain.AuditLog{
+			ID:         uuid.NewString(),
+			Action:     action,
+			Actor:      &admin,
+			TargetType: "user",
+			CreatedAt:  base.Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("create audit log: %v", err)
+		}
+	}
+
+	items, total, err := svc.List(ctx, domain.AuditLogListOptions{Action: "auth.", Limit: 2})
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	if total != 3 || len(items) != 2 || items[0].Action != "auth.signing_key.rotated" {
+		t.Fatalf("unexpected prefix filter result total=%d items=%+v", total, items)
+	}
+
+	items, total, err = svc.List(ctx, domain.AuditLogListOptions{Action: "user.created", From: base.Add(90 * time.Second)})
+	if err != nil || total != 1 || len(items) != 1 {
+		t.Fatalf("expected exact action and time filter to match 1 record, got %d (%v)", total, err)
+	}
+
+	report, err := svc.VerifyChain(ctx)
+	if err != nil {
+		t.Fatalf("verify chain: %v", err)
+	}
+	if !report.Verified || report.Checked != len(entries) {
+		t.Fatalf("expected intact chain, got %+v", report)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_logs SET action = 'auth.login.succeeded' WHERE seq = 2`); err != nil {
+		t.Fatalf("tamper record: %v", err)
+	}
+	report, err = svc.VerifyChain(ctx)
+	if err != nil {
+		t.Fatalf("verify tampered chain: %v", err)
+	}
+	if report.Verified || len(report.Issues) != 1 || report.Issues[0].Kind != auditutil.IssueHashMismatch || report.Issues[0].Seq != 2 {
+		t.Fatalf("expected hash mismatch at seq 2, got %+v", report)
+	}
+}
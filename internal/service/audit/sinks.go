@@ -0,0 +1,233 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SignatureHeader 携带 HTTP 推送请求体的 HMAC-SHA256 签名（sha256=<hex>），仅配置签名密钥时发送。
+const SignatureHeader = "X-Prompt-Manager-Signature"
+
+// syslogPriority 为 local0.info（16*8+6），审计事件统一按信息级别上报。
+const syslogPriority = 134
+
+// SyslogSink 以 RFC 5424 格式把每条审计记录作为一条 syslog 消息发送，消息体为记录的 JSON。
+// TCP 连接按 RFC 6587 的长度前缀分帧，发送失败时断开并在下次发送时重连。
+type SyslogSink struct {
+	name     string
+	network  string
+	address  string
+	appName  string
+	hostname string
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink 创建 syslog 推送目标，network 为 udp 或 tcp，appName 为空时使用 prompt-manager。
+func NewSyslogSink(name, network, address, appName string) *SyslogSink {
+	if appName == "" {
+		appName = "prompt-manager"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &SyslogSink{
+		name:     name,
+		network:  network,
+		address:  address,
+		appName:  appName,
+		hostname: hostname,
+		timeout:  5 * time.Second,
+	}
+}
+
+// Name 实现 Sink。
+func (s *SyslogSink) Name() string {
+	return s.name
+}
+
+// Send 实现 Sink。
+func (s *SyslogSink) Send(ctx context.Context, records []SinkRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		dialer := net.Dialer{Timeout: s.timeout}
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	for _, record := range records {
+		message, err := s.format(record)
+		if err != nil {
+			return err
+		}
+		if s.network == "tcp" {
+			message = append([]byte(fmt.Sprintf("%d ", len(message))), message...)
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+		if _, err := s.conn.Write(message); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SyslogSink) format(record SinkRecord) ([]byte, error) {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+		syslogPriority,
+		record.CreatedAt.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		s.appName,
+		os.Getpid(),
+		syslogMsgID(record.Action),
+	)
+	return append([]byte(header), body...), nil
+}
+
+// syslogMsgID 以审计动作为 MSGID，按 RFC 5424 限制为 32 个可打印 ASCII 字符。
+func syslogMsgID(action string) string {
+	if action == "" {
+		return "-"
+	}
+	id := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, action)
+	if len(id) > 32 {
+		id = id[:32]
+	}
+	return id
+}
+
+// Close 关闭连接。
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// HTTPSink 以 JSON POST（{"records": [...]}）批量推送审计记录，适用于 Splunk HEC 转发、Logstash 等 HTTP 接收端。
+type HTTPSink struct {
+	name   string
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewHTTPSink 创建 HTTP 推送目标，secret 为空时不签名。
+func NewHTTPSink(name, target, secret string) *HTTPSink {
+	return &HTTPSink{name: name, url: target, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name 实现 Sink。
+func (s *HTTPSink) Name() string {
+	return s.name
+}
+
+// Send 实现 Sink。
+func (s *HTTPSink) Send(ctx context.Context, records []SinkRecord) error {
+	payload, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	headers := http.Header{"Content-Type": {"application/json"}}
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(payload)
+		headers.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return post(ctx, s.client, s.url, headers, payload)
+}
+
+// KafkaSink 通过 Kafka REST Proxy（v2 JSON 格式）写入主题，以记录目标为消息键，保证同一目标的记录落在同一分区。
+type KafkaSink struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// NewKafkaSink 创建 Kafka 推送目标，proxyURL 为 REST Proxy 地址。
+func NewKafkaSink(name, proxyURL, topic string) *KafkaSink {
+	return &KafkaSink{
+		name:   name,
+		url:    strings.TrimRight(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 实现 Sink。
+func (s *KafkaSink) Name() string {
+	return s.name
+}
+
+// Send 实现 Sink。
+func (s *KafkaSink) Send(ctx context.Context, records []SinkRecord) error {
+	type message struct {
+		Key   string     `json:"key"`
+		Value SinkRecord `json:"value"`
+	}
+	messages := make([]message, 0, len(records))
+	for _, record := range records {
+		key := record.TargetID
+		if key == "" {
+			key = record.ID
+		}
+		messages = append(messages, message{Key: key, Value: record})
+	}
+	payload, err := json.Marshal(map[string]interface{}{"records": messages})
+	if err != nil {
+		return err
+	}
+	headers := http.Header{
+		"Content-Type": {"application/vnd.kafka.json.v2+json"},
+		"Accept":       {"application/vnd.kafka.v2+json"},
+	}
+	return post(ctx, s.client, s.url, headers, payload)
+}
+
+func post(ctx context.Context, client *http.Client, target string, headers http.Header, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header = headers
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("audit sink responded %d", resp.StatusCode)
+	}
+	return nil
+}
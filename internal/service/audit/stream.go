@@ -0,0 +1,380 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// OverflowDrop 在推送队列已满时丢弃新记录并计数，写审计的请求不被阻塞。
+	OverflowDrop = "drop"
+	// OverflowBlock 在推送队列已满时等待空位，直到调用方上下文取消（此时记为 dropped）。
+	OverflowBlock = "block"
+)
+
+// 审计记录的来源：通用审计日志与 Prompt 审计日志。
+const (
+	RecordSourceAudit  = "audit"
+	RecordSourcePrompt = "prompt"
+)
+
+var (
+	// SinkQueueDepth 为各推送目标队列中等待发送的审计记录总数。
+	SinkQueueDepth = metrics.NewGauge(
+		"prompt_manager_audit_sink_queue_depth",
+		"Audit records queued in memory and waiting to be delivered to external sinks.",
+	)
+	// SinkOutcomes 按推送目标与结果统计审计记录：delivered 已送达、dropped 因队列已满或关停丢弃、
+	// failed 重试耗尽仍发送失败。
+	SinkOutcomes = metrics.NewCounterVec(
+		"prompt_manager_audit_sink_records_total",
+		"Audit records handled by external sinks, by sink and outcome.",
+		"sink", "outcome",
+	)
+	// SinkRetries 按推送目标统计批次重试次数。
+	SinkRetries = metrics.NewCounterVec(
+		"prompt_manager_audit_sink_retries_total",
+		"Audit sink batch delivery retries, by sink.",
+		"sink",
+	)
+)
+
+func init() {
+	metrics.Default.MustRegister(SinkQueueDepth, SinkOutcomes, SinkRetries)
+}
+
+// SinkRecord 为推送到外部系统的审计记录，统一了通用审计日志与 Prompt 审计日志。
+type SinkRecord struct {
+	ID         string          `json:"id"`
+	Source     string          `json:"source"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor,omitempty"`
+	TargetType string          `json:"target_type,omitempty"`
+	TargetID   string          `json:"target_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Seq        int64           `json:"seq,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+}
+
+// RecordFromAuditLog 转换通用审计日志。
+func RecordFromAuditLog(log *domain.AuditLog) SinkRecord {
+	return SinkRecord{
+		ID:         log.ID,
+		Source:     RecordSourceAudit,
+		Action:     log.Action,
+		Actor:      derefString(log.Actor),
+		TargetType: log.TargetType,
+		TargetID:   derefString(log.TargetID),
+		IP:         derefString(log.IP),
+		UserAgent:  derefString(log.UserAgent),
+		Payload:    log.Payload,
+		CreatedAt:  log.CreatedAt,
+		Seq:        log.Seq,
+		Hash:       log.Hash,
+	}
+}
+
+// RecordFromPromptAuditLog 转换 Prompt 审计日志，目标固定为所属 Prompt。
+func RecordFromPromptAuditLog(log *domain.PromptAuditLog) SinkRecord {
+	return SinkRecord{
+		ID:         log.ID,
+		Source:     RecordSourcePrompt,
+		Action:     log.Action,
+		Actor:      derefString(log.CreatedBy),
+		TargetType: "prompt",
+		TargetID:   log.PromptID,
+		Payload:    log.Payload,
+		CreatedAt:  log.CreatedAt,
+		Seq:        log.Seq,
+		Hash:       log.Hash,
+	}
+}
+
+// Sink 为审计记录的外部推送目标（syslog、HTTP、Kafka 等），Name 用于指标与日志区分各目标。
+// 实现 io.Closer 的目标会在 Streamer.Close 时关闭。
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, records []SinkRecord) error
+}
+
+// StreamOption 调整 Streamer 的队列、批次与重试参数。
+type StreamOption func(*Streamer)
+
+// WithStreamBufferSize 设置每个推送目标的内存队列容量，默认 10000。
+func WithStreamBufferSize(size int) StreamOption {
+	return func(s *Streamer) {
+		if size > 0 {
+			s.bufferSize = size
+		}
+	}
+}
+
+// WithStreamBatchSize 设置单次发送的最大条数，默认 100。
+func WithStreamBatchSize(size int) StreamOption {
+	return func(s *Streamer) {
+		if size > 0 {
+			s.batchSize = size
+		}
+	}
+}
+
+// WithStreamFlushInterval 设置未攒满一批时的最长等待时间，默认 1 秒。
+func WithStreamFlushInterval(interval time.Duration) StreamOption {
+	return func(s *Streamer) {
+		if interval > 0 {
+			s.flushInterval = interval
+		}
+	}
+}
+
+// WithStreamOverflowPolicy 设置队列已满时的处理方式（OverflowDrop 或 OverflowBlock），默认丢弃。
+func WithStreamOverflowPolicy(policy string) StreamOption {
+	return func(s *Streamer) {
+		if policy == OverflowDrop || policy == OverflowBlock {
+			s.overflow = policy
+		}
+	}
+}
+
+// WithStreamRetry 设置发送失败后的最大重试次数与首次退避间隔（此后每次翻倍），默认 3 次、500 毫秒。
+func WithStreamRetry(maxRetries int, backoff time.Duration) StreamOption {
+	return func(s *Streamer) {
+		if maxRetries >= 0 {
+			s.maxRetries = maxRetries
+		}
+		if backoff > 0 {
+			s.retryBackoff = backoff
+		}
+	}
+}
+
+// Streamer 将审计记录近实时地推送到各外部目标。每个目标有独立的队列与发送协程，
+// 慢目标只会填满自己的队列，不影响其他目标与审计写库。进程退出前需调用 Close 排空队列。
+type Streamer struct {
+	logger        *zap.Logger
+	bufferSize    int
+	batchSize     int
+	flushInterval time.Duration
+	overflow      string
+	maxRetries    int
+	retryBackoff  time.Duration
+
+	sinks   []*sinkWorker
+	mu      sync.RWMutex
+	started bool
+	closed  bool
+}
+
+type sinkWorker struct {
+	sink  Sink
+	queue chan SinkRecord
+	done  chan struct{}
+}
+
+// NewStreamer 创建推送器，需调用 Start 启动后台发送。
+func NewStreamer(logger *zap.Logger, sinks []Sink, opts ...StreamOption) *Streamer {
+	s := &Streamer{
+		logger:        logger,
+		bufferSize:    10000,
+		batchSize:     100,
+		flushInterval: time.Second,
+		overflow:      OverflowDrop,
+		maxRetries:    3,
+		retryBackoff:  500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	for _, sink := range sinks {
+		s.sinks = append(s.sinks, &sinkWorker{
+			sink:  sink,
+			queue: make(chan SinkRecord, s.bufferSize),
+			done:  make(chan struct{}),
+		})
+	}
+	return s
+}
+
+// Start 启动各目标的发送协程。发送协程不随 ctx 退出，而是在 Close 时排空队列后结束。
+func (s *Streamer) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, worker := range s.sinks {
+		go s.run(context.WithoutCancel(ctx), worker)
+	}
+}
+
+// Publish 将记录放入各目标的队列；未启动或已关闭时记为 dropped。推送失败不影响审计写库，因此不返回错误。
+func (s *Streamer) Publish(ctx context.Context, record SinkRecord) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, worker := range s.sinks {
+		name := worker.sink.Name()
+		if s.closed || !s.started {
+			SinkOutcomes.Inc(name, "dropped")
+			continue
+		}
+		if s.overflow == OverflowBlock {
+			select {
+			case worker.queue <- record:
+				SinkQueueDepth.Add(1)
+			case <-ctx.Done():
+				SinkOutcomes.Inc(name, "dropped")
+				s.logger.Warn("audit record dropped; sink queue full", zap.String("sink", name), zap.String("record_id", record.ID))
+			}
+			continue
+		}
+		select {
+		case worker.queue <- record:
+			SinkQueueDepth.Add(1)
+		default:
+			SinkOutcomes.Inc(name, "dropped")
+			s.logger.Warn("audit record dropped; sink queue full", zap.String("sink", name), zap.String("record_id", record.ID))
+		}
+	}
+}
+
+func (s *Streamer) run(ctx context.Context, worker *sinkWorker) {
+	defer close(worker.done)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SinkRecord, 0, s.batchSize)
+	for {
+		select {
+		case record, ok := <-worker.queue:
+			if !ok {
+				s.deliver(ctx, worker.sink, batch)
+				return
+			}
+			SinkQueueDepth.Add(-1)
+			batch = append(batch, record)
+			if len(batch) >= s.batchSize {
+				s.deliver(ctx, worker.sink, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.deliver(ctx, worker.sink, batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// deliver 发送一批记录，失败时按指数退避重试。
+func (s *Streamer) deliver(ctx context.Context, sink Sink, batch []SinkRecord) {
+	if len(batch) == 0 {
+		return
+	}
+	name := sink.Name()
+	backoff := s.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := sink.Send(ctx, batch)
+		if err == nil {
+			SinkOutcomes.Add(uint64(len(batch)), name, "delivered")
+			return
+		}
+		if attempt >= s.maxRetries {
+			SinkOutcomes.Add(uint64(len(batch)), name, "failed")
+			s.logger.Error("deliver audit records failed", zap.String("sink", name), zap.Int("count", len(batch)), zap.Error(err))
+			return
+		}
+		SinkRetries.Inc(name)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// Close 停止接收新记录并等待各目标排空队列；ctx 到期时返回 ctx.Err()，剩余记录继续在后台发送。
+func (s *Streamer) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	started := s.started
+	for _, worker := range s.sinks {
+		close(worker.queue)
+	}
+	s.mu.Unlock()
+
+	if started {
+		for _, worker := range s.sinks {
+			select {
+			case <-worker.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	for _, worker := range s.sinks {
+		if closer, ok := worker.sink.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				s.logger.Warn("close audit sink failed", zap.String("sink", worker.sink.Name()), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// StreamingAuditLogs 包装通用审计日志仓储：写库成功后把记录推送到外部目标，其余方法直接委托。
+type StreamingAuditLogs struct {
+	domain.AuditLogRepository
+	streamer *Streamer
+}
+
+// NewStreamingAuditLogs 创建推送通用审计日志的仓储包装。
+func NewStreamingAuditLogs(repo domain.AuditLogRepository, streamer *Streamer) *StreamingAuditLogs {
+	return &StreamingAuditLogs{AuditLogRepository: repo, streamer: streamer}
+}
+
+// Create 写库后推送。
+func (r *StreamingAuditLogs) Create(ctx context.Context, log *domain.AuditLog) error {
+	if err := r.AuditLogRepository.Create(ctx, log); err != nil {
+		return err
+	}
+	r.streamer.Publish(ctx, RecordFromAuditLog(log))
+	return nil
+}
+
+// StreamingPromptAuditLogs 包装 Prompt 审计日志仓储：写库成功后把记录推送到外部目标，其余方法直接委托。
+type StreamingPromptAuditLogs struct {
+	domain.PromptAuditLogRepository
+	streamer *Streamer
+}
+
+// NewStreamingPromptAuditLogs 创建推送 Prompt 审计日志的仓储包装。
+func NewStreamingPromptAuditLogs(repo domain.PromptAuditLogRepository, streamer *Streamer) *StreamingPromptAuditLogs {
+	return &StreamingPromptAuditLogs{PromptAuditLogRepository: repo, streamer: streamer}
+}
+
+// Create 写库后推送。
+func (r *StreamingPromptAuditLogs) Create(ctx context.Context, log *domain.PromptAuditLog) error {
+	if err := r.PromptAuditLogRepository.Create(ctx, log); err != nil {
+		return err
+	}
+	r.streamer.Publish(ctx, RecordFromPromptAuditLog(log))
+	return nil
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
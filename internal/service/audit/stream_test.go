@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type recordingSink struct {
+	name     string
+	failures int
+	sending  chan struct{}
+	release  chan struct{}
+
+	mu      sync.Mutex
+	calls   int
+	records []SinkRecord
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Send(ctx context.Context, records []SinkRecord) error {
+	s.mu.Lock()
+	first := s.calls == 0
+	s.mu.Unlock()
+	// 首次发送时阻塞，模拟慢目标。
+	if s.sending != nil && first {
+		s.sending <- struct{}{}
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("sink unavailable")
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func TestStreamerDeliversAuditRecords(t *testing.T) {
+	svc, _, cleanup := setupAuditService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	sink := &recordingSink{name: "stream-test", failures: 1}
+	streamer := NewStreamer(zap.NewNop(), []Sink{sink}, WithStreamBatchSize(2), WithStreamFlushInterval(10*time.Millisecond), WithStreamRetry(2, time.Millisecond))
+	streamer.Start(ctx)
+	svc.repos.AuditLogs = NewStreamingAuditLogs(svc.repos.AuditLogs, streamer)
+	retriesBefore, deliveredBefore := SinkRetries.Value("stream-test"), SinkOutcomes.Value("stream-test", "delivered")
+
+	for _, action := range []string{"auth.login", "user.role_changed", "api_key.revoked"} {
+		if err := Record(ctx, svc.repos.AuditLogs, Entry{Action: action, Actor: "admin@example.com", TargetType: "user", TargetID: "u-1"}); err != nil {
+			t.Fatalf("record %s: %v", action, err)
+		}
+	}
+	if err := streamer.Close(ctx); err != nil {
+		t.Fatalf("close streamer: %v", err)
+	}
+
+	if len(sink.records) != 3 {
+		t.Fatalf("expected 3 delivered records, got %d", len(sink.records))
+	}
+	first := sink.records[0]
+	if first.Source != RecordSourceAudit || first.Action != "auth.login" || first.Actor != "admin@example.com" || first.TargetID != "u-1" {
+		t.Fatalf("unexpected record %+v", first)
+	}
+	if first.Seq == 0 || first.Hash == "" {
+		t.Fatalf("expected chain fields to be streamed, got %+v", first)
+	}
+	if SinkRetries.Value("stream-test") != retriesBefore+1 || SinkOutcomes.Value("stream-test", "delivered") != deliveredBefore+3 {
+		t.Fatalf("unexpected delivery metrics")
+	}
+}
+
+func TestStreamerDropsWhenSinkQueueFull(t *testing.T) {
+	sink := &recordingSink{name: "stream-overflow", sending: make(chan struct{}), release: make(chan struct{})}
+	streamer := NewStreamer(zap.NewNop(), []Sink{sink}, WithStreamBufferSize(1), WithStreamBatchSize(1))
+	streamer.Start(context.Background())
+	droppedBefore := SinkOutcomes.Value("stream-overflow", "dropped")
+
+	ctx := context.Background()
+	streamer.Publish(ctx, SinkRecord{ID: "r1"})
+	<-sink.sending // r1 已出队，发送被阻塞
+	streamer.Publish(ctx, SinkRecord{ID: "r2"})
+	streamer.Publish(ctx, SinkRecord{ID: "r3"})
+	if dropped := SinkOutcomes.Value("stream-overflow", "dropped") - droppedBefore; dropped != 1 {
+		t.Fatalf("expected 1 dropped record, got %d", dropped)
+	}
+
+	close(sink.release)
+	if err := streamer.Close(ctx); err != nil {
+		t.Fatalf("close streamer: %v", err)
+	}
+	if len(sink.records) != 2 || sink.records[1].ID != "r2" {
+		t.Fatalf("unexpected delivered records %+v", sink.records)
+	}
+}
+
+func TestAuditSinks(t *testing.T) {
+	ctx := context.Background()
+	records := []SinkRecord{{ID: "r1", Source: RecordSourcePrompt, Action: "prompt.version.activated", TargetType: "prompt", TargetID: "p-1", CreatedAt: time.Now()}}
+
+	var (
+		httpBody      []byte
+		httpSignature string
+		kafkaPath     string
+		kafkaType     string
+		kafkaBody     []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/audit" {
+			httpBody, httpSignature = body, r.Header.Get(SignatureHeader)
+		} else {
+			kafkaPath, kafkaType, kafkaBody = r.URL.Path, r.Header.Get("Content-Type"), body
+		}
+	}))
+	defer server.Close()
+
+	if err := NewHTTPSink("http", server.URL+"/audit", "s3cret").Send(ctx, records); err != nil {
+		t.Fatalf("http sink: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(httpBody)
+	if httpSignature != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("unexpected signature %q", httpSignature)
+	}
+
+	if err := NewKafkaSink("kafka", server.URL+"/", "audit events").Send(ctx, records); err != nil {
+		t.Fatalf("kafka sink: %v", err)
+	}
+	var kafkaPayload struct {
+		Records []struct {
+			Key   string     `json:"key"`
+			Value SinkRecord `json:"value"`
+		} `json:"records"`
+	}
+	if err := json.Unmarshal(kafkaBody, &kafkaPayload); err != nil {
+		t.Fatalf("decode kafka payload: %v", err)
+	}
+	if kafkaPath != "/topics/audit events" || kafkaType != "application/vnd.kafka.json.v2+json" ||
+		len(kafkaPayload.Records) != 1 || kafkaPayload.Records[0].Key != "p-1" || kafkaPayload.Records[0].Value.ID != "r1" {
+		t.Fatalf("unexpected kafka request %s %s %s", kafkaPath, kafkaType, kafkaBody)
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer conn.Close()
+	syslog := NewSyslogSink("syslog", "udp", conn.LocalAddr().String(), "")
+	defer syslog.Close()
+	if err := syslog.Send(ctx, records); err != nil {
+		t.Fatalf("syslog sink: %v", err)
+	}
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read syslog message: %v", err)
+	}
+	message := string(buf[:n])
+	if !strings.HasPrefix(message, "<134>1 ") || !strings.Contains(message, " prompt-manager ") ||
+		!strings.Contains(message, " prompt.version.activated - {") || !strings.Contains(message, `"id":"r1"`) {
+		t.Fatalf("unexpected syslog message %q", message)
+	}
+}
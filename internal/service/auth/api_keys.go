@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+)
+
+// API Key 相关的审计动作。
+const (
+	AuditAPIKeyCreated = "auth.api_key.created"
+	AuditAPIKeyRevoked = "auth.api_key.revoked"
+	auditTargetAPIKey  = "api_key"
+)
+
+const (
+	apiKeyPrefix       = "pmk_"
+	apiKeyDisplayChars = 12
+	// maxAPIKeyRateLimit 为单个密钥可申请的每分钟请求上限。
+	maxAPIKeyRateLimit = 10000
+	// maxAPIKeyDailyQuota 为单个密钥可申请的每 24 小时请求配额上限。
+	maxAPIKeyDailyQuota = 10000000
+)
+
+// CreateAPIKeyInput 定义签发 API Key 所需的字段。
+type CreateAPIKeyInput struct {
+	UserID string
+	Name   string
+	// Scopes 取值 read/render/execute/write/admin，write 仅限管理员与编辑者账号申请，admin 仅限管理员账号申请。
+	Scopes []string
+	// RateLimit 为每分钟请求上限，0 表示使用服务默认值。
+	RateLimit int
+	// DailyQuota 为每 24 小时的请求配额，0 表示不限。
+	DailyQuota int
+	Actor      string
+}
+
+// CreatedAPIKey 包含只返回一次的明文密钥。
+type CreatedAPIKey struct {
+	Key    *domain.APIKey `json:"api_key"`
+	Secret string         `json:"secret"`
+}
+
+// CreateAPIKey 为用户签发 API Key，库中只保存 HMAC 摘要与前缀。
+func (s *Service) CreateAPIKey(ctx context.Context, input CreateAPIKeyInput) (*CreatedAPIKey, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || input.RateLimit < 0 || input.RateLimit > maxAPIKeyRateLimit || input.DailyQuota < 0 || input.DailyQuota > maxAPIKeyDailyQuota {
+		return nil, ErrInvalidInput
+	}
+	user, err := s.getUser(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != userStatusActive {
+		return nil, userStatusError(user.Status)
+	}
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if scope == domain.APIKeyScopeAdmin && user.Role != roleAdmin {
+			return nil, ErrInvalidScope
+		}
+		if scope == domain.APIKeyScopeWrite && user.Role != roleAdmin && user.Role != roleEditor {
+			return nil, ErrInvalidScope
+		}
+	}
+
+	token, _, err := authutil.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	secret := apiKeyPrefix + token
+	key := &domain.APIKey{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		Name:       name,
+		Prefix:     secret[:apiKeyDisplayChars],
+		KeyHash:    authutil.HashAPIKey(secret, s.cfg.APIKeyHashSecret),
+		Scopes:     scopes,
+		RateLimit:  input.RateLimit,
+		DailyQuota: input.DailyQuota,
+		CreatedBy:  optionalString(input.Actor),
+		CreatedAt:  s.nowFn(),
+	}
+	if err := s.repos.APIKeys.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	_ = s.recordAudit(ctx, AuditAPIKeyCreated, input.Actor, auditTargetAPIKey, key.ID, map[string]interface{}{
+		"user_id":     user.ID,
+		"name":        key.Name,
+		"prefix":      key.Prefix,
+		"scopes":      key.Scopes,
+		"rate_limit":  key.RateLimit,
+		"daily_quota": key.DailyQuota,
+	})
+	return &CreatedAPIKey{Key: key, Secret: secret}, nil
+}
+
+// ListAPIKeys 返回用户名下的 API Key（含已吊销）。
+func (s *Service) ListAPIKeys(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	return s.repos.APIKeys.ListByUser(ctx, userID)
+}
+
+// RevokeAPIKey 吊销用户名下的 API Key，立即生效。
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID, actor string) error {
+	key, err := s.repos.APIKeys.GetByID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	if key.UserID != userID || key.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+	if err := s.repos.APIKeys.Revoke(ctx, key.ID, s.nowFn()); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	_ = s.recordAudit(ctx, AuditAPIKeyRevoked, actor, auditTargetAPIKey, key.ID, map[string]interface{}{
+		"user_id": key.UserID,
+		"prefix":  key.Prefix,
+	})
+	return nil
+}
+
+// AuthenticateAPIKey 校验明文密钥，返回密钥及其所属用户；已吊销或所属用户未激活时返回 ErrAPIKeyInvalid。
+func (s *Service) AuthenticateAPIKey(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error) {
+	secret = strings.TrimSpace(secret)
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+	key, err := s.repos.APIKeys.GetByHash(ctx, authutil.HashAPIKey(secret, s.cfg.APIKeyHashSecret))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, ErrAPIKeyInvalid
+		}
+		return nil, nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+	user, err := s.repos.Users.GetByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, ErrAPIKeyInvalid
+		}
+		return nil, nil, err
+	}
+	if user.Status != userStatusActive {
+		return nil, nil, ErrAPIKeyInvalid
+	}
+	_ = s.repos.APIKeys.TouchLastUsed(ctx, key.ID, s.nowFn())
+	return key, user, nil
+}
+
+// normalizeScopes 去重排序并校验取值，至少需要一个范围。
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		switch scope {
+		case domain.APIKeyScopeRead, domain.APIKeyScopeRender, domain.APIKeyScopeExecute, domain.APIKeyScopeWrite, domain.APIKeyScopeAdmin:
+		default:
+			return nil, ErrInvalidScope
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		normalized = append(normalized, scope)
+	}
+	if len(normalized) == 0 {
+		return nil, ErrInvalidScope
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+
+	auditsvc "github.com/zacharykka/prompt-manager/internal/service/audit"
+)
+
+// 认证与用户相关的审计动作。
+const (
+	AuditLoginSucceeded    = "auth.login.succeeded"
+	AuditLoginFailed       = "auth.login.failed"
+	AuditUserCreated       = "user.created"
+	AuditSigningKeyRotated = "auth.signing_key.rotated"
+	auditTargetUser        = "user"
+	auditTargetSigningKey  = "signing_key"
+)
+
+// recordAudit 写入通用审计日志，未配置审计仓储时跳过。
+func (s *Service) recordAudit(ctx context.Context, action, actor, targetType, targetID string, payload map[string]interface{}) error {
+	return auditsvc.Record(ctx, s.repos.AuditLogs, auditsvc.Entry{
+		Action:     action,
+		Actor:      actor,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Payload:    payload,
+		CreatedAt:  s.nowFn(),
+	})
+}
+
+// recordLoginFailure 记录登录失败；审计写入失败不影响原始错误的返回。
+func (s *Service) recordLoginFailure(ctx context.Context, provider, email, reason string) {
+	_ = s.recordAudit(ctx, AuditLoginFailed, email, auditTargetUser, "", map[string]interface{}{
+		"provider": provider,
+		"reason":   reason,
+	})
+}
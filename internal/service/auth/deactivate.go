@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// AuditUserDeactivated 记录管理员停用用户并移交其资源。
+const AuditUserDeactivated = "user.deactivated"
+
+// UserDeactivationResult 汇总停用结果，Moved 为各表移交的行数。
+type UserDeactivationResult struct {
+	User          *domain.User     `json:"user"`
+	TransferredTo *domain.User     `json:"transferred_to"`
+	Moved         map[string]int64 `json:"moved"`
+}
+
+// DeactivateUser 停用用户，并把其创建的 Prompt、版本、流水线、工作区等归属移交给 transferToID，
+// 避免离职后资源无人负责。外部身份、登录记录与成员关系保留在原账号上，需要合并账号时使用 MergeUsers。
+// actor 不能停用自己，以免管理员误操作把自己锁在系统外。
+func (s *Service) DeactivateUser(ctx context.Context, userID, transferToID, actor string) (*UserDeactivationResult, error) {
+	userID = strings.TrimSpace(userID)
+	transferToID = strings.TrimSpace(transferToID)
+	if userID == "" || transferToID == "" || userID == transferToID {
+		return nil, ErrInvalidInput
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(user.Email, actor) || user.ID == actor {
+		return nil, ErrInvalidInput
+	}
+	recipient, err := s.getUser(ctx, transferToID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Status != userStatusActive {
+		return nil, userStatusError(recipient.Status)
+	}
+
+	moved, err := s.repos.Users.TransferOwnership(ctx, domain.UserReassignment{
+		FromUserID: user.ID,
+		ToUserID:   recipient.ID,
+		FromActors: []string{user.Email, user.ID},
+		ToActor:    recipient.Email,
+		FromStatus: userStatusDisabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.recordAudit(ctx, AuditUserDeactivated, actor, auditTargetUser, user.ID, map[string]interface{}{
+		"email":             user.Email,
+		"transfer_to":       recipient.ID,
+		"transfer_to_email": recipient.Email,
+		"moved":             moved,
+	})
+
+	if user, err = s.repos.Users.GetByID(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return &UserDeactivationResult{User: user, TransferredTo: recipient, Moved: moved}, nil
+}
@@ -21,6 +21,42 @@ var (
 	ErrOAuthExchangeFailed = errors.New("oauth exchange failed")
 	// ErrOAuthEmailMissing 无法获取有效的邮箱信息。
 	ErrOAuthEmailMissing = errors.New("oauth email missing")
+	// ErrOAuthRedirectNotAllowed 回跳地址或客户端来源不在白名单内。
+	ErrOAuthRedirectNotAllowed = errors.New("oauth redirect origin not allowed")
 	// ErrOAuthOrgUnauthorized 用户不属于允许的组织。
 	ErrOAuthOrgUnauthorized = errors.New("oauth organization not allowed")
+	// ErrOAuthTeamUnauthorized 用户不属于允许的团队。
+	ErrOAuthTeamUnauthorized = errors.New("oauth team not allowed")
+	// ErrInvalidAlgorithm 不支持的签名算法。
+	ErrInvalidAlgorithm = errors.New("invalid signing algorithm")
+	// ErrSigningKeysUnavailable 未配置签名密钥存储。
+	ErrSigningKeysUnavailable = errors.New("signing key store unavailable")
+	// ErrOrganizationNotFound 轮换签名密钥时指定的组织不存在。
+	ErrOrganizationNotFound = errors.New("organization not found")
+	// ErrInvalidRole 角色不在 admin/editor/viewer 之内。
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvitationInvalid 邀请令牌不存在或已被使用。
+	ErrInvitationInvalid = errors.New("invitation invalid")
+	// ErrInvitationExpired 邀请令牌已过期。
+	ErrInvitationExpired = errors.New("invitation expired")
+	// ErrRegistrationDisabled 未开放自助注册。
+	ErrRegistrationDisabled = errors.New("registration disabled")
+	// ErrEmailDomainNotAllowed 邮箱域名不在注册白名单内。
+	ErrEmailDomainNotAllowed = errors.New("email domain not allowed")
+	// ErrUserPending 用户等待管理员审批。
+	ErrUserPending = errors.New("user pending approval")
+	// ErrUserNotPending 用户不处于待审批状态。
+	ErrUserNotPending = errors.New("user not pending approval")
+	// ErrUserNotFound 用户不存在。
+	ErrUserNotFound = errors.New("user not found")
+	// ErrProviderUnsupported 不支持绑定该外部身份提供方。
+	ErrProviderUnsupported = errors.New("identity provider not supported")
+	// ErrIdentityConflict 外部身份已绑定其他账号，或账号已绑定同一提供方的其他身份。
+	ErrIdentityConflict = errors.New("identity already linked")
+	// ErrInvalidScope API Key 权限范围无效，或非管理员申请 admin 范围。
+	ErrInvalidScope = errors.New("invalid api key scope")
+	// ErrAPIKeyNotFound API Key 不存在、已吊销或不属于当前用户。
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyInvalid API Key 无效、已吊销或所属用户不可用。
+	ErrAPIKeyInvalid = errors.New("api key invalid")
 )
@@ -0,0 +1,13 @@
+package auth
+
+// SetGitHubClientSecret 在密钥轮换后替换 GitHub OAuth client secret，后续授权码交换立即使用新值。
+func (s *Service) SetGitHubClientSecret(secret string) {
+	s.githubSecret.Store(&secret)
+}
+
+func (s *Service) githubClientSecret() string {
+	if secret := s.githubSecret.Load(); secret != nil {
+		return *secret
+	}
+	return s.cfg.GitHub.ClientSecret
+}
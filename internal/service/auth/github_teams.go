@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/zacharykka/prompt-manager/internal/config"
+)
+
+// GitHub 登录可用于映射规则的声明名。
+const (
+	claimOrgs        = "orgs"
+	claimTeams       = "teams"
+	claimEmail       = "email"
+	claimEmailDomain = "email_domain"
+	claimLogin       = "login"
+)
+
+// gitHubMembership 保存用户所属组织与团队，键统一为小写的 org 与 org/team-slug。
+type gitHubMembership struct {
+	orgs  map[string]struct{}
+	teams map[string]struct{}
+}
+
+func (m *gitHubMembership) matches(entry string) bool {
+	key := strings.ToLower(strings.TrimSpace(entry))
+	if strings.Contains(key, "/") {
+		_, ok := m.teams[key]
+		return ok
+	}
+	_, ok := m.orgs[key]
+	return ok
+}
+
+// loadGitHubMembership 按配置需要拉取组织与团队，未配置相关限制或映射时不发起请求。
+func (s *Service) loadGitHubMembership(ctx context.Context, accessToken string) (*gitHubMembership, error) {
+	membership := &gitHubMembership{orgs: map[string]struct{}{}, teams: map[string]struct{}{}}
+	needOrgs := len(s.cfg.GitHub.AllowedOrgs) > 0
+	needTeams := len(s.cfg.GitHub.AllowedTeams) > 0
+	for _, rule := range s.gitHubRoleMappingRules() {
+		switch rule.Claim {
+		case claimOrgs:
+			needOrgs = true
+		case claimTeams:
+			needTeams = true
+		}
+	}
+
+	if needOrgs {
+		orgs, err := s.fetchGitHubOrgs(ctx, accessToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, org := range orgs {
+			membership.orgs[strings.ToLower(org)] = struct{}{}
+		}
+	}
+	if needTeams {
+		teams, err := s.fetchGitHubTeams(ctx, accessToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			membership.teams[strings.ToLower(team)] = struct{}{}
+		}
+	}
+	return membership, nil
+}
+
+// ensureGitHubAccess 校验组织与团队白名单，两者同时配置时需同时满足。
+func (s *Service) ensureGitHubAccess(membership *gitHubMembership) error {
+	if len(s.cfg.GitHub.AllowedOrgs) > 0 && !matchesAny(membership, s.cfg.GitHub.AllowedOrgs) {
+		return ErrOAuthOrgUnauthorized
+	}
+	if len(s.cfg.GitHub.AllowedTeams) > 0 && !matchesAny(membership, s.cfg.GitHub.AllowedTeams) {
+		return ErrOAuthTeamUnauthorized
+	}
+	return nil
+}
+
+func matchesAny(membership *gitHubMembership, entries []string) bool {
+	for _, entry := range entries {
+		if membership.matches(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitHubRoleMappingRules 返回作用于 GitHub 登录的映射规则：github.roleMappings 简写展开为 orgs/teams 规则，
+// 再追加 auth.roleMapping 中 provider 为 github 或为空的规则。
+func (s *Service) gitHubRoleMappingRules() []config.RoleMappingRule {
+	var rules []config.RoleMappingRule
+	for _, mapping := range s.cfg.GitHub.RoleMappings {
+		claim := claimOrgs
+		if strings.Contains(mapping.Match, "/") {
+			claim = claimTeams
+		}
+		rules = append(rules, config.RoleMappingRule{Provider: providerGitHub, Claim: claim, Values: []string{mapping.Match}, Role: mapping.Role})
+	}
+	for _, rule := range s.cfg.RoleMapping.Rules {
+		if rule.Provider == "" || strings.EqualFold(rule.Provider, providerGitHub) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// gitHubClaims 将 GitHub 用户信息与成员关系转换为映射引擎使用的声明。
+func gitHubClaims(info *gitHubUserInfo, email string, membership *gitHubMembership) IdentityClaims {
+	claims := IdentityClaims{
+		claimEmail: {email},
+		claimLogin: {info.Login},
+	}
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		claims[claimEmailDomain] = []string{email[at+1:]}
+	}
+	for org := range membership.orgs {
+		claims[claimOrgs] = append(claims[claimOrgs], org)
+	}
+	for team := range membership.teams {
+		claims[claimTeams] = append(claims[claimTeams], team)
+	}
+	return claims
+}
+
+func (s *Service) fetchGitHubTeams(ctx context.Context, accessToken string) ([]string, error) {
+	resp, err := s.doGitHubRequest(ctx, http.MethodGet, "/user/teams?per_page=100", accessToken)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%w: fetch teams", ErrOAuthExchangeFailed)
+	}
+
+	var payload []struct {
+		Slug         string `json:"slug"`
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("%w: decode teams", ErrOAuthExchangeFailed)
+	}
+
+	var teams []string
+	for _, item := range payload {
+		org := strings.TrimSpace(item.Organization.Login)
+		slug := strings.TrimSpace(item.Slug)
+		if org != "" && slug != "" {
+			teams = append(teams, org+"/"+slug)
+		}
+	}
+	return teams, nil
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+)
+
+// AuditIdentityLinked 记录用户主动为账号绑定外部身份。
+const AuditIdentityLinked = "user.identity.linked"
+
+// ListIdentities 返回用户已绑定的外部身份。
+func (s *Service) ListIdentities(ctx context.Context, userID string) ([]*domain.UserIdentity, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrUserNotFound
+	}
+	return s.repos.UserIdentities.ListByUser(ctx, userID)
+}
+
+// IdentityLinkURL 为已登录用户生成绑定外部身份的授权地址，回调时身份挂到该用户而非按邮箱匹配。
+// 同时返回随机绑定值：调用方需将其保存在发起绑定的浏览器中（HttpOnly Cookie），回调时原样传给
+// HandleGitHubCallback；state 只记录其摘要，他人拿到授权地址也无法完成绑定。
+func (s *Service) IdentityLinkURL(ctx context.Context, userID, provider, redirectURI, responseMode, clientOrigin string) (string, string, error) {
+	if strings.ToLower(strings.TrimSpace(provider)) != providerGitHub {
+		return "", "", ErrProviderUnsupported
+	}
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", "", ErrUserNotFound
+		}
+		return "", "", err
+	}
+	if user.Status != userStatusActive {
+		return "", "", userStatusError(user.Status)
+	}
+	binding, bindingHash, err := authutil.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	authorizeURL, err := s.gitHubAuthorizeURL(redirectURI, responseMode, clientOrigin, user.ID, bindingHash)
+	if err != nil {
+		return "", "", err
+	}
+	return authorizeURL, binding, nil
+}
+
+// linkBindingMatches 以常量时间比较回调携带的绑定值与 state 中的摘要，任一为空时不匹配。
+func linkBindingMatches(hash, binding string) bool {
+	binding = strings.TrimSpace(binding)
+	if hash == "" || binding == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(authutil.HashOpaqueToken(binding))) == 1
+}
+
+// linkGitHubIdentity 在绑定模式下把 GitHub 身份挂到 state 中的用户。
+// existing/lookupErr 为按 provider_user_id 查询的结果：身份已属于其他用户，
+// 或当前用户已绑定另一个 GitHub 账号时返回 ErrIdentityConflict；重复绑定同一身份视为成功。
+func (s *Service) linkGitHubIdentity(ctx context.Context, userID string, existing *domain.UserIdentity, lookupErr error, ghUser *gitHubUserInfo) (*domain.User, error) {
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrOAuthStateInvalid
+		}
+		return nil, err
+	}
+
+	switch {
+	case lookupErr == nil:
+		if existing.UserID != user.ID {
+			return user, ErrIdentityConflict
+		}
+		return user, nil
+	case !errors.Is(lookupErr, domain.ErrNotFound):
+		return nil, lookupErr
+	}
+
+	identities, err := s.repos.UserIdentities.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		if identity.Provider == providerGitHub {
+			return user, ErrIdentityConflict
+		}
+	}
+
+	identity := newGitHubIdentity(user.ID, ghUser)
+	if err := s.repos.UserIdentities.Create(ctx, identity); err != nil {
+		if isUniqueViolation(err) {
+			return user, ErrIdentityConflict
+		}
+		return nil, err
+	}
+	_ = s.recordAudit(ctx, AuditIdentityLinked, user.Email, auditTargetUser, user.ID, map[string]interface{}{
+		"provider":         providerGitHub,
+		"provider_user_id": identity.ProviderUserID,
+		"provider_login":   strings.TrimSpace(ghUser.Login),
+	})
+	return user, nil
+}
+
+func newGitHubIdentity(userID string, ghUser *gitHubUserInfo) *domain.UserIdentity {
+	return &domain.UserIdentity{
+		ID:             uuid.NewString(),
+		UserID:         userID,
+		Provider:       providerGitHub,
+		ProviderUserID: ghUser.ID,
+		ProviderLogin:  optionalString(ghUser.Login),
+		AvatarURL:      optionalString(ghUser.AvatarURL),
+	}
+}
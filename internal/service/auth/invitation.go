@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+)
+
+// 邀请相关的审计动作。
+const (
+	AuditUserInvited        = "user.invited"
+	AuditInvitationAccepted = "user.invitation.accepted"
+	providerInvitation      = "invitation"
+	defaultInvitationTTL    = 7 * 24 * time.Hour
+)
+
+// CreateInvitationInput 定义邀请成员所需字段，WorkspaceID 为空时邀请加入默认工作区。
+type CreateInvitationInput struct {
+	Email       string
+	Role        string
+	WorkspaceID string
+	InvitedBy   string
+}
+
+// CreateInvitation 生成带有效期的邀请令牌，仅返回一次明文令牌，数据库只保存其哈希。
+func (s *Service) CreateInvitation(ctx context.Context, input CreateInvitationInput) (*domain.Invitation, string, error) {
+	email := normalizeEmail(input.Email)
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, "", ErrInvalidInput
+	}
+	role := strings.ToLower(strings.TrimSpace(input.Role))
+	if !isValidRole(role) {
+		return nil, "", ErrInvalidRole
+	}
+	workspaceID := strings.TrimSpace(input.WorkspaceID)
+	if workspaceID == "" {
+		workspaceID = domain.DefaultWorkspaceID
+	}
+	if _, err := s.repos.Workspaces.GetWorkspace(ctx, workspaceID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, "", domain.ErrWorkspaceNotFound
+		}
+		return nil, "", err
+	}
+
+	token, tokenHash, err := authutil.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	ttl := s.cfg.InvitationTTL
+	if ttl <= 0 {
+		ttl = defaultInvitationTTL
+	}
+	invitation := &domain.Invitation{
+		ID:          uuid.NewString(),
+		Email:       email,
+		Role:        role,
+		WorkspaceID: workspaceID,
+		TokenHash:   tokenHash,
+		InvitedBy:   optionalString(input.InvitedBy),
+		ExpiresAt:   s.nowFn().Add(ttl).UTC(),
+	}
+	if err := s.repos.Invitations.Create(ctx, invitation); err != nil {
+		return nil, "", err
+	}
+
+	_ = s.recordAudit(ctx, AuditUserInvited, input.InvitedBy, auditTargetUser, "", map[string]interface{}{
+		"email":         email,
+		"role":          role,
+		"workspace_id":  workspaceID,
+		"invitation_id": invitation.ID,
+	})
+
+	stored, err := s.repos.Invitations.GetByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return nil, "", err
+	}
+	return stored, token, nil
+}
+
+// ListPendingInvitations 返回尚未接受且未过期的邀请。
+func (s *Service) ListPendingInvitations(ctx context.Context) ([]*domain.Invitation, error) {
+	return s.repos.Invitations.ListPending(ctx, s.nowFn())
+}
+
+// AcceptInvitation 接受邀请：邮箱未注册时以 password 创建账号，已注册时需校验其密码后关联。
+// 邀请默认工作区时 role 作为新账号的全局角色；邀请其他工作区时新账号全局角色为 viewer，并以 role 加入该工作区。
+func (s *Service) AcceptInvitation(ctx context.Context, token, password string) (*Tokens, *domain.User, error) {
+	invitation, err := s.repos.Invitations.GetByTokenHash(ctx, authutil.HashOpaqueToken(strings.TrimSpace(token)))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, ErrInvitationInvalid
+		}
+		return nil, nil, err
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, nil, ErrInvitationInvalid
+	}
+	if !s.nowFn().Before(invitation.ExpiresAt) {
+		return nil, nil, ErrInvitationExpired
+	}
+
+	user, created, err := s.userForInvitation(ctx, invitation, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if invitation.WorkspaceID != domain.DefaultWorkspaceID {
+		if err := s.repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      user.ID,
+			Role:        invitation.Role,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := s.repos.Invitations.MarkAccepted(ctx, invitation.ID, user.ID, s.nowFn()); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, ErrInvitationInvalid
+		}
+		return nil, nil, err
+	}
+
+	_ = s.recordAudit(ctx, AuditInvitationAccepted, user.Email, auditTargetUser, user.ID, map[string]interface{}{
+		"invitation_id": invitation.ID,
+		"workspace_id":  invitation.WorkspaceID,
+		"role":          invitation.Role,
+		"new_account":   created,
+	})
+
+	workspaceID := ""
+	if invitation.WorkspaceID != domain.DefaultWorkspaceID {
+		workspaceID = invitation.WorkspaceID
+	}
+	tokens, err := s.issueTokensForWorkspace(ctx, user, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, user, nil
+}
+
+func (s *Service) userForInvitation(ctx context.Context, invitation *domain.Invitation, password string) (*domain.User, bool, error) {
+	user, err := s.repos.Users.GetByEmail(ctx, invitation.Email)
+	if err == nil {
+		if !s.verifyPassword(ctx, user, password) {
+			return nil, false, ErrInvalidCredentials
+		}
+		if user.Status != userStatusActive {
+			return nil, false, userStatusError(user.Status)
+		}
+		return user, false, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, false, err
+	}
+
+	if password == "" {
+		return nil, false, ErrInvalidInput
+	}
+	if err := s.validatePassword(ctx, password, invitation.Email); err != nil {
+		return nil, false, err
+	}
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, false, err
+	}
+	role := invitation.Role
+	if invitation.WorkspaceID != domain.DefaultWorkspaceID {
+		role = roleViewer
+	}
+	user = &domain.User{
+		ID:             uuid.NewString(),
+		Email:          invitation.Email,
+		HashedPassword: hash,
+		Role:           role,
+		Status:         userStatusActive,
+	}
+	if err := s.repos.Users.Create(ctx, user); err != nil {
+		if isUniqueViolation(err) {
+			return nil, false, ErrUserExists
+		}
+		return nil, false, err
+	}
+	_ = s.recordAudit(ctx, AuditUserCreated, invitation.Email, auditTargetUser, user.ID, map[string]interface{}{
+		"provider": providerInvitation,
+		"role":     user.Role,
+	})
+
+	created, err := s.repos.Users.GetByEmail(ctx, invitation.Email)
+	if err != nil {
+		return nil, false, err
+	}
+	return created, true, nil
+}
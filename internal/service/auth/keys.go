@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+)
+
+const (
+	signingKeyStatusActive = "active"
+	// keyReloadInterval 限制遇到未知 kid 时重新加载密钥的频率，避免伪造 kid 造成数据库压力。
+	keyReloadInterval = 30 * time.Second
+)
+
+// InitSigningKeys 从存储加载签名密钥；默认组织及各组织不存在当前密钥或算法与配置不一致时自动轮换。
+// 未调用时访问令牌继续使用 accessTokenSecret 签发（不带 kid）。
+func (s *Service) InitSigningKeys(ctx context.Context) error {
+	if s.repos.SigningKeys == nil {
+		return ErrSigningKeysUnavailable
+	}
+	if err := s.ReloadSigningKeys(ctx); err != nil {
+		return err
+	}
+	desired, err := authutil.NormalizeAlgorithm(s.cfg.Signing.Algorithm)
+	if err != nil {
+		return ErrInvalidAlgorithm
+	}
+
+	orgIDs := []string{domain.DefaultOrganizationID}
+	if s.repos.Workspaces != nil {
+		orgs, err := s.repos.Workspaces.ListOrganizations(ctx)
+		if err != nil {
+			return err
+		}
+		for _, org := range orgs {
+			if org.ID != domain.DefaultOrganizationID {
+				orgIDs = append(orgIDs, org.ID)
+			}
+		}
+	}
+	for _, orgID := range orgIDs {
+		active := s.keys.TenantActive(signingTenant(orgID))
+		if active != nil && active.Algorithm == desired {
+			continue
+		}
+		if _, err := s.RotateSigningKey(ctx, orgID, desired, "system"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReloadSigningKeys 重新从存储加载当前与未过期的历史密钥。
+func (s *Service) ReloadSigningKeys(ctx context.Context) error {
+	if s.repos.SigningKeys == nil {
+		return ErrSigningKeysUnavailable
+	}
+	now := s.nowFn()
+	records, err := s.repos.SigningKeys.ListUsable(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var (
+		active       *authutil.SigningKey
+		keys         []*authutil.SigningKey
+		tenantActive []*authutil.SigningKey
+		seenTenants  = map[string]bool{}
+	)
+	for _, record := range records {
+		material, err := s.decryptSigningKey(record)
+		if err != nil {
+			return fmt.Errorf("decrypt signing key %s: %w", record.ID, err)
+		}
+		key, err := authutil.ParseSigningKey(record.ID, record.Algorithm, material)
+		if err != nil {
+			return fmt.Errorf("parse signing key %s: %w", record.ID, err)
+		}
+		key.Tenant = signingTenant(record.OrganizationID)
+		key.CreatedAt = record.CreatedAt
+		key.RetiresAt = record.RetiresAt
+		if record.Status == signingKeyStatusActive && !seenTenants[key.Tenant] {
+			seenTenants[key.Tenant] = true
+			if key.Tenant == "" {
+				active = key
+			} else {
+				tenantActive = append(tenantActive, key)
+			}
+			continue
+		}
+		keys = append(keys, key)
+	}
+	s.keys.Replace(active, keys, tenantActive...)
+
+	s.keyMu.Lock()
+	s.keysLoadedAt = now
+	s.keyMu.Unlock()
+	return nil
+}
+
+// RotateSigningKey 为组织生成新的当前密钥（organizationID 为空表示默认组织），
+// 该组织的旧密钥在 rotationGrace 内仍可验签，其他组织的密钥不受影响。
+func (s *Service) RotateSigningKey(ctx context.Context, organizationID, algorithm, actor string) (*domain.SigningKey, error) {
+	if s.repos.SigningKeys == nil {
+		return nil, ErrSigningKeysUnavailable
+	}
+	if organizationID == "" {
+		organizationID = domain.DefaultOrganizationID
+	}
+	if organizationID != domain.DefaultOrganizationID {
+		if s.repos.Workspaces == nil {
+			return nil, ErrOrganizationNotFound
+		}
+		if _, err := s.repos.Workspaces.GetOrganization(ctx, organizationID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, ErrOrganizationNotFound
+			}
+			return nil, err
+		}
+	}
+	if algorithm == "" {
+		algorithm = s.cfg.Signing.Algorithm
+	}
+	alg, err := authutil.NormalizeAlgorithm(algorithm)
+	if err != nil {
+		return nil, ErrInvalidAlgorithm
+	}
+
+	key, err := authutil.GenerateSigningKey(uuid.NewString(), alg)
+	if err != nil {
+		return nil, err
+	}
+	material, err := key.MarshalPrivate()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := authutil.EncryptSecret(s.tenantKeySecret(organizationID), material)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &domain.SigningKey{
+		ID:             key.ID,
+		OrganizationID: organizationID,
+		Algorithm:      key.Algorithm,
+		EncryptedKey:   encrypted,
+		Status:         signingKeyStatusActive,
+	}
+	if actor != "" {
+		record.CreatedBy = &actor
+	}
+	if err := s.repos.SigningKeys.Rotate(ctx, record, s.nowFn().Add(s.rotationGrace())); err != nil {
+		return nil, err
+	}
+	if err := s.ReloadSigningKeys(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.recordAudit(ctx, AuditSigningKeyRotated, actor, auditTargetSigningKey, key.ID, map[string]interface{}{
+		"algorithm":       key.Algorithm,
+		"organization_id": organizationID,
+	}); err != nil {
+		return nil, err
+	}
+	return s.findSigningKey(ctx, key.ID)
+}
+
+// ListSigningKeys 返回当前与仍可验签的历史密钥（不含私钥材料）。
+func (s *Service) ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error) {
+	if s.repos.SigningKeys == nil {
+		return nil, ErrSigningKeysUnavailable
+	}
+	return s.repos.SigningKeys.ListUsable(ctx, s.nowFn())
+}
+
+// ParseAccessToken 校验访问令牌；遇到未知 kid 时（例如其他实例刚完成轮换）会限频重新加载密钥后重试。
+func (s *Service) ParseAccessToken(token string) (*authutil.Claims, error) {
+	claims, err := s.keys.Parse(token)
+	if err == nil || !errors.Is(err, authutil.ErrUnknownKey) || s.repos.SigningKeys == nil {
+		return claims, err
+	}
+
+	s.keyMu.Lock()
+	stale := s.nowFn().Sub(s.keysLoadedAt) >= keyReloadInterval
+	s.keyMu.Unlock()
+	if !stale {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if reloadErr := s.ReloadSigningKeys(ctx); reloadErr != nil {
+		return nil, err
+	}
+	return s.keys.Parse(token)
+}
+
+// SigningKeys 返回运行时密钥集合，供 JWKS 等只读场景使用。
+func (s *Service) SigningKeys() *authutil.KeySet {
+	return s.keys
+}
+
+func (s *Service) findSigningKey(ctx context.Context, kid string) (*domain.SigningKey, error) {
+	records, err := s.repos.SigningKeys.ListUsable(ctx, s.nowFn())
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.ID == kid {
+			return record, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (s *Service) keyEncryptionSecret() string {
+	if s.cfg.Signing.EncryptionKey != "" {
+		return s.cfg.Signing.EncryptionKey
+	}
+	return s.cfg.AccessTokenSecret
+}
+
+// tenantKeySecret 派生组织专属的密钥加密口令，一个组织的口令无法解密其他组织的签名密钥。
+func (s *Service) tenantKeySecret(organizationID string) string {
+	return authutil.TenantSecret(s.keyEncryptionSecret(), organizationID)
+}
+
+// decryptSigningKey 使用组织口令解密签名密钥；按组织加密之前写入的记录回退到全局口令。
+func (s *Service) decryptSigningKey(record *domain.SigningKey) ([]byte, error) {
+	material, err := authutil.DecryptSecret(s.tenantKeySecret(record.OrganizationID), record.EncryptedKey)
+	if err == nil {
+		return material, nil
+	}
+	if legacy, legacyErr := authutil.DecryptSecret(s.keyEncryptionSecret(), record.EncryptedKey); legacyErr == nil {
+		return legacy, nil
+	}
+	return nil, err
+}
+
+// signingTenant 将组织 ID 映射为 KeySet 中的租户标识，默认组织使用全局当前密钥。
+func signingTenant(organizationID string) string {
+	if organizationID == "" || organizationID == domain.DefaultOrganizationID {
+		return ""
+	}
+	return organizationID
+}
+
+// workspaceSigningTenant 返回工作区所属组织对应的签名租户。
+func (s *Service) workspaceSigningTenant(ctx context.Context, workspaceID string) (string, error) {
+	if workspaceID == "" || workspaceID == domain.DefaultWorkspaceID || s.repos.Workspaces == nil {
+		return "", nil
+	}
+	workspace, err := s.repos.Workspaces.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return signingTenant(workspace.OrganizationID), nil
+}
+
+func (s *Service) rotationGrace() time.Duration {
+	if s.cfg.Signing.RotationGrace > 0 {
+		return s.cfg.Signing.RotationGrace
+	}
+	if s.cfg.AccessTokenTTL > 0 {
+		return s.cfg.AccessTokenTTL
+	}
+	return 15 * time.Minute
+}
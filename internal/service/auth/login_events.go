@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/audit"
+)
+
+// 登录事件类型与相关审计动作。
+const (
+	loginEventLogin         = "login"
+	loginEventRefresh       = "refresh"
+	providerRefreshToken    = "refresh_token"
+	defaultLoginEventsLimit = 20
+	maxLoginEventsLimit     = 100
+
+	// AuditLoginNewDevice 记录用户首次以新设备登录，可作为告警来源。
+	AuditLoginNewDevice = "auth.login.new_device"
+)
+
+// LoginNotifier 在用户以新设备登录时发送提醒（如邮件、IM），未配置时仅写入审计日志。
+type LoginNotifier interface {
+	NotifyNewDevice(ctx context.Context, user *domain.User, event *domain.LoginEvent) error
+}
+
+// WithLoginNotifier 注入新设备登录提醒。
+func WithLoginNotifier(notifier LoginNotifier) Option {
+	return func(s *Service) {
+		s.loginNotifier = notifier
+	}
+}
+
+// ListLoginEvents 返回用户最近的登录与刷新记录。
+func (s *Service) ListLoginEvents(ctx context.Context, userID string, limit int) ([]*domain.LoginEvent, error) {
+	if limit <= 0 {
+		limit = defaultLoginEventsLimit
+	}
+	if limit > maxLoginEventsLimit {
+		limit = maxLoginEventsLimit
+	}
+	return s.repos.LoginEvents.ListByUser(ctx, userID, limit)
+}
+
+// recordLoginEvent 记录登录事件并检测新设备；写入失败不影响登录结果。
+// 用户已有登录记录但从未使用过该设备时视为新设备，首次登录不提醒。
+func (s *Service) recordLoginEvent(ctx context.Context, user *domain.User, eventType, provider string) {
+	if s.repos.LoginEvents == nil {
+		return
+	}
+	client := audit.ClientFromContext(ctx)
+	event := &domain.LoginEvent{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		EventType:  eventType,
+		Provider:   provider,
+		IPAddress:  optionalString(client.IP),
+		UserAgent:  optionalString(client.UserAgent),
+		DeviceHash: deviceHash(client.UserAgent),
+		CreatedAt:  s.nowFn().UTC(),
+	}
+
+	known, err := s.repos.LoginEvents.HasDevice(ctx, user.ID, event.DeviceHash)
+	if err != nil {
+		return
+	}
+	if !known {
+		previous, err := s.repos.LoginEvents.ListByUser(ctx, user.ID, 1)
+		if err != nil {
+			return
+		}
+		event.NewDevice = len(previous) > 0
+	}
+	if err := s.repos.LoginEvents.Create(ctx, event); err != nil {
+		return
+	}
+
+	if event.NewDevice {
+		_ = s.recordAudit(ctx, AuditLoginNewDevice, user.Email, auditTargetUser, user.ID, map[string]interface{}{
+			"provider":       provider,
+			"login_event_id": event.ID,
+		})
+		if s.loginNotifier != nil {
+			_ = s.loginNotifier.NotifyNewDevice(ctx, user, event)
+		}
+	}
+}
+
+// deviceHash 以规范化后的 User-Agent 作为设备指纹。
+func deviceHash(userAgent string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(userAgent))))
+	return hex.EncodeToString(sum[:])
+}
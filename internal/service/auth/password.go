@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zacharykka/prompt-manager/internal/config"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
+)
+
+const (
+	// AuditPasswordChanged 记录用户修改密码。
+	AuditPasswordChanged = "user.password_changed"
+	// PasswordUnchanged 表示新密码与当前密码相同。
+	PasswordUnchanged = "unchanged"
+)
+
+// PasswordPolicyError 表示密码未通过策略校验，Violations 为违规代码（见 pkg/auth 中的 Password* 常量）。
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet policy: " + strings.Join(e.Violations, ", ")
+}
+
+func newPasswordPolicy(cfg config.PasswordPolicyConfig) authutil.PasswordPolicy {
+	policy := authutil.PasswordPolicy{
+		MinLength:           cfg.MinLength,
+		MaxLength:           cfg.MaxLength,
+		RequireUppercase:    cfg.RequireUppercase,
+		RequireLowercase:    cfg.RequireLowercase,
+		RequireDigit:        cfg.RequireDigit,
+		RequireSymbol:       cfg.RequireSymbol,
+		MinCharacterClasses: cfg.MinCharacterClasses,
+		DenyCommon:          cfg.DenyCommon,
+		CheckBreached:       cfg.CheckBreached,
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = 8
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = 128
+	}
+	return policy
+}
+
+func newPasswordHasher(cfg config.PasswordHashingConfig) authutil.PasswordHasher {
+	return authutil.PasswordHasher{
+		Algorithm:         cfg.Algorithm,
+		BcryptCost:        cfg.BcryptCost,
+		Argon2Memory:      cfg.Argon2.Memory,
+		Argon2Iterations:  cfg.Argon2.Iterations,
+		Argon2Parallelism: cfg.Argon2.Parallelism,
+	}
+}
+
+// verifyPassword 校验密码，成功且哈希算法或参数落后于当前配置时透明重新哈希；升级失败不影响本次登录。
+func (s *Service) verifyPassword(ctx context.Context, user *domain.User, password string) bool {
+	if !s.hasher.Verify(user.HashedPassword, password) {
+		return false
+	}
+	if s.hasher.NeedsRehash(user.HashedPassword) {
+		if hash, err := s.hasher.Hash(password); err == nil {
+			if err := s.repos.Users.UpdatePassword(ctx, user.ID, hash); err == nil {
+				user.HashedPassword = hash
+			}
+		}
+	}
+	return true
+}
+
+// PasswordPolicy 返回当前生效的密码策略，供前端展示与本地预校验。
+func (s *Service) PasswordPolicy() authutil.PasswordPolicy {
+	return s.passwordPolicy
+}
+
+// validatePassword 按策略校验密码；泄露检查接口异常时放行，避免外部服务故障阻断注册。
+func (s *Service) validatePassword(ctx context.Context, password, email string) error {
+	violations := s.passwordPolicy.Validate(password, email)
+	if len(violations) == 0 && s.passwordPolicy.CheckBreached && s.breachChecker != nil {
+		if breached, err := s.breachChecker.Breached(ctx, password); err == nil && breached {
+			violations = append(violations, authutil.PasswordBreached)
+		}
+	}
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// ChangePassword 校验当前密码后按策略设置新密码。
+func (s *Service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if !s.hasher.Verify(user.HashedPassword, currentPassword) {
+		return ErrInvalidCredentials
+	}
+	if currentPassword == newPassword {
+		return &PasswordPolicyError{Violations: []string{PasswordUnchanged}}
+	}
+	if err := s.validatePassword(ctx, newPassword, user.Email); err != nil {
+		return err
+	}
+
+	hash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.Users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	_ = s.recordAudit(ctx, AuditPasswordChanged, user.Email, auditTargetUser, user.ID, nil)
+	return nil
+}
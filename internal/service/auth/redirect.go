@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+)
+
+// checkRedirectTargets 校验登录结果的投递目标（redirect_uri 与 client_origin）是否在来源白名单内。
+// 白名单为空时不做限制（仅允许非生产环境，由配置校验保证）。
+func checkRedirectTargets(allowlist []string, redirectURI, clientOrigin string) error {
+	if len(allowlist) == 0 {
+		return nil
+	}
+	for _, target := range []string{redirectURI, clientOrigin} {
+		if target == "" {
+			continue
+		}
+		origin, ok := originOf(target)
+		if !ok || !originAllowed(allowlist, origin) {
+			return ErrOAuthRedirectNotAllowed
+		}
+	}
+	return nil
+}
+
+// originOf 提取 http(s) URL 的 scheme://host[:port]，统一为小写。
+func originOf(raw string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" || u.User != nil {
+		return "", false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", false
+	}
+	return scheme + "://" + strings.ToLower(u.Host), true
+}
+
+// originAllowed 判断来源是否匹配白名单；条目形如 https://app.example.com 或 https://*.example.com，
+// 通配符仅匹配一个或多个子域名，不匹配裸域名。
+func originAllowed(allowlist []string, origin string) bool {
+	for _, entry := range allowlist {
+		pattern := strings.ToLower(strings.TrimRight(strings.TrimSpace(entry), "/"))
+		if pattern == "" {
+			continue
+		}
+		if pattern == origin {
+			return true
+		}
+		prefix, suffix, found := strings.Cut(pattern, "*")
+		if !found || !strings.HasPrefix(suffix, ".") {
+			continue
+		}
+		if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			if label := strings.TrimSuffix(strings.TrimPrefix(origin, prefix), suffix); label != "" && !strings.ContainsAny(label, "/:@") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/zacharykka/prompt-manager/internal/config"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// 用户状态。
+const (
+	userStatusActive   = "active"
+	userStatusPending  = "pending"
+	userStatusDisabled = "disabled"
+)
+
+// AuditUserApproved 记录管理员审批通过待激活用户。
+const AuditUserApproved = "user.approved"
+
+// Register 按注册策略创建密码账号：关闭注册时返回 ErrRegistrationDisabled，
+// 邮箱域名不在白名单时返回 ErrEmailDomainNotAllowed，审批模式下用户处于 pending 状态直至管理员批准。
+func (s *Service) Register(ctx context.Context, email, password, role string) (*domain.User, error) {
+	email = normalizeEmail(email)
+	if email == "" || !strings.Contains(email, "@") || password == "" {
+		return nil, ErrInvalidInput
+	}
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		role = roleViewer
+	}
+	if !isValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if err := s.checkRegistrationPolicy(email); err != nil {
+		return nil, err
+	}
+	if err := s.validatePassword(ctx, password, email); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	user := &domain.User{
+		ID:             uuid.NewString(),
+		Email:          email,
+		HashedPassword: hash,
+		Role:           role,
+		Status:         s.registrationStatus(),
+	}
+	if err := s.repos.Users.Create(ctx, user); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	_ = s.recordAudit(ctx, AuditUserCreated, email, auditTargetUser, user.ID, map[string]interface{}{
+		"provider": providerPassword,
+		"role":     user.Role,
+		"status":   user.Status,
+	})
+	return s.repos.Users.GetByID(ctx, user.ID)
+}
+
+// ListPendingUsers 返回等待管理员审批的用户。
+func (s *Service) ListPendingUsers(ctx context.Context) ([]*domain.User, error) {
+	return s.repos.Users.ListByStatus(ctx, userStatusPending)
+}
+
+// ApproveUser 将 pending 用户激活，非 pending 用户返回 ErrUserNotPending。
+func (s *Service) ApproveUser(ctx context.Context, userID, actor string) (*domain.User, error) {
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Status != userStatusPending {
+		return nil, ErrUserNotPending
+	}
+	if err := s.repos.Users.UpdateStatus(ctx, user.ID, userStatusActive); err != nil {
+		return nil, err
+	}
+	_ = s.recordAudit(ctx, AuditUserApproved, actor, auditTargetUser, user.ID, map[string]interface{}{
+		"email": user.Email,
+	})
+	return s.repos.Users.GetByID(ctx, user.ID)
+}
+
+// checkRegistrationPolicy 校验自助注册是否开放以及邮箱域名是否在白名单内。
+func (s *Service) checkRegistrationPolicy(email string) error {
+	if s.cfg.Registration.Mode == config.RegistrationClosed {
+		return ErrRegistrationDisabled
+	}
+	if len(s.cfg.Registration.AllowedDomains) == 0 {
+		return nil
+	}
+	domainPart := email[strings.LastIndex(email, "@")+1:]
+	for _, allowed := range s.cfg.Registration.AllowedDomains {
+		if strings.EqualFold(strings.TrimSpace(allowed), domainPart) {
+			return nil
+		}
+	}
+	return ErrEmailDomainNotAllowed
+}
+
+// registrationStatus 返回自助注册用户的初始状态。
+func (s *Service) registrationStatus() string {
+	if s.cfg.Registration.Mode == config.RegistrationApproval {
+		return userStatusPending
+	}
+	return userStatusActive
+}
+
+// userStatusError 将非激活状态映射为对应错误。
+func userStatusError(status string) error {
+	if status == userStatusPending {
+		return ErrUserPending
+	}
+	return ErrUserDisabled
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/zacharykka/prompt-manager/internal/config"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// AuditUserRoleMapped 记录登录时按身份提供方声明同步本地角色或工作区成员角色。
+const AuditUserRoleMapped = "user.role_mapped"
+
+// IdentityClaims 为外部身份提供方的声明，键为声明名（如 orgs、teams、groups、email_domain），值为多值列表。
+type IdentityClaims map[string][]string
+
+// roleMappingResult 为规则求值结果：全局角色与各工作区角色，均为命中规则中的最高角色。
+type roleMappingResult struct {
+	Role       string
+	Workspaces map[string]string
+}
+
+// evaluateRoleMappings 对声明应用映射规则，provider 为空的规则对所有提供方生效。
+func evaluateRoleMappings(rules []config.RoleMappingRule, provider string, claims IdentityClaims) roleMappingResult {
+	result := roleMappingResult{Workspaces: map[string]string{}}
+	for _, rule := range rules {
+		if rule.Provider != "" && !strings.EqualFold(rule.Provider, provider) {
+			continue
+		}
+		if !claimMatches(claims[strings.TrimSpace(rule.Claim)], rule.Values) {
+			continue
+		}
+		if role := strings.ToLower(strings.TrimSpace(rule.Role)); isValidRole(role) && roleRank(role) > roleRank(result.Role) {
+			result.Role = role
+		}
+		if workspaceID := strings.TrimSpace(rule.WorkspaceID); workspaceID != "" {
+			role := strings.ToLower(strings.TrimSpace(rule.WorkspaceRole))
+			if isValidRole(role) && roleRank(role) > roleRank(result.Workspaces[workspaceID]) {
+				result.Workspaces[workspaceID] = role
+			}
+		}
+	}
+	return result
+}
+
+// claimMatches 不区分大小写比较声明值与规则值，规则值支持 path.Match 通配（如 acme/*）。
+func claimMatches(values, patterns []string) bool {
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		for _, pattern := range patterns {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if pattern == value {
+				return true
+			}
+			if ok, err := path.Match(pattern, value); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyRoleMappings 按规则同步用户全局角色与工作区成员角色；无规则命中时保持不变。
+// 规则引用的工作区不存在时跳过，默认工作区的角色即全局角色，不单独维护成员关系。
+func (s *Service) applyRoleMappings(ctx context.Context, user *domain.User, provider string, rules []config.RoleMappingRule, claims IdentityClaims) (*domain.User, error) {
+	result := evaluateRoleMappings(rules, provider, claims)
+
+	if result.Role != "" && result.Role != user.Role {
+		if err := s.repos.Users.UpdateRole(ctx, user.ID, result.Role); err != nil {
+			return nil, err
+		}
+		_ = s.recordAudit(ctx, AuditUserRoleMapped, user.Email, auditTargetUser, user.ID, map[string]interface{}{
+			"provider":  provider,
+			"from_role": user.Role,
+			"to_role":   result.Role,
+		})
+		updated := *user
+		updated.Role = result.Role
+		user = &updated
+	}
+
+	for workspaceID, role := range result.Workspaces {
+		if workspaceID == domain.DefaultWorkspaceID || s.repos.Workspaces == nil {
+			continue
+		}
+		if _, err := s.repos.Workspaces.GetWorkspace(ctx, workspaceID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if err := s.repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{
+			WorkspaceID: workspaceID,
+			UserID:      user.ID,
+			Role:        role,
+		}); err != nil {
+			return nil, err
+		}
+		_ = s.recordAudit(ctx, AuditUserRoleMapped, user.Email, auditTargetUser, user.ID, map[string]interface{}{
+			"provider":       provider,
+			"workspace_id":   workspaceID,
+			"workspace_role": role,
+		})
+	}
+	return user, nil
+}
+
+// roleRank 返回角色权限高低，未知角色为 0。
+func roleRank(role string) int {
+	switch role {
+	case roleAdmin:
+		return 3
+	case roleEditor:
+		return 2
+	case roleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -20,6 +22,7 @@ import (
 
 const (
 	providerGitHub      = "github"
+	providerPassword    = "password"
 	tokenTypeOAuthState = "oauth_state"
 	gitHubUserAgent     = "prompt-manager-oauth"
 )
@@ -40,6 +43,15 @@ type Service struct {
 	githubAuthURL    string
 	githubTokenURL   string
 	githubAPIBaseURL string
+	keys             *authutil.KeySet
+	keyMu            sync.Mutex
+	keysLoadedAt     time.Time
+	passwordPolicy   authutil.PasswordPolicy
+	breachChecker    authutil.BreachChecker
+	hasher           authutil.PasswordHasher
+	loginNotifier    LoginNotifier
+	// githubSecret 为轮换后的 GitHub client secret，为空时使用 cfg 中的值。
+	githubSecret atomic.Pointer[string]
 }
 
 // Tokens 表示访问令牌与刷新令牌。
@@ -77,20 +89,33 @@ func WithGitHubEndpoints(authURL, tokenURL, apiBaseURL string) Option {
 	}
 }
 
+// WithBreachChecker 自定义泄露密码检查器（用于测试或私有部署的 HIBP 镜像）。
+func WithBreachChecker(checker authutil.BreachChecker) Option {
+	return func(s *Service) {
+		s.breachChecker = checker
+	}
+}
+
 // NewService 创建认证服务。
 func NewService(repos *domain.Repositories, cfg config.AuthConfig, opts ...Option) *Service {
 	svc := &Service{
 		repos:            repos,
 		cfg:              cfg,
-		nowFn:            time.Now,
+		nowFn:            func() time.Time { return time.Now().UTC() },
 		httpClient:       &http.Client{Timeout: 10 * time.Second},
 		githubAuthURL:    "https://github.com/login/oauth/authorize",
 		githubTokenURL:   "https://github.com/login/oauth/access_token",
 		githubAPIBaseURL: "https://api.github.com",
+		keys:             authutil.NewKeySet(cfg.AccessTokenSecret),
+		passwordPolicy:   newPasswordPolicy(cfg.PasswordPolicy),
+		hasher:           newPasswordHasher(cfg.PasswordHashing),
 	}
 	for _, opt := range opts {
 		opt(svc)
 	}
+	if svc.passwordPolicy.CheckBreached && svc.breachChecker == nil {
+		svc.breachChecker = authutil.NewHIBPChecker(svc.httpClient, cfg.PasswordPolicy.BreachAPIURL)
+	}
 	return svc
 }
 
@@ -113,16 +138,23 @@ func (s *Service) Login(ctx context.Context, email, password string) (*Tokens, *
 	user, err := s.repos.Users.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
+			s.recordLoginFailure(ctx, providerPassword, email, "unknown_user")
 			return nil, nil, ErrInvalidCredentials
 		}
 		return nil, nil, err
 	}
 
-	if user.Status != "active" {
-		return nil, nil, ErrUserDisabled
+	if user.Status != userStatusActive {
+		reason := "user_disabled"
+		if user.Status == userStatusPending {
+			reason = "user_pending"
+		}
+		s.recordLoginFailure(ctx, providerPassword, email, reason)
+		return nil, nil, userStatusError(user.Status)
 	}
 
-	if !authutil.VerifyPassword(user.HashedPassword, password) {
+	if !s.verifyPassword(ctx, user, password) {
+		s.recordLoginFailure(ctx, providerPassword, email, "invalid_password")
 		return nil, nil, ErrInvalidCredentials
 	}
 
@@ -130,11 +162,18 @@ func (s *Service) Login(ctx context.Context, email, password string) (*Tokens, *
 		return nil, nil, err
 	}
 
-	tokens, err := s.issueTokens(user)
+	tokens, err := s.issueTokens(ctx, user)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if err := s.recordAudit(ctx, AuditLoginSucceeded, user.Email, auditTargetUser, user.ID, map[string]interface{}{
+		"provider": providerPassword,
+	}); err != nil {
+		return nil, nil, err
+	}
+	s.recordLoginEvent(ctx, user, loginEventLogin, providerPassword)
+
 	return tokens, user, nil
 }
 
@@ -156,17 +195,46 @@ func (s *Service) Refresh(ctx context.Context, refreshToken string) (*Tokens, *d
 		}
 		return nil, nil, err
 	}
+	// 停用或合并后的账号不能再续期，已签发的访问令牌随过期失效。
+	if user.Status != userStatusActive {
+		return nil, nil, userStatusError(user.Status)
+	}
 
-	tokens, err := s.issueTokens(user)
+	tokens, err := s.issueTokensForWorkspace(ctx, user, claims.WorkspaceID)
 	if err != nil {
 		return nil, nil, err
 	}
+	s.recordLoginEvent(ctx, user, loginEventRefresh, providerRefreshToken)
 
 	return tokens, user, nil
 }
 
+// SwitchWorkspace 为用户签发选中指定工作区的新令牌；调用方需先校验用户对该工作区的访问权限。
+func (s *Service) SwitchWorkspace(ctx context.Context, userID, workspaceID string) (*Tokens, *domain.User, error) {
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, ErrTokenInvalid
+		}
+		return nil, nil, err
+	}
+	if user.Status != userStatusActive {
+		return nil, nil, userStatusError(user.Status)
+	}
+	tokens, err := s.issueTokensForWorkspace(ctx, user, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, user, nil
+}
+
 // GitHubAuthorizeURL 构造 GitHub OAuth 授权地址。
 func (s *Service) GitHubAuthorizeURL(redirectURI, responseMode, clientOrigin string) (string, error) {
+	return s.gitHubAuthorizeURL(redirectURI, responseMode, clientOrigin, "", "")
+}
+
+// gitHubAuthorizeURL 构造授权地址，linkUserID 非空时 state 以绑定模式携带当前用户与浏览器绑定值的摘要。
+func (s *Service) gitHubAuthorizeURL(redirectURI, responseMode, clientOrigin, linkUserID, linkBindingHash string) (string, error) {
 	if !s.cfg.GitHub.Enabled {
 		return "", ErrOAuthDisabled
 	}
@@ -175,10 +243,14 @@ func (s *Service) GitHubAuthorizeURL(redirectURI, responseMode, clientOrigin str
 	if err != nil {
 		return "", fmt.Errorf("%w: %v", ErrOAuthStateInvalid, err)
 	}
+	clientOrigin = strings.TrimSpace(clientOrigin)
+	if err := checkRedirectTargets(s.cfg.GitHub.AllowedRedirectOrigins, finalRedirect, clientOrigin); err != nil {
+		return "", err
+	}
 
 	mode := normalizeResponseMode(responseMode)
 
-	state, err := s.generateOAuthState(providerGitHub, finalRedirect, mode, clientOrigin)
+	state, err := s.generateOAuthState(providerGitHub, finalRedirect, mode, clientOrigin, linkUserID, linkBindingHash)
 	if err != nil {
 		return "", err
 	}
@@ -195,8 +267,37 @@ func (s *Service) GitHubAuthorizeURL(redirectURI, responseMode, clientOrigin str
 	return fmt.Sprintf("%s?%s", s.githubAuthURL, query.Encode()), nil
 }
 
-// HandleGitHubCallback 处理 GitHub OAuth 回调并返回本地令牌。
-func (s *Service) HandleGitHubCallback(ctx context.Context, code, state string) (*Tokens, *domain.User, string, string, string, error) {
+// HandleGitHubCallback 处理 GitHub OAuth 回调并返回本地令牌，登录结果写入审计日志。
+// linkBinding 为发起绑定的浏览器所持有的绑定值（见 IdentityLinkURL），绑定模式下必须与 state 匹配；
+// 绑定模式只挂载身份、不签发令牌，此时返回的 tokens 为 nil。
+func (s *Service) HandleGitHubCallback(ctx context.Context, code, state, linkBinding string) (*Tokens, *domain.User, string, string, string, error) {
+	tokens, user, redirectURI, responseMode, clientOrigin, err := s.handleGitHubCallback(ctx, code, state, linkBinding)
+	if err != nil {
+		if errors.Is(err, ErrOAuthDisabled) || errors.Is(err, ErrOAuthStateInvalid) || errors.Is(err, ErrOAuthRedirectNotAllowed) {
+			// state 无效的请求无法确认来源，不写入审计以免被刷量。
+			return nil, nil, "", "", "", err
+		}
+		email := ""
+		if user != nil {
+			email = user.Email
+		}
+		s.recordLoginFailure(ctx, providerGitHub, email, err.Error())
+		return nil, nil, "", "", "", err
+	}
+	if tokens == nil {
+		// 绑定身份不是登录，已记录 user.identity.linked 审计。
+		return nil, user, redirectURI, responseMode, clientOrigin, nil
+	}
+	if err := s.recordAudit(ctx, AuditLoginSucceeded, user.Email, auditTargetUser, user.ID, map[string]interface{}{
+		"provider": providerGitHub,
+	}); err != nil {
+		return nil, nil, "", "", "", err
+	}
+	s.recordLoginEvent(ctx, user, loginEventLogin, providerGitHub)
+	return tokens, user, redirectURI, responseMode, clientOrigin, nil
+}
+
+func (s *Service) handleGitHubCallback(ctx context.Context, code, state, linkBinding string) (*Tokens, *domain.User, string, string, string, error) {
 	if !s.cfg.GitHub.Enabled {
 		return nil, nil, "", "", "", ErrOAuthDisabled
 	}
@@ -206,18 +307,27 @@ func (s *Service) HandleGitHubCallback(ctx context.Context, code, state string)
 		return nil, nil, "", "", "", ErrOAuthStateInvalid
 	}
 
-	provider, finalRedirect, responseMode, clientOrigin, err := s.parseOAuthState(state)
+	parsedState, err := s.parseOAuthState(state)
 	if err != nil {
 		return nil, nil, "", "", "", ErrOAuthStateInvalid
 	}
-	if provider != providerGitHub {
+	if parsedState.provider != providerGitHub {
+		return nil, nil, "", "", "", ErrOAuthStateInvalid
+	}
+	// 绑定模式的 state 只能由发起绑定的浏览器完成，防止诱导他人把 GitHub 身份绑到攻击者账号。
+	if parsedState.linkUserID != "" && !linkBindingMatches(parsedState.linkBindingHash, linkBinding) {
 		return nil, nil, "", "", "", ErrOAuthStateInvalid
 	}
+	finalRedirect, responseMode, clientOrigin, linkUserID := parsedState.redirectURI, parsedState.responseMode, parsedState.clientOrigin, parsedState.linkUserID
 	if finalRedirect != "" {
 		if finalRedirect, err = s.normalizeRedirectURI(finalRedirect); err != nil {
 			return nil, nil, "", "", "", fmt.Errorf("%w: %v", ErrOAuthStateInvalid, err)
 		}
 	}
+	// 回调时按当前配置再次校验，白名单收紧后未过期的 state 也无法再投递令牌。
+	if err := checkRedirectTargets(s.cfg.GitHub.AllowedRedirectOrigins, finalRedirect, clientOrigin); err != nil {
+		return nil, nil, "", "", "", err
+	}
 	if clientOrigin == "" && finalRedirect != "" {
 		if parsed, err := url.Parse(finalRedirect); err == nil && parsed.Scheme != "" && parsed.Host != "" {
 			clientOrigin = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
@@ -242,7 +352,11 @@ func (s *Service) HandleGitHubCallback(ctx context.Context, code, state string)
 		}
 	}
 
-	if err := s.ensureGitHubOrgAccess(ctx, token); err != nil {
+	membership, err := s.loadGitHubMembership(ctx, token)
+	if err != nil {
+		return nil, nil, "", "", "", err
+	}
+	if err := s.ensureGitHubAccess(membership); err != nil {
 		return nil, nil, "", "", "", err
 	}
 
@@ -253,7 +367,12 @@ func (s *Service) HandleGitHubCallback(ctx context.Context, code, state string)
 
 	identity, err := s.repos.UserIdentities.GetByProviderAndExternalID(ctx, providerGitHub, providerUserID)
 	var user *domain.User
-	if err == nil {
+	if linkUserID != "" {
+		user, err = s.linkGitHubIdentity(ctx, linkUserID, identity, err, ghUser)
+		if err != nil {
+			return nil, nil, "", "", "", err
+		}
+	} else if err == nil {
 		user, err = s.repos.Users.GetByID(ctx, identity.UserID)
 		if err != nil {
 			return nil, nil, "", "", "", err
@@ -264,37 +383,28 @@ func (s *Service) HandleGitHubCallback(ctx context.Context, code, state string)
 			return nil, nil, "", "", "", err
 		}
 
-		login := strings.TrimSpace(ghUser.Login)
-		avatar := strings.TrimSpace(ghUser.AvatarURL)
-		identity := &domain.UserIdentity{
-			ID:             uuid.NewString(),
-			UserID:         user.ID,
-			Provider:       providerGitHub,
-			ProviderUserID: providerUserID,
-		}
-		if login != "" {
-			identity.ProviderLogin = &login
-		}
-		if avatar != "" {
-			identity.AvatarURL = &avatar
-		}
-
-		if err := s.repos.UserIdentities.Create(ctx, identity); err != nil {
+		if err := s.repos.UserIdentities.Create(ctx, newGitHubIdentity(user.ID, ghUser)); err != nil {
 			return nil, nil, "", "", "", err
 		}
 	} else {
 		return nil, nil, "", "", "", err
 	}
 
-	if user.Status != "active" {
-		return nil, nil, "", "", "", ErrUserDisabled
+	if user.Status != userStatusActive {
+		return nil, user, "", "", "", userStatusError(user.Status)
+	}
+	if linkUserID != "" {
+		return nil, user, finalRedirect, responseMode, clientOrigin, nil
+	}
+	if user, err = s.applyRoleMappings(ctx, user, providerGitHub, s.gitHubRoleMappingRules(), gitHubClaims(ghUser, email, membership)); err != nil {
+		return nil, nil, "", "", "", err
 	}
 
 	if err := s.repos.Users.UpdateLastLogin(ctx, user.ID); err != nil && !errors.Is(err, domain.ErrNotFound) {
 		return nil, nil, "", "", "", err
 	}
 
-	tokens, err := s.issueTokens(user)
+	tokens, err := s.issueTokens(ctx, user)
 	if err != nil {
 		return nil, nil, "", "", "", err
 	}
@@ -302,7 +412,22 @@ func (s *Service) HandleGitHubCallback(ctx context.Context, code, state string)
 	return tokens, user, finalRedirect, responseMode, clientOrigin, nil
 }
 
-func (s *Service) issueTokens(user *domain.User) (*Tokens, error) {
+func (s *Service) issueTokens(ctx context.Context, user *domain.User) (*Tokens, error) {
+	return s.issueTokensForWorkspace(ctx, user, "")
+}
+
+// issueTokensForWorkspace 签发令牌；访问令牌使用工作区所属组织的签名密钥，组织尚无密钥时按需生成。
+func (s *Service) issueTokensForWorkspace(ctx context.Context, user *domain.User, workspaceID string) (*Tokens, error) {
+	tenant, err := s.workspaceSigningTenant(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant != "" && s.keys.Active() != nil && s.keys.TenantActive(tenant) == nil {
+		if _, err := s.RotateSigningKey(ctx, tenant, "", "system"); err != nil {
+			return nil, err
+		}
+	}
+
 	now := s.nowFn()
 	accessTTL := s.cfg.AccessTokenTTL
 	if accessTTL <= 0 {
@@ -314,9 +439,10 @@ func (s *Service) issueTokens(user *domain.User) (*Tokens, error) {
 	}
 
 	accessClaims := authutil.Claims{
-		UserID:    user.ID,
-		Role:      user.Role,
-		TokenType: "access",
+		UserID:      user.ID,
+		Role:        user.Role,
+		TokenType:   "access",
+		WorkspaceID: workspaceID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:  user.Email,
 			Issuer:   "prompt-manager",
@@ -324,15 +450,16 @@ func (s *Service) issueTokens(user *domain.User) (*Tokens, error) {
 		},
 	}
 
-	accessToken, err := authutil.GenerateToken(s.cfg.AccessTokenSecret, accessTTL, accessClaims)
+	accessToken, err := s.keys.SignFor(tenant, accessTTL, accessClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	refreshClaims := authutil.Claims{
-		UserID:    user.ID,
-		Role:      user.Role,
-		TokenType: "refresh",
+		UserID:      user.ID,
+		Role:        user.Role,
+		TokenType:   "refresh",
+		WorkspaceID: workspaceID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:  user.Email,
 			Issuer:   "prompt-manager",
@@ -354,7 +481,7 @@ func (s *Service) issueTokens(user *domain.User) (*Tokens, error) {
 	return tokens, nil
 }
 
-func (s *Service) generateOAuthState(provider, redirectURI, responseMode, clientOrigin string) (string, error) {
+func (s *Service) generateOAuthState(provider, redirectURI, responseMode, clientOrigin, linkUserID, linkBindingHash string) (string, error) {
 	metadata := map[string]string{
 		"provider":      provider,
 		"response_mode": responseMode,
@@ -365,6 +492,10 @@ func (s *Service) generateOAuthState(provider, redirectURI, responseMode, client
 	if redirectURI != "" {
 		metadata["redirect_uri"] = redirectURI
 	}
+	if linkUserID != "" {
+		metadata["link_user_id"] = linkUserID
+		metadata["link_binding"] = linkBindingHash
+	}
 	metadata["nonce"] = uuid.NewString()
 
 	claims := authutil.Claims{
@@ -382,24 +513,33 @@ func (s *Service) generateOAuthState(provider, redirectURI, responseMode, client
 	return authutil.GenerateToken(s.cfg.AccessTokenSecret, s.cfg.GitHub.StateTTL, claims)
 }
 
-func (s *Service) parseOAuthState(state string) (string, string, string, string, error) {
+// oauthState 为解析后的 OAuth state。
+type oauthState struct {
+	provider        string
+	redirectURI     string
+	responseMode    string
+	clientOrigin    string
+	linkUserID      string
+	linkBindingHash string
+}
+
+func (s *Service) parseOAuthState(state string) (*oauthState, error) {
 	claims, err := authutil.ParseToken(state, s.cfg.AccessTokenSecret)
 	if err != nil {
-		return "", "", "", "", err
+		return nil, err
 	}
 	if claims.TokenType != tokenTypeOAuthState {
-		return "", "", "", "", ErrOAuthStateInvalid
+		return nil, ErrOAuthStateInvalid
 	}
-	provider := strings.TrimSpace(claims.RegisteredClaims.Subject)
-	redirect := ""
-	mode := ""
-	origin := ""
+	parsed := &oauthState{provider: strings.TrimSpace(claims.RegisteredClaims.Subject)}
 	if claims.Metadata != nil {
-		redirect = strings.TrimSpace(claims.Metadata["redirect_uri"])
-		mode = normalizeResponseMode(claims.Metadata["response_mode"])
-		origin = strings.TrimSpace(claims.Metadata["client_origin"])
+		parsed.redirectURI = strings.TrimSpace(claims.Metadata["redirect_uri"])
+		parsed.responseMode = normalizeResponseMode(claims.Metadata["response_mode"])
+		parsed.clientOrigin = strings.TrimSpace(claims.Metadata["client_origin"])
+		parsed.linkUserID = strings.TrimSpace(claims.Metadata["link_user_id"])
+		parsed.linkBindingHash = strings.TrimSpace(claims.Metadata["link_binding"])
 	}
-	return provider, redirect, mode, origin, nil
+	return parsed, nil
 }
 
 func (s *Service) normalizeRedirectURI(raw string) (string, error) {
@@ -424,7 +564,7 @@ func (s *Service) normalizeRedirectURI(raw string) (string, error) {
 func (s *Service) exchangeGitHubCode(ctx context.Context, code, state string) (string, error) {
 	form := url.Values{}
 	form.Set("client_id", s.cfg.GitHub.ClientID)
-	form.Set("client_secret", s.cfg.GitHub.ClientSecret)
+	form.Set("client_secret", s.githubClientSecret())
 	form.Set("code", code)
 	form.Set("redirect_uri", s.cfg.GitHub.RedirectURL)
 	form.Set("state", state)
@@ -549,35 +689,6 @@ func (s *Service) fetchPrimaryGitHubEmail(ctx context.Context, accessToken strin
 	return "", ErrOAuthEmailMissing
 }
 
-func (s *Service) ensureGitHubOrgAccess(ctx context.Context, accessToken string) error {
-	if len(s.cfg.GitHub.AllowedOrgs) == 0 {
-		return nil
-	}
-
-	orgs, err := s.fetchGitHubOrgs(ctx, accessToken)
-	if err != nil {
-		return err
-	}
-	if len(orgs) == 0 {
-		return ErrOAuthOrgUnauthorized
-	}
-
-	allowed := make(map[string]struct{}, len(s.cfg.GitHub.AllowedOrgs))
-	for _, org := range s.cfg.GitHub.AllowedOrgs {
-		name := strings.ToLower(strings.TrimSpace(org))
-		if name != "" {
-			allowed[name] = struct{}{}
-		}
-	}
-
-	for _, org := range orgs {
-		if _, ok := allowed[strings.ToLower(org)]; ok {
-			return nil
-		}
-	}
-	return ErrOAuthOrgUnauthorized
-}
-
 func (s *Service) fetchGitHubOrgs(ctx context.Context, accessToken string) ([]string, error) {
 	resp, err := s.doGitHubRequest(ctx, http.MethodGet, "/user/orgs", accessToken)
 	if err != nil {
@@ -640,8 +751,12 @@ func (s *Service) findOrCreateUserByEmail(ctx context.Context, email string) (*d
 		return nil, err
 	}
 
+	if err := s.checkRegistrationPolicy(normalized); err != nil {
+		return nil, err
+	}
+
 	randomSecret := uuid.NewString() + uuid.NewString()
-	hash, err := authutil.HashPassword(randomSecret)
+	hash, err := s.hasher.Hash(randomSecret)
 	if err != nil {
 		return nil, err
 	}
@@ -650,8 +765,8 @@ func (s *Service) findOrCreateUserByEmail(ctx context.Context, email string) (*d
 		ID:             uuid.NewString(),
 		Email:          normalized,
 		HashedPassword: hash,
-		Role:           "admin", // Single user gets admin privileges
-		Status:         "active",
+		Role:           roleViewer,
+		Status:         s.registrationStatus(),
 	}
 
 	if err := s.repos.Users.Create(ctx, user); err != nil {
@@ -662,6 +777,14 @@ func (s *Service) findOrCreateUserByEmail(ctx context.Context, email string) (*d
 		return nil, err
 	}
 
+	if err := s.recordAudit(ctx, AuditUserCreated, normalized, auditTargetUser, user.ID, map[string]interface{}{
+		"provider": providerGitHub,
+		"role":     user.Role,
+		"status":   user.Status,
+	}); err != nil {
+		return nil, err
+	}
+
 	return s.repos.Users.GetByEmail(ctx, normalized)
 }
 
@@ -678,3 +801,35 @@ func normalizeResponseMode(mode string) string {
 func normalizeEmail(email string) string {
 	return strings.TrimSpace(strings.ToLower(email))
 }
+
+// 可分配的用户角色。
+const (
+	roleAdmin  = "admin"
+	roleEditor = "editor"
+	roleViewer = "viewer"
+)
+
+func isValidRole(role string) bool {
+	switch role {
+	case roleAdmin, roleEditor, roleViewer:
+		return true
+	default:
+		return false
+	}
+}
+
+func optionalString(value string) *string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique") || strings.Contains(msg, "duplicate")
+}
@@ -13,9 +13,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/zacharykka/prompt-manager/internal/config"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
 	"github.com/zacharykka/prompt-manager/internal/infra/database"
 	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	"github.com/zacharykka/prompt-manager/pkg/audit"
+	authutil "github.com/zacharykka/prompt-manager/pkg/auth"
 )
 
 func setupAuthTestService(t *testing.T) (*Service, func()) {
@@ -43,6 +47,34 @@ func setupAuthTestServiceWithConfig(t *testing.T, cfg config.AuthConfig, opts ..
 		"000002_add_prompt_body.up.sql",
 		"000003_prompt_soft_delete.up.sql",
 		"000004_add_user_identities.up.sql",
+		"000005_prompt_version_locales.up.sql",
+		"000006_prompt_dependencies.up.sql",
+		"000007_pipelines.up.sql",
+		"000008_prompt_render_mode.up.sql",
+		"000009_signing_keys.up.sql",
+		"000010_audit_hash_chain.up.sql",
+		"000011_audit_logs.up.sql",
+		"000012_workspaces.up.sql",
+		"000013_invitations.up.sql",
+		"000014_login_events.up.sql",
+		"000015_prompts_created_by_idx.up.sql",
+		"000016_execution_error_class.up.sql",
+		"000017_prompt_alert_rules.up.sql",
+		"000018_api_keys.up.sql",
+		"000019_prompt_templates.up.sql",
+		"000020_prompt_drafts.up.sql",
+		"000021_prompt_owner.up.sql",
+		"000022_prompt_reviews.up.sql",
+		"000023_hot_query_indexes.up.sql",
+		"000024_usage_metering.up.sql",
+		"000025_announcements.up.sql",
+		"000026_prompt_previous_active_version.up.sql",
+		"000027_prompt_canaries.up.sql",
+		"000028_alert_webhook_secrets.up.sql",
+		"000029_prompt_version_target_models.up.sql",
+		"000030_api_key_daily_quota.up.sql",
+		"000031_pipeline_workspaces.up.sql",
+		"000032_signing_key_organizations.up.sql",
 	}
 	for _, file := range migrationFiles {
 		path := filepath.Join(migrationDir, file)
@@ -168,21 +200,24 @@ func TestGitHubAuthorizeURL(t *testing.T) {
 		t.Fatalf("state should not be empty")
 	}
 
-	provider, redirectURI, mode, origin, err := svc.parseOAuthState(state)
+	parsedState, err := svc.parseOAuthState(state)
 	if err != nil {
 		t.Fatalf("parseOAuthState error: %v", err)
 	}
-	if provider != providerGitHub {
-		t.Fatalf("expected provider %s got %s", providerGitHub, provider)
+	if parsedState.provider != providerGitHub {
+		t.Fatalf("expected provider %s got %s", providerGitHub, parsedState.provider)
 	}
-	if redirectURI != "https://app.example.com/finish" {
-		t.Fatalf("unexpected redirect uri: %s", redirectURI)
+	if parsedState.redirectURI != "https://app.example.com/finish" {
+		t.Fatalf("unexpected redirect uri: %s", parsedState.redirectURI)
+	}
+	if parsedState.responseMode != "web_message" {
+		t.Fatalf("unexpected response mode: %s", parsedState.responseMode)
 	}
-	if mode != "web_message" {
-		t.Fatalf("unexpected response mode: %s", mode)
+	if parsedState.clientOrigin != "http://localhost:5173" {
+		t.Fatalf("unexpected client origin: %s", parsedState.clientOrigin)
 	}
-	if origin != "http://localhost:5173" {
-		t.Fatalf("unexpected client origin: %s", origin)
+	if parsedState.linkUserID != "" || parsedState.linkBindingHash != "" {
+		t.Fatalf("login state should not carry link user: %s", parsedState.linkUserID)
 	}
 }
 
@@ -243,7 +278,7 @@ func TestHandleGitHubCallback_NewUser(t *testing.T) {
 		t.Fatalf("state should not be empty")
 	}
 
-	tokens, user, redirectURI, responseMode, clientOrigin, err := svc.HandleGitHubCallback(context.Background(), "dummy-code", state)
+	tokens, user, redirectURI, responseMode, clientOrigin, err := svc.HandleGitHubCallback(context.Background(), "dummy-code", state, "")
 	if err != nil {
 		t.Fatalf("HandleGitHubCallback error: %v", err)
 	}
@@ -328,8 +363,927 @@ func TestHandleGitHubCallback_OrgRestriction(t *testing.T) {
 		t.Fatalf("state should not be empty")
 	}
 
-	_, _, _, _, _, err = svc.HandleGitHubCallback(context.Background(), "dummy-code", state)
+	_, _, _, _, _, err = svc.HandleGitHubCallback(context.Background(), "dummy-code", state, "")
 	if !errors.Is(err, ErrOAuthOrgUnauthorized) {
 		t.Fatalf("expected ErrOAuthOrgUnauthorized got %v", err)
 	}
 }
+
+func TestSigningKeyRotation(t *testing.T) {
+	cfg := config.AuthConfig{
+		AccessTokenSecret:  "access-secret",
+		RefreshTokenSecret: "refresh-secret",
+		AccessTokenTTL:     15 * time.Minute,
+		RefreshTokenTTL:    24 * time.Hour,
+		Signing:            config.SigningConfig{Algorithm: "RS256", RotationGrace: time.Minute},
+	}
+	svc, cleanup := setupAuthTestServiceWithConfig(t, cfg)
+	defer cleanup()
+
+	ctx := context.Background()
+	legacy, err := svc.issueTokens(ctx, &domain.User{ID: "u1", Email: "legacy@example.com", Role: "viewer"})
+	if err != nil {
+		t.Fatalf("issue legacy tokens: %v", err)
+	}
+
+	if err := svc.InitSigningKeys(ctx); err != nil {
+		t.Fatalf("init signing keys: %v", err)
+	}
+	first := svc.SigningKeys().Active()
+	if first == nil || first.Algorithm != "RS256" {
+		t.Fatalf("expected RS256 active key got %+v", first)
+	}
+	before, err := svc.issueTokens(ctx, &domain.User{ID: "u1", Email: "user@example.com", Role: "viewer"})
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+
+	rotated, err := svc.RotateSigningKey(ctx, "", "EdDSA", "admin@example.com")
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if rotated.ID == first.ID || rotated.Algorithm != "EdDSA" {
+		t.Fatalf("unexpected rotated key %+v", rotated)
+	}
+
+	for name, token := range map[string]string{"legacy": legacy.AccessToken, "previous": before.AccessToken} {
+		if _, err := svc.ParseAccessToken(token); err != nil {
+			t.Fatalf("%s token should remain valid: %v", name, err)
+		}
+	}
+	keys, err := svc.ListSigningKeys(ctx)
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("expected 2 usable keys got %d (%v)", len(keys), err)
+	}
+
+	svc.WithClock(func() time.Time { return time.Now().Add(2 * time.Minute) })
+	if err := svc.ReloadSigningKeys(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err := svc.ParseAccessToken(before.AccessToken); err == nil {
+		t.Fatalf("expected token signed by retired key to be rejected after grace period")
+	}
+
+	if _, err := svc.RotateSigningKey(ctx, "", "none", ""); err != ErrInvalidAlgorithm {
+		t.Fatalf("expected ErrInvalidAlgorithm got %v", err)
+	}
+}
+
+func TestSigningKeysPerOrganization(t *testing.T) {
+	cfg := config.AuthConfig{
+		AccessTokenSecret:  "access-secret",
+		RefreshTokenSecret: "refresh-secret",
+		AccessTokenTTL:     15 * time.Minute,
+		RefreshTokenTTL:    24 * time.Hour,
+		Signing:            config.SigningConfig{Algorithm: "EdDSA", RotationGrace: time.Minute},
+	}
+	svc, cleanup := setupAuthTestServiceWithConfig(t, cfg)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := svc.repos.Workspaces.CreateOrganization(ctx, &domain.Organization{ID: "acme", Name: "Acme", Slug: "acme"}); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	workspace := &domain.Workspace{ID: "acme-ws", OrganizationID: "acme", Name: "Acme", Slug: "acme-ws"}
+	if err := svc.repos.Workspaces.CreateWorkspace(ctx, workspace, nil); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+
+	if err := svc.InitSigningKeys(ctx); err != nil {
+		t.Fatalf("init signing keys: %v", err)
+	}
+	defaultKey := svc.SigningKeys().Active()
+	acmeKey := svc.SigningKeys().TenantActive("acme")
+	if defaultKey == nil || acmeKey == nil || defaultKey.ID == acmeKey.ID {
+		t.Fatalf("expected separate default and organization keys got %+v / %+v", defaultKey, acmeKey)
+	}
+
+	user := &domain.User{ID: "u1", Email: "user@example.com", Role: "viewer"}
+	tokens, err := svc.issueTokensForWorkspace(ctx, user, "acme-ws")
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(tokens.AccessToken, &authutil.Claims{})
+	if err != nil || parsed.Header["kid"] != acmeKey.ID {
+		t.Fatalf("expected token signed with organization key %s got %v (%v)", acmeKey.ID, parsed.Header["kid"], err)
+	}
+	if _, err := svc.ParseAccessToken(tokens.AccessToken); err != nil {
+		t.Fatalf("parse organization token: %v", err)
+	}
+
+	if _, err := svc.RotateSigningKey(ctx, "", "", "admin@example.com"); err != nil {
+		t.Fatalf("rotate default organization: %v", err)
+	}
+	if svc.SigningKeys().TenantActive("acme").ID != acmeKey.ID {
+		t.Fatalf("rotating the default organization must not replace other organizations' keys")
+	}
+	if _, err := svc.RotateSigningKey(ctx, "missing", "", ""); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Fatalf("expected ErrOrganizationNotFound got %v", err)
+	}
+
+	records, err := svc.ListSigningKeys(ctx)
+	if err != nil {
+		t.Fatalf("list signing keys: %v", err)
+	}
+	var acmeRecord *domain.SigningKey
+	for _, record := range records {
+		if record.ID == acmeKey.ID {
+			acmeRecord = record
+		}
+	}
+	if acmeRecord == nil || acmeRecord.OrganizationID != "acme" {
+		t.Fatalf("expected stored key for acme got %+v", acmeRecord)
+	}
+	if _, err := authutil.DecryptSecret(svc.keyEncryptionSecret(), acmeRecord.EncryptedKey); err == nil {
+		t.Fatalf("organization key must not be decryptable with the global secret")
+	}
+	if _, err := authutil.DecryptSecret(svc.tenantKeySecret(domain.DefaultOrganizationID), acmeRecord.EncryptedKey); err == nil {
+		t.Fatalf("organization key must not be decryptable with another organization's secret")
+	}
+
+	// 按组织加密之前写入的记录（使用全局口令）仍可加载。
+	legacyKey, err := authutil.GenerateSigningKey("legacy-kid", "HS256")
+	if err != nil {
+		t.Fatalf("generate legacy key: %v", err)
+	}
+	material, err := legacyKey.MarshalPrivate()
+	if err != nil {
+		t.Fatalf("marshal legacy key: %v", err)
+	}
+	encrypted, err := authutil.EncryptSecret(svc.keyEncryptionSecret(), material)
+	if err != nil {
+		t.Fatalf("encrypt legacy key: %v", err)
+	}
+	legacyRecord := &domain.SigningKey{ID: "legacy-kid", OrganizationID: "acme", Algorithm: "HS256", EncryptedKey: encrypted, Status: signingKeyStatusActive}
+	if err := svc.repos.SigningKeys.Rotate(ctx, legacyRecord, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("store legacy key: %v", err)
+	}
+	if err := svc.ReloadSigningKeys(ctx); err != nil {
+		t.Fatalf("reload with legacy key: %v", err)
+	}
+	if active := svc.SigningKeys().TenantActive("acme"); active == nil || active.ID != "legacy-kid" {
+		t.Fatalf("expected legacy key to load as acme's active key got %+v", active)
+	}
+}
+
+func TestLoginAuditEvents(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+	ctx := audit.WithClient(context.Background(), audit.Client{IP: "203.0.113.7", UserAgent: "test-agent"})
+
+	hash, err := authutil.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	user := &domain.User{ID: "audit-user", Email: "audit@example.com", HashedPassword: hash, Role: "viewer", Status: "active"}
+	if err := svc.repos.Users.Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if _, _, err := svc.Login(ctx, "audit@example.com", "wrong-password"); err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "audit@example.com", "password123"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	logs, err := svc.repos.AuditLogs.List(ctx, domain.AuditLogListOptions{Action: "auth.", Actor: "audit@example.com"})
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 auth audit logs got %d", len(logs))
+	}
+	actions := map[string]*domain.AuditLog{}
+	for _, log := range logs {
+		actions[log.Action] = log
+	}
+	failed := actions[AuditLoginFailed]
+	if failed == nil || !strings.Contains(string(failed.Payload), "invalid_password") {
+		t.Fatalf("expected login failure with reason, got %+v", failed)
+	}
+	succeeded := actions[AuditLoginSucceeded]
+	if succeeded == nil || succeeded.TargetID == nil || *succeeded.TargetID != user.ID {
+		t.Fatalf("expected login success targeting user, got %+v", succeeded)
+	}
+	if succeeded.IP == nil || *succeeded.IP != "203.0.113.7" || succeeded.UserAgent == nil || *succeeded.UserAgent != "test-agent" {
+		t.Fatalf("expected client info on audit log, got %+v", succeeded)
+	}
+}
+
+func TestInvitationFlow(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	workspace := &domain.Workspace{ID: "team", OrganizationID: domain.DefaultOrganizationID, Name: "Team", Slug: "team"}
+	if err := svc.repos.Workspaces.CreateWorkspace(ctx, workspace, nil); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+
+	if _, _, err := svc.CreateInvitation(ctx, CreateInvitationInput{Email: "new@example.com", Role: "owner"}); err != ErrInvalidRole {
+		t.Fatalf("expected ErrInvalidRole got %v", err)
+	}
+	if _, _, err := svc.CreateInvitation(ctx, CreateInvitationInput{Email: "new@example.com", Role: "editor", WorkspaceID: "missing"}); err != domain.ErrWorkspaceNotFound {
+		t.Fatalf("expected ErrWorkspaceNotFound got %v", err)
+	}
+
+	invitation, token, err := svc.CreateInvitation(ctx, CreateInvitationInput{Email: "New@Example.com", Role: "editor", WorkspaceID: "team", InvitedBy: "admin@example.com"})
+	if err != nil {
+		t.Fatalf("create invitation: %v", err)
+	}
+	if token == "" || invitation.Email != "new@example.com" || invitation.TokenHash == token {
+		t.Fatalf("unexpected invitation %+v", invitation)
+	}
+	pending, err := svc.ListPendingInvitations(ctx)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected 1 pending invitation, got %d (%v)", len(pending), err)
+	}
+
+	if _, _, err := svc.AcceptInvitation(ctx, "bogus", "password123"); err != ErrInvitationInvalid {
+		t.Fatalf("expected ErrInvitationInvalid got %v", err)
+	}
+	tokens, user, err := svc.AcceptInvitation(ctx, token, "password123")
+	if err != nil {
+		t.Fatalf("accept invitation: %v", err)
+	}
+	if user.Role != "viewer" || tokens.AccessToken == "" {
+		t.Fatalf("expected new viewer account with tokens, got %+v", user)
+	}
+	claims, err := svc.ParseAccessToken(tokens.AccessToken)
+	if err != nil || claims.WorkspaceID != "team" {
+		t.Fatalf("expected token scoped to invited workspace, got %+v (%v)", claims, err)
+	}
+	member, err := svc.repos.Workspaces.GetMember(ctx, "team", user.ID)
+	if err != nil || member.Role != "editor" {
+		t.Fatalf("expected editor membership, got %+v (%v)", member, err)
+	}
+	if _, _, err := svc.AcceptInvitation(ctx, token, "password123"); err != ErrInvitationInvalid {
+		t.Fatalf("expected reused invitation to be rejected, got %v", err)
+	}
+	if pending, _ := svc.ListPendingInvitations(ctx); len(pending) != 0 {
+		t.Fatalf("expected no pending invitations, got %d", len(pending))
+	}
+
+	// 已有账号需提供正确密码才能关联。
+	_, linkToken, err := svc.CreateInvitation(ctx, CreateInvitationInput{Email: "new@example.com", Role: "admin", WorkspaceID: "team"})
+	if err != nil {
+		t.Fatalf("create second invitation: %v", err)
+	}
+	if _, _, err := svc.AcceptInvitation(ctx, linkToken, "wrong-password"); err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials got %v", err)
+	}
+	if _, linked, err := svc.AcceptInvitation(ctx, linkToken, "password123"); err != nil || linked.ID != user.ID {
+		t.Fatalf("expected existing account to be linked, got %v", err)
+	}
+	if member, _ := svc.repos.Workspaces.GetMember(ctx, "team", user.ID); member == nil || member.Role != "admin" {
+		t.Fatalf("expected membership role to be updated, got %+v", member)
+	}
+
+	svc.WithClock(func() time.Time { return time.Now().Add(8 * 24 * time.Hour) })
+	_, expiredToken, err := svc.CreateInvitation(ctx, CreateInvitationInput{Email: "late@example.com", Role: "viewer"})
+	if err != nil {
+		t.Fatalf("create invitation: %v", err)
+	}
+	svc.WithClock(func() time.Time { return time.Now().Add(16 * 24 * time.Hour) })
+	if _, _, err := svc.AcceptInvitation(ctx, expiredToken, "password123"); err != ErrInvitationExpired {
+		t.Fatalf("expected ErrInvitationExpired got %v", err)
+	}
+}
+
+func TestRegistrationPolicy(t *testing.T) {
+	cfg := config.AuthConfig{
+		AccessTokenSecret:  "access-secret",
+		RefreshTokenSecret: "refresh-secret",
+		AccessTokenTTL:     15 * time.Minute,
+		RefreshTokenTTL:    24 * time.Hour,
+		Registration:       config.RegistrationConfig{Mode: config.RegistrationClosed},
+	}
+	svc, cleanup := setupAuthTestServiceWithConfig(t, cfg)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, "user@example.com", "password123", ""); err != ErrRegistrationDisabled {
+		t.Fatalf("expected ErrRegistrationDisabled got %v", err)
+	}
+
+	svc.cfg.Registration = config.RegistrationConfig{Mode: config.RegistrationApproval, AllowedDomains: []string{"Example.com"}}
+	if _, err := svc.Register(ctx, "user@other.com", "password123", ""); err != ErrEmailDomainNotAllowed {
+		t.Fatalf("expected ErrEmailDomainNotAllowed got %v", err)
+	}
+	user, err := svc.Register(ctx, "user@example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if user.Status != "pending" || user.Role != "viewer" {
+		t.Fatalf("expected pending viewer, got %s/%s", user.Status, user.Role)
+	}
+	if _, _, err := svc.Login(ctx, "user@example.com", "password123"); err != ErrUserPending {
+		t.Fatalf("expected ErrUserPending got %v", err)
+	}
+
+	pending, err := svc.ListPendingUsers(ctx)
+	if err != nil || len(pending) != 1 || pending[0].ID != user.ID {
+		t.Fatalf("expected 1 pending user, got %d (%v)", len(pending), err)
+	}
+	approved, err := svc.ApproveUser(ctx, user.ID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("approve user: %v", err)
+	}
+	if approved.Status != "active" {
+		t.Fatalf("expected active status, got %s", approved.Status)
+	}
+	if _, err := svc.ApproveUser(ctx, user.ID, "admin@example.com"); err != ErrUserNotPending {
+		t.Fatalf("expected ErrUserNotPending got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "user@example.com", "password123"); err != nil {
+		t.Fatalf("login after approval: %v", err)
+	}
+}
+
+type stubBreachChecker struct {
+	breached map[string]bool
+}
+
+func (s stubBreachChecker) Breached(_ context.Context, password string) (bool, error) {
+	return s.breached[password], nil
+}
+
+func TestPasswordPolicyAndChangePassword(t *testing.T) {
+	cfg := config.AuthConfig{
+		AccessTokenSecret:  "access-secret",
+		RefreshTokenSecret: "refresh-secret",
+		AccessTokenTTL:     15 * time.Minute,
+		RefreshTokenTTL:    24 * time.Hour,
+		PasswordPolicy:     config.PasswordPolicyConfig{MinLength: 10, MinCharacterClasses: 2, DenyCommon: true, CheckBreached: true},
+	}
+	checker := stubBreachChecker{breached: map[string]bool{"Leaked-Secret-1": true}}
+	svc, cleanup := setupAuthTestServiceWithConfig(t, cfg, WithBreachChecker(checker))
+	defer cleanup()
+	ctx := context.Background()
+
+	var policyErr *PasswordPolicyError
+	if _, err := svc.Register(ctx, "policy@example.com", "password123", ""); !errors.As(err, &policyErr) {
+		t.Fatalf("expected PasswordPolicyError got %v", err)
+	}
+	if policyErr.Violations[0] != authutil.PasswordCommon {
+		t.Fatalf("unexpected violations %v", policyErr.Violations)
+	}
+	if _, err := svc.Register(ctx, "policy@example.com", "Leaked-Secret-1", ""); !errors.As(err, &policyErr) || policyErr.Violations[0] != authutil.PasswordBreached {
+		t.Fatalf("expected breached violation got %v", err)
+	}
+	user, err := svc.Register(ctx, "policy@example.com", "Correct-Horse-9", "")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if got := svc.PasswordPolicy(); got.MinLength != 10 || got.MaxLength != 128 || !got.CheckBreached {
+		t.Fatalf("unexpected exposed policy %+v", got)
+	}
+
+	if err := svc.ChangePassword(ctx, user.ID, "wrong-password", "Battery-Staple-7"); err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, user.ID, "Correct-Horse-9", "short"); !errors.As(err, &policyErr) {
+		t.Fatalf("expected PasswordPolicyError got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, user.ID, "Correct-Horse-9", "Battery-Staple-7"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "policy@example.com", "Correct-Horse-9"); err != ErrInvalidCredentials {
+		t.Fatalf("expected old password to be rejected, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "policy@example.com", "Battery-Staple-7"); err != nil {
+		t.Fatalf("login with new password: %v", err)
+	}
+}
+
+func TestLoginRehashesLegacyPassword(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, "legacy@example.com", "password123", ""); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	svc.hasher = authutil.PasswordHasher{Algorithm: authutil.HashAlgorithmArgon2id, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+	if _, _, err := svc.Login(ctx, "legacy@example.com", "password123"); err != nil {
+		t.Fatalf("login with legacy hash: %v", err)
+	}
+	user, err := svc.repos.Users.GetByEmail(ctx, "legacy@example.com")
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if !strings.HasPrefix(user.HashedPassword, "$argon2id$") {
+		t.Fatalf("expected hash to be upgraded to argon2id, got %s", user.HashedPassword[:7])
+	}
+	if _, _, err := svc.Login(ctx, "legacy@example.com", "password123"); err != nil {
+		t.Fatalf("login with upgraded hash: %v", err)
+	}
+}
+
+func TestLoginEventsDetectNewDevice(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	notifier := &recordingLoginNotifier{}
+	svc.loginNotifier = notifier
+
+	user, err := svc.Register(ctx, "events@example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	laptop := audit.WithClient(ctx, audit.Client{IP: "10.0.0.1", UserAgent: "Laptop/1.0"})
+	phone := audit.WithClient(ctx, audit.Client{IP: "10.0.0.2", UserAgent: "Phone/2.0"})
+
+	if _, _, err := svc.Login(laptop, "events@example.com", "password123"); err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	tokens, _, err := svc.Login(laptop, "events@example.com", "password123")
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if _, _, err := svc.Refresh(phone, tokens.RefreshToken); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	events, err := svc.ListLoginEvents(ctx, user.ID, 0)
+	if err != nil {
+		t.Fatalf("list login events: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 login events, got %d", len(events))
+	}
+	newDevices := 0
+	for _, event := range events {
+		if event.NewDevice {
+			newDevices++
+			if event.EventType != "refresh" || event.UserAgent == nil || *event.UserAgent != "Phone/2.0" {
+				t.Fatalf("unexpected new device event %+v", event)
+			}
+		}
+	}
+	if newDevices != 1 || len(notifier.events) != 1 {
+		t.Fatalf("expected exactly one new device alert, got %d events / %d notifications", newDevices, len(notifier.events))
+	}
+
+	logs, err := svc.repos.AuditLogs.List(ctx, domain.AuditLogListOptions{Action: AuditLoginNewDevice})
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("expected new device audit entry, got %d (%v)", len(logs), err)
+	}
+}
+
+type recordingLoginNotifier struct {
+	events []*domain.LoginEvent
+}
+
+func (n *recordingLoginNotifier) NotifyNewDevice(_ context.Context, _ *domain.User, event *domain.LoginEvent) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestGitHubRedirectAllowlist(t *testing.T) {
+	cfg := config.AuthConfig{
+		AccessTokenSecret:  "access-secret",
+		RefreshTokenSecret: "refresh-secret",
+		AccessTokenTTL:     15 * time.Minute,
+		RefreshTokenTTL:    24 * time.Hour,
+		GitHub: config.GitHubOAuthConfig{
+			Enabled:                true,
+			ClientID:               "client-id",
+			ClientSecret:           "client-secret",
+			RedirectURL:            "http://localhost:8080/api/v1/auth/github/callback",
+			StateTTL:               time.Minute,
+			AllowedRedirectOrigins: []string{"http://localhost:5173", "https://*.example.com"},
+		},
+	}
+	svc, cleanup := setupAuthTestServiceWithConfig(t, cfg)
+	defer cleanup()
+
+	if _, err := svc.GitHubAuthorizeURL("https://app.example.com/finish", "web_message", "http://localhost:5173"); err != nil {
+		t.Fatalf("expected allowlisted targets to pass, got %v", err)
+	}
+	for _, tc := range []struct{ redirect, origin string }{
+		{"https://evil.com/finish", ""},
+		{"", "https://evil.com"},
+		{"https://example.com.evil.com/", ""},
+		{"https://example.com/finish", ""},
+	} {
+		if _, err := svc.GitHubAuthorizeURL(tc.redirect, "web_message", tc.origin); !errors.Is(err, ErrOAuthRedirectNotAllowed) {
+			t.Fatalf("expected %q/%q to be rejected, got %v", tc.redirect, tc.origin, err)
+		}
+	}
+
+	// 白名单收紧后，已签发的 state 在回调时同样被拒绝。
+	authorizeURL, err := svc.GitHubAuthorizeURL("", "", "http://localhost:5173")
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	parsed, _ := url.Parse(authorizeURL)
+	svc.cfg.GitHub.AllowedRedirectOrigins = []string{"https://*.example.com"}
+	if _, _, _, _, _, err := svc.HandleGitHubCallback(context.Background(), "code", parsed.Query().Get("state"), ""); !errors.Is(err, ErrOAuthRedirectNotAllowed) {
+		t.Fatalf("expected callback to reject revoked origin, got %v", err)
+	}
+}
+
+func TestHandleGitHubCallback_TeamRestrictionAndRoleMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login/oauth/access_token":
+			_, _ = w.Write([]byte(`{"access_token":"stub-token","token_type":"bearer"}`))
+		case "/user":
+			_, _ = w.Write([]byte(`{"id":24680,"login":"lead","email":"lead@example.com"}`))
+		case "/user/orgs":
+			_, _ = w.Write([]byte(`[{"login":"Acme"}]`))
+		case "/user/teams":
+			_, _ = w.Write([]byte(`[{"slug":"prompt-admins","organization":{"login":"Acme"}},{"slug":"writers","organization":{"login":"acme"}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.AuthConfig{
+		AccessTokenSecret:  "access-secret",
+		RefreshTokenSecret: "refresh-secret",
+		AccessTokenTTL:     15 * time.Minute,
+		RefreshTokenTTL:    24 * time.Hour,
+		GitHub: config.GitHubOAuthConfig{
+			Enabled:      true,
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			RedirectURL:  server.URL + "/callback",
+			AllowedTeams: []string{"acme/release-managers"},
+			RoleMappings: []config.GitHubRoleMapping{
+				{Match: "acme", Role: "viewer"},
+				{Match: "acme/writers", Role: "editor"},
+				{Match: "acme/prompt-admins", Role: "admin"},
+			},
+			StateTTL: time.Minute,
+		},
+	}
+	svc, cleanup := setupAuthTestServiceWithConfig(t, cfg, WithHTTPClient(server.Client()), WithGitHubEndpoints(server.URL+"/authorize", server.URL+"/login/oauth/access_token", server.URL))
+	defer cleanup()
+	ctx := context.Background()
+
+	callback := func() (*domain.User, error) {
+		authorizeURL, err := svc.GitHubAuthorizeURL("", "", "")
+		if err != nil {
+			t.Fatalf("GitHubAuthorizeURL error: %v", err)
+		}
+		parsed, _ := url.Parse(authorizeURL)
+		_, user, _, _, _, err := svc.HandleGitHubCallback(ctx, "dummy-code", parsed.Query().Get("state"), "")
+		return user, err
+	}
+
+	if _, err := callback(); !errors.Is(err, ErrOAuthTeamUnauthorized) {
+		t.Fatalf("expected ErrOAuthTeamUnauthorized got %v", err)
+	}
+
+	svc.cfg.GitHub.AllowedTeams = []string{"acme/writers"}
+	user, err := callback()
+	if err != nil {
+		t.Fatalf("HandleGitHubCallback error: %v", err)
+	}
+	if user.Role != "admin" {
+		t.Fatalf("expected highest mapped role admin, got %s", user.Role)
+	}
+	stored, err := svc.repos.Users.GetByID(ctx, user.ID)
+	if err != nil || stored.Role != "admin" {
+		t.Fatalf("expected mapped role to be persisted, got %+v (%v)", stored, err)
+	}
+
+	logs, err := svc.repos.AuditLogs.List(ctx, domain.AuditLogListOptions{Action: AuditUserRoleMapped})
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("expected one role mapping audit entry, got %d (%v)", len(logs), err)
+	}
+}
+
+func TestApplyRoleMappings(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	workspace := &domain.Workspace{ID: "research", OrganizationID: domain.DefaultOrganizationID, Name: "Research", Slug: "research"}
+	if err := svc.repos.Workspaces.CreateWorkspace(ctx, workspace, nil); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	user, err := svc.Register(ctx, "scientist@lab.example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	rules := []config.RoleMappingRule{
+		{Provider: "oidc", Claim: "groups", Values: []string{"prompt-admins"}, Role: "admin"},
+		{Claim: "groups", Values: []string{"research-*"}, Role: "editor", WorkspaceID: "research", WorkspaceRole: "admin"},
+		{Claim: "email_domain", Values: []string{"lab.example.com"}, Role: "viewer", WorkspaceID: "missing", WorkspaceRole: "editor"},
+	}
+	claims := IdentityClaims{"groups": {"Research-Team", "prompt-admins"}, "email_domain": {"lab.example.com"}}
+
+	result := evaluateRoleMappings(rules, providerGitHub, claims)
+	if result.Role != "editor" || result.Workspaces["research"] != "admin" || result.Workspaces["missing"] != "editor" {
+		t.Fatalf("unexpected evaluation %+v", result)
+	}
+	if result := evaluateRoleMappings(rules, "oidc", claims); result.Role != "admin" {
+		t.Fatalf("expected provider-scoped rule to apply for oidc, got %+v", result)
+	}
+	if result := evaluateRoleMappings(rules, providerGitHub, IdentityClaims{"groups": {"marketing"}}); result.Role != "" || len(result.Workspaces) != 0 {
+		t.Fatalf("expected no mapping, got %+v", result)
+	}
+
+	mapped, err := svc.applyRoleMappings(ctx, user, providerGitHub, rules, claims)
+	if err != nil {
+		t.Fatalf("apply role mappings: %v", err)
+	}
+	if mapped.Role != "editor" {
+		t.Fatalf("expected global role editor, got %s", mapped.Role)
+	}
+	member, err := svc.repos.Workspaces.GetMember(ctx, "research", user.ID)
+	if err != nil || member.Role != "admin" {
+		t.Fatalf("expected research workspace admin, got %+v (%v)", member, err)
+	}
+}
+
+func TestLinkGitHubIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login/oauth/access_token":
+			_, _ = w.Write([]byte(`{"access_token":"stub-token","token_type":"bearer"}`))
+		case "/user":
+			_, _ = w.Write([]byte(`{"id":4242,"login":"octolinker","email":"personal@github.example"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.AuthConfig{
+		AccessTokenSecret:  "access-secret",
+		RefreshTokenSecret: "refresh-secret",
+		GitHub: config.GitHubOAuthConfig{
+			Enabled:     true,
+			ClientID:    "client-id",
+			RedirectURL: server.URL + "/callback",
+			StateTTL:    time.Minute,
+		},
+	}
+	svc, cleanup := setupAuthTestServiceWithConfig(t, cfg, WithHTTPClient(server.Client()), WithGitHubEndpoints(server.URL+"/authorize", server.URL+"/login/oauth/access_token", server.URL))
+	defer cleanup()
+
+	ctx := context.Background()
+	owner, err := svc.Register(ctx, "owner@example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("register owner: %v", err)
+	}
+	other, err := svc.Register(ctx, "other@example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("register other: %v", err)
+	}
+
+	if _, _, err := svc.IdentityLinkURL(ctx, owner.ID, "gitlab", "", "", ""); !errors.Is(err, ErrProviderUnsupported) {
+		t.Fatalf("expected ErrProviderUnsupported, got %v", err)
+	}
+
+	linkState := func(userID string) (string, string) {
+		t.Helper()
+		authorizeURL, binding, err := svc.IdentityLinkURL(ctx, userID, "github", "", "", "")
+		if err != nil {
+			t.Fatalf("IdentityLinkURL error: %v", err)
+		}
+		parsed, err := url.Parse(authorizeURL)
+		if err != nil {
+			t.Fatalf("parse authorize url: %v", err)
+		}
+		return parsed.Query().Get("state"), binding
+	}
+
+	// 没有发起绑定的浏览器持有的绑定值时，state 不可用于绑定。
+	state, binding := linkState(owner.ID)
+	for _, forged := range []string{"", "other-binding"} {
+		if _, _, _, _, _, err := svc.HandleGitHubCallback(ctx, "code", state, forged); !errors.Is(err, ErrOAuthStateInvalid) {
+			t.Fatalf("expected ErrOAuthStateInvalid for binding %q, got %v", forged, err)
+		}
+	}
+	if identities, _ := svc.ListIdentities(ctx, owner.ID); len(identities) != 0 {
+		t.Fatalf("unbound link callback must not attach identity, got %+v", identities)
+	}
+
+	tokens, user, _, _, _, err := svc.HandleGitHubCallback(ctx, "code", state, binding)
+	if err != nil {
+		t.Fatalf("link callback error: %v", err)
+	}
+	if user.ID != owner.ID {
+		t.Fatalf("identity should attach to the linking user, got %s", user.Email)
+	}
+	if tokens != nil {
+		t.Fatalf("link mode must not issue tokens, got %+v", tokens)
+	}
+	if _, err := svc.repos.Users.GetByEmail(ctx, "personal@github.example"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("link mode must not create a user for the GitHub email, got %v", err)
+	}
+	identities, err := svc.ListIdentities(ctx, owner.ID)
+	if err != nil || len(identities) != 1 || identities[0].ProviderUserID != "4242" {
+		t.Fatalf("unexpected identities: %+v (%v)", identities, err)
+	}
+
+	// 重复绑定同一身份是幂等的。
+	if _, _, _, _, _, err := svc.HandleGitHubCallback(ctx, "code", state, binding); err != nil {
+		t.Fatalf("relink should succeed, got %v", err)
+	}
+
+	otherState, otherBinding := linkState(other.ID)
+	if _, _, _, _, _, err := svc.HandleGitHubCallback(ctx, "code", otherState, otherBinding); !errors.Is(err, ErrIdentityConflict) {
+		t.Fatalf("expected ErrIdentityConflict, got %v", err)
+	}
+	if identities, _ := svc.ListIdentities(ctx, other.ID); len(identities) != 0 {
+		t.Fatalf("conflicting link must not attach identity, got %+v", identities)
+	}
+}
+
+func TestMergeUsers(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	source, err := svc.Register(ctx, "dup@example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("register source: %v", err)
+	}
+	target, err := svc.Register(ctx, "main@example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("register target: %v", err)
+	}
+	if err := svc.repos.UserIdentities.Create(ctx, &domain.UserIdentity{ID: "ident-1", UserID: source.ID, Provider: providerGitHub, ProviderUserID: "777"}); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	createdBy := source.Email
+	if err := svc.repos.Prompts.Create(ctx, &domain.Prompt{ID: "prompt-1", Name: "dup-prompt", CreatedBy: &createdBy, WorkspaceID: domain.DefaultWorkspaceID}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if err := svc.repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{WorkspaceID: domain.DefaultWorkspaceID, UserID: source.ID, Role: "editor"}); err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+
+	if _, err := svc.MergeUsers(ctx, source.ID, source.ID, "admin@example.com"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for self merge, got %v", err)
+	}
+	if _, err := svc.MergeUsers(ctx, source.ID, "missing", "admin@example.com"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+
+	result, err := svc.MergeUsers(ctx, source.ID, target.ID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("MergeUsers error: %v", err)
+	}
+	if result.Source.Status != userStatusDisabled {
+		t.Fatalf("source should be disabled, got %s", result.Source.Status)
+	}
+	if result.Moved["prompts"] != 1 || result.Moved["user_identities"] != 1 || result.Moved["workspace_members"] != 1 {
+		t.Fatalf("unexpected moved counts: %+v", result.Moved)
+	}
+
+	prompt, err := svc.repos.Prompts.GetByID(ctx, "prompt-1")
+	if err != nil || prompt.CreatedBy == nil || *prompt.CreatedBy != target.Email {
+		t.Fatalf("prompt should belong to target, got %+v (%v)", prompt, err)
+	}
+	identity, err := svc.repos.UserIdentities.GetByProviderAndExternalID(ctx, providerGitHub, "777")
+	if err != nil || identity.UserID != target.ID {
+		t.Fatalf("identity should move to target, got %+v (%v)", identity, err)
+	}
+	member, err := svc.repos.Workspaces.GetMember(ctx, domain.DefaultWorkspaceID, target.ID)
+	if err != nil || member.Role != "editor" {
+		t.Fatalf("membership should move to target, got %+v (%v)", member, err)
+	}
+	if _, _, err := svc.Login(ctx, source.Email, "password123"); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("merged account should not log in, got %v", err)
+	}
+}
+
+func TestDeactivateUserTransfersOwnership(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	leaver, err := svc.Register(ctx, "leaver@example.com", "password123", "editor")
+	if err != nil {
+		t.Fatalf("register leaver: %v", err)
+	}
+	heir, err := svc.Register(ctx, "heir@example.com", "password123", "editor")
+	if err != nil {
+		t.Fatalf("register heir: %v", err)
+	}
+	tokens, _, err := svc.Login(ctx, leaver.Email, "password123")
+	if err != nil {
+		t.Fatalf("login leaver: %v", err)
+	}
+	createdBy := leaver.Email
+	if err := svc.repos.Prompts.Create(ctx, &domain.Prompt{ID: "prompt-leaver", Name: "leaver-prompt", CreatedBy: &createdBy, WorkspaceID: domain.DefaultWorkspaceID}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	if _, err := svc.DeactivateUser(ctx, leaver.ID, heir.ID, leaver.Email); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput when deactivating self, got %v", err)
+	}
+
+	result, err := svc.DeactivateUser(ctx, leaver.ID, heir.ID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("DeactivateUser error: %v", err)
+	}
+	if result.User.Status != userStatusDisabled || result.Moved["prompts"] != 1 {
+		t.Fatalf("unexpected result: status=%s moved=%+v", result.User.Status, result.Moved)
+	}
+	prompt, err := svc.repos.Prompts.GetByID(ctx, "prompt-leaver")
+	if err != nil || prompt.CreatedBy == nil || *prompt.CreatedBy != heir.Email {
+		t.Fatalf("prompt should transfer to heir, got %+v (%v)", prompt, err)
+	}
+	if _, _, err := svc.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("deactivated user should not refresh, got %v", err)
+	}
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	svc, cleanup := setupAuthTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := svc.Register(ctx, "integration@example.com", "password123", "editor")
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	if _, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{UserID: user.ID, Name: "ci", Scopes: []string{domain.APIKeyScopeAdmin}}); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope for admin scope on editor, got %v", err)
+	}
+	if _, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{UserID: user.ID, Name: "ci", Scopes: []string{"delete"}}); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope for unknown scope, got %v", err)
+	}
+	if _, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{UserID: user.ID, Name: "ci", Scopes: []string{domain.APIKeyScopeRead}, DailyQuota: -1}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for negative daily quota, got %v", err)
+	}
+
+	viewer, err := svc.Register(ctx, "viewer-integration@example.com", "password123", "viewer")
+	if err != nil {
+		t.Fatalf("register viewer: %v", err)
+	}
+	if _, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{UserID: viewer.ID, Name: "ci", Scopes: []string{domain.APIKeyScopeWrite}}); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope for write scope on viewer, got %v", err)
+	}
+	deployer, err := svc.Register(ctx, "deployer@example.com", "password123", "editor")
+	if err != nil {
+		t.Fatalf("register deployer: %v", err)
+	}
+	writer, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{UserID: deployer.ID, Name: "deploy", Scopes: []string{domain.APIKeyScopeWrite}, DailyQuota: 500, Actor: deployer.Email})
+	if err != nil {
+		t.Fatalf("create write api key: %v", err)
+	}
+	if writer.Key.DailyQuota != 500 || len(writer.Key.Scopes) != 1 || writer.Key.Scopes[0] != domain.APIKeyScopeWrite {
+		t.Fatalf("unexpected write key %+v", writer.Key)
+	}
+	if stored, _, err := svc.AuthenticateAPIKey(ctx, writer.Secret); err != nil || stored.DailyQuota != 500 {
+		t.Fatalf("expected daily quota to persist, got %+v (%v)", stored, err)
+	}
+
+	created, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{
+		UserID:    user.ID,
+		Name:      "ci",
+		Scopes:    []string{domain.APIKeyScopeRender, domain.APIKeyScopeRead},
+		RateLimit: 30,
+		Actor:     user.Email,
+	})
+	if err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+	if created.Secret == "" || created.Key.KeyHash == created.Secret {
+		t.Fatalf("expected secret to be returned and stored hashed")
+	}
+
+	key, owner, err := svc.AuthenticateAPIKey(ctx, created.Secret)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if owner.ID != user.ID || key.RateLimit != 30 || len(key.Scopes) != 2 {
+		t.Fatalf("unexpected key %+v for owner %s", key, owner.ID)
+	}
+	if _, _, err := svc.AuthenticateAPIKey(ctx, created.Secret+"x"); !errors.Is(err, ErrAPIKeyInvalid) {
+		t.Fatalf("expected ErrAPIKeyInvalid for wrong secret, got %v", err)
+	}
+
+	keys, err := svc.ListAPIKeys(ctx, user.ID)
+	if err != nil || len(keys) != 1 || keys[0].LastUsedAt == nil {
+		t.Fatalf("expected one key with last_used_at, got %v (%v)", keys, err)
+	}
+
+	if err := svc.RevokeAPIKey(ctx, "someone-else", created.Key.ID, user.Email); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("expected ErrAPIKeyNotFound for foreign key, got %v", err)
+	}
+	if err := svc.RevokeAPIKey(ctx, user.ID, created.Key.ID, user.Email); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, _, err := svc.AuthenticateAPIKey(ctx, created.Secret); !errors.Is(err, ErrAPIKeyInvalid) {
+		t.Fatalf("expected revoked key to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package events 提供服务层的进程内事件总线：业务服务在数据变更后发布事件，
+// 订阅方（如列表响应缓存）据此做失效或其他副作用，避免业务代码直接依赖这些组件。
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Prompt 相关事件类型。
+const (
+	PromptCreated        = "prompt.created"
+	PromptUpdated        = "prompt.updated"
+	PromptDeleted        = "prompt.deleted"
+	PromptRestored       = "prompt.restored"
+	PromptStatusChanged  = "prompt.status_changed"
+	PromptOwnerChanged   = "prompt.owner_changed"
+	VersionCreated       = "prompt.version.created"
+	VersionActivated     = "prompt.version.activated"
+	VersionStatusChanged = "prompt.version.status_changed"
+	VersionsPruned       = "prompt.versions.pruned"
+)
+
+// Event 描述一次数据变更。
+type Event struct {
+	Type        string
+	WorkspaceID string
+	PromptID    string
+	OccurredAt  time.Time
+}
+
+// Handler 处理事件。处理失败由订阅方自行记录，不影响发布方与其他订阅方。
+type Handler func(ctx context.Context, event Event)
+
+// Bus 为同步分发的事件总线：Publish 返回时所有订阅方均已处理完毕，
+// 因此变更接口返回后，后续读取不会命中已失效的缓存。
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus 创建事件总线。
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe 注册订阅方。
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish 依次调用全部订阅方；OccurredAt 为空时填入当前时间。
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
@@ -0,0 +1,232 @@
+// Package executionlog 提供执行日志的异步写入，避免日志写库阻塞调用链路。
+package executionlog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// OverflowDrop 在队列已满时丢弃新日志并计数，调用方不被阻塞。
+	OverflowDrop = "drop"
+	// OverflowBlock 在队列已满时等待空位，直到调用方上下文取消。
+	OverflowBlock = "block"
+)
+
+var (
+	// QueueDepth 为等待写库的执行日志数量。
+	QueueDepth = metrics.NewGauge(
+		"prompt_manager_execution_log_queue_depth",
+		"Execution logs buffered in memory and waiting to be written.",
+	)
+	// Outcomes 按结果统计异步写入的执行日志：written 已写库、dropped 因队列已满丢弃、
+	// failed 写库失败或 Stream 条目无法解析。
+	Outcomes = metrics.NewCounterVec(
+		"prompt_manager_execution_logs_total",
+		"Execution logs handled by the asynchronous writers, by outcome.",
+		"outcome",
+	)
+)
+
+func init() {
+	metrics.Default.MustRegister(QueueDepth, Outcomes)
+}
+
+// Option 调整 BufferedWriter 的阈值与溢出策略。
+type Option func(*BufferedWriter)
+
+// WithBufferSize 设置内存队列容量，默认 10000。
+func WithBufferSize(size int) Option {
+	return func(w *BufferedWriter) {
+		if size > 0 {
+			w.bufferSize = size
+		}
+	}
+}
+
+// WithBatchSize 设置单次写库的最大条数，达到后立即刷新，默认 200。
+func WithBatchSize(size int) Option {
+	return func(w *BufferedWriter) {
+		if size > 0 {
+			w.batchSize = size
+		}
+	}
+}
+
+// WithFlushInterval 设置未攒满一批时的最长等待时间，默认 1 秒。
+func WithFlushInterval(interval time.Duration) Option {
+	return func(w *BufferedWriter) {
+		if interval > 0 {
+			w.flushInterval = interval
+		}
+	}
+}
+
+// WithOverflowPolicy 设置队列已满时的处理方式（OverflowDrop 或 OverflowBlock），默认丢弃。
+func WithOverflowPolicy(policy string) Option {
+	return func(w *BufferedWriter) {
+		if policy == OverflowDrop || policy == OverflowBlock {
+			w.overflow = policy
+		}
+	}
+}
+
+// BufferedWriter 包装执行日志仓储：Create/CreateBatch 只把日志放入内存队列，由后台协程按
+// 条数或时间阈值批量写库；查询方法直接委托给底层仓储。进程退出前需调用 Close 排空队列。
+type BufferedWriter struct {
+	domain.PromptExecutionLogRepository
+
+	logger        *zap.Logger
+	bufferSize    int
+	batchSize     int
+	flushInterval time.Duration
+	overflow      string
+
+	queue chan *domain.PromptExecutionLog
+	done  chan struct{}
+
+	mu      sync.RWMutex
+	started bool
+	closed  bool
+}
+
+// NewBufferedWriter 创建写入器，需调用 Start 启动后台刷新。
+func NewBufferedWriter(repo domain.PromptExecutionLogRepository, logger *zap.Logger, opts ...Option) *BufferedWriter {
+	w := &BufferedWriter{
+		PromptExecutionLogRepository: repo,
+		logger:                       logger,
+		bufferSize:                   10000,
+		batchSize:                    200,
+		flushInterval:                time.Second,
+		overflow:                     OverflowDrop,
+		done:                         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.queue = make(chan *domain.PromptExecutionLog, w.bufferSize)
+	return w
+}
+
+// Start 启动后台刷新协程。刷新协程不随 ctx 退出，而是在 Close 时排空队列后结束，
+// 以免关停信号到达时丢弃尚未写库的日志。
+func (w *BufferedWriter) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return
+	}
+	w.started = true
+	go w.run(context.WithoutCancel(ctx))
+}
+
+// Create 将单条日志放入队列。
+func (w *BufferedWriter) Create(ctx context.Context, log *domain.PromptExecutionLog) error {
+	return w.enqueue(ctx, []*domain.PromptExecutionLog{log})
+}
+
+// CreateBatch 将多条日志放入队列；写库按批次重新组合，不再保证整批原子写入。
+func (w *BufferedWriter) CreateBatch(ctx context.Context, logs []*domain.PromptExecutionLog) error {
+	return w.enqueue(ctx, logs)
+}
+
+func (w *BufferedWriter) enqueue(ctx context.Context, logs []*domain.PromptExecutionLog) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	// 关闭后（或尚未启动时）没有刷新协程，直接同步写库，关停过程中到达的请求不会丢日志。
+	if w.closed || !w.started {
+		return w.PromptExecutionLogRepository.CreateBatch(ctx, logs)
+	}
+
+	for _, log := range logs {
+		if log.CreatedAt.IsZero() {
+			// 写库可能延后，入队时固定执行时间，避免统计分桶偏移。
+			log.CreatedAt = time.Now().UTC()
+		}
+		if w.overflow == OverflowBlock {
+			select {
+			case w.queue <- log:
+				QueueDepth.Add(1)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case w.queue <- log:
+			QueueDepth.Add(1)
+		default:
+			Outcomes.Inc("dropped")
+			w.logger.Warn("execution log dropped; buffer full", zap.String("prompt_id", log.PromptID), zap.String("log_id", log.ID))
+		}
+	}
+	return nil
+}
+
+func (w *BufferedWriter) run(ctx context.Context) {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*domain.PromptExecutionLog, 0, w.batchSize)
+	for {
+		select {
+		case log, ok := <-w.queue:
+			if !ok {
+				w.flush(ctx, batch)
+				return
+			}
+			QueueDepth.Add(-1)
+			batch = append(batch, log)
+			if len(batch) >= w.batchSize {
+				w.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (w *BufferedWriter) flush(ctx context.Context, batch []*domain.PromptExecutionLog) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := w.PromptExecutionLogRepository.CreateBatch(ctx, batch); err != nil {
+		Outcomes.Add(uint64(len(batch)), "failed")
+		w.logger.Error("flush execution logs failed", zap.Int("count", len(batch)), zap.Error(err))
+		return
+	}
+	Outcomes.Add(uint64(len(batch)), "written")
+}
+
+// Close 停止接收新日志并等待队列排空；ctx 到期时返回 ctx.Err()，剩余日志继续在后台写入。
+func (w *BufferedWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	started := w.started
+	close(w.queue)
+	w.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
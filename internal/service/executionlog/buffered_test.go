@@ -0,0 +1,126 @@
+package executionlog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+type recordingRepo struct {
+	domain.PromptExecutionLogRepository
+
+	mu      sync.Mutex
+	batches [][]*domain.PromptExecutionLog
+	release chan struct{}
+}
+
+func (r *recordingRepo) CreateBatch(ctx context.Context, logs []*domain.PromptExecutionLog) error {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, append([]*domain.PromptExecutionLog(nil), logs...))
+	return nil
+}
+
+func (r *recordingRepo) written() (batches, logs int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, batch := range r.batches {
+		logs += len(batch)
+	}
+	return len(r.batches), logs
+}
+
+func testLog(i int) *domain.PromptExecutionLog {
+	return &domain.PromptExecutionLog{ID: fmt.Sprintf("log-%d", i), PromptID: "prompt-1", PromptVersionID: "version-1", Status: "success"}
+}
+
+func TestBufferedWriterFlushesBySizeAndDrainsOnClose(t *testing.T) {
+	repo := &recordingRepo{}
+	writer := NewBufferedWriter(repo, zap.NewNop(), WithBatchSize(3), WithFlushInterval(time.Hour))
+	writer.Start(context.Background())
+
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		if err := writer.Create(ctx, testLog(i)); err != nil {
+			t.Fatalf("create log %d: %v", i, err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if batches, _ := repo.written(); batches >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if batches, logs := repo.written(); batches != 2 || logs != 6 {
+		t.Fatalf("expected two full batches before close, got %d batches / %d logs", batches, logs)
+	}
+
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, logs := repo.written(); logs != 7 {
+		t.Fatalf("expected close to drain remaining log, got %d logs", logs)
+	}
+	for _, batch := range repo.batches {
+		for _, log := range batch {
+			if log.CreatedAt.IsZero() {
+				t.Fatalf("expected enqueue to stamp created_at on %s", log.ID)
+			}
+		}
+	}
+
+	// 关闭后回退为同步写入。
+	if err := writer.Create(ctx, testLog(8)); err != nil {
+		t.Fatalf("create after close: %v", err)
+	}
+	if _, logs := repo.written(); logs != 8 {
+		t.Fatalf("expected synchronous write after close, got %d logs", logs)
+	}
+}
+
+func TestBufferedWriterOverflow(t *testing.T) {
+	repo := &recordingRepo{release: make(chan struct{})}
+	writer := NewBufferedWriter(repo, zap.NewNop(), WithBufferSize(2), WithBatchSize(1), WithFlushInterval(time.Hour))
+	writer.Start(context.Background())
+	ctx := context.Background()
+
+	// 第一条被刷新协程取走后阻塞在写库上，随后两条填满队列，第四条被丢弃。
+	if err := writer.Create(ctx, testLog(0)); err != nil {
+		t.Fatalf("create first log: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(writer.queue) != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	dropped := Outcomes.Value("dropped")
+	if err := writer.CreateBatch(ctx, []*domain.PromptExecutionLog{testLog(1), testLog(2), testLog(3)}); err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	if got := Outcomes.Value("dropped") - dropped; got != 1 {
+		t.Fatalf("expected one dropped log, got %d", got)
+	}
+
+	// 不启动刷新协程，直接占满队列，使下一次写入等待。
+	blocking := NewBufferedWriter(repo, zap.NewNop(), WithBufferSize(1), WithOverflowPolicy(OverflowBlock))
+	blocking.started = true
+	blocking.queue <- testLog(9)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := blocking.Create(timeoutCtx, testLog(10)); err != context.DeadlineExceeded {
+		t.Fatalf("expected block policy to wait until context deadline, got %v", err)
+	}
+
+	close(repo.release)
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("close writer: %v", err)
+	}
+}
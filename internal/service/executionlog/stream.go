@@ -0,0 +1,278 @@
+package executionlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// streamField 为 Stream 条目中保存日志 JSON 的字段名。
+	streamField = "log"
+	// streamRetryDelay 为读取或写库失败后的重试间隔。
+	streamRetryDelay = 5 * time.Second
+)
+
+// StreamWriter 包装执行日志仓储：Create/CreateBatch 把日志追加到 Redis Stream，由 StreamConsumer
+// 写库；查询方法直接委托给底层仓储。与 BufferedWriter 不同，进程重启不会丢失已入队的日志。
+type StreamWriter struct {
+	domain.PromptExecutionLogRepository
+
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewStreamWriter 创建 Stream 写入器。maxLen 大于 0 时按近似长度裁剪 Stream，
+// 防止消费者长时间不可用时 Redis 内存无限增长（超出部分的日志会丢失）。
+func NewStreamWriter(repo domain.PromptExecutionLogRepository, client *redis.Client, stream string, maxLen int64) *StreamWriter {
+	return &StreamWriter{PromptExecutionLogRepository: repo, client: client, stream: stream, maxLen: maxLen}
+}
+
+// Create 将单条日志追加到 Stream。
+func (w *StreamWriter) Create(ctx context.Context, log *domain.PromptExecutionLog) error {
+	return w.CreateBatch(ctx, []*domain.PromptExecutionLog{log})
+}
+
+// CreateBatch 通过一次 pipeline 将多条日志追加到 Stream，每条日志为一个条目。
+func (w *StreamWriter) CreateBatch(ctx context.Context, logs []*domain.PromptExecutionLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	pipe := w.client.Pipeline()
+	for _, log := range logs {
+		if log.CreatedAt.IsZero() {
+			// 写库可能延后，入队时固定执行时间，避免统计分桶偏移。
+			log.CreatedAt = time.Now().UTC()
+		}
+		values, err := encodeStreamLog(log)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: w.stream, MaxLen: w.maxLen, Approx: w.maxLen > 0, Values: values})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("enqueue execution logs: %w", err)
+	}
+	return nil
+}
+
+func encodeStreamLog(log *domain.PromptExecutionLog) (map[string]interface{}, error) {
+	payload, err := json.Marshal(log)
+	if err != nil {
+		return nil, fmt.Errorf("encode execution log: %w", err)
+	}
+	return map[string]interface{}{streamField: string(payload)}, nil
+}
+
+func decodeStreamLog(message redis.XMessage) (*domain.PromptExecutionLog, error) {
+	raw, ok := message.Values[streamField].(string)
+	if !ok {
+		return nil, fmt.Errorf("stream entry %s has no %q field", message.ID, streamField)
+	}
+	var log domain.PromptExecutionLog
+	if err := json.Unmarshal([]byte(raw), &log); err != nil {
+		return nil, fmt.Errorf("decode stream entry %s: %w", message.ID, err)
+	}
+	if log.ID == "" || log.PromptID == "" || log.PromptVersionID == "" {
+		return nil, fmt.Errorf("stream entry %s is missing identifiers", message.ID)
+	}
+	return &log, nil
+}
+
+// ConsumerOption 调整 StreamConsumer 的读取参数。
+type ConsumerOption func(*StreamConsumer)
+
+// WithConsumerName 设置消费者名称，多个实例共享消费组时需各不相同，默认 "worker"。
+func WithConsumerName(name string) ConsumerOption {
+	return func(c *StreamConsumer) {
+		if name != "" {
+			c.consumer = name
+		}
+	}
+}
+
+// WithReadBatchSize 设置单次读取并写库的最大条数，默认 200。
+func WithReadBatchSize(size int) ConsumerOption {
+	return func(c *StreamConsumer) {
+		if size > 0 {
+			c.batchSize = size
+		}
+	}
+}
+
+// WithReadBlock 设置无新条目时单次读取的最长阻塞时间，默认 1 秒。
+func WithReadBlock(block time.Duration) ConsumerOption {
+	return func(c *StreamConsumer) {
+		if block > 0 {
+			c.block = block
+		}
+	}
+}
+
+// WithClaimIdle 设置其他消费者未确认条目的接管阈值，默认 1 分钟。
+func WithClaimIdle(idle time.Duration) ConsumerOption {
+	return func(c *StreamConsumer) {
+		if idle > 0 {
+			c.claimIdle = idle
+		}
+	}
+}
+
+// StreamConsumer 以消费组方式读取 Stream 中的执行日志并批量写库，写库成功后才确认条目，
+// 因此日志至少写入一次；重复投递依赖 CreateBatch 按 ID 跳过已存在的日志。
+type StreamConsumer struct {
+	repo   domain.PromptExecutionLogRepository
+	client *redis.Client
+	logger *zap.Logger
+	stream string
+	group  string
+
+	consumer  string
+	batchSize int
+	block     time.Duration
+	claimIdle time.Duration
+}
+
+// NewStreamConsumer 创建消费者，repo 必须是直接写库的仓储而非 StreamWriter。
+func NewStreamConsumer(repo domain.PromptExecutionLogRepository, client *redis.Client, logger *zap.Logger, stream, group string, opts ...ConsumerOption) *StreamConsumer {
+	c := &StreamConsumer{
+		repo:      repo,
+		client:    client,
+		logger:    logger,
+		stream:    stream,
+		group:     group,
+		consumer:  "worker",
+		batchSize: 200,
+		block:     time.Second,
+		claimIdle: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Run 持续消费直到 ctx 取消。启动时先处理本消费者上次退出前已读取但未确认的条目，
+// 并定期接管其他消费者空闲超过阈值的条目，避免实例下线后日志滞留。
+func (c *StreamConsumer) Run(ctx context.Context) error {
+	if err := c.ensureGroup(ctx); err != nil {
+		return err
+	}
+
+	pending := true
+	lastClaim := time.Now()
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= c.claimIdle {
+			lastClaim = time.Now()
+			if c.claim(ctx) {
+				pending = true
+			}
+		}
+
+		// "0" 读取本消费者的待确认条目且不阻塞，">" 读取新条目。
+		args := &redis.XReadGroupArgs{Group: c.group, Consumer: c.consumer, Count: int64(c.batchSize), Streams: []string{c.stream, ">"}, Block: c.block}
+		if pending {
+			args.Streams[1] = "0"
+			args.Block = -1
+		}
+		streams, err := c.client.XReadGroup(ctx, args).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			c.logger.Error("read execution log stream failed", zap.String("stream", c.stream), zap.Error(err))
+			c.wait(ctx)
+			continue
+		}
+
+		var messages []redis.XMessage
+		for _, stream := range streams {
+			messages = append(messages, stream.Messages...)
+		}
+		if len(messages) == 0 {
+			pending = false
+			continue
+		}
+		if err := c.persist(ctx, messages); err != nil {
+			c.logger.Error("persist execution logs failed", zap.Int("count", len(messages)), zap.Error(err))
+			// 条目保留在待确认列表中，稍后从 "0" 重新读取。
+			pending = true
+			c.wait(ctx)
+		}
+	}
+	return nil
+}
+
+func (c *StreamConsumer) ensureGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.stream, c.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("create consumer group: %w", err)
+	}
+	return nil
+}
+
+// claim 将其他消费者空闲超过阈值的条目转到本消费者名下，返回是否有条目被接管。
+func (c *StreamConsumer) claim(ctx context.Context) bool {
+	ids, _, err := c.client.XAutoClaimJustID(ctx, &redis.XAutoClaimArgs{
+		Stream:   c.stream,
+		Group:    c.group,
+		Consumer: c.consumer,
+		MinIdle:  c.claimIdle,
+		Start:    "0-0",
+		Count:    int64(c.batchSize),
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Warn("claim idle execution logs failed", zap.String("stream", c.stream), zap.Error(err))
+		}
+		return false
+	}
+	return len(ids) > 0
+}
+
+// persist 写库并确认一批条目。无法解析的条目直接确认并计为 failed，避免反复投递。
+// 写库与确认不随 ctx 取消中断，关停时正在处理的批次会完整结束。
+func (c *StreamConsumer) persist(ctx context.Context, messages []redis.XMessage) error {
+	ctx = context.WithoutCancel(ctx)
+	ids := make([]string, 0, len(messages))
+	logs := make([]*domain.PromptExecutionLog, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+		log, err := decodeStreamLog(message)
+		if err != nil {
+			Outcomes.Inc("failed")
+			c.logger.Error("discard malformed execution log", zap.String("stream", c.stream), zap.Error(err))
+			continue
+		}
+		logs = append(logs, log)
+	}
+	if err := c.repo.CreateBatch(ctx, logs); err != nil {
+		return err
+	}
+	Outcomes.Add(uint64(len(logs)), "written")
+	if err := c.client.XAck(ctx, c.stream, c.group, ids...).Err(); err != nil {
+		// 未确认的条目会被重新投递，写库按 ID 去重，不会产生重复日志。
+		c.logger.Warn("ack execution logs failed", zap.String("stream", c.stream), zap.Error(err))
+	}
+	return nil
+}
+
+func (c *StreamConsumer) wait(ctx context.Context) {
+	timer := time.NewTimer(streamRetryDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
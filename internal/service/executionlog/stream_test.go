@@ -0,0 +1,32 @@
+package executionlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStreamLogRoundTrip(t *testing.T) {
+	log := testLog(1)
+	log.CreatedAt = time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	values, err := encodeStreamLog(log)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	decoded, err := decodeStreamLog(redis.XMessage{ID: "1-0", Values: values})
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.ID != log.ID || decoded.PromptVersionID != log.PromptVersionID || !decoded.CreatedAt.Equal(log.CreatedAt) {
+		t.Fatalf("unexpected decoded log %+v", decoded)
+	}
+
+	// 被裁剪的待确认条目没有字段，格式错误的条目同样应被拒绝。
+	for _, values := range []map[string]interface{}{nil, {streamField: "{"}, {streamField: `{"id":"x"}`}} {
+		if _, err := decodeStreamLog(redis.XMessage{ID: "2-0", Values: values}); err == nil {
+			t.Fatalf("expected decode error for %v", values)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package freeze 维护变更冻结窗口（如大促、发布会前后），窗口内禁止激活与删除 Prompt。
+package freeze
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/pkg/cron"
+)
+
+// MaxRecurringDuration 为周期性窗口的最长持续时间。
+const MaxRecurringDuration = 7 * 24 * time.Hour
+
+// WindowSpec 描述一个冻结窗口：固定时间段（Start/End，RFC3339）或周期窗口（Cron 为开始时刻，持续 Duration），二选一。
+// Workspaces 为空时对全部工作区生效；Timezone 为 Cron 的计算时区，默认 UTC。
+type WindowSpec struct {
+	Name       string
+	Workspaces []string
+	Start      string
+	End        string
+	Cron       string
+	Duration   time.Duration
+	Timezone   string
+}
+
+// Window 为解析后的冻结窗口。
+type Window struct {
+	name       string
+	workspaces map[string]struct{}
+	start, end time.Time
+	schedule   *cron.Schedule
+	duration   time.Duration
+	location   *time.Location
+}
+
+// ParseWindow 校验并解析冻结窗口。
+func ParseWindow(spec WindowSpec) (*Window, error) {
+	name := strings.TrimSpace(spec.Name)
+	if name == "" {
+		return nil, errors.New("freeze window name is required")
+	}
+	window := &Window{name: name, location: time.UTC}
+	if len(spec.Workspaces) > 0 {
+		window.workspaces = make(map[string]struct{}, len(spec.Workspaces))
+		for _, workspace := range spec.Workspaces {
+			window.workspaces[strings.TrimSpace(workspace)] = struct{}{}
+		}
+	}
+
+	recurring := strings.TrimSpace(spec.Cron) != ""
+	fixed := spec.Start != "" || spec.End != ""
+	switch {
+	case recurring && fixed:
+		return nil, fmt.Errorf("freeze window %s: use either start/end or cron/duration", name)
+	case recurring:
+		schedule, err := cron.Parse(spec.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("freeze window %s: %w", name, err)
+		}
+		if spec.Duration <= 0 || spec.Duration > MaxRecurringDuration {
+			return nil, fmt.Errorf("freeze window %s: duration must be positive and at most %s", name, MaxRecurringDuration)
+		}
+		if spec.Timezone != "" {
+			location, err := time.LoadLocation(spec.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("freeze window %s: invalid timezone %q", name, spec.Timezone)
+			}
+			window.location = location
+		}
+		window.schedule, window.duration = schedule, spec.Duration
+	default:
+		start, err := time.Parse(time.RFC3339, spec.Start)
+		if err != nil {
+			return nil, fmt.Errorf("freeze window %s: start must be an RFC3339 time", name)
+		}
+		end, err := time.Parse(time.RFC3339, spec.End)
+		if err != nil || !end.After(start) {
+			return nil, fmt.Errorf("freeze window %s: end must be an RFC3339 time after start", name)
+		}
+		window.start, window.end = start, end
+	}
+	return window, nil
+}
+
+// Name 返回窗口名称。
+func (w *Window) Name() string {
+	return w.name
+}
+
+// activeUntil 判断 at 是否落在窗口内并返回本次冻结的结束时间。
+func (w *Window) activeUntil(workspaceID string, at time.Time) (time.Time, bool) {
+	if w.workspaces != nil {
+		if _, ok := w.workspaces[workspaceID]; !ok {
+			return time.Time{}, false
+		}
+	}
+	if w.schedule == nil {
+		return w.end, !at.Before(w.start) && at.Before(w.end)
+	}
+	started, ok := w.schedule.Previous(at.In(w.location), w.duration)
+	if !ok {
+		return time.Time{}, false
+	}
+	return started.Add(w.duration), true
+}
+
+// Calendar 汇总全部冻结窗口。
+type Calendar struct {
+	windows []*Window
+}
+
+// NewCalendar 创建冻结日历。
+func NewCalendar(windows ...*Window) *Calendar {
+	return &Calendar{windows: windows}
+}
+
+// Active 返回工作区在 at 时刻所处的冻结窗口名称与冻结结束时间；多个窗口重叠时取结束最晚的一个。
+func (c *Calendar) Active(workspaceID string, at time.Time) (string, time.Time, bool) {
+	var (
+		name  string
+		until time.Time
+		found bool
+	)
+	for _, window := range c.windows {
+		end, ok := window.activeUntil(workspaceID, at)
+		if ok && (!found || end.After(until)) {
+			name, until, found = window.name, end, true
+		}
+	}
+	return name, until.UTC(), found
+}
@@ -0,0 +1,57 @@
+package freeze
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarActive(t *testing.T) {
+	launch, err := ParseWindow(WindowSpec{Name: "launch", Start: "2025-11-27T00:00:00Z", End: "2025-12-01T00:00:00Z"})
+	if err != nil {
+		t.Fatalf("parse fixed window: %v", err)
+	}
+	// 每周五 18:00（上海时间）起冻结 62 小时，仅作用于 team-a。
+	weekend, err := ParseWindow(WindowSpec{Name: "weekend", Cron: "0 18 * * 5", Duration: 62 * time.Hour, Timezone: "Asia/Shanghai", Workspaces: []string{"team-a"}})
+	if err != nil {
+		t.Fatalf("parse recurring window: %v", err)
+	}
+	calendar := NewCalendar(launch, weekend)
+
+	cases := []struct {
+		name      string
+		workspace string
+		at        string
+		window    string
+		until     string
+	}{
+		{"fixed window", "team-b", "2025-11-28T12:00:00Z", "launch", "2025-12-01T00:00:00Z"},
+		{"after fixed window", "team-b", "2025-12-01T00:00:00Z", "", ""},
+		{"recurring window", "team-a", "2025-12-06T02:00:00Z", "weekend", "2025-12-08T00:00:00Z"},
+		{"recurring window other workspace", "team-b", "2025-12-06T02:00:00Z", "", ""},
+		{"before recurring window", "team-a", "2025-12-05T09:59:00Z", "", ""},
+		{"overlap with same end", "team-a", "2025-11-29T02:00:00Z", "launch", "2025-12-01T00:00:00Z"},
+	}
+	for _, tc := range cases {
+		at, _ := time.Parse(time.RFC3339, tc.at)
+		window, until, ok := calendar.Active(tc.workspace, at)
+		if ok != (tc.window != "") || window != tc.window {
+			t.Fatalf("%s: expected window %q, got %q (active=%v)", tc.name, tc.window, window, ok)
+		}
+		if ok && until.Format(time.RFC3339) != tc.until {
+			t.Fatalf("%s: expected until %s, got %s", tc.name, tc.until, until.Format(time.RFC3339))
+		}
+	}
+
+	invalid := []WindowSpec{
+		{Name: "", Start: "2025-11-27T00:00:00Z", End: "2025-12-01T00:00:00Z"},
+		{Name: "both", Start: "2025-11-27T00:00:00Z", Cron: "0 0 * * *", Duration: time.Hour},
+		{Name: "bad-cron", Cron: "0 25 * * *", Duration: time.Hour},
+		{Name: "no-duration", Cron: "0 0 * * *"},
+		{Name: "reversed", Start: "2025-12-01T00:00:00Z", End: "2025-11-27T00:00:00Z"},
+	}
+	for _, spec := range invalid {
+		if _, err := ParseWindow(spec); err == nil {
+			t.Fatalf("expected %+v to be rejected", spec)
+		}
+	}
+}
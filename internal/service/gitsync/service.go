@@ -0,0 +1,308 @@
+// Package gitsync 接收 GitHub push webhook，将配置仓库中改动的 Prompt 文件导入为 Prompt 或新版本，
+// 使 Git 中的修改自动回流到 Prompt Manager。
+package gitsync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+)
+
+// SignatureHeader 为 GitHub 携带请求体 HMAC-SHA256 签名（sha256=<hex>）的请求头。
+const SignatureHeader = "X-Hub-Signature-256"
+
+// maxFileBytes 限制单个文件的读取大小，与导入包的单文件上限一致。
+const maxFileBytes = 1 << 20
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidPayload   = errors.New("invalid push payload")
+	ErrFetchFailed      = errors.New("fetch file from github failed")
+)
+
+// Importer 导入一组 Prompt 文件，由 Prompt 服务实现。
+type Importer interface {
+	ImportFiles(ctx context.Context, files []promptsvc.ImportFile, opts promptsvc.ImportOptions) (*promptsvc.ImportReport, error)
+}
+
+// PushEvent 为 GitHub push 事件中同步所需的字段。
+type PushEvent struct {
+	Ref        string         `json:"ref"`
+	After      string         `json:"after"`
+	Deleted    bool           `json:"deleted"`
+	Repository pushRepository `json:"repository"`
+	Pusher     pushUser       `json:"pusher"`
+	Commits    []pushCommit   `json:"commits"`
+}
+
+type pushRepository struct {
+	FullName string `json:"full_name"`
+}
+
+type pushUser struct {
+	Name string `json:"name"`
+}
+
+type pushCommit struct {
+	Added    []string `json:"added"`
+	Modified []string `json:"modified"`
+	Removed  []string `json:"removed"`
+}
+
+// Result 为一次 push 的处理结果。Ignored 非空表示事件与配置不匹配而未处理；
+// Removed 列出被删除的 Prompt 文件，同步不会删除对应 Prompt，需人工处理。
+type Result struct {
+	Repository string                  `json:"repository"`
+	Ref        string                  `json:"ref"`
+	Commit     string                  `json:"commit"`
+	Ignored    string                  `json:"ignored,omitempty"`
+	Files      []string                `json:"files"`
+	Removed    []string                `json:"removed,omitempty"`
+	Report     *promptsvc.ImportReport `json:"report,omitempty"`
+}
+
+// Service 校验并处理 GitHub push 事件。
+type Service struct {
+	importer   Importer
+	repository string
+	secret     string
+	branch     string
+	dir        string
+	token      string
+	apiBase    string
+	workspace  string
+	httpClient *http.Client
+}
+
+// Option 自定义 Service 行为。
+type Option func(*Service)
+
+// WithBranch 设置同步的分支，默认 main。
+func WithBranch(branch string) Option {
+	return func(s *Service) {
+		if branch != "" {
+			s.branch = branch
+		}
+	}
+}
+
+// WithPath 限定 Prompt 文件所在目录（相对仓库根目录）。
+func WithPath(dir string) Option {
+	return func(s *Service) {
+		s.dir = strings.Trim(path.Clean("/"+dir), "/")
+	}
+}
+
+// WithToken 设置读取文件内容所用的 GitHub 令牌。
+func WithToken(token string) Option {
+	return func(s *Service) {
+		s.token = token
+	}
+}
+
+// WithAPIBase 设置 GitHub API 地址，默认 https://api.github.com。
+func WithAPIBase(apiBase string) Option {
+	return func(s *Service) {
+		if apiBase != "" {
+			s.apiBase = strings.TrimRight(apiBase, "/")
+		}
+	}
+}
+
+// WithWorkspace 设置导入的目标工作区，默认 default。
+func WithWorkspace(workspaceID string) Option {
+	return func(s *Service) {
+		if workspaceID != "" {
+			s.workspace = workspaceID
+		}
+	}
+}
+
+// WithHTTPClient 替换访问 GitHub API 所用的 HTTP 客户端。
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Service) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// NewService 创建同步服务；repository 为 owner/name，secret 为 GitHub webhook 的签名密钥。
+func NewService(importer Importer, repository, secret string, opts ...Option) *Service {
+	s := &Service{
+		importer:   importer,
+		repository: repository,
+		secret:     secret,
+		branch:     "main",
+		apiBase:    "https://api.github.com",
+		workspace:  domain.DefaultWorkspaceID,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// VerifySignature 校验请求体的 X-Hub-Signature-256 签名。
+func (s *Service) VerifySignature(body []byte, signature string) error {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// HandlePush 处理已通过签名校验的 push 事件：只处理配置仓库与分支，读取提交后仍存在且位于配置目录下的
+// Prompt 文件，按同名追加版本的规则导入。
+func (s *Service) HandlePush(ctx context.Context, payload []byte) (*Result, error) {
+	var event PushEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	result := &Result{Repository: event.Repository.FullName, Ref: event.Ref, Commit: event.After, Files: []string{}}
+	switch {
+	case !strings.EqualFold(event.Repository.FullName, s.repository):
+		result.Ignored = "repository not configured"
+		return result, nil
+	case event.Ref != "refs/heads/"+s.branch:
+		result.Ignored = "branch not configured"
+		return result, nil
+	case event.Deleted:
+		result.Ignored = "branch deleted"
+		return result, nil
+	}
+
+	changed, removed := s.changedFiles(event.Commits)
+	result.Removed = removed
+	if len(changed) == 0 {
+		return result, nil
+	}
+
+	files := make([]promptsvc.ImportFile, 0, len(changed))
+	for _, file := range changed {
+		content, err := s.fetchFile(ctx, file, event.After)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, promptsvc.ImportFile{Name: s.relative(file), Content: content})
+		result.Files = append(result.Files, file)
+	}
+
+	createdBy := "github"
+	if event.Pusher.Name != "" {
+		createdBy = "github:" + event.Pusher.Name
+	}
+	report, err := s.importer.ImportFiles(domain.WithWorkspace(ctx, s.workspace), files, promptsvc.ImportOptions{
+		OnConflict: promptsvc.ImportConflictAppend,
+		CreatedBy:  createdBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Report = report
+	return result, nil
+}
+
+// changedFiles 按提交顺序合并改动，返回推送后仍存在的与已删除的 Prompt 文件，均已排序。
+func (s *Service) changedFiles(commits []pushCommit) ([]string, []string) {
+	present := make(map[string]bool)
+	for _, commit := range commits {
+		for _, file := range append(commit.Added, commit.Modified...) {
+			present[file] = true
+		}
+		for _, file := range commit.Removed {
+			present[file] = false
+		}
+	}
+	var changed, removed []string
+	for file, exists := range present {
+		if !s.tracks(file) {
+			continue
+		}
+		if exists {
+			changed = append(changed, file)
+		} else {
+			removed = append(removed, file)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// tracks 判断文件是否位于配置目录下且为 YAML/JSON 文件。
+func (s *Service) tracks(file string) bool {
+	if s.dir != "" && !strings.HasPrefix(file, s.dir+"/") {
+		return false
+	}
+	switch strings.ToLower(path.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// relative 返回相对配置目录的路径，使仓库中的目录结构与导出包一致。
+func (s *Service) relative(file string) string {
+	if s.dir == "" {
+		return file
+	}
+	return strings.TrimPrefix(file, s.dir+"/")
+}
+
+// fetchFile 通过 contents API 读取文件在 ref 时的原始内容。
+func (s *Service) fetchFile(ctx context.Context, file, ref string) ([]byte, error) {
+	segments := strings.Split(file, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	target := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", s.apiBase, s.repository, strings.Join(segments, "/"), url.QueryEscape(ref))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.raw+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrFetchFailed, file, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: github responded %d", ErrFetchFailed, file, resp.StatusCode)
+	}
+	// 超出上限时多读一个字节，交由导入逻辑报告文件过大。
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrFetchFailed, file, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,133 @@
+package gitsync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+)
+
+type fakeImporter struct {
+	files     []promptsvc.ImportFile
+	opts      promptsvc.ImportOptions
+	workspace string
+}
+
+func (f *fakeImporter) ImportFiles(ctx context.Context, files []promptsvc.ImportFile, opts promptsvc.ImportOptions) (*promptsvc.ImportReport, error) {
+	f.files = files
+	f.opts = opts
+	f.workspace = domain.WorkspaceFromContext(ctx)
+	return &promptsvc.ImportReport{Total: len(files), Updated: len(files)}, nil
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	svc := NewService(&fakeImporter{}, "acme/prompts", "s3cret")
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	if err := svc.VerifySignature(body, sign("s3cret", body)); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	for _, signature := range []string{"", sign("other", body), "sha1=abc", "sha256=zz"} {
+		if err := svc.VerifySignature(body, signature); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected invalid signature for %q, got %v", signature, err)
+		}
+	}
+}
+
+func TestHandlePushImportsChangedFiles(t *testing.T) {
+	var paths []string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("ref") != "abc123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte("name: greeting\nversions:\n  - body: Hello\n"))
+	}))
+	defer github.Close()
+
+	importer := &fakeImporter{}
+	svc := NewService(importer, "Acme/Prompts", "s3cret",
+		WithPath("/prompts/"), WithToken("token"), WithAPIBase(github.URL), WithWorkspace("team-a"), WithHTTPClient(github.Client()))
+
+	payload := []byte(`{
+		"ref": "refs/heads/main",
+		"after": "abc123",
+		"repository": {"full_name": "acme/prompts"},
+		"pusher": {"name": "octocat"},
+		"commits": [
+			{"added": ["prompts/greeting.yaml", "prompts/old.yaml"], "modified": ["README.md"]},
+			{"modified": ["prompts/team/summary.json"], "removed": ["prompts/old.yaml", "docs/x.yaml"]},
+			{"removed": ["prompts/gone.yml"], "added": ["prompts/notes.txt"]}
+		]
+	}`)
+	result, err := svc.HandlePush(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("handle push: %v", err)
+	}
+	if result.Ignored != "" || strings.Join(result.Files, ",") != "prompts/greeting.yaml,prompts/team/summary.json" {
+		t.Fatalf("unexpected files %+v", result)
+	}
+	if strings.Join(result.Removed, ",") != "prompts/gone.yml,prompts/old.yaml" {
+		t.Fatalf("unexpected removed files %v", result.Removed)
+	}
+	if strings.Join(paths, ",") != "/repos/Acme/Prompts/contents/prompts/greeting.yaml,/repos/Acme/Prompts/contents/prompts/team/summary.json" {
+		t.Fatalf("unexpected fetched paths %v", paths)
+	}
+	if len(importer.files) != 2 || importer.files[1].Name != "team/summary.json" {
+		t.Fatalf("expected files relative to path, got %+v", importer.files)
+	}
+	if importer.opts.OnConflict != promptsvc.ImportConflictAppend || importer.opts.CreatedBy != "github:octocat" || importer.workspace != "team-a" {
+		t.Fatalf("unexpected import options %+v in %q", importer.opts, importer.workspace)
+	}
+	if result.Report == nil || result.Report.Updated != 2 {
+		t.Fatalf("expected import report, got %+v", result.Report)
+	}
+}
+
+func TestHandlePushIgnoresAndFailures(t *testing.T) {
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer github.Close()
+
+	importer := &fakeImporter{}
+	svc := NewService(importer, "acme/prompts", "s3cret", WithBranch("release"), WithAPIBase(github.URL))
+	ctx := context.Background()
+
+	cases := map[string]string{
+		`{"ref":"refs/heads/release","repository":{"full_name":"acme/other"}}`:                  "repository not configured",
+		`{"ref":"refs/heads/main","repository":{"full_name":"acme/prompts"}}`:                   "branch not configured",
+		`{"ref":"refs/heads/release","deleted":true,"repository":{"full_name":"acme/prompts"}}`: "branch deleted",
+	}
+	for payload, reason := range cases {
+		result, err := svc.HandlePush(ctx, []byte(payload))
+		if err != nil || result.Ignored != reason {
+			t.Fatalf("expected %q for %s, got %+v (%v)", reason, payload, result, err)
+		}
+	}
+	if importer.files != nil {
+		t.Fatalf("expected no import for ignored pushes")
+	}
+
+	if _, err := svc.HandlePush(ctx, []byte(`{`)); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("expected invalid payload, got %v", err)
+	}
+	payload := `{"ref":"refs/heads/release","after":"def","repository":{"full_name":"acme/prompts"},"commits":[{"added":["a.yaml"]}]}`
+	if _, err := svc.HandlePush(ctx, []byte(payload)); !errors.Is(err, ErrFetchFailed) {
+		t.Fatalf("expected fetch failure, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Package llm 定义调用 LLM 网关的公共接口，由 Pipeline 执行与执行回放共用，具体实现由部署方注入。
+package llm
+
+import "context"
+
+// CompletionRequest 描述发送给 LLM 网关的一次调用。Model 为调用方请求的模型，为空时由网关选择。
+type CompletionRequest struct {
+	PromptID        string
+	PromptVersionID string
+	Prompt          string
+	Variables       map[string]interface{}
+	Model           string
+}
+
+// CompletionResponse 为网关返回的结果，Metadata 会写入执行日志。
+type CompletionResponse struct {
+	Output   string
+	Metadata map[string]interface{}
+}
+
+// Gateway 抽象 LLM 调用入口，具体实现由部署方注入。
+type Gateway interface {
+	Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error)
+}
+
+// GatewayError 允许网关实现显式声明失败分类（如 guardrail_block、validation）及提供方错误码。
+type GatewayError struct {
+	Class string
+	Code  string
+	Err   error
+}
+
+func (e *GatewayError) Error() string {
+	if e.Err == nil {
+		return e.Class
+	}
+	return e.Err.Error()
+}
+
+func (e *GatewayError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// modelNamePattern 约束模型 ID 与系列名：小写字母或数字开头，可含 . _ : / -。
+var modelNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:/-]{0,127}$`)
+
+// 模型生命周期状态。
+const (
+	ModelStatusAvailable  = "available"
+	ModelStatusDeprecated = "deprecated"
+	ModelStatusRetired    = "retired"
+)
+
+// ValidModelStatus 判断是否为合法的生命周期状态。
+func ValidModelStatus(status string) bool {
+	switch status {
+	case ModelStatusAvailable, ModelStatusDeprecated, ModelStatusRetired:
+		return true
+	default:
+		return false
+	}
+}
+
+// Model 描述注册表中的一个已知模型；Family 为模型系列（如 gpt-4o），版本可声明系列以兼容其下全部模型。
+// Status 为生命周期状态，Replacement 为弃用或下线后建议迁移到的模型。
+type Model struct {
+	ID          string `json:"id"`
+	Family      string `json:"family,omitempty"`
+	Provider    string `json:"provider,omitempty"`
+	Status      string `json:"status"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Deprecated 判断模型是否已弃用或下线。
+func (m Model) Deprecated() bool {
+	return m.Status == ModelStatusDeprecated || m.Status == ModelStatusRetired
+}
+
+// Registry 为已知模型注册表，按 ID 与系列查找。nil 或空注册表表示未配置，此时不限制模型名称。
+type Registry struct {
+	models   []Model
+	byID     map[string]Model
+	families map[string][]string
+}
+
+// NewRegistry 创建注册表，ID 与系列统一规范化为小写，未设置状态的模型视为 available，重复 ID 只保留第一个。
+func NewRegistry(models []Model) *Registry {
+	r := &Registry{byID: make(map[string]Model, len(models)), families: map[string][]string{}}
+	for _, model := range models {
+		model.ID = NormalizeModelName(model.ID)
+		model.Family = NormalizeModelName(model.Family)
+		model.Replacement = NormalizeModelName(model.Replacement)
+		if model.Status == "" {
+			model.Status = ModelStatusAvailable
+		}
+		if _, ok := r.byID[model.ID]; ok || model.ID == "" {
+			continue
+		}
+		r.models = append(r.models, model)
+		r.byID[model.ID] = model
+		if model.Family != "" {
+			r.families[model.Family] = append(r.families[model.Family], model.ID)
+		}
+	}
+	return r
+}
+
+// NormalizeModelName 去除首尾空白并转为小写。
+func NormalizeModelName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ValidModelName 判断规范化后的名称是否为合法的模型 ID 或系列名。
+func ValidModelName(name string) bool {
+	return modelNamePattern.MatchString(name)
+}
+
+// Enabled 判断是否配置了已知模型。
+func (r *Registry) Enabled() bool {
+	return r != nil && len(r.models) > 0
+}
+
+// Models 按配置顺序返回全部已知模型。
+func (r *Registry) Models() []Model {
+	if r == nil {
+		return nil
+	}
+	return append([]Model(nil), r.models...)
+}
+
+// HasDeprecated 判断注册表中是否有已弃用或下线的模型。
+func (r *Registry) HasDeprecated() bool {
+	if r == nil {
+		return false
+	}
+	for _, model := range r.models {
+		if model.Deprecated() {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup 按 ID 查找模型。
+func (r *Registry) Lookup(id string) (Model, bool) {
+	if r == nil {
+		return Model{}, false
+	}
+	model, ok := r.byID[NormalizeModelName(id)]
+	return model, ok
+}
+
+// Known 判断名称是否为已知的模型 ID 或系列；注册表未配置时总是返回 true。
+func (r *Registry) Known(name string) bool {
+	if !r.Enabled() {
+		return true
+	}
+	name = NormalizeModelName(name)
+	if _, ok := r.byID[name]; ok {
+		return true
+	}
+	_, ok := r.families[name]
+	return ok
+}
+
+// Related 返回与名称互相覆盖的目标：模型 ID 对应自身及所属系列，系列对应自身及其下全部模型，结果已排序。
+// 用于按模型筛选声明了目标模型的版本。
+func (r *Registry) Related(name string) []string {
+	name = NormalizeModelName(name)
+	related := map[string]bool{name: true}
+	if r != nil {
+		if model, ok := r.byID[name]; ok && model.Family != "" {
+			related[model.Family] = true
+		}
+		for _, id := range r.families[name] {
+			related[id] = true
+		}
+	}
+	names := make([]string, 0, len(related))
+	for related := range related {
+		names = append(names, related)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Compatible 判断模型是否满足版本声明的目标：未声明目标时总是兼容，否则需命中模型 ID 或其所属系列。
+func (r *Registry) Compatible(model string, targets []string) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	model = NormalizeModelName(model)
+	family := ""
+	if found, ok := r.Lookup(model); ok {
+		family = found.Family
+	}
+	for _, target := range targets {
+		if target == model || (family != "" && target == family) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompatibilityWarning 返回模型不满足版本声明目标时的提示，未指定模型或兼容时返回空串。
+func (r *Registry) CompatibilityWarning(model string, targets []string) string {
+	if strings.TrimSpace(model) == "" || r.Compatible(model, targets) {
+		return ""
+	}
+	return fmt.Sprintf("model %q is not among the version's target models: %s", NormalizeModelName(model), strings.Join(targets, ", "))
+}
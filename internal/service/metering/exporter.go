@@ -0,0 +1,167 @@
+package metering
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// UsageRecord 为推送给计费系统的一条用量：Value 为周期内的累计值（存储为快照），
+// Delta 为相对上次成功推送的变化量。
+type UsageRecord struct {
+	OrganizationID string    `json:"organization_id"`
+	Period         string    `json:"period"`
+	Metric         string    `json:"metric"`
+	Value          int64     `json:"value"`
+	Delta          int64     `json:"delta"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// Exporter 将用量推送到外部计费系统，Name 用于区分各目标的推送进度。
+type Exporter interface {
+	Name() string
+	Export(ctx context.Context, records []UsageRecord) error
+}
+
+// WebhookSignatureHeader 携带请求体的 HMAC-SHA256 签名（sha256=<hex>），仅配置签名密钥时发送。
+const WebhookSignatureHeader = "X-Prompt-Manager-Signature"
+
+// WebhookExporter 以 JSON POST 推送用量，接收方应按 organization_id、period、metric 与 value 去重。
+type WebhookExporter struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookExporter 创建 webhook 推送目标，secret 为空时不签名。
+func NewWebhookExporter(target, secret string) *WebhookExporter {
+	return &WebhookExporter{url: target, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name 实现 Exporter。
+func (e *WebhookExporter) Name() string {
+	return "webhook"
+}
+
+// Export 实现 Exporter，一次请求推送全部记录。
+func (e *WebhookExporter) Export(ctx context.Context, records []UsageRecord) error {
+	payload, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.secret != "" {
+		mac := hmac.New(sha256.New, []byte(e.secret))
+		mac.Write(payload)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("billing webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// StripeExporter 通过 Stripe Billing Meter Events 上报用量。计数类指标上报增量（对应 sum 聚合的 Meter），
+// 存储上报当前值（对应 last 聚合的 Meter）。未映射 Stripe 客户的组织与未配置事件名的指标会被跳过。
+type StripeExporter struct {
+	apiKey    string
+	apiBase   string
+	customers map[string]string
+	events    map[string]string
+	client    *http.Client
+}
+
+// NewStripeExporter 创建 Stripe 推送目标；customers 为组织 ID 到 Stripe 客户 ID 的映射，
+// events 为指标名到 Meter 事件名的映射，apiBase 为空时使用 https://api.stripe.com。
+func NewStripeExporter(apiKey, apiBase string, customers, events map[string]string) *StripeExporter {
+	if apiBase == "" {
+		apiBase = "https://api.stripe.com"
+	}
+	return &StripeExporter{
+		apiKey:    apiKey,
+		apiBase:   strings.TrimRight(apiBase, "/"),
+		customers: customers,
+		events:    events,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 实现 Exporter。
+func (e *StripeExporter) Name() string {
+	return "stripe"
+}
+
+// Export 实现 Exporter，逐条创建 Meter 事件。identifier 由累计值派生，重试时 Stripe 会去重。
+func (e *StripeExporter) Export(ctx context.Context, records []UsageRecord) error {
+	for _, record := range records {
+		customer, eventName := e.customers[record.OrganizationID], e.events[record.Metric]
+		if customer == "" || eventName == "" {
+			continue
+		}
+		value := record.Delta
+		if record.Metric == domain.UsageMetricStorageBytes {
+			value = record.Value
+		}
+		if value <= 0 {
+			continue
+		}
+		form := url.Values{
+			"event_name":                  {eventName},
+			"payload[stripe_customer_id]": {customer},
+			"payload[value]":              {strconv.FormatInt(value, 10)},
+			"identifier":                  {fmt.Sprintf("%s:%s:%s:%d", record.OrganizationID, record.Period, record.Metric, record.Value)},
+			"timestamp":                   {strconv.FormatInt(record.Timestamp.Unix(), 10)},
+		}
+		if err := e.createMeterEvent(ctx, form); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StripeExporter) createMeterEvent(ctx context.Context, form url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.apiBase+"/v1/billing/meter_events", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		_ = json.Unmarshal(body, &apiErr)
+		return fmt.Errorf("stripe responded %d %s", resp.StatusCode, apiErr.Error.Code)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// Package metering 按租户（组织）统计 API 调用、执行次数与存储用量，并推送到外部计费系统。
+package metering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// periodLayout 为计量周期格式，按 UTC 自然月划分。
+const periodLayout = "2006-01"
+
+// ErrInvalidPeriod 表示计量周期格式不合法。
+var ErrInvalidPeriod = errors.New("period must be formatted as YYYY-MM")
+
+// PeriodOf 返回时间所属的计量周期。
+func PeriodOf(t time.Time) string {
+	return t.UTC().Format(periodLayout)
+}
+
+// periodRange 返回计量周期的起止时间 [from, to)。
+func periodRange(period string) (time.Time, time.Time, error) {
+	from, err := time.Parse(periodLayout, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// Recorder 在内存中累加 API 调用等计数，由 Flush 定期批量写库，避免每个请求都写数据库。
+type Recorder struct {
+	repo domain.UsageRepository
+	now  func() time.Time
+
+	mu      sync.Mutex
+	pending map[counterKey]int64
+}
+
+type counterKey struct {
+	workspaceID string
+	period      string
+	metric      string
+}
+
+// NewRecorder 创建计数器。
+func NewRecorder(repo domain.UsageRepository) *Recorder {
+	return &Recorder{repo: repo, now: time.Now, pending: make(map[counterKey]int64)}
+}
+
+// Record 为工作区的指标累加 n，周期取记录时刻所在的月份。
+func (r *Recorder) Record(workspaceID, metric string, n int64) {
+	if workspaceID == "" {
+		workspaceID = domain.DefaultWorkspaceID
+	}
+	key := counterKey{workspaceID: workspaceID, period: PeriodOf(r.now()), metric: metric}
+	r.mu.Lock()
+	r.pending[key] += n
+	r.mu.Unlock()
+}
+
+// Flush 将累加的计数写库；写入失败时计数保留到下次 Flush。
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[counterKey]int64)
+	r.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	counters := make([]domain.UsageCounter, 0, len(pending))
+	for key, value := range pending {
+		counters = append(counters, domain.UsageCounter{
+			WorkspaceID: key.workspaceID,
+			Period:      key.period,
+			Metric:      key.metric,
+			Value:       value,
+		})
+	}
+	if err := r.repo.AddCounters(ctx, counters); err != nil {
+		r.mu.Lock()
+		for key, value := range pending {
+			r.pending[key] += value
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Service 查询租户用量并推送到计费系统。
+type Service struct {
+	repo      domain.UsageRepository
+	exporters []Exporter
+	now       func() time.Time
+}
+
+// Option 自定义 Service 行为。
+type Option func(*Service)
+
+// WithExporters 配置用量推送目标。
+func WithExporters(exporters ...Exporter) Option {
+	return func(s *Service) {
+		s.exporters = append(s.exporters, exporters...)
+	}
+}
+
+// NewService 创建计量服务。
+func NewService(repo domain.UsageRepository, opts ...Option) *Service {
+	s := &Service{repo: repo, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HasExporters 判断是否配置了推送目标。
+func (s *Service) HasExporters() bool {
+	return len(s.exporters) > 0
+}
+
+// Usage 返回各租户在 period 的用量，period 为空时取当前月份。
+func (s *Service) Usage(ctx context.Context, period string) ([]*domain.TenantUsage, error) {
+	if period == "" {
+		period = PeriodOf(s.now())
+	}
+	from, to, err := periodRange(period)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.TenantUsage(ctx, period, from, to)
+}
+
+// 存储报告中占用最多的 Prompt 的默认与最大返回数量。
+const (
+	defaultStorageTop = 10
+	maxStorageTop     = 100
+)
+
+// Storage 返回各租户的存储占用与占用最多的 top 个 Prompt，供运维据此调整保留策略；
+// top 不大于 0 时取默认值，超过上限时截断。
+func (s *Service) Storage(ctx context.Context, top int) (*domain.StorageReport, error) {
+	if top <= 0 {
+		top = defaultStorageTop
+	}
+	return s.repo.StorageReport(ctx, min(top, maxStorageTop))
+}
+
+// Export 将当前与上一个月份的用量推送到全部目标。每个目标只接收自上次成功推送以来变化的指标，
+// 推送失败时不记录进度，下次整体重试；各目标互不影响。
+func (s *Service) Export(ctx context.Context) error {
+	now := s.now().UTC()
+	current := PeriodOf(now)
+	previous := PeriodOf(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0))
+
+	var errs []error
+	for _, period := range []string{previous, current} {
+		usages, err := s.Usage(ctx, period)
+		if err != nil {
+			return err
+		}
+		_, to, _ := periodRange(period)
+		timestamp := now
+		if !timestamp.Before(to) {
+			timestamp = to.Add(-time.Second)
+		}
+		for _, exporter := range s.exporters {
+			if err := s.exportPeriod(ctx, exporter, period, timestamp, usages); err != nil {
+				errs = append(errs, fmt.Errorf("export %s usage to %s: %w", period, exporter.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) exportPeriod(ctx context.Context, exporter Exporter, period string, timestamp time.Time, usages []*domain.TenantUsage) error {
+	exported, err := s.repo.ListExports(ctx, exporter.Name(), period)
+	if err != nil {
+		return err
+	}
+	previous := make(map[[2]string]int64, len(exported))
+	for _, export := range exported {
+		previous[[2]string{export.OrganizationID, export.Metric}] = export.Value
+	}
+
+	var (
+		records []UsageRecord
+		marks   []*domain.UsageExport
+	)
+	for _, usage := range usages {
+		for _, metric := range []struct {
+			name  string
+			value int64
+		}{
+			{domain.UsageMetricAPICalls, usage.APICalls},
+			{domain.UsageMetricExecutions, usage.Executions},
+			{domain.UsageMetricStorageBytes, usage.StorageBytes},
+		} {
+			last := previous[[2]string{usage.OrganizationID, metric.name}]
+			if metric.value == last {
+				continue
+			}
+			records = append(records, UsageRecord{
+				OrganizationID: usage.OrganizationID,
+				Period:         period,
+				Metric:         metric.name,
+				Value:          metric.value,
+				Delta:          metric.value - last,
+				Timestamp:      timestamp,
+			})
+			marks = append(marks, &domain.UsageExport{
+				OrganizationID: usage.OrganizationID,
+				Period:         period,
+				Metric:         metric.name,
+				Exporter:       exporter.Name(),
+				Value:          metric.value,
+			})
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	if err := exporter.Export(ctx, records); err != nil {
+		return err
+	}
+	return s.repo.SaveExports(ctx, marks)
+}
@@ -0,0 +1,202 @@
+package metering
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+type fakeUsageRepo struct {
+	mu       sync.Mutex
+	counters map[domain.UsageCounter]int64
+	usage    map[string][]*domain.TenantUsage
+	exports  map[string]*domain.UsageExport
+	failAdd  bool
+	topAsked []int
+}
+
+func newFakeUsageRepo() *fakeUsageRepo {
+	return &fakeUsageRepo{
+		counters: make(map[domain.UsageCounter]int64),
+		usage:    make(map[string][]*domain.TenantUsage),
+		exports:  make(map[string]*domain.UsageExport),
+	}
+}
+
+func (r *fakeUsageRepo) AddCounters(_ context.Context, counters []domain.UsageCounter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failAdd {
+		return errors.New("database unavailable")
+	}
+	for _, counter := range counters {
+		key := counter
+		key.Value = 0
+		r.counters[key] += counter.Value
+	}
+	return nil
+}
+
+func (r *fakeUsageRepo) TenantUsage(_ context.Context, period string, _, _ time.Time) ([]*domain.TenantUsage, error) {
+	return r.usage[period], nil
+}
+
+func (r *fakeUsageRepo) StorageReport(_ context.Context, top int) (*domain.StorageReport, error) {
+	r.topAsked = append(r.topAsked, top)
+	return &domain.StorageReport{}, nil
+}
+
+func (r *fakeUsageRepo) ListExports(_ context.Context, exporter, period string) ([]*domain.UsageExport, error) {
+	var out []*domain.UsageExport
+	for _, export := range r.exports {
+		if export.Exporter == exporter && export.Period == period {
+			out = append(out, export)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeUsageRepo) SaveExports(_ context.Context, exports []*domain.UsageExport) error {
+	for _, export := range exports {
+		r.exports[export.Exporter+"/"+export.OrganizationID+"/"+export.Period+"/"+export.Metric] = export
+	}
+	return nil
+}
+
+func TestRecorderFlushRetainsCountsOnFailure(t *testing.T) {
+	repo := newFakeUsageRepo()
+	recorder := NewRecorder(repo)
+	recorder.now = func() time.Time { return time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC) }
+
+	recorder.Record("team", domain.UsageMetricAPICalls, 1)
+	recorder.Record("team", domain.UsageMetricAPICalls, 1)
+	recorder.Record("", domain.UsageMetricAPICalls, 1)
+
+	repo.failAdd = true
+	if err := recorder.Flush(context.Background()); err == nil {
+		t.Fatalf("expected flush error")
+	}
+	repo.failAdd = false
+	recorder.Record("team", domain.UsageMetricAPICalls, 1)
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	team := domain.UsageCounter{WorkspaceID: "team", Period: "2025-03", Metric: domain.UsageMetricAPICalls}
+	def := domain.UsageCounter{WorkspaceID: domain.DefaultWorkspaceID, Period: "2025-03", Metric: domain.UsageMetricAPICalls}
+	if repo.counters[team] != 3 || repo.counters[def] != 1 {
+		t.Fatalf("unexpected counters: %+v", repo.counters)
+	}
+}
+
+func TestServiceExportPushesChangedUsage(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		webhookBodies []map[string][]UsageRecord
+		stripeEvents  []url.Values
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("hook-secret"))
+		mac.Write(body)
+		if r.Header.Get(WebhookSignatureHeader) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload map[string][]UsageRecord
+		_ = json.Unmarshal(body, &payload)
+		mu.Lock()
+		webhookBodies = append(webhookBodies, payload)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+	stripe := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/billing/meter_events" || r.Header.Get("Authorization") != "Bearer sk_test" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = r.ParseForm()
+		mu.Lock()
+		stripeEvents = append(stripeEvents, r.PostForm)
+		mu.Unlock()
+	}))
+	defer stripe.Close()
+
+	repo := newFakeUsageRepo()
+	repo.usage["2025-04"] = []*domain.TenantUsage{
+		{OrganizationID: "acme", Period: "2025-04", APICalls: 10, Executions: 4, StorageBytes: 2048},
+		{OrganizationID: "unbilled", Period: "2025-04", APICalls: 7},
+	}
+	service := NewService(repo, WithExporters(
+		NewWebhookExporter(webhook.URL, "hook-secret"),
+		NewStripeExporter("sk_test", stripe.URL,
+			map[string]string{"acme": "cus_123"},
+			map[string]string{domain.UsageMetricAPICalls: "api_calls", domain.UsageMetricStorageBytes: "storage"}),
+	))
+	service.now = func() time.Time { return time.Date(2025, 4, 15, 12, 0, 0, 0, time.UTC) }
+
+	if err := service.Export(context.Background()); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(webhookBodies) != 1 || len(webhookBodies[0]["records"]) != 4 {
+		t.Fatalf("expected one webhook call with 4 records, got %+v", webhookBodies)
+	}
+	if len(stripeEvents) != 2 {
+		t.Fatalf("expected 2 stripe meter events, got %+v", stripeEvents)
+	}
+	if got := stripeEvents[0]; got.Get("event_name") != "api_calls" || got.Get("payload[stripe_customer_id]") != "cus_123" || got.Get("payload[value]") != "10" {
+		t.Fatalf("unexpected api call event: %v", got)
+	}
+
+	// 只推送变化的指标：计数类上报增量，存储上报当前值。
+	repo.usage["2025-04"][0].APICalls = 15
+	repo.usage["2025-04"][0].StorageBytes = 1024
+	if err := service.Export(context.Background()); err != nil {
+		t.Fatalf("second export: %v", err)
+	}
+	if len(webhookBodies) != 2 || len(webhookBodies[1]["records"]) != 2 {
+		t.Fatalf("expected 2 changed records, got %+v", webhookBodies[len(webhookBodies)-1])
+	}
+	if len(stripeEvents) != 4 || stripeEvents[2].Get("payload[value]") != "5" || stripeEvents[3].Get("payload[value]") != "1024" {
+		t.Fatalf("unexpected stripe events: %+v", stripeEvents[2:])
+	}
+
+	if err := service.Export(context.Background()); err != nil {
+		t.Fatalf("third export: %v", err)
+	}
+	if len(webhookBodies) != 2 || len(stripeEvents) != 4 {
+		t.Fatalf("expected no pushes without changes")
+	}
+}
+
+func TestServiceUsageRejectsInvalidPeriod(t *testing.T) {
+	service := NewService(newFakeUsageRepo())
+	if _, err := service.Usage(context.Background(), "2025-13"); !errors.Is(err, ErrInvalidPeriod) {
+		t.Fatalf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
+
+func TestServiceStorageClampsTop(t *testing.T) {
+	repo := newFakeUsageRepo()
+	service := NewService(repo)
+	for _, top := range []int{0, 5, 1000} {
+		if _, err := service.Storage(context.Background(), top); err != nil {
+			t.Fatalf("storage report: %v", err)
+		}
+	}
+	if len(repo.topAsked) != 3 || repo.topAsked[0] != defaultStorageTop || repo.topAsked[1] != 5 || repo.topAsked[2] != maxStorageTop {
+		t.Fatalf("unexpected top values %v", repo.topAsked)
+	}
+}
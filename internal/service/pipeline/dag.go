@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	inputSourcePrefix = "input."
+	stepSourcePrefix  = "steps."
+	stepSourceSuffix  = ".output"
+)
+
+// Step 定义 Pipeline 中的一个 Prompt 步骤。
+type Step struct {
+	ID        string   `json:"id"`
+	PromptID  string   `json:"prompt_id"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Inputs 将模板变量映射到来源：input.<key> 读取调用入参，steps.<id>.output 读取上游步骤输出。
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// upstreamSteps 返回步骤的全部上游（显式 depends_on 与 inputs 中引用的步骤）。
+func (s Step) upstreamSteps() []string {
+	seen := make(map[string]struct{})
+	var upstream []string
+	add := func(id string) {
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		upstream = append(upstream, id)
+	}
+	for _, id := range s.DependsOn {
+		add(id)
+	}
+	for _, source := range s.Inputs {
+		if stepID, ok := parseStepSource(source); ok {
+			add(stepID)
+		}
+	}
+	return upstream
+}
+
+func parseStepSource(source string) (string, bool) {
+	if !strings.HasPrefix(source, stepSourcePrefix) || !strings.HasSuffix(source, stepSourceSuffix) {
+		return "", false
+	}
+	stepID := strings.TrimSuffix(strings.TrimPrefix(source, stepSourcePrefix), stepSourceSuffix)
+	return stepID, stepID != ""
+}
+
+// validateSteps 校验步骤定义并返回拓扑排序后的执行顺序。
+func validateSteps(steps []Step) ([]Step, error) {
+	if len(steps) == 0 {
+		return nil, ErrStepsRequired
+	}
+
+	index := make(map[string]Step, len(steps))
+	for _, step := range steps {
+		if strings.TrimSpace(step.ID) == "" || strings.TrimSpace(step.PromptID) == "" {
+			return nil, fmt.Errorf("%w: id and prompt_id are required", ErrInvalidStep)
+		}
+		if _, exists := index[step.ID]; exists {
+			return nil, fmt.Errorf("%w: duplicate step id %q", ErrInvalidStep, step.ID)
+		}
+		index[step.ID] = step
+	}
+
+	for _, step := range steps {
+		for variable, source := range step.Inputs {
+			if strings.HasPrefix(source, inputSourcePrefix) && len(source) > len(inputSourcePrefix) {
+				continue
+			}
+			if _, ok := parseStepSource(source); !ok {
+				return nil, fmt.Errorf("%w: step %q variable %q has invalid source %q", ErrInvalidStep, step.ID, variable, source)
+			}
+		}
+		for _, upstream := range step.upstreamSteps() {
+			if _, ok := index[upstream]; !ok {
+				return nil, fmt.Errorf("%w: step %q depends on unknown step %q", ErrInvalidStep, step.ID, upstream)
+			}
+		}
+	}
+
+	// Kahn 算法，入度相同的步骤按定义顺序执行，保证结果稳定。
+	order := make(map[string]int, len(steps))
+	inDegree := make(map[string]int, len(steps))
+	downstream := make(map[string][]string, len(steps))
+	for i, step := range steps {
+		order[step.ID] = i
+		for _, upstream := range step.upstreamSteps() {
+			inDegree[step.ID]++
+			downstream[upstream] = append(downstream[upstream], step.ID)
+		}
+	}
+
+	var ready []string
+	for _, step := range steps {
+		if inDegree[step.ID] == 0 {
+			ready = append(ready, step.ID)
+		}
+	}
+
+	sorted := make([]Step, 0, len(steps))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return order[ready[i]] < order[ready[j]] })
+		current := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, index[current])
+		for _, next := range downstream[current] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	if len(sorted) != len(steps) {
+		return nil, ErrPipelineCycle
+	}
+	return sorted, nil
+}
@@ -0,0 +1,19 @@
+package pipeline
+
+import "errors"
+
+var (
+	ErrNameRequired        = errors.New("pipeline name required")
+	ErrStepsRequired       = errors.New("pipeline steps required")
+	ErrInvalidStep         = errors.New("invalid pipeline step")
+	ErrPipelineCycle       = errors.New("pipeline steps contain a cycle")
+	ErrPipelineNotFound    = errors.New("pipeline not found")
+	ErrVersionNotFound     = errors.New("pipeline version not found")
+	ErrPipelineExists      = errors.New("pipeline already exists")
+	ErrPromptNotFound      = errors.New("pipeline step prompt not found")
+	ErrPromptNotActive     = errors.New("pipeline step prompt has no active version")
+	ErrPromptArchived      = errors.New("pipeline step prompt is archived")
+	ErrGatewayUnavailable  = errors.New("llm gateway is not configured")
+	ErrStepExecutionFailed = errors.New("pipeline step execution failed")
+	ErrRenderFailed        = errors.New("pipeline step template render failed")
+)
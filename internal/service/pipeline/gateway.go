@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+)
+
+// 网关相关类型定义在 llm 包中，与执行回放共用；此处保留别名以兼容现有调用方。
+type (
+	CompletionRequest  = llm.CompletionRequest
+	CompletionResponse = llm.CompletionResponse
+	Gateway            = llm.Gateway
+	GatewayError       = llm.GatewayError
+)
+
+// classifyError 推断网关调用失败的分类：显式声明优先，超时归为 timeout，其余视为 provider_error。
+func classifyError(err error) (class, code string) {
+	var gatewayErr *GatewayError
+	if errors.As(err, &gatewayErr) && gatewayErr.Class != "" {
+		return gatewayErr.Class, gatewayErr.Code
+	}
+	if gatewayErr != nil {
+		code = gatewayErr.Code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.ExecutionErrorTimeout, code
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return domain.ExecutionErrorTimeout, code
+	}
+	return domain.ExecutionErrorProvider, code
+}
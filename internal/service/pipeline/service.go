@@ -0,0 +1,395 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+	"github.com/zacharykka/prompt-manager/pkg/render"
+)
+
+// Service 负责 Pipeline 的定义、版本管理与执行。
+type Service struct {
+	repos   *domain.Repositories
+	gateway Gateway
+	models  *llm.Registry
+	now     func() time.Time
+}
+
+// Option 定义 Service 可选项。
+type Option func(*Service)
+
+// WithGateway 注入 LLM 网关，未注入时调用 Invoke 会返回 ErrGatewayUnavailable。
+func WithGateway(gateway Gateway) Option {
+	return func(s *Service) {
+		s.gateway = gateway
+	}
+}
+
+// WithModelRegistry 注入已知模型注册表，调用时按模型系列判断请求的模型是否满足版本声明的目标模型。
+func WithModelRegistry(registry *llm.Registry) Option {
+	return func(s *Service) {
+		s.models = registry
+	}
+}
+
+// NewService 创建 Pipeline 服务。
+func NewService(repos *domain.Repositories, opts ...Option) *Service {
+	svc := &Service{repos: repos, now: time.Now}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// CreatePipelineInput 定义创建 Pipeline 所需字段。
+type CreatePipelineInput struct {
+	Name        string
+	Description *string
+	Steps       []Step
+	CreatedBy   string
+}
+
+// PipelineDetail 聚合 Pipeline 与其某个版本的步骤定义。
+type PipelineDetail struct {
+	Pipeline *domain.Pipeline        `json:"pipeline"`
+	Version  *domain.PipelineVersion `json:"version"`
+}
+
+// CreatePipeline 创建 Pipeline 并写入第一个版本。
+func (s *Service) CreatePipeline(ctx context.Context, input CreatePipelineInput) (*PipelineDetail, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrNameRequired
+	}
+	stepsJSON, err := s.prepareSteps(ctx, input.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := &domain.Pipeline{
+		ID:          uuid.NewString(),
+		Name:        name,
+		Description: optionalString(input.Description),
+		CreatedBy:   optionalValue(input.CreatedBy),
+	}
+	if err := s.repos.Pipelines.Create(ctx, pipeline); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrPipelineExists
+		}
+		return nil, err
+	}
+
+	return s.createVersion(ctx, pipeline.ID, 1, stepsJSON, input.CreatedBy)
+}
+
+// UpdatePipelineSteps 以新版本的形式保存步骤定义，历史版本保持不变。
+func (s *Service) UpdatePipelineSteps(ctx context.Context, pipelineID string, steps []Step, updatedBy string) (*PipelineDetail, error) {
+	pipeline, err := s.getPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	stepsJSON, err := s.prepareSteps(ctx, steps)
+	if err != nil {
+		return nil, err
+	}
+	return s.createVersion(ctx, pipeline.ID, pipeline.LatestVersion+1, stepsJSON, updatedBy)
+}
+
+// GetPipeline 返回 Pipeline 及指定版本（versionNumber<=0 表示最新版本）。
+func (s *Service) GetPipeline(ctx context.Context, pipelineID string, versionNumber int) (*PipelineDetail, error) {
+	pipeline, err := s.getPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if versionNumber <= 0 {
+		versionNumber = pipeline.LatestVersion
+	}
+	version, err := s.repos.Pipelines.GetVersion(ctx, pipelineID, versionNumber)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return &PipelineDetail{Pipeline: pipeline, Version: version}, nil
+}
+
+// ListPipelines 返回 Pipeline 列表及总数。
+func (s *Service) ListPipelines(ctx context.Context, limit, offset int) ([]*domain.Pipeline, int64, error) {
+	items, err := s.repos.Pipelines.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repos.Pipelines.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// ListPipelineVersions 返回 Pipeline 的全部版本。
+func (s *Service) ListPipelineVersions(ctx context.Context, pipelineID string) ([]*domain.PipelineVersion, error) {
+	if _, err := s.getPipeline(ctx, pipelineID); err != nil {
+		return nil, err
+	}
+	return s.repos.Pipelines.ListVersions(ctx, pipelineID)
+}
+
+// InvokeInput 定义一次 Pipeline 调用。
+type InvokeInput struct {
+	PipelineID string
+	Version    int
+	Inputs     map[string]interface{}
+	UserID     string
+	// Model 为请求的模型，透传给网关；与步骤所用版本声明的目标模型不符时在步骤结果中给出警告。
+	Model string
+}
+
+// StepResult 记录单个步骤的执行结果。
+type StepResult struct {
+	StepID          string `json:"step_id"`
+	PromptID        string `json:"prompt_id"`
+	PromptVersionID string `json:"prompt_version_id"`
+	Status          string `json:"status"`
+	Output          string `json:"output,omitempty"`
+	Error           string `json:"error,omitempty"`
+	ErrorClass      string `json:"error_class,omitempty"`
+	DurationMs      int64  `json:"duration_ms"`
+	ExecutionLogID  string `json:"execution_log_id"`
+	// Warnings 为不阻断执行的提示，如请求的模型不在版本声明的目标模型内。
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// InvokeResult 汇总 Pipeline 调用结果，Output 为最后一个步骤的输出。
+type InvokeResult struct {
+	PipelineID    string       `json:"pipeline_id"`
+	VersionNumber int          `json:"version_number"`
+	Status        string       `json:"status"`
+	Output        string       `json:"output,omitempty"`
+	Steps         []StepResult `json:"steps"`
+}
+
+// Invoke 按拓扑顺序执行各步骤，上游输出映射为下游变量；任一步骤失败即停止，并为每个步骤写入执行日志。
+func (s *Service) Invoke(ctx context.Context, input InvokeInput) (*InvokeResult, error) {
+	if s.gateway == nil {
+		return nil, ErrGatewayUnavailable
+	}
+
+	detail, err := s.GetPipeline(ctx, input.PipelineID, input.Version)
+	if err != nil {
+		return nil, err
+	}
+	var steps []Step
+	if err := json.Unmarshal(detail.Version.Steps, &steps); err != nil {
+		return nil, err
+	}
+	ordered, err := validateSteps(steps)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InvokeResult{
+		PipelineID:    detail.Pipeline.ID,
+		VersionNumber: detail.Version.VersionNumber,
+		Status:        "success",
+		Steps:         make([]StepResult, 0, len(ordered)),
+	}
+	outputs := make(map[string]string, len(ordered))
+
+	for _, step := range ordered {
+		stepResult, err := s.runStep(ctx, detail, step, input, outputs)
+		if stepResult != nil {
+			result.Steps = append(result.Steps, *stepResult)
+		}
+		if err != nil {
+			if stepResult == nil {
+				return nil, err
+			}
+			result.Status = "error"
+			return result, fmt.Errorf("%w: step %q: %v", ErrStepExecutionFailed, step.ID, err)
+		}
+		outputs[step.ID] = stepResult.Output
+		result.Output = stepResult.Output
+	}
+
+	return result, nil
+}
+
+func (s *Service) runStep(ctx context.Context, detail *PipelineDetail, step Step, input InvokeInput, outputs map[string]string) (*StepResult, error) {
+	prompt, err := s.repos.Prompts.GetByID(ctx, step.PromptID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+	if prompt.Status == domain.PromptStatusArchived {
+		return nil, fmt.Errorf("%w: %s", ErrPromptArchived, step.PromptID)
+	}
+	if prompt.ActiveVersionID == nil {
+		return nil, ErrPromptNotActive
+	}
+	version, err := s.repos.PromptVersions.GetByID(ctx, *prompt.ActiveVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make(map[string]interface{}, len(step.Inputs))
+	for variable, source := range step.Inputs {
+		if stepID, ok := parseStepSource(source); ok {
+			variables[variable] = outputs[stepID]
+			continue
+		}
+		variables[variable] = input.Inputs[strings.TrimPrefix(source, inputSourcePrefix)]
+	}
+
+	rendered, err := render.Render(version.Body, variables, render.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: step %q: %v", ErrRenderFailed, step.ID, err)
+	}
+
+	started := s.now()
+	response, callErr := s.gateway.Complete(ctx, CompletionRequest{
+		PromptID:        prompt.ID,
+		PromptVersionID: version.ID,
+		Prompt:          rendered,
+		Variables:       variables,
+		Model:           input.Model,
+	})
+	duration := s.now().Sub(started).Milliseconds()
+
+	stepResult := &StepResult{
+		StepID:          step.ID,
+		PromptID:        prompt.ID,
+		PromptVersionID: version.ID,
+		Status:          "success",
+		DurationMs:      duration,
+		ExecutionLogID:  uuid.NewString(),
+	}
+	if warning := s.models.CompatibilityWarning(input.Model, version.TargetModels); warning != "" {
+		stepResult.Warnings = append(stepResult.Warnings, warning)
+	}
+	responseMetadata := map[string]interface{}{}
+	var errorClass, errorCode *string
+	if callErr != nil {
+		stepResult.Status = "error"
+		stepResult.Error = callErr.Error()
+		responseMetadata["error"] = callErr.Error()
+		class, code := classifyError(callErr)
+		stepResult.ErrorClass = class
+		errorClass = &class
+		errorCode = optionalValue(code)
+	} else {
+		stepResult.Output = response.Output
+		for key, value := range response.Metadata {
+			responseMetadata[key] = value
+		}
+	}
+
+	payload := map[string]interface{}{
+		"pipeline_id":      detail.Pipeline.ID,
+		"pipeline_version": detail.Version.VersionNumber,
+		"step_id":          step.ID,
+		"variables":        variables,
+	}
+	if input.Model != "" {
+		payload["model"] = input.Model
+	}
+	requestPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	metadataPayload, err := json.Marshal(responseMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repos.PromptExecutionLog.Create(ctx, &domain.PromptExecutionLog{
+		ID:               stepResult.ExecutionLogID,
+		PromptID:         prompt.ID,
+		PromptVersionID:  version.ID,
+		UserID:           optionalValue(input.UserID),
+		Status:           stepResult.Status,
+		DurationMs:       duration,
+		RequestPayload:   requestPayload,
+		ResponseMetadata: metadataPayload,
+		ErrorClass:       errorClass,
+		ErrorCode:        errorCode,
+	}); err != nil {
+		return nil, err
+	}
+
+	return stepResult, callErr
+}
+
+func (s *Service) prepareSteps(ctx context.Context, steps []Step) (json.RawMessage, error) {
+	if _, err := validateSteps(steps); err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		if _, err := s.repos.Prompts.GetByID(ctx, step.PromptID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, step.PromptID)
+			}
+			return nil, err
+		}
+	}
+	return json.Marshal(steps)
+}
+
+func (s *Service) createVersion(ctx context.Context, pipelineID string, number int, steps json.RawMessage, createdBy string) (*PipelineDetail, error) {
+	version := &domain.PipelineVersion{
+		ID:            uuid.NewString(),
+		PipelineID:    pipelineID,
+		VersionNumber: number,
+		Steps:         steps,
+		CreatedBy:     optionalValue(createdBy),
+	}
+	if err := s.repos.Pipelines.CreateVersion(ctx, version); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPipelineNotFound
+		}
+		return nil, err
+	}
+	return s.GetPipeline(ctx, pipelineID, number)
+}
+
+func (s *Service) getPipeline(ctx context.Context, pipelineID string) (*domain.Pipeline, error) {
+	pipeline, err := s.repos.Pipelines.GetByID(ctx, pipelineID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPipelineNotFound
+		}
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+func optionalValue(val string) *string {
+	trimmed := strings.TrimSpace(val)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func optionalString(val *string) *string {
+	if val == nil {
+		return nil
+	}
+	return optionalValue(*val)
+}
+
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique") || strings.Contains(msg, "duplicate")
+}
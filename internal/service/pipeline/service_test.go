@@ -0,0 +1,317 @@
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+)
+
+type fakeGateway struct {
+	requests []CompletionRequest
+	failOn   string
+	failWith error
+}
+
+func (g *fakeGateway) Complete(_ context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	g.requests = append(g.requests, req)
+	if g.failOn != "" && strings.Contains(req.Prompt, g.failOn) {
+		if g.failWith != nil {
+			return nil, g.failWith
+		}
+		return nil, errors.New("upstream failure")
+	}
+	return &CompletionResponse{Output: "out(" + req.Prompt + ")", Metadata: map[string]interface{}{"model": "fake"}}, nil
+}
+
+func setupPipelineService(t *testing.T, opts ...Option) (*Service, *promptsvc.Service, *domain.Repositories, func()) {
+	t.Helper()
+	dsn := "file:pipeline_service_test.db?mode=memory&cache=shared&_fk=1"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("glob migrations: %v", err)
+	}
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
+	cleanup := func() { _ = db.Close() }
+	return NewService(repos, opts...), promptsvc.NewService(repos), repos, cleanup
+}
+
+func createActivePrompt(t *testing.T, prompts *promptsvc.Service, name, body string) string {
+	t.Helper()
+	ctx := context.Background()
+	prompt, err := prompts.CreatePrompt(ctx, promptsvc.CreatePromptInput{Name: name})
+	if err != nil {
+		t.Fatalf("create prompt %s: %v", name, err)
+	}
+	if _, err := prompts.CreatePromptVersion(ctx, promptsvc.CreatePromptVersionInput{PromptID: prompt.ID, Body: body, Activate: true}); err != nil {
+		t.Fatalf("create version %s: %v", name, err)
+	}
+	return prompt.ID
+}
+
+func TestInvokePipeline(t *testing.T) {
+	gateway := &fakeGateway{}
+	svc, prompts, repos, cleanup := setupPipelineService(t, WithGateway(gateway))
+	defer cleanup()
+
+	ctx := context.Background()
+	extractID := createActivePrompt(t, prompts, "extract", "Extract facts from {{doc}}")
+	summarizeID := createActivePrompt(t, prompts, "summarize", "Summarize {{facts}}")
+
+	detail, err := svc.CreatePipeline(ctx, CreatePipelineInput{
+		Name: "digest",
+		Steps: []Step{
+			{ID: "summary", PromptID: summarizeID, Inputs: map[string]string{"facts": "steps.facts.output"}},
+			{ID: "facts", PromptID: extractID, Inputs: map[string]string{"doc": "input.document"}},
+		},
+		CreatedBy: "author@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create pipeline: %v", err)
+	}
+	if detail.Version.VersionNumber != 1 || detail.Pipeline.LatestVersion != 1 {
+		t.Fatalf("expected version 1 got %+v", detail)
+	}
+
+	result, err := svc.Invoke(ctx, InvokeInput{PipelineID: detail.Pipeline.ID, Inputs: map[string]interface{}{"document": "report"}})
+	if err != nil {
+		t.Fatalf("invoke: %v", err)
+	}
+	if len(result.Steps) != 2 || result.Steps[0].StepID != "facts" {
+		t.Fatalf("expected facts to run first got %+v", result.Steps)
+	}
+	if result.Output != "out(Summarize out(Extract facts from report))" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+
+	logs, err := repos.PromptExecutionLog.ListRecent(ctx, summarizeID, 10)
+	if err != nil {
+		t.Fatalf("list logs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Status != "success" {
+		t.Fatalf("expected one success log got %+v", logs)
+	}
+
+	// 新版本只影响后续调用，旧版本仍可按版本号执行。
+	updated, err := svc.UpdatePipelineSteps(ctx, detail.Pipeline.ID, []Step{
+		{ID: "facts", PromptID: extractID, Inputs: map[string]string{"doc": "input.document"}},
+	}, "author@example.com")
+	if err != nil {
+		t.Fatalf("update steps: %v", err)
+	}
+	if updated.Version.VersionNumber != 2 {
+		t.Fatalf("expected version 2 got %d", updated.Version.VersionNumber)
+	}
+
+	gateway.failOn = "Summarize"
+	failed, err := svc.Invoke(ctx, InvokeInput{PipelineID: detail.Pipeline.ID, Version: 1, Inputs: map[string]interface{}{"document": "x"}})
+	if !errors.Is(err, ErrStepExecutionFailed) {
+		t.Fatalf("expected ErrStepExecutionFailed got %v", err)
+	}
+	if failed == nil || failed.Status != "error" || failed.Steps[1].Status != "error" {
+		t.Fatalf("expected failed step result got %+v", failed)
+	}
+}
+
+func TestInvokePipelineModelWarning(t *testing.T) {
+	gateway := &fakeGateway{}
+	models := llm.NewRegistry([]llm.Model{{ID: "gpt-4o-mini", Family: "gpt-4o"}, {ID: "claude-3-5-sonnet", Family: "claude-3-5"}})
+	svc, prompts, _, cleanup := setupPipelineService(t, WithGateway(gateway), WithModelRegistry(models))
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := prompts.CreatePrompt(ctx, promptsvc.CreatePromptInput{Name: "targeted"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if _, err := prompts.CreatePromptVersion(ctx, promptsvc.CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "Answer {{question}}",
+		Metadata: map[string]interface{}{"target_models": []string{"gpt-4o"}},
+		Activate: true,
+	}); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	detail, err := svc.CreatePipeline(ctx, CreatePipelineInput{
+		Name:  "answer",
+		Steps: []Step{{ID: "answer", PromptID: prompt.ID, Inputs: map[string]string{"question": "input.question"}}},
+	})
+	if err != nil {
+		t.Fatalf("create pipeline: %v", err)
+	}
+
+	// 系列内的模型兼容，请求的模型透传给网关。
+	result, err := svc.Invoke(ctx, InvokeInput{PipelineID: detail.Pipeline.ID, Inputs: map[string]interface{}{"question": "why"}, Model: "GPT-4o-mini"})
+	if err != nil {
+		t.Fatalf("invoke: %v", err)
+	}
+	if len(result.Steps[0].Warnings) != 0 || gateway.requests[0].Model != "GPT-4o-mini" {
+		t.Fatalf("expected compatible invocation got %+v %+v", result.Steps[0], gateway.requests[0])
+	}
+
+	// 不在声明目标内的模型只警告，不阻断执行。
+	result, err = svc.Invoke(ctx, InvokeInput{PipelineID: detail.Pipeline.ID, Inputs: map[string]interface{}{"question": "why"}, Model: "claude-3-5-sonnet"})
+	if err != nil {
+		t.Fatalf("invoke with mismatched model: %v", err)
+	}
+	if warnings := result.Steps[0].Warnings; len(warnings) != 1 || !strings.Contains(warnings[0], "claude-3-5-sonnet") {
+		t.Fatalf("expected model warning got %v", warnings)
+	}
+}
+
+func TestInvokePipelineErrorClasses(t *testing.T) {
+	gateway := &fakeGateway{failOn: "Classify"}
+	svc, prompts, repos, cleanup := setupPipelineService(t, WithGateway(gateway))
+	defer cleanup()
+
+	ctx := context.Background()
+	promptID := createActivePrompt(t, prompts, "classify", "Classify {{text}}")
+	detail, err := svc.CreatePipeline(ctx, CreatePipelineInput{
+		Name:  "classifier",
+		Steps: []Step{{ID: "classify", PromptID: promptID, Inputs: map[string]string{"text": "input.text"}}},
+	})
+	if err != nil {
+		t.Fatalf("create pipeline: %v", err)
+	}
+
+	failures := []error{
+		&GatewayError{Class: domain.ExecutionErrorGuardrailBlock, Code: "content_filter", Err: errors.New("blocked")},
+		fmt.Errorf("call provider: %w", context.DeadlineExceeded),
+		errors.New("upstream failure"),
+		errors.New("upstream failure"),
+	}
+	for _, failure := range failures {
+		gateway.failWith = failure
+		if _, err := svc.Invoke(ctx, InvokeInput{PipelineID: detail.Pipeline.ID, Inputs: map[string]interface{}{"text": "x"}}); !errors.Is(err, ErrStepExecutionFailed) {
+			t.Fatalf("expected ErrStepExecutionFailed got %v", err)
+		}
+	}
+	gateway.failOn = ""
+	result, err := svc.Invoke(ctx, InvokeInput{PipelineID: detail.Pipeline.ID, Inputs: map[string]interface{}{"text": "x"}})
+	if err != nil || result.Steps[0].ErrorClass != "" {
+		t.Fatalf("expected success without class got %+v err=%v", result, err)
+	}
+
+	logs, err := repos.PromptExecutionLog.ListRecent(ctx, promptID, 10)
+	if err != nil {
+		t.Fatalf("list logs: %v", err)
+	}
+	var guardrail *domain.PromptExecutionLog
+	for _, log := range logs {
+		if log.ErrorClass != nil && *log.ErrorClass == domain.ExecutionErrorGuardrailBlock {
+			guardrail = log
+		}
+	}
+	if guardrail == nil || guardrail.ErrorCode == nil || *guardrail.ErrorCode != "content_filter" {
+		t.Fatalf("expected guardrail log with code got %+v", logs)
+	}
+
+	stats, err := repos.PromptExecutionLog.AggregateUsage(ctx, promptID, domain.ExecutionAggregateOptions{From: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalCalls != 5 {
+		t.Fatalf("expected one bucket with 5 calls got %+v", stats)
+	}
+	expected := map[string]int{
+		domain.ExecutionErrorGuardrailBlock: 1,
+		domain.ExecutionErrorTimeout:        1,
+		domain.ExecutionErrorProvider:       2,
+	}
+	if !reflect.DeepEqual(stats[0].ErrorClasses, expected) {
+		t.Fatalf("unexpected error classes %+v", stats[0].ErrorClasses)
+	}
+}
+
+func TestCreatePipelineValidation(t *testing.T) {
+	svc, prompts, _, cleanup := setupPipelineService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	promptID := createActivePrompt(t, prompts, "single", "Hello")
+
+	_, err := svc.CreatePipeline(ctx, CreatePipelineInput{
+		Name: "cyclic",
+		Steps: []Step{
+			{ID: "a", PromptID: promptID, DependsOn: []string{"b"}},
+			{ID: "b", PromptID: promptID, Inputs: map[string]string{"x": "steps.a.output"}},
+		},
+	})
+	if !errors.Is(err, ErrPipelineCycle) {
+		t.Fatalf("expected ErrPipelineCycle got %v", err)
+	}
+
+	_, err = svc.CreatePipeline(ctx, CreatePipelineInput{
+		Name:  "unknown",
+		Steps: []Step{{ID: "a", PromptID: promptID, DependsOn: []string{"missing"}}},
+	})
+	if !errors.Is(err, ErrInvalidStep) {
+		t.Fatalf("expected ErrInvalidStep got %v", err)
+	}
+
+	detail, err := svc.CreatePipeline(ctx, CreatePipelineInput{Name: "ok", Steps: []Step{{ID: "a", PromptID: promptID}}})
+	if err != nil {
+		t.Fatalf("create pipeline: %v", err)
+	}
+	if _, err := svc.Invoke(ctx, InvokeInput{PipelineID: detail.Pipeline.ID}); !errors.Is(err, ErrGatewayUnavailable) {
+		t.Fatalf("expected ErrGatewayUnavailable got %v", err)
+	}
+}
+
+func TestPipelineWorkspaceIsolation(t *testing.T) {
+	svc, prompts, _, cleanup := setupPipelineService(t, WithGateway(&fakeGateway{}))
+	defer cleanup()
+
+	ctx := context.Background()
+	promptID := createActivePrompt(t, prompts, "shared", "Hello")
+	teamCtx := domain.WithWorkspace(ctx, "team-a")
+
+	// 其他工作区的 Prompt 不能作为步骤引用。
+	if _, err := svc.CreatePipeline(teamCtx, CreatePipelineInput{Name: "borrowed", Steps: []Step{{ID: "a", PromptID: promptID}}}); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected ErrPromptNotFound for foreign prompt got %v", err)
+	}
+
+	detail, err := svc.CreatePipeline(domain.WithWorkspace(ctx, domain.DefaultWorkspaceID), CreatePipelineInput{Name: "default-only", Steps: []Step{{ID: "a", PromptID: promptID}}})
+	if err != nil {
+		t.Fatalf("create pipeline: %v", err)
+	}
+	if detail.Pipeline.WorkspaceID != domain.DefaultWorkspaceID {
+		t.Fatalf("expected pipeline in default workspace got %q", detail.Pipeline.WorkspaceID)
+	}
+	if _, err := svc.GetPipeline(teamCtx, detail.Pipeline.ID, 0); !errors.Is(err, ErrPipelineNotFound) {
+		t.Fatalf("expected ErrPipelineNotFound from another workspace got %v", err)
+	}
+	if _, err := svc.Invoke(teamCtx, InvokeInput{PipelineID: detail.Pipeline.ID}); !errors.Is(err, ErrPipelineNotFound) {
+		t.Fatalf("expected invoke from another workspace to fail got %v", err)
+	}
+	if items, total, err := svc.ListPipelines(teamCtx, 10, 0); err != nil || total != 0 || len(items) != 0 {
+		t.Fatalf("expected no pipelines in team workspace got %d (%v)", total, err)
+	}
+}
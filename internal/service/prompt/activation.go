@@ -0,0 +1,353 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+)
+
+const (
+	// auditActionVersionActivated 为激活版本写入的审计动作，同时作为激活历史的数据来源。
+	auditActionVersionActivated = "prompt.version.activated"
+	// auditActionVersionRolledBack 为一键回滚写入的审计动作，记录回滚前后的版本。
+	auditActionVersionRolledBack = "prompt.version.rolled_back"
+	// maxReleaseNoteLength 为发布说明的最大字符数。
+	maxReleaseNoteLength = 2000
+)
+
+// ActivateVersionInput 定义激活版本所需参数，ReleaseNote 为可选的发布说明。
+type ActivateVersionInput struct {
+	PromptID    string
+	VersionID   string
+	ActivatedBy string
+	ReleaseNote string
+}
+
+// ActivationEvent 描述一次版本激活，作为激活历史条目与通知/webhook 的负载。
+type ActivationEvent struct {
+	PromptID          string              `json:"prompt_id"`
+	PromptName        string              `json:"prompt_name"`
+	VersionID         string              `json:"version_id"`
+	VersionNumber     int                 `json:"version_number"`
+	PreviousVersionID *string             `json:"previous_version_id,omitempty"`
+	ReleaseNote       string              `json:"release_note,omitempty"`
+	ActivatedBy       *string             `json:"activated_by,omitempty"`
+	Owner             *domain.PromptOwner `json:"owner,omitempty"`
+	OccurredAt        time.Time           `json:"occurred_at"`
+}
+
+// ActivationResult 为激活结果；NotifyErr 记录通知投递失败，激活本身已生效。
+type ActivationResult struct {
+	Event     ActivationEvent
+	NotifyErr error
+}
+
+// ActivationNotifier 在版本激活后发送通知（如 IM、邮件）。
+type ActivationNotifier interface {
+	NotifyActivation(ctx context.Context, event ActivationEvent) error
+}
+
+// WithActivationNotifier 注入版本激活通知渠道。
+func WithActivationNotifier(notifier ActivationNotifier) Option {
+	return func(s *Service) {
+		s.activationNotifier = notifier
+	}
+}
+
+// WithActivationWebhook 配置版本激活后推送事件的 webhook 地址，为空时不推送。
+func WithActivationWebhook(target string) Option {
+	return func(s *Service) {
+		s.activationWebhook = strings.TrimSpace(target)
+	}
+}
+
+// WithRequireReleaseNote 要求通过 ActivateVersion 激活版本时必须填写发布说明。
+func WithRequireReleaseNote(required bool) Option {
+	return func(s *Service) {
+		s.requireReleaseNote = required
+	}
+}
+
+// WithRequirePublished 要求版本处于 published 状态（配置了评审策略时还需获得足够批准）才能激活。
+func WithRequirePublished(required bool) Option {
+	return func(s *Service) {
+		s.requirePublished = required
+	}
+}
+
+// VersionNotPublishableError 表示版本未满足激活前置条件：状态不是 published，或评审未通过时 Review 非空。
+type VersionNotPublishableError struct {
+	Status string
+	Review *VersionReview
+}
+
+func (e *VersionNotPublishableError) Error() string {
+	if e.Review != nil {
+		return fmt.Sprintf("version in status %s with %d/%d approvals cannot be activated; it must be published and approved",
+			e.Status, e.Review.ValidApprovals, e.Review.RequiredApprovals)
+	}
+	return fmt.Sprintf("version in status %s cannot be activated; it must be published", e.Status)
+}
+
+// Unwrap 使 errors.Is(err, ErrVersionNotPublishable) 成立。
+func (e *VersionNotPublishableError) Unwrap() error {
+	return ErrVersionNotPublishable
+}
+
+// ActivateVersion 激活指定版本并记录发布说明，随后推送激活通知。
+// 通知失败不回滚激活，错误通过 ActivationResult.NotifyErr 返回。
+func (s *Service) ActivateVersion(ctx context.Context, input ActivateVersionInput) (*ActivationResult, error) {
+	note := strings.TrimSpace(input.ReleaseNote)
+	if note == "" && s.requireReleaseNote {
+		return nil, ErrReleaseNoteRequired
+	}
+	if utf8.RuneCountInString(note) > maxReleaseNoteLength {
+		return nil, ErrInvalidReleaseNote
+	}
+
+	event, err := s.activateVersion(ctx, input.PromptID, input.VersionID, input.ActivatedBy, note)
+	if err != nil {
+		return nil, err
+	}
+	return &ActivationResult{Event: *event, NotifyErr: s.notifyActivation(ctx, *event)}, nil
+}
+
+// ActivatePreviousVersion 将 Prompt 回滚到上一次切换前的激活版本，ReleaseNote 可选（不受 requireReleaseNote 约束）。
+// 回滚本身也是一次激活，再次调用会切回回滚前的版本；除激活审计外额外写入记录回滚前后版本的审计。
+func (s *Service) ActivatePreviousVersion(ctx context.Context, input ActivateVersionInput) (*ActivationResult, error) {
+	note := strings.TrimSpace(input.ReleaseNote)
+	if utf8.RuneCountInString(note) > maxReleaseNoteLength {
+		return nil, ErrInvalidReleaseNote
+	}
+	prompt, err := s.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.PreviousActiveVersionID == nil {
+		return nil, ErrNoPreviousVersion
+	}
+
+	event, err := s.activateVersion(ctx, prompt.ID, *prompt.PreviousActiveVersionID, input.ActivatedBy, note)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.repos.PromptAuditLog != nil {
+		payloadData := map[string]interface{}{
+			"to_version_id":     event.VersionID,
+			"to_version_number": event.VersionNumber,
+		}
+		if event.PreviousVersionID != nil {
+			payloadData["from_version_id"] = *event.PreviousVersionID
+			if from, err := s.repos.PromptVersions.GetByID(ctx, *event.PreviousVersionID); err == nil {
+				payloadData["from_version_number"] = from.VersionNumber
+			}
+		}
+		payload, err := json.Marshal(payloadData)
+		if err != nil {
+			return nil, err
+		}
+		audit := &domain.PromptAuditLog{
+			ID:        uuid.NewString(),
+			PromptID:  prompt.ID,
+			Action:    auditActionVersionRolledBack,
+			Payload:   payload,
+			CreatedBy: event.ActivatedBy,
+		}
+		if err := s.repos.PromptAuditLog.Create(ctx, audit); err != nil {
+			return nil, err
+		}
+	}
+	return &ActivationResult{Event: *event, NotifyErr: s.notifyActivation(ctx, *event)}, nil
+}
+
+// ListActivations 从审计日志重建 Prompt 的激活历史（含发布说明），按时间倒序返回。
+func (s *Service) ListActivations(ctx context.Context, promptID string) ([]ActivationEvent, error) {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ActivationEvent
+	err = s.IterateAuditLogs(ctx, domain.AuditLogIterateOptions{PromptID: promptID}, func(log *domain.PromptAuditLog) error {
+		if log.Action != auditActionVersionActivated {
+			return nil
+		}
+		var payload struct {
+			VersionID         string  `json:"version_id"`
+			VersionNumber     int     `json:"version_number"`
+			PreviousVersionID *string `json:"previous_version_id"`
+			ReleaseNote       string  `json:"release_note"`
+		}
+		if len(log.Payload) > 0 {
+			if err := json.Unmarshal(log.Payload, &payload); err != nil {
+				return err
+			}
+		}
+		events = append(events, ActivationEvent{
+			PromptID:          promptID,
+			PromptName:        prompt.Name,
+			VersionID:         payload.VersionID,
+			VersionNumber:     payload.VersionNumber,
+			PreviousVersionID: payload.PreviousVersionID,
+			ReleaseNote:       payload.ReleaseNote,
+			ActivatedBy:       log.CreatedBy,
+			Owner:             prompt.Owner,
+			OccurredAt:        log.CreatedAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// activateVersion 更新激活版本与正文快照、同步 include 依赖并写入激活审计。
+func (s *Service) activateVersion(ctx context.Context, promptID, versionID, activatedBy, releaseNote string) (*ActivationEvent, error) {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.repos.PromptVersions.GetByID(ctx, versionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	if version.Status == domain.PromptVersionStatusArchived {
+		return nil, ErrVersionArchived
+	}
+	if err := s.checkActivationPolicy(ctx, promptID, version); err != nil {
+		return nil, err
+	}
+	if err := s.checkChangeFreeze(ctx, prompt, FreezeOperationActivate, activatedBy); err != nil {
+		return nil, err
+	}
+
+	body := version.Body
+	if err := s.repos.Prompts.UpdateActiveVersion(ctx, promptID, &versionID, &body); err != nil {
+		return nil, err
+	}
+	if err := s.syncIncludeDependencies(ctx, promptID, body, activatedBy); err != nil {
+		return nil, err
+	}
+	if err := s.settleCanaryOnActivation(ctx, promptID, version.ID, activatedBy); err != nil {
+		return nil, err
+	}
+
+	event := &ActivationEvent{
+		PromptID:          promptID,
+		PromptName:        prompt.Name,
+		VersionID:         version.ID,
+		VersionNumber:     version.VersionNumber,
+		PreviousVersionID: prompt.ActiveVersionID,
+		ReleaseNote:       releaseNote,
+		ActivatedBy:       optionalString(activatedBy),
+		Owner:             prompt.Owner,
+		OccurredAt:        time.Now().UTC(),
+	}
+
+	if s.repos.PromptAuditLog != nil {
+		payloadData := map[string]interface{}{
+			"version_id":     version.ID,
+			"version_number": version.VersionNumber,
+		}
+		if prompt.ActiveVersionID != nil {
+			payloadData["previous_version_id"] = *prompt.ActiveVersionID
+		}
+		if releaseNote != "" {
+			payloadData["release_note"] = releaseNote
+		}
+		payload, err := json.Marshal(payloadData)
+		if err != nil {
+			return nil, err
+		}
+		audit := &domain.PromptAuditLog{
+			ID:        uuid.NewString(),
+			PromptID:  promptID,
+			Action:    auditActionVersionActivated,
+			Payload:   payload,
+			CreatedBy: event.ActivatedBy,
+		}
+		if err := s.repos.PromptAuditLog.Create(ctx, audit); err != nil {
+			return nil, err
+		}
+	}
+
+	s.publishEvent(ctx, events.VersionActivated, prompt)
+	return event, nil
+}
+
+// checkActivationPolicy 校验激活前置条件。未启用 requirePublished 时仅校验评审策略（返回 *ReviewRequiredError），
+// 启用后状态与评审任一不满足都返回 *VersionNotPublishableError。
+func (s *Service) checkActivationPolicy(ctx context.Context, promptID string, version *domain.PromptVersion) error {
+	err := s.checkReviewRequirement(ctx, promptID, version.ID)
+	if !s.requirePublished {
+		return err
+	}
+	var reviewRequired *ReviewRequiredError
+	if err != nil && !errors.As(err, &reviewRequired) {
+		return err
+	}
+	if version.Status == domain.PromptVersionStatusPublished && reviewRequired == nil {
+		return nil
+	}
+	notPublishable := &VersionNotPublishableError{Status: version.Status}
+	if reviewRequired != nil {
+		notPublishable.Review = reviewRequired.Review
+	}
+	return notPublishable
+}
+
+func (s *Service) notifyActivation(ctx context.Context, event ActivationEvent) error {
+	var errs []error
+	if s.activationNotifier != nil {
+		if err := s.activationNotifier.NotifyActivation(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.activationWebhook != "" {
+		if err := s.postActivationWebhook(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) postActivationWebhook(ctx context.Context, event ActivationEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.activationWebhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("activation webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
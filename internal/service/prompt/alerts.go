@@ -0,0 +1,361 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// maxAlertWindowMinutes 限制评估窗口，避免调度器每轮扫描过多执行日志。
+const maxAlertWindowMinutes = 24 * 60
+
+// maxSecretOverlap 限制轮换签名密钥时旧密钥的保留时长。
+const maxSecretOverlap = 7 * 24 * time.Hour
+
+// AlertSignatureHeader 携带告警 webhook 请求体的 HMAC-SHA256 签名（sha256=<hex>）。轮换重叠期内附带新旧两个签名，
+// 以逗号分隔且新密钥在前，接收方匹配任一即可。
+const AlertSignatureHeader = "X-Prompt-Manager-Signature"
+
+// CreateAlertRuleInput 定义创建告警规则所需的字段。
+type CreateAlertRuleInput struct {
+	PromptID string
+	Name     string
+	// Metric 为 error_rate（Threshold 为百分比）或 p95_latency（Threshold 为毫秒）。
+	Metric        string
+	Threshold     float64
+	WindowMinutes int
+	WebhookURL    string
+	CreatedBy     string
+}
+
+// AlertEvent 描述一次告警状态切换，作为通知与 webhook 的负载。
+type AlertEvent struct {
+	RuleID     string    `json:"rule_id"`
+	RuleName   string    `json:"rule_name"`
+	PromptID   string    `json:"prompt_id"`
+	Metric     string    `json:"metric"`
+	Threshold  float64   `json:"threshold"`
+	Window     int       `json:"window_minutes"`
+	State      string    `json:"state"`
+	Value      *float64  `json:"value,omitempty"`
+	TotalCalls int       `json:"total_calls"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Test 标记由测试接口发送的示例事件，接收方应忽略。
+	Test bool `json:"test,omitempty"`
+}
+
+// AlertNotifier 在告警触发或恢复时发送额外通知（如 IM、邮件）；规则上配置的 webhook 由服务直接推送。
+type AlertNotifier interface {
+	NotifyAlert(ctx context.Context, event AlertEvent) error
+}
+
+// WithAlertNotifier 注入告警通知渠道。
+func WithAlertNotifier(notifier AlertNotifier) Option {
+	return func(s *Service) {
+		s.alertNotifier = notifier
+	}
+}
+
+// WithHTTPClient 替换推送告警 webhook 所用的 HTTP 客户端。
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Service) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// CreateAlertRule 为 Prompt 创建告警规则，初始状态为 ok，由调度器周期评估。
+func (s *Service) CreateAlertRule(ctx context.Context, input CreateAlertRuleInput) (*domain.PromptAlertRule, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || input.Threshold <= 0 || input.WindowMinutes <= 0 || input.WindowMinutes > maxAlertWindowMinutes {
+		return nil, ErrInvalidAlertRule
+	}
+	switch input.Metric {
+	case domain.AlertMetricErrorRate:
+		if input.Threshold > 100 {
+			return nil, ErrInvalidAlertRule
+		}
+	case domain.AlertMetricP95Latency:
+	default:
+		return nil, ErrInvalidAlertRule
+	}
+	webhookURL := strings.TrimSpace(input.WebhookURL)
+	if webhookURL != "" {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, ErrInvalidAlertRule
+		}
+	}
+	if _, err := s.GetPrompt(ctx, input.PromptID); err != nil {
+		return nil, err
+	}
+	var secret string
+	if webhookURL != "" {
+		var err error
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	rule := &domain.PromptAlertRule{
+		ID:            uuid.NewString(),
+		PromptID:      input.PromptID,
+		Name:          name,
+		Metric:        input.Metric,
+		Threshold:     input.Threshold,
+		WindowMinutes: input.WindowMinutes,
+		WebhookURL:    optionalString(webhookURL),
+		WebhookSecret: secret,
+		Enabled:       true,
+		State:         domain.AlertStateOK,
+		CreatedBy:     optionalString(input.CreatedBy),
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := s.repos.PromptAlertRules.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ListAlertRules 返回 Prompt 的告警规则及其当前状态。
+func (s *Service) ListAlertRules(ctx context.Context, promptID string) ([]*domain.PromptAlertRule, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	return s.repos.PromptAlertRules.ListByPrompt(ctx, promptID)
+}
+
+// DeleteAlertRule 删除 Prompt 下的告警规则。
+func (s *Service) DeleteAlertRule(ctx context.Context, promptID, ruleID string) error {
+	if _, err := s.getAlertRule(ctx, promptID, ruleID); err != nil {
+		return err
+	}
+	return s.repos.PromptAlertRules.Delete(ctx, ruleID)
+}
+
+// RotateAlertWebhookSecret 为规则的 webhook 生成新的签名密钥；overlap 大于 0 时旧密钥在此期间继续参与签名，
+// 为 0 时立即失效。返回的规则携带新密钥明文。
+func (s *Service) RotateAlertWebhookSecret(ctx context.Context, promptID, ruleID string, overlap time.Duration) (*domain.PromptAlertRule, error) {
+	if overlap < 0 || overlap > maxSecretOverlap {
+		return nil, ErrInvalidAlertRule
+	}
+	rule, err := s.getAlertRule(ctx, promptID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.WebhookURL == nil {
+		return nil, ErrAlertWebhookMissing
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// 规则在签名功能上线前创建时没有旧密钥，无需保留。
+	var previous *string
+	var expiresAt *time.Time
+	if overlap > 0 && rule.WebhookSecret != "" {
+		at := time.Now().UTC().Add(overlap)
+		previous, expiresAt = &rule.WebhookSecret, &at
+	}
+	if err := s.repos.PromptAlertRules.UpdateWebhookSecret(ctx, rule.ID, secret, previous, expiresAt); err != nil {
+		return nil, err
+	}
+	rule.WebhookSecret = secret
+	rule.PreviousWebhookSecret = previous
+	rule.PreviousSecretExpiresAt = expiresAt
+	return rule, nil
+}
+
+// TestAlertWebhook 向规则的 webhook 发送一条签名的示例事件（test 为 true），便于接入方在真实告警前验证接收端。
+func (s *Service) TestAlertWebhook(ctx context.Context, promptID, ruleID string) error {
+	rule, err := s.getAlertRule(ctx, promptID, ruleID)
+	if err != nil {
+		return err
+	}
+	if rule.WebhookURL == nil {
+		return ErrAlertWebhookMissing
+	}
+	now := time.Now().UTC()
+	value := rule.Threshold
+	event := AlertEvent{
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		PromptID:   rule.PromptID,
+		Metric:     rule.Metric,
+		Threshold:  rule.Threshold,
+		Window:     rule.WindowMinutes,
+		State:      domain.AlertStateFiring,
+		Value:      &value,
+		OccurredAt: now,
+		Test:       true,
+	}
+	if err := s.postAlertWebhook(ctx, *rule.WebhookURL, alertWebhookSecrets(rule, now), event); err != nil {
+		return fmt.Errorf("%w: %v", ErrAlertWebhookFailed, err)
+	}
+	return nil
+}
+
+// getAlertRule 读取 Prompt 下的告警规则，Prompt 需在当前工作区可见，规则不属于该 Prompt 时同样视为不存在。
+func (s *Service) getAlertRule(ctx context.Context, promptID, ruleID string) (*domain.PromptAlertRule, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	rule, err := s.repos.PromptAlertRules.GetByID(ctx, ruleID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrAlertRuleNotFound
+		}
+		return nil, err
+	}
+	if rule.PromptID != promptID {
+		return nil, ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
+
+// EvaluateAlerts 评估全部启用的规则，状态切换时推送通知；单条规则失败不影响其余规则。
+func (s *Service) EvaluateAlerts(ctx context.Context) error {
+	rules, err := s.repos.PromptAlertRules.ListEnabled(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	var errs []error
+	for _, rule := range rules {
+		if err := s.evaluateAlertRule(ctx, rule, now); err != nil {
+			errs = append(errs, fmt.Errorf("alert rule %s: %w", rule.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) evaluateAlertRule(ctx context.Context, rule *domain.PromptAlertRule, now time.Time) error {
+	window, err := s.repos.PromptExecutionLog.SummarizeWindow(ctx, rule.PromptID, now.Add(-time.Duration(rule.WindowMinutes)*time.Minute))
+	if err != nil {
+		return err
+	}
+
+	// 窗口内没有调用时视为恢复，避免流量归零后告警一直挂起。
+	state := domain.AlertStateOK
+	var value *float64
+	if window.TotalCalls > 0 {
+		current := alertMetricValue(rule.Metric, window)
+		value = &current
+		if current > rule.Threshold {
+			state = domain.AlertStateFiring
+		}
+	}
+
+	var changedAt *time.Time
+	if state != rule.State {
+		changedAt = &now
+	}
+	if err := s.repos.PromptAlertRules.UpdateState(ctx, rule.ID, state, value, now, changedAt); err != nil {
+		return err
+	}
+	if changedAt == nil {
+		return nil
+	}
+
+	return s.notifyAlert(ctx, rule.WebhookURL, alertWebhookSecrets(rule, now), AlertEvent{
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		PromptID:   rule.PromptID,
+		Metric:     rule.Metric,
+		Threshold:  rule.Threshold,
+		Window:     rule.WindowMinutes,
+		State:      state,
+		Value:      value,
+		TotalCalls: window.TotalCalls,
+		OccurredAt: now,
+	})
+}
+
+func alertMetricValue(metric string, window *domain.PromptExecutionWindow) float64 {
+	if metric == domain.AlertMetricP95Latency {
+		return window.P95Millis
+	}
+	return float64(window.FailedCalls) / float64(window.TotalCalls) * 100
+}
+
+func (s *Service) notifyAlert(ctx context.Context, webhookURL *string, secrets []string, event AlertEvent) error {
+	var errs []error
+	if s.alertNotifier != nil {
+		if err := s.alertNotifier.NotifyAlert(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if webhookURL != nil {
+		if err := s.postAlertWebhook(ctx, *webhookURL, secrets, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postAlertWebhook 推送事件，secrets 非空时逐个签名并写入 AlertSignatureHeader。
+func (s *Service) postAlertWebhook(ctx context.Context, target string, secrets []string, event AlertEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(secrets) > 0 {
+		signatures := make([]string, 0, len(secrets))
+		for _, secret := range secrets {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(payload)
+			signatures = append(signatures, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+		req.Header.Set(AlertSignatureHeader, strings.Join(signatures, ", "))
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("alert webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// alertWebhookSecrets 返回 now 时刻参与签名的密钥：当前密钥在前，未过期的旧密钥在后。
+func alertWebhookSecrets(rule *domain.PromptAlertRule, now time.Time) []string {
+	var secrets []string
+	if rule.WebhookSecret != "" {
+		secrets = append(secrets, rule.WebhookSecret)
+	}
+	if rule.PreviousWebhookSecret != nil && rule.PreviousSecretExpiresAt != nil && now.Before(*rule.PreviousSecretExpiresAt) {
+		secrets = append(secrets, *rule.PreviousWebhookSecret)
+	}
+	return secrets
+}
+
+// newWebhookSecret 生成 webhook 签名密钥。
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,369 @@
+package prompt
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// archiveMaxEntries 限制单个压缩包的条目数，避免超大目录拖垮一次请求。
+	archiveMaxEntries = 5000
+	// archiveMaxEntryBytes 限制单个文件解压后的大小，防御压缩炸弹。
+	archiveMaxEntryBytes = 1 << 20
+
+	ImportConflictSkip   = "skip"
+	ImportConflictAppend = "append"
+
+	ImportItemCreated = "created"
+	ImportItemUpdated = "updated"
+	ImportItemSkipped = "skipped"
+	ImportItemFailed  = "failed"
+)
+
+// PromptDocument 为导入导出使用的单个 Prompt 文件格式（YAML 或 JSON），
+// body 是只有一个版本时的简写，与 versions 二选一。
+type PromptDocument struct {
+	Name        string                  `json:"name" yaml:"name"`
+	Description *string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string                `json:"tags,omitempty" yaml:"tags,omitempty"`
+	RenderMode  *string                 `json:"render_mode,omitempty" yaml:"render_mode,omitempty"`
+	Body        string                  `json:"body,omitempty" yaml:"body,omitempty"`
+	Versions    []PromptDocumentVersion `json:"versions,omitempty" yaml:"versions,omitempty"`
+}
+
+// PromptDocumentVersion 描述文件中的一个版本，active 标记导入后启用的版本。
+type PromptDocumentVersion struct {
+	Body            string      `json:"body" yaml:"body"`
+	VariablesSchema interface{} `json:"variables_schema,omitempty" yaml:"variables_schema,omitempty"`
+	Metadata        interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Status          string      `json:"status,omitempty" yaml:"status,omitempty"`
+	Active          bool        `json:"active,omitempty" yaml:"active,omitempty"`
+}
+
+// ImportOptions 控制批量导入（压缩包或 CSV）行为。
+type ImportOptions struct {
+	// OnConflict 为 skip（默认）时跳过同名 Prompt，为 append 时把文件中的版本追加到已有 Prompt。
+	OnConflict string
+	// DryRun 仅解析与校验，不写入数据库。
+	DryRun    bool
+	CreatedBy string
+}
+
+func (o *ImportOptions) normalize() error {
+	switch o.OnConflict {
+	case "":
+		o.OnConflict = ImportConflictSkip
+	case ImportConflictSkip, ImportConflictAppend:
+	default:
+		return errors.New("on_conflict must be skip or append")
+	}
+	return nil
+}
+
+// ImportItem 为单个文件（压缩包）或单行（CSV，Row 从 2 开始计入表头）的导入结果。
+type ImportItem struct {
+	File     string `json:"file,omitempty"`
+	Row      int    `json:"row,omitempty"`
+	Prompt   string `json:"prompt,omitempty"`
+	PromptID string `json:"prompt_id,omitempty"`
+	Status   string `json:"status"`
+	Versions int    `json:"versions"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ImportReport 汇总一次批量导入的结果。
+type ImportReport struct {
+	DryRun  bool          `json:"dry_run"`
+	Total   int           `json:"total"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Failed  int           `json:"failed"`
+	Items   []*ImportItem `json:"items"`
+}
+
+func (r *ImportReport) add(item *ImportItem) {
+	r.Total++
+	switch item.Status {
+	case ImportItemCreated:
+		r.Created++
+	case ImportItemUpdated:
+		r.Updated++
+	case ImportItemSkipped:
+		r.Skipped++
+	case ImportItemFailed:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
+
+// ImportArchive 逐个读取 zip 中的 .yaml/.yml/.json 文件并导入 Prompt。单个文件失败只记入报告，
+// 不影响其他文件；压缩包本身无法解析时返回 ErrInvalidArchive。
+func (s *Service) ImportArchive(ctx context.Context, r io.ReaderAt, size int64, opts ImportOptions) (*ImportReport, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if len(reader.File) > archiveMaxEntries {
+		return nil, fmt.Errorf("%w: archive contains more than %d entries", ErrInvalidArchive, archiveMaxEntries)
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Items: []*ImportItem{}}
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !isArchivePromptFile(file.Name) {
+			continue
+		}
+		report.add(s.importArchiveEntry(ctx, file, opts))
+	}
+	return report, nil
+}
+
+// ImportFile 为已读取内容的单个 Prompt 文件，Name 的扩展名决定解析格式。
+type ImportFile struct {
+	Name    string
+	Content []byte
+}
+
+// ImportFiles 按压缩包导入的规则导入一组已读取的文件（如 Git 仓库中改动的文件），非 Prompt 文件被忽略。
+func (s *Service) ImportFiles(ctx context.Context, files []ImportFile, opts ImportOptions) (*ImportReport, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Items: []*ImportItem{}}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !isArchivePromptFile(file.Name) {
+			continue
+		}
+		item := &ImportItem{File: file.Name}
+		doc, err := parseImportFile(file)
+		if err != nil {
+			item.Status = ImportItemFailed
+			item.Error = err.Error()
+			report.add(item)
+			continue
+		}
+		report.add(s.importDocument(ctx, item, doc, opts))
+	}
+	return report, nil
+}
+
+func parseImportFile(file ImportFile) (*PromptDocument, error) {
+	if len(file.Content) > archiveMaxEntryBytes {
+		return nil, fmt.Errorf("file exceeds %d bytes", archiveMaxEntryBytes)
+	}
+	return parsePromptDocument(file.Name, file.Content)
+}
+
+func (s *Service) importArchiveEntry(ctx context.Context, file *zip.File, opts ImportOptions) *ImportItem {
+	item := &ImportItem{File: file.Name}
+	doc, err := readPromptDocument(file)
+	if err != nil {
+		item.Status = ImportItemFailed
+		item.Error = err.Error()
+		return item
+	}
+	return s.importDocument(ctx, item, doc, opts)
+}
+
+// importDocument 校验并导入单个 Prompt 文档，结果写入 item；同名处理与 dry-run 由 opts 决定。
+func (s *Service) importDocument(ctx context.Context, item *ImportItem, doc *PromptDocument, opts ImportOptions) *ImportItem {
+	fail := func(err error) *ImportItem {
+		item.Status = ImportItemFailed
+		item.Error = err.Error()
+		return item
+	}
+
+	doc.Name = strings.TrimSpace(doc.Name)
+	item.Prompt = doc.Name
+	if doc.Name == "" {
+		return fail(ErrNameRequired)
+	}
+	versions, err := doc.normalizedVersions()
+	if err != nil {
+		return fail(err)
+	}
+	item.Versions = len(versions)
+
+	existing, err := s.repos.Prompts.GetByName(ctx, doc.Name, false)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return fail(err)
+	}
+	if existing != nil {
+		item.PromptID = existing.ID
+		if opts.OnConflict == ImportConflictSkip {
+			item.Status = ImportItemSkipped
+			item.Versions = 0
+			return item
+		}
+		item.Status = ImportItemUpdated
+	} else {
+		item.Status = ImportItemCreated
+	}
+	if opts.DryRun {
+		return item
+	}
+
+	if existing == nil {
+		created, err := s.CreatePrompt(ctx, CreatePromptInput{
+			Name:        doc.Name,
+			Description: doc.Description,
+			Tags:        doc.Tags,
+			CreatedBy:   opts.CreatedBy,
+		})
+		if err != nil {
+			return fail(err)
+		}
+		item.PromptID = created.ID
+		if doc.RenderMode != nil && *doc.RenderMode != "" {
+			if _, err := s.UpdatePrompt(ctx, UpdatePromptInput{PromptID: created.ID, RenderMode: doc.RenderMode}); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	// 新建 Prompt 时完整保留文档中的历史；追加到已有 Prompt 时跳过与最新版本相同的版本，
+	// 避免重复导入产生空操作版本。
+	created := 0
+	for i, version := range versions {
+		_, err := s.CreatePromptVersion(ctx, CreatePromptVersionInput{
+			PromptID:        item.PromptID,
+			Body:            version.Body,
+			VariablesSchema: version.VariablesSchema,
+			Metadata:        version.Metadata,
+			Status:          version.Status,
+			CreatedBy:       opts.CreatedBy,
+			Activate:        version.Active,
+			AllowDuplicate:  existing == nil,
+		})
+		var duplicate *DuplicateVersionError
+		switch {
+		case errors.As(err, &duplicate):
+			if version.Active && (existing.ActiveVersionID == nil || *existing.ActiveVersionID != duplicate.Version.ID) {
+				if err := s.SetActiveVersion(ctx, item.PromptID, duplicate.Version.ID, opts.CreatedBy); err != nil {
+					item.Versions = created
+					return fail(fmt.Errorf("version %d: %w", i+1, err))
+				}
+			}
+		case err != nil:
+			item.Versions = created
+			return fail(fmt.Errorf("version %d: %w", i+1, err))
+		default:
+			created++
+		}
+	}
+	item.Versions = created
+	return item
+}
+
+// isArchivePromptFile 过滤目录、隐藏文件（含 macOS 的 __MACOSX 元数据）、导出包附带的
+// manifest.json 与 stats/ 统计文件，以及非 Prompt 文件。
+func isArchivePromptFile(name string) bool {
+	if strings.HasSuffix(name, "/") || name == exportManifestFile || strings.HasPrefix(name, exportStatsDir) {
+		return false
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") || segment == "__MACOSX" {
+			return false
+		}
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+func readPromptDocument(file *zip.File) (*PromptDocument, error) {
+	if file.UncompressedSize64 > archiveMaxEntryBytes {
+		return nil, fmt.Errorf("file exceeds %d bytes", archiveMaxEntryBytes)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// 声明的解压大小不可信，读取时再次限制。
+	content, err := io.ReadAll(io.LimitReader(rc, archiveMaxEntryBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > archiveMaxEntryBytes {
+		return nil, fmt.Errorf("file exceeds %d bytes", archiveMaxEntryBytes)
+	}
+	return parsePromptDocument(file.Name, content)
+}
+
+// parsePromptDocument 按扩展名以 JSON 或 YAML 解析 Prompt 文档。
+func parsePromptDocument(name string, content []byte) (*PromptDocument, error) {
+	var (
+		doc PromptDocument
+		err error
+	)
+	if strings.EqualFold(path.Ext(name), ".json") {
+		err = json.Unmarshal(content, &doc)
+	} else {
+		err = yaml.Unmarshal(content, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse document: %v", err)
+	}
+	return &doc, nil
+}
+
+// normalizedVersions 校验文件中的版本；未标记 active 时启用最后一个版本。
+func (d *PromptDocument) normalizedVersions() ([]PromptDocumentVersion, error) {
+	versions := d.Versions
+	if strings.TrimSpace(d.Body) != "" {
+		if len(versions) > 0 {
+			return nil, errors.New("body and versions are mutually exclusive")
+		}
+		versions = []PromptDocumentVersion{{Body: d.Body}}
+	}
+	if len(versions) == 0 {
+		return nil, ErrBodyRequired
+	}
+
+	active := -1
+	for i, version := range versions {
+		if strings.TrimSpace(version.Body) == "" {
+			return nil, fmt.Errorf("version %d: %w", i+1, ErrBodyRequired)
+		}
+		switch version.Status {
+		case "", "draft", "published", "archived":
+		default:
+			return nil, fmt.Errorf("version %d: status must be one of draft, published, archived", i+1)
+		}
+		if version.Active {
+			if active >= 0 {
+				return nil, errors.New("only one version can be active")
+			}
+			active = i
+		}
+	}
+	if active < 0 {
+		versions[len(versions)-1].Active = true
+	}
+	return versions, nil
+}
@@ -0,0 +1,72 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+)
+
+// ArchivePrompt 将 Prompt 归档：默认列表不再展示且禁止渲染执行，数据与版本原样保留，可随时取消归档。
+func (s *Service) ArchivePrompt(ctx context.Context, promptID, archivedBy string) (*domain.Prompt, error) {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.Status == domain.PromptStatusArchived {
+		return nil, ErrPromptArchived
+	}
+	return s.transitionPromptStatus(ctx, promptID, prompt.Status, domain.PromptStatusArchived, "prompt.archived", archivedBy)
+}
+
+// UnarchivePrompt 将已归档的 Prompt 恢复为可用状态。
+func (s *Service) UnarchivePrompt(ctx context.Context, promptID, unarchivedBy string) (*domain.Prompt, error) {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.Status != domain.PromptStatusArchived {
+		return nil, ErrPromptNotArchived
+	}
+	return s.transitionPromptStatus(ctx, promptID, domain.PromptStatusArchived, domain.PromptStatusActive, "prompt.unarchived", unarchivedBy)
+}
+
+// transitionPromptStatus 切换 Prompt 状态并写入审计，并发修改导致状态不匹配时返回 ErrPromptNotFound。
+func (s *Service) transitionPromptStatus(ctx context.Context, promptID, from, to, action, actor string) (*domain.Prompt, error) {
+	if err := s.repos.Prompts.UpdateStatus(ctx, promptID, from, to); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+
+	if s.repos.PromptAuditLog != nil {
+		payload, err := json.Marshal(map[string]string{
+			"from": from,
+			"to":   to,
+		})
+		if err != nil {
+			return nil, err
+		}
+		audit := &domain.PromptAuditLog{
+			ID:        uuid.NewString(),
+			PromptID:  promptID,
+			Action:    action,
+			Payload:   payload,
+			CreatedBy: optionalString(actor),
+		}
+		if err := s.repos.PromptAuditLog.Create(ctx, audit); err != nil {
+			return nil, err
+		}
+	}
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	s.publishEvent(ctx, events.PromptStatusChanged, prompt)
+	return prompt, nil
+}
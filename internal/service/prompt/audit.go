@@ -0,0 +1,33 @@
+package prompt
+
+import (
+	"context"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/audit"
+)
+
+// VerifyAuditChain 按序号遍历全部 Prompt 审计日志，检测缺失（删除）、篡改与链接断裂。
+// 引入哈希链之前写入的历史记录无法校验，仅计入 Unchained。
+func (s *Service) VerifyAuditChain(ctx context.Context) (*audit.ChainReport, error) {
+	if s.repos.PromptAuditLog == nil {
+		return nil, ErrAuditLogUnavailable
+	}
+	verifier := audit.NewChainVerifier()
+	err := s.repos.PromptAuditLog.Iterate(ctx, domain.AuditLogIterateOptions{}, func(log *domain.PromptAuditLog) error {
+		verifier.Add(log.ID, log.Seq, log.PrevHash, log.Hash, log.ChainHash())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Report(), nil
+}
+
+// IterateAuditLogs 按链序号逐行回调审计日志，用于合规归档导出。
+func (s *Service) IterateAuditLogs(ctx context.Context, opts domain.AuditLogIterateOptions, fn func(*domain.PromptAuditLog) error) error {
+	if s.repos.PromptAuditLog == nil {
+		return ErrAuditLogUnavailable
+	}
+	return s.repos.PromptAuditLog.Iterate(ctx, opts, fn)
+}
@@ -0,0 +1,105 @@
+package prompt
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// BlameLine 描述正文中一行的来源：最后修改该行的版本及其作者。
+type BlameLine struct {
+	Line          int       `json:"line"`
+	Text          string    `json:"text"`
+	VersionID     string    `json:"version_id"`
+	VersionNumber int       `json:"version_number"`
+	Author        *string   `json:"author,omitempty"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// PromptBlame 为指定版本（默认激活版本）正文的逐行溯源结果。
+type PromptBlame struct {
+	PromptID string         `json:"prompt_id"`
+	Version  VersionSummary `json:"version"`
+	Lines    []BlameLine    `json:"lines"`
+}
+
+// BlamePrompt 按版本号顺序回放历史版本，逐行计算最后修改者。
+// versionID 为空时使用激活版本；仅回放编号不大于该版本的历史。
+func (s *Service) BlamePrompt(ctx context.Context, promptID, versionID string) (*PromptBlame, error) {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.resolveRenderVersion(ctx, prompt, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.listAllVersions(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+
+	dmp := diffmatchpatch.New()
+	var (
+		lines    []BlameLine
+		previous string
+	)
+	for _, version := range versions {
+		if version.VersionNumber > target.VersionNumber {
+			break
+		}
+		current := blameText(version.Body)
+		lines = advanceBlame(dmp, lines, previous, current, version)
+		previous = current
+	}
+
+	for i := range lines {
+		lines[i].Line = i + 1
+	}
+	return &PromptBlame{
+		PromptID: promptID,
+		Version:  summarizeVersion(target),
+		Lines:    lines,
+	}, nil
+}
+
+// advanceBlame 将上一版本的逐行归属按行级 diff 映射到当前版本：
+// 未变化的行保留原归属，新增或修改的行归属当前版本。
+func advanceBlame(dmp *diffmatchpatch.DiffMatchPatch, lines []BlameLine, previous, current string, version *domain.PromptVersion) []BlameLine {
+	next := make([]BlameLine, 0, strings.Count(current, "\n"))
+	cursor := 0
+	for _, piece := range diffLines(dmp, previous, current) {
+		pieceLines := tokenizeLines(piece.Text)
+		switch piece.Type {
+		case diffmatchpatch.DiffEqual:
+			next = append(next, lines[cursor:cursor+len(pieceLines)]...)
+			cursor += len(pieceLines)
+		case diffmatchpatch.DiffDelete:
+			cursor += len(pieceLines)
+		case diffmatchpatch.DiffInsert:
+			for _, line := range pieceLines {
+				next = append(next, BlameLine{
+					Text:          strings.TrimSuffix(line, "\n"),
+					VersionID:     version.ID,
+					VersionNumber: version.VersionNumber,
+					Author:        version.CreatedBy,
+					ChangedAt:     version.CreatedAt,
+				})
+			}
+		}
+	}
+	return next
+}
+
+// blameText 统一换行并补齐末尾换行，避免最后一行仅因换行符差异被视为修改。
+func blameText(body string) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body
+}
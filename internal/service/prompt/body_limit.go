@@ -0,0 +1,33 @@
+package prompt
+
+import "fmt"
+
+// BodyTooLargeError 表示正文超出配置的字节上限。
+type BodyTooLargeError struct {
+	Size  int
+	Limit int
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("prompt body is %d bytes, exceeds limit of %d bytes", e.Size, e.Limit)
+}
+
+// Unwrap 使 errors.Is(err, ErrBodyTooLarge) 成立。
+func (e *BodyTooLargeError) Unwrap() error {
+	return ErrBodyTooLarge
+}
+
+// WithMaxBodyBytes 设置单个版本正文（含多语言正文）的字节上限，<=0 表示不限制。
+func WithMaxBodyBytes(limit int) Option {
+	return func(s *Service) {
+		s.maxBodyBytes = limit
+	}
+}
+
+// checkBodySize 校验正文字节数，超出上限时返回 *BodyTooLargeError。
+func (s *Service) checkBodySize(body string) error {
+	if s.maxBodyBytes > 0 && len(body) > s.maxBodyBytes {
+		return &BodyTooLargeError{Size: len(body), Limit: s.maxBodyBytes}
+	}
+	return nil
+}
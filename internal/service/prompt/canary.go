@@ -0,0 +1,309 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// 灰度相关的审计动作。
+const (
+	auditActionCanaryStarted    = "prompt.canary.started"
+	auditActionCanaryRolledBack = "prompt.canary.rolled_back"
+	auditActionCanaryAborted    = "prompt.canary.aborted"
+)
+
+// 灰度参数的默认值。
+const (
+	defaultCanaryMinCalls      = 20
+	defaultCanaryWindowMinutes = 15
+)
+
+// StartCanaryInput 定义灰度激活参数。Percent 为灰度流量百分比（1-99）；
+// MaxErrorRateDelta 为灰度版本错误率允许高出激活版本的百分点，MinCalls 为开始判定前灰度版本至少需要的调用数。
+type StartCanaryInput struct {
+	PromptID          string
+	VersionID         string
+	Percent           int
+	MaxErrorRateDelta float64
+	MinCalls          int
+	WindowMinutes     int
+	WebhookURL        string
+	StartedBy         string
+}
+
+// StartCanary 以灰度方式激活版本：激活版本不变，渲染时按比例改用灰度版本，由 EvaluateCanaries 监控错误率。
+// 灰度版本需满足与正式激活相同的前置条件（未归档、评审与发布策略）。
+func (s *Service) StartCanary(ctx context.Context, input StartCanaryInput) (*domain.PromptCanary, error) {
+	if input.MinCalls == 0 {
+		input.MinCalls = defaultCanaryMinCalls
+	}
+	if input.WindowMinutes == 0 {
+		input.WindowMinutes = defaultCanaryWindowMinutes
+	}
+	if input.Percent < 1 || input.Percent > 99 {
+		return nil, fmt.Errorf("%w: percent must be between 1 and 99", ErrInvalidCanary)
+	}
+	if input.MaxErrorRateDelta <= 0 || input.MaxErrorRateDelta > 100 {
+		return nil, fmt.Errorf("%w: max_error_rate_delta must be between 0 and 100", ErrInvalidCanary)
+	}
+	if input.MinCalls < 1 || input.WindowMinutes < 1 || input.WindowMinutes > maxAlertWindowMinutes {
+		return nil, fmt.Errorf("%w: min_calls must be positive and window_minutes between 1 and %d", ErrInvalidCanary, maxAlertWindowMinutes)
+	}
+	webhookURL := strings.TrimSpace(input.WebhookURL)
+	if webhookURL != "" {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("%w: webhook_url must be an http(s) URL", ErrInvalidCanary)
+		}
+	}
+
+	prompt, err := s.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.ActiveVersionID == nil {
+		return nil, fmt.Errorf("%w: prompt has no active version to compare against", ErrInvalidCanary)
+	}
+	version, err := s.getPromptVersion(ctx, prompt.ID, input.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	if version.ID == *prompt.ActiveVersionID {
+		return nil, fmt.Errorf("%w: version is already active", ErrInvalidCanary)
+	}
+	if version.Status == domain.PromptVersionStatusArchived {
+		return nil, ErrVersionArchived
+	}
+	if err := s.checkActivationPolicy(ctx, prompt.ID, version); err != nil {
+		return nil, err
+	}
+	if err := s.checkChangeFreeze(ctx, prompt, FreezeOperationCanary, input.StartedBy); err != nil {
+		return nil, err
+	}
+
+	canary := &domain.PromptCanary{
+		ID:                uuid.NewString(),
+		PromptID:          prompt.ID,
+		VersionID:         version.ID,
+		BaselineVersionID: *prompt.ActiveVersionID,
+		Percent:           input.Percent,
+		MaxErrorRateDelta: input.MaxErrorRateDelta,
+		MinCalls:          input.MinCalls,
+		WindowMinutes:     input.WindowMinutes,
+		WebhookURL:        optionalString(webhookURL),
+		Status:            domain.CanaryStatusRunning,
+		StartedBy:         optionalString(input.StartedBy),
+		StartedAt:         time.Now().UTC(),
+	}
+	if err := s.repos.PromptCanaries.Create(ctx, canary); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrCanaryRunning
+		}
+		return nil, err
+	}
+	if err := s.recordCanaryAudit(ctx, auditActionCanaryStarted, canary, input.StartedBy, nil); err != nil {
+		return nil, err
+	}
+	return canary, nil
+}
+
+// GetCanary 返回 Prompt 进行中的灰度。
+func (s *Service) GetCanary(ctx context.Context, promptID string) (*domain.PromptCanary, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	canary, err := s.repos.PromptCanaries.GetRunning(ctx, promptID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrCanaryNotFound
+	}
+	return canary, err
+}
+
+// PromoteCanary 将灰度版本全量激活，灰度随激活结束为 promoted。
+func (s *Service) PromoteCanary(ctx context.Context, input ActivateVersionInput) (*ActivationResult, error) {
+	canary, err := s.GetCanary(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	input.VersionID = canary.VersionID
+	return s.ActivateVersion(ctx, input)
+}
+
+// AbortCanary 手动终止灰度，流量全部回到激活版本。
+func (s *Service) AbortCanary(ctx context.Context, promptID, abortedBy string) (*domain.PromptCanary, error) {
+	canary, err := s.GetCanary(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.finishCanary(ctx, canary, domain.CanaryStatusAborted, "aborted manually", abortedBy); err != nil {
+		return nil, err
+	}
+	return canary, nil
+}
+
+// EvaluateCanaries 比较全部进行中灰度与激活版本的错误率，超出阈值时自动回滚并通知；单个灰度失败不影响其余灰度。
+func (s *Service) EvaluateCanaries(ctx context.Context) error {
+	if s.repos.PromptCanaries == nil {
+		return nil
+	}
+	canaries, err := s.repos.PromptCanaries.ListRunning(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	var errs []error
+	for _, canary := range canaries {
+		if err := s.evaluateCanary(ctx, canary, now); err != nil {
+			errs = append(errs, fmt.Errorf("canary %s: %w", canary.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) evaluateCanary(ctx context.Context, canary *domain.PromptCanary, now time.Time) error {
+	from := now.Add(-time.Duration(canary.WindowMinutes) * time.Minute)
+	if canary.StartedAt.After(from) {
+		from = canary.StartedAt
+	}
+	windows, err := s.repos.PromptExecutionLog.SummarizeWindowByVersion(ctx, canary.PromptID, from)
+	if err != nil {
+		return err
+	}
+	canaryWindow, baselineWindow := windows[canary.VersionID], windows[canary.BaselineVersionID]
+	canaryRate, baselineRate := windowErrorRate(canaryWindow), windowErrorRate(baselineWindow)
+	if err := s.repos.PromptCanaries.UpdateEvaluation(ctx, canary.ID, canaryRate, baselineRate, now); err != nil {
+		return err
+	}
+	canary.CanaryErrorRate, canary.BaselineErrorRate = canaryRate, baselineRate
+
+	if canaryWindow == nil || canaryWindow.TotalCalls < canary.MinCalls {
+		return nil
+	}
+	baseline := 0.0
+	if baselineRate != nil {
+		baseline = *baselineRate
+	}
+	if *canaryRate-baseline <= canary.MaxErrorRateDelta {
+		return nil
+	}
+
+	reason := fmt.Sprintf("canary error rate %.2f%% exceeds baseline %.2f%% by more than %.2f points", *canaryRate, baseline, canary.MaxErrorRateDelta)
+	if err := s.finishCanary(ctx, canary, domain.CanaryStatusRolledBack, reason, ""); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.notifyAlert(ctx, canary.WebhookURL, nil, AlertEvent{
+		RuleID:     canary.ID,
+		RuleName:   "canary rollback",
+		PromptID:   canary.PromptID,
+		Metric:     domain.AlertMetricErrorRate,
+		Threshold:  canary.MaxErrorRateDelta,
+		Window:     canary.WindowMinutes,
+		State:      domain.CanaryStatusRolledBack,
+		Value:      canaryRate,
+		TotalCalls: canaryWindow.TotalCalls,
+		OccurredAt: now,
+	})
+}
+
+// windowErrorRate 返回窗口内的错误率百分比，无调用时返回 nil。
+func windowErrorRate(window *domain.PromptExecutionWindow) *float64 {
+	if window == nil || window.TotalCalls == 0 {
+		return nil
+	}
+	rate := float64(window.FailedCalls) / float64(window.TotalCalls) * 100
+	return &rate
+}
+
+// resolveCanaryVersion 在 Prompt 有进行中的灰度时按比例返回灰度版本，否则返回 nil（使用激活版本）。
+func (s *Service) resolveCanaryVersion(ctx context.Context, prompt *domain.Prompt) (*domain.PromptVersion, error) {
+	if s.repos.PromptCanaries == nil || prompt.ActiveVersionID == nil {
+		return nil, nil
+	}
+	canary, err := s.repos.PromptCanaries.GetRunning(ctx, prompt.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// canaryRoll 可在测试中替换，默认使用随机数。
+	roll := s.canaryRoll
+	if roll == nil {
+		roll = rand.IntN
+	}
+	if roll(100) >= canary.Percent {
+		return nil, nil
+	}
+	version, err := s.getPromptVersion(ctx, prompt.ID, canary.VersionID)
+	if errors.Is(err, ErrVersionNotFound) {
+		return nil, nil
+	}
+	return version, err
+}
+
+// settleCanaryOnActivation 在版本被正式激活后结束进行中的灰度：激活的是灰度版本时记为 promoted，否则记为 aborted。
+func (s *Service) settleCanaryOnActivation(ctx context.Context, promptID, versionID, activatedBy string) error {
+	if s.repos.PromptCanaries == nil {
+		return nil
+	}
+	canary, err := s.repos.PromptCanaries.GetRunning(ctx, promptID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if canary.VersionID == versionID {
+		err = s.repos.PromptCanaries.Finish(ctx, canary.ID, domain.CanaryStatusPromoted, nil, time.Now().UTC())
+	} else {
+		err = s.finishCanary(ctx, canary, domain.CanaryStatusAborted, "superseded by activation of another version", activatedBy)
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *Service) finishCanary(ctx context.Context, canary *domain.PromptCanary, status, reason, actor string) error {
+	now := time.Now().UTC()
+	if err := s.repos.PromptCanaries.Finish(ctx, canary.ID, status, &reason, now); err != nil {
+		return err
+	}
+	canary.Status, canary.Reason, canary.EndedAt = status, &reason, &now
+
+	action := auditActionCanaryAborted
+	if status == domain.CanaryStatusRolledBack {
+		action = auditActionCanaryRolledBack
+	}
+	return s.recordCanaryAudit(ctx, action, canary, actor, map[string]interface{}{"reason": reason})
+}
+
+func (s *Service) recordCanaryAudit(ctx context.Context, action string, canary *domain.PromptCanary, actor string, extra map[string]interface{}) error {
+	payload := map[string]interface{}{
+		"canary_id":           canary.ID,
+		"version_id":          canary.VersionID,
+		"baseline_version_id": canary.BaselineVersionID,
+		"percent":             canary.Percent,
+	}
+	if canary.CanaryErrorRate != nil {
+		payload["canary_error_rate"] = *canary.CanaryErrorRate
+	}
+	if canary.BaselineErrorRate != nil {
+		payload["baseline_error_rate"] = *canary.BaselineErrorRate
+	}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	return s.recordAudit(ctx, canary.PromptID, action, actor, payload)
+}
@@ -0,0 +1,209 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/render"
+)
+
+const (
+	minCompareVersions = 2
+	maxCompareVersions = 10
+)
+
+// modelConfigKeys 为从版本 metadata 中提取的模型配置字段。
+var modelConfigKeys = []string{"provider", "model", "temperature", "top_p", "max_tokens"}
+
+// VersionComparisonColumn 为对比矩阵中一个版本的关键属性。
+type VersionComparisonColumn struct {
+	VersionSummary
+	Active          bool                   `json:"active"`
+	Length          int                    `json:"length"`
+	EstimatedTokens int                    `json:"estimated_tokens"`
+	Variables       []string               `json:"variables"`
+	ModelConfig     map[string]interface{} `json:"model_config"`
+	// EvalScores 取自版本 metadata.eval_scores（评测指标名到分数），未记录时为空。
+	EvalScores map[string]float64 `json:"eval_scores"`
+}
+
+// VersionComparisonRow 为矩阵的一行：同一属性在各版本上的取值，顺序与 Versions 一致。
+type VersionComparisonRow struct {
+	Attribute string        `json:"attribute"`
+	Values    []interface{} `json:"values"`
+	Differs   bool          `json:"differs"`
+}
+
+// VersionComparison 为多个版本的对比矩阵。
+type VersionComparison struct {
+	PromptID string                     `json:"prompt_id"`
+	Versions []*VersionComparisonColumn `json:"versions"`
+	Rows     []VersionComparisonRow     `json:"rows"`
+}
+
+// CompareVersions 按传入顺序对比同一 Prompt 的 2~10 个版本，便于激活前挑选候选版本。
+func (s *Service) CompareVersions(ctx context.Context, promptID string, versionIDs []string) (*VersionComparison, error) {
+	ids := uniqueStrings(versionIDs)
+	if len(ids) < minCompareVersions || len(ids) > maxCompareVersions {
+		return nil, fmt.Errorf("%w: between %d and %d distinct version ids are required", ErrInvalidComparison, minCompareVersions, maxCompareVersions)
+	}
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &VersionComparison{PromptID: prompt.ID}
+	for _, id := range ids {
+		version, err := s.getPromptVersion(ctx, prompt.ID, id)
+		if err != nil {
+			return nil, err
+		}
+		column, err := comparisonColumn(version)
+		if err != nil {
+			return nil, err
+		}
+		column.Active = prompt.ActiveVersionID != nil && *prompt.ActiveVersionID == version.ID
+		comparison.Versions = append(comparison.Versions, column)
+	}
+
+	comparison.Rows = comparisonRows(comparison.Versions)
+	return comparison, nil
+}
+
+func comparisonColumn(version *domain.PromptVersion) (*VersionComparisonColumn, error) {
+	column := &VersionComparisonColumn{
+		VersionSummary: VersionSummary{
+			ID:            version.ID,
+			VersionNumber: version.VersionNumber,
+			CreatedBy:     version.CreatedBy,
+			CreatedAt:     version.CreatedAt,
+			Status:        version.Status,
+		},
+		Length:          utf8.RuneCountInString(version.Body),
+		EstimatedTokens: estimateTokens(version.Body),
+		Variables:       []string{},
+		ModelConfig:     map[string]interface{}{},
+		EvalScores:      map[string]float64{},
+	}
+	// 无法解析的历史模板不阻断对比，变量列表留空即可。
+	if tmpl, err := render.Parse(version.Body); err == nil {
+		column.Variables = tmpl.Variables()
+	}
+
+	if len(version.Metadata) > 0 {
+		var metadata map[string]interface{}
+		if err := json.Unmarshal(version.Metadata, &metadata); err != nil {
+			return nil, err
+		}
+		for _, key := range modelConfigKeys {
+			if value, ok := metadata[key]; ok {
+				column.ModelConfig[key] = value
+			}
+		}
+		if scores, ok := metadata["eval_scores"].(map[string]interface{}); ok {
+			for name, value := range scores {
+				if score, ok := value.(float64); ok {
+					column.EvalScores[name] = score
+				}
+			}
+		}
+	}
+	return column, nil
+}
+
+func comparisonRows(columns []*VersionComparisonColumn) []VersionComparisonRow {
+	attributes := []struct {
+		name  string
+		value func(*VersionComparisonColumn) interface{}
+	}{
+		{"version_number", func(c *VersionComparisonColumn) interface{} { return c.VersionNumber }},
+		{"status", func(c *VersionComparisonColumn) interface{} { return c.Status }},
+		{"active", func(c *VersionComparisonColumn) interface{} { return c.Active }},
+		{"created_by", func(c *VersionComparisonColumn) interface{} { return c.CreatedBy }},
+		{"created_at", func(c *VersionComparisonColumn) interface{} { return c.CreatedAt.UTC().Format(time.RFC3339) }},
+		{"length", func(c *VersionComparisonColumn) interface{} { return c.Length }},
+		{"estimated_tokens", func(c *VersionComparisonColumn) interface{} { return c.EstimatedTokens }},
+		{"variables", func(c *VersionComparisonColumn) interface{} { return strings.Join(c.Variables, ",") }},
+	}
+
+	rows := make([]VersionComparisonRow, 0, len(attributes)+len(modelConfigKeys))
+	for _, attribute := range attributes {
+		row := VersionComparisonRow{Attribute: attribute.name}
+		for _, column := range columns {
+			row.Values = append(row.Values, attribute.value(column))
+		}
+		rows = append(rows, finalizeComparisonRow(row))
+	}
+
+	// 模型配置与评测分数只输出至少一个版本具备的键。
+	for _, key := range modelConfigKeys {
+		row := VersionComparisonRow{Attribute: "model_config." + key}
+		present := false
+		for _, column := range columns {
+			value, ok := column.ModelConfig[key]
+			present = present || ok
+			row.Values = append(row.Values, value)
+		}
+		if present {
+			rows = append(rows, finalizeComparisonRow(row))
+		}
+	}
+	for _, name := range evalScoreNames(columns) {
+		row := VersionComparisonRow{Attribute: "eval_scores." + name}
+		for _, column := range columns {
+			if score, ok := column.EvalScores[name]; ok {
+				row.Values = append(row.Values, score)
+			} else {
+				row.Values = append(row.Values, nil)
+			}
+		}
+		rows = append(rows, finalizeComparisonRow(row))
+	}
+	return rows
+}
+
+func finalizeComparisonRow(row VersionComparisonRow) VersionComparisonRow {
+	for _, value := range row.Values[1:] {
+		if !reflect.DeepEqual(value, row.Values[0]) {
+			row.Differs = true
+			break
+		}
+	}
+	return row
+}
+
+func evalScoreNames(columns []*VersionComparisonColumn) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, column := range columns {
+		for name := range column.EvalScores {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
@@ -0,0 +1,112 @@
+package prompt
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvImportMaxRows 限制单次 CSV 导入的行数。
+const csvImportMaxRows = 5000
+
+// ImportCSV 按表头 name,description,tags,body 逐行导入 Prompt，列顺序不限、表头不区分大小写，
+// name 与 body 必填；tags 以分号或逗号分隔。单行失败只记入报告，表头缺失或 CSV 无法解析时返回 ErrInvalidCSV。
+func (s *Service) ImportCSV(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: missing header row", ErrInvalidCSV)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+	}
+	columns, err := csvImportColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Items: []*ImportItem{}}
+	for row := 2; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if report.Total >= csvImportMaxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidCSV, csvImportMaxRows)
+		}
+
+		if err != nil {
+			// 引号不闭合等错误会让后续行错位，无法可靠地继续解析；列数不足的行按空值处理。
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+		if isBlankCSVRecord(record) {
+			continue
+		}
+		report.add(s.importDocument(ctx, &ImportItem{Row: row}, columns.document(record), opts))
+	}
+	return report, nil
+}
+
+type csvColumns struct {
+	name, description, tags, body int
+}
+
+func csvImportColumns(header []string) (*csvColumns, error) {
+	columns := &csvColumns{name: -1, description: -1, tags: -1, body: -1}
+	for i, raw := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(raw, "\ufeff"))) {
+		case "name":
+			columns.name = i
+		case "description":
+			columns.description = i
+		case "tags":
+			columns.tags = i
+		case "body":
+			columns.body = i
+		}
+	}
+	if columns.name < 0 || columns.body < 0 {
+		return nil, fmt.Errorf("%w: header must include name and body columns", ErrInvalidCSV)
+	}
+	return columns, nil
+}
+
+func (c *csvColumns) document(record []string) *PromptDocument {
+	field := func(index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return record[index]
+	}
+
+	doc := &PromptDocument{Name: field(c.name), Body: field(c.body)}
+	if description := strings.TrimSpace(field(c.description)); description != "" {
+		doc.Description = &description
+	}
+	for _, tag := range strings.FieldsFunc(field(c.tags), func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			doc.Tags = append(doc.Tags, tag)
+		}
+	}
+	return doc
+}
+
+func isBlankCSVRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
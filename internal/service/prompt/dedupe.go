@@ -0,0 +1,39 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// DuplicateVersionError 表示待创建版本与最新版本内容一致，Version 为已存在的版本。
+type DuplicateVersionError struct {
+	Version *domain.PromptVersion
+}
+
+func (e *DuplicateVersionError) Error() string {
+	return fmt.Sprintf("version is identical to latest version %d (%s)", e.Version.VersionNumber, e.Version.ID)
+}
+
+// Unwrap 使 errors.Is(err, ErrDuplicateVersion) 成立。
+func (e *DuplicateVersionError) Unwrap() error {
+	return ErrDuplicateVersion
+}
+
+// checkDuplicateVersion 比较候选版本与最新版本的正文与变量 Schema，一致时返回 *DuplicateVersionError。
+// metadata、状态不参与比较。
+func (s *Service) checkDuplicateVersion(ctx context.Context, candidate *domain.PromptVersion) error {
+	latest, err := s.latestVersion(ctx, candidate.PromptID)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		return nil
+	}
+	if latest.Body == candidate.Body && bytes.Equal(latest.VariablesSchema, candidate.VariablesSchema) {
+		return &DuplicateVersionError{Version: latest}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+const (
+	DependencySourceExplicit = "explicit"
+	DependencySourceInclude  = "include"
+)
+
+// includePattern 匹配正文中的 {{> prompt-name}} 引用语法。
+var includePattern = regexp.MustCompile(`\{\{>\s*([^\s{}]+)\s*\}\}`)
+
+// SetPromptDependencies 覆盖 Prompt 显式声明的依赖，refs 可以是 Prompt ID 或名称。
+func (s *Service) SetPromptDependencies(ctx context.Context, promptID string, refs []string, updatedBy string) ([]*domain.PromptDependencyLink, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+
+	dependsOn := make([]string, 0, len(refs))
+	seen := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		target, err := s.resolvePromptRef(ctx, ref)
+		if err != nil {
+			if errors.Is(err, ErrPromptNotFound) {
+				return nil, ErrDependencyNotFound
+			}
+			return nil, err
+		}
+		if _, ok := seen[target.ID]; ok {
+			continue
+		}
+		if target.ID == promptID {
+			return nil, ErrDependencyCycle
+		}
+		reaches, err := s.dependsTransitively(ctx, target.ID, promptID)
+		if err != nil {
+			return nil, err
+		}
+		if reaches {
+			return nil, ErrDependencyCycle
+		}
+		seen[target.ID] = struct{}{}
+		dependsOn = append(dependsOn, target.ID)
+	}
+
+	if err := s.repos.PromptDependencies.ReplaceBySource(ctx, promptID, DependencySourceExplicit, dependsOn, optionalString(updatedBy)); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAudit(ctx, promptID, "prompt.dependencies.updated", updatedBy, map[string]interface{}{
+		"depends_on": dependsOn,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repos.PromptDependencies.ListDependencies(ctx, promptID)
+}
+
+// ListPromptDependencies 返回 Prompt 依赖的其他 Prompt（显式声明与正文引用）。
+func (s *Service) ListPromptDependencies(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	return s.repos.PromptDependencies.ListDependencies(ctx, promptID)
+}
+
+// ListPromptDependents 返回依赖该 Prompt 的其他 Prompt，用于删除或切换版本前的提示。
+func (s *Service) ListPromptDependents(ctx context.Context, promptID string) ([]*domain.PromptDependencyLink, error) {
+	if _, err := s.repos.Prompts.GetByIDIncludeDeleted(ctx, promptID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+	return s.repos.PromptDependencies.ListDependents(ctx, promptID)
+}
+
+// DetectIncludes 提取正文中引用的 Prompt 名称，按出现顺序去重。
+func DetectIncludes(body string) []string {
+	matches := includePattern.FindAllStringSubmatch(body, -1)
+	names := make([]string, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+	for _, match := range matches {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// syncIncludeDependencies 根据激活版本正文刷新 include 类依赖，无法解析的名称与自引用会被忽略。
+func (s *Service) syncIncludeDependencies(ctx context.Context, promptID, body, actor string) error {
+	if s.repos.PromptDependencies == nil {
+		return nil
+	}
+	var dependsOn []string
+	for _, name := range DetectIncludes(body) {
+		target, err := s.repos.Prompts.GetByName(ctx, name, false)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if target.ID == promptID {
+			continue
+		}
+		dependsOn = append(dependsOn, target.ID)
+	}
+	return s.repos.PromptDependencies.ReplaceBySource(ctx, promptID, DependencySourceInclude, dependsOn, optionalString(actor))
+}
+
+func (s *Service) resolvePromptRef(ctx context.Context, ref string) (*domain.Prompt, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, ErrPromptNotFound
+	}
+	prompt, err := s.repos.Prompts.GetByID(ctx, ref)
+	if err == nil {
+		return prompt, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	prompt, err = s.repos.Prompts.GetByName(ctx, ref, false)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+	return prompt, nil
+}
+
+// dependsTransitively 判断 from 是否（直接或间接）依赖 target。
+func (s *Service) dependsTransitively(ctx context.Context, from, target string) (bool, error) {
+	visited := map[string]struct{}{}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[current]; ok {
+			continue
+		}
+		visited[current] = struct{}{}
+
+		links, err := s.repos.PromptDependencies.ListDependencies(ctx, current)
+		if err != nil {
+			return false, err
+		}
+		for _, link := range links {
+			if link.PromptID == target {
+				return true, nil
+			}
+			queue = append(queue, link.PromptID)
+		}
+	}
+	return false, nil
+}
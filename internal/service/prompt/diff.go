@@ -12,10 +12,23 @@ import (
 	domain "github.com/zacharykka/prompt-manager/internal/domain"
 )
 
+const (
+	// DiffGranularityChar 字符级差异（默认）。
+	DiffGranularityChar = "char"
+	// DiffGranularityWord 按单词与空白切分的差异。
+	DiffGranularityWord = "word"
+	// DiffGranularityLine 按行切分的差异。
+	DiffGranularityLine = "line"
+)
+
 type DiffPromptVersionOptions struct {
 	TargetVersionID   *string
 	CompareToActive   bool
 	CompareToPrevious bool
+	// Granularity 控制 Body 差异粒度：char|word|line，默认 char。
+	Granularity string
+	// Unified 为 true 时额外生成统一 diff 文本。
+	Unified bool
 }
 
 type DiffSegment struct {
@@ -43,24 +56,25 @@ type VersionSummary struct {
 }
 
 type PromptVersionDiff struct {
-	PromptID  string         `json:"prompt_id"`
-	Base      VersionSummary `json:"base"`
-	Target    VersionSummary `json:"target"`
-	Body      []DiffSegment  `json:"body"`
-	Variables *FieldDiff     `json:"variables_schema,omitempty"`
-	Metadata  *FieldDiff     `json:"metadata,omitempty"`
+	PromptID    string         `json:"prompt_id"`
+	Base        VersionSummary `json:"base"`
+	Target      VersionSummary `json:"target"`
+	Body        []DiffSegment  `json:"body"`
+	Granularity string         `json:"granularity"`
+	Unified     string         `json:"unified,omitempty"`
+	Variables   *FieldDiff     `json:"variables_schema,omitempty"`
+	Metadata    *FieldDiff     `json:"metadata,omitempty"`
 }
 
 func (s *Service) DiffPromptVersion(ctx context.Context, promptID, baseVersionID string, opts DiffPromptVersionOptions) (*PromptVersionDiff, error) {
-	base, err := s.repos.PromptVersions.GetByID(ctx, baseVersionID)
+	granularity, err := normalizeDiffGranularity(opts.Granularity)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return nil, ErrVersionNotFound
-		}
 		return nil, err
 	}
-	if base.PromptID != promptID {
-		return nil, ErrVersionNotFound
+
+	base, err := s.getPromptVersion(ctx, promptID, baseVersionID)
+	if err != nil {
+		return nil, err
 	}
 
 	target, err := s.resolveDiffTarget(ctx, promptID, base, opts)
@@ -69,10 +83,19 @@ func (s *Service) DiffPromptVersion(ctx context.Context, promptID, baseVersionID
 	}
 
 	diff := &PromptVersionDiff{
-		PromptID: promptID,
-		Base:     summarizeVersion(base),
-		Target:   summarizeVersion(target),
-		Body:     buildBodyDiff(target.Body, base.Body),
+		PromptID:    promptID,
+		Base:        summarizeVersion(base),
+		Target:      summarizeVersion(target),
+		Body:        buildBodyDiff(target.Body, base.Body, granularity),
+		Granularity: granularity,
+	}
+	if opts.Unified {
+		diff.Unified = buildUnifiedDiff(
+			fmt.Sprintf("v%d", target.VersionNumber),
+			fmt.Sprintf("v%d", base.VersionNumber),
+			target.Body,
+			base.Body,
+		)
 	}
 
 	if fieldDiff := buildFieldDiff(target.VariablesSchema, base.VariablesSchema); fieldDiff != nil {
@@ -139,10 +162,18 @@ func (s *Service) resolveDiffTarget(ctx context.Context, promptID string, base *
 	return nil, ErrVersionNotFound
 }
 
-func buildBodyDiff(left, right string) []DiffSegment {
+func buildBodyDiff(left, right, granularity string) []DiffSegment {
 	dmp := diffmatchpatch.New()
-	patches := dmp.DiffMain(left, right, false)
-	dmp.DiffCleanupSemantic(patches)
+	var patches []diffmatchpatch.Diff
+	switch granularity {
+	case DiffGranularityLine:
+		patches = diffLines(dmp, left, right)
+	case DiffGranularityWord:
+		patches = diffWords(dmp, left, right)
+	default:
+		// DiffCleanupSemantic 会改写底层数组并返回新切片，必须使用返回值。
+		patches = dmp.DiffCleanupSemantic(dmp.DiffMain(left, right, false))
+	}
 
 	segments := make([]DiffSegment, 0, len(patches))
 	for _, piece := range patches {
@@ -0,0 +1,233 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	// unifiedDiffContext 为统一 diff 每个 hunk 前后保留的上下文行数。
+	unifiedDiffContext = 3
+	// maxDiffTokens 限制行/单词级 diff 的去重 token 数，超出后回退到字符级。
+	maxDiffTokens = 0x10FFFF - 0x800
+)
+
+func normalizeDiffGranularity(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", DiffGranularityChar:
+		return DiffGranularityChar, nil
+	case DiffGranularityWord:
+		return DiffGranularityWord, nil
+	case DiffGranularityLine:
+		return DiffGranularityLine, nil
+	default:
+		return "", ErrInvalidDiffGranularity
+	}
+}
+
+// diffLines 以整行为最小单位计算差异。
+func diffLines(dmp *diffmatchpatch.DiffMatchPatch, left, right string) []diffmatchpatch.Diff {
+	return diffTokens(dmp, left, right, tokenizeLines)
+}
+
+// diffWords 将文本切分为单词、空白与标点后再计算差异，避免单词被拆散。
+func diffWords(dmp *diffmatchpatch.DiffMatchPatch, left, right string) []diffmatchpatch.Diff {
+	return diffTokens(dmp, left, right, tokenizeWords)
+}
+
+// diffTokens 把每个不同的 token 映射为单个 rune 后交给 diffmatchpatch，
+// 再将结果还原为原文本，从而得到 token 粒度的差异。
+func diffTokens(dmp *diffmatchpatch.DiffMatchPatch, left, right string, tokenize func(string) []string) []diffmatchpatch.Diff {
+	index := make(map[string]rune)
+	tokens := make([]string, 0)
+	encode := func(text string) []rune {
+		parts := tokenize(text)
+		encoded := make([]rune, 0, len(parts))
+		for _, part := range parts {
+			r, ok := index[part]
+			if !ok {
+				r = tokenRune(len(tokens))
+				index[part] = r
+				tokens = append(tokens, part)
+			}
+			encoded = append(encoded, r)
+		}
+		return encoded
+	}
+
+	leftRunes := encode(left)
+	rightRunes := encode(right)
+	if len(tokens) > maxDiffTokens {
+		return dmp.DiffMain(left, right, false)
+	}
+
+	diffs := dmp.DiffMainRunes(leftRunes, rightRunes, false)
+	hydrated := make([]diffmatchpatch.Diff, 0, len(diffs))
+	for _, piece := range diffs {
+		var builder strings.Builder
+		for _, r := range piece.Text {
+			builder.WriteString(tokens[tokenIndex(r)])
+		}
+		hydrated = append(hydrated, diffmatchpatch.Diff{Type: piece.Type, Text: builder.String()})
+	}
+	return hydrated
+}
+
+func tokenizeLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// tokenizeWords 把字母数字连续段、空白连续段各视为一个 token，
+// 标点与汉字等无空格分隔的字符则单独成 token。
+func tokenizeWords(text string) []string {
+	tokens := make([]string, 0)
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		j := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		case isWordRune(r):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+		return false
+	}
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokenRune 将 token 序号映射为合法 rune，跳过 UTF-16 代理区。
+func tokenRune(i int) rune {
+	r := rune(i + 1)
+	if r >= 0xD800 {
+		r += 0x800
+	}
+	return r
+}
+
+func tokenIndex(r rune) int {
+	if r >= 0xD800+0x800 {
+		r -= 0x800
+	}
+	return int(r) - 1
+}
+
+type unifiedLine struct {
+	op        byte
+	text      string
+	oldPos    int
+	newPos    int
+	noNewline bool
+}
+
+// buildUnifiedDiff 生成 `diff -u` 风格的文本，内容无变化时返回空字符串。
+func buildUnifiedDiff(leftName, rightName, left, right string) string {
+	dmp := diffmatchpatch.New()
+	lines := make([]unifiedLine, 0)
+	oldPos, newPos := 0, 0
+	for _, piece := range diffLines(dmp, left, right) {
+		op := byte(' ')
+		switch piece.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, line := range strings.SplitAfter(piece.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			entry := unifiedLine{op: op, oldPos: oldPos, newPos: newPos}
+			entry.text = strings.TrimSuffix(line, "\n")
+			entry.noNewline = !strings.HasSuffix(line, "\n")
+			lines = append(lines, entry)
+			if op != '+' {
+				oldPos++
+			}
+			if op != '-' {
+				newPos++
+			}
+		}
+	}
+
+	var builder strings.Builder
+	for start := 0; start < len(lines); {
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		last := first
+		for i := first + 1; i < len(lines) && i-last <= 2*unifiedDiffContext; i++ {
+			if lines[i].op != ' ' {
+				last = i
+			}
+		}
+		from := max(first-unifiedDiffContext, start)
+		to := min(last+unifiedDiffContext+1, len(lines))
+
+		if builder.Len() == 0 {
+			fmt.Fprintf(&builder, "--- %s\n+++ %s\n", leftName, rightName)
+		}
+		writeUnifiedHunk(&builder, lines[from:to])
+		start = to
+	}
+	return builder.String()
+}
+
+func writeUnifiedHunk(builder *strings.Builder, hunk []unifiedLine) {
+	oldCount, newCount := 0, 0
+	for _, line := range hunk {
+		if line.op != '+' {
+			oldCount++
+		}
+		if line.op != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n",
+		unifiedRange(hunk[0].oldPos, oldCount),
+		unifiedRange(hunk[0].newPos, newCount),
+	)
+	for _, line := range hunk {
+		builder.WriteByte(line.op)
+		builder.WriteString(line.text)
+		builder.WriteByte('\n')
+		if line.noNewline {
+			builder.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
+
+func unifiedRange(pos, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", pos)
+	case 1:
+		return fmt.Sprintf("%d", pos+1)
+	default:
+		return fmt.Sprintf("%d,%d", pos+1, count)
+	}
+}
@@ -0,0 +1,169 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// SaveDraftInput 定义保存草稿所需字段。
+// Revision 为客户端最后一次看到的草稿版本号：nil 表示直接覆盖，0 表示仅在尚无草稿时创建。
+type SaveDraftInput struct {
+	PromptID        string
+	UserID          string
+	Body            string
+	VariablesSchema interface{}
+	Metadata        interface{}
+	BaseVersionID   *string
+	Revision        *int
+}
+
+// PromptDraftView 为返回给客户端的草稿，附带其与最新版本的关系。
+type PromptDraftView struct {
+	*domain.PromptDraft
+	LatestVersionID *string `json:"latest_version_id,omitempty"`
+	// Stale 表示草稿所基于的版本之后又产生了新版本，保存前需要合并。
+	Stale bool `json:"stale"`
+}
+
+// GetDraft 返回当前用户在该 Prompt 上的草稿，用于崩溃后恢复未保存内容。
+func (s *Service) GetDraft(ctx context.Context, promptID, userID string) (*PromptDraftView, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	draft, err := s.getDraft(ctx, promptID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.draftView(ctx, draft)
+}
+
+// SaveDraft 创建或覆盖草稿。内容未变化时不递增 Revision，便于客户端按防抖频率反复调用；
+// Revision 与服务端不一致时返回 ErrDraftConflict，避免多个标签页互相覆盖。
+func (s *Service) SaveDraft(ctx context.Context, input SaveDraftInput) (*PromptDraftView, error) {
+	if strings.TrimSpace(input.UserID) == "" {
+		return nil, ErrInvalidDraft
+	}
+	if _, err := s.GetPrompt(ctx, input.PromptID); err != nil {
+		return nil, err
+	}
+
+	draft := &domain.PromptDraft{
+		PromptID:      input.PromptID,
+		UserID:        input.UserID,
+		Body:          input.Body,
+		BaseVersionID: input.BaseVersionID,
+	}
+	var err error
+	if input.VariablesSchema != nil {
+		if draft.VariablesSchema, err = json.Marshal(input.VariablesSchema); err != nil {
+			return nil, err
+		}
+	}
+	if input.Metadata != nil {
+		if draft.Metadata, err = json.Marshal(input.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.getDraft(ctx, input.PromptID, input.UserID)
+	if err != nil && !errors.Is(err, ErrDraftNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		if input.Revision != nil && *input.Revision != existing.Revision {
+			return nil, ErrDraftConflict
+		}
+		if draft.BaseVersionID == nil {
+			draft.BaseVersionID = existing.BaseVersionID
+		}
+		if sameDraftContent(existing, draft) {
+			return s.draftView(ctx, existing)
+		}
+	} else if draft.BaseVersionID == nil {
+		latest, err := s.latestVersion(ctx, input.PromptID)
+		if err != nil {
+			return nil, err
+		}
+		if latest != nil {
+			draft.BaseVersionID = &latest.ID
+		}
+	}
+
+	if err := s.repos.PromptDrafts.Save(ctx, draft, input.Revision); err != nil {
+		if errors.Is(err, domain.ErrRevisionConflict) {
+			return nil, ErrDraftConflict
+		}
+		return nil, err
+	}
+	saved, err := s.getDraft(ctx, input.PromptID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.draftView(ctx, saved)
+}
+
+// DeleteDraft 丢弃草稿，通常在保存为正式版本后调用。
+func (s *Service) DeleteDraft(ctx context.Context, promptID, userID string) error {
+	if err := s.repos.PromptDrafts.Delete(ctx, promptID, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrDraftNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) getDraft(ctx context.Context, promptID, userID string) (*domain.PromptDraft, error) {
+	draft, err := s.repos.PromptDrafts.Get(ctx, promptID, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (s *Service) draftView(ctx context.Context, draft *domain.PromptDraft) (*PromptDraftView, error) {
+	view := &PromptDraftView{PromptDraft: draft}
+	latest, err := s.latestVersion(ctx, draft.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		view.LatestVersionID = &latest.ID
+		view.Stale = draft.BaseVersionID != nil && *draft.BaseVersionID != latest.ID
+	}
+	return view, nil
+}
+
+// latestVersion 返回编号最大的版本，尚无版本时返回 nil。
+func (s *Service) latestVersion(ctx context.Context, promptID string) (*domain.PromptVersion, error) {
+	versions, err := s.repos.PromptVersions.ListByPrompt(ctx, promptID, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return versions[0], nil
+}
+
+func sameDraftContent(left, right *domain.PromptDraft) bool {
+	return left.Body == right.Body &&
+		bytes.Equal(left.VariablesSchema, right.VariablesSchema) &&
+		bytes.Equal(left.Metadata, right.Metadata) &&
+		equalStringPtr(left.BaseVersionID, right.BaseVersionID)
+}
+
+func equalStringPtr(left, right *string) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+	return *left == *right
+}
@@ -10,4 +10,70 @@ var (
 	ErrPromptAlreadyExists = errors.New("prompt already exists")
 	ErrNoFieldsToUpdate    = errors.New("no prompt fields to update")
 	ErrPromptNotDeleted    = errors.New("prompt is not deleted")
+	ErrPromptArchived      = errors.New("prompt is archived")
+	ErrPromptNotArchived   = errors.New("prompt is not archived")
+	ErrInvalidOwner        = errors.New("invalid prompt owner")
+
+	ErrUnsupportedPreviewFormat = errors.New("unsupported preview format")
+	ErrInvalidLocale            = errors.New("invalid locale")
+	ErrLocaleNotFound           = errors.New("prompt locale variant not found")
+	ErrDependencyNotFound       = errors.New("dependency prompt not found")
+	ErrDependencyCycle          = errors.New("prompt dependency would create a cycle")
+	ErrRenderFailed             = errors.New("prompt template render failed")
+	ErrInvalidRenderMode        = errors.New("invalid render mode")
+	ErrMissingVariables         = errors.New("prompt template variables missing")
+	ErrAuditLogUnavailable      = errors.New("audit log not configured")
+	ErrInvalidTimezone          = errors.New("invalid timezone")
+	ErrInvalidGranularity       = errors.New("invalid stats granularity")
+	ErrInvalidTimeRange         = errors.New("invalid stats time range")
+	ErrInvalidAlertRule         = errors.New("invalid alert rule")
+	ErrAlertRuleNotFound        = errors.New("alert rule not found")
+	ErrAlertWebhookMissing      = errors.New("alert rule has no webhook")
+	ErrAlertWebhookFailed       = errors.New("alert webhook delivery failed")
+	ErrInvalidArchive           = errors.New("invalid prompt archive")
+	ErrInvalidExportFilter      = errors.New("invalid export filter")
+	ErrInvalidCSV               = errors.New("invalid csv import")
+	ErrInvalidTemplate          = errors.New("invalid prompt template")
+	ErrTemplateNotFound         = errors.New("prompt template not found")
+	ErrTemplateAlreadyExists    = errors.New("prompt template already exists")
+	ErrInvalidComparison        = errors.New("invalid version comparison")
+	ErrInvalidDiffGranularity   = errors.New("invalid diff granularity")
+	ErrReleaseNoteRequired      = errors.New("release note required")
+	ErrInvalidReleaseNote       = errors.New("invalid release note")
+	ErrDraftNotFound            = errors.New("prompt draft not found")
+	ErrDraftConflict            = errors.New("prompt draft was modified concurrently")
+	ErrInvalidDraft             = errors.New("invalid prompt draft")
+	ErrEditLocksDisabled        = errors.New("edit locks not configured")
+	ErrEditLockHeld             = errors.New("prompt edit lock held by another user")
+	ErrEditLockNotHeld          = errors.New("prompt edit lock not held")
+	ErrInvalidEditLock          = errors.New("invalid prompt edit lock")
+	ErrDuplicateVersion         = errors.New("version identical to latest version")
+	ErrReviewPolicyNotFound     = errors.New("prompt review policy not found")
+	ErrInvalidReviewPolicy      = errors.New("invalid prompt review policy")
+	ErrNotPromptOwner           = errors.New("only the prompt owner can change this setting")
+	ErrReviewRequired           = errors.New("version requires more approvals before activation")
+	ErrSelfApproval             = errors.New("authors cannot approve their own version")
+	ErrNotEligibleReviewer      = errors.New("user is not an eligible reviewer for this prompt")
+	ErrApprovalNotFound         = errors.New("version approval not found")
+	ErrInvalidReview            = errors.New("invalid version review")
+	ErrInvalidCountMode         = errors.New("count mode must be exact, estimated or none")
+	ErrInvalidExecutionBatch    = errors.New("invalid execution batch")
+	ErrInvalidExecution         = errors.New("invalid execution record")
+	ErrInvalidVersionStatus     = errors.New("version status must be draft, published or archived")
+	ErrInvalidStatusTransition  = errors.New("invalid version status transition")
+	ErrVersionArchived          = errors.New("archived versions cannot be activated")
+	ErrVersionActive            = errors.New("the active version cannot be archived")
+	ErrVersionNotPublishable    = errors.New("version must be published before activation")
+	ErrNoPreviousVersion        = errors.New("prompt has no previous active version to roll back to")
+	ErrInvalidCanary            = errors.New("invalid canary activation")
+	ErrCanaryRunning            = errors.New("prompt already has a running canary")
+	ErrCanaryNotFound           = errors.New("prompt has no running canary")
+	ErrChangeFreeze             = errors.New("operation blocked by change freeze")
+	ErrBodyTooLarge             = errors.New("prompt body exceeds size limit")
+	ErrExecutionNotFound        = errors.New("execution log not found")
+	ErrExecutionNotReplayable   = errors.New("execution log cannot be replayed")
+	ErrGatewayUnavailable       = errors.New("llm gateway is not configured")
+	ErrGatewayFailed            = errors.New("llm gateway call failed")
+	ErrInvalidTargetModels      = errors.New("invalid target models")
+	ErrUnknownModel             = errors.New("unknown model")
 )
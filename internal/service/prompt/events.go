@@ -0,0 +1,27 @@
+package prompt
+
+import (
+	"context"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+)
+
+// WithEventBus 在 Prompt 及其版本变更后向总线发布事件（见 events 包中的事件类型）。
+func WithEventBus(bus *events.Bus) Option {
+	return func(s *Service) {
+		s.events = bus
+	}
+}
+
+// publishEvent 发布 Prompt 变更事件；未配置总线或 prompt 为空时跳过。
+func (s *Service) publishEvent(ctx context.Context, eventType string, prompt *domain.Prompt) {
+	if s.events == nil || prompt == nil {
+		return
+	}
+	s.events.Publish(ctx, events.Event{
+		Type:        eventType,
+		WorkspaceID: prompt.WorkspaceID,
+		PromptID:    prompt.ID,
+	})
+}
@@ -0,0 +1,168 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+const (
+	// MaxExecutionBatchSize 为单次批量上报的记录上限。
+	MaxExecutionBatchSize = 1000
+	// executionClockSkew 容忍上报方与服务端的时钟偏差，超出后视为未来时间。
+	executionClockSkew = 5 * time.Minute
+
+	ExecutionItemRecorded = "recorded"
+	ExecutionItemFailed   = "failed"
+)
+
+// ExecutionRecordInput 为外部上报的一次执行结果。VersionID 为空时记到当前激活版本，
+// ExecutedAt 为空时使用写入时间。
+type ExecutionRecordInput struct {
+	PromptID         string
+	VersionID        string
+	Status           string
+	DurationMs       int64
+	RequestPayload   json.RawMessage
+	ResponseMetadata json.RawMessage
+	ErrorClass       string
+	ErrorCode        string
+	ExecutedAt       *time.Time
+}
+
+// ExecutionBatchItem 为单条记录的处理结果，Index 对应请求中的下标。
+type ExecutionBatchItem struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ExecutionBatchReport 汇总一次批量上报的结果。
+type ExecutionBatchReport struct {
+	Total    int                   `json:"total"`
+	Recorded int                   `json:"recorded"`
+	Failed   int                   `json:"failed"`
+	Items    []*ExecutionBatchItem `json:"items"`
+}
+
+// RecordExecutions 批量写入外部上报的执行日志。每条记录单独校验，无效记录只计入报告，
+// 有效记录在一个事务内写入，数据库错误会使整批失败；批次为空或超过 MaxExecutionBatchSize
+// 时返回 ErrInvalidExecutionBatch。
+func (s *Service) RecordExecutions(ctx context.Context, inputs []ExecutionRecordInput, userID string) (*ExecutionBatchReport, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: records required", ErrInvalidExecutionBatch)
+	}
+	if len(inputs) > MaxExecutionBatchSize {
+		return nil, fmt.Errorf("%w: at most %d records per batch", ErrInvalidExecutionBatch, MaxExecutionBatchSize)
+	}
+
+	report := &ExecutionBatchReport{Total: len(inputs), Items: make([]*ExecutionBatchItem, 0, len(inputs))}
+	prompts := make(map[string]*domain.Prompt)
+	versions := make(map[string]*domain.PromptVersion)
+	logs := make([]*domain.PromptExecutionLog, 0, len(inputs))
+	now := time.Now()
+	for i, input := range inputs {
+		item := &ExecutionBatchItem{Index: i}
+		report.Items = append(report.Items, item)
+		log, err := s.executionLog(ctx, input, userID, now, prompts, versions)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidExecution) && !errors.Is(err, ErrPromptNotFound) && !errors.Is(err, ErrVersionNotFound) {
+				return nil, err
+			}
+			item.Status = ExecutionItemFailed
+			item.Error = err.Error()
+			report.Failed++
+			continue
+		}
+		item.ID = log.ID
+		item.Status = ExecutionItemRecorded
+		logs = append(logs, log)
+	}
+
+	if err := s.repos.PromptExecutionLog.CreateBatch(ctx, logs); err != nil {
+		return nil, err
+	}
+	report.Recorded = len(logs)
+	return report, nil
+}
+
+// executionLog 校验单条上报记录并转换为执行日志，prompts 与 versions 缓存同一批次内的查询结果。
+func (s *Service) executionLog(ctx context.Context, input ExecutionRecordInput, userID string, now time.Time, prompts map[string]*domain.Prompt, versions map[string]*domain.PromptVersion) (*domain.PromptExecutionLog, error) {
+	promptID := strings.TrimSpace(input.PromptID)
+	if promptID == "" {
+		return nil, fmt.Errorf("%w: prompt_id required", ErrInvalidExecution)
+	}
+	switch input.Status {
+	case "success", "error":
+	default:
+		return nil, fmt.Errorf("%w: status must be success or error", ErrInvalidExecution)
+	}
+	if input.DurationMs < 0 {
+		return nil, fmt.Errorf("%w: duration_ms must not be negative", ErrInvalidExecution)
+	}
+	switch input.ErrorClass {
+	case "", domain.ExecutionErrorTimeout, domain.ExecutionErrorProvider, domain.ExecutionErrorGuardrailBlock, domain.ExecutionErrorValidation:
+	default:
+		return nil, fmt.Errorf("%w: unknown error_class %q", ErrInvalidExecution, input.ErrorClass)
+	}
+	if input.ExecutedAt != nil && input.ExecutedAt.After(now.Add(executionClockSkew)) {
+		return nil, fmt.Errorf("%w: executed_at is in the future", ErrInvalidExecution)
+	}
+
+	prompt, ok := prompts[promptID]
+	if !ok {
+		var err error
+		prompt, err = s.GetPrompt(ctx, promptID)
+		if err != nil && !errors.Is(err, ErrPromptNotFound) {
+			return nil, err
+		}
+		prompts[promptID] = prompt
+	}
+	if prompt == nil {
+		return nil, ErrPromptNotFound
+	}
+
+	versionID := strings.TrimSpace(input.VersionID)
+	if versionID == "" {
+		if prompt.ActiveVersionID == nil {
+			return nil, fmt.Errorf("%w: version_id required when prompt has no active version", ErrInvalidExecution)
+		}
+		versionID = *prompt.ActiveVersionID
+	}
+	version, ok := versions[versionID]
+	if !ok {
+		var err error
+		version, err = s.repos.PromptVersions.GetByID(ctx, versionID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		versions[versionID] = version
+	}
+	if version == nil || version.PromptID != prompt.ID {
+		return nil, ErrVersionNotFound
+	}
+
+	log := &domain.PromptExecutionLog{
+		ID:               uuid.NewString(),
+		PromptID:         prompt.ID,
+		PromptVersionID:  version.ID,
+		UserID:           optionalString(userID),
+		Status:           input.Status,
+		DurationMs:       input.DurationMs,
+		RequestPayload:   input.RequestPayload,
+		ResponseMetadata: input.ResponseMetadata,
+		ErrorClass:       optionalString(input.ErrorClass),
+		ErrorCode:        optionalString(input.ErrorCode),
+	}
+	if input.ExecutedAt != nil {
+		log.CreatedAt = *input.ExecutedAt
+	}
+	return log, nil
+}
@@ -0,0 +1,225 @@
+package prompt
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	exportFormatVersion = 1
+	exportPageSize      = 100
+	// defaultExportStatsDays 为导出执行统计时默认覆盖的天数。
+	defaultExportStatsDays = 30
+
+	exportManifestFile = "manifest.json"
+	exportPromptsDir   = "prompts/"
+	exportStatsDir     = "stats/"
+)
+
+var exportFileNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExportArchiveOptions 控制工作区导出范围。
+type ExportArchiveOptions struct {
+	// Include/Exclude 为 Prompt 名称的通配模式（path.Match 语法），Include 为空表示全部。
+	Include []string
+	Exclude []string
+	// Stats 为 true 时附带每个 Prompt 最近 StatsDays 天的按日执行统计。
+	Stats     bool
+	StatsDays int
+}
+
+// ExportManifest 写入压缩包根目录的 manifest.json，描述导出来源与内容。
+type ExportManifest struct {
+	FormatVersion int       `json:"format_version"`
+	WorkspaceID   string    `json:"workspace_id,omitempty"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Prompts       int       `json:"prompts"`
+	Versions      int       `json:"versions"`
+	Stats         bool      `json:"stats"`
+}
+
+// ExportArchive 把当前工作区的 Prompt 逐个写入 zip：prompts/*.yaml 与导入格式一致，
+// 开启统计时另写 stats/*.json，最后写 manifest.json。写出中途失败时压缩包不完整，调用方应中断响应。
+func (s *Service) ExportArchive(ctx context.Context, zw *zip.Writer, opts ExportArchiveOptions) (*ExportManifest, error) {
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid name pattern %q", ErrInvalidExportFilter, pattern)
+		}
+	}
+	if opts.StatsDays <= 0 {
+		opts.StatsDays = defaultExportStatsDays
+	}
+
+	manifest := &ExportManifest{
+		FormatVersion: exportFormatVersion,
+		WorkspaceID:   domain.WorkspaceFromContext(ctx),
+		ExportedAt:    time.Now().UTC(),
+		Stats:         opts.Stats,
+	}
+	usedNames := map[string]bool{}
+
+	for offset := 0; ; offset += exportPageSize {
+		prompts, err := s.repos.Prompts.List(ctx, domain.PromptListOptions{Limit: exportPageSize, Offset: offset, IncludeArchived: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, prompt := range prompts {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if !matchExportFilters(prompt.Name, opts) {
+				continue
+			}
+			doc, err := s.promptDocument(ctx, prompt)
+			if err != nil {
+				return nil, err
+			}
+			fileName := exportFileName(prompt, usedNames)
+			if err := writeArchiveYAML(zw, exportPromptsDir+fileName+".yaml", doc); err != nil {
+				return nil, err
+			}
+			manifest.Prompts++
+			manifest.Versions += len(doc.Versions)
+
+			if opts.Stats {
+				stats, err := s.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Days: opts.StatsDays})
+				if err != nil {
+					return nil, err
+				}
+				if err := writeArchiveJSON(zw, exportStatsDir+fileName+".json", stats); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(prompts) < exportPageSize {
+			break
+		}
+	}
+
+	if err := writeArchiveJSON(zw, exportManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// promptDocument 把 Prompt 及其全部版本转换为导入格式，版本按编号升序，当前启用版本标记 active。
+func (s *Service) promptDocument(ctx context.Context, prompt *domain.Prompt) (*PromptDocument, error) {
+	doc := &PromptDocument{
+		Name:        prompt.Name,
+		Description: prompt.Description,
+		RenderMode:  prompt.RenderMode,
+	}
+	if len(prompt.Tags) > 0 {
+		if err := json.Unmarshal(prompt.Tags, &doc.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	versions, err := s.listAllVersions(ctx, prompt.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		entry := PromptDocumentVersion{
+			Body:   version.Body,
+			Status: version.Status,
+			Active: prompt.ActiveVersionID != nil && *prompt.ActiveVersionID == version.ID,
+		}
+		if len(version.VariablesSchema) > 0 {
+			if err := json.Unmarshal(version.VariablesSchema, &entry.VariablesSchema); err != nil {
+				return nil, err
+			}
+		}
+		if len(version.Metadata) > 0 {
+			if err := json.Unmarshal(version.Metadata, &entry.Metadata); err != nil {
+				return nil, err
+			}
+		}
+		doc.Versions = append(doc.Versions, entry)
+	}
+	return doc, nil
+}
+
+func matchExportFilters(name string, opts ExportArchiveOptions) bool {
+	for _, pattern := range opts.Exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+	if len(opts.Include) == 0 {
+		return true
+	}
+	for _, pattern := range opts.Include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// exportFileName 由 Prompt 名称生成安全的文件名，清洗后重名时追加 ID 前缀区分。
+func exportFileName(prompt *domain.Prompt, used map[string]bool) string {
+	name := strings.Trim(exportFileNameUnsafe.ReplaceAllString(prompt.Name, "_"), "._")
+	if name == "" {
+		name = "prompt"
+	}
+	if used[strings.ToLower(name)] {
+		id := prompt.ID
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		name = name + "-" + id
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+func writeArchiveYAML(zw *zip.Writer, name string, value interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func writeArchiveJSON(zw *zip.Writer, name string, value interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// listAllVersions 分页读取 Prompt 的全部版本，按版本号升序返回。
+func (s *Service) listAllVersions(ctx context.Context, promptID string) ([]*domain.PromptVersion, error) {
+	var versions []*domain.PromptVersion
+	for offset := 0; ; offset += exportPageSize {
+		page, err := s.repos.PromptVersions.ListByPrompt(ctx, promptID, exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].VersionNumber < versions[j].VersionNumber })
+	return versions, nil
+}
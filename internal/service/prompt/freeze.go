@@ -0,0 +1,66 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// auditActionFreezeOverridden 记录冻结窗口内被豁免的激活或删除。
+const auditActionFreezeOverridden = "prompt.freeze.overridden"
+
+// 受变更冻结约束的操作。
+const (
+	FreezeOperationActivate = "activate"
+	FreezeOperationCanary   = "canary"
+	FreezeOperationDelete   = "delete"
+)
+
+// ChangeFreeze 判断工作区在某一时刻是否处于变更冻结窗口，返回窗口名称与冻结结束时间。
+type ChangeFreeze interface {
+	Active(workspaceID string, at time.Time) (string, time.Time, bool)
+}
+
+// WithChangeFreeze 启用变更冻结：窗口内禁止激活（含灰度）与删除 Prompt，上下文带有冻结豁免的操作者除外。
+func WithChangeFreeze(freeze ChangeFreeze) Option {
+	return func(s *Service) {
+		s.changeFreeze = freeze
+	}
+}
+
+// ChangeFreezeError 表示操作落在冻结窗口内，Until 为冻结结束时间。
+type ChangeFreezeError struct {
+	Window    string
+	Until     time.Time
+	Operation string
+}
+
+func (e *ChangeFreezeError) Error() string {
+	return fmt.Sprintf("%s is blocked by change freeze %q until %s", e.Operation, e.Window, e.Until.Format(time.RFC3339))
+}
+
+// Unwrap 使 errors.Is(err, ErrChangeFreeze) 成立。
+func (e *ChangeFreezeError) Unwrap() error {
+	return ErrChangeFreeze
+}
+
+// checkChangeFreeze 在冻结窗口内拒绝操作；有豁免时放行并写入审计。
+func (s *Service) checkChangeFreeze(ctx context.Context, prompt *domain.Prompt, operation, actor string) error {
+	if s.changeFreeze == nil {
+		return nil
+	}
+	window, until, ok := s.changeFreeze.Active(prompt.WorkspaceID, time.Now())
+	if !ok {
+		return nil
+	}
+	if !domain.FreezeOverrideFromContext(ctx) {
+		return &ChangeFreezeError{Window: window, Until: until, Operation: operation}
+	}
+	return s.recordAudit(ctx, prompt.ID, auditActionFreezeOverridden, actor, map[string]interface{}{
+		"window":    window,
+		"until":     until,
+		"operation": operation,
+	})
+}
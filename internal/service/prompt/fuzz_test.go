@@ -0,0 +1,104 @@
+package prompt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func addDiffSeeds(f *testing.F) {
+	f.Add("", "")
+	f.Add("hello world", "hello there world")
+	f.Add("line one\nline two\n", "line one\nline 2\nline three")
+	f.Add("你好，世界", "你好，朋友")
+	f.Add("a\r\nb\r\n", "a\nb\n")
+	f.Add(strings.Repeat("x\n", 64), strings.Repeat("y\n", 64))
+	f.Add("\x00�\U0010FFFF", "\U0010FFFF\x00")
+}
+
+func FuzzBuildBodyDiff(f *testing.F) {
+	addDiffSeeds(f)
+	f.Fuzz(func(t *testing.T, left, right string) {
+		// diffmatchpatch 按 rune 处理文本，非法 UTF-8 会被替换，无法还原原文。
+		if !utf8.ValidString(left) || !utf8.ValidString(right) {
+			t.Skip()
+		}
+		for _, granularity := range []string{DiffGranularityChar, DiffGranularityWord, DiffGranularityLine} {
+			var gotLeft, gotRight strings.Builder
+			for _, segment := range buildBodyDiff(left, right, granularity) {
+				if segment.Text == "" {
+					t.Fatalf("%s: empty segment", granularity)
+				}
+				switch segment.Type {
+				case "equal":
+					gotLeft.WriteString(segment.Text)
+					gotRight.WriteString(segment.Text)
+				case "delete":
+					gotLeft.WriteString(segment.Text)
+				case "insert":
+					gotRight.WriteString(segment.Text)
+				default:
+					t.Fatalf("%s: unexpected segment type %q", granularity, segment.Type)
+				}
+			}
+			// 删除与相等片段拼出左侧，插入与相等片段拼出右侧。
+			if gotLeft.String() != left || gotRight.String() != right {
+				t.Fatalf("%s: segments do not reconstruct inputs", granularity)
+			}
+		}
+	})
+}
+
+func FuzzBuildUnifiedDiff(f *testing.F) {
+	addDiffSeeds(f)
+	f.Fuzz(func(t *testing.T, left, right string) {
+		if !utf8.ValidString(left) || !utf8.ValidString(right) {
+			t.Skip()
+		}
+		unified := buildUnifiedDiff("a", "b", left, right)
+		if (unified == "") != (left == right) {
+			t.Fatalf("expected empty diff only for identical inputs, got %q", unified)
+		}
+		if unified != "" && !strings.HasPrefix(unified, "--- a\n+++ b\n@@ -") {
+			t.Fatalf("malformed unified diff header: %q", unified)
+		}
+	})
+}
+
+func FuzzBuildFieldDiff(f *testing.F) {
+	f.Add([]byte(`{"temperature":0.2,"model":"gpt"}`), []byte(`{"temperature":0.7,"stop":["\n"]}`))
+	f.Add([]byte(`{}`), []byte(`null`))
+	f.Add([]byte(`{"a":{"b":[1,2,{"c":null}]}}`), []byte(`{"a":{"b":[1,2]}}`))
+	f.Add([]byte(`[1,2,3]`), []byte(`"not an object"`))
+	f.Add([]byte(`{"a":1`), []byte(`{"a":1e400}`))
+	f.Fuzz(func(t *testing.T, left, right []byte) {
+		if diff := buildFieldDiff(left, left); diff != nil {
+			t.Fatalf("expected no changes for identical metadata, got %+v", diff)
+		}
+		diff := buildFieldDiff(left, right)
+		if diff == nil {
+			return
+		}
+		if len(diff.Changes) == 0 {
+			t.Fatalf("non-nil diff without changes")
+		}
+		for i, change := range diff.Changes {
+			if i > 0 && diff.Changes[i-1].Key >= change.Key {
+				t.Fatalf("changes not sorted by unique key: %q then %q", diff.Changes[i-1].Key, change.Key)
+			}
+			switch change.Type {
+			case "added", "removed":
+			case "modified":
+				if change.Left == change.Right {
+					t.Fatalf("modified change %q without difference", change.Key)
+				}
+			default:
+				t.Fatalf("unexpected change type %q", change.Type)
+			}
+		}
+		if _, err := json.Marshal(diff); err != nil {
+			t.Fatalf("field diff not serializable: %v", err)
+		}
+	})
+}
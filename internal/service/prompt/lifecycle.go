@@ -0,0 +1,126 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+)
+
+// auditActionVersionStatusChanged 为版本状态流转写入的审计动作。
+const auditActionVersionStatusChanged = "prompt.version.status_changed"
+
+// versionStatusTransitions 为版本状态机：draft → published → archived，archived 为终态。
+var versionStatusTransitions = map[string][]string{
+	domain.PromptVersionStatusDraft:     {domain.PromptVersionStatusPublished},
+	domain.PromptVersionStatusPublished: {domain.PromptVersionStatusArchived},
+}
+
+// StatusTransitionError 表示版本状态不允许从 From 流转到 To。
+type StatusTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change version status from %s to %s", e.From, e.To)
+}
+
+// Unwrap 使 errors.Is(err, ErrInvalidStatusTransition) 成立。
+func (e *StatusTransitionError) Unwrap() error {
+	return ErrInvalidStatusTransition
+}
+
+// AllowedVersionStatusTransitions 返回版本在当前状态下可流转到的状态。
+func AllowedVersionStatusTransitions(from string) []string {
+	return append([]string(nil), versionStatusTransitions[from]...)
+}
+
+func canTransitionVersionStatus(from, to string) bool {
+	for _, next := range versionStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionVersionStatusInput 定义版本状态流转所需参数。
+type TransitionVersionStatusInput struct {
+	PromptID  string
+	VersionID string
+	Status    string
+	ChangedBy string
+}
+
+// TransitionVersionStatus 按状态机变更版本状态并写入审计。
+// 不允许的流转返回 *StatusTransitionError；当前激活版本不可归档，返回 ErrVersionActive。
+func (s *Service) TransitionVersionStatus(ctx context.Context, input TransitionVersionStatusInput) (*domain.PromptVersion, error) {
+	target := strings.TrimSpace(strings.ToLower(input.Status))
+	switch target {
+	case domain.PromptVersionStatusDraft, domain.PromptVersionStatusPublished, domain.PromptVersionStatusArchived:
+	default:
+		return nil, ErrInvalidVersionStatus
+	}
+
+	prompt, err := s.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.repos.PromptVersions.GetByID(ctx, input.VersionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	if version.PromptID != prompt.ID {
+		return nil, ErrVersionNotFound
+	}
+
+	from := version.Status
+	if !canTransitionVersionStatus(from, target) {
+		return nil, &StatusTransitionError{From: from, To: target}
+	}
+	if target == domain.PromptVersionStatusArchived && prompt.ActiveVersionID != nil && *prompt.ActiveVersionID == version.ID {
+		return nil, ErrVersionActive
+	}
+
+	if err := s.repos.PromptVersions.UpdateStatus(ctx, version.ID, from, target); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			// 状态已被并发修改，按最新状态重新判定。
+			return nil, &StatusTransitionError{From: from, To: target}
+		}
+		return nil, err
+	}
+	version.Status = target
+
+	if s.repos.PromptAuditLog != nil {
+		payload, err := json.Marshal(map[string]interface{}{
+			"version_id":     version.ID,
+			"version_number": version.VersionNumber,
+			"from":           from,
+			"to":             target,
+		})
+		if err != nil {
+			return nil, err
+		}
+		audit := &domain.PromptAuditLog{
+			ID:        uuid.NewString(),
+			PromptID:  prompt.ID,
+			Action:    auditActionVersionStatusChanged,
+			Payload:   payload,
+			CreatedBy: optionalString(input.ChangedBy),
+		}
+		if err := s.repos.PromptAuditLog.Create(ctx, audit); err != nil {
+			return nil, err
+		}
+	}
+	s.publishEvent(ctx, events.VersionStatusChanged, prompt)
+	return version, nil
+}
@@ -0,0 +1,259 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// SetVersionLocaleInput 定义写入版本语言变体所需字段。
+type SetVersionLocaleInput struct {
+	PromptID  string
+	VersionID string
+	Locale    string
+	Body      string
+	CreatedBy string
+}
+
+// LocaleCoverageReport 汇总某个语言区域在激活版本中的覆盖情况。
+type LocaleCoverageReport struct {
+	Locale  string                         `json:"locale"`
+	Total   int                            `json:"total"`
+	Covered int                            `json:"covered"`
+	Missing []*domain.PromptLocaleCoverage `json:"missing"`
+}
+
+// SetVersionLocale 为指定版本创建或覆盖一个语言变体。
+func (s *Service) SetVersionLocale(ctx context.Context, input SetVersionLocaleInput) (*domain.PromptVersionLocale, error) {
+	locale, err := NormalizeLocale(input.Locale)
+	if err != nil {
+		return nil, err
+	}
+	body := s.normalizeBody(input.Body)
+	if strings.TrimSpace(body) == "" {
+		return nil, ErrBodyRequired
+	}
+	if err := s.checkBodySize(body); err != nil {
+		return nil, err
+	}
+	if _, err := s.getPromptVersion(ctx, input.PromptID, input.VersionID); err != nil {
+		return nil, err
+	}
+
+	variant := &domain.PromptVersionLocale{
+		ID:        uuid.NewString(),
+		VersionID: input.VersionID,
+		Locale:    locale,
+		Body:      body,
+		CreatedBy: optionalString(input.CreatedBy),
+	}
+	if err := s.repos.PromptLocales.Upsert(ctx, variant); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAudit(ctx, input.PromptID, "prompt.version.locale.updated", input.CreatedBy, map[string]interface{}{
+		"version_id": input.VersionID,
+		"locale":     locale,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repos.PromptLocales.GetByVersionAndLocale(ctx, input.VersionID, locale)
+}
+
+// ListVersionLocales 列出指定版本的全部语言变体。
+func (s *Service) ListVersionLocales(ctx context.Context, promptID, versionID string) ([]*domain.PromptVersionLocale, error) {
+	if _, err := s.getPromptVersion(ctx, promptID, versionID); err != nil {
+		return nil, err
+	}
+	return s.repos.PromptLocales.ListByVersion(ctx, versionID)
+}
+
+// DeleteVersionLocale 删除指定版本的某个语言变体。
+func (s *Service) DeleteVersionLocale(ctx context.Context, promptID, versionID, rawLocale, deletedBy string) error {
+	locale, err := NormalizeLocale(rawLocale)
+	if err != nil {
+		return err
+	}
+	if _, err := s.getPromptVersion(ctx, promptID, versionID); err != nil {
+		return err
+	}
+	if err := s.repos.PromptLocales.Delete(ctx, versionID, locale); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrLocaleNotFound
+		}
+		return err
+	}
+
+	return s.recordAudit(ctx, promptID, "prompt.version.locale.deleted", deletedBy, map[string]interface{}{
+		"version_id": versionID,
+		"locale":     locale,
+	})
+}
+
+// GetPromptWithLocale 返回 Prompt，并按语言偏好链替换激活版本正文；返回实际命中的语言，未命中时为空。
+func (s *Service) GetPromptWithLocale(ctx context.Context, promptID, requested string) (*domain.Prompt, string, error) {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, "", err
+	}
+	if strings.TrimSpace(requested) == "" || prompt.ActiveVersionID == nil {
+		return prompt, "", nil
+	}
+
+	variant, err := s.ResolveVersionLocale(ctx, *prompt.ActiveVersionID, requested)
+	if err != nil {
+		return nil, "", err
+	}
+	if variant == nil {
+		return prompt, "", nil
+	}
+	body := variant.Body
+	prompt.Body = &body
+	return prompt, variant.Locale, nil
+}
+
+// ResolveVersionLocale 按回退链查找版本的语言变体，全部未命中时返回 nil 表示使用默认正文。
+func (s *Service) ResolveVersionLocale(ctx context.Context, versionID, requested string) (*domain.PromptVersionLocale, error) {
+	chain, err := LocaleFallbackChain(requested)
+	if err != nil {
+		return nil, err
+	}
+	for _, locale := range chain {
+		variant, err := s.repos.PromptLocales.GetByVersionAndLocale(ctx, versionID, locale)
+		if err == nil {
+			return variant, nil
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// GetLocaleCoverage 统计激活版本中缺少指定语言（含回退链）的 Prompt。
+func (s *Service) GetLocaleCoverage(ctx context.Context, requested string) (*LocaleCoverageReport, error) {
+	chain, err := LocaleFallbackChain(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repos.PromptLocales.ListActiveCoverage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LocaleCoverageReport{Locale: chain[0], Total: len(items), Missing: []*domain.PromptLocaleCoverage{}}
+	for _, item := range items {
+		if localeCovered(item.Locales, chain) {
+			report.Covered++
+			continue
+		}
+		report.Missing = append(report.Missing, item)
+	}
+	return report, nil
+}
+
+// NormalizeLocale 将 zh_cn、ZH-cn 等写法规范为 BCP 47 风格的 zh-CN。
+func NormalizeLocale(raw string) (string, error) {
+	value := strings.ReplaceAll(strings.TrimSpace(raw), "_", "-")
+	if !localePattern.MatchString(value) {
+		return "", ErrInvalidLocale
+	}
+	parts := strings.Split(value, "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// LocaleFallbackChain 将逗号分隔的语言偏好展开为回退链，例如 "zh-Hant-TW,en" -> zh-Hant-TW, zh-Hant, zh, en。
+func LocaleFallbackChain(requested string) ([]string, error) {
+	var chain []string
+	seen := make(map[string]struct{})
+	for _, candidate := range strings.Split(requested, ",") {
+		if strings.TrimSpace(candidate) == "" {
+			continue
+		}
+		locale, err := NormalizeLocale(candidate)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Split(locale, "-")
+		for i := len(parts); i > 0; i-- {
+			tag := strings.Join(parts[:i], "-")
+			if _, ok := seen[tag]; ok {
+				continue
+			}
+			seen[tag] = struct{}{}
+			chain = append(chain, tag)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, ErrInvalidLocale
+	}
+	return chain, nil
+}
+
+func localeCovered(available []string, chain []string) bool {
+	for _, locale := range chain {
+		for _, candidate := range available {
+			if candidate == locale {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getPromptVersion 先按当前工作区校验 Prompt 可见，再加载其下的版本，跨工作区的版本表现为 Prompt 不存在。
+func (s *Service) getPromptVersion(ctx context.Context, promptID, versionID string) (*domain.PromptVersion, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	version, err := s.repos.PromptVersions.GetByID(ctx, versionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	if version.PromptID != promptID {
+		return nil, ErrVersionNotFound
+	}
+	return version, nil
+}
+
+// recordAudit 写入 Prompt 审计日志，未配置审计仓储时跳过。
+func (s *Service) recordAudit(ctx context.Context, promptID, action, actor string, payloadData map[string]interface{}) error {
+	if s.repos.PromptAuditLog == nil {
+		return nil
+	}
+	payload, err := json.Marshal(payloadData)
+	if err != nil {
+		return err
+	}
+	return s.repos.PromptAuditLog.Create(ctx, &domain.PromptAuditLog{
+		ID:        uuid.NewString(),
+		PromptID:  promptID,
+		Action:    action,
+		Payload:   payload,
+		CreatedBy: optionalString(actor),
+	})
+}
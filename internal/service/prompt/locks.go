@@ -0,0 +1,93 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// defaultEditLockTTL 为编辑锁默认有效期，编辑器需在到期前重复获取以续期。
+const defaultEditLockTTL = 2 * time.Minute
+
+// EditLockHeldError 表示编辑锁已被他人持有，Lock 为当前持有的锁。
+type EditLockHeldError struct {
+	Lock *domain.EditLock
+}
+
+func (e *EditLockHeldError) Error() string {
+	return fmt.Sprintf("prompt is being edited by %s until %s", e.Lock.Holder, e.Lock.ExpiresAt.Format(time.RFC3339))
+}
+
+// Unwrap 使 errors.Is(err, ErrEditLockHeld) 成立。
+func (e *EditLockHeldError) Unwrap() error {
+	return ErrEditLockHeld
+}
+
+// WithEditLocks 启用编辑锁；ttl<=0 时使用默认有效期。
+func WithEditLocks(store domain.EditLockStore, ttl time.Duration) Option {
+	return func(s *Service) {
+		s.editLocks = store
+		s.editLockTTL = ttl
+		if s.editLockTTL <= 0 {
+			s.editLockTTL = defaultEditLockTTL
+		}
+	}
+}
+
+// AcquireEditLock 获取或续期 Prompt 的编辑锁，已被他人持有时返回 *EditLockHeldError。
+func (s *Service) AcquireEditLock(ctx context.Context, promptID, holderID, holder string) (*domain.EditLock, error) {
+	if s.editLocks == nil {
+		return nil, ErrEditLocksDisabled
+	}
+	if strings.TrimSpace(holderID) == "" {
+		return nil, ErrInvalidEditLock
+	}
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(holder) == "" {
+		holder = holderID
+	}
+
+	now := time.Now().UTC()
+	current, acquired, err := s.editLocks.Acquire(ctx, domain.EditLock{
+		PromptID:   promptID,
+		HolderID:   holderID,
+		Holder:     holder,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(s.editLockTTL),
+	}, s.editLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, &EditLockHeldError{Lock: current}
+	}
+	return current, nil
+}
+
+// ReleaseEditLock 释放自己持有的编辑锁，未持有时返回 ErrEditLockNotHeld。
+func (s *Service) ReleaseEditLock(ctx context.Context, promptID, holderID string) error {
+	if s.editLocks == nil {
+		return ErrEditLocksDisabled
+	}
+	released, err := s.editLocks.Release(ctx, promptID, holderID)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrEditLockNotHeld
+	}
+	return nil
+}
+
+// GetEditLock 返回当前编辑锁，未启用编辑锁或锁空闲时返回 nil。
+func (s *Service) GetEditLock(ctx context.Context, promptID string) (*domain.EditLock, error) {
+	if s.editLocks == nil {
+		return nil, nil
+	}
+	return s.editLocks.Get(ctx, promptID)
+}
@@ -0,0 +1,118 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+)
+
+// targetModelsKey 为版本 metadata 中声明目标模型或模型系列的字段。
+const targetModelsKey = "target_models"
+
+// WithModelRegistry 注入已知模型注册表，用于校验版本声明的目标模型与按模型筛选；未注入时只校验名称格式。
+func WithModelRegistry(registry *llm.Registry) Option {
+	return func(s *Service) {
+		s.models = registry
+	}
+}
+
+// targetModels 读取 metadata.target_models 并规范化去重。声明不是字符串数组、名称不合法，
+// 或配置了注册表而名称既不是已知模型也不是已知系列时返回 ErrInvalidTargetModels。
+func (s *Service) targetModels(metadata json.RawMessage) ([]string, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &fields); err != nil {
+		// metadata 不要求是对象，非对象时视为未声明目标模型。
+		return nil, nil
+	}
+	raw, ok := fields[targetModelsKey]
+	if !ok || string(raw) == "null" {
+		return nil, nil
+	}
+	var names []string
+	if err := json.Unmarshal(raw, &names); err != nil {
+		return nil, fmt.Errorf("%w: %s must be an array of strings", ErrInvalidTargetModels, targetModelsKey)
+	}
+
+	var targets, unknown []string
+	for _, name := range names {
+		name = llm.NormalizeModelName(name)
+		if !llm.ValidModelName(name) {
+			return nil, fmt.Errorf("%w: invalid model name %q", ErrInvalidTargetModels, name)
+		}
+		if slices.Contains(targets, name) {
+			continue
+		}
+		if !s.models.Known(name) {
+			unknown = append(unknown, name)
+			continue
+		}
+		targets = append(targets, name)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: unknown models or families: %s", ErrInvalidTargetModels, strings.Join(unknown, ", "))
+	}
+	return targets, nil
+}
+
+// modelFilter 将列表的模型筛选条件展开为互相覆盖的目标名称（模型 ID 与所属系列，或系列与其下模型），
+// 配置了注册表时拒绝未知名称。
+func (s *Service) modelFilter(model string) ([]string, error) {
+	model = llm.NormalizeModelName(model)
+	if model == "" {
+		return nil, nil
+	}
+	if !llm.ValidModelName(model) || !s.models.Known(model) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownModel, model)
+	}
+	return s.models.Related(model), nil
+}
+
+// flagDeprecatedModels 为激活版本 model_config（metadata.model）引用已弃用或下线模型的 Prompt 填充 ModelDeprecation，
+// 一次批量读取本页激活版本的 metadata；注册表中没有弃用模型时不查询。
+func (s *Service) flagDeprecatedModels(ctx context.Context, prompts []*domain.Prompt) error {
+	if !s.models.HasDeprecated() {
+		return nil
+	}
+	versionIDs := make([]string, 0, len(prompts))
+	for _, prompt := range prompts {
+		if prompt.ActiveVersionID != nil {
+			versionIDs = append(versionIDs, *prompt.ActiveVersionID)
+		}
+	}
+	metadata, err := s.repos.PromptVersions.MetadataByIDs(ctx, versionIDs)
+	if err != nil {
+		return err
+	}
+	for _, prompt := range prompts {
+		if prompt.ActiveVersionID != nil {
+			prompt.ModelDeprecation = s.modelDeprecation(metadata[*prompt.ActiveVersionID])
+		}
+	}
+	return nil
+}
+
+// modelDeprecation 返回 metadata.model 引用的弃用模型，未引用、模型未登记或仍可用时返回 nil。
+func (s *Service) modelDeprecation(metadata json.RawMessage) *domain.ModelDeprecation {
+	if len(metadata) == 0 {
+		return nil
+	}
+	var config struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(metadata, &config); err != nil || config.Model == "" {
+		return nil
+	}
+	model, ok := s.models.Lookup(config.Model)
+	if !ok || !model.Deprecated() {
+		return nil
+	}
+	return &domain.ModelDeprecation{Model: model.ID, Status: model.Status, Replacement: model.Replacement}
+}
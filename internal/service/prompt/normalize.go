@@ -0,0 +1,99 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// invisibleChar 描述一个不可见字符；strip 为 false 的字符在 emoji 组合与部分文字中有实际用途，只提示不移除。
+type invisibleChar struct {
+	name  string
+	strip bool
+}
+
+// invisibleChars 为从富文本编辑器粘贴时常混入、肉眼不可见却会改变模型输入的字符。
+var invisibleChars = map[rune]invisibleChar{
+	'\u00AD': {name: "SOFT HYPHEN", strip: true},
+	'\u180E': {name: "MONGOLIAN VOWEL SEPARATOR", strip: true},
+	'\u200B': {name: "ZERO WIDTH SPACE", strip: true},
+	'\u200C': {name: "ZERO WIDTH NON-JOINER"},
+	'\u200D': {name: "ZERO WIDTH JOINER"},
+	'\u200E': {name: "LEFT-TO-RIGHT MARK", strip: true},
+	'\u200F': {name: "RIGHT-TO-LEFT MARK", strip: true},
+	'\u202A': {name: "LEFT-TO-RIGHT EMBEDDING", strip: true},
+	'\u202B': {name: "RIGHT-TO-LEFT EMBEDDING", strip: true},
+	'\u202C': {name: "POP DIRECTIONAL FORMATTING", strip: true},
+	'\u202D': {name: "LEFT-TO-RIGHT OVERRIDE", strip: true},
+	'\u202E': {name: "RIGHT-TO-LEFT OVERRIDE", strip: true},
+	'\u2060': {name: "WORD JOINER", strip: true},
+	'\u2061': {name: "FUNCTION APPLICATION", strip: true},
+	'\u2062': {name: "INVISIBLE TIMES", strip: true},
+	'\u2063': {name: "INVISIBLE SEPARATOR", strip: true},
+	'\u2064': {name: "INVISIBLE PLUS", strip: true},
+	'\u2066': {name: "LEFT-TO-RIGHT ISOLATE", strip: true},
+	'\u2067': {name: "RIGHT-TO-LEFT ISOLATE", strip: true},
+	'\u2068': {name: "FIRST STRONG ISOLATE", strip: true},
+	'\u2069': {name: "POP DIRECTIONAL ISOLATE", strip: true},
+	'\uFEFF': {name: "ZERO WIDTH NO-BREAK SPACE", strip: true},
+}
+
+// smartQuotes 为排版引号，英文提示词中通常由编辑器自动替换而来。
+const smartQuotes = "\u2018\u2019\u201C\u201D"
+
+// WithStripInvisibleChars 为 true 时保存版本与语言变体前移除不可见字符（零宽空格、方向控制符、BOM 等）。
+func WithStripInvisibleChars(strip bool) Option {
+	return func(s *Service) {
+		s.stripInvisible = strip
+	}
+}
+
+// NormalizeBody 将正文规范化为 Unicode NFC；strip 为 true 时同时移除可安全去除的不可见字符。
+func NormalizeBody(body string, strip bool) string {
+	body = norm.NFC.String(body)
+	if !strip {
+		return body
+	}
+	return strings.Map(func(r rune) rune {
+		if char, ok := invisibleChars[r]; ok && char.strip {
+			return -1
+		}
+		return r
+	}, body)
+}
+
+// normalizeBody 按服务配置规范化待保存的正文。
+func (s *Service) normalizeBody(body string) string {
+	return NormalizeBody(body, s.stripInvisible)
+}
+
+// lintUnicode 检查单行中的非 NFC 文本、不可见字符与排版引号，同一字符在一行内只报告一次。
+func (s *Service) lintUnicode(line string, lineNumber int) []ValidationIssue {
+	var issues []ValidationIssue
+	if !norm.NFC.IsNormalString(line) {
+		issues = append(issues, ValidationIssue{Rule: "unicode_normalization", Severity: ValidationSeverityWarning, Message: "line is not NFC normalized and will be normalized on save", Line: lineNumber})
+	}
+	seen := map[rune]bool{}
+	smartQuote := false
+	for _, r := range line {
+		if strings.ContainsRune(smartQuotes, r) {
+			smartQuote = true
+			continue
+		}
+		char, ok := invisibleChars[r]
+		if !ok || seen[r] {
+			continue
+		}
+		seen[r] = true
+		message := fmt.Sprintf("line contains invisible character U+%04X %s", r, char.name)
+		if s.stripInvisible && char.strip {
+			message += " (removed on save)"
+		}
+		issues = append(issues, ValidationIssue{Rule: "invisible_character", Severity: ValidationSeverityWarning, Message: message, Line: lineNumber})
+	}
+	if smartQuote {
+		issues = append(issues, ValidationIssue{Rule: "smart_quotes", Severity: ValidationSeverityWarning, Message: "line contains typographic quotes; use straight quotes unless intended", Line: lineNumber})
+	}
+	return issues
+}
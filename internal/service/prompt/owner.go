@@ -0,0 +1,95 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+)
+
+// maxTeamOwnerLength 限制团队负责人标识长度。
+const maxTeamOwnerLength = 200
+
+// TransferOwner 将 Prompt 转交给新的负责人（owner 为 nil 时清除负责人），并写入审计。
+func (s *Service) TransferOwner(ctx context.Context, promptID string, owner *domain.PromptOwner, actor string) (*domain.Prompt, error) {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := s.normalizePromptOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.Prompts.UpdateOwner(ctx, promptID, normalized); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+
+	if s.repos.PromptAuditLog != nil {
+		payload, err := json.Marshal(map[string]interface{}{
+			"from": prompt.Owner,
+			"to":   normalized,
+		})
+		if err != nil {
+			return nil, err
+		}
+		audit := &domain.PromptAuditLog{
+			ID:        uuid.NewString(),
+			PromptID:  promptID,
+			Action:    "prompt.owner.transferred",
+			Payload:   payload,
+			CreatedBy: optionalString(actor),
+		}
+		if err := s.repos.PromptAuditLog.Create(ctx, audit); err != nil {
+			return nil, err
+		}
+	}
+	s.publishEvent(ctx, events.PromptOwnerChanged, prompt)
+	return s.GetPrompt(ctx, promptID)
+}
+
+// normalizePromptOwner 校验负责人：用户负责人需存在（邮箱会解析为用户 ID），团队负责人只校验标识非空。
+func (s *Service) normalizePromptOwner(ctx context.Context, owner *domain.PromptOwner) (*domain.PromptOwner, error) {
+	if owner == nil {
+		return nil, nil
+	}
+	ownerType := strings.ToLower(strings.TrimSpace(owner.Type))
+	id := strings.TrimSpace(owner.ID)
+	if id == "" {
+		return nil, ErrInvalidOwner
+	}
+
+	switch ownerType {
+	case domain.PromptOwnerUser:
+		if s.repos.Users == nil {
+			return nil, ErrInvalidOwner
+		}
+		user, err := s.repos.Users.GetByID(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) && strings.Contains(id, "@") {
+			user, err = s.repos.Users.GetByEmail(ctx, id)
+		}
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, ErrInvalidOwner
+			}
+			return nil, err
+		}
+		return &domain.PromptOwner{Type: domain.PromptOwnerUser, ID: user.ID}, nil
+	case domain.PromptOwnerTeam:
+		if utf8.RuneCountInString(id) > maxTeamOwnerLength {
+			return nil, ErrInvalidOwner
+		}
+		return &domain.PromptOwner{Type: domain.PromptOwnerTeam, ID: id}, nil
+	default:
+		return nil, ErrInvalidOwner
+	}
+}
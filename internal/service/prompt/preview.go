@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	PreviewFormatHTML     = "html"
+	PreviewFormatMarkdown = "markdown"
+)
+
+// variablePattern 匹配 {{ name }} 形式的模板变量。
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.]*)\s*\}\}`)
+
+// htmlTagPattern 用于在后处理时跳过标签本身，只替换文本节点。
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// markdownRenderer 默认不输出原始 HTML，并会过滤 javascript: 等危险链接。
+var markdownRenderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+type PromptVersionPreview struct {
+	PromptID  string         `json:"prompt_id"`
+	Version   VersionSummary `json:"version"`
+	Format    string         `json:"format"`
+	Content   string         `json:"content"`
+	Variables []string       `json:"variables"`
+}
+
+// PreviewPromptVersion 将指定版本正文渲染为可直接展示的内容，变量会被高亮标注。
+func (s *Service) PreviewPromptVersion(ctx context.Context, promptID, versionID, format string) (*PromptVersionPreview, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = PreviewFormatHTML
+	}
+	if format != PreviewFormatHTML && format != PreviewFormatMarkdown {
+		return nil, ErrUnsupportedPreviewFormat
+	}
+
+	version, err := s.getPromptVersion(ctx, promptID, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &PromptVersionPreview{
+		PromptID:  promptID,
+		Version:   summarizeVersion(version),
+		Format:    format,
+		Content:   version.Body,
+		Variables: extractVariables(version.Body),
+	}
+	if format == PreviewFormatHTML {
+		rendered, err := renderMarkdownHTML(version.Body)
+		if err != nil {
+			return nil, err
+		}
+		preview.Content = rendered
+	}
+
+	return preview, nil
+}
+
+func renderMarkdownHTML(body string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(body), &buf); err != nil {
+		return "", err
+	}
+	return highlightVariables(buf.String()), nil
+}
+
+// highlightVariables 仅在文本节点中包裹变量，避免破坏属性值。
+func highlightVariables(rendered string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range htmlTagPattern.FindAllStringIndex(rendered, -1) {
+		out.WriteString(wrapVariables(rendered[last:loc[0]]))
+		out.WriteString(rendered[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	out.WriteString(wrapVariables(rendered[last:]))
+	return out.String()
+}
+
+func wrapVariables(text string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		return `<span class="prompt-variable" data-variable="` + html.EscapeString(name) + `">` + match + `</span>`
+	})
+}
+
+func extractVariables(body string) []string {
+	matches := variablePattern.FindAllStringSubmatch(body, -1)
+	seen := make(map[string]struct{}, len(matches))
+	variables := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		variables = append(variables, match[1])
+	}
+	return variables
+}
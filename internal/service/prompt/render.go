@@ -0,0 +1,115 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/pkg/render"
+)
+
+// RenderPromptInput 定义渲染 Prompt 所需参数，VersionID 为空时使用激活版本。
+// Mode 为空时使用 Prompt 的默认渲染模式，仍未设置则为 lenient。
+type RenderPromptInput struct {
+	PromptID  string
+	VersionID string
+	Locale    string
+	Mode      string
+	Variables map[string]interface{}
+}
+
+// RenderResult 为渲染输出，EngineVersion 标识所用模板引擎版本。
+type RenderResult struct {
+	PromptID      string `json:"prompt_id"`
+	VersionID     string `json:"version_id"`
+	VersionNumber int    `json:"version_number"`
+	Locale        string `json:"locale,omitempty"`
+	Mode          string `json:"mode"`
+	Output        string `json:"output"`
+	EngineVersion string `json:"engine_version"`
+	// Canary 为 true 表示本次未指定版本，按灰度比例选中了灰度版本。
+	Canary bool `json:"canary,omitempty"`
+}
+
+// RenderPrompt 使用模板引擎渲染指定版本（或激活版本），可按语言偏好选择变体。
+func (s *Service) RenderPrompt(ctx context.Context, input RenderPromptInput) (*RenderResult, error) {
+	mode, ok := render.ParseMissingMode(input.Mode)
+	if !ok {
+		return nil, ErrInvalidRenderMode
+	}
+	prompt, err := s.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.Status == domain.PromptStatusArchived {
+		return nil, ErrPromptArchived
+	}
+	if mode == "" && prompt.RenderMode != nil {
+		mode, _ = render.ParseMissingMode(*prompt.RenderMode)
+	}
+	if mode == "" {
+		mode = render.MissingLenient
+	}
+
+	var version *domain.PromptVersion
+	if strings.TrimSpace(input.VersionID) == "" {
+		version, err = s.resolveCanaryVersion(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	canary := version != nil
+	if version == nil {
+		version, err = s.resolveRenderVersion(ctx, prompt, input.VersionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	body := version.Body
+	result := &RenderResult{
+		PromptID:      version.PromptID,
+		VersionID:     version.ID,
+		VersionNumber: version.VersionNumber,
+		Mode:          string(mode),
+		EngineVersion: render.EngineVersion,
+		Canary:        canary,
+	}
+	if strings.TrimSpace(input.Locale) != "" {
+		variant, err := s.ResolveVersionLocale(ctx, version.ID, input.Locale)
+		if err != nil {
+			return nil, err
+		}
+		if variant != nil {
+			body = variant.Body
+			result.Locale = variant.Locale
+		}
+	}
+
+	opts := render.Options{Missing: mode}
+	if mode == render.MissingDefault {
+		opts.Defaults = schemaDefaults(version.VariablesSchema)
+	}
+	output, err := render.Render(body, input.Variables, opts)
+	if err != nil {
+		var missing *render.MissingVariablesError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("%w: %w", ErrMissingVariables, missing)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRenderFailed, err)
+	}
+	result.Output = output
+	return result, nil
+}
+
+func (s *Service) resolveRenderVersion(ctx context.Context, prompt *domain.Prompt, versionID string) (*domain.PromptVersion, error) {
+	if strings.TrimSpace(versionID) != "" {
+		return s.getPromptVersion(ctx, prompt.ID, versionID)
+	}
+	if prompt.ActiveVersionID == nil {
+		return nil, ErrVersionNotFound
+	}
+	return s.getPromptVersion(ctx, prompt.ID, *prompt.ActiveVersionID)
+}
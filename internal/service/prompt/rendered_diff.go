@@ -0,0 +1,88 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+)
+
+// RenderedDiffInput 定义渲染后差异对比参数：两侧版本使用同一组变量、
+// 渲染模式与语言渲染，再对输出文本做 diff。
+type RenderedDiffInput struct {
+	PromptID      string
+	BaseVersionID string
+	Diff          DiffPromptVersionOptions
+	Locale        string
+	Mode          string
+	Variables     map[string]interface{}
+}
+
+// RenderedVersionDiff 为渲染输出的差异结果，附带两侧完整输出便于排查。
+type RenderedVersionDiff struct {
+	PromptID     string         `json:"prompt_id"`
+	Base         VersionSummary `json:"base"`
+	Target       VersionSummary `json:"target"`
+	Mode         string         `json:"mode"`
+	BaseOutput   string         `json:"base_output"`
+	TargetOutput string         `json:"target_output"`
+	Body         []DiffSegment  `json:"body"`
+	Granularity  string         `json:"granularity"`
+	Unified      string         `json:"unified,omitempty"`
+	Changed      bool           `json:"changed"`
+}
+
+// DiffRenderedVersions 使用相同变量渲染两个版本并对比渲染结果，
+// 可发现仅在插值或条件分支展开后才出现的差异。
+func (s *Service) DiffRenderedVersions(ctx context.Context, input RenderedDiffInput) (*RenderedVersionDiff, error) {
+	granularity, err := normalizeDiffGranularity(input.Diff.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := s.getPromptVersion(ctx, input.PromptID, input.BaseVersionID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.resolveDiffTarget(ctx, input.PromptID, base, input.Diff)
+	if err != nil {
+		return nil, err
+	}
+
+	renderVersion := func(versionID string) (*RenderResult, error) {
+		return s.RenderPrompt(ctx, RenderPromptInput{
+			PromptID:  input.PromptID,
+			VersionID: versionID,
+			Locale:    input.Locale,
+			Mode:      input.Mode,
+			Variables: input.Variables,
+		})
+	}
+	baseResult, err := renderVersion(base.ID)
+	if err != nil {
+		return nil, err
+	}
+	targetResult, err := renderVersion(target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RenderedVersionDiff{
+		PromptID:     input.PromptID,
+		Base:         summarizeVersion(base),
+		Target:       summarizeVersion(target),
+		Mode:         baseResult.Mode,
+		BaseOutput:   baseResult.Output,
+		TargetOutput: targetResult.Output,
+		Body:         buildBodyDiff(targetResult.Output, baseResult.Output, granularity),
+		Granularity:  granularity,
+		Changed:      baseResult.Output != targetResult.Output,
+	}
+	if input.Diff.Unified {
+		diff.Unified = buildUnifiedDiff(
+			fmt.Sprintf("v%d (rendered)", target.VersionNumber),
+			fmt.Sprintf("v%d (rendered)", base.VersionNumber),
+			targetResult.Output,
+			baseResult.Output,
+		)
+	}
+	return diff, nil
+}
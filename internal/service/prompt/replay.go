@@ -0,0 +1,199 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+)
+
+// auditActionExecutionReplayed 为回放执行日志时写入的审计动作。
+const auditActionExecutionReplayed = "prompt.execution.replayed"
+
+// 回放对比的内容。
+const (
+	ReplayComparePrompt = "prompt"
+	ReplayCompareOutput = "output"
+)
+
+// WithGateway 注入回放时调用的 LLM 网关，与 Pipeline 使用同一接口；未注入时 Invoke 回放返回 ErrGatewayUnavailable。
+func WithGateway(gateway llm.Gateway) Option {
+	return func(s *Service) {
+		s.gateway = gateway
+	}
+}
+
+// ReplayExecutionInput 定义回放参数。Invoke 为 true 时将重新渲染的 Prompt 发送给网关并对比输出，
+// 否则只重新渲染并与原执行记录的 Prompt 对比。Model 非空时以该模型重新调用，否则沿用原执行记录的模型。
+type ReplayExecutionInput struct {
+	ExecutionID string
+	Invoke      bool
+	Model       string
+	Granularity string
+	Unified     bool
+	ReplayedBy  string
+}
+
+// ExecutionReplay 为回放结果。BaselineRecorded 为 false 表示原执行未记录被对比的内容
+// （request_payload.prompt 或 response_metadata.output），此时不生成差异。
+type ExecutionReplay struct {
+	ExecutionID      string                 `json:"execution_id"`
+	PromptID         string                 `json:"prompt_id"`
+	Version          VersionSummary         `json:"version"`
+	Locale           string                 `json:"locale,omitempty"`
+	Mode             string                 `json:"mode"`
+	Variables        map[string]interface{} `json:"variables"`
+	OriginalPrompt   string                 `json:"original_prompt,omitempty"`
+	Prompt           string                 `json:"prompt"`
+	Invoked          bool                   `json:"invoked"`
+	Model            string                 `json:"model,omitempty"`
+	OriginalOutput   string                 `json:"original_output,omitempty"`
+	Output           string                 `json:"output,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	Compared         string                 `json:"compared"`
+	BaselineRecorded bool                   `json:"baseline_recorded"`
+	Changed          bool                   `json:"changed"`
+	Diff             []DiffSegment          `json:"diff"`
+	Granularity      string                 `json:"granularity"`
+	Unified          string                 `json:"unified,omitempty"`
+	Warnings         []string               `json:"warnings,omitempty"`
+}
+
+// replayPayload 为执行日志中回放所需的字段：request_payload 的 variables（必需）、prompt、locale、mode、model
+// 与 response_metadata 的 output。Pipeline 写入的日志只含 variables，可回放但没有可对比的原始内容。
+type replayPayload struct {
+	Variables map[string]interface{} `json:"variables"`
+	Prompt    string                 `json:"prompt"`
+	Locale    string                 `json:"locale"`
+	Mode      string                 `json:"mode"`
+	Model     string                 `json:"model"`
+}
+
+// ReplayExecution 使用执行日志记录的变量重新渲染原版本，可选地重新调用网关，并返回与原执行的差异，
+// 用于排查模型升级等变化后的回归。回放结果不写入执行日志，避免影响调用统计与告警。
+func (s *Service) ReplayExecution(ctx context.Context, input ReplayExecutionInput) (*ExecutionReplay, error) {
+	granularity, err := normalizeDiffGranularity(input.Granularity)
+	if err != nil {
+		return nil, err
+	}
+	if input.Invoke && s.gateway == nil {
+		return nil, ErrGatewayUnavailable
+	}
+
+	log, err := s.repos.PromptExecutionLog.GetByID(ctx, input.ExecutionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrExecutionNotFound
+		}
+		return nil, err
+	}
+	// 日志按所属 Prompt 的工作区隔离，其他工作区的日志视为不存在。
+	if _, err := s.GetPrompt(ctx, log.PromptID); err != nil {
+		if errors.Is(err, ErrPromptNotFound) {
+			return nil, ErrExecutionNotFound
+		}
+		return nil, err
+	}
+
+	var payload replayPayload
+	if len(log.RequestPayload) > 0 {
+		if err := json.Unmarshal(log.RequestPayload, &payload); err != nil {
+			return nil, fmt.Errorf("%w: request_payload is not a JSON object", ErrExecutionNotReplayable)
+		}
+	}
+	if payload.Variables == nil {
+		return nil, fmt.Errorf("%w: request_payload.variables not recorded", ErrExecutionNotReplayable)
+	}
+
+	rendered, err := s.RenderPrompt(ctx, RenderPromptInput{
+		PromptID:  log.PromptID,
+		VersionID: log.PromptVersionID,
+		Locale:    payload.Locale,
+		Mode:      payload.Mode,
+		Variables: payload.Variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.getPromptVersion(ctx, log.PromptID, log.PromptVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &ExecutionReplay{
+		ExecutionID:    log.ID,
+		PromptID:       log.PromptID,
+		Version:        summarizeVersion(version),
+		Locale:         rendered.Locale,
+		Mode:           rendered.Mode,
+		Variables:      payload.Variables,
+		OriginalPrompt: payload.Prompt,
+		Prompt:         rendered.Output,
+		OriginalOutput: recordedOutput(log.ResponseMetadata),
+		Compared:       ReplayComparePrompt,
+		Granularity:    granularity,
+	}
+	original, current := replay.OriginalPrompt, replay.Prompt
+	if input.Invoke {
+		replay.Model = payload.Model
+		if input.Model != "" {
+			replay.Model = input.Model
+		}
+		if warning := s.models.CompatibilityWarning(replay.Model, version.TargetModels); warning != "" {
+			replay.Warnings = append(replay.Warnings, warning)
+		}
+		response, err := s.gateway.Complete(ctx, llm.CompletionRequest{
+			PromptID:        log.PromptID,
+			PromptVersionID: version.ID,
+			Prompt:          rendered.Output,
+			Variables:       payload.Variables,
+			Model:           replay.Model,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrGatewayFailed, err)
+		}
+		replay.Invoked = true
+		replay.Output = response.Output
+		replay.Metadata = response.Metadata
+		replay.Compared = ReplayCompareOutput
+		original, current = replay.OriginalOutput, replay.Output
+	}
+
+	replay.BaselineRecorded = original != ""
+	replay.Diff = []DiffSegment{}
+	if replay.BaselineRecorded {
+		replay.Changed = original != current
+		replay.Diff = buildBodyDiff(original, current, granularity)
+		if input.Unified {
+			replay.Unified = buildUnifiedDiff("original "+replay.Compared, "replayed "+replay.Compared, original, current)
+		}
+	}
+
+	if err := s.recordAudit(ctx, log.PromptID, auditActionExecutionReplayed, input.ReplayedBy, map[string]interface{}{
+		"execution_id": log.ID,
+		"version_id":   version.ID,
+		"invoked":      replay.Invoked,
+		"model":        replay.Model,
+		"changed":      replay.Changed,
+	}); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// recordedOutput 读取 response_metadata.output，未记录时返回空串。
+func recordedOutput(metadata json.RawMessage) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+	var parsed struct {
+		Output string `json:"output"`
+	}
+	if err := json.Unmarshal(metadata, &parsed); err != nil {
+		return ""
+	}
+	return parsed.Output
+}
@@ -0,0 +1,159 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+)
+
+// auditActionVersionsPruned 为保留策略删除历史版本时写入的审计动作。
+const auditActionVersionsPruned = "prompt.versions.pruned"
+
+// WithMaxVersions 设置每个 Prompt 保留的版本上限，<=0 表示不限制。
+func WithMaxVersions(limit int) Option {
+	return func(s *Service) {
+		s.maxVersions = limit
+	}
+}
+
+// PruneVersions 为保留策略任务入口：遍历所有 Prompt，删除超出版本上限的历史版本。
+func (s *Service) PruneVersions(ctx context.Context) error {
+	if s.maxVersions <= 0 {
+		return nil
+	}
+	var errs []error
+	for offset := 0; ; offset += exportPageSize {
+		prompts, err := s.repos.Prompts.List(ctx, domain.PromptListOptions{Limit: exportPageSize, Offset: offset, IncludeDeleted: true, IncludeArchived: true})
+		if err != nil {
+			return err
+		}
+		for _, prompt := range prompts {
+			if _, err := s.PrunePromptVersions(ctx, prompt.ID); err != nil {
+				errs = append(errs, fmt.Errorf("prune prompt %s: %w", prompt.ID, err))
+			}
+		}
+		if len(prompts) < exportPageSize {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PrunePromptVersions 按版本上限删除单个 Prompt 最旧的历史版本，返回删除的版本。
+// 当前激活版本、曾经激活过的版本以及最新版本始终保留，因此保留数可能超过上限。
+func (s *Service) PrunePromptVersions(ctx context.Context, promptID string) ([]*domain.PromptVersion, error) {
+	if s.maxVersions <= 0 {
+		return nil, nil
+	}
+	total, err := s.repos.PromptVersions.CountByPrompt(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	excess := int(total) - s.maxVersions
+	if excess <= 0 {
+		return nil, nil
+	}
+
+	prompt, err := s.repos.Prompts.GetByIDIncludeDeleted(ctx, promptID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+	protected, err := s.activatedVersionIDs(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.ActiveVersionID != nil {
+		protected[*prompt.ActiveVersionID] = struct{}{}
+	}
+
+	versions, err := s.listAllVersions(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) > 0 {
+		// 保留最新版本，避免版本号被后续创建的版本复用。
+		protected[versions[len(versions)-1].ID] = struct{}{}
+	}
+
+	var pruned []*domain.PromptVersion
+	ids := make([]string, 0, excess)
+	for _, version := range versions {
+		if len(pruned) == excess {
+			break
+		}
+		if _, ok := protected[version.ID]; ok {
+			continue
+		}
+		pruned = append(pruned, version)
+		ids = append(ids, version.ID)
+	}
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.repos.PromptVersions.DeleteByIDs(ctx, promptID, ids); err != nil {
+		return nil, err
+	}
+
+	if s.repos.PromptAuditLog != nil {
+		numbers := make([]int, 0, len(pruned))
+		for _, version := range pruned {
+			numbers = append(numbers, version.VersionNumber)
+		}
+		payload, err := json.Marshal(map[string]interface{}{
+			"version_ids":     ids,
+			"version_numbers": numbers,
+			"max_versions":    s.maxVersions,
+		})
+		if err != nil {
+			return nil, err
+		}
+		audit := &domain.PromptAuditLog{
+			ID:       uuid.NewString(),
+			PromptID: promptID,
+			Action:   auditActionVersionsPruned,
+			Payload:  payload,
+		}
+		if err := s.repos.PromptAuditLog.Create(ctx, audit); err != nil {
+			return nil, err
+		}
+	}
+	s.publishEvent(ctx, events.VersionsPruned, prompt)
+	return pruned, nil
+}
+
+// activatedVersionIDs 从激活审计中收集曾经激活过的版本。
+func (s *Service) activatedVersionIDs(ctx context.Context, promptID string) (map[string]struct{}, error) {
+	ids := make(map[string]struct{})
+	if s.repos.PromptAuditLog == nil {
+		return ids, nil
+	}
+	err := s.IterateAuditLogs(ctx, domain.AuditLogIterateOptions{PromptID: promptID}, func(log *domain.PromptAuditLog) error {
+		if log.Action != auditActionVersionActivated || len(log.Payload) == 0 {
+			return nil
+		}
+		var payload struct {
+			VersionID string `json:"version_id"`
+		}
+		if err := json.Unmarshal(log.Payload, &payload); err != nil {
+			return err
+		}
+		if payload.VersionID != "" {
+			ids[payload.VersionID] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
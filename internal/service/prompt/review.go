@@ -0,0 +1,348 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// maxRequiredApprovals 限制单个 Prompt 可要求的批准数。
+const maxRequiredApprovals = 10
+
+// TeamMembership 判断用户是否属于某个团队（如 org/team-slug），用于团队负责人与团队评审人的校验。
+type TeamMembership interface {
+	IsTeamMember(ctx context.Context, userID, team string) (bool, error)
+}
+
+// WithTeamMembership 注入团队成员关系来源；未注入时无法配置团队评审人，团队负责人只能由管理员代为管理。
+func WithTeamMembership(membership TeamMembership) Option {
+	return func(s *Service) {
+		s.teamMembership = membership
+	}
+}
+
+// ReviewActor 描述执行评审相关操作的用户。
+type ReviewActor struct {
+	UserID  string
+	Actor   string
+	IsAdmin bool
+}
+
+// SetReviewPolicyInput 定义评审策略，Reviewers 为空表示任何可写成员都可评审。
+type SetReviewPolicyInput struct {
+	PromptID          string
+	RequiredApprovals int
+	Reviewers         []domain.PromptOwner
+	By                ReviewActor
+}
+
+// ApproveVersionInput 定义一次版本批准。
+type ApproveVersionInput struct {
+	PromptID  string
+	VersionID string
+	By        ReviewActor
+	Comment   string
+}
+
+// VersionReview 汇总版本的评审进度，ValidApprovals 只统计当前策略下仍合格的批准。
+type VersionReview struct {
+	PromptID          string                          `json:"prompt_id"`
+	VersionID         string                          `json:"version_id"`
+	Policy            *domain.PromptReviewPolicy      `json:"policy,omitempty"`
+	Approvals         []*domain.PromptVersionApproval `json:"approvals"`
+	RequiredApprovals int                             `json:"required_approvals"`
+	ValidApprovals    int                             `json:"valid_approvals"`
+	Approved          bool                            `json:"approved"`
+}
+
+// ReviewRequiredError 表示版本批准数不足，Review 为当前评审进度。
+type ReviewRequiredError struct {
+	Review *VersionReview
+}
+
+func (e *ReviewRequiredError) Error() string {
+	return fmt.Sprintf("version requires %d approvals, has %d", e.Review.RequiredApprovals, e.Review.ValidApprovals)
+}
+
+// Unwrap 使 errors.Is(err, ErrReviewRequired) 成立。
+func (e *ReviewRequiredError) Unwrap() error {
+	return ErrReviewRequired
+}
+
+// GetReviewPolicy 返回 Prompt 的评审策略，未配置时返回 ErrReviewPolicyNotFound。
+func (s *Service) GetReviewPolicy(ctx context.Context, promptID string) (*domain.PromptReviewPolicy, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+	policy, err := s.loadReviewPolicy(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, ErrReviewPolicyNotFound
+	}
+	return policy, nil
+}
+
+// SetReviewPolicy 创建或覆盖评审策略，仅 Prompt 负责人（未设置负责人时为任意可写成员）或管理员可操作。
+func (s *Service) SetReviewPolicy(ctx context.Context, input SetReviewPolicyInput) (*domain.PromptReviewPolicy, error) {
+	prompt, err := s.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requirePromptManager(ctx, prompt, input.By); err != nil {
+		return nil, err
+	}
+	if input.RequiredApprovals < 1 || input.RequiredApprovals > maxRequiredApprovals {
+		return nil, fmt.Errorf("%w: required_approvals must be between 1 and %d", ErrInvalidReviewPolicy, maxRequiredApprovals)
+	}
+
+	reviewers := make([]domain.PromptOwner, 0, len(input.Reviewers))
+	seen := make(map[domain.PromptOwner]struct{}, len(input.Reviewers))
+	users, teams := 0, 0
+	for i := range input.Reviewers {
+		reviewer, err := s.normalizePromptOwner(ctx, &input.Reviewers[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: reviewer %q must be an existing user or a team", ErrInvalidReviewPolicy, input.Reviewers[i].ID)
+		}
+		if _, ok := seen[*reviewer]; ok {
+			continue
+		}
+		seen[*reviewer] = struct{}{}
+		if reviewer.Type == domain.PromptOwnerTeam {
+			if s.teamMembership == nil {
+				return nil, fmt.Errorf("%w: team reviewers require a team membership source", ErrInvalidReviewPolicy)
+			}
+			teams++
+		} else {
+			users++
+		}
+		reviewers = append(reviewers, *reviewer)
+	}
+	if teams == 0 && users > 0 && users < input.RequiredApprovals {
+		return nil, fmt.Errorf("%w: %d reviewers cannot satisfy %d required approvals", ErrInvalidReviewPolicy, users, input.RequiredApprovals)
+	}
+
+	policy := &domain.PromptReviewPolicy{
+		PromptID:          prompt.ID,
+		RequiredApprovals: input.RequiredApprovals,
+		Reviewers:         reviewers,
+		UpdatedBy:         optionalString(input.By.Actor),
+	}
+	if err := s.repos.PromptReviews.SavePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	if err := s.recordAudit(ctx, prompt.ID, "prompt.review_policy.updated", input.By.Actor, map[string]interface{}{
+		"required_approvals": policy.RequiredApprovals,
+		"reviewers":          policy.Reviewers,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetReviewPolicy(ctx, prompt.ID)
+}
+
+// DeleteReviewPolicy 移除评审策略，之后激活不再要求批准。
+func (s *Service) DeleteReviewPolicy(ctx context.Context, promptID string, by ReviewActor) error {
+	prompt, err := s.GetPrompt(ctx, promptID)
+	if err != nil {
+		return err
+	}
+	if err := s.requirePromptManager(ctx, prompt, by); err != nil {
+		return err
+	}
+	if err := s.repos.PromptReviews.DeletePolicy(ctx, promptID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrReviewPolicyNotFound
+		}
+		return err
+	}
+	return s.recordAudit(ctx, promptID, "prompt.review_policy.deleted", by.Actor, map[string]interface{}{})
+}
+
+// ApproveVersion 记录当前用户对版本的批准；作者不能批准自己的版本，配置了评审人时只接受名单内的批准。
+func (s *Service) ApproveVersion(ctx context.Context, input ApproveVersionInput) (*VersionReview, error) {
+	if strings.TrimSpace(input.By.UserID) == "" {
+		return nil, ErrInvalidReview
+	}
+	version, err := s.getPromptVersion(ctx, input.PromptID, input.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	if version.CreatedBy != nil && (*version.CreatedBy == input.By.UserID || *version.CreatedBy == input.By.Actor) {
+		return nil, ErrSelfApproval
+	}
+	policy, err := s.loadReviewPolicy(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	eligible, err := s.isEligibleReviewer(ctx, policy, input.By.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !eligible {
+		return nil, ErrNotEligibleReviewer
+	}
+
+	approval := &domain.PromptVersionApproval{
+		VersionID:  version.ID,
+		PromptID:   version.PromptID,
+		ReviewerID: input.By.UserID,
+		Reviewer:   optionalString(input.By.Actor),
+		Comment:    optionalString(input.Comment),
+	}
+	if err := s.repos.PromptReviews.Approve(ctx, approval); err != nil {
+		return nil, err
+	}
+	if err := s.recordAudit(ctx, version.PromptID, "prompt.version.approved", input.By.Actor, map[string]interface{}{
+		"version_id":     version.ID,
+		"version_number": version.VersionNumber,
+	}); err != nil {
+		return nil, err
+	}
+	return s.reviewVersion(ctx, version.PromptID, version.ID, policy)
+}
+
+// RevokeApproval 撤回当前用户对版本的批准。
+func (s *Service) RevokeApproval(ctx context.Context, promptID, versionID string, by ReviewActor) (*VersionReview, error) {
+	version, err := s.getPromptVersion(ctx, promptID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repos.PromptReviews.RevokeApproval(ctx, version.ID, by.UserID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrApprovalNotFound
+		}
+		return nil, err
+	}
+	if err := s.recordAudit(ctx, promptID, "prompt.version.approval_revoked", by.Actor, map[string]interface{}{
+		"version_id":     version.ID,
+		"version_number": version.VersionNumber,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetVersionReview(ctx, promptID, versionID)
+}
+
+// GetVersionReview 返回版本的批准列表与是否满足当前评审策略。
+func (s *Service) GetVersionReview(ctx context.Context, promptID, versionID string) (*VersionReview, error) {
+	version, err := s.getPromptVersion(ctx, promptID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.loadReviewPolicy(ctx, promptID)
+	if err != nil {
+		return nil, err
+	}
+	return s.reviewVersion(ctx, promptID, version.ID, policy)
+}
+
+// checkReviewRequirement 在激活前校验评审策略，批准不足时返回 *ReviewRequiredError。
+func (s *Service) checkReviewRequirement(ctx context.Context, promptID, versionID string) error {
+	policy, err := s.loadReviewPolicy(ctx, promptID)
+	if err != nil || policy == nil {
+		return err
+	}
+	review, err := s.reviewVersion(ctx, promptID, versionID, policy)
+	if err != nil {
+		return err
+	}
+	if !review.Approved {
+		return &ReviewRequiredError{Review: review}
+	}
+	return nil
+}
+
+func (s *Service) reviewVersion(ctx context.Context, promptID, versionID string, policy *domain.PromptReviewPolicy) (*VersionReview, error) {
+	approvals, err := s.repos.PromptReviews.ListApprovals(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if approvals == nil {
+		approvals = []*domain.PromptVersionApproval{}
+	}
+	review := &VersionReview{
+		PromptID:  promptID,
+		VersionID: versionID,
+		Policy:    policy,
+		Approvals: approvals,
+	}
+	for _, approval := range approvals {
+		eligible, err := s.isEligibleReviewer(ctx, policy, approval.ReviewerID)
+		if err != nil {
+			return nil, err
+		}
+		if eligible {
+			review.ValidApprovals++
+		}
+	}
+	if policy != nil {
+		review.RequiredApprovals = policy.RequiredApprovals
+	}
+	review.Approved = review.ValidApprovals >= review.RequiredApprovals
+	return review, nil
+}
+
+// loadReviewPolicy 读取评审策略，未配置时返回 nil。
+func (s *Service) loadReviewPolicy(ctx context.Context, promptID string) (*domain.PromptReviewPolicy, error) {
+	if s.repos.PromptReviews == nil {
+		return nil, nil
+	}
+	policy, err := s.repos.PromptReviews.GetPolicy(ctx, promptID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return policy, err
+}
+
+func (s *Service) isEligibleReviewer(ctx context.Context, policy *domain.PromptReviewPolicy, userID string) (bool, error) {
+	if policy == nil || len(policy.Reviewers) == 0 {
+		return true, nil
+	}
+	for _, reviewer := range policy.Reviewers {
+		switch reviewer.Type {
+		case domain.PromptOwnerUser:
+			if reviewer.ID == userID {
+				return true, nil
+			}
+		case domain.PromptOwnerTeam:
+			if s.teamMembership == nil {
+				continue
+			}
+			member, err := s.teamMembership.IsTeamMember(ctx, userID, reviewer.ID)
+			if err != nil {
+				return false, err
+			}
+			if member {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// requirePromptManager 校验操作者能否管理 Prompt 的评审设置：管理员、负责人本人或负责团队成员；未设置负责人时不限制。
+func (s *Service) requirePromptManager(ctx context.Context, prompt *domain.Prompt, by ReviewActor) error {
+	if by.IsAdmin || prompt.Owner == nil {
+		return nil
+	}
+	switch prompt.Owner.Type {
+	case domain.PromptOwnerUser:
+		if prompt.Owner.ID == by.UserID {
+			return nil
+		}
+	case domain.PromptOwnerTeam:
+		if s.teamMembership != nil {
+			member, err := s.teamMembership.IsTeamMember(ctx, by.UserID, prompt.Owner.ID)
+			if err != nil {
+				return err
+			}
+			if member {
+				return nil
+			}
+		}
+	}
+	return ErrNotPromptOwner
+}
@@ -0,0 +1,37 @@
+package prompt
+
+import "encoding/json"
+
+// schemaDefaults 从 variables_schema 中提取变量默认值，兼容两种结构：
+// JSON Schema 风格 {"properties": {"name": {"default": ...}}}，
+// 以及列表风格 {"vars": [{"name": "name", "default": ...}]}。
+func schemaDefaults(raw json.RawMessage) map[string]interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Default interface{} `json:"default"`
+		} `json:"properties"`
+		Vars []struct {
+			Name    string      `json:"name"`
+			Default interface{} `json:"default"`
+		} `json:"vars"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil
+	}
+
+	defaults := make(map[string]interface{})
+	for name, property := range schema.Properties {
+		if property.Default != nil {
+			defaults[name] = property.Default
+		}
+	}
+	for _, variable := range schema.Vars {
+		if variable.Name != "" && variable.Default != nil {
+			defaults[variable.Name] = variable.Default
+		}
+	}
+	return defaults
+}
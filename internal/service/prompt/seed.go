@@ -0,0 +1,71 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// SeedOptions 控制启动时从种子目录加载 Prompt 的行为。
+type SeedOptions struct {
+	CreatedBy string
+}
+
+// SeedPrompts 读取 fsys 中的 .yaml/.yml/.json Prompt 文档（与导入导出格式一致）并逐个创建，
+// 已存在的同名 Prompt 会被跳过，因此可在每次启动时重复执行。单个文件失败只记入报告，
+// 目录本身无法读取时返回错误。
+func (s *Service) SeedPrompts(ctx context.Context, fsys fs.FS, opts SeedOptions) (*ImportReport, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isArchivePromptFile(name) {
+			return nil
+		}
+		files = append(files, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read seed directory: %w", err)
+	}
+	// 按路径排序，保证依赖其他 Prompt 的种子（如模板引用）加载顺序可预期。
+	sort.Strings(files)
+
+	importOpts := ImportOptions{OnConflict: ImportConflictSkip, CreatedBy: opts.CreatedBy}
+	report := &ImportReport{Items: []*ImportItem{}}
+	for _, name := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item := &ImportItem{File: name}
+		doc, err := readSeedDocument(fsys, name)
+		if err != nil {
+			item.Status = ImportItemFailed
+			item.Error = err.Error()
+			report.add(item)
+			continue
+		}
+		report.add(s.importDocument(ctx, item, doc, importOpts))
+	}
+	return report, nil
+}
+
+func readSeedDocument(fsys fs.FS, name string) (*PromptDocument, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, archiveMaxEntryBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > archiveMaxEntryBytes {
+		return nil, fmt.Errorf("file exceeds %d bytes", archiveMaxEntryBytes)
+	}
+	return parsePromptDocument(name, content)
+}
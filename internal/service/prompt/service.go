@@ -4,21 +4,49 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+	"github.com/zacharykka/prompt-manager/pkg/render"
 )
 
 // Service 提供 Prompt 领域相关操作。
 type Service struct {
-	repos *domain.Repositories
+	repos              *domain.Repositories
+	alertNotifier      AlertNotifier
+	activationNotifier ActivationNotifier
+	activationWebhook  string
+	requireReleaseNote bool
+	requirePublished   bool
+	canaryRoll         func(n int) int
+	changeFreeze       ChangeFreeze
+	events             *events.Bus
+	editLocks          domain.EditLockStore
+	editLockTTL        time.Duration
+	maxVersions        int
+	maxBodyBytes       int
+	stripInvisible     bool
+	gateway            llm.Gateway
+	models             *llm.Registry
+	teamMembership     TeamMembership
+	httpClient         *http.Client
 }
 
+// Option 定义 Prompt 服务的可选配置。
+type Option func(*Service)
+
 // NewService 创建 Prompt 服务实例。
-func NewService(repos *domain.Repositories) *Service {
-	return &Service{repos: repos}
+func NewService(repos *domain.Repositories, opts ...Option) *Service {
+	svc := &Service{repos: repos, httpClient: &http.Client{Timeout: 10 * time.Second}}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
 }
 
 // CreatePromptInput 定义创建 Prompt 所需的字段。
@@ -27,6 +55,8 @@ type CreatePromptInput struct {
 	Description *string
 	Tags        []string
 	CreatedBy   string
+	// Owner 为负责人，可为空；用户负责人可传用户 ID 或邮箱。
+	Owner *domain.PromptOwner
 }
 
 // UpdatePromptInput 定义更新 Prompt 所需的可选字段。
@@ -35,6 +65,8 @@ type UpdatePromptInput struct {
 	Name        *string
 	Description *string
 	Tags        *[]string
+	// RenderMode 为空字符串时清除 Prompt 的默认渲染模式。
+	RenderMode *string
 }
 
 // CreatePrompt 创建新的 Prompt 记录。
@@ -60,6 +92,10 @@ func (s *Service) CreatePrompt(ctx context.Context, input CreatePromptInput) (*d
 
 	createdBy := optionalString(input.CreatedBy)
 	description := optionalTrimmedString(input.Description)
+	owner, err := s.normalizePromptOwner(ctx, input.Owner)
+	if err != nil {
+		return nil, err
+	}
 
 	var created *domain.Prompt
 
@@ -81,6 +117,9 @@ func (s *Service) CreatePrompt(ctx context.Context, input CreatePromptInput) (*d
 		if err := s.repos.Prompts.Restore(ctx, existing.ID, restoreParams); err != nil {
 			return nil, err
 		}
+		if err := s.repos.Prompts.UpdateOwner(ctx, existing.ID, owner); err != nil {
+			return nil, err
+		}
 
 		restored, err := s.repos.Prompts.GetByID(ctx, existing.ID)
 		if err != nil {
@@ -105,6 +144,7 @@ func (s *Service) CreatePrompt(ctx context.Context, input CreatePromptInput) (*d
 			Name:      name,
 			Tags:      tagsJSON,
 			CreatedBy: createdBy,
+			Owner:     owner,
 		}
 		prompt.Description = description
 
@@ -136,6 +176,7 @@ func (s *Service) CreatePrompt(ctx context.Context, input CreatePromptInput) (*d
 		return nil, ErrPromptNotFound
 	}
 
+	s.publishEvent(ctx, events.PromptCreated, created)
 	return created, nil
 }
 
@@ -146,28 +187,162 @@ type ListPromptsOptions struct {
 	Offset         int
 	Search         string
 	IncludeDeleted bool
+	// IncludeArchived 同时返回已归档的 Prompt，ArchivedOnly 只返回已归档的 Prompt。
+	IncludeArchived bool
+	ArchivedOnly    bool
+	// CreatedBy 按创建人过滤，可传邮箱或用户 ID。
+	CreatedBy string
+	// CountMode 控制总数的计算方式，为空时等同 PromptCountExact。
+	CountMode string
+	// OmitBody 为 true 时仓储不读取正文列，适用于不展示正文的列表视图。
+	OmitBody bool
+	// Model 按激活版本声明的目标模型过滤，模型 ID 同时匹配其所属系列，系列同时匹配其下模型。
+	Model string
+}
+
+// defaultPromptListLimit 与仓储层未指定 limit 时的默认值一致。
+const defaultPromptListLimit = 50
+
+const (
+	// PromptCountExact 执行 COUNT 返回精确总数。
+	PromptCountExact = "exact"
+	// PromptCountEstimated 在无筛选条件时使用数据库统计估计总数，不支持时回退为精确计数。
+	PromptCountEstimated = "estimated"
+	// PromptCountNone 不计算总数，多取一条判断是否还有下一页。
+	PromptCountNone = "none"
+)
+
+// PromptPage 为一页 Prompt 列表；CountMode 为 none 时 Total 为 nil。
+type PromptPage struct {
+	Items          []*domain.Prompt
+	Total          *int64
+	TotalEstimated bool
+	HasMore        bool
 }
 
-// ListPrompts 返回 Prompt 列表及总数。
+// ListPrompts 返回 Prompt 列表及精确总数。
 func (s *Service) ListPrompts(ctx context.Context, opts ListPromptsOptions) ([]*domain.Prompt, int64, error) {
+	opts.CountMode = PromptCountExact
+	page, err := s.ListPromptsPage(ctx, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return page.Items, *page.Total, nil
+}
+
+// ListPromptsPage 按 CountMode 返回一页 Prompt。大表上 COUNT 开销较高，
+// 列表只需翻页时可用 none 省去计数，或用 estimated 获取近似总数。
+func (s *Service) ListPromptsPage(ctx context.Context, opts ListPromptsOptions) (*PromptPage, error) {
+	switch opts.CountMode {
+	case "":
+		opts.CountMode = PromptCountExact
+	case PromptCountExact, PromptCountEstimated, PromptCountNone:
+	default:
+		return nil, ErrInvalidCountMode
+	}
+
 	repoOpts := domain.PromptListOptions{
-		Limit:          opts.Limit,
-		Offset:         opts.Offset,
-		Search:         strings.TrimSpace(opts.Search),
-		IncludeDeleted: opts.IncludeDeleted,
+		Limit:           opts.Limit,
+		Offset:          opts.Offset,
+		Search:          strings.TrimSpace(opts.Search),
+		IncludeDeleted:  opts.IncludeDeleted,
+		IncludeArchived: opts.IncludeArchived,
+		ArchivedOnly:    opts.ArchivedOnly,
+		OmitBody:        opts.OmitBody,
+	}
+	if creator := strings.TrimSpace(opts.CreatedBy); creator != "" {
+		creators, err := s.creatorIdentifiers(ctx, creator)
+		if err != nil {
+			return nil, err
+		}
+		repoOpts.CreatedBy = creators
+	}
+	targets, err := s.modelFilter(opts.Model)
+	if err != nil {
+		return nil, err
 	}
+	repoOpts.TargetModels = targets
 
+	if opts.CountMode == PromptCountExact {
+		prompts, err := s.repos.Prompts.List(ctx, repoOpts)
+		if err != nil {
+			return nil, err
+		}
+		total, err := s.repos.Prompts.Count(ctx, repoOpts)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.flagDeprecatedModels(ctx, prompts); err != nil {
+			return nil, err
+		}
+		offset := int64(max(repoOpts.Offset, 0))
+		return &PromptPage{Items: prompts, Total: &total, HasMore: offset+int64(len(prompts)) < total}, nil
+	}
+
+	// 非精确模式多取一条判断是否还有下一页，不依赖（可能不准确的）总数。
+	limit := repoOpts.Limit
+	if limit <= 0 {
+		limit = defaultPromptListLimit
+	}
+	repoOpts.Limit = limit + 1
 	prompts, err := s.repos.Prompts.List(ctx, repoOpts)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	page := &PromptPage{Items: prompts}
+	if len(prompts) > limit {
+		page.Items = prompts[:limit]
+		page.HasMore = true
+	}
+	if err := s.flagDeprecatedModels(ctx, page.Items); err != nil {
+		return nil, err
+	}
+	if opts.CountMode == PromptCountNone {
+		return page, nil
 	}
 
+	// 估计值覆盖整张表，只有未按名称、创建人或模型筛选时才近似等于列表总数，否则回退为精确计数。
+	if repoOpts.Search == "" && len(repoOpts.CreatedBy) == 0 && len(repoOpts.TargetModels) == 0 {
+		estimate, ok, err := s.repos.Prompts.EstimateCount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			page.Total = &estimate
+			page.TotalEstimated = true
+			return page, nil
+		}
+	}
 	total, err := s.repos.Prompts.Count(ctx, repoOpts)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
+	page.Total = &total
+	return page, nil
+}
 
-	return prompts, total, nil
+// creatorIdentifiers 返回创建人的全部标识。created_by 通常记录邮箱，缺失邮箱时记录用户 ID，
+// 因此查到对应用户时同时匹配两者；用户不存在时按原值匹配。
+func (s *Service) creatorIdentifiers(ctx context.Context, creator string) ([]string, error) {
+	if s.repos.Users == nil {
+		return []string{creator}, nil
+	}
+	var (
+		user *domain.User
+		err  error
+	)
+	if strings.Contains(creator, "@") {
+		user, err = s.repos.Users.GetByEmail(ctx, strings.ToLower(creator))
+	} else {
+		user, err = s.repos.Users.GetByID(ctx, creator)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return []string{creator}, nil
+		}
+		return nil, err
+	}
+	return []string{user.Email, user.ID}, nil
 }
 
 // UpdatePrompt 更新 Prompt 元数据。
@@ -200,7 +375,19 @@ func (s *Service) UpdatePrompt(ctx context.Context, input UpdatePromptInput) (*d
 		}
 	}
 
-	if !updates.HasName && !updates.HasDescription && !updates.HasTags {
+	if input.RenderMode != nil {
+		mode, ok := render.ParseMissingMode(*input.RenderMode)
+		if !ok {
+			return nil, ErrInvalidRenderMode
+		}
+		updates.HasRenderMode = true
+		if mode != "" {
+			value := string(mode)
+			updates.RenderMode = &value
+		}
+	}
+
+	if !updates.HasName && !updates.HasDescription && !updates.HasTags && !updates.HasRenderMode {
 		return nil, ErrNoFieldsToUpdate
 	}
 
@@ -214,7 +401,12 @@ func (s *Service) UpdatePrompt(ctx context.Context, input UpdatePromptInput) (*d
 		return nil, err
 	}
 
-	return s.GetPrompt(ctx, input.PromptID)
+	updated, err := s.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	s.publishEvent(ctx, events.PromptUpdated, updated)
+	return updated, nil
 }
 
 // GetPrompt 根据 ID 获取 Prompt。
@@ -229,6 +421,18 @@ func (s *Service) GetPrompt(ctx context.Context, promptID string) (*domain.Promp
 	return prompt, nil
 }
 
+// GetPromptByName 按名称获取当前工作区中未删除的 Prompt。
+func (s *Service) GetPromptByName(ctx context.Context, name string) (*domain.Prompt, error) {
+	prompt, err := s.repos.Prompts.GetByName(ctx, strings.TrimSpace(name), false)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrPromptNotFound
+		}
+		return nil, err
+	}
+	return prompt, nil
+}
+
 // CreatePromptVersionInput 定义创建 Prompt 版本所需字段。
 type CreatePromptVersionInput struct {
 	PromptID        string
@@ -238,19 +442,25 @@ type CreatePromptVersionInput struct {
 	Status          string
 	CreatedBy       string
 	Activate        bool
+	// AllowDuplicate 为 true 时即使正文与变量 Schema 与最新版本完全一致也创建新版本。
+	AllowDuplicate bool
 }
 
 // CreatePromptVersion 创建新的 Prompt 版本记录。
+// 默认拒绝与最新版本正文、变量 Schema 逐字节相同的空操作版本，返回 *DuplicateVersionError。
 func (s *Service) CreatePromptVersion(ctx context.Context, input CreatePromptVersionInput) (*domain.PromptVersion, error) {
 	prompt, err := s.GetPrompt(ctx, input.PromptID)
 	if err != nil {
 		return nil, err
 	}
 
-	body := strings.TrimSpace(input.Body)
+	body := strings.TrimSpace(s.normalizeBody(input.Body))
 	if body == "" {
 		return nil, ErrBodyRequired
 	}
+	if err := s.checkBodySize(body); err != nil {
+		return nil, err
+	}
 
 	latest, err := s.repos.PromptVersions.GetLatestVersionNumber(ctx, prompt.ID)
 	if err != nil {
@@ -280,6 +490,15 @@ func (s *Service) CreatePromptVersion(ctx context.Context, input CreatePromptVer
 		}
 		version.Metadata = data
 	}
+	if version.TargetModels, err = s.targetModels(version.Metadata); err != nil {
+		return nil, err
+	}
+
+	if !input.AllowDuplicate && latest > 0 {
+		if err := s.checkDuplicateVersion(ctx, version); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.repos.PromptVersions.Create(ctx, version); err != nil {
 		return nil, err
@@ -321,6 +540,7 @@ func (s *Service) CreatePromptVersion(ctx context.Context, input CreatePromptVer
 			return nil, err
 		}
 	}
+	s.publishEvent(ctx, events.VersionCreated, prompt)
 	return created, nil
 }
 
@@ -399,68 +619,38 @@ func (s *Service) ListPromptVersionsEx(ctx context.Context, promptID string, lim
 
 // SetActiveVersion 将指定版本设为当前启用版本。
 func (s *Service) SetActiveVersion(ctx context.Context, promptID, versionID, activatedBy string) error {
-	prompt, err := s.GetPrompt(ctx, promptID)
+	event, err := s.activateVersion(ctx, promptID, versionID, activatedBy, "")
 	if err != nil {
 		return err
 	}
+	// 内联激活等内部流程的通知为尽力而为，失败不影响激活结果。
+	_ = s.notifyActivation(ctx, *event)
+	return nil
+}
 
-	version, err := s.repos.PromptVersions.GetByID(ctx, versionID)
-	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return ErrVersionNotFound
-		}
-		return err
+// ListExecutionLogs 返回最近的执行日志。
+func (s *Service) ListExecutionLogs(ctx context.Context, promptID string, limit int) ([]*domain.PromptExecutionLog, error) {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
 	}
+	return s.repos.PromptExecutionLog.ListRecent(ctx, promptID, limit)
+}
 
-	body := version.Body
-	if err := s.repos.Prompts.UpdateActiveVersion(ctx, promptID, &versionID, &body); err != nil {
+// IterateVersions 按版本号倒序逐个回调 Prompt 的全部版本，status 非空时按状态过滤，用于流式输出。
+func (s *Service) IterateVersions(ctx context.Context, promptID string, status string, fn func(*domain.PromptVersion) error) error {
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
 		return err
 	}
-
-	if s.repos.PromptAuditLog != nil {
-		payloadData := map[string]interface{}{
-			"version_id":     version.ID,
-			"version_number": version.VersionNumber,
-		}
-		if prompt.ActiveVersionID != nil {
-			payloadData["previous_version_id"] = *prompt.ActiveVersionID
-		}
-		payload, err := json.Marshal(payloadData)
-		if err != nil {
-			return err
-		}
-		actor := optionalString(activatedBy)
-		audit := &domain.PromptAuditLog{
-			ID:        uuid.NewString(),
-			PromptID:  promptID,
-			Action:    "prompt.version.activated",
-			Payload:   payload,
-			CreatedBy: actor,
-		}
-		if err := s.repos.PromptAuditLog.Create(ctx, audit); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return s.repos.PromptVersions.IterateByPrompt(ctx, promptID, strings.TrimSpace(status), fn)
 }
 
-// GetExecutionStats 返回最近若干天的执行统计。
-func (s *Service) GetExecutionStats(ctx context.Context, promptID string, days int) ([]*domain.PromptExecutionAggregate, error) {
+// IterateExecutionLogs 逐行回调最近若干天的执行日志，调用方需先确认 Prompt 存在。
+func (s *Service) IterateExecutionLogs(ctx context.Context, promptID string, days int, fn func(*domain.PromptExecutionLog) error) error {
 	if days <= 0 {
 		days = 7
 	}
-
-	if _, err := s.GetPrompt(ctx, promptID); err != nil {
-		return nil, err
-	}
-
 	from := time.Now().AddDate(0, 0, -days)
-	stats, err := s.repos.PromptExecutionLog.AggregateUsage(ctx, promptID, from)
-	if err != nil {
-		return nil, err
-	}
-	return stats, nil
+	return s.repos.PromptExecutionLog.IterateSince(ctx, promptID, from, fn)
 }
 
 // RestorePrompt 将软删除的 Prompt 恢复为可用状态，并记录审计日志。
@@ -511,11 +701,24 @@ func (s *Service) RestorePrompt(ctx context.Context, promptID, restoredBy string
 		}
 	}
 
+	s.publishEvent(ctx, events.PromptRestored, restored)
 	return restored, nil
 }
 
 // DeletePrompt 删除指定 Prompt（软删除），并记录审计日志。
 func (s *Service) DeletePrompt(ctx context.Context, promptID, deletedBy string) error {
+	// 冻结校验与事件发布都需要 Prompt 所属工作区，未启用时不额外查询。
+	var prompt *domain.Prompt
+	if s.changeFreeze != nil || s.events != nil {
+		current, err := s.GetPrompt(ctx, promptID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkChangeFreeze(ctx, current, FreezeOperationDelete, deletedBy); err != nil {
+			return err
+		}
+		prompt = current
+	}
 	if err := s.repos.Prompts.Delete(ctx, promptID); err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			return ErrPromptNotFound
@@ -542,6 +745,7 @@ func (s *Service) DeletePrompt(ctx context.Context, promptID, deletedBy string)
 			return err
 		}
 	}
+	s.publishEvent(ctx, events.PromptDeleted, prompt)
 	return nil
 }
 
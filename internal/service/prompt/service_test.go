@@ -1,17 +1,35 @@
 package prompt
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/google/uuid"
 	domain "github.com/zacharykka/prompt-manager/internal/domain"
 	"github.com/zacharykka/prompt-manager/internal/infra/database"
 	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	"github.com/zacharykka/prompt-manager/internal/service/events"
+	"github.com/zacharykka/prompt-manager/internal/service/llm"
+	"github.com/zacharykka/prompt-manager/pkg/audit"
+	"github.com/zacharykka/prompt-manager/pkg/render"
 )
 
 func setupPromptServiceWithDB(t *testing.T) (*Service, *sql.DB, func()) {
@@ -22,29 +40,18 @@ func setupPromptServiceWithDB(t *testing.T) (*Service, *sql.DB, func()) {
 		t.Fatalf("open sqlite: %v", err)
 	}
 
-	migrationPath := filepath.Join("..", "..", "..", "db", "migrations", "000001_init.up.sql")
-	migrationSQL, err := os.ReadFile(migrationPath)
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
 	if err != nil {
-		t.Fatalf("read migration: %v", err)
+		t.Fatalf("glob migrations: %v", err)
 	}
-	if _, err := db.Exec(string(migrationSQL)); err != nil {
-		t.Fatalf("exec migration: %v", err)
-	}
-	migration2Path := filepath.Join("..", "..", "..", "db", "migrations", "000002_add_prompt_body.up.sql")
-	migration2SQL, err := os.ReadFile(migration2Path)
-	if err != nil {
-		t.Fatalf("read migration 2: %v", err)
-	}
-	if _, err := db.Exec(string(migration2SQL)); err != nil {
-		t.Fatalf("exec migration 2: %v", err)
-	}
-	migration3Path := filepath.Join("..", "..", "..", "db", "migrations", "000003_prompt_soft_delete.up.sql")
-	migration3SQL, err := os.ReadFile(migration3Path)
-	if err != nil {
-		t.Fatalf("read migration 3: %v", err)
-	}
-	if _, err := db.Exec(string(migration3SQL)); err != nil {
-		t.Fatalf("exec migration 3: %v", err)
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
 	}
 
 	repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
@@ -159,7 +166,7 @@ func TestGetExecutionStats(t *testing.T) {
 		}
 	}
 
-	stats, err := svc.GetExecutionStats(context.Background(), prompt.ID, 7)
+	stats, err := svc.GetExecutionStats(context.Background(), prompt.ID, ExecutionStatsOptions{Days: 7})
 	if err != nil {
 		t.Fatalf("get stats: %v", err)
 	}
@@ -171,6 +178,267 @@ func TestGetExecutionStats(t *testing.T) {
 	}
 }
 
+func TestGetExecutionStatsTimezoneBuckets(t *testing.T) {
+	svc, db, cleanup := setupPromptServiceWithDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Timezone stats"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "tz", Status: "published", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	if err := svc.repos.PromptExecutionLog.Create(ctx, &domain.PromptExecutionLog{
+		ID:              "tz-log",
+		PromptID:        prompt.ID,
+		PromptVersionID: version.ID,
+		Status:          "success",
+		DurationMs:      50,
+	}); err != nil {
+		t.Fatalf("create log: %v", err)
+	}
+	// 昨天 20:00 UTC 在 Asia/Shanghai 已是今天 04:00。
+	now := time.Now().UTC()
+	executedAt := time.Date(now.Year(), now.Month(), now.Day()-1, 20, 0, 0, 0, time.UTC)
+	if _, err := db.Exec(`UPDATE prompt_execution_logs SET created_at = ? WHERE id = ?`, executedAt.Format("2006-01-02 15:04:05"), "tz-log"); err != nil {
+		t.Fatalf("backdate log: %v", err)
+	}
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	cases := []struct {
+		tz  string
+		loc *time.Location
+	}{
+		{"", time.UTC},
+		{"Asia/Shanghai", shanghai},
+	}
+	for _, tc := range cases {
+		stats, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Days: 7, Timezone: tc.tz})
+		if err != nil {
+			t.Fatalf("stats tz=%q: %v", tc.tz, err)
+		}
+		if len(stats) != 1 {
+			t.Fatalf("expected one bucket for tz=%q, got %d", tc.tz, len(stats))
+		}
+		local := executedAt.In(tc.loc)
+		wantBucket := local.Format("2006-01-02")
+		wantDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, tc.loc).UTC()
+		if stats[0].Bucket != wantBucket || !stats[0].Day.Equal(wantDay) || stats[0].Day.Location() != time.UTC {
+			t.Fatalf("tz=%q: unexpected bucket %s / day %s, want %s / %s", tc.tz, stats[0].Bucket, stats[0].Day, wantBucket, wantDay)
+		}
+	}
+
+	if _, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Timezone: "Mars/Olympus"}); err != ErrInvalidTimezone {
+		t.Fatalf("expected ErrInvalidTimezone, got %v", err)
+	}
+}
+
+func TestRecordExecutions(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Reported prompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "reported", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	other, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Other prompt"})
+	if err != nil {
+		t.Fatalf("create other prompt: %v", err)
+	}
+	otherVersion, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: other.ID, Body: "other"})
+	if err != nil {
+		t.Fatalf("create other version: %v", err)
+	}
+
+	now := time.Now().UTC()
+	executedAt := time.Date(now.Year(), now.Month(), now.Day()-2, 10, 30, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	report, err := svc.RecordExecutions(ctx, []ExecutionRecordInput{
+		{PromptID: prompt.ID, Status: "success", DurationMs: 40},
+		{PromptID: prompt.ID, VersionID: version.ID, Status: "error", DurationMs: 900, ErrorClass: domain.ExecutionErrorTimeout, ExecutedAt: &executedAt},
+		{PromptID: prompt.ID, Status: "pending"},
+		{PromptID: "missing", Status: "success"},
+		{PromptID: prompt.ID, VersionID: otherVersion.ID, Status: "success"},
+		{PromptID: prompt.ID, Status: "success", ExecutedAt: &future},
+	}, "reporter-1")
+	if err != nil {
+		t.Fatalf("record executions: %v", err)
+	}
+	if report.Total != 6 || report.Recorded != 2 || report.Failed != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Items[0].Status != ExecutionItemRecorded || report.Items[0].ID == "" || report.Items[3].Status != ExecutionItemFailed {
+		t.Fatalf("unexpected items %+v %+v", report.Items[0], report.Items[3])
+	}
+
+	logs, err := svc.ListExecutionLogs(ctx, prompt.ID, 10)
+	if err != nil {
+		t.Fatalf("list logs: %v", err)
+	}
+	if len(logs) != 2 || logs[0].PromptVersionID != version.ID || logs[0].UserID == nil || *logs[0].UserID != "reporter-1" {
+		t.Fatalf("unexpected logs %+v", logs)
+	}
+	stats, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Days: 7})
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	buckets := make(map[string]int)
+	for _, item := range stats {
+		buckets[item.Bucket] = item.TotalCalls
+	}
+	if buckets[executedAt.Format("2006-01-02")] != 1 {
+		t.Fatalf("expected backdated execution in its own bucket, got %+v", buckets)
+	}
+
+	if _, err := svc.RecordExecutions(ctx, nil, ""); !errors.Is(err, ErrInvalidExecutionBatch) {
+		t.Fatalf("expected ErrInvalidExecutionBatch for empty batch, got %v", err)
+	}
+	if _, err := svc.RecordExecutions(ctx, make([]ExecutionRecordInput, MaxExecutionBatchSize+1), ""); !errors.Is(err, ErrInvalidExecutionBatch) {
+		t.Fatalf("expected ErrInvalidExecutionBatch for oversized batch, got %v", err)
+	}
+}
+
+func TestGetExecutionStatsGranularityAndRange(t *testing.T) {
+	svc, db, cleanup := setupPromptServiceWithDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Hourly stats"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "hourly", Status: "published", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-5 * time.Hour)
+	offsets := []time.Duration{10 * time.Minute, 20 * time.Minute, 65 * time.Minute, 3 * time.Hour}
+	for i, offset := range offsets {
+		id := uuid.NewString()
+		if err := svc.repos.PromptExecutionLog.Create(ctx, &domain.PromptExecutionLog{
+			ID:              id,
+			PromptID:        prompt.ID,
+			PromptVersionID: version.ID,
+			Status:          "success",
+			DurationMs:      int64(10 * (i + 1)),
+		}); err != nil {
+			t.Fatalf("create log: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE prompt_execution_logs SET created_at = ? WHERE id = ?`, base.Add(offset).Format("2006-01-02 15:04:05"), id); err != nil {
+			t.Fatalf("backdate log: %v", err)
+		}
+	}
+
+	stats, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{
+		Granularity: "hour",
+		From:        base,
+		To:          base.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("hourly stats: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 hourly buckets, got %d", len(stats))
+	}
+	if !stats[0].Day.Equal(base.Add(time.Hour)) || stats[0].TotalCalls != 1 {
+		t.Fatalf("unexpected latest bucket: %+v", stats[0])
+	}
+	if !stats[1].Day.Equal(base) || stats[1].TotalCalls != 2 || stats[1].Bucket != base.Format("2006-01-02 15:04") {
+		t.Fatalf("unexpected earliest bucket: %+v", stats[1])
+	}
+
+	monthly, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Granularity: "month", From: base})
+	if err != nil {
+		t.Fatalf("monthly stats: %v", err)
+	}
+	total := 0
+	for _, item := range monthly {
+		if item.Day.Day() != 1 {
+			t.Fatalf("month bucket should start on day 1: %+v", item)
+		}
+		total += item.TotalCalls
+	}
+	if total != len(offsets) {
+		t.Fatalf("expected %d calls across month buckets, got %d", len(offsets), total)
+	}
+
+	weekly, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Granularity: "week", From: base})
+	if err != nil {
+		t.Fatalf("weekly stats: %v", err)
+	}
+	for _, item := range weekly {
+		if item.Day.Weekday() != time.Monday {
+			t.Fatalf("week bucket should start on Monday: %+v", item)
+		}
+	}
+
+	if _, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Granularity: "minute"}); err != ErrInvalidGranularity {
+		t.Fatalf("expected ErrInvalidGranularity, got %v", err)
+	}
+	if _, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{From: base, To: base}); err != ErrInvalidTimeRange {
+		t.Fatalf("expected ErrInvalidTimeRange for empty range, got %v", err)
+	}
+	if _, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Granularity: "hour", Days: 90}); err != ErrInvalidTimeRange {
+		t.Fatalf("expected ErrInvalidTimeRange for too many buckets, got %v", err)
+	}
+}
+
+func TestGetExecutionStatsPercentiles(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Latency stats"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "latency", Status: "published", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	// 9 次快速调用加 1 次长尾调用：均值被拉高，而 p50 仍反映典型耗时。
+	durations := []int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 1000}
+	for _, duration := range durations {
+		if err := svc.repos.PromptExecutionLog.Create(ctx, &domain.PromptExecutionLog{
+			ID:              uuid.NewString(),
+			PromptID:        prompt.ID,
+			PromptVersionID: version.ID,
+			Status:          "success",
+			DurationMs:      duration,
+		}); err != nil {
+			t.Fatalf("create log: %v", err)
+		}
+	}
+
+	stats, err := svc.GetExecutionStats(ctx, prompt.ID, ExecutionStatsOptions{Days: 1})
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 bucket, got %d", len(stats))
+	}
+	item := stats[0]
+	if item.AverageMillis != 145 {
+		t.Fatalf("unexpected average: %v", item.AverageMillis)
+	}
+	if item.P50Millis != 55 || math.Abs(item.P90Millis-181) > 1e-6 || math.Abs(item.P99Millis-918.1) > 1e-6 {
+		t.Fatalf("unexpected percentiles: p50=%v p90=%v p99=%v", item.P50Millis, item.P90Millis, item.P99Millis)
+	}
+}
+
 func TestListPromptsWithSearch(t *testing.T) {
 	svc, cleanup := setupPromptService(t)
 	defer cleanup()
@@ -209,6 +477,84 @@ func TestListPromptsWithSearch(t *testing.T) {
 	}
 }
 
+func TestListPromptsPageCountModes(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, name := range []string{"Page one", "Page two", "Page three"} {
+		if _, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: name}); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+
+	page, err := svc.ListPromptsPage(ctx, ListPromptsOptions{Limit: 2, CountMode: PromptCountNone})
+	if err != nil {
+		t.Fatalf("list without count: %v", err)
+	}
+	if page.Total != nil || !page.HasMore || len(page.Items) != 2 {
+		t.Fatalf("expected 2 items with hasMore and no total, got %+v", page)
+	}
+	last, err := svc.ListPromptsPage(ctx, ListPromptsOptions{Limit: 2, Offset: 2, CountMode: PromptCountNone})
+	if err != nil {
+		t.Fatalf("list last page: %v", err)
+	}
+	if last.HasMore || len(last.Items) != 1 {
+		t.Fatalf("expected final page without hasMore, got %+v", last)
+	}
+
+	// SQLite 没有统计估计，estimated 回退为精确计数。
+	estimated, err := svc.ListPromptsPage(ctx, ListPromptsOptions{Limit: 2, CountMode: PromptCountEstimated})
+	if err != nil {
+		t.Fatalf("list estimated: %v", err)
+	}
+	if estimated.Total == nil || *estimated.Total != 3 || estimated.TotalEstimated || !estimated.HasMore {
+		t.Fatalf("expected exact fallback total 3, got %+v", estimated)
+	}
+
+	if _, err := svc.ListPromptsPage(ctx, ListPromptsOptions{CountMode: "approximate"}); !errors.Is(err, ErrInvalidCountMode) {
+		t.Fatalf("expected ErrInvalidCountMode, got %v", err)
+	}
+}
+
+func TestListPromptsByCreator(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	author := &domain.User{ID: "author-1", Email: "author@example.com", HashedPassword: "x", Role: "editor", Status: "active"}
+	if err := svc.repos.Users.Create(ctx, author); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	for _, input := range []CreatePromptInput{
+		{Name: "By email", CreatedBy: author.Email},
+		{Name: "By id", CreatedBy: author.ID},
+		{Name: "Someone else", CreatedBy: "other@example.com"},
+	} {
+		if _, err := svc.CreatePrompt(ctx, input); err != nil {
+			t.Fatalf("create %s: %v", input.Name, err)
+		}
+	}
+
+	for _, creator := range []string{author.Email, author.ID} {
+		prompts, total, err := svc.ListPrompts(ctx, ListPromptsOptions{CreatedBy: creator})
+		if err != nil {
+			t.Fatalf("list by %s: %v", creator, err)
+		}
+		if total != 2 || len(prompts) != 2 {
+			t.Fatalf("expected 2 prompts for %s, got total=%d len=%d", creator, total, len(prompts))
+		}
+	}
+
+	prompts, total, err := svc.ListPrompts(ctx, ListPromptsOptions{CreatedBy: "other@example.com"})
+	if err != nil {
+		t.Fatalf("list by unknown user: %v", err)
+	}
+	if total != 1 || len(prompts) != 1 || prompts[0].Name != "Someone else" {
+		t.Fatalf("unexpected prompts for unregistered creator: %+v", prompts)
+	}
+}
+
 func TestUpdatePrompt(t *testing.T) {
 	svc, cleanup := setupPromptService(t)
 	defer cleanup()
@@ -460,148 +806,2727 @@ func TestDiffPromptVersion(t *testing.T) {
 	}
 }
 
-func TestCreatePromptVersionAuditLog(t *testing.T) {
+func TestDiffPromptVersionGranularity(t *testing.T) {
 	svc, cleanup := setupPromptService(t)
 	defer cleanup()
 
 	ctx := context.Background()
-	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "AuditVersion"})
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "DiffGranularity"})
 	if err != nil {
 		t.Fatalf("create prompt: %v", err)
 	}
-
-	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
-		PromptID:  prompt.ID,
-		Body:      "Draft body",
-		CreatedBy: "creator@example.com",
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "You are a helpful assistant.\nAnswer briefly.\nCite sources.",
+	}); err != nil {
+		t.Fatalf("create first version: %v", err)
+	}
+	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "You are a careful assistant.\nAnswer briefly.\nCite sources.",
 	})
 	if err != nil {
-		t.Fatalf("create version: %v", err)
+		t.Fatalf("create second version: %v", err)
 	}
-	if version == nil {
-		t.Fatalf("expected created version")
+
+	wordDiff, err := svc.DiffPromptVersion(ctx, prompt.ID, second.ID, DiffPromptVersionOptions{Granularity: "word"})
+	if err != nil {
+		t.Fatalf("word diff: %v", err)
+	}
+	if wordDiff.Granularity != DiffGranularityWord {
+		t.Fatalf("expected word granularity got %s", wordDiff.Granularity)
+	}
+	var deleted, inserted []string
+	for _, segment := range wordDiff.Body {
+		switch segment.Type {
+		case "delete":
+			deleted = append(deleted, segment.Text)
+		case "insert":
+			inserted = append(inserted, segment.Text)
+		}
+	}
+	if len(deleted) != 1 || deleted[0] != "helpful" || len(inserted) != 1 || inserted[0] != "careful" {
+		t.Fatalf("unexpected word diff deleted=%v inserted=%v", deleted, inserted)
 	}
 
-	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 10)
+	lineDiff, err := svc.DiffPromptVersion(ctx, prompt.ID, second.ID, DiffPromptVersionOptions{Granularity: "line", Unified: true})
 	if err != nil {
-		t.Fatalf("list audit logs: %v", err)
+		t.Fatalf("line diff: %v", err)
 	}
-	found := false
-	for _, log := range logs {
-		if log.Action == "prompt.version.created" {
-			found = true
-			if log.CreatedBy == nil || *log.CreatedBy != "creator@example.com" {
-				t.Fatalf("unexpected audit actor: %v", log.CreatedBy)
-			}
-			var payload map[string]interface{}
-			if err := json.Unmarshal(log.Payload, &payload); err != nil {
-				t.Fatalf("unmarshal payload: %v", err)
-			}
-			if payload["version_id"] != version.ID {
-				t.Fatalf("expected version id %s got %v", version.ID, payload["version_id"])
-			}
+	if len(lineDiff.Body) != 3 || lineDiff.Body[0].Text != "You are a helpful assistant.\n" || lineDiff.Body[2].Type != "equal" {
+		t.Fatalf("unexpected line diff %+v", lineDiff.Body)
+	}
+	expectedUnified := "--- v1\n+++ v2\n@@ -1,3 +1,3 @@\n-You are a helpful assistant.\n+You are a careful assistant.\n Answer briefly.\n Cite sources.\n\\ No newline at end of file\n"
+	if lineDiff.Unified != expectedUnified {
+		t.Fatalf("unexpected unified diff:\n%s", lineDiff.Unified)
+	}
+
+	if _, err := svc.DiffPromptVersion(ctx, prompt.ID, second.ID, DiffPromptVersionOptions{Granularity: "sentence"}); !errors.Is(err, ErrInvalidDiffGranularity) {
+		t.Fatalf("expected ErrInvalidDiffGranularity got %v", err)
+	}
+}
+
+func TestBuildUnifiedDiffHunks(t *testing.T) {
+	var left, right strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&left, "line %d\n", i)
+		switch i {
+		case 2:
+			fmt.Fprintf(&right, "line %d changed\n", i)
+		case 18:
+		default:
+			fmt.Fprintf(&right, "line %d\n", i)
 		}
 	}
-	if !found {
-		t.Fatalf("expected prompt.version.created audit log")
+	right.WriteString("tail")
+
+	unified := buildUnifiedDiff("a", "b", left.String(), right.String())
+	expected := "--- a\n+++ b\n" +
+		"@@ -1,5 +1,5 @@\n line 1\n-line 2\n+line 2 changed\n line 3\n line 4\n line 5\n" +
+		"@@ -15,6 +15,6 @@\n line 15\n line 16\n line 17\n-line 18\n line 19\n line 20\n+tail\n\\ No newline at end of file\n"
+	if unified != expected {
+		t.Fatalf("unexpected unified diff:\n%s", unified)
+	}
+	if buildUnifiedDiff("a", "b", "same\n", "same\n") != "" {
+		t.Fatalf("expected empty unified diff for identical bodies")
 	}
 }
 
-func TestSetActiveVersionAuditLog(t *testing.T) {
+func TestBlamePrompt(t *testing.T) {
 	svc, cleanup := setupPromptService(t)
 	defer cleanup()
 
 	ctx := context.Background()
-	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "ActivateAudit"})
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "BlamePrompt"})
 	if err != nil {
 		t.Fatalf("create prompt: %v", err)
 	}
+	if _, err := svc.BlamePrompt(ctx, prompt.ID, ""); err != ErrVersionNotFound {
+		t.Fatalf("expected ErrVersionNotFound without active version got %v", err)
+	}
+
+	bodies := []struct {
+		body   string
+		author string
+	}{
+		{"You are a bot.\nBe concise.\nNever lie.", "alice@example.com"},
+		{"You are a helpful bot.\nBe concise.\nNever lie.", "bob@example.com"},
+		{"You are a helpful bot.\nBe concise.\nCite sources.\nNever lie.", "carol@example.com"},
+	}
+	var versions []*domain.PromptVersion
+	for _, item := range bodies {
+		version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+			PromptID:  prompt.ID,
+			Body:      item.body,
+			Activate:  true,
+			CreatedBy: item.author,
+		})
+		if err != nil {
+			t.Fatalf("create version: %v", err)
+		}
+		versions = append(versions, version)
+	}
 
-	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
-		PromptID:  prompt.ID,
-		Body:      "Initial",
-		Activate:  true,
-		CreatedBy: "creator@example.com",
-	})
+	blame, err := svc.BlamePrompt(ctx, prompt.ID, "")
 	if err != nil {
-		t.Fatalf("create first version: %v", err)
+		t.Fatalf("blame: %v", err)
+	}
+	if blame.Version.ID != versions[2].ID || len(blame.Lines) != 4 {
+		t.Fatalf("unexpected blame %+v", blame)
+	}
+	expected := []struct {
+		text    string
+		version int
+		author  string
+	}{
+		{"You are a helpful bot.", 2, "bob@example.com"},
+		{"Be concise.", 1, "alice@example.com"},
+		{"Cite sources.", 3, "carol@example.com"},
+		{"Never lie.", 1, "alice@example.com"},
+	}
+	for i, want := range expected {
+		line := blame.Lines[i]
+		if line.Line != i+1 || line.Text != want.text || line.VersionNumber != want.version || line.Author == nil || *line.Author != want.author {
+			t.Fatalf("unexpected blame line %d: %+v", i+1, line)
+		}
 	}
 
-	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
-		PromptID:  prompt.ID,
-		Body:      "Second",
-		CreatedBy: "creator@example.com",
-	})
+	older, err := svc.BlamePrompt(ctx, prompt.ID, versions[0].ID)
 	if err != nil {
-		t.Fatalf("create second version: %v", err)
+		t.Fatalf("blame older version: %v", err)
 	}
+	if len(older.Lines) != 3 || older.Lines[0].VersionNumber != 1 {
+		t.Fatalf("unexpected blame for first version %+v", older.Lines)
+	}
+}
 
-	if err := svc.SetActiveVersion(ctx, prompt.ID, second.ID, "activator@example.com"); err != nil {
-		t.Fatalf("set active version: %v", err)
+func TestCreatePromptVersionDeduplicates(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "DedupePrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	schema := map[string]interface{}{"type": "object"}
+	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "hello {{name}}", VariablesSchema: schema})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
 	}
 
-	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 10)
+	// 仅 metadata 不同仍视为重复。
+	_, err = svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "hello {{name}}", VariablesSchema: schema, Metadata: map[string]interface{}{"note": "x"}})
+	var duplicate *DuplicateVersionError
+	if !errors.As(err, &duplicate) || !errors.Is(err, ErrDuplicateVersion) {
+		t.Fatalf("expected DuplicateVersionError got %v", err)
+	}
+	if duplicate.Version.ID != first.ID {
+		t.Fatalf("expected duplicate of %s got %s", first.ID, duplicate.Version.ID)
+	}
+
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "hello {{name}}"}); err != nil {
+		t.Fatalf("schema change should not be duplicate: %v", err)
+	}
+
+	forced, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "hello {{name}}", AllowDuplicate: true})
 	if err != nil {
-		t.Fatalf("list audit logs: %v", err)
+		t.Fatalf("allow duplicate: %v", err)
 	}
-	found := false
-	for _, log := range logs {
-		if log.Action == "prompt.version.activated" {
-			var payload map[string]interface{}
-			if err := json.Unmarshal(log.Payload, &payload); err != nil {
-				t.Fatalf("unmarshal payload: %v", err)
+	if forced.VersionNumber != 3 {
+		t.Fatalf("expected version 3 got %d", forced.VersionNumber)
+	}
+}
+
+func TestPrunePromptVersions(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+	svc := NewService(base.repos, WithMaxVersions(3))
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "RetentionPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	var versions []*domain.PromptVersion
+	for i := 1; i <= 6; i++ {
+		version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: fmt.Sprintf("body %d", i)})
+		if err != nil {
+			t.Fatalf("create version %d: %v", i, err)
+		}
+		versions = append(versions, version)
+	}
+	// v2 曾被激活，v4 为当前激活版本，二者都应保留。
+	if err := svc.SetActiveVersion(ctx, prompt.ID, versions[1].ID, "tester"); err != nil {
+		t.Fatalf("activate v2: %v", err)
+	}
+	if err := svc.SetActiveVersion(ctx, prompt.ID, versions[3].ID, "tester"); err != nil {
+		t.Fatalf("activate v4: %v", err)
+	}
+
+	if err := svc.PruneVersions(ctx); err != nil {
+		t.Fatalf("prune versions: %v", err)
+	}
+
+	remaining, err := svc.listAllVersions(ctx, prompt.ID)
+	if err != nil {
+		t.Fatalf("list versions: %v", err)
+	}
+	var numbers []int
+	for _, version := range remaining {
+		numbers = append(numbers, version.VersionNumber)
+	}
+	if fmt.Sprint(numbers) != "[2 4 6]" {
+		t.Fatalf("expected versions [2 4 6] to remain got %v", numbers)
+	}
+
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 10)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	if len(logs) == 0 || logs[0].Action != auditActionVersionsPruned {
+		t.Fatalf("expected prune audit entry first got %+v", logs)
+	}
+
+	pruned, err := svc.PrunePromptVersions(ctx, prompt.ID)
+	if err != nil || len(pruned) != 0 {
+		t.Fatalf("expected idempotent prune got %v %v", pruned, err)
+	}
+}
+
+func TestArchivePrompt(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "ArchivedPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "hello", Activate: true}); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	archived, err := svc.ArchivePrompt(ctx, prompt.ID, "tester")
+	if err != nil {
+		t.Fatalf("archive prompt: %v", err)
+	}
+	if archived.Status != domain.PromptStatusArchived || archived.DeletedAt != nil {
+		t.Fatalf("unexpected archived prompt %+v", archived)
+	}
+	if _, err := svc.ArchivePrompt(ctx, prompt.ID, "tester"); err != ErrPromptArchived {
+		t.Fatalf("expected ErrPromptArchived got %v", err)
+	}
+
+	listed, _, err := svc.ListPrompts(ctx, ListPromptsOptions{Search: "ArchivedPrompt"})
+	if err != nil || len(listed) != 0 {
+		t.Fatalf("expected archived prompt hidden by default got %d %v", len(listed), err)
+	}
+	listed, total, err := svc.ListPrompts(ctx, ListPromptsOptions{Search: "ArchivedPrompt", ArchivedOnly: true})
+	if err != nil || len(listed) != 1 || total != 1 {
+		t.Fatalf("expected archived-only listing to return prompt got %d/%d %v", len(listed), total, err)
+	}
+	if _, err := svc.GetPrompt(ctx, prompt.ID); err != nil {
+		t.Fatalf("archived prompt should stay retrievable: %v", err)
+	}
+	if _, err := svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID}); err != ErrPromptArchived {
+		t.Fatalf("expected render blocked got %v", err)
+	}
+
+	restored, err := svc.UnarchivePrompt(ctx, prompt.ID, "tester")
+	if err != nil {
+		t.Fatalf("unarchive prompt: %v", err)
+	}
+	if restored.Status != domain.PromptStatusActive {
+		t.Fatalf("expected active status got %s", restored.Status)
+	}
+	if _, err := svc.UnarchivePrompt(ctx, prompt.ID, "tester"); err != ErrPromptNotArchived {
+		t.Fatalf("expected ErrPromptNotArchived got %v", err)
+	}
+	if _, err := svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID}); err != nil {
+		t.Fatalf("render after unarchive: %v", err)
+	}
+
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 10)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	if len(logs) < 2 || logs[0].Action != "prompt.unarchived" || logs[1].Action != "prompt.archived" {
+		t.Fatalf("unexpected audit trail %+v", logs)
+	}
+}
+
+func TestPromptOwner(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := &domain.User{ID: "owner-1", Email: "owner@example.com", HashedPassword: "x", Role: "editor", Status: "active"}
+	if err := svc.repos.Users.Create(ctx, owner); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "OwnedPrompt", CreatedBy: "creator@example.com", Owner: &domain.PromptOwner{Type: "user", ID: owner.Email}})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if prompt.Owner == nil || prompt.Owner.Type != domain.PromptOwnerUser || prompt.Owner.ID != owner.ID {
+		t.Fatalf("expected owner resolved to user id got %+v", prompt.Owner)
+	}
+
+	if _, err := svc.TransferOwner(ctx, prompt.ID, &domain.PromptOwner{Type: "user", ID: "missing"}, "admin"); err != ErrInvalidOwner {
+		t.Fatalf("expected ErrInvalidOwner for unknown user got %v", err)
+	}
+	if _, err := svc.TransferOwner(ctx, prompt.ID, &domain.PromptOwner{Type: "group", ID: "x"}, "admin"); err != ErrInvalidOwner {
+		t.Fatalf("expected ErrInvalidOwner for unknown type got %v", err)
+	}
+
+	transferred, err := svc.TransferOwner(ctx, prompt.ID, &domain.PromptOwner{Type: "team", ID: "acme/prompt-team"}, "admin")
+	if err != nil {
+		t.Fatalf("transfer owner: %v", err)
+	}
+	if transferred.Owner == nil || transferred.Owner.Type != domain.PromptOwnerTeam || transferred.Owner.ID != "acme/prompt-team" {
+		t.Fatalf("unexpected owner after transfer %+v", transferred.Owner)
+	}
+	if transferred.CreatedBy == nil || *transferred.CreatedBy != "creator@example.com" {
+		t.Fatalf("transfer should not change created_by got %v", transferred.CreatedBy)
+	}
+
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 10)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	if len(logs) == 0 || logs[0].Action != "prompt.owner.transferred" {
+		t.Fatalf("expected owner transfer audit got %+v", logs)
+	}
+
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "hello"})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	result, err := svc.ActivateVersion(ctx, ActivateVersionInput{PromptID: prompt.ID, VersionID: version.ID, ActivatedBy: "admin"})
+	if err != nil {
+		t.Fatalf("activate version: %v", err)
+	}
+	if result.Event.Owner == nil || result.Event.Owner.ID != "acme/prompt-team" {
+		t.Fatalf("expected activation event to carry owner got %+v", result.Event.Owner)
+	}
+}
+
+func TestReviewPolicyGatesActivation(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, user := range []*domain.User{
+		{ID: "owner-1", Email: "owner@example.com", HashedPassword: "x", Role: "editor", Status: "active"},
+		{ID: "reviewer-1", Email: "reviewer@example.com", HashedPassword: "x", Role: "editor", Status: "active"},
+		{ID: "outsider-1", Email: "outsider@example.com", HashedPassword: "x", Role: "editor", Status: "active"},
+	} {
+		if err := svc.repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	owner := ReviewActor{UserID: "owner-1", Actor: "owner@example.com"}
+	reviewer := ReviewActor{UserID: "reviewer-1", Actor: "reviewer@example.com"}
+	outsider := ReviewActor{UserID: "outsider-1", Actor: "outsider@example.com"}
+
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "ReviewedPrompt", Owner: &domain.PromptOwner{Type: "user", ID: "owner-1"}})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	policyInput := SetReviewPolicyInput{PromptID: prompt.ID, RequiredApprovals: 1, Reviewers: []domain.PromptOwner{{Type: "user", ID: "reviewer@example.com"}}}
+
+	policyInput.By = outsider
+	if _, err := svc.SetReviewPolicy(ctx, policyInput); err != ErrNotPromptOwner {
+		t.Fatalf("expected ErrNotPromptOwner got %v", err)
+	}
+	policyInput.By = owner
+	policyInput.RequiredApprovals = 2
+	if _, err := svc.SetReviewPolicy(ctx, policyInput); !errors.Is(err, ErrInvalidReviewPolicy) {
+		t.Fatalf("expected unsatisfiable policy rejected got %v", err)
+	}
+	if _, err := svc.SetReviewPolicy(ctx, SetReviewPolicyInput{PromptID: prompt.ID, RequiredApprovals: 1, Reviewers: []domain.PromptOwner{{Type: "team", ID: "acme/reviewers"}}, By: owner}); !errors.Is(err, ErrInvalidReviewPolicy) {
+		t.Fatalf("expected team reviewers rejected without membership source got %v", err)
+	}
+	policyInput.RequiredApprovals = 1
+	policy, err := svc.SetReviewPolicy(ctx, policyInput)
+	if err != nil {
+		t.Fatalf("set review policy: %v", err)
+	}
+	if len(policy.Reviewers) != 1 || policy.Reviewers[0].ID != "reviewer-1" {
+		t.Fatalf("expected reviewer resolved to user id got %+v", policy.Reviewers)
+	}
+
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1", CreatedBy: "owner@example.com"})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	err = svc.SetActiveVersion(ctx, prompt.ID, version.ID, "owner@example.com")
+	var required *ReviewRequiredError
+	if !errors.As(err, &required) || required.Review.RequiredApprovals != 1 || required.Review.ValidApprovals != 0 {
+		t.Fatalf("expected ReviewRequiredError got %v", err)
+	}
+
+	approve := ApproveVersionInput{PromptID: prompt.ID, VersionID: version.ID}
+	approve.By = owner
+	if _, err := svc.ApproveVersion(ctx, approve); err != ErrSelfApproval {
+		t.Fatalf("expected ErrSelfApproval got %v", err)
+	}
+	approve.By = outsider
+	if _, err := svc.ApproveVersion(ctx, approve); err != ErrNotEligibleReviewer {
+		t.Fatalf("expected ErrNotEligibleReviewer got %v", err)
+	}
+	approve.By = reviewer
+	// 其他工作区无法为该版本记录、撤回或查看批准，从而绕过激活前的评审。
+	otherCtx := domain.WithWorkspace(ctx, "other")
+	if _, err := svc.ApproveVersion(otherCtx, approve); err != ErrPromptNotFound {
+		t.Fatalf("expected cross-workspace approval rejected got %v", err)
+	}
+	if _, err := svc.GetVersionReview(otherCtx, prompt.ID, version.ID); err != ErrPromptNotFound {
+		t.Fatalf("expected cross-workspace review lookup rejected got %v", err)
+	}
+	review, err := svc.ApproveVersion(ctx, approve)
+	if err != nil {
+		t.Fatalf("approve version: %v", err)
+	}
+	if !review.Approved || review.ValidApprovals != 1 || len(review.Approvals) != 1 {
+		t.Fatalf("unexpected review %+v", review)
+	}
+	if _, err := svc.RevokeApproval(otherCtx, prompt.ID, version.ID, reviewer); err != ErrPromptNotFound {
+		t.Fatalf("expected cross-workspace revoke rejected got %v", err)
+	}
+	if err := svc.SetActiveVersion(ctx, prompt.ID, version.ID, "owner@example.com"); err != nil {
+		t.Fatalf("activate approved version: %v", err)
+	}
+
+	if _, err := svc.RevokeApproval(ctx, prompt.ID, version.ID, reviewer); err != nil {
+		t.Fatalf("revoke approval: %v", err)
+	}
+	if _, err := svc.RevokeApproval(ctx, prompt.ID, version.ID, reviewer); err != ErrApprovalNotFound {
+		t.Fatalf("expected ErrApprovalNotFound got %v", err)
+	}
+	if err := svc.DeleteReviewPolicy(ctx, prompt.ID, ReviewActor{UserID: "admin-1", IsAdmin: true}); err != nil {
+		t.Fatalf("delete review policy: %v", err)
+	}
+	if _, err := svc.GetReviewPolicy(ctx, prompt.ID); err != ErrReviewPolicyNotFound {
+		t.Fatalf("expected ErrReviewPolicyNotFound got %v", err)
+	}
+}
+
+func TestPromptDrafts(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "DraftPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	if _, err := svc.GetDraft(ctx, prompt.ID, "user-1"); err != ErrDraftNotFound {
+		t.Fatalf("expected ErrDraftNotFound got %v", err)
+	}
+
+	zero := 0
+	draft, err := svc.SaveDraft(ctx, SaveDraftInput{PromptID: prompt.ID, UserID: "user-1", Body: "v1 edited", Revision: &zero})
+	if err != nil {
+		t.Fatalf("save draft: %v", err)
+	}
+	if draft.Revision != 1 || draft.BaseVersionID == nil || *draft.BaseVersionID != first.ID || draft.Stale {
+		t.Fatalf("unexpected draft %+v", draft)
+	}
+
+	// 相同内容重复保存（防抖）不递增 revision。
+	again, err := svc.SaveDraft(ctx, SaveDraftInput{PromptID: prompt.ID, UserID: "user-1", Body: "v1 edited", Revision: &draft.Revision})
+	if err != nil {
+		t.Fatalf("save identical draft: %v", err)
+	}
+	if again.Revision != 1 {
+		t.Fatalf("expected revision to stay 1 got %d", again.Revision)
+	}
+
+	updated, err := svc.SaveDraft(ctx, SaveDraftInput{PromptID: prompt.ID, UserID: "user-1", Body: "v1 edited twice", Revision: &draft.Revision})
+	if err != nil {
+		t.Fatalf("update draft: %v", err)
+	}
+	if updated.Revision != 2 {
+		t.Fatalf("expected revision 2 got %d", updated.Revision)
+	}
+
+	// 另一个标签页仍持有 revision 1，保存时应检测到冲突。
+	if _, err := svc.SaveDraft(ctx, SaveDraftInput{PromptID: prompt.ID, UserID: "user-1", Body: "stale tab", Revision: &draft.Revision}); err != ErrDraftConflict {
+		t.Fatalf("expected ErrDraftConflict got %v", err)
+	}
+	if _, err := svc.SaveDraft(ctx, SaveDraftInput{PromptID: prompt.ID, UserID: "user-1", Body: "new tab", Revision: &zero}); err != ErrDraftConflict {
+		t.Fatalf("expected ErrDraftConflict for create over existing draft got %v", err)
+	}
+
+	// 其他用户的草稿互不影响。
+	if _, err := svc.SaveDraft(ctx, SaveDraftInput{PromptID: prompt.ID, UserID: "user-2", Body: "other"}); err != nil {
+		t.Fatalf("save other user's draft: %v", err)
+	}
+
+	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v2"})
+	if err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+	recovered, err := svc.GetDraft(ctx, prompt.ID, "user-1")
+	if err != nil {
+		t.Fatalf("get draft: %v", err)
+	}
+	if recovered.Body != "v1 edited twice" || !recovered.Stale || recovered.LatestVersionID == nil || *recovered.LatestVersionID != second.ID {
+		t.Fatalf("unexpected recovered draft %+v", recovered)
+	}
+
+	versions, err := svc.ListPromptVersions(ctx, prompt.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("list versions: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("drafts must not create versions, got %d", len(versions))
+	}
+
+	if err := svc.DeleteDraft(ctx, prompt.ID, "user-1"); err != nil {
+		t.Fatalf("delete draft: %v", err)
+	}
+	if err := svc.DeleteDraft(ctx, prompt.ID, "user-1"); err != ErrDraftNotFound {
+		t.Fatalf("expected ErrDraftNotFound on second delete got %v", err)
+	}
+	if _, err := svc.GetDraft(ctx, prompt.ID, "user-2"); err != nil {
+		t.Fatalf("other user's draft should remain: %v", err)
+	}
+}
+
+func TestCreatePromptVersionAuditLog(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "AuditVersion"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID:  prompt.ID,
+		Body:      "Draft body",
+		CreatedBy: "creator@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	if version == nil {
+		t.Fatalf("expected created version")
+	}
+
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 10)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	found := false
+	for _, log := range logs {
+		if log.Action == "prompt.version.created" {
+			found = true
+			if log.CreatedBy == nil || *log.CreatedBy != "creator@example.com" {
+				t.Fatalf("unexpected audit actor: %v", log.CreatedBy)
 			}
-			if payload["version_id"] != second.ID {
-				continue
+			var payload map[string]interface{}
+			if err := json.Unmarshal(log.Payload, &payload); err != nil {
+				t.Fatalf("unmarshal payload: %v", err)
 			}
-			found = true
-			if log.CreatedBy == nil || *log.CreatedBy != "activator@example.com" {
-				t.Fatalf("unexpected activator: %v", log.CreatedBy)
+			if payload["version_id"] != version.ID {
+				t.Fatalf("expected version id %s got %v", version.ID, payload["version_id"])
 			}
-			if payload["previous_version_id"] != first.ID {
-				t.Fatalf("expected previous version id %s got %v", first.ID, payload["previous_version_id"])
+		}
+	}
+	if !found {
+		t.Fatalf("expected prompt.version.created audit log")
+	}
+}
+
+func TestSetActiveVersionAuditLog(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "ActivateAudit"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID:  prompt.ID,
+		Body:      "Initial",
+		Activate:  true,
+		CreatedBy: "creator@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create first version: %v", err)
+	}
+
+	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID:  prompt.ID,
+		Body:      "Second",
+		CreatedBy: "creator@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+
+	if err := svc.SetActiveVersion(ctx, prompt.ID, second.ID, "activator@example.com"); err != nil {
+		t.Fatalf("set active version: %v", err)
+	}
+
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 10)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	found := false
+	for _, log := range logs {
+		if log.Action == "prompt.version.activated" {
+			var payload map[string]interface{}
+			if err := json.Unmarshal(log.Payload, &payload); err != nil {
+				t.Fatalf("unmarshal payload: %v", err)
+			}
+			if payload["version_id"] != second.ID {
+				continue
+			}
+			found = true
+			if log.CreatedBy == nil || *log.CreatedBy != "activator@example.com" {
+				t.Fatalf("unexpected activator: %v", log.CreatedBy)
+			}
+			if payload["previous_version_id"] != first.ID {
+				t.Fatalf("expected previous version id %s got %v", first.ID, payload["previous_version_id"])
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected prompt.version.activated audit log")
+	}
+}
+
+func TestCreatePromptAfterSoftDelete(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	desc := "First"
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Reusable", Description: &desc, CreatedBy: "first@example.com"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	if err := svc.DeletePrompt(ctx, prompt.ID, "tester@example.com"); err != nil {
+		t.Fatalf("delete prompt: %v", err)
+	}
+
+	secondDesc := "Second"
+	recreated, err := svc.CreatePrompt(ctx, CreatePromptInput{
+		Name:        "Reusable",
+		Description: &secondDesc,
+		CreatedBy:   "second@example.com",
+	})
+	if err != nil {
+		t.Fatalf("recreate prompt: %v", err)
+	}
+	if recreated == nil {
+		t.Fatalf("expected recreated prompt")
+	}
+	if recreated.Status != "active" {
+		t.Fatalf("expected status active got %s", recreated.Status)
+	}
+	if recreated.DeletedAt != nil {
+		t.Fatalf("expected deleted_at cleared")
+	}
+	if recreated.Name != "Reusable" {
+		t.Fatalf("unexpected name %s", recreated.Name)
+	}
+}
+
+func TestPreviewPromptVersion(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "PreviewPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "# Title\n\nHello {{ name }}, <script>alert(1)</script> [x](javascript:alert(1)) {{name}}",
+	})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	preview, err := svc.PreviewPromptVersion(ctx, prompt.ID, version.ID, "html")
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !strings.Contains(preview.Content, "<h1>Title</h1>") {
+		t.Fatalf("expected rendered heading got %s", preview.Content)
+	}
+	if strings.Contains(preview.Content, "<script>") || strings.Contains(preview.Content, "javascript:") {
+		t.Fatalf("expected sanitized html got %s", preview.Content)
+	}
+	if !strings.Contains(preview.Content, `<span class="prompt-variable" data-variable="name">{{ name }}</span>`) {
+		t.Fatalf("expected highlighted variable got %s", preview.Content)
+	}
+	if len(preview.Variables) != 1 || preview.Variables[0] != "name" {
+		t.Fatalf("expected variables [name] got %v", preview.Variables)
+	}
+
+	if _, err := svc.PreviewPromptVersion(ctx, prompt.ID, version.ID, "pdf"); err != ErrUnsupportedPreviewFormat {
+		t.Fatalf("expected ErrUnsupportedPreviewFormat got %v", err)
+	}
+	if _, err := svc.PreviewPromptVersion(ctx, "other", version.ID, ""); err != ErrPromptNotFound {
+		t.Fatalf("expected ErrPromptNotFound got %v", err)
+	}
+	other, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "PreviewOther"})
+	if err != nil {
+		t.Fatalf("create other prompt: %v", err)
+	}
+	if _, err := svc.PreviewPromptVersion(ctx, other.ID, version.ID, ""); err != ErrVersionNotFound {
+		t.Fatalf("expected ErrVersionNotFound got %v", err)
+	}
+}
+
+func TestPromptLocaleVariants(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "LocalePrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "Hello", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	other, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Untranslated"})
+	if err != nil {
+		t.Fatalf("create second prompt: %v", err)
+	}
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: other.ID, Body: "Hi", Activate: true}); err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+
+	variant, err := svc.SetVersionLocale(ctx, SetVersionLocaleInput{
+		PromptID:  prompt.ID,
+		VersionID: version.ID,
+		Locale:    "zh_cn",
+		Body:      "你好",
+		CreatedBy: "author@example.com",
+	})
+	if err != nil {
+		t.Fatalf("set locale: %v", err)
+	}
+	if variant.Locale != "zh-CN" {
+		t.Fatalf("expected normalized locale zh-CN got %s", variant.Locale)
+	}
+
+	// fr-FR、fr 均未命中，继续按偏好回退到 zh-CN。
+	got, resolved, err := svc.GetPromptWithLocale(ctx, prompt.ID, "fr-FR,zh-CN")
+	if err != nil {
+		t.Fatalf("get with locale: %v", err)
+	}
+	if resolved != "zh-CN" || got.Body == nil || *got.Body != "你好" {
+		t.Fatalf("expected zh-CN body got resolved=%q body=%v", resolved, got.Body)
+	}
+
+	fallback, resolved, err := svc.GetPromptWithLocale(ctx, prompt.ID, "de")
+	if err != nil {
+		t.Fatalf("get with fallback: %v", err)
+	}
+	if resolved != "" || *fallback.Body != "Hello" {
+		t.Fatalf("expected default body got resolved=%q body=%s", resolved, *fallback.Body)
+	}
+
+	if _, _, err := svc.GetPromptWithLocale(ctx, prompt.ID, "not a locale"); err != ErrInvalidLocale {
+		t.Fatalf("expected ErrInvalidLocale got %v", err)
+	}
+
+	report, err := svc.GetLocaleCoverage(ctx, "zh-CN")
+	if err != nil {
+		t.Fatalf("coverage: %v", err)
+	}
+	if report.Total != 2 || report.Covered != 1 || len(report.Missing) != 1 || report.Missing[0].PromptName != "Untranslated" {
+		t.Fatalf("unexpected coverage report %+v", report)
+	}
+
+	if err := svc.DeleteVersionLocale(ctx, prompt.ID, version.ID, "zh-CN", "author@example.com"); err != nil {
+		t.Fatalf("delete locale: %v", err)
+	}
+	if err := svc.DeleteVersionLocale(ctx, prompt.ID, version.ID, "zh-CN", "author@example.com"); err != ErrLocaleNotFound {
+		t.Fatalf("expected ErrLocaleNotFound got %v", err)
+	}
+}
+
+func TestLocaleFallbackChain(t *testing.T) {
+	chain, err := LocaleFallbackChain("zh-hant-tw, en_US")
+	if err != nil {
+		t.Fatalf("chain: %v", err)
+	}
+	expected := []string{"zh-Hant-TW", "zh-Hant", "zh", "en-US", "en"}
+	if strings.Join(chain, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v got %v", expected, chain)
+	}
+}
+
+func TestPromptDependencies(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "base"})
+	if err != nil {
+		t.Fatalf("create base: %v", err)
+	}
+	chain, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "chain"})
+	if err != nil {
+		t.Fatalf("create chain: %v", err)
+	}
+	summary, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "summary"})
+	if err != nil {
+		t.Fatalf("create summary: %v", err)
+	}
+
+	// chain 正文引用 base，激活时应自动记录 include 依赖。
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: chain.ID,
+		Body:     "{{> base}}\nThen summarize. {{> missing}}",
+		Activate: true,
+	}); err != nil {
+		t.Fatalf("create chain version: %v", err)
+	}
+
+	links, err := svc.SetPromptDependencies(ctx, chain.ID, []string{"summary", summary.ID}, "author@example.com")
+	if err != nil {
+		t.Fatalf("set dependencies: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("expected 2 dependencies got %+v", links)
+	}
+	if links[0].Name != "base" || links[0].Source != DependencySourceInclude {
+		t.Fatalf("expected include dependency on base got %+v", links[0])
+	}
+
+	dependents, err := svc.ListPromptDependents(ctx, base.ID)
+	if err != nil {
+		t.Fatalf("list dependents: %v", err)
+	}
+	if len(dependents) != 1 || dependents[0].PromptID != chain.ID {
+		t.Fatalf("expected chain as dependent got %+v", dependents)
+	}
+
+	if _, err := svc.SetPromptDependencies(ctx, summary.ID, []string{"chain"}, ""); err != ErrDependencyCycle {
+		t.Fatalf("expected ErrDependencyCycle got %v", err)
+	}
+	if _, err := svc.SetPromptDependencies(ctx, summary.ID, []string{"nope"}, ""); err != ErrDependencyNotFound {
+		t.Fatalf("expected ErrDependencyNotFound got %v", err)
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "RenderPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if _, err := svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID}); err != ErrVersionNotFound {
+		t.Fatalf("expected ErrVersionNotFound got %v", err)
+	}
+
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     `Hello {{ name | upper }}{{#each tags}}, {{this}}{{/each}}`,
+		Activate: true,
+	})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	result, err := svc.RenderPrompt(ctx, RenderPromptInput{
+		PromptID:  prompt.ID,
+		Variables: map[string]interface{}{"name": "ada", "tags": []interface{}{"a", "b"}},
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if result.Output != "Hello ADA, a, b" || result.VersionID != version.ID {
+		t.Fatalf("unexpected render result %+v", result)
+	}
+
+	broken, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "{{#if a}}open"})
+	if err != nil {
+		t.Fatalf("create broken version: %v", err)
+	}
+	if _, err := svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID, VersionID: broken.ID}); !errors.Is(err, ErrRenderFailed) {
+		t.Fatalf("expected ErrRenderFailed got %v", err)
+	}
+}
+
+func TestDiffRenderedVersions(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "RenderedDiff"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     `Hi {{name}}.{{#if vip}} Welcome back!{{/if}}`,
+		Activate: true,
+	})
+	if err != nil {
+		t.Fatalf("create first version: %v", err)
+	}
+	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     `Hi {{name}}.{{#unless vip}} Welcome back!{{/unless}}`,
+	})
+	if err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+	third, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     `Hi {{name}}.{{#unless vip}} Welcome back, friend!{{/unless}}`,
+	})
+	if err != nil {
+		t.Fatalf("create third version: %v", err)
+	}
+
+	diff, err := svc.DiffRenderedVersions(ctx, RenderedDiffInput{
+		PromptID:      prompt.ID,
+		BaseVersionID: second.ID,
+		Diff:          DiffPromptVersionOptions{Granularity: DiffGranularityWord},
+		Variables:     map[string]interface{}{"name": "ada", "vip": true},
+	})
+	if err != nil {
+		t.Fatalf("rendered diff: %v", err)
+	}
+	if diff.Target.ID != first.ID || diff.TargetOutput != "Hi ada. Welcome back!" || diff.BaseOutput != "Hi ada." || !diff.Changed {
+		t.Fatalf("unexpected rendered diff %+v", diff)
+	}
+	if len(diff.Body) != 2 || diff.Body[1].Type != "delete" || diff.Body[1].Text != " Welcome back!" {
+		t.Fatalf("unexpected rendered diff body %+v", diff.Body)
+	}
+
+	unchanged, err := svc.DiffRenderedVersions(ctx, RenderedDiffInput{
+		PromptID:      prompt.ID,
+		BaseVersionID: third.ID,
+		Diff:          DiffPromptVersionOptions{CompareToPrevious: true},
+		Variables:     map[string]interface{}{"name": "ada", "vip": true},
+	})
+	if err != nil {
+		t.Fatalf("rendered diff unchanged: %v", err)
+	}
+	if unchanged.Changed || len(unchanged.Body) != 1 || unchanged.Body[0].Type != "equal" {
+		t.Fatalf("expected identical rendered output got %+v", unchanged)
+	}
+
+	if _, err := svc.DiffRenderedVersions(ctx, RenderedDiffInput{
+		PromptID:      prompt.ID,
+		BaseVersionID: second.ID,
+		Mode:          "strict",
+		Variables:     map[string]interface{}{"vip": true},
+	}); !errors.Is(err, ErrMissingVariables) {
+		t.Fatalf("expected ErrMissingVariables got %v", err)
+	}
+}
+
+func TestRenderPromptModes(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "RenderModes"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "Dear {{ name }}, about {{ topic }}",
+		VariablesSchema: map[string]interface{}{
+			"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string", "default": "customer"}},
+		},
+		Activate: true,
+	}); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	result, err := svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID})
+	if err != nil || result.Output != "Dear {{ name }}, about {{ topic }}" || result.Mode != "lenient" {
+		t.Fatalf("lenient: unexpected %+v %v", result, err)
+	}
+
+	result, err = svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID, Mode: "default"})
+	if err != nil || result.Output != "Dear customer, about {{ topic }}" {
+		t.Fatalf("default: unexpected %+v %v", result, err)
+	}
+
+	mode := "strict"
+	if _, err := svc.UpdatePrompt(ctx, UpdatePromptInput{PromptID: prompt.ID, RenderMode: &mode}); err != nil {
+		t.Fatalf("update render mode: %v", err)
+	}
+	_, err = svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID, Variables: map[string]interface{}{"name": "Ada"}})
+	var missing *render.MissingVariablesError
+	if !errors.Is(err, ErrMissingVariables) || !errors.As(err, &missing) || len(missing.Keys) != 1 || missing.Keys[0] != "topic" {
+		t.Fatalf("strict: expected missing topic got %v", err)
+	}
+
+	invalid := "loose"
+	if _, err := svc.UpdatePrompt(ctx, UpdatePromptInput{PromptID: prompt.ID, RenderMode: &invalid}); err != ErrInvalidRenderMode {
+		t.Fatalf("expected ErrInvalidRenderMode got %v", err)
+	}
+}
+
+func TestValidatePromptVersion(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "ValidatePrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	report, err := svc.ValidatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "Hello {{ name }} \n{{> missing-prompt}} {{ topic }}",
+		VariablesSchema: map[string]interface{}{
+			"properties": map[string]interface{}{
+				"name":  map[string]interface{}{"type": "string", "default": 1},
+				"extra": map[string]interface{}{"type": "string"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if report.Valid {
+		t.Fatalf("expected invalid report")
+	}
+	rules := map[string]bool{}
+	for _, check := range report.Checks {
+		for _, issue := range check.Issues {
+			rules[issue.Rule] = true
+		}
+	}
+	for _, rule := range []string{"default_type_mismatch", "undeclared_variable", "unused_variable", "trailing_whitespace", "unknown_include"} {
+		if !rules[rule] {
+			t.Fatalf("expected rule %s in report %+v", rule, report.Checks)
+		}
+	}
+
+	report, err = svc.ValidatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "{{#if a}}open"})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if report.Valid || report.Checks[0].Passed || report.Checks[0].Issues[0].Line != 1 {
+		t.Fatalf("expected template parse failure got %+v", report.Checks[0])
+	}
+
+	report, err = svc.ValidatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     strings.Repeat("word ", 20),
+		Metadata: map[string]interface{}{"max_tokens": 10},
+	})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if report.Valid || report.TokenLimit != 10 || report.Checks[3].Passed {
+		t.Fatalf("expected token limit failure got %+v", report)
+	}
+
+	versions, err := svc.ListPromptVersions(ctx, prompt.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("list versions: %v", err)
+	}
+	if len(versions) != 0 {
+		t.Fatalf("expected validation to persist nothing got %d versions", len(versions))
+	}
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	svc, db, cleanup := setupPromptServiceWithDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "audited", CreatedBy: "auditor@example.com"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+			PromptID:  prompt.ID,
+			Body:      "v" + string(rune('1'+i)),
+			Status:    "draft",
+			CreatedBy: "auditor@example.com",
+		}); err != nil {
+			t.Fatalf("create version: %v", err)
+		}
+	}
+
+	report, err := svc.VerifyAuditChain(ctx)
+	if err != nil {
+		t.Fatalf("verify chain: %v", err)
+	}
+	if !report.Verified || report.Checked != 3 || report.HeadSeq != 3 || report.HeadHash == "" {
+		t.Fatalf("expected intact chain of 3 records, got %+v", report)
+	}
+
+	var exported []*domain.PromptAuditLog
+	if err := svc.IterateAuditLogs(ctx, domain.AuditLogIterateOptions{PromptID: prompt.ID}, func(log *domain.PromptAuditLog) error {
+		exported = append(exported, log)
+		return nil
+	}); err != nil {
+		t.Fatalf("iterate audit logs: %v", err)
+	}
+	if len(exported) != 3 || exported[0].Seq != 1 || exported[1].PrevHash != exported[0].Hash {
+		t.Fatalf("unexpected exported chain: %+v", exported)
+	}
+
+	if _, err := db.Exec(`UPDATE prompt_audit_logs SET created_by = 'intruder' WHERE seq = 2`); err != nil {
+		t.Fatalf("tamper record: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM prompt_audit_logs WHERE seq = 1`); err != nil {
+		t.Fatalf("delete record: %v", err)
+	}
+
+	report, err = svc.VerifyAuditChain(ctx)
+	if err != nil {
+		t.Fatalf("verify tampered chain: %v", err)
+	}
+	if report.Verified {
+		t.Fatalf("expected tampered chain to fail verification")
+	}
+	kinds := map[string]int64{}
+	for _, issue := range report.Issues {
+		kinds[issue.Kind] = issue.Seq
+	}
+	if kinds[audit.IssueGap] != 2 || kinds[audit.IssueHashMismatch] != 2 {
+		t.Fatalf("expected gap and hash mismatch at seq 2, got %+v", report.Issues)
+	}
+}
+
+type memoryEditLockStore struct {
+	locks map[string]domain.EditLock
+}
+
+func (m *memoryEditLockStore) Acquire(_ context.Context, lock domain.EditLock, _ time.Duration) (*domain.EditLock, bool, error) {
+	if current, ok := m.locks[lock.PromptID]; ok && current.ExpiresAt.After(time.Now()) {
+		if current.HolderID != lock.HolderID {
+			return &current, false, nil
+		}
+		lock.AcquiredAt = current.AcquiredAt
+	}
+	m.locks[lock.PromptID] = lock
+	return &lock, true, nil
+}
+
+func (m *memoryEditLockStore) Get(_ context.Context, promptID string) (*domain.EditLock, error) {
+	current, ok := m.locks[promptID]
+	if !ok || !current.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	return &current, nil
+}
+
+func (m *memoryEditLockStore) Release(_ context.Context, promptID, holderID string) (bool, error) {
+	current, ok := m.locks[promptID]
+	if !ok || current.HolderID != holderID {
+		return false, nil
+	}
+	delete(m.locks, promptID)
+	return true, nil
+}
+
+func TestEditLocks(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := base.AcquireEditLock(ctx, "any", "user-1", "alice@example.com"); err != ErrEditLocksDisabled {
+		t.Fatalf("expected ErrEditLocksDisabled got %v", err)
+	}
+
+	svc := NewService(base.repos, WithEditLocks(&memoryEditLockStore{locks: map[string]domain.EditLock{}}, 0))
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "LockedPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	lock, err := svc.AcquireEditLock(ctx, prompt.ID, "user-1", "alice@example.com")
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	if lock.Holder != "alice@example.com" || lock.ExpiresAt.Sub(lock.AcquiredAt) != defaultEditLockTTL {
+		t.Fatalf("unexpected lock %+v", lock)
+	}
+	renewed, err := svc.AcquireEditLock(ctx, prompt.ID, "user-1", "alice@example.com")
+	if err != nil {
+		t.Fatalf("renew lock: %v", err)
+	}
+	if !renewed.AcquiredAt.Equal(lock.AcquiredAt) {
+		t.Fatalf("renewal should keep acquired_at, got %+v", renewed)
+	}
+
+	_, err = svc.AcquireEditLock(ctx, prompt.ID, "user-2", "bob@example.com")
+	var held *EditLockHeldError
+	if !errors.As(err, &held) || !errors.Is(err, ErrEditLockHeld) || held.Lock.HolderID != "user-1" {
+		t.Fatalf("expected EditLockHeldError got %v", err)
+	}
+	if err := svc.ReleaseEditLock(ctx, prompt.ID, "user-2"); err != ErrEditLockNotHeld {
+		t.Fatalf("expected ErrEditLockNotHeld got %v", err)
+	}
+
+	current, err := svc.GetEditLock(ctx, prompt.ID)
+	if err != nil || current == nil || current.HolderID != "user-1" {
+		t.Fatalf("unexpected current lock %+v err=%v", current, err)
+	}
+
+	if err := svc.ReleaseEditLock(ctx, prompt.ID, "user-1"); err != nil {
+		t.Fatalf("release lock: %v", err)
+	}
+	if current, _ := svc.GetEditLock(ctx, prompt.ID); current != nil {
+		t.Fatalf("expected lock to be released got %+v", current)
+	}
+	if _, err := svc.AcquireEditLock(ctx, prompt.ID, "user-2", "bob@example.com"); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	if _, err := svc.AcquireEditLock(ctx, "missing", "user-2", "bob@example.com"); err != ErrPromptNotFound {
+		t.Fatalf("expected ErrPromptNotFound got %v", err)
+	}
+}
+
+type recordingActivationNotifier struct {
+	events []ActivationEvent
+}
+
+func (n *recordingActivationNotifier) NotifyActivation(_ context.Context, event ActivationEvent) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestActivateVersionReleaseNotes(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	var webhookEvents []ActivationEvent
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event ActivationEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode webhook payload: %v", err)
+		}
+		webhookEvents = append(webhookEvents, event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	notifier := &recordingActivationNotifier{}
+	svc := NewService(base.repos,
+		WithActivationNotifier(notifier),
+		WithActivationWebhook(webhook.URL),
+		WithRequireReleaseNote(true),
+	)
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "ReleaseNotes"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1", Activate: true})
+	if err != nil {
+		t.Fatalf("create first version: %v", err)
+	}
+	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v2"})
+	if err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+
+	if _, err := svc.ActivateVersion(ctx, ActivateVersionInput{PromptID: prompt.ID, VersionID: second.ID}); err != ErrReleaseNoteRequired {
+		t.Fatalf("expected ErrReleaseNoteRequired got %v", err)
+	}
+	if _, err := svc.ActivateVersion(ctx, ActivateVersionInput{
+		PromptID:    prompt.ID,
+		VersionID:   second.ID,
+		ReleaseNote: strings.Repeat("x", maxReleaseNoteLength+1),
+	}); err != ErrInvalidReleaseNote {
+		t.Fatalf("expected ErrInvalidReleaseNote got %v", err)
+	}
+
+	result, err := svc.ActivateVersion(ctx, ActivateVersionInput{
+		PromptID:    prompt.ID,
+		VersionID:   second.ID,
+		ActivatedBy: "releaser@example.com",
+		ReleaseNote: "  Tighten tone for support replies  ",
+	})
+	if err != nil {
+		t.Fatalf("activate version: %v", err)
+	}
+	if result.NotifyErr != nil {
+		t.Fatalf("unexpected notify error: %v", result.NotifyErr)
+	}
+	event := result.Event
+	if event.ReleaseNote != "Tighten tone for support replies" || event.PreviousVersionID == nil || *event.PreviousVersionID != first.ID {
+		t.Fatalf("unexpected activation event %+v", event)
+	}
+	// 内联激活同样推送通知，但不要求发布说明。
+	if len(notifier.events) != 2 || len(webhookEvents) != 2 || webhookEvents[1].ReleaseNote != event.ReleaseNote || webhookEvents[1].PromptName != "ReleaseNotes" {
+		t.Fatalf("unexpected notifications notifier=%+v webhook=%+v", notifier.events, webhookEvents)
+	}
+
+	activations, err := svc.ListActivations(ctx, prompt.ID)
+	if err != nil {
+		t.Fatalf("list activations: %v", err)
+	}
+	if len(activations) != 2 {
+		t.Fatalf("expected 2 activations got %+v", activations)
+	}
+	latest := activations[0]
+	if latest.VersionID != second.ID || latest.ReleaseNote != event.ReleaseNote || latest.ActivatedBy == nil || *latest.ActivatedBy != "releaser@example.com" {
+		t.Fatalf("unexpected latest activation %+v", latest)
+	}
+	if activations[1].VersionID != first.ID || activations[1].ReleaseNote != "" {
+		t.Fatalf("unexpected first activation %+v", activations[1])
+	}
+}
+
+type recordingAlertNotifier struct {
+	events []AlertEvent
+}
+
+func (n *recordingAlertNotifier) NotifyAlert(_ context.Context, event AlertEvent) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestEvaluateAlerts(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	var webhookEvents []AlertEvent
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event AlertEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode webhook payload: %v", err)
+		}
+		webhookEvents = append(webhookEvents, event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	notifier := &recordingAlertNotifier{}
+	svc := NewService(base.repos, WithAlertNotifier(notifier))
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Alerting"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "alert", Status: "published", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	if _, err := svc.CreateAlertRule(ctx, CreateAlertRuleInput{PromptID: prompt.ID, Name: "bad", Metric: domain.AlertMetricErrorRate, Threshold: 150, WindowMinutes: 5}); !errors.Is(err, ErrInvalidAlertRule) {
+		t.Fatalf("expected ErrInvalidAlertRule for error rate above 100, got %v", err)
+	}
+	errorRule, err := svc.CreateAlertRule(ctx, CreateAlertRuleInput{
+		PromptID:      prompt.ID,
+		Name:          "error rate",
+		Metric:        domain.AlertMetricErrorRate,
+		Threshold:     50,
+		WindowMinutes: 15,
+		WebhookURL:    webhook.URL,
+	})
+	if err != nil {
+		t.Fatalf("create error rule: %v", err)
+	}
+	latencyRule, err := svc.CreateAlertRule(ctx, CreateAlertRuleInput{
+		PromptID:      prompt.ID,
+		Name:          "p95",
+		Metric:        domain.AlertMetricP95Latency,
+		Threshold:     1000,
+		WindowMinutes: 15,
+	})
+	if err != nil {
+		t.Fatalf("create latency rule: %v", err)
+	}
+
+	for i, status := range []string{"error", "error", "error", "success"} {
+		if err := svc.repos.PromptExecutionLog.Create(ctx, &domain.PromptExecutionLog{
+			ID:              uuid.NewString(),
+			PromptID:        prompt.ID,
+			PromptVersionID: version.ID,
+			Status:          status,
+			DurationMs:      int64(100 * (i + 1)),
+		}); err != nil {
+			t.Fatalf("create log: %v", err)
+		}
+	}
+
+	if err := svc.EvaluateAlerts(ctx); err != nil {
+		t.Fatalf("evaluate alerts: %v", err)
+	}
+	rules, err := svc.ListAlertRules(ctx, prompt.ID)
+	if err != nil {
+		t.Fatalf("list rules: %v", err)
+	}
+	states := map[string]*domain.PromptAlertRule{}
+	for _, rule := range rules {
+		states[rule.ID] = rule
+	}
+	if rule := states[errorRule.ID]; rule.State != domain.AlertStateFiring || rule.LastValue == nil || *rule.LastValue != 75 || rule.StateChangedAt == nil {
+		t.Fatalf("expected error rule firing at 75%%, got %+v", rule)
+	}
+	if rule := states[latencyRule.ID]; rule.State != domain.AlertStateOK || rule.LastEvaluatedAt == nil {
+		t.Fatalf("expected latency rule ok, got %+v", rule)
+	}
+	if len(notifier.events) != 1 || notifier.events[0].RuleID != errorRule.ID || notifier.events[0].State != domain.AlertStateFiring {
+		t.Fatalf("expected one firing notification, got %+v", notifier.events)
+	}
+	if len(webhookEvents) != 1 || webhookEvents[0].TotalCalls != 4 {
+		t.Fatalf("expected one webhook call, got %+v", webhookEvents)
+	}
+
+	// 状态未变化时不重复通知。
+	if err := svc.EvaluateAlerts(ctx); err != nil {
+		t.Fatalf("re-evaluate alerts: %v", err)
+	}
+	if len(notifier.events) != 1 || len(webhookEvents) != 1 {
+		t.Fatalf("expected no repeated notifications, got %d/%d", len(notifier.events), len(webhookEvents))
+	}
+
+	if err := svc.DeleteAlertRule(ctx, "other-prompt", errorRule.ID); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected ErrPromptNotFound for missing prompt, got %v", err)
+	}
+	otherPrompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "AlertOther"})
+	if err != nil {
+		t.Fatalf("create other prompt: %v", err)
+	}
+	if err := svc.DeleteAlertRule(ctx, otherPrompt.ID, errorRule.ID); !errors.Is(err, ErrAlertRuleNotFound) {
+		t.Fatalf("expected ErrAlertRuleNotFound for mismatched prompt, got %v", err)
+	}
+	if err := svc.DeleteAlertRule(domain.WithWorkspace(ctx, "other"), prompt.ID, errorRule.ID); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected cross-workspace delete rejected, got %v", err)
+	}
+	if err := svc.DeleteAlertRule(ctx, prompt.ID, errorRule.ID); err != nil {
+		t.Fatalf("delete rule: %v", err)
+	}
+	if err := svc.DeleteAlertRule(ctx, prompt.ID, latencyRule.ID); err != nil {
+		t.Fatalf("delete latency rule: %v", err)
+	}
+}
+
+func TestAlertWebhookSecretRotationAndTestFire(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	type delivery struct {
+		event     AlertEvent
+		body      []byte
+		signature string
+	}
+	var deliveries []delivery
+	status := http.StatusNoContent
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event AlertEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("decode webhook payload: %v", err)
+		}
+		deliveries = append(deliveries, delivery{event: event, body: body, signature: r.Header.Get(AlertSignatureHeader)})
+		w.WriteHeader(status)
+	}))
+	defer webhook.Close()
+
+	sign := func(secret string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Signed alerts"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	rule, err := svc.CreateAlertRule(ctx, CreateAlertRuleInput{PromptID: prompt.ID, Name: "errors", Metric: domain.AlertMetricErrorRate, Threshold: 10, WindowMinutes: 5, WebhookURL: webhook.URL})
+	if err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+	if !strings.HasPrefix(rule.WebhookSecret, "whsec_") {
+		t.Fatalf("expected generated webhook secret, got %q", rule.WebhookSecret)
+	}
+	initial := rule.WebhookSecret
+
+	// 其他工作区无法轮换密钥或触发测试推送。
+	otherCtx := domain.WithWorkspace(ctx, "other")
+	if _, err := svc.RotateAlertWebhookSecret(otherCtx, prompt.ID, rule.ID, time.Hour); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected cross-workspace rotation rejected, got %v", err)
+	}
+	if err := svc.TestAlertWebhook(otherCtx, prompt.ID, rule.ID); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected cross-workspace test delivery rejected, got %v", err)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("expected no deliveries from another workspace, got %d", len(deliveries))
+	}
+
+	if err := svc.TestAlertWebhook(ctx, prompt.ID, rule.ID); err != nil {
+		t.Fatalf("test webhook: %v", err)
+	}
+	if len(deliveries) != 1 || !deliveries[0].event.Test || deliveries[0].signature != sign(initial, deliveries[0].body) {
+		t.Fatalf("expected one signed test event, got %+v", deliveries)
+	}
+
+	// 重叠期内同时携带新旧密钥的签名，新密钥在前。
+	rotated, err := svc.RotateAlertWebhookSecret(ctx, prompt.ID, rule.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("rotate secret: %v", err)
+	}
+	if rotated.WebhookSecret == initial || rotated.PreviousSecretExpiresAt == nil {
+		t.Fatalf("expected new secret with overlap, got %+v", rotated)
+	}
+	if err := svc.TestAlertWebhook(ctx, prompt.ID, rule.ID); err != nil {
+		t.Fatalf("test webhook after rotation: %v", err)
+	}
+	last := deliveries[len(deliveries)-1]
+	if want := sign(rotated.WebhookSecret, last.body) + ", " + sign(initial, last.body); last.signature != want {
+		t.Fatalf("expected dual signatures %q, got %q", want, last.signature)
+	}
+
+	// 不保留重叠期时旧密钥立即失效。
+	final, err := svc.RotateAlertWebhookSecret(ctx, prompt.ID, rule.ID, 0)
+	if err != nil {
+		t.Fatalf("rotate secret without overlap: %v", err)
+	}
+	if err := svc.TestAlertWebhook(ctx, prompt.ID, rule.ID); err != nil {
+		t.Fatalf("test webhook after cutover: %v", err)
+	}
+	last = deliveries[len(deliveries)-1]
+	if last.signature != sign(final.WebhookSecret, last.body) {
+		t.Fatalf("expected single signature after cutover, got %q", last.signature)
+	}
+
+	status = http.StatusInternalServerError
+	if err := svc.TestAlertWebhook(ctx, prompt.ID, rule.ID); !errors.Is(err, ErrAlertWebhookFailed) {
+		t.Fatalf("expected ErrAlertWebhookFailed, got %v", err)
+	}
+	if _, err := svc.RotateAlertWebhookSecret(ctx, prompt.ID, rule.ID, 8*24*time.Hour); !errors.Is(err, ErrInvalidAlertRule) {
+		t.Fatalf("expected ErrInvalidAlertRule for long overlap, got %v", err)
+	}
+	if err := svc.TestAlertWebhook(ctx, "other-prompt", rule.ID); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected ErrPromptNotFound for missing prompt, got %v", err)
+	}
+	otherPrompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Signed alerts other"})
+	if err != nil {
+		t.Fatalf("create other prompt: %v", err)
+	}
+	if err := svc.TestAlertWebhook(ctx, otherPrompt.ID, rule.ID); !errors.Is(err, ErrAlertRuleNotFound) {
+		t.Fatalf("expected ErrAlertRuleNotFound for mismatched prompt, got %v", err)
+	}
+	silent, err := svc.CreateAlertRule(ctx, CreateAlertRuleInput{PromptID: prompt.ID, Name: "silent", Metric: domain.AlertMetricP95Latency, Threshold: 100, WindowMinutes: 5})
+	if err != nil {
+		t.Fatalf("create rule without webhook: %v", err)
+	}
+	if err := svc.TestAlertWebhook(ctx, prompt.ID, silent.ID); !errors.Is(err, ErrAlertWebhookMissing) {
+		t.Fatalf("expected ErrAlertWebhookMissing, got %v", err)
+	}
+}
+
+func buildTestArchive(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write zip entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestImportArchive(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "existing"}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	archive := buildTestArchive(t, map[string]string{
+		"prompts/greeting.yaml":    "name: greeting\ntags: [demo]\nversions:\n  - body: Hello {{name}}\n    variables_schema:\n      type: object\n    active: true\n  - body: Hi {{name}}\n    status: draft\n",
+		"prompts/summary.json":     `{"name": "summary", "body": "Summarize {{text}}"}`,
+		"prompts/existing.yml":     "name: existing\nbody: appended body\n",
+		"prompts/broken.yaml":      "name: broken\n",
+		"README.md":                "ignored",
+		"__MACOSX/._greeting.yaml": "ignored",
+	})
+
+	dry, err := svc.ImportArchive(ctx, archive, archive.Size(), ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if dry.Total != 4 || dry.Created != 2 || dry.Skipped != 1 || dry.Failed != 1 {
+		t.Fatalf("unexpected dry run report %+v", dry)
+	}
+	if _, err := svc.repos.Prompts.GetByName(ctx, "greeting", false); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("dry run should not create prompts, got %v", err)
+	}
+
+	report, err := svc.ImportArchive(ctx, archive, archive.Size(), ImportOptions{OnConflict: ImportConflictAppend, CreatedBy: "importer@example.com"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Created != 2 || report.Updated != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	greeting, err := svc.repos.Prompts.GetByName(ctx, "greeting", false)
+	if err != nil {
+		t.Fatalf("get greeting: %v", err)
+	}
+	versions, err := svc.ListPromptVersions(ctx, greeting.ID, 10, 0)
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d (%v)", len(versions), err)
+	}
+	if greeting.ActiveVersionID == nil || *greeting.ActiveVersionID != versionByNumber(versions, 1).ID {
+		t.Fatalf("expected first version to be active, got %v", greeting.ActiveVersionID)
+	}
+
+	if _, err := svc.ImportArchive(ctx, bytes.NewReader([]byte("not a zip")), 9, ImportOptions{}); !errors.Is(err, ErrInvalidArchive) {
+		t.Fatalf("expected ErrInvalidArchive, got %v", err)
+	}
+}
+
+func versionByNumber(versions []*domain.PromptVersion, number int) *domain.PromptVersion {
+	for _, version := range versions {
+		if version.VersionNumber == number {
+			return version
+		}
+	}
+	return &domain.PromptVersion{}
+}
+
+func TestSeedPrompts(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "existing"}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	seeds := fstest.MapFS{
+		"support/greeting.yaml": {Data: []byte("name: greeting\ntags: [demo]\nbody: Hello {{name}}\n")},
+		"summary.json":          {Data: []byte(`{"name": "summary", "body": "Summarize {{text}}"}`)},
+		"existing.yml":          {Data: []byte("name: existing\nbody: should not be appended\n")},
+		"broken.yaml":           {Data: []byte("name: broken\n")},
+		"notes.md":              {Data: []byte("ignored")},
+	}
+
+	report, err := svc.SeedPrompts(ctx, seeds, SeedOptions{})
+	if err != nil {
+		t.Fatalf("seed prompts: %v", err)
+	}
+	if report.Total != 4 || report.Created != 2 || report.Skipped != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected seed report %+v", report)
+	}
+	greeting, err := svc.repos.Prompts.GetByName(ctx, "greeting", false)
+	if err != nil {
+		t.Fatalf("get greeting: %v", err)
+	}
+	if greeting.ActiveVersionID == nil {
+		t.Fatalf("expected seeded prompt to have an active version")
+	}
+	existing, err := svc.repos.Prompts.GetByName(ctx, "existing", false)
+	if err != nil {
+		t.Fatalf("get existing: %v", err)
+	}
+	if versions, err := svc.listAllVersions(ctx, existing.ID); err != nil || len(versions) != 0 {
+		t.Fatalf("expected existing prompt untouched, got %d versions err=%v", len(versions), err)
+	}
+
+	again, err := svc.SeedPrompts(ctx, seeds, SeedOptions{})
+	if err != nil {
+		t.Fatalf("reseed prompts: %v", err)
+	}
+	if again.Created != 0 || again.Skipped != 3 {
+		t.Fatalf("expected reseed to skip existing prompts, got %+v", again)
+	}
+}
+
+func TestExportArchiveRoundTrip(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, name := range []string{"support/greeting", "internal-draft"} {
+		prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: name, Tags: []string{"demo"}})
+		if err != nil {
+			t.Fatalf("create prompt: %v", err)
+		}
+		for i, body := range []string{"v1 {{name}}", "v2 {{name}}"} {
+			if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+				PromptID:        prompt.ID,
+				Body:            body,
+				VariablesSchema: map[string]interface{}{"type": "object"},
+				Activate:        i == 0,
+			}); err != nil {
+				t.Fatalf("create version: %v", err)
+			}
+		}
+	}
+
+	if _, err := svc.ExportArchive(ctx, zip.NewWriter(&bytes.Buffer{}), ExportArchiveOptions{Include: []string{"["}}); !errors.Is(err, ErrInvalidExportFilter) {
+		t.Fatalf("expected ErrInvalidExportFilter, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	manifest, err := svc.ExportArchive(ctx, zw, ExportArchiveOptions{Exclude: []string{"internal-*"}, Stats: true})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	if manifest.Prompts != 1 || manifest.Versions != 2 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	names := make([]string, 0, len(reader.File))
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, ",") != "prompts/support_greeting.yaml,stats/support_greeting.json,manifest.json" {
+		t.Fatalf("unexpected archive entries %v", names)
+	}
+
+	// 测试库为共享内存库，先改名源 Prompt，再把导出包导入同一工作区。
+	source, err := svc.repos.Prompts.GetByName(ctx, "support/greeting", false)
+	if err != nil {
+		t.Fatalf("get source prompt: %v", err)
+	}
+	renamed := "support/greeting-old"
+	if _, err := svc.UpdatePrompt(ctx, UpdatePromptInput{PromptID: source.ID, Name: &renamed}); err != nil {
+		t.Fatalf("rename source prompt: %v", err)
+	}
+	target := svc
+	report, err := target.ImportArchive(ctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()), ImportOptions{})
+	if err != nil || report.Created != 1 || report.Failed != 0 {
+		t.Fatalf("expected exported archive to import cleanly, got %+v (%v)", report, err)
+	}
+	imported, err := target.repos.Prompts.GetByName(ctx, "support/greeting", false)
+	if err != nil {
+		t.Fatalf("get imported prompt: %v", err)
+	}
+	versions, err := target.ListPromptVersions(ctx, imported.ID, 10, 0)
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("expected 2 imported versions, got %d (%v)", len(versions), err)
+	}
+	if imported.ActiveVersionID == nil || *imported.ActiveVersionID != versionByNumber(versions, 1).ID {
+		t.Fatalf("expected active version to round-trip")
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "csv-existing"}); err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+
+	input := "Body,Name,Tags,Description\n" +
+		"\"Summarize:\n{{text}}\",csv-summary,\"ops; support\",Daily summary\n" +
+		"missing body,,,\n" +
+		",csv-empty,,\n" +
+		"\n" +
+		"Hello,csv-existing,,\n"
+
+	dry, err := svc.ImportCSV(ctx, strings.NewReader(input), ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if dry.Total != 4 || dry.Created != 1 || dry.Failed != 2 || dry.Skipped != 1 {
+		t.Fatalf("unexpected dry run report %+v", dry)
+	}
+	if dry.Items[1].Row != 3 || dry.Items[1].Error == "" {
+		t.Fatalf("expected row 3 to report an error, got %+v", dry.Items[1])
+	}
+
+	report, err := svc.ImportCSV(ctx, strings.NewReader(input), ImportOptions{})
+	if err != nil || report.Created != 1 {
+		t.Fatalf("import: %+v (%v)", report, err)
+	}
+	summary, err := svc.repos.Prompts.GetByName(ctx, "csv-summary", false)
+	if err != nil {
+		t.Fatalf("get imported prompt: %v", err)
+	}
+	if string(summary.Tags) != `["ops","support"]` || summary.Description == nil || *summary.Description != "Daily summary" {
+		t.Fatalf("unexpected imported prompt %+v", summary)
+	}
+	if summary.ActiveVersionID == nil {
+		t.Fatalf("expected imported body to become the active version")
+	}
+
+	if _, err := svc.ImportCSV(ctx, strings.NewReader("title,text\nfoo,bar\n"), ImportOptions{}); !errors.Is(err, ErrInvalidCSV) {
+		t.Fatalf("expected ErrInvalidCSV for missing columns, got %v", err)
+	}
+}
+
+func TestPromptTemplates(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	builtin, err := svc.ListTemplates(ctx)
+	if err != nil {
+		t.Fatalf("list templates: %v", err)
+	}
+	if len(builtin) < 2 {
+		t.Fatalf("expected built-in templates to be seeded, got %d", len(builtin))
+	}
+
+	if _, err := svc.CreateTemplate(ctx, PromptTemplateInput{Slug: "Bad Slug", Name: "x", Body: "y"}); !errors.Is(err, ErrInvalidTemplate) {
+		t.Fatalf("expected ErrInvalidTemplate, got %v", err)
+	}
+	created, err := svc.CreateTemplate(ctx, PromptTemplateInput{
+		Slug:            "classifier",
+		Name:            "Classifier",
+		Body:            "Classify {{input}} into {{labels}}",
+		VariablesSchema: map[string]interface{}{"type": "object"},
+		Metadata:        map[string]interface{}{"model": "gpt-4o"},
+		Tags:            []string{"classification"},
+		CreatedBy:       "admin@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if _, err := svc.CreateTemplate(ctx, PromptTemplateInput{Slug: created.Slug, Name: "dup", Body: "dup"}); !errors.Is(err, ErrTemplateAlreadyExists) {
+		t.Fatalf("expected ErrTemplateAlreadyExists, got %v", err)
+	}
+
+	prompt, err := svc.CreatePromptFromTemplate(ctx, CreatePromptFromTemplateInput{
+		CreatePromptInput: CreatePromptInput{Name: "ticket-classifier", Tags: []string{"support", "classification"}},
+		Template:          "classifier",
+	})
+	if err != nil {
+		t.Fatalf("create from template: %v", err)
+	}
+	if string(prompt.Tags) != `["classification","support"]` {
+		t.Fatalf("expected merged tags, got %s", prompt.Tags)
+	}
+	if prompt.ActiveVersionID == nil {
+		t.Fatalf("expected scaffold version to be active")
+	}
+	version, err := svc.repos.PromptVersions.GetByID(ctx, *prompt.ActiveVersionID)
+	if err != nil {
+		t.Fatalf("get version: %v", err)
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(version.Metadata, &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if version.Body != "Classify {{input}} into {{labels}}" || metadata["template"] != "classifier" || metadata["model"] != "gpt-4o" {
+		t.Fatalf("unexpected scaffold version body=%q metadata=%v", version.Body, metadata)
+	}
+
+	if _, err := svc.CreatePromptFromTemplate(ctx, CreatePromptFromTemplateInput{CreatePromptInput: CreatePromptInput{Name: "x"}, Template: "missing"}); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+	if err := svc.DeleteTemplate(ctx, "classifier"); err != nil {
+		t.Fatalf("delete template: %v", err)
+	}
+	if _, err := svc.GetTemplate(ctx, "classifier"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected deleted template to be gone, got %v", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "compare-me"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "Hello {{name}}",
+		Metadata: map[string]interface{}{"model": "gpt-4o", "eval_scores": map[string]interface{}{"accuracy": 0.8}},
+		Activate: true,
+	})
+	if err != nil {
+		t.Fatalf("create first version: %v", err)
+	}
+	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID: prompt.ID,
+		Body:     "Hello {{name}}, welcome to {{place}}",
+		Metadata: map[string]interface{}{"model": "gpt-4o", "temperature": 0.2, "eval_scores": map[string]interface{}{"accuracy": 0.9}},
+	})
+	if err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+
+	if _, err := svc.CompareVersions(ctx, prompt.ID, []string{first.ID, first.ID}); !errors.Is(err, ErrInvalidComparison) {
+		t.Fatalf("expected ErrInvalidComparison for a single distinct id, got %v", err)
+	}
+	if _, err := svc.CompareVersions(ctx, prompt.ID, []string{first.ID, "missing"}); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("expected ErrVersionNotFound, got %v", err)
+	}
+
+	comparison, err := svc.CompareVersions(ctx, prompt.ID, []string{second.ID, first.ID})
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if len(comparison.Versions) != 2 || comparison.Versions[0].ID != second.ID || !comparison.Versions[1].Active {
+		t.Fatalf("unexpected columns %+v", comparison.Versions)
+	}
+	if got := strings.Join(comparison.Versions[0].Variables, ","); got != "name,place" {
+		t.Fatalf("expected variables name,place got %s", got)
+	}
+
+	rows := map[string]VersionComparisonRow{}
+	for _, row := range comparison.Rows {
+		rows[row.Attribute] = row
+	}
+	if !rows["length"].Differs || rows["model_config.model"].Differs || !rows["model_config.temperature"].Differs {
+		t.Fatalf("unexpected differs flags %+v", comparison.Rows)
+	}
+	if scores := rows["eval_scores.accuracy"].Values; len(scores) != 2 || scores[0] != 0.9 || scores[1] != 0.8 {
+		t.Fatalf("unexpected eval scores %v", scores)
+	}
+	if _, ok := rows["model_config.top_p"]; ok {
+		t.Fatalf("expected absent model config keys to be omitted")
+	}
+}
+
+func TestTransitionVersionStatus(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Lifecycle"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	active, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1", Status: "published", Activate: true})
+	if err != nil {
+		t.Fatalf("create active version: %v", err)
+	}
+	draft, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v2"})
+	if err != nil {
+		t.Fatalf("create draft version: %v", err)
+	}
+
+	transition := func(versionID, status string) (*domain.PromptVersion, error) {
+		return svc.TransitionVersionStatus(ctx, TransitionVersionStatusInput{
+			PromptID: prompt.ID, VersionID: versionID, Status: status, ChangedBy: "editor@example.com",
+		})
+	}
+
+	var transitionErr *StatusTransitionError
+	if _, err := transition(draft.ID, "archived"); !errors.As(err, &transitionErr) || transitionErr.From != "draft" {
+		t.Fatalf("expected draft -> archived to be rejected, got %v", err)
+	}
+	if _, err := transition(draft.ID, "retired"); !errors.Is(err, ErrInvalidVersionStatus) {
+		t.Fatalf("expected ErrInvalidVersionStatus, got %v", err)
+	}
+	if _, err := transition(active.ID, "archived"); !errors.Is(err, ErrVersionActive) {
+		t.Fatalf("expected ErrVersionActive, got %v", err)
+	}
+
+	published, err := transition(draft.ID, "published")
+	if err != nil || published.Status != domain.PromptVersionStatusPublished {
+		t.Fatalf("publish draft: %v %+v", err, published)
+	}
+	if _, err := transition(draft.ID, "draft"); !errors.Is(err, ErrInvalidStatusTransition) {
+		t.Fatalf("expected published -> draft to be rejected, got %v", err)
+	}
+	if _, err := transition(draft.ID, "archived"); err != nil {
+		t.Fatalf("archive version: %v", err)
+	}
+	if err := svc.SetActiveVersion(ctx, prompt.ID, draft.ID, "editor@example.com"); !errors.Is(err, ErrVersionArchived) {
+		t.Fatalf("expected ErrVersionArchived, got %v", err)
+	}
+
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 20)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	var transitions []string
+	for _, log := range logs {
+		if log.Action != "prompt.version.status_changed" {
+			continue
+		}
+		var payload struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		}
+		if err := json.Unmarshal(log.Payload, &payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		transitions = append(transitions, payload.From+"->"+payload.To)
+	}
+	if len(transitions) != 2 {
+		t.Fatalf("expected 2 status transition audits, got %v", transitions)
+	}
+}
+
+func TestRequirePublishedGatesActivation(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+	svc := NewService(base.repos, WithRequirePublished(true))
+
+	ctx := context.Background()
+	for _, user := range []*domain.User{
+		{ID: "owner-1", Email: "owner@example.com", HashedPassword: "x", Role: "editor", Status: "active"},
+		{ID: "reviewer-1", Email: "reviewer@example.com", HashedPassword: "x", Role: "editor", Status: "active"},
+	} {
+		if err := svc.repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "PublishedOnly", Owner: &domain.PromptOwner{Type: "user", ID: "owner-1"}})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1", CreatedBy: "owner@example.com"})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	var notPublishable *VersionNotPublishableError
+	if err := svc.SetActiveVersion(ctx, prompt.ID, version.ID, "owner@example.com"); !errors.As(err, &notPublishable) || notPublishable.Status != "draft" || notPublishable.Review != nil {
+		t.Fatalf("expected draft to be rejected, got %v", err)
+	}
+
+	if _, err := svc.SetReviewPolicy(ctx, SetReviewPolicyInput{
+		PromptID:          prompt.ID,
+		RequiredApprovals: 1,
+		Reviewers:         []domain.PromptOwner{{Type: "user", ID: "reviewer-1"}},
+		By:                ReviewActor{UserID: "owner-1", Actor: "owner@example.com"},
+	}); err != nil {
+		t.Fatalf("set review policy: %v", err)
+	}
+	if _, err := svc.TransitionVersionStatus(ctx, TransitionVersionStatusInput{PromptID: prompt.ID, VersionID: version.ID, Status: "published"}); err != nil {
+		t.Fatalf("publish version: %v", err)
+	}
+	err = svc.SetActiveVersion(ctx, prompt.ID, version.ID, "owner@example.com")
+	if !errors.As(err, &notPublishable) || notPublishable.Status != "published" || notPublishable.Review == nil || notPublishable.Review.ValidApprovals != 0 {
+		t.Fatalf("expected unapproved version to be rejected, got %v", err)
+	}
+
+	if _, err := svc.ApproveVersion(ctx, ApproveVersionInput{
+		PromptID:  prompt.ID,
+		VersionID: version.ID,
+		By:        ReviewActor{UserID: "reviewer-1", Actor: "reviewer@example.com"},
+	}); err != nil {
+		t.Fatalf("approve version: %v", err)
+	}
+	if err := svc.SetActiveVersion(ctx, prompt.ID, version.ID, "owner@example.com"); err != nil {
+		t.Fatalf("activate published version: %v", err)
+	}
+}
+
+func TestActivatePreviousVersion(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Rollback"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if _, err := svc.ActivatePreviousVersion(ctx, ActivateVersionInput{PromptID: prompt.ID}); !errors.Is(err, ErrNoPreviousVersion) {
+		t.Fatalf("expected ErrNoPreviousVersion, got %v", err)
+	}
+
+	first, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1", Activate: true})
+	if err != nil {
+		t.Fatalf("create first version: %v", err)
+	}
+	second, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v2", Activate: true})
+	if err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+	// 重复激活同一版本不覆盖回滚目标。
+	if err := svc.SetActiveVersion(ctx, prompt.ID, second.ID, "editor@example.com"); err != nil {
+		t.Fatalf("reactivate second version: %v", err)
+	}
+	current, err := svc.GetPrompt(ctx, prompt.ID)
+	if err != nil {
+		t.Fatalf("get prompt: %v", err)
+	}
+	if current.PreviousActiveVersionID == nil || *current.PreviousActiveVersionID != first.ID {
+		t.Fatalf("expected previous active version %s, got %v", first.ID, current.PreviousActiveVersionID)
+	}
+
+	result, err := svc.ActivatePreviousVersion(ctx, ActivateVersionInput{PromptID: prompt.ID, ActivatedBy: "oncall@example.com", ReleaseNote: "v2 broke output"})
+	if err != nil {
+		t.Fatalf("activate previous version: %v", err)
+	}
+	if result.Event.VersionID != first.ID || result.Event.PreviousVersionID == nil || *result.Event.PreviousVersionID != second.ID {
+		t.Fatalf("unexpected rollback event %+v", result.Event)
+	}
+	current, err = svc.GetPrompt(ctx, prompt.ID)
+	if err != nil {
+		t.Fatalf("get prompt: %v", err)
+	}
+	if *current.ActiveVersionID != first.ID || *current.Body != "v1" || *current.PreviousActiveVersionID != second.ID {
+		t.Fatalf("unexpected prompt after rollback %+v", current)
+	}
+
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 20)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	var rolledBack map[string]interface{}
+	for _, log := range logs {
+		if log.Action == "prompt.version.rolled_back" {
+			if err := json.Unmarshal(log.Payload, &rolledBack); err != nil {
+				t.Fatalf("decode payload: %v", err)
 			}
 		}
 	}
-	if !found {
-		t.Fatalf("expected prompt.version.activated audit log")
+	if rolledBack["from_version_id"] != second.ID || rolledBack["to_version_id"] != first.ID || rolledBack["from_version_number"] != float64(2) {
+		t.Fatalf("unexpected rollback audit %v", rolledBack)
 	}
 }
 
-func TestCreatePromptAfterSoftDelete(t *testing.T) {
-	svc, cleanup := setupPromptService(t)
+func TestCanaryActivationRollsBackOnErrorSpike(t *testing.T) {
+	base, cleanup := setupPromptService(t)
 	defer cleanup()
+	notifier := &recordingAlertNotifier{}
+	svc := NewService(base.repos, WithAlertNotifier(notifier))
 
 	ctx := context.Background()
-	desc := "First"
-	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Reusable", Description: &desc, CreatedBy: "first@example.com"})
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Canary"})
 	if err != nil {
 		t.Fatalf("create prompt: %v", err)
 	}
+	stable, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "stable", Activate: true})
+	if err != nil {
+		t.Fatalf("create stable version: %v", err)
+	}
+	candidate, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "candidate"})
+	if err != nil {
+		t.Fatalf("create candidate version: %v", err)
+	}
 
-	if err := svc.DeletePrompt(ctx, prompt.ID, "tester@example.com"); err != nil {
+	start := StartCanaryInput{PromptID: prompt.ID, VersionID: candidate.ID, Percent: 10, MaxErrorRateDelta: 5, MinCalls: 10}
+	if _, err := svc.StartCanary(ctx, StartCanaryInput{PromptID: prompt.ID, VersionID: candidate.ID, Percent: 100, MaxErrorRateDelta: 5}); !errors.Is(err, ErrInvalidCanary) {
+		t.Fatalf("expected ErrInvalidCanary, got %v", err)
+	}
+	canary, err := svc.StartCanary(ctx, start)
+	if err != nil {
+		t.Fatalf("start canary: %v", err)
+	}
+	if _, err := svc.StartCanary(ctx, start); !errors.Is(err, ErrCanaryRunning) {
+		t.Fatalf("expected ErrCanaryRunning, got %v", err)
+	}
+
+	for roll, want := range map[int]string{5: candidate.ID, 10: stable.ID} {
+		svc.canaryRoll = func(int) int { return roll }
+		result, err := svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID})
+		if err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		if result.VersionID != want || result.Canary != (want == candidate.ID) {
+			t.Fatalf("roll %d: expected version %s, got %+v", roll, want, result)
+		}
+	}
+
+	var logs []*domain.PromptExecutionLog
+	for i := 0; i < 10; i++ {
+		status := "success"
+		if i < 3 {
+			status = "error"
+		}
+		logs = append(logs,
+			&domain.PromptExecutionLog{ID: uuid.NewString(), PromptID: prompt.ID, PromptVersionID: candidate.ID, Status: status},
+			&domain.PromptExecutionLog{ID: uuid.NewString(), PromptID: prompt.ID, PromptVersionID: stable.ID, Status: "success"},
+		)
+	}
+	if err := svc.repos.PromptExecutionLog.CreateBatch(ctx, logs); err != nil {
+		t.Fatalf("create execution logs: %v", err)
+	}
+	if err := svc.EvaluateCanaries(ctx); err != nil {
+		t.Fatalf("evaluate canaries: %v", err)
+	}
+	if _, err := svc.GetCanary(ctx, prompt.ID); !errors.Is(err, ErrCanaryNotFound) {
+		t.Fatalf("expected canary to be rolled back, got %v", err)
+	}
+	if len(notifier.events) != 1 || notifier.events[0].RuleID != canary.ID || notifier.events[0].State != domain.CanaryStatusRolledBack {
+		t.Fatalf("expected rollback notification, got %+v", notifier.events)
+	}
+	svc.canaryRoll = func(int) int { return 0 }
+	result, err := svc.RenderPrompt(ctx, RenderPromptInput{PromptID: prompt.ID})
+	if err != nil || result.VersionID != stable.ID {
+		t.Fatalf("expected stable version after rollback, got %+v %v", result, err)
+	}
+
+	// 正式激活灰度版本时灰度结束为 promoted。
+	if _, err := svc.StartCanary(ctx, start); err != nil {
+		t.Fatalf("restart canary: %v", err)
+	}
+	if _, err := svc.PromoteCanary(ctx, ActivateVersionInput{PromptID: prompt.ID, ActivatedBy: "editor@example.com"}); err != nil {
+		t.Fatalf("promote canary: %v", err)
+	}
+	current, err := svc.GetPrompt(ctx, prompt.ID)
+	if err != nil || *current.ActiveVersionID != candidate.ID {
+		t.Fatalf("expected candidate to be active, got %+v %v", current, err)
+	}
+	if _, err := svc.GetCanary(ctx, prompt.ID); !errors.Is(err, ErrCanaryNotFound) {
+		t.Fatalf("expected canary to be finished after promotion, got %v", err)
+	}
+}
+
+type fixedFreeze struct {
+	window string
+	until  time.Time
+}
+
+func (f fixedFreeze) Active(string, time.Time) (string, time.Time, bool) {
+	return f.window, f.until, true
+}
+
+func TestChangeFreezeBlocksActivationAndDeletion(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := base.CreatePrompt(ctx, CreatePromptInput{Name: "Frozen"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := base.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1"})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	svc := NewService(base.repos, WithChangeFreeze(fixedFreeze{window: "launch", until: until}))
+
+	err = svc.SetActiveVersion(ctx, prompt.ID, version.ID, "editor@example.com")
+	var frozen *ChangeFreezeError
+	if !errors.As(err, &frozen) || frozen.Window != "launch" || !frozen.Until.Equal(until) || frozen.Operation != FreezeOperationActivate {
+		t.Fatalf("expected activation to be frozen, got %v", err)
+	}
+	if err := svc.DeletePrompt(ctx, prompt.ID, "editor@example.com"); !errors.Is(err, ErrChangeFreeze) {
+		t.Fatalf("expected deletion to be frozen, got %v", err)
+	}
+
+	overrideCtx := domain.WithFreezeOverride(ctx)
+	if err := svc.SetActiveVersion(overrideCtx, prompt.ID, version.ID, "admin@example.com"); err != nil {
+		t.Fatalf("activate with override: %v", err)
+	}
+	logs, err := svc.repos.PromptAuditLog.ListByPrompt(ctx, prompt.ID, 20)
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	var overridden map[string]interface{}
+	for _, log := range logs {
+		if log.Action == "prompt.freeze.overridden" {
+			if err := json.Unmarshal(log.Payload, &overridden); err != nil {
+				t.Fatalf("decode payload: %v", err)
+			}
+		}
+	}
+	if overridden["window"] != "launch" || overridden["operation"] != FreezeOperationActivate {
+		t.Fatalf("unexpected override audit %v", overridden)
+	}
+	if err := svc.DeletePrompt(overrideCtx, prompt.ID, "admin@example.com"); err != nil {
+		t.Fatalf("delete with override: %v", err)
+	}
+}
+
+func TestMutationsPublishEvents(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	var published []events.Event
+	bus := events.NewBus()
+	bus.Subscribe(func(_ context.Context, event events.Event) {
+		published = append(published, event)
+	})
+	svc := NewService(base.repos, WithEventBus(bus))
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "Evented"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "v1", Activate: true}); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	description := "updated"
+	if _, err := svc.UpdatePrompt(ctx, UpdatePromptInput{PromptID: prompt.ID, Description: &description}); err != nil {
+		t.Fatalf("update prompt: %v", err)
+	}
+	if err := svc.DeletePrompt(ctx, prompt.ID, "editor@example.com"); err != nil {
 		t.Fatalf("delete prompt: %v", err)
 	}
 
-	secondDesc := "Second"
-	recreated, err := svc.CreatePrompt(ctx, CreatePromptInput{
-		Name:        "Reusable",
-		Description: &secondDesc,
-		CreatedBy:   "second@example.com",
+	want := []string{events.PromptCreated, events.VersionActivated, events.VersionCreated, events.PromptUpdated, events.PromptDeleted}
+	if len(published) != len(want) {
+		t.Fatalf("expected events %v, got %+v", want, published)
+	}
+	for i, event := range published {
+		if event.Type != want[i] || event.PromptID != prompt.ID || event.WorkspaceID != prompt.WorkspaceID || event.OccurredAt.IsZero() {
+			t.Fatalf("unexpected event %d: %+v", i, event)
+		}
+	}
+}
+
+func TestPromptBodySizeLimit(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+	svc := NewService(base.repos, WithMaxBodyBytes(16))
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "SizedPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	// 上限按去除首尾空白后的正文计算。
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "  " + strings.Repeat("a", 16) + "\n"})
+	if err != nil {
+		t.Fatalf("create version at limit: %v", err)
+	}
+
+	_, err = svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: strings.Repeat("a", 17)})
+	var tooLarge *BodyTooLargeError
+	if !errors.As(err, &tooLarge) || !errors.Is(err, ErrBodyTooLarge) || tooLarge.Size != 17 || tooLarge.Limit != 16 {
+		t.Fatalf("expected body too large error, got %v", err)
+	}
+	if _, err := svc.ValidatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: strings.Repeat("b", 17)}); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected validation to reject oversized body, got %v", err)
+	}
+	// 与创建版本一致，上限按 NFC 规范化后的正文计算：8 个分解形式的 é 原始 24 字节，规范化后为 16 字节。
+	decomposed := strings.Repeat("e\u0301", 8)
+	if _, err := svc.ValidatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: decomposed}); err != nil {
+		t.Fatalf("expected validation to measure normalized body, got %v", err)
+	}
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: decomposed}); err != nil {
+		t.Fatalf("expected create to accept normalized body, got %v", err)
+	}
+	// 多字节字符按 UTF-8 字节计数。
+	if _, err := svc.SetVersionLocale(ctx, SetVersionLocaleInput{PromptID: prompt.ID, VersionID: version.ID, Locale: "zh-CN", Body: strings.Repeat("你", 6)}); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected locale body to be rejected, got %v", err)
+	}
+}
+
+func TestNormalizeVersionBody(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+	svc := NewService(base.repos, WithStripInvisibleChars(true))
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "UnicodePrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	// 分解形式的 é、零宽空格、BOM 与 emoji 中的零宽连接符。
+	raw := "\uFEFFCafe\u0301 menu\u200B “special” \U0001F468\u200D\U0001F469"
+	report, err := svc.ValidatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: raw})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	var messages []string
+	rules := map[string]int{}
+	for _, issue := range report.Checks[2].Issues {
+		rules[issue.Rule]++
+		messages = append(messages, issue.Message)
+	}
+	if rules["unicode_normalization"] != 1 || rules["invisible_character"] != 3 || rules["smart_quotes"] != 1 || !report.Valid {
+		t.Fatalf("unexpected lint issues %v", messages)
+	}
+	joined := strings.Join(messages, "\n")
+	// 零宽连接符用于 emoji 组合，只提示不移除。
+	if !strings.Contains(joined, "U+200B ZERO WIDTH SPACE (removed on save)") || strings.Contains(joined, "ZERO WIDTH JOINER (removed on save)") {
+		t.Fatalf("unexpected invisible character messages %v", messages)
+	}
+
+	version, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: raw})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	want := "Café menu “special” \U0001F468\u200D\U0001F469"
+	if version.Body != want {
+		t.Fatalf("expected normalized body %q, got %q", want, version.Body)
+	}
+	// 规范化后内容一致的版本视为重复。
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: want + "\u200B"}); !errors.Is(err, ErrDuplicateVersion) {
+		t.Fatalf("expected duplicate after normalization, got %v", err)
+	}
+	if _, err := svc.SetVersionLocale(ctx, SetVersionLocaleInput{PromptID: prompt.ID, VersionID: version.ID, Locale: "fr", Body: "\u200B\uFEFF"}); !errors.Is(err, ErrBodyRequired) {
+		t.Fatalf("expected invisible-only locale body to be rejected, got %v", err)
+	}
+
+	// 未开启移除时只规范化为 NFC。
+	if got := NormalizeBody("e\u0301\u200B", false); got != "é\u200B" {
+		t.Fatalf("expected NFC only, got %q", got)
+	}
+}
+
+type replayGateway struct {
+	requests []llm.CompletionRequest
+	err      error
+}
+
+func (g *replayGateway) Complete(_ context.Context, req llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	g.requests = append(g.requests, req)
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &llm.CompletionResponse{Output: "Bonjour Ada!", Metadata: map[string]interface{}{"model": "new-model"}}, nil
+}
+
+func TestReplayExecution(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := base.CreatePrompt(ctx, CreatePromptInput{Name: "ReplayPrompt"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	version, err := base.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "Greet {{ name }}", Activate: true})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	if _, err := base.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "Welcome {{ name }}", Activate: true}); err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+	report, err := base.RecordExecutions(ctx, []ExecutionRecordInput{
+		{PromptID: prompt.ID, VersionID: version.ID, Status: "success", RequestPayload: json.RawMessage(`{"variables":{"name":"Ada"},"prompt":"Greet Ada"}`), ResponseMetadata: json.RawMessage(`{"output":"Hello Ada!"}`)},
+		{PromptID: prompt.ID, VersionID: version.ID, Status: "success", RequestPayload: json.RawMessage(`{"input":"Ada"}`)},
+	}, "")
+	if err != nil || report.Recorded != 2 {
+		t.Fatalf("record executions: %v %+v", err, report)
+	}
+	replayable, legacy := report.Items[0].ID, report.Items[1].ID
+
+	// 未注入网关时只能重新渲染；回放使用原版本而非当前激活版本。
+	replay, err := base.ReplayExecution(ctx, ReplayExecutionInput{ExecutionID: replayable, Unified: true})
+	if err != nil {
+		t.Fatalf("replay without invoke: %v", err)
+	}
+	if replay.Version.ID != version.ID || replay.Prompt != "Greet Ada" || replay.Compared != ReplayComparePrompt || !replay.BaselineRecorded || replay.Changed || replay.Invoked {
+		t.Fatalf("unexpected render replay %+v", replay)
+	}
+	if _, err := base.ReplayExecution(ctx, ReplayExecutionInput{ExecutionID: replayable, Invoke: true}); !errors.Is(err, ErrGatewayUnavailable) {
+		t.Fatalf("expected gateway unavailable, got %v", err)
+	}
+
+	gateway := &replayGateway{}
+	svc := NewService(base.repos, WithGateway(gateway))
+	replay, err = svc.ReplayExecution(ctx, ReplayExecutionInput{ExecutionID: replayable, Invoke: true, Granularity: DiffGranularityWord, Unified: true})
+	if err != nil {
+		t.Fatalf("replay with invoke: %v", err)
+	}
+	if len(gateway.requests) != 1 || gateway.requests[0].Prompt != "Greet Ada" || gateway.requests[0].PromptVersionID != version.ID {
+		t.Fatalf("unexpected gateway requests %+v", gateway.requests)
+	}
+	if !replay.Invoked || replay.Compared != ReplayCompareOutput || replay.OriginalOutput != "Hello Ada!" || replay.Output != "Bonjour Ada!" || !replay.Changed || replay.Metadata["model"] != "new-model" {
+		t.Fatalf("unexpected invoke replay %+v", replay)
+	}
+	if len(replay.Diff) == 0 || replay.Diff[0].Type != "delete" || !strings.Contains(replay.Unified, "-Hello Ada!") || !strings.Contains(replay.Unified, "+Bonjour Ada!") {
+		t.Fatalf("unexpected diff %+v %q", replay.Diff, replay.Unified)
+	}
+	// 回放不写入执行日志。
+	logs, err := svc.ListExecutionLogs(ctx, prompt.ID, 10)
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("expected replay not to record executions, got %d: %v", len(logs), err)
+	}
+
+	gateway.err = errors.New("upstream unavailable")
+	if _, err := svc.ReplayExecution(ctx, ReplayExecutionInput{ExecutionID: replayable, Invoke: true}); !errors.Is(err, ErrGatewayFailed) {
+		t.Fatalf("expected gateway failure, got %v", err)
+	}
+	if _, err := svc.ReplayExecution(ctx, ReplayExecutionInput{ExecutionID: legacy}); !errors.Is(err, ErrExecutionNotReplayable) {
+		t.Fatalf("expected not replayable without variables, got %v", err)
+	}
+	if _, err := svc.ReplayExecution(ctx, ReplayExecutionInput{ExecutionID: "missing"}); !errors.Is(err, ErrExecutionNotFound) {
+		t.Fatalf("expected execution not found, got %v", err)
+	}
+	if _, err := svc.ReplayExecution(domain.WithWorkspace(ctx, "other"), ReplayExecutionInput{ExecutionID: replayable}); !errors.Is(err, ErrExecutionNotFound) {
+		t.Fatalf("expected execution in other workspace to be hidden, got %v", err)
+	}
+}
+
+func TestVersionTargetModels(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	models := llm.NewRegistry([]llm.Model{{ID: "gpt-4o-mini", Family: "gpt-4o"}, {ID: "gpt-4o-2024-08-06", Family: "gpt-4o"}, {ID: "claude-3-5-sonnet", Family: "claude-3-5"}})
+	svc := NewService(base.repos, WithModelRegistry(models))
+	ctx := context.Background()
+
+	create := func(name string, metadata interface{}) (*domain.PromptVersion, error) {
+		prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: name})
+		if err != nil {
+			t.Fatalf("create prompt %s: %v", name, err)
+		}
+		return svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "Answer " + name, Metadata: metadata, Activate: true})
+	}
+
+	// 目标模型规范化为小写并去重，系列名与模型 ID 均可声明。
+	family, err := create("FamilyTargeted", map[string]interface{}{"target_models": []string{"GPT-4o", "gpt-4o"}})
+	if err != nil {
+		t.Fatalf("create family-targeted version: %v", err)
+	}
+	if len(family.TargetModels) != 1 || family.TargetModels[0] != "gpt-4o" {
+		t.Fatalf("expected normalized targets, got %v", family.TargetModels)
+	}
+	if _, err := create("ModelTargeted", map[string]interface{}{"target_models": []string{"claude-3-5-sonnet"}}); err != nil {
+		t.Fatalf("create model-targeted version: %v", err)
+	}
+	if _, err := create("Untargeted", nil); err != nil {
+		t.Fatalf("create untargeted version: %v", err)
+	}
+
+	for _, metadata := range []interface{}{
+		map[string]interface{}{"target_models": []string{"llama-3"}},
+		map[string]interface{}{"target_models": "gpt-4o"},
+		map[string]interface{}{"target_models": []string{"gpt 4o"}},
+	} {
+		if _, err := create(uuid.NewString(), metadata); !errors.Is(err, ErrInvalidTargetModels) {
+			t.Fatalf("expected ErrInvalidTargetModels for %v, got %v", metadata, err)
+		}
+	}
+
+	// 按模型 ID 筛选同时命中声明其系列的版本，按系列筛选同时命中声明其下模型的版本。
+	names := func(model string) []string {
+		prompts, _, err := svc.ListPrompts(ctx, ListPromptsOptions{Model: model})
+		if err != nil {
+			t.Fatalf("list prompts by model %s: %v", model, err)
+		}
+		var names []string
+		for _, prompt := range prompts {
+			names = append(names, prompt.Name)
+		}
+		return names
+	}
+	if got := names("gpt-4o-mini"); len(got) != 1 || got[0] != "FamilyTargeted" {
+		t.Fatalf("expected family-targeted prompt for gpt-4o-mini, got %v", got)
+	}
+	if got := names("claude-3-5"); len(got) != 1 || got[0] != "ModelTargeted" {
+		t.Fatalf("expected model-targeted prompt for claude-3-5, got %v", got)
+	}
+	if _, _, err := svc.ListPrompts(ctx, ListPromptsOptions{Model: "llama-3"}); !errors.Is(err, ErrUnknownModel) {
+		t.Fatalf("expected ErrUnknownModel, got %v", err)
+	}
+
+	// 未配置注册表时只校验名称格式。
+	if _, err := NewService(base.repos).targetModels(json.RawMessage(`{"target_models":["llama-3"]}`)); err != nil {
+		t.Fatalf("expected any model name without registry, got %v", err)
+	}
+
+	report, err := svc.ValidatePromptVersion(ctx, CreatePromptVersionInput{PromptID: family.PromptID, Body: "Answer", Metadata: map[string]interface{}{"target_models": []string{"llama-3"}}})
+	if err != nil {
+		t.Fatalf("validate version: %v", err)
+	}
+	if report.Valid || report.Checks[4].Name != "metadata" || report.Checks[4].Passed {
+		t.Fatalf("expected metadata check to fail, got %+v", report.Checks)
+	}
+}
+
+func TestListPromptsFlagsDeprecatedModels(t *testing.T) {
+	base, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	models := llm.NewRegistry([]llm.Model{
+		{ID: "gpt-4o"},
+		{ID: "gpt-4-turbo", Status: llm.ModelStatusDeprecated, Replacement: "GPT-4o"},
+		{ID: "text-davinci-003", Status: llm.ModelStatusRetired},
 	})
+	svc := NewService(base.repos, WithModelRegistry(models))
+	ctx := context.Background()
+
+	for name, metadata := range map[string]interface{}{
+		"Deprecated": map[string]interface{}{"model": "GPT-4-Turbo"},
+		"Retired":    map[string]interface{}{"model": "text-davinci-003"},
+		"Current":    map[string]interface{}{"model": "gpt-4o"},
+		"Unknown":    map[string]interface{}{"model": "llama-3"},
+		"NoModel":    nil,
+	} {
+		prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: name})
+		if err != nil {
+			t.Fatalf("create prompt %s: %v", name, err)
+		}
+		if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "Answer " + name, Metadata: metadata, Activate: true}); err != nil {
+			t.Fatalf("create version %s: %v", name, err)
+		}
+	}
+
+	prompts, _, err := svc.ListPrompts(ctx, ListPromptsOptions{})
 	if err != nil {
-		t.Fatalf("recreate prompt: %v", err)
+		t.Fatalf("list prompts: %v", err)
 	}
-	if recreated == nil {
-		t.Fatalf("expected recreated prompt")
+	flagged := map[string]*domain.ModelDeprecation{}
+	for _, prompt := range prompts {
+		if prompt.ModelDeprecation != nil {
+			flagged[prompt.Name] = prompt.ModelDeprecation
+		}
 	}
-	if recreated.Status != "active" {
-		t.Fatalf("expected status active got %s", recreated.Status)
+	if len(flagged) != 2 {
+		t.Fatalf("expected deprecated and retired prompts flagged, got %v", flagged)
 	}
-	if recreated.DeletedAt != nil {
-		t.Fatalf("expected deleted_at cleared")
+	if got := flagged["Deprecated"]; got == nil || got.Model != "gpt-4-turbo" || got.Status != llm.ModelStatusDeprecated || got.Replacement != "gpt-4o" {
+		t.Fatalf("unexpected deprecation %+v", got)
 	}
-	if recreated.Name != "Reusable" {
-		t.Fatalf("unexpected name %s", recreated.Name)
+	if got := flagged["Retired"]; got == nil || got.Status != llm.ModelStatusRetired || got.Replacement != "" {
+		t.Fatalf("unexpected retirement %+v", got)
+	}
+
+	// 注册表中没有弃用模型时不标记。
+	prompts, _, err = NewService(base.repos).ListPrompts(ctx, ListPromptsOptions{})
+	if err != nil {
+		t.Fatalf("list prompts without registry: %v", err)
+	}
+	for _, prompt := range prompts {
+		if prompt.ModelDeprecation != nil {
+			t.Fatalf("expected no flags without registry, got %+v on %s", prompt.ModelDeprecation, prompt.Name)
+		}
+	}
+}
+
+func TestVersionAccessIsWorkspaceScoped(t *testing.T) {
+	svc, cleanup := setupPromptService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	prompt, err := svc.CreatePrompt(ctx, CreatePromptInput{Name: "ScopedVersions"})
+	if err != nil {
+		t.Fatalf("create prompt: %v", err)
+	}
+	base, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "Hello {{name}}"})
+	if err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	if _, err := svc.CreatePromptVersion(ctx, CreatePromptVersionInput{PromptID: prompt.ID, Body: "Hi {{name}}", Activate: true}); err != nil {
+		t.Fatalf("create second version: %v", err)
+	}
+	if _, err := svc.SetVersionLocale(ctx, SetVersionLocaleInput{PromptID: prompt.ID, VersionID: base.ID, Locale: "fr", Body: "Bonjour {{name}}"}); err != nil {
+		t.Fatalf("set locale: %v", err)
+	}
+
+	// 其他工作区即使知道 Prompt 与版本 ID 也无法读写其版本。
+	otherCtx := domain.WithWorkspace(ctx, "other")
+	if _, err := svc.DiffPromptVersion(otherCtx, prompt.ID, base.ID, DiffPromptVersionOptions{CompareToActive: true}); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected diff from another workspace to fail, got %v", err)
+	}
+	if _, err := svc.PreviewPromptVersion(otherCtx, prompt.ID, base.ID, ""); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected preview from another workspace to fail, got %v", err)
+	}
+	if _, err := svc.ListVersionLocales(otherCtx, prompt.ID, base.ID); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected locale listing from another workspace to fail, got %v", err)
+	}
+	if _, err := svc.SetVersionLocale(otherCtx, SetVersionLocaleInput{PromptID: prompt.ID, VersionID: base.ID, Locale: "de", Body: "Hallo"}); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected locale write from another workspace to fail, got %v", err)
+	}
+	if err := svc.DeleteVersionLocale(otherCtx, prompt.ID, base.ID, "fr", "intruder"); !errors.Is(err, ErrPromptNotFound) {
+		t.Fatalf("expected locale delete from another workspace to fail, got %v", err)
+	}
+
+	defaultCtx := domain.WithWorkspace(ctx, domain.DefaultWorkspaceID)
+	if _, err := svc.DiffPromptVersion(defaultCtx, prompt.ID, base.ID, DiffPromptVersionOptions{CompareToActive: true}); err != nil {
+		t.Fatalf("diff in owning workspace: %v", err)
+	}
+	locales, err := svc.ListVersionLocales(defaultCtx, prompt.ID, base.ID)
+	if err != nil || len(locales) != 1 {
+		t.Fatalf("expected locale to survive, got %d (%v)", len(locales), err)
 	}
 }
@@ -0,0 +1,126 @@
+package prompt
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+// maxStatsBuckets 限制单次统计的分桶数量，避免按小时查询超长区间。
+const maxStatsBuckets = 1000
+
+// ExecutionStatsOptions 控制执行统计的时间窗口、分桶粒度与时区。
+type ExecutionStatsOptions struct {
+	// Days 为包含今天在内的统计天数，默认 7；指定 From 时忽略。
+	Days int
+	// From/To 指定自定义区间 [From, To)，To 为空时截至当前。
+	From time.Time
+	To   time.Time
+	// Granularity 为 hour/day/week/month，默认 day。
+	Granularity string
+	// Timezone 为 IANA 时区名（如 Asia/Shanghai），为空时按 UTC 分桶。
+	Timezone string
+}
+
+// GetExecutionStats 返回执行统计，按所选时区与粒度分桶。
+func (s *Service) GetExecutionStats(ctx context.Context, promptID string, opts ExecutionStatsOptions) ([]*domain.PromptExecutionAggregate, error) {
+	loc, err := loadTimezone(opts.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	granularity, err := normalizeGranularity(opts.Granularity)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := statsRange(opts, loc, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if bucketCount(from, to, granularity) > maxStatsBuckets {
+		return nil, ErrInvalidTimeRange
+	}
+
+	if _, err := s.GetPrompt(ctx, promptID); err != nil {
+		return nil, err
+	}
+
+	aggregateOpts := domain.ExecutionAggregateOptions{
+		From:        from,
+		Granularity: granularity,
+		Location:    loc,
+	}
+	if !opts.To.IsZero() {
+		aggregateOpts.To = to
+	}
+	return s.repos.PromptExecutionLog.AggregateUsage(ctx, promptID, aggregateOpts)
+}
+
+// statsRange 返回统计区间：指定 From 时使用 [From, To)，否则为最近 Days 个自然日（含今天）。
+func statsRange(opts ExecutionStatsOptions, loc *time.Location, now time.Time) (time.Time, time.Time, error) {
+	if !opts.From.IsZero() {
+		to := opts.To
+		if to.IsZero() {
+			to = now
+		}
+		if !opts.From.Before(to) {
+			return time.Time{}, time.Time{}, ErrInvalidTimeRange
+		}
+		return opts.From, to, nil
+	}
+	if !opts.To.IsZero() {
+		return time.Time{}, time.Time{}, ErrInvalidTimeRange
+	}
+	days := opts.Days
+	if days <= 0 {
+		days = 7
+	}
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()-days+1, 0, 0, 0, 0, loc), now, nil
+}
+
+func normalizeGranularity(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", domain.GranularityDay:
+		return domain.GranularityDay, nil
+	case domain.GranularityHour:
+		return domain.GranularityHour, nil
+	case domain.GranularityWeek:
+		return domain.GranularityWeek, nil
+	case domain.GranularityMonth:
+		return domain.GranularityMonth, nil
+	default:
+		return "", ErrInvalidGranularity
+	}
+}
+
+// bucketCount 估算区间内的分桶数量，月按 28 天计以取上限。
+func bucketCount(from, to time.Time, granularity string) int64 {
+	unit := 24 * time.Hour
+	switch granularity {
+	case domain.GranularityHour:
+		unit = time.Hour
+	case domain.GranularityWeek:
+		unit = 7 * 24 * time.Hour
+	case domain.GranularityMonth:
+		unit = 28 * 24 * time.Hour
+	}
+	return int64(to.Sub(from)/unit) + 1
+}
+
+// loadTimezone 解析 IANA 时区名，空值视为 UTC；拒绝依赖服务器本地时区的 Local。
+func loadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	if strings.EqualFold(name, "local") {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
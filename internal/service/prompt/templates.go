@@ -0,0 +1,209 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+var templateSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// PromptTemplateInput 定义创建或更新脚手架模板的字段；更新时按 Slug 定位并整体替换其余字段。
+type PromptTemplateInput struct {
+	Slug            string
+	Name            string
+	Description     *string
+	Body            string
+	VariablesSchema interface{}
+	Metadata        interface{}
+	Tags            []string
+	CreatedBy       string
+}
+
+// CreatePromptFromTemplateInput 基于模板创建 Prompt：模板标签与请求标签合并，未提供描述时沿用模板描述。
+type CreatePromptFromTemplateInput struct {
+	CreatePromptInput
+	Template string
+	// Body 非空时覆盖模板正文，变量定义仍取自模板。
+	Body string
+}
+
+// ListTemplates 返回全部脚手架模板。
+func (s *Service) ListTemplates(ctx context.Context) ([]*domain.PromptTemplate, error) {
+	templates, err := s.repos.PromptTemplates.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []*domain.PromptTemplate{}
+	}
+	return templates, nil
+}
+
+// GetTemplate 按 slug 读取脚手架模板。
+func (s *Service) GetTemplate(ctx context.Context, slug string) (*domain.PromptTemplate, error) {
+	template, err := s.repos.PromptTemplates.GetBySlug(ctx, strings.TrimSpace(slug))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// CreateTemplate 新建脚手架模板，slug 重复时返回 ErrTemplateAlreadyExists。
+func (s *Service) CreateTemplate(ctx context.Context, input PromptTemplateInput) (*domain.PromptTemplate, error) {
+	template, err := buildPromptTemplate(input)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = uuid.NewString()
+	template.CreatedBy = optionalString(input.CreatedBy)
+	if err := s.repos.PromptTemplates.Create(ctx, template); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTemplateAlreadyExists
+		}
+		return nil, err
+	}
+	return s.GetTemplate(ctx, template.Slug)
+}
+
+// UpdateTemplate 整体替换脚手架模板内容，slug 不可修改。
+func (s *Service) UpdateTemplate(ctx context.Context, input PromptTemplateInput) (*domain.PromptTemplate, error) {
+	template, err := buildPromptTemplate(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repos.PromptTemplates.Update(ctx, template); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return s.GetTemplate(ctx, template.Slug)
+}
+
+// DeleteTemplate 删除脚手架模板，已基于该模板创建的 Prompt 不受影响。
+func (s *Service) DeleteTemplate(ctx context.Context, slug string) error {
+	if err := s.repos.PromptTemplates.Delete(ctx, strings.TrimSpace(slug)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// CreatePromptFromTemplate 按模板创建 Prompt 并发布、启用首个版本，版本 metadata 记录来源模板。
+func (s *Service) CreatePromptFromTemplate(ctx context.Context, input CreatePromptFromTemplateInput) (*domain.Prompt, error) {
+	template, err := s.GetTemplate(ctx, input.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	createInput := input.CreatePromptInput
+	if createInput.Description == nil {
+		createInput.Description = template.Description
+	}
+	if len(template.Tags) > 0 {
+		var templateTags []string
+		if err := json.Unmarshal(template.Tags, &templateTags); err != nil {
+			return nil, err
+		}
+		createInput.Tags = mergeTags(templateTags, createInput.Tags)
+	}
+
+	metadata := map[string]interface{}{}
+	if len(template.Metadata) > 0 {
+		if err := json.Unmarshal(template.Metadata, &metadata); err != nil {
+			return nil, err
+		}
+	}
+	metadata["template"] = template.Slug
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		body = template.Body
+	}
+
+	prompt, err := s.CreatePrompt(ctx, createInput)
+	if err != nil {
+		return nil, err
+	}
+	var variablesSchema interface{}
+	if len(template.VariablesSchema) > 0 {
+		variablesSchema = template.VariablesSchema
+	}
+	if _, err := s.CreatePromptVersion(ctx, CreatePromptVersionInput{
+		PromptID:        prompt.ID,
+		Body:            body,
+		VariablesSchema: variablesSchema,
+		Metadata:        metadata,
+		Status:          "published",
+		CreatedBy:       input.CreatedBy,
+		Activate:        true,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetPrompt(ctx, prompt.ID)
+}
+
+func buildPromptTemplate(input PromptTemplateInput) (*domain.PromptTemplate, error) {
+	template := &domain.PromptTemplate{
+		Slug:        strings.TrimSpace(input.Slug),
+		Name:        strings.TrimSpace(input.Name),
+		Description: optionalTrimmedString(input.Description),
+		Body:        strings.TrimSpace(input.Body),
+	}
+	if !templateSlugPattern.MatchString(template.Slug) {
+		return nil, fmt.Errorf("%w: slug must match %s", ErrInvalidTemplate, templateSlugPattern.String())
+	}
+	if template.Name == "" || template.Body == "" {
+		return nil, fmt.Errorf("%w: name and body are required", ErrInvalidTemplate)
+	}
+
+	var err error
+	if input.VariablesSchema != nil {
+		if template.VariablesSchema, err = json.Marshal(input.VariablesSchema); err != nil {
+			return nil, err
+		}
+	}
+	if input.Metadata != nil {
+		if _, ok := input.Metadata.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("%w: metadata must be an object", ErrInvalidTemplate)
+		}
+		if template.Metadata, err = json.Marshal(input.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	if tags := mergeTags(input.Tags); len(tags) > 0 {
+		if template.Tags, err = json.Marshal(tags); err != nil {
+			return nil, err
+		}
+	}
+	return template, nil
+}
+
+// mergeTags 依次合并多组标签，去除空白与重复项并保持首次出现的顺序。
+func mergeTags(groups ...[]string) []string {
+	seen := map[string]bool{}
+	var merged []string
+	for _, group := range groups {
+		for _, tag := range group {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,295 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zacharykka/prompt-manager/pkg/render"
+)
+
+// DefaultTokenLimit 为版本正文的默认 Token 上限，可通过 metadata.max_tokens 覆盖。
+const DefaultTokenLimit = 8192
+
+const (
+	ValidationSeverityError   = "error"
+	ValidationSeverityWarning = "warning"
+)
+
+var legacyVariablePattern = regexp.MustCompile(`\{\{\s*\.[A-Za-z_]`)
+
+var schemaTypes = map[string]struct{}{
+	"string": {}, "number": {}, "integer": {}, "boolean": {}, "array": {}, "object": {}, "null": {},
+}
+
+// ValidationIssue 描述单条校验问题。
+type ValidationIssue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// ValidationCheck 汇总某一类检查的结果，存在 error 级问题即视为未通过。
+type ValidationCheck struct {
+	Name   string            `json:"name"`
+	Passed bool              `json:"passed"`
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// ValidationReport 为版本草稿的完整校验报告，不会写入任何数据。
+type ValidationReport struct {
+	Valid           bool              `json:"valid"`
+	Checks          []ValidationCheck `json:"checks"`
+	Variables       []string          `json:"variables"`
+	EstimatedTokens int               `json:"estimated_tokens"`
+	TokenLimit      int               `json:"token_limit"`
+}
+
+// ValidatePromptVersion 对版本草稿执行模板解析、Schema、Lint 与 Token 上限检查，仅返回报告不做持久化。
+func (s *Service) ValidatePromptVersion(ctx context.Context, input CreatePromptVersionInput) (*ValidationReport, error) {
+	prompt, err := s.GetPrompt(ctx, input.PromptID)
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimSpace(s.normalizeBody(input.Body))
+	if body == "" {
+		return nil, ErrBodyRequired
+	}
+	if err := s.checkBodySize(body); err != nil {
+		return nil, err
+	}
+
+	report := &ValidationReport{Variables: []string{}}
+
+	templateCheck := ValidationCheck{Name: "template"}
+	if tmpl, err := render.Parse(body); err != nil {
+		issue := ValidationIssue{Rule: "parse", Severity: ValidationSeverityError, Message: err.Error()}
+		var parseErr *render.ParseError
+		if errors.As(err, &parseErr) {
+			issue.Line = parseErr.Line
+			issue.Message = parseErr.Message
+		}
+		templateCheck.Issues = append(templateCheck.Issues, issue)
+	} else {
+		report.Variables = tmpl.Variables()
+	}
+
+	schemaCheck := ValidationCheck{Name: "schema", Issues: validateVariablesSchema(input.VariablesSchema, report.Variables)}
+
+	lintCheck := ValidationCheck{Name: "lint"}
+	lintIssues, err := s.lintVersionBody(ctx, prompt.Name, body, strings.TrimSpace(input.Body))
+	if err != nil {
+		return nil, err
+	}
+	lintCheck.Issues = lintIssues
+
+	report.TokenLimit = tokenLimitFromMetadata(input.Metadata)
+	report.EstimatedTokens = estimateTokens(body)
+	tokenCheck := ValidationCheck{Name: "token_limit"}
+	if report.EstimatedTokens > report.TokenLimit {
+		tokenCheck.Issues = append(tokenCheck.Issues, ValidationIssue{
+			Rule:     "max_tokens",
+			Severity: ValidationSeverityError,
+			Message:  fmt.Sprintf("estimated %d tokens exceeds limit %d", report.EstimatedTokens, report.TokenLimit),
+		})
+	}
+
+	metadataCheck := ValidationCheck{Name: "metadata"}
+	if input.Metadata != nil {
+		data, err := json.Marshal(input.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.targetModels(data); err != nil {
+			metadataCheck.Issues = append(metadataCheck.Issues, ValidationIssue{Rule: targetModelsKey, Severity: ValidationSeverityError, Message: err.Error()})
+		}
+	}
+
+	report.Valid = true
+	for _, check := range []ValidationCheck{templateCheck, schemaCheck, lintCheck, tokenCheck, metadataCheck} {
+		check.Passed = true
+		if check.Issues == nil {
+			check.Issues = []ValidationIssue{}
+		}
+		for _, issue := range check.Issues {
+			if issue.Severity == ValidationSeverityError {
+				check.Passed = false
+				report.Valid = false
+			}
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// validateVariablesSchema 校验 Schema 结构，并比对模板变量与声明变量。
+func validateVariablesSchema(schema interface{}, variables []string) []ValidationIssue {
+	if schema == nil {
+		return nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return []ValidationIssue{{Rule: "invalid_json", Severity: ValidationSeverityError, Message: err.Error()}}
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return []ValidationIssue{{Rule: "invalid_schema", Severity: ValidationSeverityError, Message: "variables_schema must be a JSON object"}}
+	}
+
+	var issues []ValidationIssue
+	declared := map[string]struct{}{}
+	hasDeclarations := false
+
+	if raw, ok := root["properties"]; ok {
+		hasDeclarations = true
+		properties, ok := raw.(map[string]interface{})
+		if !ok {
+			issues = append(issues, ValidationIssue{Rule: "invalid_schema", Severity: ValidationSeverityError, Message: "properties must be an object"})
+		}
+		for name, value := range properties {
+			declared[name] = struct{}{}
+			property, ok := value.(map[string]interface{})
+			if !ok {
+				issues = append(issues, ValidationIssue{Rule: "invalid_schema", Severity: ValidationSeverityError, Message: fmt.Sprintf("property %q must be an object", name)})
+				continue
+			}
+			issues = append(issues, validateSchemaType(name, property)...)
+		}
+	}
+	if raw, ok := root["vars"]; ok {
+		hasDeclarations = true
+		vars, ok := raw.([]interface{})
+		if !ok {
+			issues = append(issues, ValidationIssue{Rule: "invalid_schema", Severity: ValidationSeverityError, Message: "vars must be an array"})
+		}
+		for idx, value := range vars {
+			entry, ok := value.(map[string]interface{})
+			name, _ := entry["name"].(string)
+			if !ok || strings.TrimSpace(name) == "" {
+				issues = append(issues, ValidationIssue{Rule: "invalid_schema", Severity: ValidationSeverityError, Message: fmt.Sprintf("vars[%d] must be an object with a name", idx)})
+				continue
+			}
+			declared[name] = struct{}{}
+			issues = append(issues, validateSchemaType(name, entry)...)
+		}
+	}
+	if !hasDeclarations {
+		return issues
+	}
+
+	used := make(map[string]struct{}, len(variables))
+	for _, name := range variables {
+		used[name] = struct{}{}
+		if _, ok := declared[name]; !ok {
+			issues = append(issues, ValidationIssue{Rule: "undeclared_variable", Severity: ValidationSeverityWarning, Message: fmt.Sprintf("variable %q is used but not declared", name)})
+		}
+	}
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := used[name]; !ok {
+			issues = append(issues, ValidationIssue{Rule: "unused_variable", Severity: ValidationSeverityWarning, Message: fmt.Sprintf("variable %q is declared but not used", name)})
+		}
+	}
+	return issues
+}
+
+func validateSchemaType(name string, property map[string]interface{}) []ValidationIssue {
+	rawType, ok := property["type"]
+	if !ok {
+		return nil
+	}
+	typeName, ok := rawType.(string)
+	if _, known := schemaTypes[typeName]; !ok || !known {
+		return []ValidationIssue{{Rule: "invalid_type", Severity: ValidationSeverityError, Message: fmt.Sprintf("variable %q has unsupported type %v", name, rawType)}}
+	}
+	if value, ok := property["default"]; ok && !matchesSchemaType(typeName, value) {
+		return []ValidationIssue{{Rule: "default_type_mismatch", Severity: ValidationSeverityError, Message: fmt.Sprintf("default of %q does not match type %s", name, typeName)}}
+	}
+	return nil
+}
+
+func matchesSchemaType(typeName string, value interface{}) bool {
+	switch typeName {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == float64(int64(number))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return value == nil
+	}
+}
+
+// lintVersionBody 执行风格类检查：旧式变量语法、行尾空白、Unicode 规范化与不可见字符及 include 引用。
+// body 为规范化后的正文；Unicode 检查针对提交的原始正文 raw，用于提示保存时会被规范化或移除的内容。
+func (s *Service) lintVersionBody(ctx context.Context, promptName, body, raw string) ([]ValidationIssue, error) {
+	var issues []ValidationIssue
+	for idx, line := range strings.Split(raw, "\n") {
+		issues = append(issues, s.lintUnicode(line, idx+1)...)
+	}
+	for idx, line := range strings.Split(body, "\n") {
+		if legacyVariablePattern.MatchString(line) {
+			issues = append(issues, ValidationIssue{Rule: "legacy_variable_syntax", Severity: ValidationSeverityWarning, Message: "use {{ name }} instead of {{.name}}", Line: idx + 1})
+		}
+		if strings.TrimRight(line, " \t") != line {
+			issues = append(issues, ValidationIssue{Rule: "trailing_whitespace", Severity: ValidationSeverityWarning, Message: "line has trailing whitespace", Line: idx + 1})
+		}
+	}
+
+	for _, name := range DetectIncludes(body) {
+		if strings.EqualFold(name, promptName) {
+			issues = append(issues, ValidationIssue{Rule: "self_include", Severity: ValidationSeverityError, Message: fmt.Sprintf("prompt cannot include itself (%s)", name)})
+			continue
+		}
+		if _, err := s.resolvePromptRef(ctx, name); err != nil {
+			if errors.Is(err, ErrPromptNotFound) {
+				issues = append(issues, ValidationIssue{Rule: "unknown_include", Severity: ValidationSeverityWarning, Message: fmt.Sprintf("included prompt %q does not exist", name)})
+				continue
+			}
+			return nil, err
+		}
+	}
+	return issues, nil
+}
+
+func tokenLimitFromMetadata(metadata interface{}) int {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return DefaultTokenLimit
+	}
+	var parsed struct {
+		MaxTokens int `json:"max_tokens"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil || parsed.MaxTokens <= 0 {
+		return DefaultTokenLimit
+	}
+	return parsed.MaxTokens
+}
+
+// estimateTokens 以约 4 个字符 1 个 Token 粗略估算，不依赖具体模型的分词器。
+func estimateTokens(body string) int {
+	return (utf8.RuneCountInString(body) + 3) / 4
+}
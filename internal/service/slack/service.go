@@ -0,0 +1,271 @@
+// Package slack 实现 Slack 斜杠命令（/prompt get <name>、/prompt search <q>），
+// 校验 Slack 请求签名后以 Block Kit 消息返回 Prompt 查询结果，便于在 Slack 内直接查阅 Prompt。
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+)
+
+const (
+	// SignatureHeader 为 Slack 请求签名（v0=<hex>）所在的请求头。
+	SignatureHeader = "X-Slack-Signature"
+	// TimestampHeader 为参与签名的请求时间戳（Unix 秒）。
+	TimestampHeader = "X-Slack-Request-Timestamp"
+
+	// maxRequestAge 为请求时间戳允许的最大偏差，超出视为重放。
+	maxRequestAge = 5 * time.Minute
+	// searchLimit 为搜索结果展示的条数。
+	searchLimit = 10
+	// maxBodyChars 截断正文，使代码块不超过 Block Kit section 的 3000 字符上限。
+	maxBodyChars = 2500
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid slack signature")
+	ErrRequestExpired   = errors.New("slack request timestamp expired")
+)
+
+// PromptFinder 提供斜杠命令所需的查询能力，由 Prompt 服务实现。
+type PromptFinder interface {
+	GetPromptByName(ctx context.Context, name string) (*domain.Prompt, error)
+	ListPromptsPage(ctx context.Context, opts promptsvc.ListPromptsOptions) (*promptsvc.PromptPage, error)
+}
+
+// Message 为斜杠命令的响应，response_type 为 ephemeral 时仅命令发起人可见。
+type Message struct {
+	ResponseType string  `json:"response_type"`
+	Text         string  `json:"text"`
+	Blocks       []Block `json:"blocks,omitempty"`
+}
+
+// Block 为 Block Kit 中用到的 section、context 与 divider 块。
+type Block struct {
+	Type     string       `json:"type"`
+	Text     *TextObject  `json:"text,omitempty"`
+	Fields   []TextObject `json:"fields,omitempty"`
+	Elements []TextObject `json:"elements,omitempty"`
+}
+
+// TextObject 为 mrkdwn 文本对象。
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Service 校验并处理 Slack 斜杠命令。
+type Service struct {
+	prompts   PromptFinder
+	secret    string
+	workspace string
+	now       func() time.Time
+}
+
+// Option 自定义 Service 行为。
+type Option func(*Service)
+
+// WithWorkspace 设置查询的工作区，默认 default。
+func WithWorkspace(workspaceID string) Option {
+	return func(s *Service) {
+		if workspaceID != "" {
+			s.workspace = workspaceID
+		}
+	}
+}
+
+// WithClock 注入时间函数，便于测试时间戳校验。
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+// NewService 创建 Slack 命令服务；signingSecret 为 Slack 应用的 Signing Secret。
+func NewService(prompts PromptFinder, signingSecret string, opts ...Option) *Service {
+	s := &Service{
+		prompts:   prompts,
+		secret:    signingSecret,
+		workspace: domain.DefaultWorkspaceID,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// VerifyRequest 校验 Slack 签名：v0=HMAC-SHA256(secret, "v0:<timestamp>:<body>")，并拒绝超过 5 分钟的请求。
+func (s *Service) VerifyRequest(body []byte, timestamp, signature string) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := s.now().Sub(time.Unix(seconds, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return ErrRequestExpired
+	}
+	digest, ok := strings.CutPrefix(signature, "v0=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// HandleCommand 解析命令文本（get <name>、search <q>、help）并返回仅发起人可见的消息。
+// 找不到 Prompt 或用法错误以消息形式返回，只有查询失败才返回 error。
+func (s *Service) HandleCommand(ctx context.Context, text string) (*Message, error) {
+	ctx = domain.WithWorkspace(ctx, s.workspace)
+	subcommand, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(subcommand) {
+	case "get":
+		if arg == "" {
+			return usage("Missing prompt name."), nil
+		}
+		return s.get(ctx, arg)
+	case "search":
+		if arg == "" {
+			return usage("Missing search query."), nil
+		}
+		return s.search(ctx, arg)
+	case "", "help":
+		return usage(""), nil
+	default:
+		return usage(fmt.Sprintf("Unknown subcommand `%s`.", escape(subcommand))), nil
+	}
+}
+
+func (s *Service) get(ctx context.Context, name string) (*Message, error) {
+	prompt, err := s.prompts.GetPromptByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, promptsvc.ErrPromptNotFound) {
+			return ephemeral(fmt.Sprintf("No prompt named `%s`.", escape(name))), nil
+		}
+		return nil, err
+	}
+
+	heading := "*" + escape(prompt.Name) + "*"
+	if prompt.Description != nil && *prompt.Description != "" {
+		heading += "\n" + escape(*prompt.Description)
+	}
+	fields := []TextObject{
+		mrkdwn("*Status*\n" + prompt.Status),
+		mrkdwn("*Updated*\n" + prompt.UpdatedAt.UTC().Format("2006-01-02 15:04 UTC")),
+	}
+	if tags := promptTags(prompt); tags != "" {
+		fields = append(fields, mrkdwn("*Tags*\n"+escape(tags)))
+	}
+	body := "_No active version._"
+	if prompt.Body != nil {
+		body = "```" + escape(truncate(*prompt.Body, maxBodyChars)) + "```"
+	}
+
+	message := ephemeral(prompt.Name)
+	message.Blocks = []Block{
+		{Type: "section", Text: textPtr(mrkdwn(heading)), Fields: fields},
+		{Type: "section", Text: textPtr(mrkdwn(body))},
+		{Type: "context", Elements: []TextObject{mrkdwn("ID `" + prompt.ID + "`")}},
+	}
+	return message, nil
+}
+
+func (s *Service) search(ctx context.Context, query string) (*Message, error) {
+	page, err := s.prompts.ListPromptsPage(ctx, promptsvc.ListPromptsOptions{
+		Limit:     searchLimit,
+		Search:    query,
+		CountMode: promptsvc.PromptCountNone,
+		OmitBody:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return ephemeral(fmt.Sprintf("No prompts match `%s`.", escape(query))), nil
+	}
+
+	lines := make([]string, 0, len(page.Items))
+	for _, prompt := range page.Items {
+		line := "• *" + escape(prompt.Name) + "*"
+		if prompt.Description != nil && *prompt.Description != "" {
+			line += " — " + escape(truncate(*prompt.Description, 120))
+		}
+		lines = append(lines, line)
+	}
+	summary := fmt.Sprintf("%d prompts match `%s`", len(page.Items), escape(query))
+	if page.HasMore {
+		summary = fmt.Sprintf("First %d prompts matching `%s`; refine the query to see more", len(page.Items), escape(query))
+	}
+
+	message := ephemeral(summary)
+	message.Blocks = []Block{
+		{Type: "section", Text: textPtr(mrkdwn(strings.Join(lines, "\n")))},
+		{Type: "context", Elements: []TextObject{mrkdwn(summary + ". Use `get <name>` for details.")}},
+	}
+	return message, nil
+}
+
+func usage(problem string) *Message {
+	text := "Usage: `get <name>` shows a prompt, `search <query>` finds prompts by name or description."
+	if problem != "" {
+		text = problem + " " + text
+	}
+	return ephemeral(text)
+}
+
+func ephemeral(text string) *Message {
+	return &Message{ResponseType: "ephemeral", Text: text}
+}
+
+func mrkdwn(text string) TextObject {
+	return TextObject{Type: "mrkdwn", Text: text}
+}
+
+func textPtr(text TextObject) *TextObject {
+	return &text
+}
+
+// promptTags 将 JSON 数组形式的标签拼接为逗号分隔的文本。
+func promptTags(prompt *domain.Prompt) string {
+	var tags []string
+	if err := json.Unmarshal(prompt.Tags, &tags); err != nil {
+		return ""
+	}
+	return strings.Join(tags, ", ")
+}
+
+// escape 转义 Slack mrkdwn 的控制字符 &、< 与 >。
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// truncate 按字符截断，超出时以省略号结尾。
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,116 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	promptsvc "github.com/zacharykka/prompt-manager/internal/service/prompt"
+)
+
+type fakeFinder struct {
+	prompts   []*domain.Prompt
+	workspace string
+	listOpts  promptsvc.ListPromptsOptions
+}
+
+func (f *fakeFinder) GetPromptByName(ctx context.Context, name string) (*domain.Prompt, error) {
+	f.workspace = domain.WorkspaceFromContext(ctx)
+	for _, prompt := range f.prompts {
+		if prompt.Name == name {
+			return prompt, nil
+		}
+	}
+	return nil, promptsvc.ErrPromptNotFound
+}
+
+func (f *fakeFinder) ListPromptsPage(ctx context.Context, opts promptsvc.ListPromptsOptions) (*promptsvc.PromptPage, error) {
+	f.listOpts = opts
+	var items []*domain.Prompt
+	for _, prompt := range f.prompts {
+		if strings.Contains(prompt.Name, opts.Search) {
+			items = append(items, prompt)
+		}
+	}
+	return &promptsvc.PromptPage{Items: items}, nil
+}
+
+func TestVerifyRequest(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	svc := NewService(&fakeFinder{}, "signing", WithClock(func() time.Time { return now }))
+	body := []byte("command=%2Fprompt&text=get+greeting")
+	sign := func(timestamp string) string {
+		mac := hmac.New(sha256.New, []byte("signing"))
+		mac.Write([]byte("v0:" + timestamp + ":"))
+		mac.Write(body)
+		return "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	if err := svc.VerifyRequest(body, timestamp, sign(timestamp)); err != nil {
+		t.Fatalf("expected valid request, got %v", err)
+	}
+	if err := svc.VerifyRequest(body, timestamp, "v0=00"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+	if err := svc.VerifyRequest(body, "soon", sign("soon")); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected invalid timestamp to fail, got %v", err)
+	}
+	stale := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+	if err := svc.VerifyRequest(body, stale, sign(stale)); !errors.Is(err, ErrRequestExpired) {
+		t.Fatalf("expected stale request to be rejected, got %v", err)
+	}
+}
+
+func TestHandleCommand(t *testing.T) {
+	description := "Greets <users> & guests"
+	body := "Hello {{ name }}"
+	finder := &fakeFinder{prompts: []*domain.Prompt{
+		{ID: "p1", Name: "greeting", Description: &description, Body: &body, Status: domain.PromptStatusActive, Tags: []byte(`["onboarding","email"]`)},
+		{ID: "p2", Name: "greeting-formal", Status: domain.PromptStatusActive},
+	}}
+	svc := NewService(finder, "signing", WithWorkspace("team-a"))
+	ctx := context.Background()
+
+	message, err := svc.HandleCommand(ctx, "  GET greeting ")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if message.ResponseType != "ephemeral" || len(message.Blocks) != 3 || finder.workspace != "team-a" {
+		t.Fatalf("unexpected get response %+v in %q", message, finder.workspace)
+	}
+	heading := message.Blocks[0].Text.Text
+	if !strings.Contains(heading, "&lt;users&gt; &amp; guests") || !strings.Contains(message.Blocks[0].Fields[2].Text, "onboarding, email") {
+		t.Fatalf("expected escaped description and tags, got %+v", message.Blocks[0])
+	}
+	if message.Blocks[1].Text.Text != "```Hello {{ name }}```" {
+		t.Fatalf("unexpected body block %q", message.Blocks[1].Text.Text)
+	}
+
+	message, err = svc.HandleCommand(ctx, "get missing")
+	if err != nil || len(message.Blocks) != 0 || !strings.Contains(message.Text, "No prompt named `missing`") {
+		t.Fatalf("expected not found message, got %+v (%v)", message, err)
+	}
+
+	message, err = svc.HandleCommand(ctx, "search greeting")
+	if err != nil || len(message.Blocks) != 2 || !strings.Contains(message.Text, "2 prompts match") {
+		t.Fatalf("unexpected search response %+v (%v)", message, err)
+	}
+	if finder.listOpts.Limit != searchLimit || !finder.listOpts.OmitBody || finder.listOpts.CountMode != promptsvc.PromptCountNone {
+		t.Fatalf("unexpected list options %+v", finder.listOpts)
+	}
+
+	for _, text := range []string{"", "help", "get", "search  ", "delete greeting"} {
+		message, err := svc.HandleCommand(ctx, text)
+		if err != nil || !strings.Contains(message.Text, "Usage:") {
+			t.Fatalf("expected usage for %q, got %+v (%v)", text, message, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	_ "modernc.org/sqlite"
+)
+
+func setupRepos(t *testing.T) (*domain.Repositories, func()) {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:telemetry_test.db?mode=memory&cache=shared&_fk=1")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("glob migrations: %v", err)
+	}
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
+	}
+	return repository.NewSQLRepositories(db, database.NewDialect("sqlite")), func() { _ = db.Close() }
+}
+
+func TestReporterSendsPreviewedReport(t *testing.T) {
+	repos, cleanup := setupRepos(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for i := 0; i < 12; i++ {
+		prompt := &domain.Prompt{ID: uuid.NewString(), Name: "secret-prompt-" + uuid.NewString()}
+		if err := repos.Prompts.Create(ctx, prompt); err != nil {
+			t.Fatalf("create prompt: %v", err)
+		}
+	}
+	if err := repos.Users.Create(ctx, &domain.User{ID: uuid.NewString(), Email: "owner@example.com", HashedPassword: "hashed", Role: "admin", Status: "active"}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	reporter := NewReporter(repos,
+		WithEndpoint(server.URL),
+		WithInstanceID(InstanceID("instance-secret")),
+		WithDatabaseDriver("pgx"),
+	)
+	reporter.now = func() time.Time { return time.Date(2025, 5, 1, 13, 14, 15, 0, time.UTC) }
+
+	preview, err := reporter.Collect(ctx)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if preview.Prompts != "10-99" || preview.Users != "1-9" || preview.Pipelines != "0" || preview.Organizations != "1-9" {
+		t.Fatalf("unexpected ranges: %+v", preview)
+	}
+	if preview.DatabaseDriver != "postgres" || preview.ReportedOn != "2025-05-01" || len(preview.InstanceID) != 16 {
+		t.Fatalf("unexpected report: %+v", preview)
+	}
+
+	if err := reporter.Send(ctx); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	expected, _ := json.Marshal(preview)
+	if string(received) != string(expected) {
+		t.Fatalf("sent report differs from preview:\n%s\n%s", received, expected)
+	}
+	for _, leaked := range []string{"secret-prompt", "owner@example.com", "instance-secret"} {
+		if strings.Contains(string(received), leaked) {
+			t.Fatalf("report leaked %q: %s", leaked, received)
+		}
+	}
+}
+
+func TestReporterDisabledByDefault(t *testing.T) {
+	reporter := NewReporter(nil)
+	if reporter.Enabled() {
+		t.Fatalf("expected telemetry to be disabled by default")
+	}
+	if err := reporter.Send(context.Background()); err != nil {
+		t.Fatalf("disabled send should be a no-op: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package workspace
+
+import (
+	"errors"
+
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+)
+
+var (
+	ErrNameRequired          = errors.New("name required")
+	ErrInvalidSlug           = errors.New("slug must contain lowercase letters, digits or '-'")
+	ErrInvalidRole           = errors.New("invalid workspace role")
+	ErrOrganizationNotFound  = errors.New("organization not found")
+	ErrOrganizationExists    = errors.New("organization slug already exists")
+	ErrWorkspaceNotFound     = domain.ErrWorkspaceNotFound
+	ErrWorkspaceExists       = errors.New("workspace slug already exists in organization")
+	ErrWorkspaceForbidden    = domain.ErrWorkspaceForbidden
+	ErrMemberNotFound        = errors.New("workspace member not found")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrDefaultWorkspaceFixed = errors.New("members of the default workspace follow global roles")
+)
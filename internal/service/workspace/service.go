@@ -0,0 +1,314 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	auditsvc "github.com/zacharykka/prompt-manager/internal/service/audit"
+)
+
+// 工作区成员角色，与全局角色取值一致。
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// 工作区相关的审计动作。
+const (
+	AuditOrganizationCreated = "organization.created"
+	AuditWorkspaceCreated    = "workspace.created"
+	AuditMemberRoleChanged   = "workspace.member.role_changed"
+	AuditMemberRemoved       = "workspace.member.removed"
+)
+
+var (
+	slugPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	slugSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Service 管理组织、工作区及成员角色，并解析用户在工作区内的有效角色。
+type Service struct {
+	repos *domain.Repositories
+	now   func() time.Time
+}
+
+// NewService 创建工作区服务。
+func NewService(repos *domain.Repositories) *Service {
+	return &Service{repos: repos, now: time.Now}
+}
+
+// CreateOrganizationInput 定义创建组织所需字段，Slug 为空时由名称生成。
+type CreateOrganizationInput struct {
+	Name      string
+	Slug      string
+	CreatedBy string
+}
+
+// CreateOrganization 创建组织。
+func (s *Service) CreateOrganization(ctx context.Context, input CreateOrganizationInput) (*domain.Organization, error) {
+	name, slug, err := normalizeNameAndSlug(input.Name, input.Slug)
+	if err != nil {
+		return nil, err
+	}
+	org := &domain.Organization{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Slug:      slug,
+		CreatedBy: optionalValue(input.CreatedBy),
+	}
+	if err := s.repos.Workspaces.CreateOrganization(ctx, org); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrOrganizationExists
+		}
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditOrganizationCreated, input.CreatedBy, "organization", org.ID, map[string]interface{}{
+		"name": org.Name,
+		"slug": org.Slug,
+	})
+	return s.repos.Workspaces.GetOrganization(ctx, org.ID)
+}
+
+// ListOrganizations 返回全部组织。
+func (s *Service) ListOrganizations(ctx context.Context) ([]*domain.Organization, error) {
+	return s.repos.Workspaces.ListOrganizations(ctx)
+}
+
+// CreateWorkspaceInput 定义创建工作区所需字段，OwnerUserID 非空时成为该工作区管理员。
+type CreateWorkspaceInput struct {
+	OrganizationID string
+	Name           string
+	Slug           string
+	OwnerUserID    string
+	CreatedBy      string
+}
+
+// CreateWorkspace 在组织下创建工作区，并将创建者加入为工作区管理员。
+func (s *Service) CreateWorkspace(ctx context.Context, input CreateWorkspaceInput) (*domain.Workspace, error) {
+	name, slug, err := normalizeNameAndSlug(input.Name, input.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repos.Workspaces.GetOrganization(ctx, input.OrganizationID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	workspace := &domain.Workspace{
+		ID:             uuid.NewString(),
+		OrganizationID: input.OrganizationID,
+		Name:           name,
+		Slug:           slug,
+		CreatedBy:      optionalValue(input.CreatedBy),
+	}
+	var owner *domain.WorkspaceMember
+	if input.OwnerUserID != "" {
+		owner = &domain.WorkspaceMember{WorkspaceID: workspace.ID, UserID: input.OwnerUserID, Role: RoleAdmin}
+	}
+	if err := s.repos.Workspaces.CreateWorkspace(ctx, workspace, owner); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrWorkspaceExists
+		}
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditWorkspaceCreated, input.CreatedBy, "workspace", workspace.ID, map[string]interface{}{
+		"organization_id": workspace.OrganizationID,
+		"name":            workspace.Name,
+		"slug":            workspace.Slug,
+	})
+	return s.repos.Workspaces.GetWorkspace(ctx, workspace.ID)
+}
+
+// ListWorkspacesForUser 返回用户可切换的工作区：全局管理员可见全部工作区；
+// 其他用户可见默认工作区（按全局角色）及其作为成员加入的工作区。Role 字段为用户在该工作区的有效角色。
+func (s *Service) ListWorkspacesForUser(ctx context.Context, userID, globalRole string) ([]*domain.Workspace, error) {
+	if globalRole == RoleAdmin {
+		workspaces, err := s.repos.Workspaces.ListWorkspaces(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, workspace := range workspaces {
+			workspace.Role = RoleAdmin
+		}
+		return workspaces, nil
+	}
+
+	defaultWorkspace, err := s.repos.Workspaces.GetWorkspace(ctx, domain.DefaultWorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defaultWorkspace.Role = globalRole
+
+	memberships, err := s.repos.Workspaces.ListWorkspacesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	workspaces := []*domain.Workspace{defaultWorkspace}
+	for _, workspace := range memberships {
+		if workspace.ID == domain.DefaultWorkspaceID {
+			continue
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	return workspaces, nil
+}
+
+// ResolveRole 返回用户在工作区内的有效角色：全局管理员在任意工作区均为管理员，
+// 默认工作区沿用全局角色，其余工作区使用成员角色；非成员返回 ErrWorkspaceForbidden。
+func (s *Service) ResolveRole(ctx context.Context, userID, globalRole, workspaceID string) (string, error) {
+	if _, err := s.getWorkspace(ctx, workspaceID); err != nil {
+		return "", err
+	}
+	if globalRole == RoleAdmin {
+		return RoleAdmin, nil
+	}
+	if workspaceID == domain.DefaultWorkspaceID {
+		return globalRole, nil
+	}
+	member, err := s.repos.Workspaces.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", ErrWorkspaceForbidden
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// ListMembers 返回工作区成员。
+func (s *Service) ListMembers(ctx context.Context, workspaceID string) ([]*domain.WorkspaceMember, error) {
+	if _, err := s.getWorkspace(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return s.repos.Workspaces.ListMembers(ctx, workspaceID)
+}
+
+// SetMemberRole 添加成员或调整成员角色；默认工作区的访问由全局角色决定，不支持单独设置。
+func (s *Service) SetMemberRole(ctx context.Context, workspaceID, userID, role, actor string) (*domain.WorkspaceMember, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !isValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if workspaceID == domain.DefaultWorkspaceID {
+		return nil, ErrDefaultWorkspaceFixed
+	}
+	if _, err := s.getWorkspace(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repos.Users.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	previousRole := ""
+	if existing, err := s.repos.Workspaces.GetMember(ctx, workspaceID, userID); err == nil {
+		previousRole = existing.Role
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	if err := s.repos.Workspaces.UpsertMember(ctx, &domain.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}); err != nil {
+		return nil, err
+	}
+	if previousRole != role {
+		s.recordAudit(ctx, AuditMemberRoleChanged, actor, "workspace", workspaceID, map[string]interface{}{
+			"user_id":       userID,
+			"previous_role": previousRole,
+			"role":          role,
+		})
+	}
+	return s.repos.Workspaces.GetMember(ctx, workspaceID, userID)
+}
+
+// RemoveMember 将用户移出工作区。
+func (s *Service) RemoveMember(ctx context.Context, workspaceID, userID, actor string) error {
+	if workspaceID == domain.DefaultWorkspaceID {
+		return ErrDefaultWorkspaceFixed
+	}
+	if _, err := s.getWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	if err := s.repos.Workspaces.RemoveMember(ctx, workspaceID, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+	s.recordAudit(ctx, AuditMemberRemoved, actor, "workspace", workspaceID, map[string]interface{}{
+		"user_id": userID,
+	})
+	return nil
+}
+
+func (s *Service) getWorkspace(ctx context.Context, workspaceID string) (*domain.Workspace, error) {
+	workspace, err := s.repos.Workspaces.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// recordAudit 写入通用审计日志；审计失败不影响已完成的操作。
+func (s *Service) recordAudit(ctx context.Context, action, actor, targetType, targetID string, payload map[string]interface{}) {
+	_ = auditsvc.Record(ctx, s.repos.AuditLogs, auditsvc.Entry{
+		Action:     action,
+		Actor:      actor,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Payload:    payload,
+		CreatedAt:  s.now(),
+	})
+}
+
+func normalizeNameAndSlug(name, slug string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", ErrNameRequired
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if slug == "" {
+		slug = strings.Trim(slugSeparator.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	}
+	if !slugPattern.MatchString(slug) {
+		return "", "", ErrInvalidSlug
+	}
+	return name, slug, nil
+}
+
+func isValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleEditor, RoleViewer:
+		return true
+	default:
+		return false
+	}
+}
+
+func optionalValue(val string) *string {
+	trimmed := strings.TrimSpace(val)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique") || strings.Contains(msg, "duplicate")
+}
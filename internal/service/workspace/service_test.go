@@ -0,0 +1,137 @@
+package workspace
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	domain "github.com/zacharykka/prompt-manager/internal/domain"
+	"github.com/zacharykka/prompt-manager/internal/infra/database"
+	"github.com/zacharykka/prompt-manager/internal/infra/repository"
+	_ "modernc.org/sqlite"
+)
+
+func setupWorkspaceService(t *testing.T) (*Service, func()) {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:workspace_service_test.db?mode=memory&cache=shared&_fk=1")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	migrationFiles, err := filepath.Glob(filepath.Join("..", "..", "..", "db", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("glob migrations: %v", err)
+	}
+	for _, path := range migrationFiles {
+		migrationSQL, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", filepath.Base(path), err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("exec migration %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	repos := repository.NewSQLRepositories(db, database.NewDialect("sqlite"))
+	return NewService(repos), func() { _ = db.Close() }
+}
+
+func createUser(t *testing.T, svc *Service, email, role string) *domain.User {
+	t.Helper()
+	user := &domain.User{ID: uuid.NewString(), Email: email, HashedPassword: "hashed", Role: role, Status: "active"}
+	if err := svc.repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func TestWorkspaceMembershipAndRoles(t *testing.T) {
+	svc, cleanup := setupWorkspaceService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	owner := createUser(t, svc, "owner@example.com", "editor")
+	viewer := createUser(t, svc, "viewer@example.com", "viewer")
+
+	org, err := svc.CreateOrganization(ctx, CreateOrganizationInput{Name: "Acme Corp", CreatedBy: owner.Email})
+	if err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	if org.Slug != "acme-corp" {
+		t.Fatalf("expected derived slug acme-corp, got %s", org.Slug)
+	}
+	if _, err := svc.CreateOrganization(ctx, CreateOrganizationInput{Name: "Acme", Slug: "acme-corp"}); !errors.Is(err, ErrOrganizationExists) {
+		t.Fatalf("expected ErrOrganizationExists, got %v", err)
+	}
+
+	ws, err := svc.CreateWorkspace(ctx, CreateWorkspaceInput{OrganizationID: org.ID, Name: "Growth", OwnerUserID: owner.ID, CreatedBy: owner.Email})
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	if _, err := svc.CreateWorkspace(ctx, CreateWorkspaceInput{OrganizationID: "missing", Name: "X"}); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Fatalf("expected ErrOrganizationNotFound, got %v", err)
+	}
+
+	role, err := svc.ResolveRole(ctx, owner.ID, owner.Role, ws.ID)
+	if err != nil || role != RoleAdmin {
+		t.Fatalf("expected creator to be workspace admin, got %q (%v)", role, err)
+	}
+	if _, err := svc.ResolveRole(ctx, viewer.ID, viewer.Role, ws.ID); !errors.Is(err, ErrWorkspaceForbidden) {
+		t.Fatalf("expected ErrWorkspaceForbidden for non-member, got %v", err)
+	}
+	if role, err := svc.ResolveRole(ctx, viewer.ID, viewer.Role, domain.DefaultWorkspaceID); err != nil || role != RoleViewer {
+		t.Fatalf("expected default workspace to follow global role, got %q (%v)", role, err)
+	}
+	if role, err := svc.ResolveRole(ctx, "any", RoleAdmin, ws.ID); err != nil || role != RoleAdmin {
+		t.Fatalf("expected global admin to access every workspace, got %q (%v)", role, err)
+	}
+	if _, err := svc.ResolveRole(ctx, owner.ID, owner.Role, "missing"); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Fatalf("expected ErrWorkspaceNotFound, got %v", err)
+	}
+
+	if _, err := svc.SetMemberRole(ctx, ws.ID, viewer.ID, "owner", owner.Email); !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("expected ErrInvalidRole, got %v", err)
+	}
+	if _, err := svc.SetMemberRole(ctx, domain.DefaultWorkspaceID, viewer.ID, RoleEditor, owner.Email); !errors.Is(err, ErrDefaultWorkspaceFixed) {
+		t.Fatalf("expected ErrDefaultWorkspaceFixed, got %v", err)
+	}
+	member, err := svc.SetMemberRole(ctx, ws.ID, viewer.ID, RoleEditor, owner.Email)
+	if err != nil {
+		t.Fatalf("set member role: %v", err)
+	}
+	if member.Role != RoleEditor || member.Email != viewer.Email {
+		t.Fatalf("unexpected member %+v", member)
+	}
+
+	workspaces, err := svc.ListWorkspacesForUser(ctx, viewer.ID, viewer.Role)
+	if err != nil {
+		t.Fatalf("list workspaces: %v", err)
+	}
+	if len(workspaces) != 2 || workspaces[0].ID != domain.DefaultWorkspaceID || workspaces[1].ID != ws.ID || workspaces[1].Role != RoleEditor {
+		t.Fatalf("unexpected workspaces %+v", workspaces)
+	}
+
+	members, err := svc.ListMembers(ctx, ws.ID)
+	if err != nil || len(members) != 2 {
+		t.Fatalf("expected 2 members, got %d (%v)", len(members), err)
+	}
+
+	if err := svc.RemoveMember(ctx, ws.ID, viewer.ID, owner.Email); err != nil {
+		t.Fatalf("remove member: %v", err)
+	}
+	if err := svc.RemoveMember(ctx, ws.ID, viewer.ID, owner.Email); !errors.Is(err, ErrMemberNotFound) {
+		t.Fatalf("expected ErrMemberNotFound, got %v", err)
+	}
+
+	logs, err := svc.repos.AuditLogs.List(ctx, domain.AuditLogListOptions{Action: "workspace.member."})
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expected role change and removal to be audited, got %d", len(logs))
+	}
+}
@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// ChainHash 计算审计记录的链式哈希：sha256(prevHash || payload)。
+// payload 由各字段按长度前缀拼接而成，避免字段边界歧义导致不同记录得到相同哈希。
+func ChainHash(prevHash string, fields ...string) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	var size [8]byte
+	for _, field := range fields {
+		binary.BigEndian.PutUint64(size[:], uint64(len(field)))
+		h.Write(size[:])
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Timestamp 返回参与哈希的时间戳表示，统一为 UTC 微秒精度以兼容各数据库的存储精度。
+func Timestamp(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+// Client 描述发起操作的客户端信息，随请求上下文传递给业务层写入审计记录。
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientContextKey struct{}
+
+// WithClient 将客户端信息写入上下文。
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext 读取客户端信息，未设置时返回零值。
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientContextKey{}).(Client)
+	return client
+}
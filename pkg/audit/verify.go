@@ -0,0 +1,82 @@
+package audit
+
+import "fmt"
+
+// maxChainIssues 限制校验报告中返回的问题条数，避免链严重损坏时响应过大。
+const maxChainIssues = 100
+
+// 哈希链校验问题类型。
+const (
+	IssueGap          = "gap"
+	IssuePrevMismatch = "prev_hash_mismatch"
+	IssueHashMismatch = "hash_mismatch"
+)
+
+// ChainIssue 描述哈希链中检测到的一处异常。
+type ChainIssue struct {
+	Seq     int64  `json:"seq"`
+	ID      string `json:"id,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// ChainReport 为哈希链的校验结果，HeadSeq/HeadHash 可存档用于后续比对尾部截断。
+type ChainReport struct {
+	Verified  bool         `json:"verified"`
+	Checked   int          `json:"checked"`
+	Unchained int          `json:"unchained"`
+	HeadSeq   int64        `json:"head_seq"`
+	HeadHash  string       `json:"head_hash,omitempty"`
+	Issues    []ChainIssue `json:"issues"`
+	Truncated bool         `json:"truncated,omitempty"`
+}
+
+// ChainVerifier 按序号递增顺序逐条接收记录并检测缺失（删除）、篡改与链接断裂。
+type ChainVerifier struct {
+	report   ChainReport
+	prevSeq  int64
+	prevHash string
+}
+
+// NewChainVerifier 创建校验器。
+func NewChainVerifier() *ChainVerifier {
+	return &ChainVerifier{report: ChainReport{Issues: []ChainIssue{}}}
+}
+
+// Add 校验一条记录，computed 为根据记录内容重新计算的哈希；seq 为 0 表示链式哈希引入前的历史记录。
+func (v *ChainVerifier) Add(id string, seq int64, prevHash, hash, computed string) {
+	if seq == 0 {
+		v.report.Unchained++
+		return
+	}
+	v.report.Checked++
+
+	if seq != v.prevSeq+1 {
+		v.addIssue(ChainIssue{Seq: seq, ID: id, Kind: IssueGap, Message: fmt.Sprintf("records %d-%d are missing", v.prevSeq+1, seq-1)})
+	} else if prevHash != v.prevHash {
+		v.addIssue(ChainIssue{Seq: seq, ID: id, Kind: IssuePrevMismatch, Message: "prev_hash does not match the previous record"})
+	}
+	if computed != hash {
+		v.addIssue(ChainIssue{Seq: seq, ID: id, Kind: IssueHashMismatch, Message: "record content does not match its hash"})
+	}
+
+	v.prevSeq = seq
+	v.prevHash = hash
+}
+
+// Report 返回当前校验结果。
+func (v *ChainVerifier) Report() *ChainReport {
+	report := v.report
+	report.HeadSeq = v.prevSeq
+	report.HeadHash = v.prevHash
+	report.Verified = len(report.Issues) == 0 && !report.Truncated
+	return &report
+}
+
+func (v *ChainVerifier) addIssue(issue ChainIssue) {
+	if len(v.report.Issues) >= maxChainIssues {
+		v.report.Truncated = true
+		return
+	}
+	v.report.Issues = append(v.report.Issues, issue)
+}
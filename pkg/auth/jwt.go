@@ -9,10 +9,12 @@ import (
 
 // Claims 定义标准化的访问令牌载荷。
 type Claims struct {
-	UserID    string            `json:"user_id"`
-	Role      string            `json:"role"`
-	TokenType string            `json:"token_type"`
-	Metadata  map[string]string `json:"metadata,omitempty"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
+	// WorkspaceID 为令牌当前选中的工作区，为空时使用默认工作区。
+	WorkspaceID string            `json:"workspace_id,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法。
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrKeyRetired           = errors.New("signing key retired")
+)
+
+// SigningKey 表示一把带 kid 的签名密钥，HS256 使用对称密钥，RS256/EdDSA 使用非对称密钥对。
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	// Tenant 为密钥所属租户（组织），空表示默认租户。
+	Tenant    string
+	CreatedAt time.Time
+	// RetiresAt 非空时表示该密钥已被轮换，仅在此时间之前继续用于验签。
+	RetiresAt *time.Time
+
+	secret  []byte
+	private crypto.Signer
+}
+
+// NormalizeAlgorithm 校验并规范化算法名称，空字符串返回 HS256。
+func NormalizeAlgorithm(alg string) (string, error) {
+	switch alg {
+	case "", AlgorithmHS256, "hs256":
+		return AlgorithmHS256, nil
+	case AlgorithmRS256, "rs256":
+		return AlgorithmRS256, nil
+	case AlgorithmEdDSA, "eddsa", "EDDSA", "Ed25519", "ed25519":
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+	}
+}
+
+// GenerateSigningKey 生成指定算法的新密钥。
+func GenerateSigningKey(id, alg string) (*SigningKey, error) {
+	alg, err := NormalizeAlgorithm(alg)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{ID: id, Algorithm: alg, CreatedAt: time.Now().UTC()}
+	switch alg {
+	case AlgorithmHS256:
+		key.secret = make([]byte, 32)
+		if _, err := rand.Read(key.secret); err != nil {
+			return nil, err
+		}
+	case AlgorithmRS256:
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		key.private = private
+	case AlgorithmEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.private = private
+	}
+	return key, nil
+}
+
+// ParseSigningKey 从 MarshalPrivate 的输出还原密钥。
+func ParseSigningKey(id, alg string, material []byte) (*SigningKey, error) {
+	alg, err := NormalizeAlgorithm(alg)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{ID: id, Algorithm: alg}
+	if alg == AlgorithmHS256 {
+		key.secret = append([]byte(nil), material...)
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(material)
+	if err != nil {
+		return nil, err
+	}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgorithmRS256 {
+			return nil, fmt.Errorf("%w: key type does not match %s", ErrUnsupportedAlgorithm, alg)
+		}
+		key.private = private
+	case ed25519.PrivateKey:
+		if alg != AlgorithmEdDSA {
+			return nil, fmt.Errorf("%w: key type does not match %s", ErrUnsupportedAlgorithm, alg)
+		}
+		key.private = private
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, parsed)
+	}
+	return key, nil
+}
+
+// MarshalPrivate 序列化私钥材料：HS256 为原始字节，非对称密钥为 PKCS#8 DER。
+func (k *SigningKey) MarshalPrivate() ([]byte, error) {
+	if k.Algorithm == AlgorithmHS256 {
+		return append([]byte(nil), k.secret...), nil
+	}
+	return x509.MarshalPKCS8PrivateKey(k.private)
+}
+
+// PublicKey 返回非对称密钥的公钥，HS256 返回 nil。
+func (k *SigningKey) PublicKey() crypto.PublicKey {
+	if k.private == nil {
+		return nil
+	}
+	return k.private.Public()
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+func (k *SigningKey) signKey() interface{} {
+	if k.Algorithm == AlgorithmHS256 {
+		return k.secret
+	}
+	return k.private
+}
+
+func (k *SigningKey) verifyKey() interface{} {
+	if k.Algorithm == AlgorithmHS256 {
+		return k.secret
+	}
+	return k.PublicKey()
+}
+
+// KeySet 维护当前签名密钥与仍可验签的历史密钥，可并发使用。
+// 除默认当前密钥外，每个租户可有自己的当前密钥；验签按 kid 查找，不区分租户。
+// 未携带 kid 的令牌使用 legacy 共享密钥（HS256）验证，以兼容轮换前签发的令牌。
+type KeySet struct {
+	mu      sync.RWMutex
+	active  *SigningKey
+	tenants map[string]*SigningKey
+	keys    map[string]*SigningKey
+	legacy  []byte
+	now     func() time.Time
+}
+
+// NewKeySet 创建密钥集合，legacySecret 为空时拒绝所有无 kid 的令牌。
+func NewKeySet(legacySecret string) *KeySet {
+	ks := &KeySet{keys: map[string]*SigningKey{}, tenants: map[string]*SigningKey{}, now: time.Now}
+	if legacySecret != "" {
+		ks.legacy = []byte(legacySecret)
+	}
+	return ks
+}
+
+// Replace 以新的当前密钥与历史密钥整体替换集合内容；tenantActive 为各租户自己的当前密钥，按 Tenant 索引。
+func (ks *KeySet) Replace(active *SigningKey, keys []*SigningKey, tenantActive ...*SigningKey) {
+	index := make(map[string]*SigningKey, len(keys)+len(tenantActive)+1)
+	for _, key := range keys {
+		index[key.ID] = key
+	}
+	tenants := make(map[string]*SigningKey, len(tenantActive))
+	for _, key := range tenantActive {
+		index[key.ID] = key
+		tenants[key.Tenant] = key
+	}
+	if active != nil {
+		index[active.ID] = active
+	}
+	ks.mu.Lock()
+	ks.active = active
+	ks.tenants = tenants
+	ks.keys = index
+	ks.mu.Unlock()
+}
+
+// Active 返回默认租户当前用于签名的密钥，未配置时返回 nil。
+func (ks *KeySet) Active() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.active
+}
+
+// TenantActive 返回租户自己的当前密钥，租户尚无专属密钥时返回 nil；空租户返回默认当前密钥。
+func (ks *KeySet) TenantActive(tenant string) *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if tenant == "" {
+		return ks.active
+	}
+	return ks.tenants[tenant]
+}
+
+// Keys 返回仍可用于验签的密钥（当前密钥在前，其余按创建时间倒序）。
+func (ks *KeySet) Keys() []*SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	now := ks.now()
+	keys := make([]*SigningKey, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		if key.RetiresAt != nil && !now.Before(*key.RetiresAt) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == ks.active || keys[j] == ks.active {
+			return keys[i] == ks.active
+		}
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// Sign 使用默认租户的当前密钥签发令牌并在头部写入 kid；未配置密钥时回退到 legacy 共享密钥。
+func (ks *KeySet) Sign(ttl time.Duration, claims Claims) (string, error) {
+	return ks.SignFor("", ttl, claims)
+}
+
+// SignFor 使用租户的当前密钥签发令牌，租户没有专属密钥时使用默认当前密钥。
+func (ks *KeySet) SignFor(tenant string, ttl time.Duration, claims Claims) (string, error) {
+	active := ks.TenantActive(tenant)
+	if active == nil {
+		active = ks.Active()
+	}
+	if active == nil {
+		if ks.legacy == nil {
+			return "", errors.New("jwt secret missing")
+		}
+		return GenerateToken(string(ks.legacy), ttl, claims)
+	}
+	now := ks.now()
+	claims.RegisteredClaims.IssuedAt = jwt.NewNumericDate(now)
+	claims.RegisteredClaims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	token := jwt.NewWithClaims(active.method(), claims)
+	token.Header["kid"] = active.ID
+	return token.SignedString(active.signKey())
+}
+
+// Parse 根据 kid 选择密钥验证令牌，kid 未知时返回 ErrUnknownKey。
+func (ks *KeySet) Parse(tokenStr string) (*Claims, error) {
+	if tokenStr == "" {
+		return nil, errors.New("token empty")
+	}
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			if ks.legacy == nil {
+				return nil, ErrUnknownKey
+			}
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			return ks.legacy, nil
+		}
+
+		ks.mu.RLock()
+		key, ok := ks.keys[kid]
+		ks.mu.RUnlock()
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if key.RetiresAt != nil && !ks.now().Before(*key.RetiresAt) {
+			return nil, ErrKeyRetired
+		}
+		if t.Method.Alg() != key.method().Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verifyKey(), nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token invalid")
+	}
+	return claims, nil
+}
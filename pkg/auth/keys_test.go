@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeySetSignAndParse(t *testing.T) {
+	for _, alg := range []string{AlgorithmHS256, AlgorithmRS256, AlgorithmEdDSA} {
+		key, err := GenerateSigningKey("kid-"+alg, alg)
+		if err != nil {
+			t.Fatalf("%s: generate: %v", alg, err)
+		}
+		material, err := key.MarshalPrivate()
+		if err != nil {
+			t.Fatalf("%s: marshal: %v", alg, err)
+		}
+		restored, err := ParseSigningKey(key.ID, alg, material)
+		if err != nil {
+			t.Fatalf("%s: parse key: %v", alg, err)
+		}
+
+		ks := NewKeySet("legacy-secret")
+		ks.Replace(restored, nil)
+		token, err := ks.Sign(time.Minute, Claims{UserID: "u1", TokenType: "access"})
+		if err != nil {
+			t.Fatalf("%s: sign: %v", alg, err)
+		}
+		claims, err := ks.Parse(token)
+		if err != nil || claims.UserID != "u1" {
+			t.Fatalf("%s: parse token: %v", alg, err)
+		}
+	}
+}
+
+func TestKeySetRotationAndLegacy(t *testing.T) {
+	oldKey, _ := GenerateSigningKey("old", AlgorithmHS256)
+	newKey, _ := GenerateSigningKey("new", AlgorithmEdDSA)
+
+	ks := NewKeySet("legacy-secret")
+	ks.Replace(oldKey, nil)
+	oldToken, err := ks.Sign(time.Minute, Claims{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	legacyToken, err := GenerateToken("legacy-secret", time.Minute, Claims{UserID: "legacy"})
+	if err != nil {
+		t.Fatalf("legacy sign: %v", err)
+	}
+
+	retiresAt := time.Now().Add(time.Minute)
+	oldKey.RetiresAt = &retiresAt
+	ks.Replace(newKey, []*SigningKey{oldKey})
+	if _, err := ks.Parse(oldToken); err != nil {
+		t.Fatalf("expected retired key to verify within grace: %v", err)
+	}
+	if claims, err := ks.Parse(legacyToken); err != nil || claims.UserID != "legacy" {
+		t.Fatalf("expected legacy token to verify: %v", err)
+	}
+	if keys := ks.Keys(); len(keys) != 2 || keys[0].ID != "new" {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+
+	expired := time.Now().Add(-time.Second)
+	oldKey.RetiresAt = &expired
+	if _, err := ks.Parse(oldToken); err == nil {
+		t.Fatalf("expected expired key to be rejected")
+	}
+
+	ks.Replace(newKey, nil)
+	if _, err := ks.Parse(oldToken); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey got %v", err)
+	}
+}
+
+func TestKeySetTenantKeys(t *testing.T) {
+	defaultKey, _ := GenerateSigningKey("default", AlgorithmEdDSA)
+	tenantKey, _ := GenerateSigningKey("acme", AlgorithmEdDSA)
+	tenantKey.Tenant = "org-acme"
+
+	ks := NewKeySet("")
+	ks.Replace(defaultKey, nil, tenantKey)
+	if ks.TenantActive("org-acme") != tenantKey || ks.TenantActive("org-other") != nil {
+		t.Fatalf("unexpected tenant keys")
+	}
+	tenantToken, err := ks.SignFor("org-acme", time.Minute, Claims{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("sign for tenant: %v", err)
+	}
+	// 没有专属密钥的租户使用默认密钥签发。
+	fallbackToken, err := ks.SignFor("org-other", time.Minute, Claims{UserID: "u2"})
+	if err != nil {
+		t.Fatalf("sign for tenant without key: %v", err)
+	}
+	for _, token := range []string{tenantToken, fallbackToken} {
+		if _, err := ks.Parse(token); err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+	}
+
+	// 租户令牌只能由该租户的密钥验证。
+	ks.Replace(defaultKey, nil)
+	if _, err := ks.Parse(tenantToken); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected tenant token to require tenant key, got %v", err)
+	}
+	if _, err := ks.Parse(fallbackToken); err != nil {
+		t.Fatalf("expected fallback token to use default key: %v", err)
+	}
+}
+
+func TestTenantSecret(t *testing.T) {
+	if TenantSecret("master", "org-a") == TenantSecret("master", "org-b") || TenantSecret("master", "org-a") != TenantSecret("master", "org-a") {
+		t.Fatalf("expected stable, distinct tenant secrets")
+	}
+	if TenantSecret("", "org-a") != "" {
+		t.Fatalf("expected empty master to yield empty secret")
+	}
+	encrypted, err := EncryptSecret(TenantSecret("master", "org-a"), []byte("material"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	for _, passphrase := range []string{"master", TenantSecret("master", "org-b"), TenantSecret("other", "org-a")} {
+		if _, err := DecryptSecret(passphrase, encrypted); err == nil {
+			t.Fatalf("expected %q to fail decrypting another tenant's secret", passphrase)
+		}
+	}
+}
+
+func TestEncryptSecret(t *testing.T) {
+	encrypted, err := EncryptSecret("passphrase", []byte("material"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	plain, err := DecryptSecret("passphrase", encrypted)
+	if err != nil || string(plain) != "material" {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if _, err := DecryptSecret("other", encrypted); err == nil {
+		t.Fatalf("expected wrong passphrase to fail")
+	}
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	hmacKey, _ := GenerateSigningKey("hmac", AlgorithmHS256)
+	rsaKey, _ := GenerateSigningKey("rsa", AlgorithmRS256)
+	edKey, _ := GenerateSigningKey("ed", AlgorithmEdDSA)
+
+	ks := NewKeySet("")
+	ks.Replace(rsaKey, []*SigningKey{hmacKey, edKey})
+	set := ks.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 public keys got %+v", set.Keys)
+	}
+	for _, jwk := range set.Keys {
+		switch jwk.KeyID {
+		case "rsa":
+			if jwk.KeyType != "RSA" || jwk.N == "" || jwk.E != "AQAB" {
+				t.Fatalf("unexpected rsa jwk %+v", jwk)
+			}
+		case "ed":
+			if jwk.KeyType != "OKP" || jwk.Curve != "Ed25519" || jwk.X == "" {
+				t.Fatalf("unexpected ed25519 jwk %+v", jwk)
+			}
+		default:
+			t.Fatalf("unexpected jwk %+v", jwk)
+		}
+	}
+}
@@ -1,17 +1,151 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
 
-// HashPassword 使用 bcrypt 生成密码哈希。
-func HashPassword(plain string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 支持的密码哈希算法。
+const (
+	HashAlgorithmBcrypt   = "bcrypt"
+	HashAlgorithmArgon2id = "argon2id"
+)
+
+// argon2id 默认参数，参考 OWASP 推荐值。
+const (
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+	argon2SaltLength         = 16
+	argon2KeyLength          = 32
+)
+
+// PasswordHasher 描述目标哈希算法与代价参数，零值等价于默认代价的 bcrypt。
+// 校验时同时识别 bcrypt 与 argon2id 哈希，便于在算法或参数调整后平滑迁移。
+type PasswordHasher struct {
+	Algorithm  string
+	BcryptCost int
+	// Argon2Memory 单位为 KiB。
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+func (h PasswordHasher) withDefaults() PasswordHasher {
+	if h.Algorithm == "" {
+		h.Algorithm = HashAlgorithmBcrypt
+	}
+	if h.BcryptCost == 0 {
+		h.BcryptCost = bcrypt.DefaultCost
+	}
+	if h.Argon2Memory == 0 {
+		h.Argon2Memory = defaultArgon2Memory
+	}
+	if h.Argon2Iterations == 0 {
+		h.Argon2Iterations = defaultArgon2Iterations
+	}
+	if h.Argon2Parallelism == 0 {
+		h.Argon2Parallelism = defaultArgon2Parallelism
+	}
+	return h
+}
+
+// Hash 按目标算法生成密码哈希，argon2id 使用 PHC 字符串格式编码参数与盐。
+func (h PasswordHasher) Hash(plain string) (string, error) {
+	h = h.withDefaults()
+	switch h.Algorithm {
+	case HashAlgorithmBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(plain), h.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	case HashAlgorithmArgon2id:
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(plain), salt, h.Argon2Iterations, h.Argon2Memory, h.Argon2Parallelism, argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, h.Argon2Memory, h.Argon2Iterations, h.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		return "", fmt.Errorf("unsupported password hash algorithm %q", h.Algorithm)
+	}
+}
+
+// Verify 对比明文密码与哈希是否匹配，哈希算法由其编码格式识别。
+func (h PasswordHasher) Verify(hash, plain string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2Hash(hash)
+		if err != nil {
+			return false
+		}
+		computed := argon2.IDKey([]byte(plain), salt, params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
+}
+
+// NeedsRehash 判断哈希的算法或代价参数是否与当前目标不一致，登录成功后可据此透明升级。
+func (h PasswordHasher) NeedsRehash(hash string) bool {
+	h = h.withDefaults()
+	if strings.HasPrefix(hash, "$argon2id$") {
+		if h.Algorithm != HashAlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2Hash(hash)
+		if err != nil {
+			return true
+		}
+		return params.Argon2Memory != h.Argon2Memory ||
+			params.Argon2Iterations != h.Argon2Iterations ||
+			params.Argon2Parallelism != h.Argon2Parallelism
+	}
+	if h.Algorithm != HashAlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.BcryptCost
+}
+
+func decodeArgon2Hash(hash string) (PasswordHasher, []byte, []byte, error) {
+	// 格式：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return PasswordHasher{}, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return PasswordHasher{}, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	params := PasswordHasher{Algorithm: HashAlgorithmArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Iterations, &params.Argon2Parallelism); err != nil {
+		return PasswordHasher{}, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return "", err
+		return PasswordHasher{}, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return PasswordHasher{}, nil, nil, fmt.Errorf("invalid argon2id key")
 	}
-	return string(hash), nil
+	return params, salt, key, nil
 }
 
-// VerifyPassword 对比明文密码与哈希是否匹配。
+// HashPassword 使用默认代价的 bcrypt 生成密码哈希。
+func HashPassword(plain string) (string, error) {
+	return PasswordHasher{}.Hash(plain)
+}
+
+// VerifyPassword 对比明文密码与哈希是否匹配，兼容 bcrypt 与 argon2id。
 func VerifyPassword(hash string, plain string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
+	return PasswordHasher{}.Verify(hash, plain)
 }
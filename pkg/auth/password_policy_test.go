@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MaxLength: 20, RequireDigit: true, MinCharacterClasses: 3, DenyCommon: true}
+
+	cases := []struct {
+		password string
+		email    string
+		want     []string
+	}{
+		{"Str0ng-Passw0rd", "", nil},
+		{"short1", "", []string{PasswordTooShort, PasswordTooFewClasses}},
+		{"abcdefghijklmnopqrstuvwxyz1", "", []string{PasswordTooLong, PasswordTooFewClasses}},
+		{"NoDigitsHere!", "", []string{PasswordMissingDigit}},
+		{"Password123", "", []string{PasswordCommon}},
+		{"Alice-2024-secret", "alice@example.com", []string{PasswordContainsEmail}},
+	}
+	for _, tc := range cases {
+		if got := policy.Validate(tc.password, tc.email); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Validate(%q) = %v, want %v", tc.password, got, tc.want)
+		}
+	}
+}
+
+func TestHIBPCheckerBreached(t *testing.T) {
+	sum := sha1.Sum([]byte("hunter2"))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 只允许发送 5 位哈希前缀。
+		if prefix := strings.TrimPrefix(r.URL.Path, "/range/"); len(prefix) != 5 {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Add-Padding") != "true" {
+			t.Errorf("expected padding header")
+		}
+		fmt.Fprintf(w, "0000000000000000000000000000000000A:0\r\n%s:42\r\n", digest[5:])
+	}))
+	defer server.Close()
+
+	checker := NewHIBPChecker(server.Client(), server.URL)
+	breached, err := checker.Breached(context.Background(), "hunter2")
+	if err != nil || !breached {
+		t.Fatalf("expected breached password, got %v (%v)", breached, err)
+	}
+	breached, err = checker.Breached(context.Background(), "a-much-less-known-passphrase")
+	if err != nil || breached {
+		t.Fatalf("expected unknown password to pass, got %v (%v)", breached, err)
+	}
+}
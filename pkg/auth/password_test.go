@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordHasherArgon2id(t *testing.T) {
+	hasher := PasswordHasher{Algorithm: HashAlgorithmArgon2id, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+	hash, err := hasher.Hash("s3cret-passphrase")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("unexpected encoding %s", hash)
+	}
+	if !hasher.Verify(hash, "s3cret-passphrase") || hasher.Verify(hash, "wrong") {
+		t.Fatalf("argon2id verification mismatch")
+	}
+	if !VerifyPassword(hash, "s3cret-passphrase") {
+		t.Fatalf("expected VerifyPassword to recognise argon2id hashes")
+	}
+	if hasher.NeedsRehash(hash) {
+		t.Fatalf("hash with current parameters should not need rehash")
+	}
+
+	stronger := hasher
+	stronger.Argon2Iterations = 2
+	if !stronger.NeedsRehash(hash) {
+		t.Fatalf("expected rehash after raising iterations")
+	}
+}
+
+func TestPasswordHasherMigratesFromBcrypt(t *testing.T) {
+	legacy, err := HashPassword("s3cret-passphrase")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if (PasswordHasher{}).NeedsRehash(legacy) {
+		t.Fatalf("default bcrypt hash should match default hasher")
+	}
+	if !(PasswordHasher{BcryptCost: 12}).NeedsRehash(legacy) {
+		t.Fatalf("expected rehash after raising bcrypt cost")
+	}
+
+	hasher := PasswordHasher{Algorithm: HashAlgorithmArgon2id, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+	if !hasher.Verify(legacy, "s3cret-passphrase") {
+		t.Fatalf("argon2id hasher should still verify bcrypt hashes")
+	}
+	if !hasher.NeedsRehash(legacy) {
+		t.Fatalf("expected bcrypt hash to be upgraded to argon2id")
+	}
+}
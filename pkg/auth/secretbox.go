@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// EncryptSecret 使用 AES-256-GCM 加密密钥材料，passphrase 经 SHA-256 派生为加密密钥，输出 base64(nonce||密文)。
+func EncryptSecret(passphrase string, plaintext []byte) (string, error) {
+	aead, err := newSecretAEAD(passphrase)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// TenantSecret 由主密钥为租户派生独立的加密口令（HMAC-SHA256），
+// 各租户的密文互不通用，主密钥为空时返回空字符串。
+func TenantSecret(master, tenant string) string {
+	if master == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(master))
+	mac.Write([]byte("tenant:" + tenant))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DecryptSecret 解密 EncryptSecret 的输出。
+func DecryptSecret(passphrase, encoded string) ([]byte, error) {
+	aead, err := newSecretAEAD(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted secret too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newSecretAEAD(passphrase string) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("encryption key missing")
+	}
+	sum := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,62 @@
+// Package buildinfo 提供构建元数据。发布构建通过 -ldflags 注入：
+//
+//	go build -ldflags "-X github.com/zacharykka/prompt-manager/pkg/buildinfo.Version=v1.2.3 \
+//	  -X github.com/zacharykka/prompt-manager/pkg/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/zacharykka/prompt-manager/pkg/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// 未注入时回退到 Go 工具链记录的模块版本与 VCS 信息。
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// 由 -ldflags -X 注入，为空时使用 debug.ReadBuildInfo 中的信息。
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Info 为当前二进制的构建信息。
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get 返回构建信息：版本缺省为模块版本，本地构建为 devel 并附带修订号前 12 位；提交与构建时间缺省取自 VCS 记录。
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		if info.Version == "" {
+			info.Version = "unknown"
+		}
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = build.Main.Version
+		if info.Version == "" || info.Version == "(devel)" {
+			info.Version = "devel"
+			if len(info.Commit) >= 12 {
+				info.Version += "+" + info.Commit[:12]
+			}
+		}
+	}
+	return info
+}
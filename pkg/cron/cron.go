@@ -0,0 +1,127 @@
+// Package cron 解析标准五段式 cron 表达式（分 时 日 月 周），用于判断某一分钟是否命中计划。
+//
+// 每段支持 *、数字、范围（1-5）、步长（*/15、1-30/5）与逗号分隔的列表；周取 0-7，0 与 7 均为周日。
+// 与常见实现一致，日与周同时受限时任一命中即视为命中。不支持月份与星期的英文缩写。
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 为解析后的 cron 计划。
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse 解析五段式 cron 表达式。
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expr)
+	}
+	var bits [5]uint64
+	for i, part := range parts {
+		value, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = value
+	}
+	// 周日既可写作 0 也可写作 7，统一到 0。
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &Schedule{
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			value, err := strconv.Atoi(item[idx+1:])
+			if err != nil || value < 1 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, item)
+			}
+			rangeExpr, step = item[:idx], value
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err1, err2 error
+			low, err1 = strconv.Atoi(bounds[0])
+			high, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil || low > high {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, item)
+			}
+		default:
+			value, err := strconv.Atoi(rangeExpr)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s field %q", f.name, item)
+			}
+			low, high = value, value
+			if step > 1 {
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max {
+			return 0, fmt.Errorf("%s field %q out of range %d-%d", f.name, item, f.min, f.max)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// Matches 判断 t 所在的分钟是否命中计划，按 t 自身的时区计算。
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 || s.hour&(1<<uint(t.Hour())) == 0 || s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Previous 返回 (t-within, t] 内最近一次命中的分钟，未命中时 ok 为 false。
+func (s *Schedule) Previous(t time.Time, within time.Duration) (time.Time, bool) {
+	earliest := t.Add(-within)
+	for candidate := t.Truncate(time.Minute); candidate.After(earliest); candidate = candidate.Add(-time.Minute) {
+		if s.Matches(candidate) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,103 @@
+package distlock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zacharykka/prompt-manager/pkg/distlock"
+	"github.com/zacharykka/prompt-manager/pkg/distlock/distlocktest"
+)
+
+func TestAcquireIsExclusiveUntilExpiry(t *testing.T) {
+	store := distlocktest.NewStore()
+	locker := distlock.New(store)
+	ctx := context.Background()
+
+	first, err := locker.Acquire(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if first.Name() != "job" || first.TTL() != time.Minute {
+		t.Fatalf("unexpected lease %+v", first)
+	}
+	if _, err := locker.Acquire(ctx, "job", time.Minute); !errors.Is(err, distlock.ErrNotAcquired) {
+		t.Fatalf("expected ErrNotAcquired while held got %v", err)
+	}
+	if _, err := locker.Acquire(ctx, "other", time.Minute); err != nil {
+		t.Fatalf("different lock names must not conflict: %v", err)
+	}
+
+	store.Advance(time.Minute)
+	second, err := locker.Acquire(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("acquire after expiry: %v", err)
+	}
+	if second.Token <= first.Token {
+		t.Fatalf("fencing token must increase: %d then %d", first.Token, second.Token)
+	}
+}
+
+func TestRefreshAndReleaseOnlyActForOwner(t *testing.T) {
+	store := distlocktest.NewStore()
+	locker := distlock.New(store)
+	ctx := context.Background()
+
+	stale, err := locker.Acquire(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	store.Advance(30 * time.Second)
+	if err := stale.Refresh(ctx); err != nil {
+		t.Fatalf("refresh by owner: %v", err)
+	}
+	store.Advance(45 * time.Second)
+	if _, err := locker.Acquire(ctx, "job", time.Minute); !errors.Is(err, distlock.ErrNotAcquired) {
+		t.Fatalf("refresh should extend the lease, got %v", err)
+	}
+
+	store.Advance(time.Minute)
+	current, err := locker.Acquire(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("acquire after expiry: %v", err)
+	}
+	if current.Token <= stale.Token {
+		t.Fatalf("fencing token must increase: %d then %d", stale.Token, current.Token)
+	}
+	if err := stale.Refresh(ctx); !errors.Is(err, distlock.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost refreshing a lost lease got %v", err)
+	}
+	if err := stale.Release(ctx); !errors.Is(err, distlock.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost releasing a lost lease got %v", err)
+	}
+	if _, err := locker.Acquire(ctx, "job", time.Minute); !errors.Is(err, distlock.ErrNotAcquired) {
+		t.Fatalf("stale owner must not release the current holder's lock, got %v", err)
+	}
+
+	if err := current.Release(ctx); err != nil {
+		t.Fatalf("release by owner: %v", err)
+	}
+	if _, err := locker.Acquire(ctx, "job", time.Minute); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+}
+
+func TestKeyPrefixAndTokenContext(t *testing.T) {
+	store := distlocktest.NewStore()
+	ctx := context.Background()
+
+	if _, err := distlock.New(store, distlock.WithKeyPrefix("a:")).Acquire(ctx, "job", time.Minute); err != nil {
+		t.Fatalf("acquire with prefix a: %v", err)
+	}
+	if _, err := distlock.New(store, distlock.WithKeyPrefix("b:")).Acquire(ctx, "job", time.Minute); err != nil {
+		t.Fatalf("locks under different prefixes must not conflict: %v", err)
+	}
+
+	if _, ok := distlock.TokenFromContext(ctx); ok {
+		t.Fatalf("expected no token on a bare context")
+	}
+	if token, ok := distlock.TokenFromContext(distlock.WithToken(ctx, 7)); !ok || token != 7 {
+		t.Fatalf("expected token 7 got %d (%v)", token, ok)
+	}
+}
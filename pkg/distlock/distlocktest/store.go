@@ -0,0 +1,146 @@
+// Package distlocktest 提供 distlock 使用的 Redis 脚本的内存实现，便于在无 Redis 的环境中测试加锁逻辑。
+package distlocktest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scriptError 实现 redis.Error，使 redis.Script.Run 将 NOSCRIPT 识别为服务端错误。
+type scriptError string
+
+func (e scriptError) Error() string { return string(e) }
+
+func (scriptError) RedisError() {}
+
+const errNoScript scriptError = "NOSCRIPT No matching script"
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Store 以内存模拟 distlock 的获取、续期与释放脚本，实现 redis.Scripter，可并发使用。
+// 时间由 Advance 推进，键过期语义与 Redis 的 PX/PEXPIRE 一致。
+type Store struct {
+	mu       sync.Mutex
+	now      time.Time
+	values   map[string]entry
+	counters map[string]int64
+}
+
+var _ redis.Scripter = (*Store)(nil)
+
+// NewStore 创建空的内存存储。
+func NewStore() *Store {
+	return &Store{now: time.Now(), values: map[string]entry{}, counters: map[string]int64{}}
+}
+
+// Advance 推进模拟时钟，到期的锁随之失效。
+func (s *Store) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// Evict 删除锁键，模拟租约意外过期（例如持有者长时间停顿）。
+func (s *Store) Evict(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// Eval 按脚本内容识别 distlock 的获取、续期与释放脚本并执行。
+func (s *Store) Eval(_ context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.Contains(script, "'NX'"):
+		return redis.NewCmdResult(s.acquire(keys, args), nil)
+	case strings.Contains(script, "PEXPIRE"):
+		return redis.NewCmdResult(s.refresh(keys, args), nil)
+	case strings.Contains(script, "DEL"):
+		return redis.NewCmdResult(s.release(keys, args), nil)
+	default:
+		return redis.NewCmdResult(nil, fmt.Errorf("distlocktest: unsupported script %q", script))
+	}
+}
+
+// EvalSha 总是返回 NOSCRIPT，使 redis.Script.Run 回退到 Eval。
+func (s *Store) EvalSha(context.Context, string, []string, ...interface{}) *redis.Cmd {
+	return redis.NewCmdResult(nil, errNoScript)
+}
+
+// EvalRO 与 Eval 相同。
+func (s *Store) EvalRO(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	return s.Eval(ctx, script, keys, args...)
+}
+
+// EvalShaRO 与 EvalSha 相同。
+func (s *Store) EvalShaRO(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd {
+	return s.EvalSha(ctx, sha1, keys, args...)
+}
+
+// ScriptExists 报告所有脚本均未缓存。
+func (s *Store) ScriptExists(ctx context.Context, hashes ...string) *redis.BoolSliceCmd {
+	cmd := redis.NewBoolSliceCmd(ctx)
+	cmd.SetVal(make([]bool, len(hashes)))
+	return cmd
+}
+
+// ScriptLoad 不缓存脚本，仅返回空摘要。
+func (s *Store) ScriptLoad(ctx context.Context, _ string) *redis.StringCmd {
+	cmd := redis.NewStringCmd(ctx)
+	cmd.SetVal("")
+	return cmd
+}
+
+func (s *Store) acquire(keys []string, args []interface{}) int64 {
+	if _, held := s.get(keys[0]); held {
+		return 0
+	}
+	s.values[keys[0]] = entry{value: fmt.Sprint(args[0]), expiresAt: s.now.Add(millis(args[1]))}
+	s.counters[keys[1]]++
+	return s.counters[keys[1]]
+}
+
+func (s *Store) refresh(keys []string, args []interface{}) int64 {
+	current, held := s.get(keys[0])
+	if !held || current.value != fmt.Sprint(args[0]) {
+		return 0
+	}
+	current.expiresAt = s.now.Add(millis(args[1]))
+	s.values[keys[0]] = current
+	return 1
+}
+
+func (s *Store) release(keys []string, args []interface{}) int64 {
+	current, held := s.get(keys[0])
+	if !held || current.value != fmt.Sprint(args[0]) {
+		return 0
+	}
+	delete(s.values, keys[0])
+	return 1
+}
+
+func (s *Store) get(key string) (entry, bool) {
+	current, ok := s.values[key]
+	if !ok {
+		return entry{}, false
+	}
+	if !s.now.Before(current.expiresAt) {
+		delete(s.values, key)
+		return entry{}, false
+	}
+	return current, true
+}
+
+func millis(arg interface{}) time.Duration {
+	ms, _ := arg.(int64)
+	return time.Duration(ms) * time.Millisecond
+}
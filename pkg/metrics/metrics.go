@@ -0,0 +1,197 @@
+// Package metrics 提供最小化的 Prometheus 文本格式指标（计数器与仪表），避免为少量指标引入完整客户端库。
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Collector 为可注册到 Registry 的指标，由本包的 CounterVec 与 Gauge 实现。
+type Collector interface {
+	metricName() string
+	write(w io.Writer) error
+}
+
+// CounterVec 为带标签的单调递增计数器。
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	count       uint64
+}
+
+// NewCounterVec 创建计数器，labels 为标签名列表。
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{name: name, help: help, labels: labels, values: make(map[string]*counterValue)}
+}
+
+// Inc 为给定标签值组合加一，标签值数量须与标签名一致。
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 为给定标签值组合累加 delta。
+func (c *CounterVec) Add(delta uint64, labelValues ...string) {
+	if len(labelValues) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", c.name, len(c.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		value = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = value
+	}
+	value.count += delta
+}
+
+// Value 返回给定标签值组合的当前计数。
+func (c *CounterVec) Value(labelValues ...string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if value, ok := c.values[strings.Join(labelValues, "\xff")]; ok {
+		return value.count
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	values := make([]*counterValue, 0, len(c.values))
+	for _, value := range c.values {
+		values = append(values, &counterValue{labelValues: value.labelValues, count: value.count})
+	}
+	c.mu.Unlock()
+	sort.Slice(values, func(i, j int) bool {
+		return strings.Join(values[i].labelValues, "\xff") < strings.Join(values[j].labelValues, "\xff")
+	})
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, escapeHelp(c.help), c.name); err != nil {
+		return err
+	}
+	for _, value := range values {
+		pairs := make([]string, len(c.labels))
+		for i, label := range c.labels {
+			pairs[i] = fmt.Sprintf("%s=\"%s\"", label, escapeLabel(value.labelValues[i]))
+		}
+		labels := ""
+		if len(pairs) > 0 {
+			labels = "{" + strings.Join(pairs, ",") + "}"
+		}
+		if _, err := fmt.Fprintf(w, "%s%s %d\n", c.name, labels, value.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CounterVec) metricName() string {
+	return c.name
+}
+
+// Gauge 为可增可减的无标签仪表，适合队列深度等瞬时值。
+type Gauge struct {
+	name  string
+	help  string
+	value atomic.Int64
+}
+
+// NewGauge 创建仪表。
+func NewGauge(name, help string) *Gauge {
+	return &Gauge{name: name, help: help}
+}
+
+// Add 为仪表累加 delta，delta 可为负数。
+func (g *Gauge) Add(delta int64) {
+	g.value.Add(delta)
+}
+
+// Set 设置仪表的当前值。
+func (g *Gauge) Set(value int64) {
+	g.value.Store(value)
+}
+
+// Value 返回仪表的当前值。
+func (g *Gauge) Value() int64 {
+	return g.value.Load()
+}
+
+func (g *Gauge) metricName() string {
+	return g.name
+}
+
+func (g *Gauge) write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", g.name, escapeHelp(g.help), g.name, g.name, g.Value())
+	return err
+}
+
+// Registry 汇总需要对外暴露的指标。
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry 创建空的指标注册表。
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default 为进程级默认注册表，由 /metrics 暴露。
+var Default = NewRegistry()
+
+// MustRegister 注册指标，同名指标重复注册时 panic。
+func (r *Registry) MustRegister(collectors ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, collector := range collectors {
+		for _, existing := range r.collectors {
+			if existing.metricName() == collector.metricName() {
+				panic("metrics: duplicate metric " + collector.metricName())
+			}
+		}
+		r.collectors = append(r.collectors, collector)
+	}
+}
+
+// WriteText 以 Prometheus 文本格式输出全部指标。
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].metricName() < collectors[j].metricName() })
+	for _, collector := range collectors {
+		if err := collector.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler 返回输出 Prometheus 文本格式的 HTTP 处理器。
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+func escapeHelp(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(value)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
@@ -0,0 +1,439 @@
+// Package render 实现 Prompt 模板渲染引擎。
+//
+// 语法兼容 {{ name }} 变量占位，并支持管道函数（{{ name | default "x" | upper }}）、
+// 条件（{{#if}}/{{#unless}}/{{else}}）、循环（{{#each}}，循环体内可用 this、@index、@key）
+// 以及注释（{{! ... }}）。{{> name}} 引用原样保留。引擎只允许白名单函数，
+// 并对循环次数、嵌套深度与输出大小设置上限。
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EngineVersion 标识模板语法与渲染行为的版本，可写入渲染结果便于追溯。
+const EngineVersion = "1." + FunctionLibraryVersion
+
+var (
+	ErrIterationLimit = errors.New("template iteration limit exceeded")
+	ErrOutputLimit    = errors.New("template output size limit exceeded")
+)
+
+// MissingMode 控制变量缺失时的渲染行为。
+type MissingMode string
+
+const (
+	// MissingLenient 保留原始占位符（默认）。
+	MissingLenient MissingMode = "lenient"
+	// MissingStrict 存在缺失变量时返回 *MissingVariablesError。
+	MissingStrict MissingMode = "strict"
+	// MissingDefault 使用 Options.Defaults 填充缺失的顶层变量，仍缺失的保留占位符。
+	MissingDefault MissingMode = "default"
+)
+
+// ParseMissingMode 解析渲染模式，空字符串返回空模式表示未指定。
+func ParseMissingMode(value string) (MissingMode, bool) {
+	switch mode := MissingMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "", MissingLenient, MissingStrict, MissingDefault:
+		return mode, true
+	default:
+		return "", false
+	}
+}
+
+// MissingVariablesError 在 strict 模式下列出缺失的变量（按首次出现顺序）。
+type MissingVariablesError struct {
+	Keys []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("missing template variables: %s", strings.Join(e.Keys, ", "))
+}
+
+// Limits 约束单次渲染可消耗的资源。
+type Limits struct {
+	MaxIterations  int
+	MaxDepth       int
+	MaxOutputBytes int
+}
+
+// DefaultLimits 返回默认的渲染限制。
+func DefaultLimits() Limits {
+	return Limits{MaxIterations: 1000, MaxDepth: 8, MaxOutputBytes: 1 << 20}
+}
+
+// Options 控制单次渲染行为。
+type Options struct {
+	Limits   Limits
+	Missing  MissingMode
+	Defaults map[string]interface{}
+}
+
+// Template 为解析后的模板，可并发复用。
+type Template struct {
+	root      []node
+	variables []string
+}
+
+// Parse 解析模板，语法错误返回 *ParseError。
+func Parse(src string) (*Template, error) {
+	return parse(src, DefaultLimits().MaxDepth)
+}
+
+// Render 解析并渲染模板。
+func Render(src string, data map[string]interface{}, opts Options) (string, error) {
+	tmpl, err := parse(src, limitsOrDefault(opts.Limits).MaxDepth)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Execute(data, opts)
+}
+
+// Variables 返回模板引用的顶层变量名（按首次出现顺序）。
+func (t *Template) Variables() []string {
+	return append([]string(nil), t.variables...)
+}
+
+// Execute 使用给定数据渲染模板，缺失变量的处理方式由 opts.Missing 决定。
+func (t *Template) Execute(data map[string]interface{}, opts Options) (string, error) {
+	st := &state{limits: limitsOrDefault(opts.Limits), strict: opts.Missing == MissingStrict}
+	if opts.Missing == MissingDefault && len(opts.Defaults) > 0 {
+		merged := make(map[string]interface{}, len(data)+len(opts.Defaults))
+		for key, value := range opts.Defaults {
+			merged[key] = value
+		}
+		for key, value := range data {
+			merged[key] = value
+		}
+		data = merged
+	}
+	if err := st.walk(t.root, &scope{data: data}); err != nil {
+		return "", err
+	}
+	if len(st.missing) > 0 {
+		return "", &MissingVariablesError{Keys: st.missing}
+	}
+	return st.out.String(), nil
+}
+
+func limitsOrDefault(limits Limits) Limits {
+	defaults := DefaultLimits()
+	if limits.MaxIterations <= 0 {
+		limits.MaxIterations = defaults.MaxIterations
+	}
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = defaults.MaxDepth
+	}
+	if limits.MaxOutputBytes <= 0 {
+		limits.MaxOutputBytes = defaults.MaxOutputBytes
+	}
+	return limits
+}
+
+type scope struct {
+	data   map[string]interface{}
+	this   interface{}
+	index  int
+	key    string
+	inLoop bool
+	parent *scope
+}
+
+type state struct {
+	limits     Limits
+	iterations int
+	strict     bool
+	missing    []string
+	seen       map[string]struct{}
+	out        strings.Builder
+}
+
+func (st *state) markMissing(key string) {
+	if _, ok := st.seen[key]; ok {
+		return
+	}
+	if st.seen == nil {
+		st.seen = make(map[string]struct{})
+	}
+	st.seen[key] = struct{}{}
+	st.missing = append(st.missing, key)
+}
+
+func (st *state) write(text string) error {
+	if st.out.Len()+len(text) > st.limits.MaxOutputBytes {
+		return ErrOutputLimit
+	}
+	st.out.WriteString(text)
+	return nil
+}
+
+func (st *state) walk(nodes []node, sc *scope) error {
+	for _, n := range nodes {
+		switch current := n.(type) {
+		case *textNode:
+			if err := st.write(current.text); err != nil {
+				return err
+			}
+		case *outputNode:
+			value, found, err := evaluate(current.expr, sc)
+			if err != nil {
+				return err
+			}
+			text := current.raw
+			if found {
+				text = formatValue(value)
+			} else if st.strict {
+				st.markMissing(current.expr.operand.path)
+			}
+			if err := st.write(text); err != nil {
+				return err
+			}
+		case *ifNode:
+			value, found, err := evaluate(current.cond, sc)
+			if err != nil {
+				return err
+			}
+			cond := found && truthy(value)
+			if current.negate {
+				cond = !cond
+			}
+			branch := current.els
+			if cond {
+				branch = current.then
+			}
+			if err := st.walk(branch, sc); err != nil {
+				return err
+			}
+		case *eachNode:
+			if err := st.walkEach(current, sc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (st *state) walkEach(n *eachNode, sc *scope) error {
+	value, found, err := evaluate(n.source, sc)
+	if err != nil {
+		return err
+	}
+	if !found || !truthy(value) {
+		return st.walk(n.els, sc)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := st.tick(); err != nil {
+				return err
+			}
+			child := &scope{this: rv.Index(i).Interface(), index: i, inLoop: true, parent: sc}
+			if err := st.walk(n.body, child); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := make([]string, 0, rv.Len())
+		values := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			name := fmt.Sprint(key.Interface())
+			keys = append(keys, name)
+			values[name] = rv.MapIndex(key).Interface()
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if err := st.tick(); err != nil {
+				return err
+			}
+			child := &scope{this: values[key], index: i, key: key, inLoop: true, parent: sc}
+			if err := st.walk(n.body, child); err != nil {
+				return err
+			}
+		}
+	default:
+		return st.walk(n.els, sc)
+	}
+	return nil
+}
+
+func (st *state) tick() error {
+	st.iterations++
+	if st.iterations > st.limits.MaxIterations {
+		return ErrIterationLimit
+	}
+	return nil
+}
+
+func evaluate(expr *expression, sc *scope) (interface{}, bool, error) {
+	value, found := resolveOperand(expr.operand, sc)
+	for _, c := range expr.calls {
+		fn := functions[c.name]
+		if !found && fn.skipMissing {
+			continue
+		}
+		args := make([]interface{}, 0, len(c.args))
+		for _, arg := range c.args {
+			argValue, _ := resolveOperand(arg, sc)
+			args = append(args, argValue)
+		}
+		result, err := fn.call(value, found, args)
+		if err != nil {
+			return nil, false, fmt.Errorf("function %s: %w", c.name, err)
+		}
+		value, found = result, true
+	}
+	return value, found, nil
+}
+
+func resolveOperand(op operand, sc *scope) (interface{}, bool) {
+	if op.isLit {
+		return op.literal, true
+	}
+	switch op.path {
+	case "@index":
+		for s := sc; s != nil; s = s.parent {
+			if s.inLoop {
+				return float64(s.index), true
+			}
+		}
+		return nil, false
+	case "@key":
+		for s := sc; s != nil; s = s.parent {
+			if s.inLoop {
+				return s.key, s.key != ""
+			}
+		}
+		return nil, false
+	case "this":
+		for s := sc; s != nil; s = s.parent {
+			if s.inLoop {
+				return s.this, true
+			}
+		}
+		return nil, false
+	}
+
+	parts := strings.Split(op.path, ".")
+	if parts[0] == "this" {
+		for s := sc; s != nil; s = s.parent {
+			if s.inLoop {
+				return lookupPath(s.this, parts[1:])
+			}
+		}
+		return nil, false
+	}
+
+	// 循环体内优先从当前元素查找字段，再逐层回退到外层作用域与根数据。
+	for s := sc; s != nil; s = s.parent {
+		if s.inLoop {
+			if value, ok := lookupPath(s.this, parts); ok {
+				return value, true
+			}
+			continue
+		}
+		if value, ok := lookupPath(s.data, parts); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func lookupPath(current interface{}, parts []string) (interface{}, bool) {
+	for _, part := range parts {
+		if current == nil {
+			return nil, false
+		}
+		rv := reflect.ValueOf(current)
+		switch rv.Kind() {
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			value := rv.MapIndex(reflect.ValueOf(part).Convert(rv.Type().Key()))
+			if !value.IsValid() {
+				return nil, false
+			}
+			current = value.Interface()
+		case reflect.Slice, reflect.Array:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= rv.Len() {
+				return nil, false
+			}
+			current = rv.Index(idx).Interface()
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func truthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case int:
+		return v != 0
+	case int64:
+		return v != 0
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() > 0
+	case reflect.Ptr, reflect.Interface:
+		return !rv.IsNil()
+	}
+	return true
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool, int, int64, int32, uint, uint64, float32:
+		return fmt.Sprint(v)
+	case json.Number:
+		return v.String()
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		data, err := json.Marshal(value)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+func toList(value interface{}) []interface{} {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, rv.Index(i).Interface())
+		}
+		return items
+	default:
+		return []interface{}{value}
+	}
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FunctionLibraryVersion 标识内置函数库的行为版本；任何函数语义变化都必须提升该版本。
+const FunctionLibraryVersion = "1"
+
+// FunctionDoc 描述一个模板函数，供文档与前端提示使用。
+type FunctionDoc struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+}
+
+type function struct {
+	minArgs int
+	maxArgs int
+	// skipMissing 为 true 时，输入值缺失会直接向后传递缺失状态而不调用函数。
+	skipMissing bool
+	call        func(value interface{}, found bool, args []interface{}) (interface{}, error)
+	doc         FunctionDoc
+}
+
+// functions 为白名单函数库，全部为纯函数，不访问文件、环境变量或网络。
+var functions = map[string]function{
+	"upper": {skipMissing: true, call: func(v interface{}, _ bool, _ []interface{}) (interface{}, error) {
+		return strings.ToUpper(formatValue(v)), nil
+	}, doc: FunctionDoc{Name: "upper", Usage: `{{ name | upper }}`, Description: "转换为大写"}},
+	"lower": {skipMissing: true, call: func(v interface{}, _ bool, _ []interface{}) (interface{}, error) {
+		return strings.ToLower(formatValue(v)), nil
+	}, doc: FunctionDoc{Name: "lower", Usage: `{{ name | lower }}`, Description: "转换为小写"}},
+	"trim": {skipMissing: true, call: func(v interface{}, _ bool, _ []interface{}) (interface{}, error) {
+		return strings.TrimSpace(formatValue(v)), nil
+	}, doc: FunctionDoc{Name: "trim", Usage: `{{ name | trim }}`, Description: "去除首尾空白"}},
+	"default": {minArgs: 1, maxArgs: 1, call: func(v interface{}, found bool, args []interface{}) (interface{}, error) {
+		if !found || !truthy(v) {
+			return args[0], nil
+		}
+		return v, nil
+	}, doc: FunctionDoc{Name: "default", Usage: `{{ name | default "anonymous" }}`, Description: "变量缺失或为空时使用默认值"}},
+	"json": {skipMissing: true, call: func(v interface{}, _ bool, _ []interface{}) (interface{}, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}, doc: FunctionDoc{Name: "json", Usage: `{{ payload | json }}`, Description: "序列化为 JSON 字符串"}},
+	"truncate": {minArgs: 1, maxArgs: 2, skipMissing: true, call: func(v interface{}, _ bool, args []interface{}) (interface{}, error) {
+		limit, ok := args[0].(float64)
+		// !(limit >= 0) 同时拒绝 NaN。
+		if !ok || !(limit >= 0) {
+			return nil, fmt.Errorf("truncate length must be a non-negative number")
+		}
+		suffix := ""
+		if len(args) > 1 {
+			suffix = formatValue(args[1])
+		}
+		text := formatValue(v)
+		// 先以浮点比较，避免超出 int 范围的长度（如 1e300）转换溢出。
+		if float64(utf8.RuneCountInString(text)) <= limit {
+			return text, nil
+		}
+		return string([]rune(text)[:int(limit)]) + suffix, nil
+	}, doc: FunctionDoc{Name: "truncate", Usage: `{{ text | truncate 100 "..." }}`, Description: "按字符数截断，可选追加后缀"}},
+	"date": {minArgs: 1, maxArgs: 1, skipMissing: true, call: func(v interface{}, _ bool, args []interface{}) (interface{}, error) {
+		layout, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("date layout must be a string")
+		}
+		t, err := toTime(v)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(layout), nil
+	}, doc: FunctionDoc{Name: "date", Usage: `{{ created_at | date "2006-01-02" }}`, Description: "格式化 RFC3339 字符串或 Unix 秒时间戳（Go 布局语法，按 UTC）"}},
+	"join": {minArgs: 1, maxArgs: 1, skipMissing: true, call: func(v interface{}, _ bool, args []interface{}) (interface{}, error) {
+		items := toList(v)
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, formatValue(item))
+		}
+		return strings.Join(parts, formatValue(args[0])), nil
+	}, doc: FunctionDoc{Name: "join", Usage: `{{ tags | join ", " }}`, Description: "以分隔符拼接列表"}},
+}
+
+// Functions 返回内置函数文档，按名称排序。
+func Functions() []FunctionDoc {
+	names := []string{"date", "default", "join", "json", "lower", "trim", "truncate", "upper"}
+	docs := make([]FunctionDoc, 0, len(names))
+	for _, name := range names {
+		docs = append(docs, functions[name].doc)
+	}
+	return docs
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch value := v.(type) {
+	case time.Time:
+		return value.UTC(), nil
+	case string:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("date expects RFC3339 string: %w", err)
+		}
+		return t.UTC(), nil
+	case float64:
+		return time.Unix(int64(value), 0).UTC(), nil
+	case int:
+		return time.Unix(int64(value), 0).UTC(), nil
+	case int64:
+		return time.Unix(value, 0).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("date cannot format %T", v)
+	}
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fuzzData 覆盖引擎支持的各类取值：嵌套对象、列表、数字、布尔与时间字符串。
+func fuzzData() map[string]interface{} {
+	return map[string]interface{}{
+		"name":    "Ada",
+		"count":   float64(3),
+		"vip":     true,
+		"created": "2025-01-02T03:04:05Z",
+		"items": []interface{}{
+			map[string]interface{}{"title": "alpha", "tags": []interface{}{"x", "y"}},
+			map[string]interface{}{"title": "beta"},
+		},
+		"meta": map[string]interface{}{"k": "v", "n": float64(1)},
+	}
+}
+
+func FuzzRender(f *testing.F) {
+	seeds := []string{
+		"",
+		"plain text",
+		"Hello {{ name }}",
+		`Hi {{ name | default "guest" | upper }}!`,
+		"{{#if items}}{{#each items}}{{@index}}:{{ title | truncate 3 \"…\" }};{{/each}}{{else}}none{{/if}}",
+		"{{#each meta}}{{@key}}={{this}}{{/each}}",
+		"{{#unless vip}}regular{{/unless}} {{ created | date \"2006-01-02\" }} {{ meta | json }}",
+		"{{#each items}}{{#each items}}{{#each items}}{{this}}{{/each}}{{/each}}{{/each}}",
+		"{{ name | truncate 1e300 }}",
+		"{{ name | truncate -1 }}",
+		"{{ items | join \", \" }}",
+		"{{! comment }}{{> shared}}{{else}}",
+		"{{", "}}", "{{}}", "{{#}}", "{{/}}", "{{ \"unterminated }}",
+		strings.Repeat("{{#if a}}", 9) + strings.Repeat("{{/if}}", 9),
+		strings.Repeat("\n{{ name }}", 64),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	limits := Limits{MaxIterations: 64, MaxDepth: 8, MaxOutputBytes: 1 << 12}
+	f.Fuzz(func(t *testing.T, src string) {
+		tmpl, err := Parse(src)
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %T: %v", err, err)
+			}
+			if parseErr.Line < 1 || parseErr.Line > strings.Count(src, "\n")+1 {
+				t.Fatalf("line %d out of range for %q", parseErr.Line, src)
+			}
+			return
+		}
+		_ = tmpl.Variables()
+
+		// 不含标签起始符的文本原样输出。
+		if !strings.Contains(src, "{{") {
+			out, err := tmpl.Execute(nil, Options{})
+			if err != nil || out != src {
+				t.Fatalf("plain text changed: %q -> %q (%v)", src, out, err)
+			}
+		}
+
+		for _, mode := range []MissingMode{MissingLenient, MissingStrict} {
+			opts := Options{Limits: limits, Missing: mode}
+			out, err := tmpl.Execute(fuzzData(), opts)
+			if err == nil && len(out) > limits.MaxOutputBytes {
+				t.Fatalf("output of %d bytes exceeds limit", len(out))
+			}
+			// 相同输入的渲染结果必须确定。
+			again, againErr := tmpl.Execute(fuzzData(), opts)
+			if out != again || (err == nil) != (againErr == nil) {
+				t.Fatalf("non-deterministic render of %q", src)
+			}
+		}
+	})
+}
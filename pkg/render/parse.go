@@ -0,0 +1,380 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type node interface{}
+
+type textNode struct {
+	text string
+}
+
+type outputNode struct {
+	expr *expression
+	raw  string
+}
+
+type ifNode struct {
+	cond   *expression
+	negate bool
+	then   []node
+	els    []node
+}
+
+type eachNode struct {
+	source *expression
+	body   []node
+	els    []node
+}
+
+// expression 由一个操作数和若干管道函数组成，例如 name | default "x" | upper。
+type expression struct {
+	operand operand
+	calls   []call
+}
+
+type operand struct {
+	path    string
+	literal interface{}
+	isLit   bool
+}
+
+type call struct {
+	name string
+	args []operand
+}
+
+// ParseError 描述模板语法错误及其所在行。
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("template parse error at line %d: %s", e.Line, e.Message)
+}
+
+type blockFrame struct {
+	kind   string
+	node   node
+	inElse bool
+	line   int
+}
+
+type parser struct {
+	src      string
+	maxDepth int
+	root     []node
+	stack    []*blockFrame
+	paths    map[string]struct{}
+	order    []string
+}
+
+func parse(src string, maxDepth int) (*Template, error) {
+	p := &parser{src: src, maxDepth: maxDepth, paths: make(map[string]struct{})}
+	pos := 0
+	// 行号随扫描位置增量累计，避免每个标签都从头计数导致大模板解析退化为平方复杂度。
+	line, counted := 1, 0
+	lineAt := func(offset int) int {
+		line += strings.Count(src[counted:offset], "\n")
+		counted = offset
+		return line
+	}
+	for pos < len(src) {
+		start := strings.Index(src[pos:], "{{")
+		if start < 0 {
+			p.appendNode(&textNode{text: src[pos:]})
+			break
+		}
+		start += pos
+		if start > pos {
+			p.appendNode(&textNode{text: src[pos:start]})
+		}
+		end := strings.Index(src[start+2:], "}}")
+		if end < 0 {
+			return nil, &ParseError{Line: lineAt(start), Message: "unclosed tag"}
+		}
+		end += start + 2
+		raw := src[start : end+2]
+		if err := p.handleTag(raw, strings.TrimSpace(src[start+2:end]), lineAt(start)); err != nil {
+			return nil, err
+		}
+		pos = end + 2
+	}
+
+	if len(p.stack) > 0 {
+		frame := p.stack[len(p.stack)-1]
+		return nil, &ParseError{Line: frame.line, Message: fmt.Sprintf("unclosed {{#%s}} block", frame.kind)}
+	}
+	return &Template{root: p.root, variables: p.order}, nil
+}
+
+func (p *parser) handleTag(raw, body string, line int) error {
+	switch {
+	case strings.HasPrefix(body, "!"):
+		return nil
+	case strings.HasPrefix(body, ">"):
+		// include 引用由依赖解析处理，渲染时原样保留。
+		p.appendNode(&textNode{text: raw})
+		return nil
+	case strings.HasPrefix(body, "#"):
+		return p.openBlock(strings.TrimSpace(body[1:]), line)
+	case strings.HasPrefix(body, "/"):
+		return p.closeBlock(strings.TrimSpace(body[1:]), line)
+	case body == "else":
+		if len(p.stack) == 0 {
+			return &ParseError{Line: line, Message: "{{else}} outside of block"}
+		}
+		frame := p.stack[len(p.stack)-1]
+		if frame.inElse {
+			return &ParseError{Line: line, Message: "duplicate {{else}}"}
+		}
+		frame.inElse = true
+		return nil
+	default:
+		expr, err := p.parseExpression(body, line)
+		if err != nil {
+			return err
+		}
+		p.appendNode(&outputNode{expr: expr, raw: raw})
+		return nil
+	}
+}
+
+func (p *parser) openBlock(body string, line int) error {
+	if p.maxDepth > 0 && len(p.stack) >= p.maxDepth {
+		return &ParseError{Line: line, Message: fmt.Sprintf("block nesting exceeds limit %d", p.maxDepth)}
+	}
+	kind, rest := splitFirst(body)
+	if rest == "" {
+		return &ParseError{Line: line, Message: fmt.Sprintf("{{#%s}} requires an expression", kind)}
+	}
+	expr, err := p.parseExpression(rest, line)
+	if err != nil {
+		return err
+	}
+
+	var n node
+	switch kind {
+	case "if":
+		n = &ifNode{cond: expr}
+	case "unless":
+		n = &ifNode{cond: expr, negate: true}
+	case "each":
+		n = &eachNode{source: expr}
+	default:
+		return &ParseError{Line: line, Message: fmt.Sprintf("unknown block %q", kind)}
+	}
+	p.appendNode(n)
+	p.stack = append(p.stack, &blockFrame{kind: kind, node: n, line: line})
+	return nil
+}
+
+func (p *parser) closeBlock(kind string, line int) error {
+	if len(p.stack) == 0 {
+		return &ParseError{Line: line, Message: fmt.Sprintf("unexpected {{/%s}}", kind)}
+	}
+	frame := p.stack[len(p.stack)-1]
+	if frame.kind != kind {
+		return &ParseError{Line: line, Message: fmt.Sprintf("expected {{/%s}} but found {{/%s}}", frame.kind, kind)}
+	}
+	p.stack = p.stack[:len(p.stack)-1]
+	return nil
+}
+
+func (p *parser) appendNode(n node) {
+	if len(p.stack) == 0 {
+		p.root = append(p.root, n)
+		return
+	}
+	frame := p.stack[len(p.stack)-1]
+	switch block := frame.node.(type) {
+	case *ifNode:
+		if frame.inElse {
+			block.els = append(block.els, n)
+		} else {
+			block.then = append(block.then, n)
+		}
+	case *eachNode:
+		if frame.inElse {
+			block.els = append(block.els, n)
+		} else {
+			block.body = append(block.body, n)
+		}
+	}
+}
+
+func (p *parser) parseExpression(body string, line int) (*expression, error) {
+	segments, err := splitPipes(body)
+	if err != nil {
+		return nil, &ParseError{Line: line, Message: err.Error()}
+	}
+	tokens, err := tokenize(segments[0])
+	if err != nil {
+		return nil, &ParseError{Line: line, Message: err.Error()}
+	}
+	if len(tokens) != 1 {
+		return nil, &ParseError{Line: line, Message: fmt.Sprintf("invalid expression %q", strings.TrimSpace(segments[0]))}
+	}
+	first, err := parseOperand(tokens[0])
+	if err != nil {
+		return nil, &ParseError{Line: line, Message: err.Error()}
+	}
+	p.trackVariable(first)
+
+	expr := &expression{operand: first}
+	for _, segment := range segments[1:] {
+		tokens, err := tokenize(segment)
+		if err != nil {
+			return nil, &ParseError{Line: line, Message: err.Error()}
+		}
+		if len(tokens) == 0 {
+			return nil, &ParseError{Line: line, Message: "empty pipe segment"}
+		}
+		fn, ok := functions[tokens[0]]
+		if !ok {
+			return nil, &ParseError{Line: line, Message: fmt.Sprintf("unknown function %q", tokens[0])}
+		}
+		args := make([]operand, 0, len(tokens)-1)
+		for _, token := range tokens[1:] {
+			arg, err := parseOperand(token)
+			if err != nil {
+				return nil, &ParseError{Line: line, Message: err.Error()}
+			}
+			p.trackVariable(arg)
+			args = append(args, arg)
+		}
+		if len(args) < fn.minArgs || len(args) > fn.maxArgs {
+			return nil, &ParseError{Line: line, Message: fmt.Sprintf("function %q expects %d-%d arguments, got %d", tokens[0], fn.minArgs, fn.maxArgs, len(args))}
+		}
+		expr.calls = append(expr.calls, call{name: tokens[0], args: args})
+	}
+	return expr, nil
+}
+
+// trackVariable 记录模板引用的顶层变量，循环内的 this/@index 不计入。
+func (p *parser) trackVariable(op operand) {
+	if op.isLit || op.path == "" || strings.HasPrefix(op.path, "@") || op.path == "this" || strings.HasPrefix(op.path, "this.") {
+		return
+	}
+	if len(p.stack) > 0 {
+		for _, frame := range p.stack {
+			if frame.kind == "each" {
+				// 循环体内的裸变量优先从当前元素解析，无法静态判定是否为顶层变量。
+				return
+			}
+		}
+	}
+	root := strings.SplitN(op.path, ".", 2)[0]
+	if _, ok := p.paths[root]; ok {
+		return
+	}
+	p.paths[root] = struct{}{}
+	p.order = append(p.order, root)
+}
+
+func parseOperand(token string) (operand, error) {
+	if strings.HasPrefix(token, `"`) {
+		value, err := strconv.Unquote(token)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid string literal %s", token)
+		}
+		return operand{literal: value, isLit: true}, nil
+	}
+	if token == "true" || token == "false" {
+		return operand{literal: token == "true", isLit: true}, nil
+	}
+	if c := token[0]; c == '-' || (c >= '0' && c <= '9') {
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid number literal %s", token)
+		}
+		return operand{literal: value, isLit: true}, nil
+	}
+	for _, r := range token {
+		if !(r == '_' || r == '.' || r == '@' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return operand{}, fmt.Errorf("invalid identifier %q", token)
+		}
+	}
+	return operand{path: token}, nil
+}
+
+// splitPipes 按 | 切分表达式，忽略字符串字面量内的 |。
+func splitPipes(body string) ([]string, error) {
+	var segments []string
+	var current strings.Builder
+	inString := false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\\' && inString && i+1 < len(body):
+			current.WriteByte(c)
+			i++
+			current.WriteByte(body[i])
+			continue
+		case c == '"':
+			inString = !inString
+		case c == '|' && !inString:
+			segments = append(segments, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	if inString {
+		return nil, fmt.Errorf("unterminated string literal")
+	}
+	segments = append(segments, current.String())
+	return segments, nil
+}
+
+// tokenize 以空白切分，保留带空格的字符串字面量。
+func tokenize(segment string) ([]string, error) {
+	var tokens []string
+	i := 0
+	for i < len(segment) {
+		c := segment[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			i++
+			continue
+		}
+		if c == '"' {
+			j := i + 1
+			for j < len(segment) {
+				if segment[j] == '\\' {
+					j += 2
+					continue
+				}
+				if segment[j] == '"' {
+					break
+				}
+				j++
+			}
+			if j >= len(segment) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, segment[i:j+1])
+			i = j + 1
+			continue
+		}
+		j := i
+		for j < len(segment) && segment[j] != ' ' && segment[j] != '\t' && segment[j] != '\n' && segment[j] != '\r' {
+			j++
+		}
+		tokens = append(tokens, segment[i:j])
+		i = j
+	}
+	return tokens, nil
+}
+
+func splitFirst(body string) (string, string) {
+	body = strings.TrimSpace(body)
+	idx := strings.IndexAny(body, " \t\n")
+	if idx < 0 {
+		return body, ""
+	}
+	return body[:idx], strings.TrimSpace(body[idx+1:])
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderFunctionsAndBlocks(t *testing.T) {
+	tmpl := `Hi {{ name | default "guest" | upper }}!
+{{#if items}}{{#each items}}{{@index}}:{{ title | truncate 3 "…" }};{{/each}}{{else}}none{{/if}}
+{{#unless vip}}regular{{/unless}} {{ created | date "2006-01-02" }} {{ meta | json }} {{ missing }} {{! comment }}{{> shared}}`
+	data := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"title": "alpha"},
+			map[string]interface{}{"title": "be"},
+		},
+		"created": "2025-01-02T03:04:05Z",
+		"meta":    map[string]interface{}{"k": "v"},
+	}
+
+	out, err := Render(tmpl, data, Options{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	expected := `Hi GUEST!
+0:alp…;1:be;
+regular 2025-01-02 {"k":"v"} {{ missing }} {{> shared}}`
+	if out != expected {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, expected)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"unclosed tag":     "Hello {{ name",
+		"unknown function": "{{ name | exec \"rm\" }}",
+		"unclosed block":   "{{#if a}}x",
+		"mismatched block": "{{#if a}}x{{/each}}",
+		"bad arity":        "{{ name | truncate }}",
+	}
+	for name, src := range cases {
+		if _, err := Parse(src); err == nil {
+			t.Fatalf("%s: expected parse error", name)
+		} else {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("%s: expected *ParseError got %T", name, err)
+			}
+		}
+	}
+
+	deep := strings.Repeat("{{#if a}}", 9) + strings.Repeat("{{/if}}", 9)
+	if _, err := Parse(deep); err == nil {
+		t.Fatalf("expected nesting limit error")
+	}
+}
+
+func TestRenderLimits(t *testing.T) {
+	items := make([]interface{}, 20)
+	_, err := Render("{{#each items}}{{#each items}}x{{/each}}{{/each}}", map[string]interface{}{"items": items}, Options{Limits: Limits{MaxIterations: 100}})
+	if !errors.Is(err, ErrIterationLimit) {
+		t.Fatalf("expected ErrIterationLimit got %v", err)
+	}
+
+	_, err = Render("{{ text }}{{ text }}", map[string]interface{}{"text": strings.Repeat("a", 10)}, Options{Limits: Limits{MaxOutputBytes: 15}})
+	if !errors.Is(err, ErrOutputLimit) {
+		t.Fatalf("expected ErrOutputLimit got %v", err)
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	tmpl, err := Parse("{{ user.name }} {{#each rows}}{{ cell }}{{/each}} {{ topic | default fallback }}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got := strings.Join(tmpl.Variables(), ",")
+	if got != "user,rows,topic,fallback" {
+		t.Fatalf("unexpected variables %s", got)
+	}
+}
+
+func TestMissingModes(t *testing.T) {
+	tmpl := "{{ greeting }} {{ name }} {{ name }} {{ topic | default \"x\" }}"
+
+	out, err := Render(tmpl, map[string]interface{}{"greeting": "Hi"}, Options{})
+	if err != nil || out != "Hi {{ name }} {{ name }} x" {
+		t.Fatalf("lenient: unexpected %q %v", out, err)
+	}
+
+	_, err = Render(tmpl, nil, Options{Missing: MissingStrict})
+	var missing *MissingVariablesError
+	if !errors.As(err, &missing) {
+		t.Fatalf("strict: expected MissingVariablesError got %v", err)
+	}
+	if strings.Join(missing.Keys, ",") != "greeting,name" {
+		t.Fatalf("strict: unexpected keys %v", missing.Keys)
+	}
+
+	out, err = Render(tmpl, map[string]interface{}{"greeting": "Hi"}, Options{
+		Missing:  MissingDefault,
+		Defaults: map[string]interface{}{"greeting": "Hello", "name": "guest"},
+	})
+	if err != nil || out != "Hi guest guest x" {
+		t.Fatalf("default: unexpected %q %v", out, err)
+	}
+
+	if _, ok := ParseMissingMode("loose"); ok {
+		t.Fatalf("expected invalid mode")
+	}
+}
@@ -0,0 +1,175 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const awsSecretsTarget = "secretsmanager.GetSecretValue"
+
+// AWSCredentials 为调用 AWS API 的访问凭证，SessionToken 仅临时凭证需要。
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSCredentialsFromEnv 读取标准环境变量 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY 与 AWS_SESSION_TOKEN。
+func AWSCredentialsFromEnv() (AWSCredentials, error) {
+	creds := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AWSCredentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+	}
+	return creds, nil
+}
+
+// AWSSecretsManager 通过 GetSecretValue 读取 AWS Secrets Manager 中的 SecretString，请求使用 SigV4 签名。
+type AWSSecretsManager struct {
+	region   string
+	endpoint string
+	creds    AWSCredentials
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewAWSSecretsManager 创建读取器；endpoint 为空时使用区域的公共端点，可指向 VPC 端点或本地模拟服务。
+func NewAWSSecretsManager(region, endpoint string, creds AWSCredentials) *AWSSecretsManager {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+	return &AWSSecretsManager{
+		region:   region,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		creds:    creds,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Fetch 实现 Provider，path 为密钥名称或 ARN。
+func (m *AWSSecretsManager) Fetch(ctx context.Context, path string) (string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", awsSecretsTarget)
+	m.sign(req, body)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		return "", fmt.Errorf("secrets manager responded %d %s", resp.StatusCode, apiErr.Type)
+	}
+
+	var payload struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(respBody, &payload); err != nil {
+		return "", fmt.Errorf("decode secrets manager response: %w", err)
+	}
+	if payload.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no SecretString (binary secrets are not supported)", path)
+	}
+	return *payload.SecretString, nil
+}
+
+// sign 按 AWS Signature Version 4 为请求添加 X-Amz-Date 与 Authorization 头。
+func (m *AWSSecretsManager) sign(req *http.Request, body []byte) {
+	now := m.now()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if m.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", m.creds.SessionToken)
+	}
+
+	// 参与签名的头按名称字典序排列。
+	headers := []string{"content-type", "host", "x-amz-date"}
+	if m.creds.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	headers = append(headers, "x-amz-target")
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	canonicalPath := req.URL.EscapedPath()
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/secretsmanager/aws4_request", dateStamp, m.region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+m.creds.SecretAccessKey), dateStamp)
+	key = hmacSHA256(key, m.region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		m.creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	// url.Values.Encode 按键排序，但空格编码为 "+"，SigV4 要求 "%20"。
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,60 @@
+// Package secrets 从外部密钥管理服务（HashiCorp Vault、AWS Secrets Manager）读取配置中的密钥，
+// 通过 HTTP API 直接访问，不依赖各厂商 SDK。
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ReferencePrefix 标记需要从密钥服务解析的配置值，格式为 secret://<路径>#<字段>。
+const ReferencePrefix = "secret://"
+
+// Provider 读取一个密钥的原始内容：Vault 返回 KV 数据的 JSON 对象，AWS 返回 SecretString。
+type Provider interface {
+	Fetch(ctx context.Context, path string) (string, error)
+}
+
+// Reference 为解析后的密钥引用，Key 为空时使用密钥的完整内容。
+type Reference struct {
+	Path string
+	Key  string
+}
+
+// ParseReference 解析 secret:// 引用，value 不是引用时返回 false。
+func ParseReference(value string) (Reference, bool, error) {
+	if !strings.HasPrefix(value, ReferencePrefix) {
+		return Reference{}, false, nil
+	}
+	path, key, _ := strings.Cut(strings.TrimPrefix(value, ReferencePrefix), "#")
+	if path == "" {
+		return Reference{}, true, fmt.Errorf("secret reference %q has no path", value)
+	}
+	return Reference{Path: path, Key: key}, true, nil
+}
+
+// Resolve 读取引用指向的值。指定 Key 时密钥内容须为 JSON 对象，字段值非字符串时返回其 JSON 文本。
+func Resolve(ctx context.Context, provider Provider, ref Reference) (string, error) {
+	raw, err := provider.Fetch(ctx, ref.Path)
+	if err != nil {
+		return "", fmt.Errorf("fetch secret %s: %w", ref.Path, err)
+	}
+	if ref.Key == "" {
+		return raw, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object", ref.Path)
+	}
+	field, ok := fields[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", ref.Path, ref.Key)
+	}
+	var text string
+	if err := json.Unmarshal(field, &text); err == nil {
+		return text, nil
+	}
+	return string(field), nil
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultResolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/data/prompt-manager/auth" || r.Header.Get("X-Vault-Token") != "token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"data":{"clientSecret":"gh-secret","port":5432},"metadata":{"version":3}}}`)
+	}))
+	defer server.Close()
+
+	vault := NewVault(server.URL, "token", "kv", "")
+	ref, ok, err := ParseReference("secret://prompt-manager/auth#clientSecret")
+	if err != nil || !ok {
+		t.Fatalf("parse reference: %v %v", ok, err)
+	}
+	value, err := Resolve(context.Background(), vault, ref)
+	if err != nil || value != "gh-secret" {
+		t.Fatalf("expected gh-secret, got %q %v", value, err)
+	}
+	if value, err := Resolve(context.Background(), vault, Reference{Path: "prompt-manager/auth", Key: "port"}); err != nil || value != "5432" {
+		t.Fatalf("expected non-string field as JSON text, got %q %v", value, err)
+	}
+	if _, err := Resolve(context.Background(), vault, Reference{Path: "prompt-manager/auth", Key: "missing"}); err == nil {
+		t.Fatalf("expected missing key error")
+	}
+	if _, err := Resolve(context.Background(), vault, Reference{Path: "other", Key: "x"}); err == nil {
+		t.Fatalf("expected error for forbidden path")
+	}
+}
+
+func TestAWSSecretsManagerFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240501/eu-west-1/secretsmanager/aws4_request, ") ||
+			!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=") {
+			t.Errorf("unexpected authorization header %q", auth)
+		}
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || r.Header.Get("X-Amz-Date") != "20240501T120000Z" || r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["SecretId"] != "prod/prompt-manager/db" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"__type":"ResourceNotFoundException","message":"not found"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"Name":"prod/prompt-manager/db","SecretString":"{\"dsn\":\"postgres://app:pw@db/prompts\"}"}`)
+	}))
+	defer server.Close()
+
+	manager := NewAWSSecretsManager("eu-west-1", server.URL, AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"})
+	manager.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	value, err := Resolve(context.Background(), manager, Reference{Path: "prod/prompt-manager/db", Key: "dsn"})
+	if err != nil || value != "postgres://app:pw@db/prompts" {
+		t.Fatalf("expected dsn from secret string, got %q %v", value, err)
+	}
+	if _, err := manager.Fetch(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Fatalf("expected api error, got %v", err)
+	}
+}
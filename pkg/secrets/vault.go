@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Vault 从 HashiCorp Vault 的 KV v2 引擎读取密钥，路径相对于挂载点。
+type Vault struct {
+	addr      string
+	token     string
+	mount     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVault 创建 Vault 读取器；mount 为空时使用 "secret"，namespace 仅 Vault Enterprise 需要。
+func NewVault(addr, token, mount, namespace string) *Vault {
+	if mount == "" {
+		mount = "secret"
+	}
+	return &Vault{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		mount:     strings.Trim(mount, "/"),
+		namespace: namespace,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Fetch 实现 Provider，返回最新版本数据的 JSON 对象。
+func (v *Vault) Fetch(ctx context.Context, path string) (string, error) {
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", v.addr, url.PathEscape(v.mount), strings.TrimLeft(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+	if len(payload.Data.Data) == 0 || string(payload.Data.Data) == "null" {
+		return "", fmt.Errorf("vault secret %s has no data", path)
+	}
+	return string(payload.Data.Data), nil
+}
@@ -1,5 +1,10 @@
 import { apiClient } from '@/libs/http/client'
 
-export async function activatePromptVersion(promptId: string, versionId: string): Promise<void> {
-  await apiClient.post(`/prompts/${promptId}/versions/${versionId}/activate`)
+export async function activatePromptVersion(
+  promptId: string,
+  versionId: string,
+  releaseNote?: string,
+): Promise<void> {
+  const payload = releaseNote?.trim() ? { release_note: releaseNote.trim() } : undefined
+  await apiClient.post(`/prompts/${promptId}/versions/${versionId}/activate`, payload)
 }
@@ -0,0 +1,9 @@
+import { apiClient } from '@/libs/http/client'
+
+export async function archivePrompt(promptId: string): Promise<void> {
+  await apiClient.post(`/prompts/${promptId}/archive`)
+}
+
+export async function unarchivePrompt(promptId: string): Promise<void> {
+  await apiClient.post(`/prompts/${promptId}/unarchive`)
+}
@@ -26,10 +26,11 @@ interface SuccessResponse<T> {
   data: T
 }
 
-export async function createPrompt(payload: CreatePromptPayload): Promise<Prompt> {
+export async function createPrompt(payload: CreatePromptPayload, template?: string): Promise<Prompt> {
   const response = await apiClient.post<SuccessResponse<RawPromptResponse>>(
     '/prompts',
     payload,
+    { params: template ? { template } : undefined },
   )
 
   return mapPrompt(response.data.data.prompt)
@@ -0,0 +1,53 @@
+import { apiClient } from '@/libs/http/client'
+import type { PromptExecutionStat, PromptStatsParams } from '@/features/prompts/types'
+
+type RawPromptExecutionStat = {
+  bucket: string
+  day: string
+  total_calls: number
+  success_calls: number
+  average_ms: number
+  p50_ms?: number
+  p90_ms?: number
+  p99_ms?: number
+  error_classes?: Record<string, number> | null
+}
+
+interface RawStatsResponse {
+  items: RawPromptExecutionStat[] | null
+}
+
+interface SuccessResponse<T> {
+  data: T
+}
+
+function mapPromptExecutionStat(raw: RawPromptExecutionStat): PromptExecutionStat {
+  return {
+    bucket: raw.bucket,
+    day: raw.day,
+    totalCalls: raw.total_calls,
+    successCalls: raw.success_calls,
+    averageMs: raw.average_ms,
+    p50Ms: raw.p50_ms ?? 0,
+    p90Ms: raw.p90_ms ?? 0,
+    p99Ms: raw.p99_ms ?? 0,
+    errorClasses: raw.error_classes ?? {},
+  }
+}
+
+export async function getPromptStats(
+  promptId: string,
+  params: PromptStatsParams = {},
+): Promise<PromptExecutionStat[]> {
+  const response = await apiClient.get<SuccessResponse<RawStatsResponse>>(
+    `/prompts/${promptId}/stats`,
+    {
+      params: {
+        days: params.days,
+        granularity: params.granularity,
+        tz: params.tz,
+      },
+    },
+  )
+  return (response.data.data.items ?? []).map(mapPromptExecutionStat)
+}
@@ -0,0 +1,37 @@
+import { apiClient } from '@/libs/http/client'
+import type { PromptTemplate } from '@/features/prompts/types'
+
+type RawPromptTemplate = {
+  id: string
+  slug: string
+  name: string
+  description?: string | null
+  body: string
+  variables_schema?: Record<string, unknown> | null
+  tags?: string[] | null
+}
+
+interface RawTemplatesResponse {
+  items: RawPromptTemplate[] | null
+}
+
+interface SuccessResponse<T> {
+  data: T
+}
+
+function mapPromptTemplate(raw: RawPromptTemplate): PromptTemplate {
+  return {
+    id: raw.id,
+    slug: raw.slug,
+    name: raw.name,
+    description: raw.description ?? null,
+    body: raw.body,
+    variablesSchema: raw.variables_schema ?? null,
+    tags: raw.tags ?? [],
+  }
+}
+
+export async function listPromptTemplates(): Promise<PromptTemplate[]> {
+  const response = await apiClient.get<SuccessResponse<RawTemplatesResponse>>('/prompt-templates')
+  return (response.data.data.items ?? []).map(mapPromptTemplate)
+}
@@ -39,10 +39,11 @@ interface SuccessResponse<T> {
 export async function listPrompts(
   params: PromptListParams = {},
 ): Promise<PromptListResult> {
+  // 列表接口默认不返回正文，表格需要展示正文摘要，因此默认请求 includeBody。
   const response = await apiClient.get<SuccessResponse<RawListResponse>>(
     '/prompts',
     {
-      params,
+      params: { ...params, includeBody: params.includeBody ?? true },
     },
   )
 
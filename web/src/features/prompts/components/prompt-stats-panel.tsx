@@ -0,0 +1,83 @@
+import { Alert } from '@/components/ui/alert'
+import { Button } from '@/components/ui/button'
+import { usePromptStatsQuery } from '@/features/prompts/hooks/use-prompt-stats'
+
+interface PromptStatsPanelProps {
+  promptId: string
+  days?: number
+}
+
+function formatMillis(value: number): string {
+  if (!value) {
+    return '-'
+  }
+  return value >= 1000 ? `${(value / 1000).toFixed(2)} s` : `${Math.round(value)} ms`
+}
+
+export function PromptStatsPanel({ promptId, days = 7 }: PromptStatsPanelProps) {
+  const timezone = Intl.DateTimeFormat().resolvedOptions().timeZone
+  const { data, isLoading, isError, error, refetch } = usePromptStatsQuery(promptId, {
+    days,
+    tz: timezone,
+  })
+  const items = data ?? []
+
+  return (
+    <section className="space-y-6 rounded-3xl border border-slate-200 bg-white p-8 shadow-sm">
+      <header className="flex flex-col gap-2 md:flex-row md:items-center md:justify-between">
+        <div>
+          <h2 className="text-xl font-semibold text-slate-900">执行概览</h2>
+          <p className="text-sm text-slate-600">
+            最近 {days} 天的调用量与耗时分位数，p90/p99 可反映均值掩盖的长尾延迟。
+          </p>
+        </div>
+        <Button variant="secondary" size="sm" onClick={() => refetch()} disabled={isLoading}>
+          刷新
+        </Button>
+      </header>
+
+      {isLoading ? (
+        <p className="text-sm text-slate-500">统计数据加载中...</p>
+      ) : isError ? (
+        <Alert variant="error" className="text-sm">
+          {error?.message || '无法加载统计数据。'}
+        </Alert>
+      ) : items.length === 0 ? (
+        <p className="text-sm text-slate-500">暂无执行记录。</p>
+      ) : (
+        <div className="overflow-x-auto">
+          <table className="min-w-full divide-y divide-slate-200 text-sm">
+            <thead>
+              <tr className="text-left text-xs uppercase tracking-wide text-slate-500">
+                <th className="py-2 pr-4 font-medium">日期</th>
+                <th className="py-2 pr-4 font-medium">调用</th>
+                <th className="py-2 pr-4 font-medium">成功率</th>
+                <th className="py-2 pr-4 font-medium">平均</th>
+                <th className="py-2 pr-4 font-medium">p50</th>
+                <th className="py-2 pr-4 font-medium">p90</th>
+                <th className="py-2 font-medium">p99</th>
+              </tr>
+            </thead>
+            <tbody className="divide-y divide-slate-100 text-slate-700">
+              {items.map((item) => (
+                <tr key={item.bucket}>
+                  <td className="py-2 pr-4">{item.bucket}</td>
+                  <td className="py-2 pr-4">{item.totalCalls}</td>
+                  <td className="py-2 pr-4">
+                    {item.totalCalls > 0
+                      ? `${((item.successCalls / item.totalCalls) * 100).toFixed(1)}%`
+                      : '-'}
+                  </td>
+                  <td className="py-2 pr-4">{formatMillis(item.averageMs)}</td>
+                  <td className="py-2 pr-4">{formatMillis(item.p50Ms)}</td>
+                  <td className="py-2 pr-4">{formatMillis(item.p90Ms)}</td>
+                  <td className="py-2">{formatMillis(item.p99Ms)}</td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      )}
+    </section>
+  )
+}
@@ -0,0 +1,18 @@
+import { useQuery } from '@tanstack/react-query'
+
+import { getPromptStats } from '@/features/prompts/api/get-prompt-stats'
+import type { PromptExecutionStat, PromptStatsParams } from '@/features/prompts/types'
+
+export function usePromptStatsQuery(promptId: string | null, params: PromptStatsParams = {}) {
+  return useQuery<PromptExecutionStat[], Error>({
+    queryKey: ['promptStats', promptId, params],
+    queryFn: () => {
+      if (!promptId) {
+        throw new Error('promptId is required')
+      }
+      return getPromptStats(promptId, params)
+    },
+    enabled: Boolean(promptId),
+    staleTime: 30_000,
+  })
+}
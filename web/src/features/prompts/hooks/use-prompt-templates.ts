@@ -0,0 +1,13 @@
+import { useQuery } from '@tanstack/react-query'
+
+import { listPromptTemplates } from '@/features/prompts/api/list-prompt-templates'
+import type { PromptTemplate } from '@/features/prompts/types'
+
+export function usePromptTemplatesQuery(enabled = true) {
+  return useQuery<PromptTemplate[], Error>({
+    queryKey: ['promptTemplates'],
+    queryFn: listPromptTemplates,
+    enabled,
+    staleTime: 5 * 60_000,
+  })
+}
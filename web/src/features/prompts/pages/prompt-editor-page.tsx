@@ -14,6 +14,8 @@ import { createPrompt } from '@/features/prompts/api/create-prompt'
 import { createPromptVersion } from '@/features/prompts/api/create-prompt-version'
 import { updatePrompt } from '@/features/prompts/api/update-prompt'
 import { usePromptQuery } from '@/features/prompts/hooks/use-prompt'
+import { usePromptTemplatesQuery } from '@/features/prompts/hooks/use-prompt-templates'
+import { PromptStatsPanel } from '@/features/prompts/components/prompt-stats-panel'
 import { PromptVersionPanel } from '@/features/prompts/components/prompt-version-panel'
 import type { Prompt } from '@/features/prompts/types'
 
@@ -46,7 +48,9 @@ export function PromptEditorPage() {
   const navigate = useNavigate()
   const [submitError, setSubmitError] = useState<string | null>(null)
   const [originalPrompt, setOriginalPrompt] = useState<Prompt | null>(null)
+  const [templateSlug, setTemplateSlug] = useState('')
   const queryClient = useQueryClient()
+  const { data: templates = [] } = usePromptTemplatesQuery(mode === 'create')
 
   const {
     data: prompt,
@@ -59,6 +63,8 @@ export function PromptEditorPage() {
     register,
     handleSubmit,
     reset,
+    getValues,
+    setValue,
     formState: { errors, isSubmitting },
   } = useForm<PromptEditorValues>({
     resolver: zodResolver(promptEditorSchema),
@@ -84,6 +90,22 @@ export function PromptEditorPage() {
 
   const pageTitle = mode === 'create' ? '新建 Prompt' : '编辑 Prompt'
 
+  // 选择模板时用脚手架填充正文，描述与标签仅在为空时补全，避免覆盖已填写的内容。
+  const handleTemplateChange = (slug: string) => {
+    setTemplateSlug(slug)
+    const template = templates.find((item) => item.slug === slug)
+    if (!template) {
+      return
+    }
+    setValue('body', template.body, { shouldValidate: true })
+    if (!getValues('description') && template.description) {
+      setValue('description', template.description)
+    }
+    if (!getValues('tags') && template.tags.length > 0) {
+      setValue('tags', template.tags.join(', '))
+    }
+  }
+
   const handleCancel = () => {
     navigate('/prompts')
   }
@@ -99,7 +121,7 @@ export function PromptEditorPage() {
 
     try {
       if (mode === 'create') {
-        await createPrompt(payload)
+        await createPrompt(payload, templateSlug || undefined)
         await queryClient.invalidateQueries({ queryKey: ['prompts'] })
         navigate('/prompts', {
           state: { feedback: { type: 'success', message: `Prompt “${payload.name}” 创建成功。` } },
@@ -205,6 +227,30 @@ export function PromptEditorPage() {
       <form onSubmit={submitHandler} className="space-y-10 rounded-3xl border border-slate-200 bg-white p-12 shadow-md">
         {submitError ? <Alert variant="error">{submitError}</Alert> : null}
 
+        {mode === 'create' && templates.length > 0 ? (
+          <div className="space-y-2">
+            <label className="block text-sm font-medium text-slate-700" htmlFor="prompt-template">
+              起始模板（可选）
+            </label>
+            <select
+              id="prompt-template"
+              className="w-full rounded-md border border-slate-300 bg-white px-3 py-2 text-sm text-slate-700"
+              value={templateSlug}
+              onChange={(event) => handleTemplateChange(event.target.value)}
+            >
+              <option value="">空白 Prompt</option>
+              {templates.map((template) => (
+                <option key={template.slug} value={template.slug}>
+                  {template.name}
+                </option>
+              ))}
+            </select>
+            <p className="text-xs text-slate-500">
+              模板会预填正文结构与变量定义，创建后仍可自由修改。
+            </p>
+          </div>
+        ) : null}
+
         <div className="grid gap-8 lg:grid-cols-3">
           <div className="space-y-2 lg:col-span-2">
             <label className="block text-sm font-medium text-slate-700" htmlFor="prompt-name">
@@ -260,6 +306,8 @@ export function PromptEditorPage() {
         </div>
       </form>
 
+      {mode === 'edit' && promptId ? <PromptStatsPanel promptId={promptId} /> : null}
+
       {mode === 'edit' && promptId ? (
         <PromptVersionPanel
           promptId={promptId}
@@ -19,6 +19,7 @@ export interface PromptListParams {
   offset?: number
   search?: string
   includeDeleted?: boolean
+  includeBody?: boolean
 }
 
 export interface PromptListMeta {
@@ -99,3 +100,33 @@ export interface PromptVersionListResult {
   items: PromptVersion[]
   meta?: PromptVersionListMeta
 }
+
+export type PromptStatsGranularity = 'hour' | 'day' | 'week' | 'month'
+
+export interface PromptStatsParams {
+  days?: number
+  granularity?: PromptStatsGranularity
+  tz?: string
+}
+
+export interface PromptExecutionStat {
+  bucket: string
+  day: string
+  totalCalls: number
+  successCalls: number
+  averageMs: number
+  p50Ms: number
+  p90Ms: number
+  p99Ms: number
+  errorClasses: Record<string, number>
+}
+
+export interface PromptTemplate {
+  id: string
+  slug: string
+  name: string
+  description: string | null
+  body: string
+  variablesSchema: Record<string, unknown> | null
+  tags: string[]
+}